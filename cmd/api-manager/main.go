@@ -6,6 +6,7 @@
 package main
 
 import (
+	"net/http"
 	"os"
 	"time"
 
@@ -21,6 +22,10 @@ import (
 	"github.com/vmware/dispatch/pkg/api-manager/gateway/kong"
 	"github.com/vmware/dispatch/pkg/api-manager/gen/restapi"
 	"github.com/vmware/dispatch/pkg/api-manager/gen/restapi/operations"
+	"github.com/vmware/dispatch/pkg/api-manager/issuer"
+	"github.com/vmware/dispatch/pkg/audit"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/controller"
 	"github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/middleware"
 	"github.com/vmware/dispatch/pkg/utils"
@@ -34,6 +39,10 @@ var debugFlags = struct {
 	DebugEnabled bool `long:"debug" description:"Enable debugging messages"`
 }{}
 
+var auditFlags = audit.Flags{}
+
+var controllerFlags = controller.OwnershipFlags{}
+
 func configureFlags() []swag.CommandLineOptionsGroup {
 	return []swag.CommandLineOptionsGroup{
 		swag.CommandLineOptionsGroup{
@@ -46,6 +55,15 @@ func configureFlags() []swag.CommandLineOptionsGroup {
 			LongDescription:  "",
 			Options:          &debugFlags,
 		},
+		swag.CommandLineOptionsGroup{
+			ShortDescription: "Audit log options",
+			LongDescription:  "",
+			Options:          &auditFlags,
+		}, {
+			ShortDescription: "Controller options",
+			LongDescription:  "",
+			Options:          &controllerFlags,
+		},
 	}
 }
 
@@ -86,18 +104,32 @@ func main() {
 	}
 
 	// entity store
-	es, err := entitystore.NewFromBackend(
-		entitystore.BackendConfig{
-			Backend:  apimanager.APIManagerFlags.DbBackend,
-			Address:  apimanager.APIManagerFlags.DbFile,
-			Bucket:   apimanager.APIManagerFlags.DbDatabase,
-			Username: apimanager.APIManagerFlags.DbUser,
-			Password: apimanager.APIManagerFlags.DbPassword,
-		})
+	dbConfig := entitystore.BackendConfig{
+		Backend:  apimanager.APIManagerFlags.DbBackend,
+		Address:  apimanager.APIManagerFlags.DbFile,
+		Bucket:   apimanager.APIManagerFlags.DbDatabase,
+		Username: apimanager.APIManagerFlags.DbUser,
+		Password: apimanager.APIManagerFlags.DbPassword,
+	}
+	es, err := entitystore.NewFromBackend(dbConfig)
 	if err != nil {
 		log.Fatalln(err)
 	}
 
+	// audit log, the store sink writes to the unwrapped store
+	auditSink, auditCloser, err := audit.NewSink(&auditFlags, es)
+	if err != nil {
+		log.Fatalf("Error creating the audit log sink: %+v", err)
+	}
+	defer auditCloser.Close()
+	es = audit.NewEntityStore(es)
+
+	// the replicas share the entities reconciled by the controller
+	ownership, err := controller.NewOwnership(&controllerFlags, "api-manager", dbConfig)
+	if err != nil {
+		log.Fatalf("Error creating the controller ownership: %+v", err)
+	}
+
 	// api gateway
 	gateway, err := kong.NewClient(&kong.Config{
 		Host:     apimanager.APIManagerFlags.GatewayHost,
@@ -107,16 +139,35 @@ func main() {
 		log.Fatalf("Error creating an api gateway client: %v", err)
 	}
 
+	secretsClient := client.NewSecretsClient(apimanager.APIManagerFlags.SecretStore, client.AuthWithToken("cookie"), "")
+
+	// acme issuer, challenges are served by the api-manager itself
+	var certIssuer issuer.Issuer
+	acmeMiddleware := alice.Constructor(func(next http.Handler) http.Handler { return next })
+	if apimanager.APIManagerFlags.AcmeDirectory != "" {
+		acmeIssuer, err := issuer.NewACMEIssuer(&issuer.ACMEConfig{
+			DirectoryURL: apimanager.APIManagerFlags.AcmeDirectory,
+			Email:        apimanager.APIManagerFlags.AcmeEmail,
+		})
+		if err != nil {
+			log.Fatalf("Error creating an acme issuer: %v", err)
+		}
+		certIssuer = acmeIssuer
+		acmeMiddleware = acmeIssuer.Middleware
+	}
+
 	// controller
 	config := &apimanager.ControllerConfig{
-		ResyncPeriod: time.Duration(apimanager.APIManagerFlags.ResyncPeriod) * time.Second,
+		ResyncPeriod:  time.Duration(apimanager.APIManagerFlags.ResyncPeriod) * time.Second,
+		ExpiryWarning: time.Duration(apimanager.APIManagerFlags.ExpiryWarning) * 24 * time.Hour,
+		Ownership:     ownership,
 	}
-	controller := apimanager.NewController(config, es, gateway)
-	defer controller.Shutdown()
-	controller.Start()
+	apiController := apimanager.NewController(config, es, gateway, secretsClient, certIssuer)
+	defer apiController.Shutdown()
+	apiController.Start()
 
 	// handlers
-	handlers := apimanager.NewHandlers(controller.Watcher(), es)
+	handlers := apimanager.NewHandlers(apiController.Watcher(), es)
 	handlers.ConfigureHandlers(api)
 
 	healthChecker := func() error {
@@ -132,8 +183,11 @@ func main() {
 	opentracing.SetGlobalTracer(tracer)
 
 	handler := alice.New(
-		middleware.NewHealthCheckMW("", healthChecker),
+		middleware.NewHealthStatusMW("", healthChecker, controller.HealthStatus(apiController)),
 		middleware.NewTracingMW(tracer),
+		middleware.NewAuditMW("api-manager", auditSink),
+		middleware.NewApplicationScopeMW(),
+		acmeMiddleware,
 	).Then(api.Serve(nil))
 
 	server.SetHandler(handler)
@@ -19,6 +19,7 @@ import (
 	"github.com/vmware/dispatch/pkg/application-manager"
 	"github.com/vmware/dispatch/pkg/application-manager/gen/restapi"
 	"github.com/vmware/dispatch/pkg/application-manager/gen/restapi/operations"
+	"github.com/vmware/dispatch/pkg/audit"
 	"github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/middleware"
 	"github.com/vmware/dispatch/pkg/utils"
@@ -32,6 +33,8 @@ var debugFlags = struct {
 	DebugEnabled bool `long:"debug" description:"Enable debugging messages"`
 }{}
 
+var auditFlags = audit.Flags{}
+
 func configureFlags() []swag.CommandLineOptionsGroup {
 	return []swag.CommandLineOptionsGroup{
 		swag.CommandLineOptionsGroup{
@@ -44,6 +47,11 @@ func configureFlags() []swag.CommandLineOptionsGroup {
 			LongDescription:  "",
 			Options:          &debugFlags,
 		},
+		swag.CommandLineOptionsGroup{
+			ShortDescription: "Audit log options",
+			LongDescription:  "",
+			Options:          &auditFlags,
+		},
 	}
 }
 
@@ -95,6 +103,14 @@ func main() {
 	if err != nil {
 		log.Fatalln(err)
 	}
+
+	// audit log, the store sink writes to the unwrapped store
+	auditSink, auditCloser, err := audit.NewSink(&auditFlags, es)
+	if err != nil {
+		log.Fatalf("Error creating the audit log sink: %+v", err)
+	}
+	defer auditCloser.Close()
+	es = audit.NewEntityStore(es)
 	// handlers
 	handlers := applicationmanager.NewHandlers(nil, es)
 	handlers.ConfigureHandlers(app)
@@ -114,6 +130,7 @@ func main() {
 	handler := alice.New(
 		middleware.NewHealthCheckMW("", healthChecker),
 		middleware.NewTracingMW(tracer),
+		middleware.NewAuditMW("application-manager", auditSink),
 	).Then(app.Serve(nil))
 
 	server.SetHandler(handler)
@@ -16,8 +16,10 @@ import (
 	"github.com/opentracing/opentracing-go"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/vmware/dispatch/pkg/audit"
 	"github.com/vmware/dispatch/pkg/client"
 	"github.com/vmware/dispatch/pkg/config"
+	"github.com/vmware/dispatch/pkg/controller"
 	"github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/event-manager"
 	"github.com/vmware/dispatch/pkg/event-manager/drivers"
@@ -38,6 +40,8 @@ var debugFlags = struct {
 	DebugEnabled bool `long:"debug" description:"Enable debugging messages"`
 }{}
 
+var auditFlags = audit.Flags{}
+
 func configureFlags() []swag.CommandLineOptionsGroup {
 	return []swag.CommandLineOptionsGroup{
 		{
@@ -50,6 +54,11 @@ func configureFlags() []swag.CommandLineOptionsGroup {
 			LongDescription:  "",
 			Options:          &debugFlags,
 		},
+		{
+			ShortDescription: "Audit log options",
+			LongDescription:  "",
+			Options:          &auditFlags,
+		},
 	}
 }
 
@@ -102,6 +111,14 @@ func main() {
 		log.Fatalln(err)
 	}
 
+	// audit log, the store sink writes to the unwrapped store
+	auditSink, auditCloser, err := audit.NewSink(&auditFlags, store)
+	if err != nil {
+		log.Fatalf("Error creating the audit log sink: %+v", err)
+	}
+	defer auditCloser.Close()
+	store = audit.NewEntityStore(store)
+
 	var eventTransport events.Transport
 
 	switch eventmanager.Flags.Transport {
@@ -143,7 +160,6 @@ func main() {
 			Tracer:          eventmanager.Flags.Tracer,
 			K8sConfig:       eventmanager.Flags.K8sConfig,
 			DriverNamespace: eventmanager.Flags.K8sNamespace,
-			OrgID:           eventmanager.Flags.OrgID,
 		},
 	)
 	if err != nil {
@@ -153,6 +169,7 @@ func main() {
 	eventController := eventmanager.NewEventController(
 		subManager,
 		k8sBackend,
+		secretsClient,
 		store,
 		eventmanager.EventControllerConfig{},
 	)
@@ -183,8 +200,10 @@ func main() {
 	opentracing.SetGlobalTracer(tracer)
 
 	handler := alice.New(
-		middleware.NewHealthCheckMW("", healthChecker),
+		middleware.NewHealthStatusMW("", healthChecker, controller.HealthStatus(eventController)),
 		middleware.NewTracingMW(tracer),
+		middleware.NewAuditMW("event-manager", auditSink),
+		middleware.NewApplicationScopeMW(),
 	).Then(api.Serve(nil))
 
 	server.SetHandler(handler)
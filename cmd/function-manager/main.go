@@ -20,7 +20,9 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/vmware/dispatch/pkg/client"
 
+	"github.com/vmware/dispatch/pkg/audit"
 	"github.com/vmware/dispatch/pkg/config"
+	"github.com/vmware/dispatch/pkg/controller"
 	"github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/function-manager"
 	"github.com/vmware/dispatch/pkg/function-manager/gen/restapi"
@@ -105,6 +107,10 @@ var debugFlags = struct {
 	DebugEnabled bool `long:"debug" description:"Enable debugging messages"`
 }{}
 
+var auditFlags = audit.Flags{}
+
+var controllerFlags = controller.OwnershipFlags{}
+
 func configureFlags() []swag.CommandLineOptionsGroup {
 	return []swag.CommandLineOptionsGroup{{
 		ShortDescription: "Function manager Flags",
@@ -114,6 +120,14 @@ func configureFlags() []swag.CommandLineOptionsGroup {
 		ShortDescription: "Debug options",
 		LongDescription:  "",
 		Options:          &debugFlags,
+	}, {
+		ShortDescription: "Audit log options",
+		LongDescription:  "",
+		Options:          &auditFlags,
+	}, {
+		ShortDescription: "Controller options",
+		LongDescription:  "",
+		Options:          &controllerFlags,
 	}}
 }
 
@@ -161,23 +175,38 @@ func main() {
 		registryAuth = config.EmptyRegistryAuth
 	}
 
-	es, err := entitystore.NewFromBackend(
-		entitystore.BackendConfig{
-			Backend:  functionmanager.FunctionManagerFlags.DbBackend,
-			Address:  functionmanager.FunctionManagerFlags.DbFile,
-			Bucket:   functionmanager.FunctionManagerFlags.DbDatabase,
-			Username: functionmanager.FunctionManagerFlags.DbUser,
-			Password: functionmanager.FunctionManagerFlags.DbPassword,
-		})
+	dbConfig := entitystore.BackendConfig{
+		Backend:  functionmanager.FunctionManagerFlags.DbBackend,
+		Address:  functionmanager.FunctionManagerFlags.DbFile,
+		Bucket:   functionmanager.FunctionManagerFlags.DbDatabase,
+		Username: functionmanager.FunctionManagerFlags.DbUser,
+		Password: functionmanager.FunctionManagerFlags.DbPassword,
+	}
+	es, err := entitystore.NewFromBackend(dbConfig)
 	if err != nil {
 		log.Fatalln(err)
 	}
 
+	// audit log, the store sink writes to the unwrapped store
+	auditSink, auditCloser, err := audit.NewSink(&auditFlags, es)
+	if err != nil {
+		log.Fatalf("Error creating the audit log sink: %+v", err)
+	}
+	defer auditCloser.Close()
+	es = audit.NewEntityStore(es)
+
+	// the replicas share the entities reconciled by the controller
+	ownership, err := controller.NewOwnership(&controllerFlags, "function-manager", dbConfig)
+	if err != nil {
+		log.Fatalf("Error creating the controller ownership: %+v", err)
+	}
+
 	faas := drivers[config.Global.Function.Faas]()
 	defer utils.Close(faas)
 
 	c := &functionmanager.ControllerConfig{
 		ResyncPeriod: time.Duration(config.Global.Function.ResyncPeriod) * time.Second,
+		Ownership:    ownership,
 	}
 
 	secretsClient := client.NewSecretsClient(functionmanager.FunctionManagerFlags.SecretStore, client.AuthWithToken("cookie"), "")
@@ -202,11 +231,11 @@ func main() {
 	}
 	imageBuilder := functions.NewDockerImageBuilder(config.Global.Registry.RegistryURI, registryAuth, dc)
 
-	controller := functionmanager.NewController(c, es, faas, r, imageGetter, imageBuilder)
-	defer controller.Shutdown()
-	controller.Start()
+	functionController := functionmanager.NewController(c, es, faas, r, imageGetter, imageBuilder)
+	defer functionController.Shutdown()
+	functionController.Start()
 
-	handlers := functionmanager.NewHandlers(controller.Watcher(), es)
+	handlers := functionmanager.NewHandlers(functionController.Watcher(), es)
 	handlers.ConfigureHandlers(api)
 
 	healthChecker := func() error {
@@ -222,8 +251,10 @@ func main() {
 	opentracing.SetGlobalTracer(tracer)
 
 	handler := alice.New(
-		middleware.NewHealthCheckMW("", healthChecker),
+		middleware.NewHealthStatusMW("", healthChecker, controller.HealthStatus(functionController)),
 		middleware.NewTracingMW(tracer),
+		middleware.NewAuditMW("function-manager", auditSink),
+		middleware.NewApplicationScopeMW(),
 	).Then(api.Serve(nil))
 
 	server.SetHandler(handler)
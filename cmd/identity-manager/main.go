@@ -6,6 +6,7 @@
 package main
 
 import (
+	"context"
 	"os"
 
 	"github.com/go-openapi/loads"
@@ -16,11 +17,14 @@ import (
 	"github.com/opentracing/opentracing-go"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/vmware/dispatch/pkg/audit"
+	"github.com/vmware/dispatch/pkg/controller"
 	"github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/identity-manager"
 	iam "github.com/vmware/dispatch/pkg/identity-manager"
 	"github.com/vmware/dispatch/pkg/identity-manager/gen/restapi"
 	"github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations"
+	"github.com/vmware/dispatch/pkg/identity-manager/oidc"
 	"github.com/vmware/dispatch/pkg/middleware"
 	"github.com/vmware/dispatch/pkg/utils"
 )
@@ -29,6 +33,8 @@ var debugFlags = struct {
 	DebugEnabled bool `long:"debug" description:"Enable debugging messages"`
 }{}
 
+var auditFlags = audit.Flags{}
+
 func configureFlags() []swag.CommandLineOptionsGroup {
 	return []swag.CommandLineOptionsGroup{
 		swag.CommandLineOptionsGroup{
@@ -41,6 +47,11 @@ func configureFlags() []swag.CommandLineOptionsGroup {
 			LongDescription:  "",
 			Options:          &debugFlags,
 		},
+		swag.CommandLineOptionsGroup{
+			ShortDescription: "Audit log options",
+			LongDescription:  "",
+			Options:          &auditFlags,
+		},
 	}
 }
 
@@ -96,15 +107,43 @@ func main() {
 		log.Fatalln(err)
 	}
 
+	// audit log, the store sink writes to the unwrapped store
+	auditSink, auditCloser, err := audit.NewSink(&auditFlags, es)
+	if err != nil {
+		log.Fatalf("Error creating the audit log sink: %+v", err)
+	}
+	defer auditCloser.Close()
+	es = audit.NewEntityStore(es)
+
 	// Setup the policy enforcer
 	enforcer := identitymanager.SetupEnforcer(es)
 
 	// Create the identity controller
-	controller := identitymanager.NewIdentityController(es, enforcer)
-	defer controller.Shutdown()
-	controller.Start()
+	identityController := identitymanager.NewIdentityController(es, enforcer)
+	defer identityController.Shutdown()
+	identityController.Start()
 
-	handlers := identitymanager.NewHandlers(controller.Watcher(), es, enforcer)
+	handlers := identitymanager.NewHandlers(identityController.Watcher(), es, enforcer)
+	handlers.SetAuditSink(auditSink)
+	tokenIssuer, err := identitymanager.NewTokenIssuer(identitymanager.IdentityManagerFlags.TokenSigningKey)
+	if err != nil {
+		log.Fatalf("Error creating the token issuer: %+v", err)
+	}
+	handlers.SetTokenIssuer(tokenIssuer)
+	if issuer := identitymanager.IdentityManagerFlags.OIDCIssuer; issuer != "" {
+		provider, err := oidc.NewProvider(context.Background(), issuer, identitymanager.IdentityManagerFlags.OIDCClientID, nil)
+		if err != nil {
+			log.Fatalf("Error configuring the OpenID Connect provider: %+v", err)
+		}
+		handlers.SetOIDCProvider(provider)
+	}
+	setupToken, err := handlers.InitSetup(context.Background())
+	if err != nil {
+		log.Fatalf("Error preparing the setup: %+v", err)
+	}
+	if setupToken != "" {
+		log.Infof("Setup not completed yet, complete it with: dispatch manage bootstrap --setup-token %s", setupToken)
+	}
 	handlers.ConfigureHandlers(api)
 
 	healthChecker := func() error {
@@ -120,8 +159,9 @@ func main() {
 	opentracing.SetGlobalTracer(tracer)
 
 	handler := alice.New(
-		middleware.NewHealthCheckMW("", healthChecker),
+		middleware.NewHealthStatusMW("", healthChecker, controller.HealthStatus(identityController)),
 		middleware.NewTracingMW(tracer),
+		middleware.NewAuditMW("identity-manager", auditSink),
 	).Then(api.Serve(nil))
 
 	server.SetHandler(handler)
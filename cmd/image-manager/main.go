@@ -17,7 +17,9 @@ import (
 	"github.com/opentracing/opentracing-go"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/vmware/dispatch/pkg/audit"
 	"github.com/vmware/dispatch/pkg/config"
+	"github.com/vmware/dispatch/pkg/controller"
 	"github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/image-manager"
 	"github.com/vmware/dispatch/pkg/image-manager/gen/restapi"
@@ -34,6 +36,10 @@ var debugFlags = struct {
 	DebugEnabled bool `long:"debug" description:"Enable debugging messages"`
 }{}
 
+var auditFlags = audit.Flags{}
+
+var controllerFlags = controller.OwnershipFlags{}
+
 func configureFlags() []swag.CommandLineOptionsGroup {
 	return []swag.CommandLineOptionsGroup{
 		swag.CommandLineOptionsGroup{
@@ -46,6 +52,15 @@ func configureFlags() []swag.CommandLineOptionsGroup {
 			LongDescription:  "",
 			Options:          &debugFlags,
 		},
+		swag.CommandLineOptionsGroup{
+			ShortDescription: "Audit log options",
+			LongDescription:  "",
+			Options:          &auditFlags,
+		}, {
+			ShortDescription: "Controller options",
+			LongDescription:  "",
+			Options:          &controllerFlags,
+		},
 	}
 }
 
@@ -88,20 +103,35 @@ func main() {
 
 	config.Global = config.LoadConfiguration(imagemanager.ImageManagerFlags.Config)
 
-	es, err := entitystore.NewFromBackend(
-		entitystore.BackendConfig{
-			Backend:  imagemanager.ImageManagerFlags.DbBackend,
-			Address:  imagemanager.ImageManagerFlags.DbFile,
-			Bucket:   imagemanager.ImageManagerFlags.DbDatabase,
-			Username: imagemanager.ImageManagerFlags.DbUser,
-			Password: imagemanager.ImageManagerFlags.DbPassword,
-		})
+	dbConfig := entitystore.BackendConfig{
+		Backend:  imagemanager.ImageManagerFlags.DbBackend,
+		Address:  imagemanager.ImageManagerFlags.DbFile,
+		Bucket:   imagemanager.ImageManagerFlags.DbDatabase,
+		Username: imagemanager.ImageManagerFlags.DbUser,
+		Password: imagemanager.ImageManagerFlags.DbPassword,
+	}
+	es, err := entitystore.NewFromBackend(dbConfig)
 	if err != nil {
 		log.Fatalln(err)
 	}
 
+	// audit log, the store sink writes to the unwrapped store
+	auditSink, auditCloser, err := audit.NewSink(&auditFlags, es)
+	if err != nil {
+		log.Fatalf("Error creating the audit log sink: %+v", err)
+	}
+	defer auditCloser.Close()
+	es = audit.NewEntityStore(es)
+
+	// the replicas share the entities reconciled by the controller
+	ownership, err := controller.NewOwnership(&controllerFlags, "image-manager", dbConfig)
+	if err != nil {
+		log.Fatalf("Error creating the controller ownership: %+v", err)
+	}
+
 	c := &imagemanager.ControllerConfig{
 		ResyncPeriod: time.Duration(imagemanager.ImageManagerFlags.ResyncPeriod) * time.Second,
+		Ownership:    ownership,
 	}
 
 	registryAuth := config.Global.Registry.RegistryAuth
@@ -118,11 +148,11 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	controller := imagemanager.NewController(c, es, bib, ib)
-	defer controller.Shutdown()
-	controller.Start()
+	imageController := imagemanager.NewController(c, es, bib, ib)
+	defer imageController.Shutdown()
+	imageController.Start()
 
-	handlers := imagemanager.NewHandlers(ib, bib, controller.Watcher(), es)
+	handlers := imagemanager.NewHandlers(ib, bib, imageController.Watcher(), es)
 	handlers.ConfigureHandlers(api)
 
 	healthChecker := func() error {
@@ -138,8 +168,10 @@ func main() {
 	opentracing.SetGlobalTracer(tracer)
 
 	handler := alice.New(
-		middleware.NewHealthCheckMW("", healthChecker),
+		middleware.NewHealthStatusMW("", healthChecker, controller.HealthStatus(imageController)),
 		middleware.NewTracingMW(tracer),
+		middleware.NewAuditMW("image-manager", auditSink),
+		middleware.NewApplicationScopeMW(),
 	).Then(api.Serve(nil))
 
 	server.SetHandler(handler)
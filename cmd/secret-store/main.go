@@ -16,6 +16,7 @@ import (
 	"github.com/opentracing/opentracing-go"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/vmware/dispatch/pkg/audit"
 	"github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/middleware"
 	"github.com/vmware/dispatch/pkg/secret-store/gen/restapi"
@@ -32,6 +33,8 @@ var debugFlags = struct {
 	DebugEnabled bool `long:"debug" description:"Enable debugging messages"`
 }{}
 
+var auditFlags = audit.Flags{}
+
 func configureFlags() []swag.CommandLineOptionsGroup {
 	return []swag.CommandLineOptionsGroup{
 		swag.CommandLineOptionsGroup{
@@ -44,6 +47,11 @@ func configureFlags() []swag.CommandLineOptionsGroup {
 			LongDescription:  "",
 			Options:          &debugFlags,
 		},
+		swag.CommandLineOptionsGroup{
+			ShortDescription: "Audit log options",
+			LongDescription:  "",
+			Options:          &auditFlags,
+		},
 	}
 }
 
@@ -96,7 +104,26 @@ func main() {
 		log.Fatalln(err)
 	}
 
+	// audit log, the store sink writes to the unwrapped store
+	auditSink, auditCloser, err := audit.NewSink(&auditFlags, entityStore)
+	if err != nil {
+		log.Fatalf("Error creating the audit log sink: %+v", err)
+	}
+	defer auditCloser.Close()
+	entityStore = audit.NewEntityStore(entityStore)
+
 	handlers, err := web.NewHandlers(entityStore)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	handlers.Rotator.Start()
+	defer handlers.Rotator.Shutdown()
+	handlers.Auditor.Start()
+	defer handlers.Auditor.Shutdown()
+	if handlers.KeyRotator != nil {
+		handlers.KeyRotator.Start()
+		defer handlers.KeyRotator.Shutdown()
+	}
 
 	web.ConfigureHandlers(api, handlers)
 
@@ -115,6 +142,8 @@ func main() {
 	handler := alice.New(
 		middleware.NewHealthCheckMW("", healthChecker),
 		middleware.NewTracingMW(tracer),
+		middleware.NewAuditMW("secret-store", auditSink),
+		middleware.NewApplicationScopeMW(),
 	).Then(api.Serve(nil))
 
 	server.SetHandler(handler)
@@ -16,8 +16,11 @@ import (
 	"github.com/justinas/alice"
 	"github.com/opentracing/opentracing-go"
 	log "github.com/sirupsen/logrus"
+	"github.com/vmware/dispatch/pkg/controller"
 	"github.com/vmware/dispatch/pkg/utils"
 
+	"github.com/vmware/dispatch/pkg/audit"
+	"github.com/vmware/dispatch/pkg/client"
 	"github.com/vmware/dispatch/pkg/config"
 	"github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/middleware"
@@ -36,6 +39,10 @@ var debugFlags = struct {
 	DebugEnabled bool `long:"debug" description:"Enable debugging messages"`
 }{}
 
+var auditFlags = audit.Flags{}
+
+var controllerFlags = controller.OwnershipFlags{}
+
 func configureFlags() []swag.CommandLineOptionsGroup {
 	return []swag.CommandLineOptionsGroup{
 		{
@@ -48,6 +55,15 @@ func configureFlags() []swag.CommandLineOptionsGroup {
 			LongDescription:  "",
 			Options:          &debugFlags,
 		},
+		{
+			ShortDescription: "Audit log options",
+			LongDescription:  "",
+			Options:          &auditFlags,
+		}, {
+			ShortDescription: "Controller options",
+			LongDescription:  "",
+			Options:          &controllerFlags,
+		},
 	}
 }
 
@@ -88,36 +104,69 @@ func main() {
 
 	config.Global = config.LoadConfiguration(servicemanagerflags.ServiceManagerFlags.Config)
 
-	store, err := entitystore.NewFromBackend(
-		entitystore.BackendConfig{
-			Backend:  servicemanagerflags.ServiceManagerFlags.DbBackend,
-			Address:  servicemanagerflags.ServiceManagerFlags.DbFile,
-			Bucket:   servicemanagerflags.ServiceManagerFlags.DbDatabase,
-			Username: servicemanagerflags.ServiceManagerFlags.DbUser,
-			Password: servicemanagerflags.ServiceManagerFlags.DbPassword,
-		})
+	dbConfig := entitystore.BackendConfig{
+		Backend:  servicemanagerflags.ServiceManagerFlags.DbBackend,
+		Address:  servicemanagerflags.ServiceManagerFlags.DbFile,
+		Bucket:   servicemanagerflags.ServiceManagerFlags.DbDatabase,
+		Username: servicemanagerflags.ServiceManagerFlags.DbUser,
+		Password: servicemanagerflags.ServiceManagerFlags.DbPassword,
+	}
+	store, err := entitystore.NewFromBackend(dbConfig)
 	if err != nil {
 		log.Fatalln(err)
 	}
 
-	k8sClient, err := clients.NewK8sBrokerClient(
-		clients.K8sBrokerConfigOpts{
-			K8sConfig:        servicemanagerflags.ServiceManagerFlags.K8sConfig,
-			CatalogNamespace: config.Global.Service.K8sServiceCatalog.CatalogNamespace,
-			SecretStoreURL:   servicemanagerflags.ServiceManagerFlags.SecretStore,
-			OrgID:            servicemanagerflags.ServiceManagerFlags.OrgID,
-		},
-	)
+	// audit log, the store sink writes to the unwrapped store
+	auditSink, auditCloser, err := audit.NewSink(&auditFlags, store)
 	if err != nil {
-		log.Fatalf("Error creating k8sClient: %v", err)
+		log.Fatalf("Error creating the audit log sink: %+v", err)
+	}
+	defer auditCloser.Close()
+	store = audit.NewEntityStore(store)
+
+	// the replicas share the entities reconciled by the controller
+	ownership, err := controller.NewOwnership(&controllerFlags, "service-manager", dbConfig)
+	if err != nil {
+		log.Fatalf("Error creating the controller ownership: %+v", err)
+	}
+
+	var brokerClient clients.BrokerClient
+	switch config.Global.Service.Catalog {
+	case clients.CatalogOSB:
+		brokerClient, err = clients.NewOSBBrokerClient(
+			clients.OSBBrokerConfigOpts{
+				Store:          store,
+				SecretStoreURL: servicemanagerflags.ServiceManagerFlags.SecretStore,
+				OrgID:          servicemanagerflags.ServiceManagerFlags.OrgID,
+			},
+		)
+		if err != nil {
+			log.Fatalf("Error creating the Open Service Broker API client: %v", err)
+		}
+	case clients.CatalogK8sServiceCatalog, "":
+		brokerClient, err = clients.NewK8sBrokerClient(
+			clients.K8sBrokerConfigOpts{
+				K8sConfig:        servicemanagerflags.ServiceManagerFlags.K8sConfig,
+				CatalogNamespace: config.Global.Service.K8sServiceCatalog.CatalogNamespace,
+				SecretStoreURL:   servicemanagerflags.ServiceManagerFlags.SecretStore,
+				OrgID:            servicemanagerflags.ServiceManagerFlags.OrgID,
+			},
+		)
+		if err != nil {
+			log.Fatalf("Error creating k8sClient: %v", err)
+		}
+	default:
+		log.Fatalf("Service catalog %s is not supported, pick one of [%s,%s]", config.Global.Service.Catalog, clients.CatalogK8sServiceCatalog, clients.CatalogOSB)
 	}
 	// service controller
 	serviceController := servicemanager.NewController(
 		&servicemanager.ControllerConfig{
-			ResyncPeriod: time.Second * time.Duration(servicemanagerflags.ServiceManagerFlags.ResyncPeriod),
+			ResyncPeriod:        time.Second * time.Duration(servicemanagerflags.ServiceManagerFlags.ResyncPeriod),
+			Ownership:           ownership,
+			BrokerRefreshPeriod: time.Second * time.Duration(servicemanagerflags.ServiceManagerFlags.BrokerRefreshPeriod),
 		},
 		store,
-		k8sClient,
+		brokerClient,
 	)
 
 	defer serviceController.Shutdown()
@@ -125,8 +174,9 @@ func main() {
 
 	// handler
 	handlers := &servicemanager.Handlers{
-		Store:   store,
-		Watcher: serviceController.Watcher(),
+		Store:         store,
+		Watcher:       serviceController.Watcher(),
+		SecretsClient: client.NewSecretsClient(servicemanagerflags.ServiceManagerFlags.SecretStore, client.AuthWithToken("cookie"), ""),
 	}
 
 	handlers.ConfigureHandlers(api)
@@ -144,8 +194,10 @@ func main() {
 	opentracing.SetGlobalTracer(tracer)
 
 	handler := alice.New(
-		middleware.NewHealthCheckMW("", healthChecker),
+		middleware.NewHealthStatusMW("", healthChecker, controller.HealthStatus(serviceController)),
 		middleware.NewTracingMW(tracer),
+		middleware.NewAuditMW("service-manager", auditSink),
+		middleware.NewApplicationScopeMW(),
 	).Then(api.Serve(nil))
 
 	server.SetHandler(handler)
@@ -7,27 +7,58 @@ package apimanager
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"reflect"
+	"strings"
 	"time"
 
 	ewrapper "github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/vmware/dispatch/pkg/api-manager/gateway"
+	"github.com/vmware/dispatch/pkg/api-manager/issuer"
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
 	"github.com/vmware/dispatch/pkg/controller"
 	"github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/errors"
 	"github.com/vmware/dispatch/pkg/trace"
 )
 
+const (
+	// keys of the certificate and private key in a certificate secret
+	certSecretKey = "cert"
+	keySecretKey  = "key"
+)
+
 // ControllerConfig defines configuration for controller
 type ControllerConfig struct {
 	ResyncPeriod time.Duration
+	// ExpiryWarning is how long before expiry a certificate is considered as expiring
+	ExpiryWarning time.Duration
+	// Ownership decides which replica reconciles the APIs and certificates, nil with a single replica
+	Ownership controller.Ownership
+}
+
+// settledFilter selects entities which are settled, i.e. not waiting to be processed
+func settledFilter() entitystore.Filter {
+	return entitystore.FilterEverything().Add(
+		entitystore.FilterStat{
+			Scope:   entitystore.FilterScopeField,
+			Subject: "Status",
+			Verb:    entitystore.FilterVerbIn,
+			Object: []entitystore.Status{
+				entitystore.StatusREADY, entitystore.StatusERROR,
+			},
+		})
 }
 
 type apiEntityHandler struct {
-	store entitystore.EntityStore
-	gw    gateway.Gateway
+	store         entitystore.EntityStore
+	gw            gateway.Gateway
+	secrets       client.SecretsClient
+	expiryWarning time.Duration
 }
 
 func (h *apiEntityHandler) Type() reflect.Type {
@@ -48,13 +79,87 @@ func (h *apiEntityHandler) Add(ctx context.Context, obj entitystore.Entity) (err
 		return ewrapper.Wrap(err, "gateway error when adding api")
 	}
 	log.Infof("api %s added by gateway", api.Name)
-	api.Status = entitystore.StatusREADY
 	api.API.ID = gwAPI.ID
 	api.API.CreatedAt = gwAPI.CreatedAt
 
+	status, reason := h.certificateState(ctx, api)
+	if status == entitystore.StatusERROR {
+		log.Warnf("api %s: %s", api.Name, reason[0])
+		return ewrapper.New(reason[0])
+	}
+	if reason != nil {
+		log.Warnf("api %s: %s", api.Name, reason[0])
+	}
+	api.Status = entitystore.StatusREADY
+	api.Reason = reason
+
 	return nil
 }
 
+// certificateState returns the state of an api as decided by its certificate
+func (h *apiEntityHandler) certificateState(ctx context.Context, api *API) (entitystore.Status, entitystore.Reason) {
+	if api.API.TLS == "" {
+		return entitystore.StatusREADY, nil
+	}
+	var cert Certificate
+	if err := h.store.Get(ctx, api.OrganizationID, api.API.TLS, entitystore.Options{}, &cert); err != nil {
+		if !h.convertSecret(ctx, api) {
+			return apiState(nil, api.API.TLS, h.expiryWarning)
+		}
+		// just created, not ready yet
+		cert = Certificate{}
+	}
+	return apiState(&cert, api.API.TLS, h.expiryWarning)
+}
+
+// convertSecret creates a certificate out of the secret named by the tls of an api, which used to name a secret
+// before certificates existed. Returns false if there is no such secret.
+func (h *apiEntityHandler) convertSecret(ctx context.Context, api *API) bool {
+	if h.secrets == nil {
+		return false
+	}
+	if _, err := h.secrets.GetSecret(ctx, api.OrganizationID, api.API.TLS); err != nil {
+		return false
+	}
+	cert := &Certificate{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: api.OrganizationID,
+			Name:           api.API.TLS,
+			Status:         entitystore.StatusCREATING,
+		},
+		Hosts:  api.API.Hosts,
+		Secret: api.API.TLS,
+	}
+	if _, err := h.store.Add(ctx, cert); err != nil && !entitystore.IsUniqueViolation(err) {
+		log.Errorf("Error creating certificate %s from the secret of api %s: %v", cert.Name, api.Name, err)
+		return false
+	}
+	log.Infof("certificate %s created from the secret of api %s", cert.Name, api.Name)
+	return true
+}
+
+// apiState returns the state of an api using a certificate, cert is nil if the certificate is not found. A missing
+// or expired certificate puts the api in error, otherwise the api is ready and a certificate not ready yet or
+// about to expire is only reported.
+func apiState(cert *Certificate, name string, warning time.Duration) (entitystore.Status, entitystore.Reason) {
+	switch {
+	case cert == nil:
+		return entitystore.StatusERROR, entitystore.Reason{"certificate " + name + " not found"}
+	case cert.NotAfter.IsZero():
+		return entitystore.StatusREADY, entitystore.Reason{"certificate " + name + " is not ready"}
+	case time.Now().After(cert.NotAfter):
+		return entitystore.StatusERROR, entitystore.Reason{"certificate " + name + " expired at " + cert.NotAfter.Format(time.RFC3339)}
+	case expiring(cert.NotAfter, warning):
+		return entitystore.StatusREADY, entitystore.Reason{"certificate " + name + " expires at " + cert.NotAfter.Format(time.RFC3339)}
+	}
+	return entitystore.StatusREADY, nil
+}
+
+// isCertificateReason returns true if the reason of an api is about its certificate
+func isCertificateReason(reason entitystore.Reason) bool {
+	return len(reason) > 0 && strings.HasPrefix(reason[0], "certificate ")
+}
+
 // Update is the handler for updating API endpoints
 func (h *apiEntityHandler) Update(ctx context.Context, obj entitystore.Entity) error {
 	span, ctx := trace.Trace(ctx, "")
@@ -112,6 +217,11 @@ func (h *apiEntityHandler) Error(ctx context.Context, obj entitystore.Entity) er
 		return ewrapper.New("type assertion error")
 	}
 
+	// the api is fine in the gateway when only its certificate is wrong
+	if isCertificateReason(api.Reason) {
+		return h.Update(ctx, api)
+	}
+
 	// delete the underlying api
 	err := h.gw.DeleteAPI(ctx, &api.API)
 	if err != nil {
@@ -122,12 +232,245 @@ func (h *apiEntityHandler) Error(ctx context.Context, obj entitystore.Entity) er
 	return h.Update(ctx, api)
 }
 
+type certificateEntityHandler struct {
+	store         entitystore.EntityStore
+	gw            gateway.Gateway
+	secrets       client.SecretsClient
+	issuer        issuer.Issuer
+	expiryWarning time.Duration
+}
+
+func (h *certificateEntityHandler) Type() reflect.Type {
+	return reflect.TypeOf(&Certificate{})
+}
+
+// Add is the handler for creating certificates
+func (h *certificateEntityHandler) Add(ctx context.Context, obj entitystore.Entity) (err error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	cert := obj.(*Certificate)
+
+	defer func() {
+		h.store.UpdateWithError(ctx, cert, err)
+		h.refreshAPIs(ctx, cert.OrganizationID, cert.Name, cert)
+	}()
+
+	keyPair, parsed, err := h.loadKeyPair(ctx, cert)
+	if err != nil {
+		return err
+	}
+	if time.Now().After(parsed.NotAfter) {
+		return ewrapper.Errorf("certificate expired at %s", parsed.NotAfter.Format(time.RFC3339))
+	}
+
+	gwCert := &gateway.Certificate{
+		ID:   cert.GatewayID,
+		Cert: keyPair.Secrets[certSecretKey],
+		Key:  keyPair.Secrets[keySecretKey],
+		SNIs: cert.Hosts,
+	}
+	var result *gateway.Certificate
+	if cert.GatewayID != "" {
+		result, err = h.gw.UpdateCertificate(ctx, gwCert)
+		if _, ok := err.(*errors.ObjectNotFoundError); ok {
+			// removed from the gateway behind our back, add it again
+			result, err = h.gw.AddCertificate(ctx, gwCert)
+		}
+	} else {
+		result, err = h.gw.AddCertificate(ctx, gwCert)
+	}
+	if err != nil {
+		return ewrapper.Wrap(err, "gateway error when adding certificate")
+	}
+	log.Infof("certificate %s added by gateway", cert.Name)
+
+	cert.GatewayID = result.ID
+	cert.Issuer = parsed.Issuer.CommonName
+	cert.NotAfter = parsed.NotAfter
+	cert.Status = entitystore.StatusREADY
+	cert.Reason = nil
+	if expiring(cert.NotAfter, h.expiryWarning) {
+		log.Warnf("certificate %s expires at %s", cert.Name, cert.NotAfter.Format(time.RFC3339))
+		cert.Reason = entitystore.Reason{"certificate expires at " + cert.NotAfter.Format(time.RFC3339)}
+	}
+	return nil
+}
+
+// loadKeyPair reads the certificate and key from the secret store, issuing (or renewing) them first when
+// the certificate is managed through ACME
+func (h *certificateEntityHandler) loadKeyPair(ctx context.Context, cert *Certificate) (*v1.Secret, *x509.Certificate, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	secret, err := h.secrets.GetSecret(ctx, cert.OrganizationID, cert.Secret)
+	if err != nil && !cert.ACME {
+		return nil, nil, ewrapper.Wrapf(err, "error getting secret %s", cert.Secret)
+	}
+	var parsed *x509.Certificate
+	if secret != nil {
+		parsed, err = parseKeyPair(secret.Secrets[certSecretKey], secret.Secrets[keySecretKey])
+		if err != nil && !cert.ACME {
+			return nil, nil, ewrapper.Wrapf(err, "invalid certificate in secret %s", cert.Secret)
+		}
+	}
+	if !cert.ACME || (parsed != nil && !expiring(parsed.NotAfter, h.expiryWarning)) {
+		return secret, parsed, nil
+	}
+
+	if h.issuer == nil {
+		return nil, nil, ewrapper.New("no acme directory configured")
+	}
+	certPEM, keyPEM, err := h.issuer.Issue(ctx, cert.Hosts)
+	if err != nil {
+		return nil, nil, ewrapper.Wrap(err, "error issuing certificate")
+	}
+	log.Infof("certificate %s issued for %s", cert.Name, strings.Join(cert.Hosts, ", "))
+	values := v1.SecretValue{
+		certSecretKey: string(certPEM),
+		keySecretKey:  string(keyPEM),
+	}
+	if secret == nil {
+		secret, err = h.secrets.CreateSecret(ctx, cert.OrganizationID, &v1.Secret{Name: &cert.Secret, Secrets: values})
+	} else {
+		secret.Secrets = values
+		secret, err = h.secrets.UpdateSecret(ctx, cert.OrganizationID, secret)
+	}
+	if err != nil {
+		return nil, nil, ewrapper.Wrapf(err, "error storing issued certificate in secret %s", cert.Secret)
+	}
+	parsed, err = parseKeyPair(string(certPEM), string(keyPEM))
+	if err != nil {
+		return nil, nil, ewrapper.Wrap(err, "invalid issued certificate")
+	}
+	return secret, parsed, nil
+}
+
+// Update is the handler for updating certificates
+func (h *certificateEntityHandler) Update(ctx context.Context, obj entitystore.Entity) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	return h.Add(ctx, obj)
+}
+
+// Delete is the handler for deleting certificates
+func (h *certificateEntityHandler) Delete(ctx context.Context, obj entitystore.Entity) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	cert, ok := obj.(*Certificate)
+	if !ok {
+		return ewrapper.New("type assertion error")
+	}
+	if cert.GatewayID != "" {
+		if err := h.gw.DeleteCertificate(ctx, &gateway.Certificate{ID: cert.GatewayID}); err != nil {
+			if _, ok := err.(*errors.ObjectNotFoundError); !ok {
+				return ewrapper.Wrap(err, "gateway error when deleting certificate")
+			}
+		}
+	}
+	if err := h.store.Delete(ctx, cert.OrganizationID, cert.Name, cert); err != nil {
+		return ewrapper.Wrap(err, "store error when deleting certificate")
+	}
+	log.Infof("certificate %s deleted by gateway and store", cert.Name)
+	h.refreshAPIs(ctx, cert.OrganizationID, cert.Name, nil)
+	return nil
+}
+
+// refreshAPIs marks the apis using a certificate updated when the certificate changes their state, so they are
+// ready, reported or in error according to the certificate. cert is nil when the certificate is deleted.
+func (h *certificateEntityHandler) refreshAPIs(ctx context.Context, organizationID, name string, cert *Certificate) {
+	var apis []*API
+	if err := h.store.List(ctx, organizationID, entitystore.Options{}, &apis); err != nil {
+		log.Errorf("Error listing the apis of certificate %s: %v", name, err)
+		return
+	}
+	status, reason := apiState(cert, name, h.expiryWarning)
+	for _, api := range apis {
+		if api.API.TLS != name || api.Delete {
+			continue
+		}
+		switch {
+		case api.Status == entitystore.StatusREADY:
+		case api.Status == entitystore.StatusERROR && isCertificateReason(api.Reason):
+		default:
+			continue
+		}
+		if api.Status == status && reflect.DeepEqual(api.Reason, reason) {
+			continue
+		}
+		api.SetStatus(entitystore.StatusUPDATING)
+		if _, err := h.store.Update(ctx, api.Revision, api); err != nil {
+			log.Errorf("Error refreshing api %s of certificate %s: %v", api.Name, name, err)
+		}
+	}
+}
+
+// Sync returns certificates which need to be resolved, as well as certificates about to expire or expired, whose
+// apis are refreshed once they are processed
+func (h *certificateEntityHandler) Sync(ctx context.Context, resyncPeriod time.Duration) ([]entitystore.Entity, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	entities, err := controller.DefaultSync(ctx, h.store, h.Type(), resyncPeriod, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	settled, err := controller.DefaultSync(ctx, h.store, h.Type(), resyncPeriod, settledFilter())
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range settled {
+		cert := e.(*Certificate)
+		if cert.Status != entitystore.StatusREADY || !expiring(cert.NotAfter, h.expiryWarning) {
+			continue
+		}
+		// ACME certificates are renewed, the others are reported once when about to expire and once expired
+		if cert.ACME || len(cert.Reason) == 0 || time.Now().After(cert.NotAfter) {
+			entities = append(entities, cert)
+		}
+	}
+	return entities, nil
+}
+
+// Error handles errors while adding certificates
+func (h *certificateEntityHandler) Error(ctx context.Context, obj entitystore.Entity) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	return h.Update(ctx, obj)
+}
+
+// parseKeyPair verifies that the certificate matches the key, and returns the parsed leaf certificate
+func parseKeyPair(certPEM, keyPEM string) (*x509.Certificate, error) {
+	keyPair, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(keyPair.Certificate[0])
+}
+
+func expiring(notAfter time.Time, warning time.Duration) bool {
+	return time.Now().Add(warning).After(notAfter)
+}
+
 // NewController creates a new controller
-func NewController(config *ControllerConfig, store entitystore.EntityStore, gw gateway.Gateway) controller.Controller {
+func NewController(config *ControllerConfig, store entitystore.EntityStore, gw gateway.Gateway, secrets client.SecretsClient, iss issuer.Issuer) controller.Controller {
 	c := controller.NewController(controller.Options{
 		ResyncPeriod: config.ResyncPeriod,
+		Store:        store,
+		Ownership:    config.Ownership,
 	})
 
-	c.AddEntityHandler(&apiEntityHandler{store: store, gw: gw})
+	c.AddEntityHandler(&apiEntityHandler{store: store, gw: gw, secrets: secrets, expiryWarning: config.ExpiryWarning})
+	c.AddEntityHandler(&certificateEntityHandler{
+		store:         store,
+		gw:            gw,
+		secrets:       secrets,
+		issuer:        iss,
+		expiryWarning: config.ExpiryWarning,
+	})
 	return c
 }
@@ -7,14 +7,25 @@ package apimanager
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
+	"math/big"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/vmware/dispatch/pkg/api-manager/gateway"
 	"github.com/vmware/dispatch/pkg/api-manager/gateway/mocks"
+	"github.com/vmware/dispatch/pkg/api-manager/issuer"
+	"github.com/vmware/dispatch/pkg/api/v1"
+	clientmocks "github.com/vmware/dispatch/pkg/client/mocks"
 	"github.com/vmware/dispatch/pkg/controller"
 	entitystore "github.com/vmware/dispatch/pkg/entity-store"
 	helpers "github.com/vmware/dispatch/pkg/testing/api"
@@ -28,13 +39,50 @@ const (
 
 func getTestController(t *testing.T, es entitystore.EntityStore, gw gateway.Gateway) (controller.Controller, controller.Watcher) {
 
+	return getTestCertController(t, es, gw, &clientmocks.SecretsClient{}, nil)
+}
+
+func getTestCertController(t *testing.T, es entitystore.EntityStore, gw gateway.Gateway, secrets *clientmocks.SecretsClient, iss issuer.Issuer) (controller.Controller, controller.Watcher) {
+
 	config := &ControllerConfig{
-		ResyncPeriod: testResyncPeriod,
+		ResyncPeriod:  testResyncPeriod,
+		ExpiryWarning: 30 * 24 * time.Hour,
 	}
-	ctrl := NewController(config, es, gw)
+	ctrl := NewController(config, es, gw, secrets, iss)
 	return ctrl, ctrl.Watcher()
 }
 
+// selfSigned generates a PEM encoded self-signed certificate and key valid until notAfter
+func selfSigned(t *testing.T, host string, notAfter time.Time) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+type fakeIssuer struct {
+	t        *testing.T
+	notAfter time.Time
+	issued   []string
+}
+
+func (i *fakeIssuer) Issue(ctx context.Context, hosts []string) ([]byte, []byte, error) {
+	i.issued = append(i.issued, hosts...)
+	cert, key := selfSigned(i.t, hosts[0], i.notAfter)
+	return []byte(cert), []byte(key), nil
+}
+
 func TestCtrlUpdateAPI(t *testing.T) {
 
 	testAddAPI := &API{
@@ -210,3 +258,252 @@ func TestCtrlDeleteAPIAsync(t *testing.T) {
 	err = es.Get(context.Background(), testOrgID, testDelAPIAsync.Name, entitystore.Options{}, &entity)
 	assert.NotNil(t, err)
 }
+
+func TestCtrlAddCertificate(t *testing.T) {
+	certPEM, keyPEM := selfSigned(t, "www.example.com", time.Now().Add(90*24*time.Hour))
+
+	mockedSecrets := &clientmocks.SecretsClient{}
+	mockedSecrets.On("GetSecret", mock.Anything, testOrgID, "example-tls").Return(&v1.Secret{
+		Secrets: v1.SecretValue{"cert": certPEM, "key": keyPEM},
+	}, nil)
+	mockedGateway := &mocks.Gateway{}
+	mockedGateway.On("AddCertificate", mock.Anything, mock.MatchedBy(func(c *gateway.Certificate) bool {
+		return c.Cert == certPEM && c.Key == keyPEM && c.SNIs[0] == "www.example.com"
+	})).Return(&gateway.Certificate{ID: "kong-cert-id"}, nil)
+	es := helpers.MakeEntityStore(t)
+
+	ctrl, watcher := getTestCertController(t, es, mockedGateway, mockedSecrets, nil)
+	ctrl.Start()
+	defer ctrl.Shutdown()
+
+	testCert := &Certificate{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: testOrgID,
+			Name:           "testAddCert",
+			Status:         entitystore.StatusCREATING,
+		},
+		Hosts:  []string{"www.example.com"},
+		Secret: "example-tls",
+	}
+	_, err := es.Add(context.Background(), testCert)
+	assert.Nil(t, err)
+	watcher.OnAction(context.Background(), testCert)
+
+	time.Sleep(testSleepDuration)
+
+	var actual Certificate
+	es.Get(context.Background(), testOrgID, testCert.Name, entitystore.Options{}, &actual)
+	assert.Equal(t, entitystore.StatusREADY, actual.Status)
+	assert.Equal(t, "kong-cert-id", actual.GatewayID)
+	assert.Equal(t, "www.example.com", actual.Issuer)
+	assert.False(t, actual.NotAfter.IsZero())
+	assert.Empty(t, actual.Reason)
+}
+
+func TestCtrlAddCertificateExpiring(t *testing.T) {
+	certPEM, keyPEM := selfSigned(t, "www.example.com", time.Now().Add(24*time.Hour))
+
+	mockedSecrets := &clientmocks.SecretsClient{}
+	mockedSecrets.On("GetSecret", mock.Anything, testOrgID, "example-tls").Return(&v1.Secret{
+		Secrets: v1.SecretValue{"cert": certPEM, "key": keyPEM},
+	}, nil)
+	mockedGateway := &mocks.Gateway{}
+	mockedGateway.On("AddCertificate", mock.Anything, mock.Anything).Return(&gateway.Certificate{ID: "kong-cert-id"}, nil)
+	mockedGateway.On("UpdateAPI", mock.Anything, "testCertAPI", mock.Anything).Return(&gateway.API{ID: "kong-api-id"}, nil)
+	es := helpers.MakeEntityStore(t)
+
+	ctrl, watcher := getTestCertController(t, es, mockedGateway, mockedSecrets, nil)
+	ctrl.Start()
+	defer ctrl.Shutdown()
+
+	testCert := &Certificate{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: testOrgID,
+			Name:           "testExpiringCert",
+			Status:         entitystore.StatusCREATING,
+		},
+		Hosts:  []string{"www.example.com"},
+		Secret: "example-tls",
+	}
+	_, err := es.Add(context.Background(), testCert)
+	assert.Nil(t, err)
+	watcher.OnAction(context.Background(), testCert)
+
+	time.Sleep(testSleepDuration)
+
+	var actual Certificate
+	es.Get(context.Background(), testOrgID, testCert.Name, entitystore.Options{}, &actual)
+	assert.Equal(t, entitystore.StatusREADY, actual.Status)
+	assert.Len(t, actual.Reason, 1)
+
+	// apis using an expiring certificate are reported, and stay ready
+	testAPI := &API{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: testOrgID,
+			Name:           "testCertAPI",
+			Status:         entitystore.StatusCREATING,
+		},
+		API: gateway.API{
+			Name:     "testCertAPI",
+			Function: "testCertAPIFunc",
+			Hosts:    []string{"www.example.com"},
+			TLS:      "testExpiringCert",
+		},
+	}
+	_, err = es.Add(context.Background(), testAPI)
+	assert.Nil(t, err)
+	watcher.OnAction(context.Background(), testAPI)
+
+	time.Sleep(testSleepDuration)
+
+	var actualAPI API
+	es.Get(context.Background(), testOrgID, testAPI.Name, entitystore.Options{}, &actualAPI)
+	assert.Equal(t, entitystore.StatusREADY, actualAPI.Status)
+	assert.Contains(t, actualAPI.Reason[0], "certificate testExpiringCert expires at")
+	mockedGateway.AssertNotCalled(t, "DeleteAPI", mock.Anything, mock.Anything)
+}
+
+func TestCtrlAddAPILegacySecret(t *testing.T) {
+	certPEM, keyPEM := selfSigned(t, "www.example.com", time.Now().Add(90*24*time.Hour))
+
+	mockedSecrets := &clientmocks.SecretsClient{}
+	mockedSecrets.On("GetSecret", mock.Anything, testOrgID, "legacy-tls").Return(&v1.Secret{
+		Secrets: v1.SecretValue{"cert": certPEM, "key": keyPEM},
+	}, nil)
+	mockedGateway := &mocks.Gateway{}
+	mockedGateway.On("AddCertificate", mock.Anything, mock.Anything).Return(&gateway.Certificate{ID: "kong-cert-id"}, nil)
+	mockedGateway.On("UpdateAPI", mock.Anything, "testLegacyAPI", mock.Anything).Return(&gateway.API{ID: "kong-api-id"}, nil)
+	es := helpers.MakeEntityStore(t)
+
+	ctrl, watcher := getTestCertController(t, es, mockedGateway, mockedSecrets, nil)
+	ctrl.Start()
+	defer ctrl.Shutdown()
+
+	// the tls of an api created before certificates names a secret
+	testAPI := &API{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: testOrgID,
+			Name:           "testLegacyAPI",
+			Status:         entitystore.StatusUPDATING,
+		},
+		API: gateway.API{
+			Name:     "testLegacyAPI",
+			Function: "testLegacyAPIFunc",
+			Hosts:    []string{"www.example.com"},
+			TLS:      "legacy-tls",
+		},
+	}
+	_, err := es.Add(context.Background(), testAPI)
+	assert.Nil(t, err)
+	watcher.OnAction(context.Background(), testAPI)
+
+	// a certificate is created from the secret, then the api is refreshed once the certificate is ready
+	time.Sleep(3 * testSleepDuration)
+
+	var actual Certificate
+	es.Get(context.Background(), testOrgID, "legacy-tls", entitystore.Options{}, &actual)
+	assert.Equal(t, entitystore.StatusREADY, actual.Status)
+	assert.Equal(t, "legacy-tls", actual.Secret)
+	assert.Equal(t, []string{"www.example.com"}, actual.Hosts)
+
+	var actualAPI API
+	es.Get(context.Background(), testOrgID, testAPI.Name, entitystore.Options{}, &actualAPI)
+	assert.Equal(t, entitystore.StatusREADY, actualAPI.Status)
+	assert.Empty(t, actualAPI.Reason)
+}
+
+func TestCtrlAddCertificateACME(t *testing.T) {
+	mockedSecrets := &clientmocks.SecretsClient{}
+	mockedSecrets.On("GetSecret", mock.Anything, testOrgID, "acme-tls").Return(nil, errors.New("not found"))
+	mockedSecrets.On("CreateSecret", mock.Anything, testOrgID, mock.Anything).Return(
+		func(ctx context.Context, organizationID string, secret *v1.Secret) *v1.Secret {
+			return secret
+		}, nil)
+	mockedGateway := &mocks.Gateway{}
+	mockedGateway.On("AddCertificate", mock.Anything, mock.Anything).Return(&gateway.Certificate{ID: "kong-cert-id"}, nil)
+	es := helpers.MakeEntityStore(t)
+
+	iss := &fakeIssuer{t: t, notAfter: time.Now().Add(90 * 24 * time.Hour)}
+	ctrl, watcher := getTestCertController(t, es, mockedGateway, mockedSecrets, iss)
+	ctrl.Start()
+	defer ctrl.Shutdown()
+
+	testCert := &Certificate{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: testOrgID,
+			Name:           "testACMECert",
+			Status:         entitystore.StatusCREATING,
+		},
+		Hosts:  []string{"acme.example.com"},
+		Secret: "acme-tls",
+		ACME:   true,
+	}
+	_, err := es.Add(context.Background(), testCert)
+	assert.Nil(t, err)
+	watcher.OnAction(context.Background(), testCert)
+
+	time.Sleep(testSleepDuration)
+
+	var actual Certificate
+	es.Get(context.Background(), testOrgID, testCert.Name, entitystore.Options{}, &actual)
+	assert.Equal(t, entitystore.StatusREADY, actual.Status)
+	assert.Equal(t, []string{"acme.example.com"}, iss.issued)
+	mockedSecrets.AssertCalled(t, "CreateSecret", mock.Anything, testOrgID, mock.Anything)
+}
+
+func TestCtrlDeleteCertificate(t *testing.T) {
+	mockedGateway := &mocks.Gateway{}
+	mockedGateway.On("DeleteCertificate", mock.Anything, &gateway.Certificate{ID: "kong-cert-id"}).Return(nil)
+	mockedGateway.On("UpdateAPI", mock.Anything, "testDelCertAPI", mock.Anything).Return(&gateway.API{ID: "kong-api-id"}, nil)
+	mockedSecrets := &clientmocks.SecretsClient{}
+	mockedSecrets.On("GetSecret", mock.Anything, testOrgID, "testDelCert").Return(nil, errors.New("not found"))
+	es := helpers.MakeEntityStore(t)
+
+	ctrl, watcher := getTestCertController(t, es, mockedGateway, mockedSecrets, nil)
+	ctrl.Start()
+	defer ctrl.Shutdown()
+
+	testAPI := &API{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: testOrgID,
+			Name:           "testDelCertAPI",
+			Status:         entitystore.StatusREADY,
+		},
+		API: gateway.API{
+			Name:     "testDelCertAPI",
+			Function: "testDelCertAPIFunc",
+			TLS:      "testDelCert",
+		},
+	}
+	_, err := es.Add(context.Background(), testAPI)
+	assert.Nil(t, err)
+
+	testCert := &Certificate{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: testOrgID,
+			Name:           "testDelCert",
+			Status:         entitystore.StatusDELETING,
+		},
+		Hosts:     []string{"www.example.com"},
+		Secret:    "example-tls",
+		GatewayID: "kong-cert-id",
+	}
+	_, err = es.Add(context.Background(), testCert)
+	assert.Nil(t, err)
+	watcher.OnAction(context.Background(), testCert)
+
+	time.Sleep(testSleepDuration)
+
+	var entity Certificate
+	err = es.Get(context.Background(), testOrgID, testCert.Name, entitystore.Options{}, &entity)
+	assert.NotNil(t, err)
+
+	// apis using a deleted certificate are in error
+	time.Sleep(3 * testSleepDuration)
+
+	var actualAPI API
+	es.Get(context.Background(), testOrgID, testAPI.Name, entitystore.Options{}, &actualAPI)
+	assert.Equal(t, entitystore.StatusERROR, actualAPI.Status)
+	assert.Equal(t, entitystore.Reason{"certificate testDelCert not found"}, actualAPI.Reason)
+	mockedGateway.AssertExpectations(t)
+}
@@ -8,6 +8,8 @@ package apimanager
 // NO TEST
 
 import (
+	"time"
+
 	"github.com/vmware/dispatch/pkg/api-manager/gateway"
 	entitystore "github.com/vmware/dispatch/pkg/entity-store"
 )
@@ -17,3 +19,15 @@ type API struct {
 	entitystore.BaseEntity
 	API gateway.API `db:"api"`
 }
+
+// Certificate is a data struct used to store tls certificate information into entity store,
+// the certificate and key themselves are kept in the secret store
+type Certificate struct {
+	entitystore.BaseEntity
+	Hosts     []string  `json:"hosts"`
+	Secret    string    `json:"secret"`
+	ACME      bool      `json:"acme"`
+	GatewayID string    `json:"gatewayId"`
+	Issuer    string    `json:"issuer"`
+	NotAfter  time.Time `json:"notAfter"`
+}
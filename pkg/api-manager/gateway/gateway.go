@@ -29,17 +29,32 @@ type API struct {
 	// i.e. http https
 	Protocols []string `json:"protocols,omitempty"`
 
-	// reference to tls certificates (a dispatch secret name)
-	// TODO: will be replaced by SNI objects
+	// reference to the tls certificate (a certificate name, formerly a secret name)
 	TLS string `json:"tls,omitempty"`
 
 	CORS bool `json:"cors,omitempty"`
 }
 
+// Certificate represents a tls certificate and the SNI host names it is served for
+type Certificate struct {
+	ID        string `json:"id,omitempty"`
+	CreatedAt int    `json:"created_at,omitempty"`
+
+	// PEM encoded certificate (chain) and private key
+	Cert string `json:"cert,omitempty"`
+	Key  string `json:"key,omitempty"`
+
+	SNIs []string `json:"snis,omitempty"`
+}
+
 // Gateway defines interfaces the underlying API Gateway provides
 type Gateway interface {
 	AddAPI(ctx context.Context, api *API) (*API, error)
 	GetAPI(ctx context.Context, name string) (*API, error)
 	UpdateAPI(ctx context.Context, name string, api *API) (*API, error)
 	DeleteAPI(ctx context.Context, api *API) error
+
+	AddCertificate(ctx context.Context, cert *Certificate) (*Certificate, error)
+	UpdateCertificate(ctx context.Context, cert *Certificate) (*Certificate, error)
+	DeleteCertificate(ctx context.Context, cert *Certificate) error
 }
@@ -22,6 +22,7 @@ import (
 	ewrapper "github.com/pkg/errors"
 
 	"github.com/vmware/dispatch/pkg/api-manager/gateway"
+	"github.com/vmware/dispatch/pkg/client"
 	"github.com/vmware/dispatch/pkg/errors"
 	"github.com/vmware/dispatch/pkg/trace"
 )
@@ -85,6 +86,21 @@ type Plugin struct {
 	Enabled bool                   `json:"enabled,omitempty"`
 }
 
+// Certificate is a struct for Kong Certificate
+type Certificate struct {
+	ID        string   `json:"id,omitempty"`
+	CreatedAt int      `json:"created_at,omitempty"`
+	Cert      string   `json:"cert,omitempty"`
+	Key       string   `json:"key,omitempty"`
+	SNIs      []string `json:"snis,omitempty"`
+}
+
+// SNI is a struct for Kong SNI
+type SNI struct {
+	Name          string `json:"name"`
+	CertificateID string `json:"ssl_certificate_id"`
+}
+
 // NewClient creates a new Kong Client
 func NewClient(config *Config) (*Client, error) {
 	client := &Client{
@@ -227,7 +243,10 @@ func (k *Client) AddAPI(ctx context.Context, entity *gateway.API) (*gateway.API,
 	// Kong ignores query params in upstream url so have to add query params to dispatchTransformer on per-api basis
 	dispatchTransformer.Config["config.append.querystring"] = fmt.Sprintf("functionName:%s", entity.Function)
 	configHeaders := dispatchTransformer.Config["config.add.internal_header"].([]string)
-	dispatchTransformer.Config["config.add.internal_header"] = append(configHeaders, fmt.Sprintf("X-Dispatch-Org:%s", entity.OrganizationID))
+	// the requester header is overridden, so API callers can't run the function for another principal
+	dispatchTransformer.Config["config.add.internal_header"] = append(configHeaders,
+		fmt.Sprintf("X-Dispatch-Org:%s", entity.OrganizationID),
+		fmt.Sprintf("%s:%s", client.HeaderRequester, client.Requester(client.RequesterAPI, entity.Name)))
 	err = k.updatePluginByName(ctx, a.Name, dispatchTransformer.Name, &dispatchTransformer)
 	if err != nil {
 		return nil, err
@@ -291,7 +310,10 @@ func (k *Client) UpdateAPI(ctx context.Context, name string, entity *gateway.API
 	// Kong ignores query params in upstream url so have to add query params to dispatchTransformer on per-api basis
 	dispatchTransformer.Config["config.append.querystring"] = fmt.Sprintf("functionName:%s", entity.Function)
 	configHeaders := dispatchTransformer.Config["config.add.internal_header"].([]string)
-	dispatchTransformer.Config["config.add.internal_header"] = append(configHeaders, fmt.Sprintf("X-Dispatch-Org:%s", entity.OrganizationID))
+	// the requester header is overridden, so API callers can't run the function for another principal
+	dispatchTransformer.Config["config.add.internal_header"] = append(configHeaders,
+		fmt.Sprintf("X-Dispatch-Org:%s", entity.OrganizationID),
+		fmt.Sprintf("%s:%s", client.HeaderRequester, client.Requester(client.RequesterAPI, entity.Name)))
 	err = k.updatePluginByName(ctx, a.Name, dispatchTransformer.Name, &dispatchTransformer)
 	if err != nil {
 		return nil, err
@@ -354,6 +376,176 @@ func (k *Client) DeleteAPI(ctx context.Context, api *gateway.API) error {
 	}
 }
 
+// AddCertificate adds a certificate and its SNIs in Kong
+func (k *Client) AddCertificate(ctx context.Context, cert *gateway.Certificate) (*gateway.Certificate, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	// SNIs are managed separately through the /snis endpoint
+	body, err := json.Marshal(&Certificate{Cert: cert.Cert, Key: cert.Key})
+	if err != nil {
+		return nil, &errors.ObjectMarshalError{Err: err}
+	}
+	resp, err := k.request(ctx, "POST", fmt.Sprintf("%s/certificates", k.host), jsonContentType, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	log.Debugf("kong.addCertificate: status code: %v", resp.StatusCode)
+	var result Certificate
+	switch resp.StatusCode {
+	case 201:
+		if err := k.getResponse(resp, &result); err != nil {
+			return nil, err
+		}
+	default:
+		err = getKongError("addCertificate", resp)
+		return nil, &errors.DriverError{Err: err}
+	}
+
+	if err := k.syncSNIs(ctx, result.ID, nil, cert.SNIs); err != nil {
+		return nil, err
+	}
+	return &gateway.Certificate{
+		ID:        result.ID,
+		CreatedAt: result.CreatedAt,
+		SNIs:      cert.SNIs,
+	}, nil
+}
+
+// UpdateCertificate updates a certificate and its SNIs in Kong
+func (k *Client) UpdateCertificate(ctx context.Context, cert *gateway.Certificate) (*gateway.Certificate, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	body, err := json.Marshal(&Certificate{Cert: cert.Cert, Key: cert.Key})
+	if err != nil {
+		return nil, &errors.ObjectMarshalError{Err: err}
+	}
+	resp, err := k.request(ctx, "PATCH", fmt.Sprintf("%s/certificates/%s", k.host, cert.ID), jsonContentType, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	log.Debugf("kong.updateCertificate.%s: status code: %v", cert.ID, resp.StatusCode)
+	var result Certificate
+	switch resp.StatusCode {
+	case 200:
+		if err := k.getResponse(resp, &result); err != nil {
+			return nil, err
+		}
+	case 404:
+		return nil, &errors.ObjectNotFoundError{Err: fmt.Errorf("certificate not found")}
+	default:
+		err = getKongError("updateCertificate", resp)
+		return nil, &errors.DriverError{Err: err}
+	}
+
+	if err := k.syncSNIs(ctx, result.ID, result.SNIs, cert.SNIs); err != nil {
+		return nil, err
+	}
+	return &gateway.Certificate{
+		ID:        result.ID,
+		CreatedAt: result.CreatedAt,
+		SNIs:      cert.SNIs,
+	}, nil
+}
+
+// DeleteCertificate deletes a certificate (and with it all its SNIs) from Kong
+func (k *Client) DeleteCertificate(ctx context.Context, cert *gateway.Certificate) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	resp, err := k.request(ctx, "DELETE", fmt.Sprintf("%s/certificates/%s", k.host, cert.ID), jsonContentType, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	log.Debugf("kong.deleteCertificate.%s: status code: %v", cert.ID, resp.StatusCode)
+	switch resp.StatusCode {
+	case 204:
+		return nil
+	case 404:
+		return &errors.ObjectNotFoundError{Err: fmt.Errorf("certificate not found")}
+	default:
+		err = getKongError("deleteCertificate", resp)
+		return &errors.DriverError{Err: err}
+	}
+}
+
+// syncSNIs points all desired SNIs to the certificate and removes the ones no longer wanted
+func (k *Client) syncSNIs(ctx context.Context, certID string, current, desired []string) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	wanted := make(map[string]bool)
+	for _, name := range desired {
+		wanted[name] = true
+		if err := k.putSNI(ctx, &SNI{Name: name, CertificateID: certID}); err != nil {
+			return err
+		}
+	}
+	for _, name := range current {
+		if wanted[name] {
+			continue
+		}
+		if err := k.deleteSNI(ctx, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putSNI creates an SNI, or re-assigns it to another certificate if it already exists
+func (k *Client) putSNI(ctx context.Context, sni *SNI) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	body, err := json.Marshal(sni)
+	if err != nil {
+		return &errors.ObjectMarshalError{Err: err}
+	}
+	resp, err := k.request(ctx, "PUT", fmt.Sprintf("%s/snis", k.host), jsonContentType, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	log.Debugf("kong.putSNI.%s: status code: %v", sni.Name, resp.StatusCode)
+	switch resp.StatusCode {
+	case 200, 201:
+		return nil
+	default:
+		err = getKongError("putSNI", resp)
+		return &errors.DriverError{Err: err}
+	}
+}
+
+// 204: delete successfully
+// 404: object not found, nothing to do
+func (k *Client) deleteSNI(ctx context.Context, name string) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	resp, err := k.request(ctx, "DELETE", fmt.Sprintf("%s/snis/%s", k.host, name), jsonContentType, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	log.Debugf("kong.deleteSNI.%s: status code: %v", name, resp.StatusCode)
+	switch resp.StatusCode {
+	case 204, 404:
+		return nil
+	default:
+		err = getKongError("deleteSNI", resp)
+		return &errors.DriverError{Err: err}
+	}
+}
+
 func (k *Client) getPluginURL(api, plugin string) string {
 
 	url := fmt.Sprintf("%s", k.host)
@@ -7,6 +7,12 @@ package kong
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -14,6 +20,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/vmware/dispatch/pkg/api-manager/gateway"
+	"github.com/vmware/dispatch/pkg/errors"
 	"github.com/vmware/dispatch/pkg/testing/dev"
 )
 
@@ -140,3 +147,102 @@ func TestDeleteAPI(t *testing.T) {
 	err := client.DeleteAPI(context.Background(), noSuchAPI)
 	assert.NotNil(t, err)
 }
+
+// fakeKong is a minimal in-memory stand-in for the kong certificates and snis admin endpoints
+type fakeKong struct {
+	sync.Mutex
+	certs map[string]*Certificate
+	snis  map[string]string
+}
+
+func (f *fakeKong) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/certificates":
+		var cert Certificate
+		json.NewDecoder(r.Body).Decode(&cert)
+		cert.ID = "cert-" + strconv.Itoa(len(f.certs))
+		f.certs[cert.ID] = &cert
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(cert)
+	case r.Method == "PATCH" && parts[0] == "certificates":
+		cert, ok := f.certs[parts[1]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(cert)
+		result := *cert
+		for name, id := range f.snis {
+			if id == cert.ID {
+				result.SNIs = append(result.SNIs, name)
+			}
+		}
+		json.NewEncoder(w).Encode(result)
+	case r.Method == "DELETE" && parts[0] == "certificates":
+		if _, ok := f.certs[parts[1]]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.certs, parts[1])
+		for name, id := range f.snis {
+			if id == parts[1] {
+				delete(f.snis, name)
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "PUT" && r.URL.Path == "/snis":
+		var sni SNI
+		json.NewDecoder(r.Body).Decode(&sni)
+		f.snis[sni.Name] = sni.CertificateID
+		w.WriteHeader(http.StatusOK)
+	case r.Method == "DELETE" && parts[0] == "snis":
+		delete(f.snis, parts[1])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestCertificates(t *testing.T) {
+
+	fake := &fakeKong{certs: make(map[string]*Certificate), snis: make(map[string]string)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := NewClient(&Config{Host: server.URL})
+	assert.Nil(t, err)
+
+	added, err := client.AddCertificate(context.Background(), &gateway.Certificate{
+		Cert: "cert",
+		Key:  "key",
+		SNIs: []string{"a.example.com", "b.example.com"},
+	})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, added.ID)
+	assert.Equal(t, map[string]string{"a.example.com": added.ID, "b.example.com": added.ID}, fake.snis)
+
+	updated, err := client.UpdateCertificate(context.Background(), &gateway.Certificate{
+		ID:   added.ID,
+		Cert: "renewed",
+		Key:  "key",
+		SNIs: []string{"b.example.com", "c.example.com"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, added.ID, updated.ID)
+	assert.Equal(t, "renewed", fake.certs[added.ID].Cert)
+	assert.Equal(t, map[string]string{"b.example.com": added.ID, "c.example.com": added.ID}, fake.snis)
+
+	err = client.DeleteCertificate(context.Background(), &gateway.Certificate{ID: added.ID})
+	assert.Nil(t, err)
+	assert.Empty(t, fake.certs)
+	assert.Empty(t, fake.snis)
+
+	_, err = client.UpdateCertificate(context.Background(), &gateway.Certificate{ID: added.ID})
+	assert.IsType(t, &errors.ObjectNotFoundError{}, err)
+	err = client.DeleteCertificate(context.Background(), &gateway.Certificate{ID: added.ID})
+	assert.IsType(t, &errors.ObjectNotFoundError{}, err)
+}
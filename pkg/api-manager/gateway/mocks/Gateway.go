@@ -34,6 +34,29 @@ func (_m *Gateway) AddAPI(ctx context.Context, api *gateway.API) (*gateway.API,
 	return r0, r1
 }
 
+// AddCertificate provides a mock function with given fields: ctx, cert
+func (_m *Gateway) AddCertificate(ctx context.Context, cert *gateway.Certificate) (*gateway.Certificate, error) {
+	ret := _m.Called(ctx, cert)
+
+	var r0 *gateway.Certificate
+	if rf, ok := ret.Get(0).(func(context.Context, *gateway.Certificate) *gateway.Certificate); ok {
+		r0 = rf(ctx, cert)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*gateway.Certificate)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *gateway.Certificate) error); ok {
+		r1 = rf(ctx, cert)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteAPI provides a mock function with given fields: ctx, api
 func (_m *Gateway) DeleteAPI(ctx context.Context, api *gateway.API) error {
 	ret := _m.Called(ctx, api)
@@ -48,6 +71,20 @@ func (_m *Gateway) DeleteAPI(ctx context.Context, api *gateway.API) error {
 	return r0
 }
 
+// DeleteCertificate provides a mock function with given fields: ctx, cert
+func (_m *Gateway) DeleteCertificate(ctx context.Context, cert *gateway.Certificate) error {
+	ret := _m.Called(ctx, cert)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *gateway.Certificate) error); ok {
+		r0 = rf(ctx, cert)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetAPI provides a mock function with given fields: ctx, name
 func (_m *Gateway) GetAPI(ctx context.Context, name string) (*gateway.API, error) {
 	ret := _m.Called(ctx, name)
@@ -93,3 +130,26 @@ func (_m *Gateway) UpdateAPI(ctx context.Context, name string, api *gateway.API)
 
 	return r0, r1
 }
+
+// UpdateCertificate provides a mock function with given fields: ctx, cert
+func (_m *Gateway) UpdateCertificate(ctx context.Context, cert *gateway.Certificate) (*gateway.Certificate, error) {
+	ret := _m.Called(ctx, cert)
+
+	var r0 *gateway.Certificate
+	if rf, ok := ret.Get(0).(func(context.Context, *gateway.Certificate) *gateway.Certificate); ok {
+		r0 = rf(ctx, cert)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*gateway.Certificate)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *gateway.Certificate) error); ok {
+		r1 = rf(ctx, cert)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/vmware/dispatch/pkg/api-manager/gateway"
 	"github.com/vmware/dispatch/pkg/api-manager/gen/restapi/operations"
+	"github.com/vmware/dispatch/pkg/api-manager/gen/restapi/operations/certificate"
 	"github.com/vmware/dispatch/pkg/api-manager/gen/restapi/operations/endpoint"
 	"github.com/vmware/dispatch/pkg/api/v1"
 	"github.com/vmware/dispatch/pkg/controller"
@@ -37,6 +38,10 @@ var APIManagerFlags = struct {
 	FunctionManager string `long:"function-manager" description:"Function Manager Host" default:"function-manager"`
 	ResyncPeriod    int    `long:"resync-period" description:"The time period (in seconds) to sync with api gateway" default:"10"`
 	Tracer          string `long:"tracer" description:"Open Tracing Tracer endpoint" default:""`
+	SecretStore     string `long:"secret-store" description:"Secret store endpoint" default:"localhost:8003"`
+	ExpiryWarning   int    `long:"certificate-expiry-warning" description:"The number of days before expiry a certificate is reported (and renewed if issued through ACME)" default:"30"`
+	AcmeDirectory   string `long:"acme-directory" description:"ACME directory URL used to issue certificates, leave empty to disable ACME" default:""`
+	AcmeEmail       string `long:"acme-email" description:"Contact email of the ACME account" default:""`
 }{}
 
 // Handlers define a set of handlers for API Manager
@@ -101,10 +106,64 @@ func apiEntityToModel(e *API) *v1.API {
 		Status:         v1.Status(e.Status),
 		Cors:           e.API.CORS,
 		Tags:           tags,
+		Reason:         e.Reason,
 	}
 	return &m
 }
 
+func certificateModelOntoEntity(organizationID string, m *v1.Certificate) *Certificate {
+	tags := make(map[string]string)
+	for _, t := range m.Tags {
+		tags[t.Key] = t.Value
+	}
+	e := Certificate{
+		BaseEntity: entitystore.BaseEntity{
+			Name:           *m.Name,
+			OrganizationID: organizationID,
+			Tags:           tags,
+		},
+		Hosts:  m.Hosts,
+		Secret: m.Secret,
+		ACME:   m.Acme,
+	}
+	return &e
+}
+
+func certificateEntityToModel(e *Certificate) *v1.Certificate {
+	var tags []*v1.Tag
+	for k, v := range e.Tags {
+		tags = append(tags, &v1.Tag{Key: k, Value: v})
+	}
+	m := v1.Certificate{
+		ID:           strfmt.UUID(e.ID),
+		Name:         swag.String(e.Name),
+		Kind:         utils.CertificateKind,
+		Hosts:        e.Hosts,
+		Secret:       e.Secret,
+		Acme:         e.ACME,
+		Issuer:       e.Issuer,
+		Status:       v1.Status(e.Status),
+		Reason:       e.Reason,
+		CreatedTime:  e.CreatedTime.Unix(),
+		ModifiedTime: e.ModifiedTime.Unix(),
+		Tags:         tags,
+	}
+	if !e.NotAfter.IsZero() {
+		m.Expiration = e.NotAfter.Unix()
+	}
+	return &m
+}
+
+func validateCertificate(m *v1.Certificate) string {
+	if m.Secret == "" {
+		return "certificate secret is required"
+	}
+	if len(m.Hosts) == 0 {
+		return "at least one certificate host is required"
+	}
+	return ""
+}
+
 // ConfigureHandlers configure handlers for API Manager
 func (h *Handlers) ConfigureHandlers(routableAPI middleware.RoutableAPI) {
 	a, ok := routableAPI.(*operations.APIManagerAPI)
@@ -131,6 +190,11 @@ func (h *Handlers) ConfigureHandlers(routableAPI middleware.RoutableAPI) {
 	a.EndpointGetAPIHandler = endpoint.GetAPIHandlerFunc(h.getAPI)
 	a.EndpointGetApisHandler = endpoint.GetApisHandlerFunc(h.getAPIs)
 	a.EndpointUpdateAPIHandler = endpoint.UpdateAPIHandlerFunc(h.updateAPI)
+	a.CertificateAddCertificateHandler = certificate.AddCertificateHandlerFunc(h.addCertificate)
+	a.CertificateDeleteCertificateHandler = certificate.DeleteCertificateHandlerFunc(h.deleteCertificate)
+	a.CertificateGetCertificateHandler = certificate.GetCertificateHandlerFunc(h.getCertificate)
+	a.CertificateGetCertificatesHandler = certificate.GetCertificatesHandlerFunc(h.getCertificates)
+	a.CertificateUpdateCertificateHandler = certificate.UpdateCertificateHandlerFunc(h.updateCertificate)
 }
 
 func (h *Handlers) addAPI(params endpoint.AddAPIParams, principal interface{}) middleware.Responder {
@@ -248,6 +312,9 @@ func (h *Handlers) getAPIs(params endpoint.GetApisParams, principal interface{})
 		Filter: entitystore.FilterExists(),
 	}
 	opts.Filter, err = utils.ParseTags(opts.Filter, params.Tags)
+	if err == nil {
+		err = utils.ParsePaging(&opts, params.Limit, params.Continue, params.Sort)
+	}
 	if err != nil {
 		log.Errorf(err.Error())
 		return endpoint.NewGetAPIBadRequest().WithPayload(
@@ -270,7 +337,7 @@ func (h *Handlers) getAPIs(params endpoint.GetApisParams, principal interface{})
 	for _, api := range apis {
 		apiModels = append(apiModels, apiEntityToModel(api))
 	}
-	return endpoint.NewGetApisOK().WithPayload(apiModels)
+	return endpoint.NewGetApisOK().WithPayload(apiModels).WithXDispatchContinue(utils.NextPage(opts))
 }
 
 func (h *Handlers) updateAPI(params endpoint.UpdateAPIParams, principal interface{}) middleware.Responder {
@@ -322,3 +389,201 @@ func (h *Handlers) updateAPI(params endpoint.UpdateAPIParams, principal interfac
 	}
 	return endpoint.NewUpdateAPIOK().WithPayload(apiEntityToModel(updatedEntity))
 }
+
+func (h *Handlers) addCertificate(params certificate.AddCertificateParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	if msg := validateCertificate(params.Body); msg != "" {
+		return certificate.NewAddCertificateBadRequest().WithPayload(&v1.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String(msg),
+		})
+	}
+	e := certificateModelOntoEntity(params.XDispatchOrg, params.Body)
+
+	e.Status = entitystore.StatusCREATING
+	if _, err := h.Store.Add(ctx, e); err != nil {
+		if entitystore.IsUniqueViolation(err) {
+			return certificate.NewAddCertificateConflict().WithPayload(&v1.Error{
+				Code:    http.StatusConflict,
+				Message: swag.String("error creating certificate: non-unique name"),
+			})
+		}
+		log.Errorf("store error when adding a new certificate %s: %+v", e.Name, err)
+		return certificate.NewAddCertificateInternalServerError().WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when storing a new certificate"),
+		})
+	}
+	if h.watcher != nil {
+		h.watcher.OnAction(ctx, e)
+	} else {
+		log.Debugf("note: the watcher is nil")
+	}
+	return certificate.NewAddCertificateOK().WithPayload(certificateEntityToModel(e))
+}
+
+func (h *Handlers) deleteCertificate(params certificate.DeleteCertificateParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	var err error
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	opts.Filter, err = utils.ParseTags(opts.Filter, params.Tags)
+	if err != nil {
+		log.Errorf("error parsing tags: %s", err)
+		return certificate.NewDeleteCertificateBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
+	var e Certificate
+	if err := h.Store.Get(ctx, params.XDispatchOrg, params.Certificate, opts, &e); err != nil {
+		log.Errorf("store error when getting certificate: %+v", err)
+		return certificate.NewDeleteCertificateNotFound().WithPayload(
+			&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String("certificate not found"),
+			})
+	}
+	e.Status = entitystore.StatusDELETING
+	if _, err := h.Store.Update(ctx, e.Revision, &e); err != nil {
+		log.Errorf("store error when deleting the certificate %s: %+v", e.Name, err)
+		return certificate.NewDeleteCertificateInternalServerError().WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when deleting a certificate"),
+		})
+	}
+	if h.watcher != nil {
+		h.watcher.OnAction(ctx, &e)
+	} else {
+		log.Debugf("note: the watcher is nil")
+	}
+	return certificate.NewDeleteCertificateOK().WithPayload(certificateEntityToModel(&e))
+}
+
+func (h *Handlers) getCertificate(params certificate.GetCertificateParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	var err error
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	opts.Filter, err = utils.ParseTags(opts.Filter, params.Tags)
+	if err != nil {
+		log.Errorf("error parsing tags: %s", err)
+		return certificate.NewGetCertificateBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
+	var e Certificate
+	if err = h.Store.Get(ctx, params.XDispatchOrg, params.Certificate, opts, &e); err != nil {
+		log.Errorf("store error when getting certificate: %+v", err)
+		return certificate.NewGetCertificateNotFound().WithPayload(
+			&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String("certificate not found"),
+			})
+	}
+	return certificate.NewGetCertificateOK().WithPayload(certificateEntityToModel(&e))
+}
+
+func (h *Handlers) getCertificates(params certificate.GetCertificatesParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	var certs []*Certificate
+
+	var err error
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	opts.Filter, err = utils.ParseTags(opts.Filter, params.Tags)
+	if err == nil {
+		err = utils.ParsePaging(&opts, params.Limit, params.Continue, params.Sort)
+	}
+	if err != nil {
+		log.Errorf("error parsing the listing parameters: %s", err)
+		return certificate.NewGetCertificatesBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
+
+	if err = h.Store.List(ctx, params.XDispatchOrg, opts, &certs); err != nil {
+		log.Errorf("store error when listing certificates: %+v", err)
+		return certificate.NewGetCertificatesDefault(http.StatusInternalServerError).WithPayload(
+			&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String("internal server error when getting certificates"),
+			})
+	}
+	var certModels []*v1.Certificate
+	for _, cert := range certs {
+		certModels = append(certModels, certificateEntityToModel(cert))
+	}
+	return certificate.NewGetCertificatesOK().WithPayload(certModels).WithXDispatchContinue(utils.NextPage(opts))
+}
+
+func (h *Handlers) updateCertificate(params certificate.UpdateCertificateParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	if msg := validateCertificate(params.Body); msg != "" {
+		return certificate.NewUpdateCertificateBadRequest().WithPayload(&v1.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String(msg),
+		})
+	}
+
+	var err error
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	opts.Filter, err = utils.ParseTags(opts.Filter, params.Tags)
+	if err != nil {
+		log.Errorf("error parsing tags: %s", err)
+		return certificate.NewUpdateCertificateBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
+	var e Certificate
+	if err = h.Store.Get(ctx, params.XDispatchOrg, params.Certificate, opts, &e); err != nil {
+		log.Errorf("store error when getting certificate: %+v", err)
+		return certificate.NewUpdateCertificateNotFound().WithPayload(
+			&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String("certificate not found"),
+			})
+	}
+
+	e.Hosts = params.Body.Hosts
+	e.Secret = params.Body.Secret
+	e.ACME = params.Body.Acme
+	e.Tags = certificateModelOntoEntity(params.XDispatchOrg, params.Body).Tags
+	e.Status = entitystore.StatusUPDATING
+	if _, err := h.Store.Update(ctx, e.Revision, &e); err != nil {
+		log.Errorf("store error when updating certificate: %+v", err)
+		return certificate.NewUpdateCertificateInternalServerError().WithPayload(
+			&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String("internal server error when updating certificates"),
+			})
+	}
+	if h.watcher != nil {
+		h.watcher.OnAction(ctx, &e)
+	} else {
+		log.Debugf("note: the watcher is nil")
+	}
+	return certificate.NewUpdateCertificateOK().WithPayload(certificateEntityToModel(&e))
+}
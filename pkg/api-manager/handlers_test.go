@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/vmware/dispatch/pkg/api-manager/gen/restapi/operations"
+	"github.com/vmware/dispatch/pkg/api-manager/gen/restapi/operations/certificate"
 	apihandler "github.com/vmware/dispatch/pkg/api-manager/gen/restapi/operations/endpoint"
 	"github.com/vmware/dispatch/pkg/api/v1"
 	helpers "github.com/vmware/dispatch/pkg/testing/api"
@@ -212,3 +213,120 @@ func TestAPIUpdateAPI(t *testing.T) {
 	helpers.HandlerRequest(t, responder, &respBody, 200)
 	assertAPIEqual(t, oneAPI, &respBody)
 }
+
+func TestCertificateAddCertificate(t *testing.T) {
+
+	a := operations.NewAPIManagerAPI(nil)
+	es := helpers.MakeEntityStore(t)
+	h := NewHandlers(nil, es)
+
+	helpers.MakeAPI(t, h.ConfigureHandlers, a)
+
+	reqBody := &v1.Certificate{
+		Name:   swag.String("testCert"),
+		Hosts:  []string{"www.example.com"},
+		Secret: "example-tls",
+	}
+	params := certificate.AddCertificateParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/api/certificate", nil),
+		Body:        reqBody,
+	}
+	responder := a.CertificateAddCertificateHandler.Handle(params, "cookie")
+	var respBody v1.Certificate
+	helpers.HandlerRequest(t, responder, &respBody, 200)
+	assert.Equal(t, "testCert", *respBody.Name)
+	assert.Equal(t, reqBody.Hosts, respBody.Hosts)
+	assert.Equal(t, reqBody.Secret, respBody.Secret)
+	assert.Equal(t, v1.StatusCREATING, respBody.Status)
+
+	getParams := certificate.GetCertificateParams{
+		HTTPRequest: httptest.NewRequest("GET", "/v1/api/certificate/testCert", nil),
+		Certificate: "testCert",
+	}
+	responder = a.CertificateGetCertificateHandler.Handle(getParams, "cookie")
+	helpers.HandlerRequest(t, responder, &respBody, 200)
+	assert.Equal(t, "testCert", *respBody.Name)
+
+	listParams := certificate.GetCertificatesParams{
+		HTTPRequest: httptest.NewRequest("GET", "/v1/api/certificate", nil),
+	}
+	responder = a.CertificateGetCertificatesHandler.Handle(listParams, "cookie")
+	var listBody []*v1.Certificate
+	helpers.HandlerRequest(t, responder, &listBody, 200)
+	assert.Len(t, listBody, 1)
+
+	deleteParams := certificate.DeleteCertificateParams{
+		HTTPRequest: httptest.NewRequest("DELETE", "/v1/api/certificate/testCert", nil),
+		Certificate: "testCert",
+	}
+	responder = a.CertificateDeleteCertificateHandler.Handle(deleteParams, "cookie")
+	helpers.HandlerRequest(t, responder, &respBody, 200)
+	assert.Equal(t, v1.StatusDELETING, respBody.Status)
+}
+
+func TestCertificateAddCertificateInvalid(t *testing.T) {
+
+	a := operations.NewAPIManagerAPI(nil)
+	es := helpers.MakeEntityStore(t)
+	h := NewHandlers(nil, es)
+
+	helpers.MakeAPI(t, h.ConfigureHandlers, a)
+
+	params := certificate.AddCertificateParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/api/certificate", nil),
+		Body: &v1.Certificate{
+			Name:   swag.String("testCert"),
+			Secret: "example-tls",
+		},
+	}
+	responder := a.CertificateAddCertificateHandler.Handle(params, "cookie")
+	var respBody v1.Error
+	helpers.HandlerRequest(t, responder, &respBody, 400)
+}
+
+func TestAPIOrganizationIsolation(t *testing.T) {
+
+	a := operations.NewAPIManagerAPI(nil)
+	es := helpers.MakeEntityStore(t)
+	h := NewHandlers(nil, es)
+
+	helpers.MakeAPI(t, h.ConfigureHandlers, a)
+
+	oneAPI := &v1.API{
+		Name:     swag.String("testAPI"),
+		Function: swag.String("testFunction"),
+		Enabled:  true,
+		Methods:  []string{"GET"},
+	}
+	addParams := apihandler.AddAPIParams{
+		HTTPRequest:  httptest.NewRequest("POST", "/v1/api", nil),
+		Body:         oneAPI,
+		XDispatchOrg: "org-a",
+	}
+	responder := a.EndpointAddAPIHandler.Handle(addParams, "cookie")
+	var addBody v1.API
+	helpers.HandlerRequest(t, responder, &addBody, 200)
+
+	getParams := apihandler.GetAPIParams{
+		HTTPRequest:  httptest.NewRequest("GET", "/v1/api", nil),
+		API:          *oneAPI.Name,
+		XDispatchOrg: "org-b",
+	}
+	responder = a.EndpointGetAPIHandler.Handle(getParams, "cookie")
+	var errorBody v1.Error
+	helpers.HandlerRequest(t, responder, &errorBody, 404)
+
+	listParams := apihandler.GetApisParams{
+		HTTPRequest:  httptest.NewRequest("GET", "/v1/api", nil),
+		XDispatchOrg: "org-b",
+	}
+	responder = a.EndpointGetApisHandler.Handle(listParams, "cookie")
+	var listBody []*v1.API
+	helpers.HandlerRequest(t, responder, &listBody, 200)
+	assert.Len(t, listBody, 0)
+
+	// org-b may use the same API name
+	addParams.XDispatchOrg = "org-b"
+	responder = a.EndpointAddAPIHandler.Handle(addParams, "cookie")
+	helpers.HandlerRequest(t, responder, &addBody, 200)
+}
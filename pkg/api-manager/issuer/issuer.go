@@ -0,0 +1,195 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2017 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package issuer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/acme"
+
+	"github.com/vmware/dispatch/pkg/trace"
+)
+
+// ChallengePathPrefix is the path prefix under which http-01 challenge responses are served
+const ChallengePathPrefix = "/.well-known/acme-challenge/"
+
+// Issuer issues tls certificates for a list of host names
+type Issuer interface {
+	// Issue returns a PEM encoded certificate chain and private key valid for all hosts
+	Issue(ctx context.Context, hosts []string) (certPEM, keyPEM []byte, err error)
+}
+
+// ACMEConfig represents a configuration for the ACME issuer
+type ACMEConfig struct {
+	DirectoryURL string
+	Email        string
+}
+
+// ACMEIssuer issues certificates against an ACME directory (e.g. Let's Encrypt), using http-01 challenges.
+// Challenge responses are served by the issuer itself, requests for ChallengePathPrefix on the certificate hosts
+// must be routed to it.
+type ACMEIssuer struct {
+	client *acme.Client
+	email  string
+
+	registerOnce sync.Once
+	registerErr  error
+
+	sync.RWMutex
+	responses map[string]string
+}
+
+// NewACMEIssuer creates a new ACME issuer with a freshly generated account key
+func NewACMEIssuer(config *ACMEConfig) (*ACMEIssuer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "error generating acme account key")
+	}
+	return &ACMEIssuer{
+		client: &acme.Client{
+			Key:          key,
+			DirectoryURL: config.DirectoryURL,
+		},
+		email:     config.Email,
+		responses: make(map[string]string),
+	}, nil
+}
+
+func (i *ACMEIssuer) register(ctx context.Context) error {
+	i.registerOnce.Do(func() {
+		account := &acme.Account{}
+		if i.email != "" {
+			account.Contact = []string{"mailto:" + i.email}
+		}
+		_, i.registerErr = i.client.Register(ctx, account, acme.AcceptTOS)
+	})
+	return i.registerErr
+}
+
+func (i *ACMEIssuer) authorize(ctx context.Context, host string) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	authz, err := i.client.Authorize(ctx, host)
+	if err != nil {
+		return errors.Wrapf(err, "error authorizing %s", host)
+	}
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+	var challenge *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == "http-01" {
+			challenge = c
+			break
+		}
+	}
+	if challenge == nil {
+		return errors.Errorf("no http-01 challenge offered for %s", host)
+	}
+	response, err := i.client.HTTP01ChallengeResponse(challenge.Token)
+	if err != nil {
+		return errors.Wrapf(err, "error preparing challenge response for %s", host)
+	}
+	path := i.client.HTTP01ChallengePath(challenge.Token)
+	i.Lock()
+	i.responses[path] = response
+	i.Unlock()
+	defer func() {
+		i.Lock()
+		delete(i.responses, path)
+		i.Unlock()
+	}()
+
+	if _, err := i.client.Accept(ctx, challenge); err != nil {
+		return errors.Wrapf(err, "error accepting challenge for %s", host)
+	}
+	if _, err := i.client.WaitAuthorization(ctx, authz.URI); err != nil {
+		return errors.Wrapf(err, "error waiting for authorization of %s", host)
+	}
+	log.Debugf("acme: authorized %s", host)
+	return nil
+}
+
+// Issue authorizes all hosts and requests a certificate for them
+func (i *ACMEIssuer) Issue(ctx context.Context, hosts []string) ([]byte, []byte, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	if len(hosts) == 0 {
+		return nil, nil, errors.New("at least one host is required to issue a certificate")
+	}
+	if err := i.register(ctx); err != nil {
+		return nil, nil, errors.Wrap(err, "error registering acme account")
+	}
+	for _, host := range hosts {
+		if err := i.authorize(ctx, host); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error generating certificate key")
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: hosts[0]},
+		DNSNames: hosts,
+	}, key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error creating certificate request")
+	}
+	chain, _, err := i.client.CreateCert(ctx, csr, 0, true)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error requesting certificate")
+	}
+
+	var certPEM []byte
+	for _, der := range chain {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error encoding certificate key")
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// ServeHTTP serves http-01 challenge responses
+func (i *ACMEIssuer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	i.RLock()
+	response, ok := i.responses[r.URL.Path]
+	i.RUnlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(response))
+}
+
+// Middleware serves http-01 challenge responses, all other requests are passed to the next handler
+func (i *ACMEIssuer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, ChallengePathPrefix) {
+			i.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,200 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2017 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package issuer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeACME is a minimal ACME (v1) directory, it validates http-01 challenges against the issuer under test
+type fakeACME struct {
+	t      *testing.T
+	url    string
+	issuer *ACMEIssuer
+
+	caKey  *ecdsa.PrivateKey
+	caCert *x509.Certificate
+	caDER  []byte
+
+	sync.Mutex
+	valid bool
+}
+
+func newFakeACME(t *testing.T) *fakeACME {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fake acme ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &fakeACME{t: t, caKey: key, caCert: cert, caDER: der}
+}
+
+// payload decodes the payload of a flattened JWS request
+func (f *fakeACME) payload(r *http.Request, v interface{}) {
+	var jws struct {
+		Payload string `json:"payload"`
+	}
+	require.NoError(f.t, json.NewDecoder(r.Body).Decode(&jws))
+	b, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+	require.NoError(f.t, err)
+	require.NoError(f.t, json.Unmarshal(b, v))
+}
+
+func (f *fakeACME) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	w.Header().Set("Replay-Nonce", fmt.Sprintf("nonce-%d", time.Now().UnixNano()))
+	if r.Method == "HEAD" {
+		return
+	}
+	switch r.URL.Path {
+	case "/directory":
+		json.NewEncoder(w).Encode(map[string]string{
+			"new-reg":   f.url + "/new-reg",
+			"new-authz": f.url + "/new-authz",
+			"new-cert":  f.url + "/new-cert",
+		})
+	case "/new-reg":
+		w.Header().Set("Location", f.url+"/reg/1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("{}"))
+	case "/new-authz":
+		w.Header().Set("Location", f.url+"/authz/1")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"status":"pending","challenges":[{"type":"http-01","uri":"%s/challenge/1","token":"token1"}]}`, f.url)
+	case "/challenge/1":
+		var accept struct {
+			KeyAuthorization string `json:"keyAuthorization"`
+		}
+		f.payload(r, &accept)
+		// validate the challenge the way the CA would, by fetching the response from the host
+		rec := httptest.NewRecorder()
+		f.issuer.Middleware(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest("GET", ChallengePathPrefix+"token1", nil))
+		f.valid = rec.Code == http.StatusOK && rec.Body.String() == accept.KeyAuthorization
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"type":"http-01","status":"pending"}`))
+	case "/authz/1":
+		status := "invalid"
+		if f.valid {
+			status = "valid"
+		}
+		fmt.Fprintf(w, `{"status":"%s"}`, status)
+	case "/new-cert":
+		if !f.valid {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var req struct {
+			CSR string `json:"csr"`
+		}
+		f.payload(r, &req)
+		b, err := base64.RawURLEncoding.DecodeString(req.CSR)
+		require.NoError(f.t, err)
+		csr, err := x509.ParseCertificateRequest(b)
+		require.NoError(f.t, err)
+		der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      csr.Subject,
+			DNSNames:     csr.DNSNames,
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}, f.caCert, csr.PublicKey, f.caKey)
+		require.NoError(f.t, err)
+		w.Header().Set("Link", fmt.Sprintf(`<%s/ca>;rel="up"`, f.url))
+		w.WriteHeader(http.StatusCreated)
+		w.Write(der)
+	case "/ca":
+		w.Write(f.caDER)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestIssue(t *testing.T) {
+	fake := newFakeACME(t)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	fake.url = server.URL
+
+	iss, err := NewACMEIssuer(&ACMEConfig{DirectoryURL: server.URL + "/directory", Email: "admin@example.com"})
+	require.NoError(t, err)
+	fake.issuer = iss
+
+	certPEM, keyPEM, err := iss.Issue(context.Background(), []string{"www.example.com"})
+	require.NoError(t, err)
+
+	keyPair, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	assert.Len(t, keyPair.Certificate, 2)
+	leaf, err := x509.ParseCertificate(keyPair.Certificate[0])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"www.example.com"}, leaf.DNSNames)
+	assert.Equal(t, "fake acme ca", leaf.Issuer.CommonName)
+
+	// challenge responses are only served while authorizing
+	rec := httptest.NewRecorder()
+	iss.ServeHTTP(rec, httptest.NewRequest("GET", ChallengePathPrefix+"token1", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestIssueNoHosts(t *testing.T) {
+	iss, err := NewACMEIssuer(&ACMEConfig{DirectoryURL: "http://localhost/directory"})
+	require.NoError(t, err)
+
+	_, _, err = iss.Issue(context.Background(), nil)
+	assert.Error(t, err)
+}
+
+func TestMiddleware(t *testing.T) {
+	iss, err := NewACMEIssuer(&ACMEConfig{})
+	require.NoError(t, err)
+	iss.responses[ChallengePathPrefix+"token"] = "response"
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("next"))
+	})
+	handler := iss.Middleware(next)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", ChallengePathPrefix+"token", nil))
+	body, _ := ioutil.ReadAll(rec.Body)
+	assert.Equal(t, "response", string(body))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/api", nil))
+	body, _ = ioutil.ReadAll(rec.Body)
+	assert.Equal(t, "next", string(body))
+}
@@ -0,0 +1,126 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	"strconv"
+
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// NO TESTS
+
+// AccessDecision access decision
+// swagger:model AccessDecision
+type AccessDecision struct {
+
+	// action
+	Action string `json:"action,omitempty"`
+
+	// whether the request is allowed
+	// Required: true
+	Allowed *bool `json:"allowed"`
+
+	// application
+	Application string `json:"application,omitempty"`
+
+	// the groups of the subject
+	Groups []string `json:"groups"`
+
+	// organization
+	Organization string `json:"organization,omitempty"`
+
+	// why the request is allowed or denied
+	Reason string `json:"reason,omitempty"`
+
+	// resource
+	Resource string `json:"resource,omitempty"`
+
+	// the rules of the organization applying to the subject
+	Rules []*AccessRule `json:"rules"`
+
+	// subject
+	Subject string `json:"subject,omitempty"`
+}
+
+// Validate validates this access decision
+func (m *AccessDecision) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateAllowed(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateRules(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *AccessDecision) validateAllowed(formats strfmt.Registry) error {
+
+	if err := validate.Required("allowed", "body", m.Allowed); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *AccessDecision) validateRules(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Rules) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Rules); i++ {
+
+		if swag.IsZero(m.Rules[i]) { // not required
+			continue
+		}
+
+		if m.Rules[i] != nil {
+
+			if err := m.Rules[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("rules" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+
+		}
+
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *AccessDecision) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *AccessDecision) UnmarshalBinary(b []byte) error {
+	var res AccessDecision
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
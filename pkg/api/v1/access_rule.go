@@ -0,0 +1,72 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+)
+
+// NO TESTS
+
+// AccessRule access rule
+// swagger:model AccessRule
+type AccessRule struct {
+
+	// the actions of the rule
+	Action string `json:"action,omitempty"`
+
+	// whether the rule allows the request
+	Allowed bool `json:"allowed,omitempty"`
+
+	// the applications of the rule
+	Application string `json:"application,omitempty"`
+
+	// why the rule allows or doesn't allow the request
+	Reason string `json:"reason,omitempty"`
+
+	// the resources of the rule
+	Resource string `json:"resource,omitempty"`
+
+	// the role of the rule, when granted by a role binding
+	Role string `json:"role,omitempty"`
+
+	// the policy or role binding defining the rule, e.g. policy/admins or rolebinding/billing-developers
+	Source string `json:"source,omitempty"`
+
+	// the subjects of the rule
+	Subject string `json:"subject,omitempty"`
+}
+
+// Validate validates this access rule
+func (m *AccessRule) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *AccessRule) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *AccessRule) UnmarshalBinary(b []byte) error {
+	var res AccessRule
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
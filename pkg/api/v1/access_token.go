@@ -0,0 +1,76 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// NO TESTS
+
+// AccessToken an access token issued to a service account
+// swagger:model AccessToken
+type AccessToken struct {
+
+	// the access token
+	// Required: true
+	AccessToken *string `json:"accessToken"`
+
+	// the lifetime of the access token, in seconds
+	ExpiresIn int64 `json:"expiresIn,omitempty"`
+
+	// the token ID, to revoke the access token
+	ID string `json:"id,omitempty"`
+
+	// the token type
+	TokenType string `json:"tokenType,omitempty"`
+}
+
+// Validate validates this access token
+func (m *AccessToken) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateAccessToken(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *AccessToken) validateAccessToken(formats strfmt.Registry) error {
+
+	if err := validate.Required("accessToken", "body", m.AccessToken); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *AccessToken) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *AccessToken) UnmarshalBinary(b []byte) error {
+	var res AccessToken
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
@@ -56,13 +56,16 @@ type API struct {
 	// a list of support protocols (i.e. http, https)
 	Protocols []string `json:"protocols"`
 
+	// reason
+	Reason []string `json:"reason"`
+
 	// status
 	Status Status `json:"status,omitempty"`
 
 	// tags
 	Tags []*Tag `json:"tags"`
 
-	// the tls credentials (imported from serverless secret) for https connection
+	// the name of the certificate used for https connections, the name of a secret (as used before certificates) is converted into a certificate of the same name
 	TLS string `json:"tls,omitempty"`
 
 	// a list of URIs prefixes that point to the API
@@ -108,6 +111,11 @@ func (m *API) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateReason(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
 	if err := m.validateStatus(formats); err != nil {
 		// prop
 		res = append(res, err)
@@ -204,6 +212,15 @@ func (m *API) validateProtocols(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *API) validateReason(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Reason) { // not required
+		return nil
+	}
+
+	return nil
+}
+
 func (m *API) validateStatus(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.Status) { // not required
@@ -0,0 +1,119 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// NO TESTS
+
+// APIToken is a personal API token, authenticating requests as the user owning it
+// swagger:model APIToken
+type APIToken struct {
+
+	// the actions the token is restricted to, e.g. get, all the actions of the owner when empty
+	Actions []string `json:"actions"`
+
+	// created time
+	// Read Only: true
+	CreatedTime int64 `json:"createdTime,omitempty"`
+
+	// what the token is used for
+	Description string `json:"description,omitempty"`
+
+	// the time the token expires, the token does not expire when not set
+	ExpiresTime int64 `json:"expiresTime,omitempty"`
+
+	// kind
+	// Read Only: true
+	// Pattern: ^[\w\d\-]+$
+	Kind string `json:"kind,omitempty"`
+
+	// the name identifying the token
+	// Read Only: true
+	// Pattern: ^[\w\d\-]+$
+	Name string `json:"name,omitempty"`
+
+	// the user owning the token, the principal of the requests authenticated by it
+	// Read Only: true
+	Owner string `json:"owner,omitempty"`
+
+	// the resources the token is restricted to, e.g. function, all the resources of the owner when empty
+	Resources []string `json:"resources"`
+
+	// the secret token, only returned when the token is created
+	// Read Only: true
+	Token string `json:"token,omitempty"`
+}
+
+// Validate validates this API token
+func (m *APIToken) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateKind(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateName(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *APIToken) validateKind(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Kind) { // not required
+		return nil
+	}
+
+	if err := validate.Pattern("kind", "body", string(m.Kind), `^[\w\d\-]+$`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *APIToken) validateName(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Name) { // not required
+		return nil
+	}
+
+	if err := validate.Pattern("name", "body", string(m.Name), `^[\w\d\-]+$`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *APIToken) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *APIToken) UnmarshalBinary(b []byte) error {
+	var res APIToken
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
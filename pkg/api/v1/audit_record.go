@@ -0,0 +1,84 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+)
+
+// NO TESTS
+
+// AuditRecord audit record
+// swagger:model AuditRecord
+type AuditRecord struct {
+
+	// the action, e.g. create, update or delete
+	Action string `json:"action,omitempty"`
+
+	// the revision of the resource after the call
+	AfterRevision int64 `json:"afterRevision,omitempty"`
+
+	// the revision of the resource before the call
+	BeforeRevision int64 `json:"beforeRevision,omitempty"`
+
+	// the name of the resource
+	Name string `json:"name,omitempty"`
+
+	// the organization of the resource
+	Organization string `json:"organization,omitempty"`
+
+	// the outcome of the call: allowed or denied for authorization decisions, succeeded or failed for calls
+	Outcome string `json:"outcome,omitempty"`
+
+	// who made the call, e.g. user/jane@example.com or serviceaccount/ci
+	Principal string `json:"principal,omitempty"`
+
+	// the ID of the request
+	RequestID string `json:"requestId,omitempty"`
+
+	// the type of the resource, e.g. function
+	Resource string `json:"resource,omitempty"`
+
+	// the service recording the call, e.g. function-manager
+	Service string `json:"service,omitempty"`
+
+	// the HTTP status of the call
+	Status int64 `json:"status,omitempty"`
+
+	// the time of the call (unix time)
+	Time int64 `json:"time,omitempty"`
+}
+
+// Validate validates this audit record
+func (m *AuditRecord) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *AuditRecord) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *AuditRecord) UnmarshalBinary(b []byte) error {
+	var res AuditRecord
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
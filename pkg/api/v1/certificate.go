@@ -0,0 +1,232 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2017 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	"strconv"
+
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// NO TESTS
+
+// Certificate Certificate
+// swagger:model Certificate
+type Certificate struct {
+
+	// issue (and renew) the certificate through the configured ACME directory
+	Acme bool `json:"acme,omitempty"`
+
+	// created time
+	CreatedTime int64 `json:"createdTime,omitempty"`
+
+	// the expiration time of the certificate (unix time)
+	// Read Only: true
+	Expiration int64 `json:"expiration,omitempty"`
+
+	// a list of SNI host names served by the certificate
+	Hosts []string `json:"hosts"`
+
+	// id
+	ID strfmt.UUID `json:"id,omitempty"`
+
+	// the issuer of the certificate
+	// Read Only: true
+	Issuer string `json:"issuer,omitempty"`
+
+	// kind
+	// Read Only: true
+	// Pattern: ^[\w\d\-]+$
+	Kind string `json:"kind,omitempty"`
+
+	// modified time
+	ModifiedTime int64 `json:"modifiedTime,omitempty"`
+
+	// name
+	// Required: true
+	// Pattern: ^[\w\d\-]+$
+	Name *string `json:"name"`
+
+	// reason
+	Reason []string `json:"reason"`
+
+	// the name of the secret holding the PEM encoded certificate (cert) and private key (key)
+	Secret string `json:"secret,omitempty"`
+
+	// status
+	Status Status `json:"status,omitempty"`
+
+	// tags
+	Tags []*Tag `json:"tags"`
+}
+
+// Validate validates this certificate
+func (m *Certificate) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateHosts(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateID(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateKind(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateName(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateReason(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateStatus(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateTags(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *Certificate) validateHosts(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Hosts) { // not required
+		return nil
+	}
+
+	return nil
+}
+
+func (m *Certificate) validateID(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.ID) { // not required
+		return nil
+	}
+
+	if err := validate.FormatOf("id", "body", "uuid", m.ID.String(), formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *Certificate) validateKind(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Kind) { // not required
+		return nil
+	}
+
+	if err := validate.Pattern("kind", "body", string(m.Kind), `^[\w\d\-]+$`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *Certificate) validateName(formats strfmt.Registry) error {
+
+	if err := validate.Required("name", "body", m.Name); err != nil {
+		return err
+	}
+
+	if err := validate.Pattern("name", "body", string(*m.Name), `^[\w\d\-]+$`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *Certificate) validateReason(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Reason) { // not required
+		return nil
+	}
+
+	return nil
+}
+
+func (m *Certificate) validateStatus(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Status) { // not required
+		return nil
+	}
+
+	if err := m.Status.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("status")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m *Certificate) validateTags(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Tags) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Tags); i++ {
+
+		if swag.IsZero(m.Tags[i]) { // not required
+			continue
+		}
+
+		if m.Tags[i] != nil {
+
+			if err := m.Tags[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("tags" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+
+		}
+
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *Certificate) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *Certificate) UnmarshalBinary(b []byte) error {
+	var res Certificate
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
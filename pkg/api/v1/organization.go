@@ -31,6 +31,9 @@ type Organization struct {
 	// Pattern: ^[\w\d\-]+$
 	Kind string `json:"kind,omitempty"`
 
+	// the users of the organization, service accounts are members of the organization they belong to
+	Members []string `json:"members"`
+
 	// modified time
 	// Read Only: true
 	ModifiedTime int64 `json:"modifiedTime,omitempty"`
@@ -0,0 +1,124 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2017 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	"encoding/json"
+	"strconv"
+
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// NO TESTS
+
+// RoleRule role rule
+// swagger:model RoleRule
+type RoleRule struct {
+
+	// actions
+	// Required: true
+	Actions []string `json:"actions"`
+
+	// resources
+	// Required: true
+	Resources []string `json:"resources"`
+}
+
+// Validate validates this role rule
+func (m *RoleRule) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateActions(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateResources(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+var roleRuleActionsItemsEnum []interface{}
+
+func init() {
+	var res []string
+	if err := json.Unmarshal([]byte(`["get","create","update","delete","*"]`), &res); err != nil {
+		panic(err)
+	}
+	for _, v := range res {
+		roleRuleActionsItemsEnum = append(roleRuleActionsItemsEnum, v)
+	}
+}
+
+func (m *RoleRule) validateActionsItemsEnum(path, location string, value string) error {
+	if err := validate.Enum(path, location, value, roleRuleActionsItemsEnum); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *RoleRule) validateActions(formats strfmt.Registry) error {
+
+	if err := validate.Required("actions", "body", m.Actions); err != nil {
+		return err
+	}
+
+	for i := 0; i < len(m.Actions); i++ {
+
+		// value enum
+		if err := m.validateActionsItemsEnum("actions"+"."+strconv.Itoa(i), "body", m.Actions[i]); err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+}
+
+func (m *RoleRule) validateResources(formats strfmt.Registry) error {
+
+	if err := validate.Required("resources", "body", m.Resources); err != nil {
+		return err
+	}
+
+	for i := 0; i < len(m.Resources); i++ {
+
+		if err := validate.Pattern("resources"+"."+strconv.Itoa(i), "body", string(m.Resources[i]), `^[\w\d\-\*]+(/[\w\d\-\*]+)?$`); err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *RoleRule) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *RoleRule) UnmarshalBinary(b []byte) error {
+	var res RoleRule
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
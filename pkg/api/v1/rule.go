@@ -105,7 +105,7 @@ func (m *Rule) validateResources(formats strfmt.Registry) error {
 
 	for i := 0; i < len(m.Resources); i++ {
 
-		if err := validate.Pattern("resources"+"."+strconv.Itoa(i), "body", string(m.Resources[i]), `^[\w\d\-\*]+$`); err != nil {
+		if err := validate.Pattern("resources"+"."+strconv.Itoa(i), "body", string(m.Resources[i]), `^[\w\d\-\*]+(/[\w\d\-\*]+)?$`); err != nil {
 			return err
 		}
 
@@ -21,6 +21,9 @@ import (
 // swagger:model Secret
 type Secret struct {
 
+	// the principals allowed to read the secret values, any principal of the organization if not set
+	Access *SecretAccess `json:"access,omitempty"`
+
 	// id
 	// Read Only: true
 	ID strfmt.UUID `json:"id,omitempty"`
@@ -35,17 +38,29 @@ type Secret struct {
 	// Pattern: ^[\w\d\-]+$
 	Name *string `json:"name"`
 
+	// the rotation schedule of the secret
+	Rotation *SecretRotation `json:"rotation,omitempty"`
+
 	// secrets
 	Secrets SecretValue `json:"secrets,omitempty"`
 
 	// tags
 	Tags []*Tag `json:"tags"`
+
+	// the current version of the secret
+	// Read Only: true
+	Version int64 `json:"version,omitempty"`
 }
 
 // Validate validates this secret
 func (m *Secret) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateAccess(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
 	if err := m.validateID(formats); err != nil {
 		// prop
 		res = append(res, err)
@@ -61,6 +76,11 @@ func (m *Secret) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateRotation(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
 	if err := m.validateTags(formats); err != nil {
 		// prop
 		res = append(res, err)
@@ -72,6 +92,26 @@ func (m *Secret) Validate(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Secret) validateAccess(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Access) { // not required
+		return nil
+	}
+
+	if m.Access != nil {
+
+		if err := m.Access.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("access")
+			}
+			return err
+		}
+
+	}
+
+	return nil
+}
+
 func (m *Secret) validateID(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.ID) { // not required
@@ -111,6 +151,26 @@ func (m *Secret) validateName(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Secret) validateRotation(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Rotation) { // not required
+		return nil
+	}
+
+	if m.Rotation != nil {
+
+		if err := m.Rotation.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("rotation")
+			}
+			return err
+		}
+
+	}
+
+	return nil
+}
+
 func (m *Secret) validateTags(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.Tags) { // not required
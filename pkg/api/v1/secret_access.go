@@ -0,0 +1,133 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+)
+
+// NO TESTS
+
+// SecretAccess secret access
+// swagger:model SecretAccess
+type SecretAccess struct {
+
+	// the event drivers allowed to read the secret
+	Drivers []string `json:"drivers"`
+
+	// the functions allowed to read the secret
+	Functions []string `json:"functions"`
+
+	// the service accounts allowed to read the secret
+	ServiceAccounts []string `json:"serviceAccounts"`
+
+	// the subscriptions allowed to read the secret, for the functions they run
+	Subscriptions []string `json:"subscriptions"`
+
+	// the users allowed to read the secret
+	Users []string `json:"users"`
+}
+
+// Validate validates this secret access
+func (m *SecretAccess) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateDrivers(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateFunctions(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateServiceAccounts(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateSubscriptions(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateUsers(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *SecretAccess) validateDrivers(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Drivers) { // not required
+		return nil
+	}
+
+	return nil
+}
+
+func (m *SecretAccess) validateFunctions(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Functions) { // not required
+		return nil
+	}
+
+	return nil
+}
+
+func (m *SecretAccess) validateServiceAccounts(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.ServiceAccounts) { // not required
+		return nil
+	}
+
+	return nil
+}
+
+func (m *SecretAccess) validateSubscriptions(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Subscriptions) { // not required
+		return nil
+	}
+
+	return nil
+}
+
+func (m *SecretAccess) validateUsers(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Users) { // not required
+		return nil
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *SecretAccess) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *SecretAccess) UnmarshalBinary(b []byte) error {
+	var res SecretAccess
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
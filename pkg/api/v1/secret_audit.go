@@ -0,0 +1,66 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+)
+
+// NO TESTS
+
+// SecretAudit secret audit
+// swagger:model SecretAudit
+type SecretAudit struct {
+
+	// whether the secret values were returned
+	Allowed bool `json:"allowed,omitempty"`
+
+	// why the secret was read
+	Purpose string `json:"purpose,omitempty"`
+
+	// the time the secret was read (unix time)
+	ReadTime int64 `json:"readTime,omitempty"`
+
+	// the principals the secret was read for, e.g. function/hello
+	Requester string `json:"requester,omitempty"`
+
+	// the name of the secret
+	Secret string `json:"secret,omitempty"`
+
+	// the version of the secret read
+	Version int64 `json:"version,omitempty"`
+}
+
+// Validate validates this secret audit
+func (m *SecretAudit) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *SecretAudit) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *SecretAudit) UnmarshalBinary(b []byte) error {
+	var res SecretAudit
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
@@ -0,0 +1,116 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// NO TESTS
+
+// SecretRotation secret rotation
+// swagger:model SecretRotation
+type SecretRotation struct {
+
+	// the name of the function producing the new secret values
+	// Required: true
+	// Pattern: ^[\w\d\-]+$
+	Function *string `json:"function"`
+
+	// how often the secret is rotated, in seconds (0 only rotates on demand)
+	// Minimum: 0
+	Interval int64 `json:"interval,omitempty"`
+
+	// the time of the last rotation (unix time)
+	// Read Only: true
+	LastRotated int64 `json:"lastRotated,omitempty"`
+
+	// the reason the last rotation failed
+	// Read Only: true
+	Reason []string `json:"reason"`
+}
+
+// Validate validates this secret rotation
+func (m *SecretRotation) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateFunction(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateInterval(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateReason(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *SecretRotation) validateFunction(formats strfmt.Registry) error {
+
+	if err := validate.Required("function", "body", m.Function); err != nil {
+		return err
+	}
+
+	if err := validate.Pattern("function", "body", string(*m.Function), `^[\w\d\-]+$`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *SecretRotation) validateInterval(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Interval) { // not required
+		return nil
+	}
+
+	if err := validate.MinimumInt("interval", "body", int64(m.Interval), 0, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *SecretRotation) validateReason(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Reason) { // not required
+		return nil
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *SecretRotation) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *SecretRotation) UnmarshalBinary(b []byte) error {
+	var res SecretRotation
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
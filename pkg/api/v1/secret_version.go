@@ -0,0 +1,57 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+)
+
+// NO TESTS
+
+// SecretVersion secret version
+// swagger:model SecretVersion
+type SecretVersion struct {
+
+	// created time
+	CreatedTime int64 `json:"createdTime,omitempty"`
+
+	// whether the version is the current version of the secret
+	Current bool `json:"current,omitempty"`
+
+	// version
+	Version int64 `json:"version,omitempty"`
+}
+
+// Validate validates this secret version
+func (m *SecretVersion) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *SecretVersion) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *SecretVersion) UnmarshalBinary(b []byte) error {
+	var res SecretVersion
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
@@ -6,6 +6,8 @@
 package v1
 
 import (
+	"strconv"
+
 	strfmt "github.com/go-openapi/strfmt"
 
 	"github.com/go-openapi/errors"
@@ -30,6 +32,9 @@ type ServiceAccount struct {
 	// id
 	ID strfmt.UUID `json:"id,omitempty"`
 
+	// the active public keys, the public key first
+	Keys []*ServiceAccountKey `json:"keys"`
+
 	// kind
 	// Read Only: true
 	// Pattern: ^[\w\d\-]+$
@@ -48,6 +53,10 @@ type ServiceAccount struct {
 	// Required: true
 	PublicKey *string `json:"publicKey"`
 
+	// the tokens of the service account issued before this time are revoked
+	// Read Only: true
+	RevokedTime int64 `json:"revokedTime,omitempty"`
+
 	// status
 	// Read Only: true
 	Status Status `json:"status,omitempty"`
@@ -67,6 +76,11 @@ func (m *ServiceAccount) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateKeys(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
 	if err := m.validateKind(formats); err != nil {
 		// prop
 		res = append(res, err)
@@ -119,6 +133,34 @@ func (m *ServiceAccount) validateID(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *ServiceAccount) validateKeys(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Keys) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Keys); i++ {
+
+		if swag.IsZero(m.Keys[i]) { // not required
+			continue
+		}
+
+		if m.Keys[i] != nil {
+
+			if err := m.Keys[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("keys" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+
+		}
+
+	}
+
+	return nil
+}
+
 func (m *ServiceAccount) validateKind(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.Kind) { // not required
@@ -0,0 +1,79 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// NO TESTS
+
+// ServiceAccountKey a public key of a service account
+// swagger:model ServiceAccountKey
+type ServiceAccountKey struct {
+
+	// the signing algorithm of the key, e.g. RS256, ES256 or EdDSA
+	// Read Only: true
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// created time
+	// Read Only: true
+	CreatedTime int64 `json:"createdTime,omitempty"`
+
+	// the key ID, set as kid header of the tokens signed with the key
+	// Read Only: true
+	ID string `json:"id,omitempty"`
+
+	// the base64 encoded PEM public key
+	// Required: true
+	PublicKey *string `json:"publicKey"`
+}
+
+// Validate validates this service account key
+func (m *ServiceAccountKey) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validatePublicKey(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *ServiceAccountKey) validatePublicKey(formats strfmt.Registry) error {
+
+	if err := validate.Required("publicKey", "body", m.PublicKey); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *ServiceAccountKey) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *ServiceAccountKey) UnmarshalBinary(b []byte) error {
+	var res ServiceAccountKey
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
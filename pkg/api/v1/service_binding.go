@@ -6,10 +6,13 @@
 package v1
 
 import (
+	"strconv"
+
 	strfmt "github.com/go-openapi/strfmt"
 
 	"github.com/go-openapi/errors"
 	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
 )
 
 // NO TESTS
@@ -18,12 +21,28 @@ import (
 // swagger:model ServiceBinding
 type ServiceBinding struct {
 
+	// binding ID, the name of the secret holding the credentials of the binding
+	// Read Only: true
+	BindingID string `json:"bindingID,omitempty"`
+
 	// binding secret
 	BindingSecret string `json:"bindingSecret,omitempty"`
 
 	// created time
 	CreatedTime int64 `json:"createdTime,omitempty"`
 
+	// id
+	ID strfmt.UUID `json:"id,omitempty"`
+
+	// kind
+	// Read Only: true
+	// Pattern: ^[\w\d\-]+$
+	Kind string `json:"kind,omitempty"`
+
+	// name
+	// Pattern: ^[\w\d\-]+$
+	Name string `json:"name,omitempty"`
+
 	// parameters
 	Parameters interface{} `json:"parameters,omitempty"`
 
@@ -33,14 +52,36 @@ type ServiceBinding struct {
 	// secret parameters
 	SecretParameters []string `json:"secretParameters"`
 
+	// service instance
+	// Pattern: ^[\w\d\-]+$
+	ServiceInstance string `json:"serviceInstance,omitempty"`
+
 	// status
 	Status Status `json:"status,omitempty"`
+
+	// tags
+	Tags []*Tag `json:"tags"`
 }
 
 // Validate validates this service binding
 func (m *ServiceBinding) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateID(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateKind(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateName(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
 	if err := m.validateReason(formats); err != nil {
 		// prop
 		res = append(res, err)
@@ -51,17 +92,66 @@ func (m *ServiceBinding) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateServiceInstance(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
 	if err := m.validateStatus(formats); err != nil {
 		// prop
 		res = append(res, err)
 	}
 
+	if err := m.validateTags(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
 	return nil
 }
 
+func (m *ServiceBinding) validateID(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.ID) { // not required
+		return nil
+	}
+
+	if err := validate.FormatOf("id", "body", "uuid", m.ID.String(), formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *ServiceBinding) validateKind(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Kind) { // not required
+		return nil
+	}
+
+	if err := validate.Pattern("kind", "body", string(m.Kind), `^[\w\d\-]+$`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *ServiceBinding) validateName(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Name) { // not required
+		return nil
+	}
+
+	if err := validate.Pattern("name", "body", string(m.Name), `^[\w\d\-]+$`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (m *ServiceBinding) validateReason(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.Reason) { // not required
@@ -80,6 +170,19 @@ func (m *ServiceBinding) validateSecretParameters(formats strfmt.Registry) error
 	return nil
 }
 
+func (m *ServiceBinding) validateServiceInstance(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.ServiceInstance) { // not required
+		return nil
+	}
+
+	if err := validate.Pattern("serviceInstance", "body", string(m.ServiceInstance), `^[\w\d\-]+$`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (m *ServiceBinding) validateStatus(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.Status) { // not required
@@ -96,6 +199,34 @@ func (m *ServiceBinding) validateStatus(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *ServiceBinding) validateTags(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Tags) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Tags); i++ {
+
+		if swag.IsZero(m.Tags[i]) { // not required
+			continue
+		}
+
+		if m.Tags[i] != nil {
+
+			if err := m.Tags[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("tags" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+
+		}
+
+	}
+
+	return nil
+}
+
 // MarshalBinary interface implementation
 func (m *ServiceBinding) MarshalBinary() ([]byte, error) {
 	if m == nil {
@@ -0,0 +1,83 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// NO TESTS
+
+// Setup a request completing the first-run setup with the single-use setup token
+// swagger:model Setup
+type Setup struct {
+
+	// whether the setup is completed
+	// Read Only: true
+	Completed bool `json:"completed,omitempty"`
+
+	// an organization to create, with the user as member
+	Organization string `json:"organization,omitempty"`
+
+	// the base64 encoded PEM public key of the service account
+	PublicKey string `json:"publicKey,omitempty"`
+
+	// the first admin service account, created with the public key
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+
+	// the single-use setup token issued by the identity manager on first start
+	// Required: true
+	SetupToken *string `json:"setupToken"`
+
+	// the first admin user
+	User string `json:"user,omitempty"`
+}
+
+// Validate validates this setup
+func (m *Setup) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateSetupToken(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *Setup) validateSetupToken(formats strfmt.Registry) error {
+
+	if err := validate.Required("setupToken", "body", m.SetupToken); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *Setup) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *Setup) UnmarshalBinary(b []byte) error {
+	var res Setup
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
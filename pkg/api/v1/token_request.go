@@ -0,0 +1,70 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// NO TESTS
+
+// TokenRequest a request exchanging a token signed by a service account for an access token
+// swagger:model TokenRequest
+type TokenRequest struct {
+
+	// the token signed by the service account
+	// Required: true
+	Assertion *string `json:"assertion"`
+
+	// the requested lifetime of the access token, in seconds
+	Lifetime int64 `json:"lifetime,omitempty"`
+}
+
+// Validate validates this token request
+func (m *TokenRequest) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateAssertion(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *TokenRequest) validateAssertion(formats strfmt.Registry) error {
+
+	if err := validate.Required("assertion", "body", m.Assertion); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *TokenRequest) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *TokenRequest) UnmarshalBinary(b []byte) error {
+	var res TokenRequest
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
@@ -0,0 +1,74 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// NO TESTS
+
+// TokenRevocation revokes a token, or all the tokens of a service account
+// swagger:model TokenRevocation
+type TokenRevocation struct {
+
+	// the ID of the token to revoke
+	ID string `json:"id,omitempty"`
+
+	// the service account whose tokens to revoke
+	// Pattern: ^[\w\d\-\.]+$
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+}
+
+// Validate validates this token revocation
+func (m *TokenRevocation) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateServiceAccount(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *TokenRevocation) validateServiceAccount(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.ServiceAccount) { // not required
+		return nil
+	}
+
+	if err := validate.Pattern("serviceAccount", "body", string(m.ServiceAccount), `^[\w\d\-\.]+$`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *TokenRevocation) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *TokenRevocation) UnmarshalBinary(b []byte) error {
+	var res TokenRevocation
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
@@ -187,7 +187,19 @@ func (h *Handlers) getApps(params application.GetAppsParams, principal interface
 	opts := entitystore.Options{
 		Filter: entitystore.FilterExists(),
 	}
-	err := h.store.List(ctx, params.XDispatchOrg, opts, &apps)
+	filter, err := utils.ParseTags(opts.Filter, params.Tags)
+	if err == nil {
+		opts.Filter = filter
+		err = utils.ParsePaging(&opts, params.Limit, params.Continue, params.Sort)
+	}
+	if err != nil {
+		return application.NewGetAppsDefault(http.StatusBadRequest).WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
+	err = h.store.List(ctx, params.XDispatchOrg, opts, &apps)
 	if err != nil {
 		log.Errorf("store error when listing applications: %+v", err)
 		return application.NewGetAppsDefault(http.StatusInternalServerError).WithPayload(
@@ -200,7 +212,7 @@ func (h *Handlers) getApps(params application.GetAppsParams, principal interface
 	for _, app := range apps {
 		appModels = append(appModels, applicationEntityToModel(app))
 	}
-	return application.NewGetAppsOK().WithPayload(appModels)
+	return application.NewGetAppsOK().WithPayload(appModels).WithXDispatchContinue(utils.NextPage(opts))
 }
 
 func (h *Handlers) updateApp(params application.UpdateAppParams, principal interface{}) middleware.Responder {
@@ -0,0 +1,117 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+// Package audit records who created, updated or deleted resources, and the authorization decisions on those calls.
+package audit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+)
+
+// HeaderRequestID identifies a request across the services handling it
+const HeaderRequestID = "X-Request-Id"
+
+// Outcomes of audited calls
+const (
+	// OutcomeAllowed and OutcomeDenied are the outcomes of authorization decisions
+	OutcomeAllowed = "allowed"
+	OutcomeDenied  = "denied"
+	// OutcomeSucceeded and OutcomeFailed are the outcomes of the calls handled by the managers
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+)
+
+// Record is an audit record of a call
+type Record struct {
+	Time time.Time `json:"time"`
+	// Service is the service recording the call, e.g. function-manager
+	Service string `json:"service"`
+	// Principal is who made the call, e.g. user/jane@example.com or serviceaccount/ci
+	Principal    string `json:"principal"`
+	Organization string `json:"organization"`
+	// Resource is the type of the resource, e.g. function, and Name the name of the instance when known
+	Resource  string `json:"resource"`
+	Name      string `json:"name,omitempty"`
+	Action    string `json:"action"`
+	Outcome   string `json:"outcome"`
+	Status    int    `json:"status,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+	// BeforeRevision and AfterRevision are the revisions of the resource in the entity store around the call
+	BeforeRevision int64 `json:"beforeRevision,omitempty"`
+	AfterRevision  int64 `json:"afterRevision,omitempty"`
+}
+
+// ToModel converts the audit record to its swagger model
+func (r *Record) ToModel() *v1.AuditRecord {
+	return &v1.AuditRecord{
+		Time:           r.Time.Unix(),
+		Service:        r.Service,
+		Principal:      r.Principal,
+		Organization:   r.Organization,
+		Resource:       r.Resource,
+		Name:           r.Name,
+		Action:         r.Action,
+		Outcome:        r.Outcome,
+		Status:         int64(r.Status),
+		RequestID:      r.RequestID,
+		BeforeRevision: r.BeforeRevision,
+		AfterRevision:  r.AfterRevision,
+	}
+}
+
+// Sink writes audit records
+type Sink interface {
+	Write(ctx context.Context, record *Record) error
+}
+
+type recordKey struct{}
+
+// pending is a record being completed while its call is handled
+type pending struct {
+	sync.Mutex
+	record Record
+}
+
+// WithRecord returns a copy of ctx carrying the record of the call it is used for. The entity stores wrapped with
+// NewEntityStore complete the record with the name and revisions of the resource the call modifies.
+func WithRecord(ctx context.Context, record *Record) context.Context {
+	return context.WithValue(ctx, recordKey{}, &pending{record: *record})
+}
+
+// FromContext returns a copy of the record carried by ctx, or nil
+func FromContext(ctx context.Context) *Record {
+	p, ok := ctx.Value(recordKey{}).(*pending)
+	if !ok {
+		return nil
+	}
+	p.Lock()
+	defer p.Unlock()
+	record := p.record
+	return &record
+}
+
+// observe completes the record carried by ctx with a change of an entity, the first one of the resource named in
+// the record, or of any resource when the name is not known yet, e.g. when creating it
+func observe(ctx context.Context, name string, before uint64, after uint64) {
+	p, ok := ctx.Value(recordKey{}).(*pending)
+	if !ok {
+		return
+	}
+	p.Lock()
+	defer p.Unlock()
+	if p.record.Name == "" {
+		p.record.Name = name
+	} else if p.record.Name != name {
+		return
+	}
+	if p.record.BeforeRevision == 0 {
+		p.record.BeforeRevision = int64(before)
+	}
+	p.record.AfterRevision = int64(after)
+}
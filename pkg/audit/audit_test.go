@@ -0,0 +1,179 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/events"
+	eventsmocks "github.com/vmware/dispatch/pkg/events/mocks"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+type testEntity struct {
+	entitystore.BaseEntity
+	Value string `json:"value"`
+}
+
+func TestEntityStoreRevisions(t *testing.T) {
+	es := NewEntityStore(helpers.MakeEntityStore(t))
+
+	// Creating: the name is not known before the call
+	ctx := WithRecord(context.Background(), &Record{Resource: "function", Action: "create"})
+	hello := &testEntity{BaseEntity: entitystore.BaseEntity{OrganizationID: "dispatch", Name: "hello"}}
+	_, err := es.Add(ctx, hello)
+	require.NoError(t, err)
+	// other entities changed by the call are ignored
+	_, err = es.Add(ctx, &testEntity{BaseEntity: entitystore.BaseEntity{OrganizationID: "dispatch", Name: "other"}})
+	require.NoError(t, err)
+	record := FromContext(ctx)
+	assert.Equal(t, "hello", record.Name)
+	assert.Zero(t, record.BeforeRevision)
+	assert.Equal(t, int64(hello.Revision), record.AfterRevision)
+	created := hello.Revision
+
+	// Updating
+	ctx = WithRecord(context.Background(), &Record{Resource: "function", Name: "hello", Action: "update"})
+	hello.Value = "world"
+	revision, err := es.Update(ctx, hello.Revision, hello)
+	require.NoError(t, err)
+	record = FromContext(ctx)
+	assert.Equal(t, int64(created), record.BeforeRevision)
+	assert.Equal(t, revision, record.AfterRevision)
+	assert.NotEqual(t, record.BeforeRevision, record.AfterRevision)
+
+	// Deleting
+	ctx = WithRecord(context.Background(), &Record{Resource: "function", Name: "hello", Action: "delete"})
+	require.NoError(t, es.Delete(ctx, "dispatch", "hello", &testEntity{}))
+	record = FromContext(ctx)
+	assert.Zero(t, record.AfterRevision)
+
+	// Calls not audited are left alone
+	_, err = es.Add(context.Background(), &testEntity{BaseEntity: entitystore.BaseEntity{OrganizationID: "dispatch", Name: "hello"}})
+	require.NoError(t, err)
+	assert.Nil(t, FromContext(context.Background()))
+
+	// Transactions: the revisions are observed once committed
+	ctx = WithRecord(context.Background(), &Record{Resource: "function", Action: "create"})
+	tx := &testEntity{BaseEntity: entitystore.BaseEntity{OrganizationID: "dispatch", Name: "tx"}}
+	err = es.Tx(ctx, func(es entitystore.EntityStore) error {
+		_, err := es.Add(ctx, tx)
+		return err
+	})
+	require.NoError(t, err)
+	record = FromContext(ctx)
+	assert.Equal(t, "tx", record.Name)
+	assert.NotZero(t, record.AfterRevision)
+	assert.Equal(t, int64(tx.Revision), record.AfterRevision)
+}
+
+func TestStoreSink(t *testing.T) {
+	sink := NewStoreSink(helpers.MakeEntityStore(t), time.Hour, time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, sink.Write(ctx, &Record{
+		Service: "function-manager", Principal: "user/jane@example.com", Organization: "dispatch",
+		Resource: "function", Name: "hello", Action: "create", Outcome: OutcomeSucceeded, Status: 201,
+		RequestID: "req-1", AfterRevision: 3,
+	}))
+	require.NoError(t, sink.Write(ctx, &Record{
+		Service: "secret-store", Principal: "serviceaccount/ci", Organization: "dispatch",
+		Resource: "secret", Name: "psql-creds", Action: "delete", Outcome: OutcomeFailed, Status: 404,
+	}))
+	require.NoError(t, sink.Write(ctx, &Record{
+		Service: "function-manager", Principal: "user/jane@example.com", Organization: "org-a",
+		Resource: "function", Action: "create", Outcome: OutcomeSucceeded,
+	}))
+
+	records, err := sink.List(ctx, "dispatch", Query{})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "user/jane@example.com", records[0].Principal)
+	assert.Equal(t, "dispatch", records[0].Organization)
+	assert.Equal(t, "hello", records[0].Name)
+	assert.Equal(t, 201, records[0].Status)
+	assert.Equal(t, "req-1", records[0].RequestID)
+	assert.Equal(t, int64(3), records[0].AfterRevision)
+	assert.False(t, records[0].Time.IsZero())
+
+	records, err = sink.List(ctx, "dispatch", Query{Principal: "serviceaccount/ci"})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "psql-creds", records[0].Name)
+
+	records, err = sink.List(ctx, "dispatch", Query{Since: time.Now().Add(-time.Minute), Until: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+	records, err = sink.List(ctx, "dispatch", Query{Since: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	require.NoError(t, sink.Prune(ctx, time.Now().Add(time.Second)))
+	records, err = sink.List(ctx, "dispatch", Query{})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write(context.Background(), &Record{Principal: "user/jane@example.com", Action: "create"}))
+	require.NoError(t, sink.Write(context.Background(), &Record{Principal: "user/joe@example.com", Action: "delete"}))
+	require.NoError(t, sink.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+	require.Len(t, records, 2)
+	assert.Equal(t, "user/jane@example.com", records[0].Principal)
+	assert.Equal(t, "delete", records[1].Action)
+}
+
+func TestTransportSink(t *testing.T) {
+	transport := &eventsmocks.Transport{}
+	transport.On("Publish", mock.Anything, mock.MatchedBy(func(e *events.CloudEvent) bool {
+		var r Record
+		return e.EventType == EventType && json.Unmarshal([]byte(e.Data), &r) == nil && r.Principal == "user/jane@example.com"
+	}), "dispatch.audit", "org-a").Return(nil)
+
+	sink := NewTransportSink(transport, "dispatch.audit")
+	require.NoError(t, sink.Write(context.Background(), &Record{Service: "api-manager", Principal: "user/jane@example.com", Organization: "org-a"}))
+	transport.AssertExpectations(t)
+}
+
+func TestNewSink(t *testing.T) {
+	sink, closer, err := NewSink(&Flags{Sink: SinkNone}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, sink)
+	assert.NoError(t, closer.Close())
+
+	_, _, err = NewSink(&Flags{Sink: "syslog"}, nil)
+	assert.Error(t, err)
+}
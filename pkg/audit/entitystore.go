@@ -0,0 +1,104 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package audit
+
+import (
+	"context"
+
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+)
+
+// entityStore records the revisions of the entities changed by audited calls
+type entityStore struct {
+	entitystore.EntityStore
+	// observations are the observations of the writes of a transaction, made once it commits. It is nil out of
+	// transactions.
+	observations *[]func()
+}
+
+// NewEntityStore wraps an entity store to complete the records carried by the contexts of its calls with the name
+// and revisions of the entities they change
+func NewEntityStore(store entitystore.EntityStore) entitystore.EntityStore {
+	return &entityStore{EntityStore: store}
+}
+
+// Add adds new entities to the store
+func (s *entityStore) Add(ctx context.Context, entity entitystore.Entity) (string, error) {
+	id, err := s.EntityStore.Add(ctx, entity)
+	if err == nil {
+		s.observe(ctx, entity.GetName(), 0, entity)
+	}
+	return id, err
+}
+
+// Update updates existing entities to the store
+func (s *entityStore) Update(ctx context.Context, lastRevision uint64, entity entitystore.Entity) (int64, error) {
+	revision, err := s.EntityStore.Update(ctx, lastRevision, entity)
+	if err == nil {
+		s.observe(ctx, entity.GetName(), lastRevision, entity)
+	}
+	return revision, err
+}
+
+// Delete deletes a single entity from the store.
+func (s *entityStore) Delete(ctx context.Context, organizationID string, id string, entity entitystore.Entity) error {
+	before := entity.GetRevision()
+	err := s.EntityStore.Delete(ctx, organizationID, id, entity)
+	if err == nil {
+		name := entity.GetName()
+		if name == "" {
+			name = id
+		}
+		s.observe(ctx, name, before, nil)
+	}
+	return err
+}
+
+// SoftDelete sets the deleted flag and status, but does not actually delete the entity from the store.
+func (s *entityStore) SoftDelete(ctx context.Context, entity entitystore.Entity) error {
+	before := entity.GetRevision()
+	err := s.EntityStore.SoftDelete(ctx, entity)
+	if err == nil {
+		s.observe(ctx, entity.GetName(), before, entity)
+	}
+	return err
+}
+
+// Tx runs f in a transaction, the writes of which are observed once it commits
+func (s *entityStore) Tx(ctx context.Context, f func(tx entitystore.EntityStore) error) error {
+	// the writes of nested transactions are observed with the writes of the outer transaction
+	var committed []func()
+	observations := s.observations
+	if observations == nil {
+		observations = &committed
+	}
+	err := s.EntityStore.Tx(ctx, func(tx entitystore.EntityStore) error {
+		return f(&entityStore{EntityStore: tx, observations: observations})
+	})
+	if err == nil {
+		for _, o := range committed {
+			o()
+		}
+	}
+	return err
+}
+
+// observe observes the write of an entity, the revision after it is the revision of the entity written, or 0 if it
+// was deleted. The revisions of the entities written in a transaction are known once it commits.
+func (s *entityStore) observe(ctx context.Context, name string, before uint64, entity entitystore.Entity) {
+	o := func() {
+		after := uint64(0)
+		if entity != nil {
+			after = entity.GetRevision()
+		}
+		observe(ctx, name, before, after)
+	}
+	if s.observations != nil {
+		*s.observations = append(*s.observations, o)
+		return
+	}
+	o()
+}
@@ -0,0 +1,78 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware/dispatch/pkg/events"
+)
+
+// EventType is the type of the events published by TransportSink
+const EventType = "audit.record"
+
+// FileSink appends the audit records to a file, as JSON lines
+type FileSink struct {
+	mu  sync.Mutex
+	out io.WriteCloser
+}
+
+// NewFileSink creates a new sink appending to the file at path, created if needed
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error opening audit file %s", path)
+	}
+	return &FileSink{out: f}, nil
+}
+
+// Write appends an audit record to the file
+func (s *FileSink) Write(ctx context.Context, record *Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "error marshalling audit record")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.out.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	return s.out.Close()
+}
+
+// TransportSink publishes the audit records as events on a topic, to the tenant of their organization
+type TransportSink struct {
+	transport events.Transport
+	topic     string
+}
+
+// NewTransportSink creates a new sink publishing to the topic
+func NewTransportSink(transport events.Transport, topic string) *TransportSink {
+	return &TransportSink{transport: transport, topic: topic}
+}
+
+// Write publishes an audit record
+func (s *TransportSink) Write(ctx context.Context, record *Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "error marshalling audit record")
+	}
+	event := events.NewCloudEventWithDefaults(EventType)
+	event.SourceType = record.Service
+	event.SourceID = record.Service
+	event.ContentType = "application/json"
+	event.Data = string(data)
+	return s.transport.Publish(ctx, &event, s.topic, record.Organization)
+}
@@ -0,0 +1,206 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package audit
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/trace"
+)
+
+// RecordEntity is an audit record stored in the entity store
+type RecordEntity struct {
+	entitystore.BaseEntity
+	Service        string `json:"service"`
+	Principal      string `json:"principal"`
+	Resource       string `json:"resource"`
+	Instance       string `json:"instance,omitempty"`
+	Action         string `json:"action"`
+	Outcome        string `json:"outcome"`
+	Status         int    `json:"status,omitempty"`
+	RequestID      string `json:"requestId,omitempty"`
+	BeforeRevision int64  `json:"beforeRevision,omitempty"`
+	AfterRevision  int64  `json:"afterRevision,omitempty"`
+}
+
+// ToRecord converts the entity to the record it stores
+func (e *RecordEntity) ToRecord() *Record {
+	return &Record{
+		Time:           e.CreatedTime,
+		Service:        e.Service,
+		Principal:      e.Principal,
+		Organization:   e.OrganizationID,
+		Resource:       e.Resource,
+		Name:           e.Instance,
+		Action:         e.Action,
+		Outcome:        e.Outcome,
+		Status:         e.Status,
+		RequestID:      e.RequestID,
+		BeforeRevision: e.BeforeRevision,
+		AfterRevision:  e.AfterRevision,
+	}
+}
+
+// StoreSink writes the audit records to the entity store, and prunes the records older than the retention
+type StoreSink struct {
+	store     entitystore.EntityStore
+	retention time.Duration
+	period    time.Duration
+	done      chan bool
+}
+
+// NewStoreSink creates a new sink writing to the entity store, pruning every period the records older than the
+// retention (0 keeps all of them). The store must not be wrapped with NewEntityStore.
+func NewStoreSink(store entitystore.EntityStore, retention time.Duration, period time.Duration) *StoreSink {
+	return &StoreSink{
+		store:     store,
+		retention: retention,
+		period:    period,
+		done:      make(chan bool),
+	}
+}
+
+// Start starts pruning the records
+func (s *StoreSink) Start() {
+	go s.run()
+}
+
+// Shutdown stops pruning the records
+func (s *StoreSink) Shutdown() {
+	s.done <- true
+}
+
+func (s *StoreSink) run() {
+	ticker := time.NewTicker(s.period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if s.retention <= 0 {
+				continue
+			}
+			if err := s.Prune(context.Background(), time.Now().Add(-s.retention)); err != nil {
+				log.Errorf("error pruning the audit records: %+v", err)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Write stores an audit record in the organization of the record
+func (s *StoreSink) Write(ctx context.Context, record *Record) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	entity := &RecordEntity{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: record.Organization,
+			Name:           uuid.NewV4().String(),
+		},
+		Service:        record.Service,
+		Principal:      record.Principal,
+		Resource:       record.Resource,
+		Instance:       record.Name,
+		Action:         record.Action,
+		Outcome:        record.Outcome,
+		Status:         record.Status,
+		RequestID:      record.RequestID,
+		BeforeRevision: record.BeforeRevision,
+		AfterRevision:  record.AfterRevision,
+	}
+	_, err := s.store.Add(ctx, entity)
+	return err
+}
+
+// Query selects audit records, the zero value selects all of them
+type Query struct {
+	Since     time.Time
+	Until     time.Time
+	Principal string
+}
+
+// List lists the audit records of an organization, oldest first
+func (s *StoreSink) List(ctx context.Context, organizationID string, query Query) ([]*Record, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	filter := entitystore.FilterEverything()
+	if !query.Since.IsZero() {
+		// the store filters on strict inequalities, include the records of the very first second
+		filter.Add(entitystore.FilterStat{
+			Scope:   entitystore.FilterScopeField,
+			Subject: "CreatedTime",
+			Verb:    entitystore.FilterVerbAfter,
+			Object:  query.Since.Add(-time.Nanosecond),
+		})
+	}
+	if !query.Until.IsZero() {
+		filter.Add(entitystore.FilterStat{
+			Scope:   entitystore.FilterScopeField,
+			Subject: "CreatedTime",
+			Verb:    entitystore.FilterVerbBefore,
+			Object:  query.Until,
+		})
+	}
+	if query.Principal != "" {
+		filter.Add(entitystore.FilterStat{
+			Scope:   entitystore.FilterScopeExtra,
+			Subject: "Principal",
+			Verb:    entitystore.FilterVerbEqual,
+			Object:  query.Principal,
+		})
+	}
+	var entities []*RecordEntity
+	if err := s.store.List(ctx, organizationID, entitystore.Options{Filter: filter}, &entities); err != nil {
+		return nil, err
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].CreatedTime.Before(entities[j].CreatedTime)
+	})
+	records := []*Record{}
+	for _, entity := range entities {
+		records = append(records, entity.ToRecord())
+	}
+	return records, nil
+}
+
+// Prune deletes the records created before the given time
+func (s *StoreSink) Prune(ctx context.Context, before time.Time) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	orgIDs, err := s.store.ListOrgIDs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, orgID := range orgIDs {
+		var entities []*RecordEntity
+		opts := entitystore.Options{
+			Filter: entitystore.FilterEverything().Add(entitystore.FilterStat{
+				Scope:   entitystore.FilterScopeField,
+				Subject: "CreatedTime",
+				Verb:    entitystore.FilterVerbBefore,
+				Object:  before,
+			}),
+		}
+		if err := s.store.List(ctx, orgID, opts, &entities); err != nil {
+			return err
+		}
+		for _, entity := range entities {
+			if err := s.store.Delete(ctx, orgID, entity.Name, entity); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
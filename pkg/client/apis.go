@@ -10,8 +10,10 @@ import (
 
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
 	"github.com/pkg/errors"
 	swaggerclient "github.com/vmware/dispatch/pkg/api-manager/gen/client"
+	"github.com/vmware/dispatch/pkg/api-manager/gen/client/certificate"
 	"github.com/vmware/dispatch/pkg/api-manager/gen/client/endpoint"
 	"github.com/vmware/dispatch/pkg/api/v1"
 )
@@ -23,7 +25,14 @@ type APIsClient interface {
 	DeleteAPI(ctx context.Context, organizationID string, apiName string) (*v1.API, error)
 	UpdateAPI(ctx context.Context, organizationID string, api *v1.API) (*v1.API, error)
 	GetAPI(ctx context.Context, organizationID string, apiName string) (*v1.API, error)
-	ListAPIs(ctx context.Context, organizationID string) ([]v1.API, error)
+	ListAPIs(ctx context.Context, organizationID string, selector string) ([]v1.API, error)
+
+	// Certificates
+	CreateCertificate(ctx context.Context, organizationID string, cert *v1.Certificate) (*v1.Certificate, error)
+	DeleteCertificate(ctx context.Context, organizationID string, certName string) (*v1.Certificate, error)
+	UpdateCertificate(ctx context.Context, organizationID string, cert *v1.Certificate) (*v1.Certificate, error)
+	GetCertificate(ctx context.Context, organizationID string, certName string) (*v1.Certificate, error)
+	ListCertificates(ctx context.Context, organizationID string, selector string) ([]v1.Certificate, error)
 }
 
 // NewAPIsClient is used to create a new APIs client
@@ -103,20 +112,107 @@ func (c *DefaultAPIsClient) GetAPI(ctx context.Context, organizationID string, a
 	return response.Payload, nil
 }
 
-// ListAPIs returns a list of APIs
-func (c *DefaultAPIsClient) ListAPIs(ctx context.Context, organizationID string) ([]v1.API, error) {
+// ListAPIs returns a list of APIs matching a label selector, all if the selector is empty
+func (c *DefaultAPIsClient) ListAPIs(ctx context.Context, organizationID string, selector string) ([]v1.API, error) {
 	params := endpoint.GetApisParams{
 		Context:      ctx,
 		XDispatchOrg: c.getOrgID(organizationID),
+		Tags:         SelectorTerms(selector),
+		Limit:        swag.Int64(listPageSize),
+	}
+	apis := []v1.API{}
+	for {
+		response, err := c.client.Endpoint.GetApis(&params, c.auth)
+		if err != nil {
+			return nil, errors.Wrap(err, "error when listing apis")
+		}
+		for _, api := range response.Payload {
+			apis = append(apis, *api)
+		}
+		if response.XDispatchContinue == "" {
+			return apis, nil
+		}
+		params.Continue = swag.String(response.XDispatchContinue)
+	}
+}
+
+// CreateCertificate creates new certificate
+func (c *DefaultAPIsClient) CreateCertificate(ctx context.Context, organizationID string, cert *v1.Certificate) (*v1.Certificate, error) {
+	params := certificate.AddCertificateParams{
+		Context:      ctx,
+		Body:         cert,
+		XDispatchOrg: c.getOrgID(organizationID),
 	}
-	response, err := c.client.Endpoint.GetApis(&params, c.auth)
+	response, err := c.client.Certificate.AddCertificate(&params, c.auth)
 	if err != nil {
-		return nil, errors.Wrap(err, "error when listing apis")
+		return nil, errors.Wrap(err, "error when creating the certificate")
 	}
+	return response.Payload, nil
+}
 
-	apis := []v1.API{}
-	for _, api := range response.Payload {
-		apis = append(apis, *api)
+// DeleteCertificate deletes a certificate
+func (c *DefaultAPIsClient) DeleteCertificate(ctx context.Context, organizationID string, certName string) (*v1.Certificate, error) {
+	params := certificate.DeleteCertificateParams{
+		Context:      ctx,
+		Certificate:  certName,
+		XDispatchOrg: c.getOrgID(organizationID),
+	}
+	response, err := c.client.Certificate.DeleteCertificate(&params, c.auth)
+	if err != nil {
+		return nil, errors.Wrap(err, "error when deleting the certificate")
+	}
+	return response.Payload, nil
+}
+
+// UpdateCertificate updates a certificate
+func (c *DefaultAPIsClient) UpdateCertificate(ctx context.Context, organizationID string, cert *v1.Certificate) (*v1.Certificate, error) {
+	params := certificate.UpdateCertificateParams{
+		Context:      ctx,
+		Body:         cert,
+		Certificate:  *cert.Name,
+		XDispatchOrg: c.getOrgID(organizationID),
+	}
+	response, err := c.client.Certificate.UpdateCertificate(&params, c.auth)
+	if err != nil {
+		return nil, errors.Wrap(err, "error when updating the certificate")
+	}
+	return response.Payload, nil
+}
+
+// GetCertificate retrieves a certificate
+func (c *DefaultAPIsClient) GetCertificate(ctx context.Context, organizationID string, certName string) (*v1.Certificate, error) {
+	params := certificate.GetCertificateParams{
+		Context:      ctx,
+		Certificate:  certName,
+		XDispatchOrg: c.getOrgID(organizationID),
+	}
+	response, err := c.client.Certificate.GetCertificate(&params, c.auth)
+	if err != nil {
+		return nil, errors.Wrap(err, "error when getting the certificate")
+	}
+	return response.Payload, nil
+}
+
+// ListCertificates returns a list of certificates matching a label selector, all if the selector is empty
+func (c *DefaultAPIsClient) ListCertificates(ctx context.Context, organizationID string, selector string) ([]v1.Certificate, error) {
+	params := certificate.GetCertificatesParams{
+		Context:      ctx,
+		XDispatchOrg: c.getOrgID(organizationID),
+		Tags:         SelectorTerms(selector),
+		Limit:        swag.Int64(listPageSize),
+	}
+	certs := []v1.Certificate{}
+	for {
+		response, err := c.client.Certificate.GetCertificates(&params, c.auth)
+		if err != nil {
+			return nil, errors.Wrap(err, "error when listing certificates")
+		}
+		for _, cert := range response.Payload {
+			certs = append(certs, *cert)
+		}
+		if response.XDispatchContinue == "" {
+			return certs, nil
+		}
+		params.Continue = swag.String(response.XDispatchContinue)
 	}
-	return apis, nil
 }
@@ -36,3 +36,23 @@ func TestCreateAPI(t *testing.T) {
 	assert.Equal(t, apiResponse, apiBody)
 
 }
+
+func TestCreateCertificate(t *testing.T) {
+	fakeServer := fakeserver.NewFakeServer(nil)
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	aclient := client.NewAPIsClient(server.URL, nil, testOrgID)
+
+	certBody := &v1.Certificate{}
+
+	certResponse, err := aclient.CreateCertificate(context.Background(), testOrgID, certBody)
+	assert.Error(t, err)
+	assert.Nil(t, certResponse)
+
+	certMap := toMap(t, certBody)
+	fakeServer.AddResponse("POST", "/v1/api/certificate", certMap, certMap, 200)
+	certResponse, err = aclient.CreateCertificate(context.Background(), testOrgID, certBody)
+	assert.NoError(t, err)
+	assert.Equal(t, certResponse, certBody)
+}
@@ -17,6 +17,9 @@ import (
 // TokenHeaderName defines the cookie token
 const TokenHeaderName = "cookie"
 
+// listPageSize is the number of entities the list methods request per page, they page until the listing is complete
+const listPageSize = 500
+
 // AuthWithUserPassword authenticates with username and password
 func AuthWithUserPassword(username string, password string) runtime.ClientAuthInfoWriter {
 	return swaggerclient.BasicAuth(username, password)
@@ -50,7 +53,7 @@ func DefaultHTTPClient(host, basePath string) *swaggerclient.Runtime {
 
 	}
 	transport := swaggerclient.New(host, basePath, schemas)
-	transport.Transport = NewTracingRoundTripper(http.DefaultTransport)
+	transport.Transport = NewRequesterRoundTripper(NewTracingRoundTripper(http.DefaultTransport))
 	return transport
 }
 
@@ -65,3 +68,24 @@ func (c *baseClient) getOrgID(organizationID string) string {
 	}
 	return c.organizationID
 }
+
+// SelectorTerms splits a label selector, e.g. "app=shop,tier in (web,api)", into the terms the list operations take
+// as tags. The terms are separated by commas outside of parentheses.
+func SelectorTerms(selector string) []string {
+	terms := []string{}
+	depth, start := 0, 0
+	for i, c := range selector + "," {
+		switch {
+		case c == '(':
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+		case c == ',' && depth == 0:
+			if term := strings.TrimSpace(selector[start:i]); term != "" {
+				terms = append(terms, term)
+			}
+			start = i + 1
+		}
+	}
+	return terms
+}
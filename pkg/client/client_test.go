@@ -30,3 +30,10 @@ func toMap(t *testing.T, val interface{}) map[string]interface{} {
 	assert.NoError(t, json.Unmarshal(body, &ret))
 	return ret
 }
+
+func TestSelectorTerms(t *testing.T) {
+	assert.Equal(t, []string{}, client.SelectorTerms(""))
+	assert.Equal(t, []string{"app=shop"}, client.SelectorTerms(" app=shop "))
+	assert.Equal(t, []string{"app=shop", "tier in (web, api)", "!canary", "name^=web|name^=api"},
+		client.SelectorTerms("app=shop, tier in (web, api),,!canary,name^=web|name^=api"))
+}
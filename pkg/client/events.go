@@ -10,6 +10,7 @@ import (
 
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
 	"github.com/pkg/errors"
 
 	"github.com/vmware/dispatch/pkg/api/v1"
@@ -28,21 +29,21 @@ type EventsClient interface {
 	CreateSubscription(ctx context.Context, organizationID string, subscription *v1.Subscription) (*v1.Subscription, error)
 	DeleteSubscription(ctx context.Context, organizationID string, subscriptionName string) (*v1.Subscription, error)
 	GetSubscription(ctx context.Context, organizationID string, subscriptionName string) (*v1.Subscription, error)
-	ListSubscriptions(ctx context.Context, organizationID string) ([]v1.Subscription, error)
+	ListSubscriptions(ctx context.Context, organizationID string, selector string) ([]v1.Subscription, error)
 	UpdateSubscription(ctx context.Context, organizationID string, subscription *v1.Subscription) (*v1.Subscription, error)
 
 	// Event Drivers
 	CreateEventDriver(ctx context.Context, organizationID string, eventDriver *v1.EventDriver) (*v1.EventDriver, error)
 	DeleteEventDriver(ctx context.Context, organizationID string, eventDriverName string) (*v1.EventDriver, error)
 	GetEventDriver(ctx context.Context, organizationID string, eventDriverName string) (*v1.EventDriver, error)
-	ListEventDrivers(ctx context.Context, organizationID string) ([]v1.EventDriver, error)
+	ListEventDrivers(ctx context.Context, organizationID string, selector string) ([]v1.EventDriver, error)
 	UpdateEventDriver(ctx context.Context, organizationID string, eventDriver *v1.EventDriver) (*v1.EventDriver, error)
 
 	// Event Driver Types
 	CreateEventDriverType(ctx context.Context, organizationID string, eventDriverType *v1.EventDriverType) (*v1.EventDriverType, error)
 	DeleteEventDriverType(ctx context.Context, organizationID string, eventDriverTypeName string) (*v1.EventDriverType, error)
 	GetEventDriverType(ctx context.Context, organizationID string, eventDriverTypeName string) (*v1.EventDriverType, error)
-	ListEventDriverTypes(ctx context.Context, organizationID string) ([]v1.EventDriverType, error)
+	ListEventDriverTypes(ctx context.Context, organizationID string, selector string) ([]v1.EventDriverType, error)
 	UpdateEventDriverType(ctx context.Context, organizationID string, eventDriverType *v1.EventDriverType) (*v1.EventDriverType, error)
 }
 
@@ -122,21 +123,28 @@ func (c *DefaultEventsClient) GetSubscription(ctx context.Context, organizationI
 	return response.Payload, nil
 }
 
-// ListSubscriptions lists all subscriptions
-func (c *DefaultEventsClient) ListSubscriptions(ctx context.Context, organizationID string) ([]v1.Subscription, error) {
+// ListSubscriptions lists all subscriptions matching a label selector
+func (c *DefaultEventsClient) ListSubscriptions(ctx context.Context, organizationID string, selector string) ([]v1.Subscription, error) {
 	params := subscriptions.GetSubscriptionsParams{
 		Context:      ctx,
 		XDispatchOrg: c.getOrgID(organizationID),
-	}
-	response, err := c.client.Subscriptions.GetSubscriptions(&params, c.auth)
-	if err != nil {
-		return nil, errors.Wrap(err, "error when retrieving the subscriptions")
+		Tags:         SelectorTerms(selector),
+		Limit:        swag.Int64(listPageSize),
 	}
 	subscriptions := []v1.Subscription{}
-	for _, f := range response.Payload {
-		subscriptions = append(subscriptions, *f)
+	for {
+		response, err := c.client.Subscriptions.GetSubscriptions(&params, c.auth)
+		if err != nil {
+			return nil, errors.Wrap(err, "error when retrieving the subscriptions")
+		}
+		for _, f := range response.Payload {
+			subscriptions = append(subscriptions, *f)
+		}
+		if response.XDispatchContinue == "" {
+			return subscriptions, nil
+		}
+		params.Continue = swag.String(response.XDispatchContinue)
 	}
-	return subscriptions, nil
 }
 
 // UpdateSubscription updates a specific subscription
@@ -196,21 +204,28 @@ func (c *DefaultEventsClient) GetEventDriver(ctx context.Context, organizationID
 	return response.Payload, nil
 }
 
-// ListEventDrivers lists all drivers
-func (c *DefaultEventsClient) ListEventDrivers(ctx context.Context, organizationID string) ([]v1.EventDriver, error) {
+// ListEventDrivers lists all drivers matching a label selector
+func (c *DefaultEventsClient) ListEventDrivers(ctx context.Context, organizationID string, selector string) ([]v1.EventDriver, error) {
 	params := drivers.GetDriversParams{
 		Context:      ctx,
 		XDispatchOrg: c.getOrgID(organizationID),
-	}
-	response, err := c.client.Drivers.GetDrivers(&params, c.auth)
-	if err != nil {
-		return nil, errors.Wrap(err, "error when retrieving the drivers")
+		Tags:         SelectorTerms(selector),
+		Limit:        swag.Int64(listPageSize),
 	}
 	drivers := []v1.EventDriver{}
-	for _, f := range response.Payload {
-		drivers = append(drivers, *f)
+	for {
+		response, err := c.client.Drivers.GetDrivers(&params, c.auth)
+		if err != nil {
+			return nil, errors.Wrap(err, "error when retrieving the drivers")
+		}
+		for _, f := range response.Payload {
+			drivers = append(drivers, *f)
+		}
+		if response.XDispatchContinue == "" {
+			return drivers, nil
+		}
+		params.Continue = swag.String(response.XDispatchContinue)
 	}
-	return drivers, nil
 }
 
 // UpdateEventDriver updates a specific driver
@@ -270,21 +285,28 @@ func (c *DefaultEventsClient) GetEventDriverType(ctx context.Context, organizati
 	return response.Payload, nil
 }
 
-// ListEventDriverTypes lists all drivers
-func (c *DefaultEventsClient) ListEventDriverTypes(ctx context.Context, organizationID string) ([]v1.EventDriverType, error) {
+// ListEventDriverTypes lists all driver types matching a label selector
+func (c *DefaultEventsClient) ListEventDriverTypes(ctx context.Context, organizationID string, selector string) ([]v1.EventDriverType, error) {
 	params := drivers.GetDriverTypesParams{
 		Context:      ctx,
 		XDispatchOrg: c.getOrgID(organizationID),
-	}
-	response, err := c.client.Drivers.GetDriverTypes(&params, c.auth)
-	if err != nil {
-		return nil, errors.Wrap(err, "error when retrieving the driver types")
+		Tags:         SelectorTerms(selector),
+		Limit:        swag.Int64(listPageSize),
 	}
 	drivers := []v1.EventDriverType{}
-	for _, f := range response.Payload {
-		drivers = append(drivers, *f)
+	for {
+		response, err := c.client.Drivers.GetDriverTypes(&params, c.auth)
+		if err != nil {
+			return nil, errors.Wrap(err, "error when retrieving the driver types")
+		}
+		for _, f := range response.Payload {
+			drivers = append(drivers, *f)
+		}
+		if response.XDispatchContinue == "" {
+			return drivers, nil
+		}
+		params.Continue = swag.String(response.XDispatchContinue)
 	}
-	return drivers, nil
 }
 
 // UpdateEventDriverType updates a specific driver
@@ -10,6 +10,7 @@ import (
 
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
 	"github.com/pkg/errors"
 
 	"github.com/vmware/dispatch/pkg/api/v1"
@@ -23,14 +24,14 @@ type FunctionsClient interface {
 	// Function Runner
 	RunFunction(ctx context.Context, organizationID string, run *v1.Run) (*v1.Run, error)
 	GetFunctionRun(ctx context.Context, organizationID string, functionName string, runName string) (*v1.Run, error)
-	ListRuns(ctx context.Context, organizationID string) ([]v1.Run, error)
-	ListFunctionRuns(ctx context.Context, organizationID string, functionName string) ([]v1.Run, error)
+	ListRuns(ctx context.Context, organizationID string, selector string) ([]v1.Run, error)
+	ListFunctionRuns(ctx context.Context, organizationID string, functionName string, selector string) ([]v1.Run, error)
 
 	// Function store
 	CreateFunction(ctx context.Context, organizationID string, function *v1.Function) (*v1.Function, error)
 	DeleteFunction(ctx context.Context, organizationID string, functionName string) (*v1.Function, error)
 	GetFunction(ctx context.Context, organizationID string, functionName string) (*v1.Function, error)
-	ListFunctions(ctx context.Context, organizationID string) ([]v1.Function, error)
+	ListFunctions(ctx context.Context, organizationID string, selector string) ([]v1.Function, error)
 	UpdateFunction(ctx context.Context, organizationID string, function *v1.Function) (*v1.Function, error)
 }
 
@@ -92,39 +93,53 @@ func (c *DefaultFunctionsClient) GetFunctionRun(ctx context.Context, organizatio
 	return response.Payload, nil
 }
 
-// ListRuns lists all the available results from previous function runs
-func (c *DefaultFunctionsClient) ListRuns(ctx context.Context, organizationID string) ([]v1.Run, error) {
+// ListRuns lists all the available results from previous function runs matching a label selector
+func (c *DefaultFunctionsClient) ListRuns(ctx context.Context, organizationID string, selector string) ([]v1.Run, error) {
 	params := runner.GetRunsParams{
 		Context:      ctx,
 		XDispatchOrg: c.getOrgID(organizationID),
-	}
-	response, err := c.client.Runner.GetRuns(&params, c.auth)
-	if err != nil {
-		return nil, errors.Wrapf(err, "error when retrieving runs")
+		Tags:         SelectorTerms(selector),
+		Limit:        swag.Int64(listPageSize),
 	}
 	runs := []v1.Run{}
-	for _, run := range response.Payload {
-		runs = append(runs, *run)
+	for {
+		response, err := c.client.Runner.GetRuns(&params, c.auth)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error when retrieving runs")
+		}
+		for _, run := range response.Payload {
+			runs = append(runs, *run)
+		}
+		if response.XDispatchContinue == "" {
+			return runs, nil
+		}
+		params.Continue = swag.String(response.XDispatchContinue)
 	}
-	return runs, nil
 }
 
-// ListFunctionRuns lists the available results from specific function runs
-func (c *DefaultFunctionsClient) ListFunctionRuns(ctx context.Context, organizationID string, functionName string) ([]v1.Run, error) {
+// ListFunctionRuns lists the available results from specific function runs matching a label selector
+func (c *DefaultFunctionsClient) ListFunctionRuns(ctx context.Context, organizationID string, functionName string, selector string) ([]v1.Run, error) {
 	params := runner.GetRunsParams{
 		Context:      ctx,
 		XDispatchOrg: c.getOrgID(organizationID),
+		Tags:         SelectorTerms(selector),
 		FunctionName: &functionName,
-	}
-	response, err := c.client.Runner.GetRuns(&params, c.auth)
-	if err != nil {
-		return nil, errors.Wrapf(err, "error when retrieving runs for function %s", functionName)
+		Limit:        swag.Int64(listPageSize),
 	}
 	runs := []v1.Run{}
-	for _, run := range response.Payload {
-		runs = append(runs, *run)
+	for {
+		response, err := c.client.Runner.GetRuns(&params, c.auth)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error when retrieving runs for function %s", functionName)
+		}
+		for _, run := range response.Payload {
+			runs = append(runs, *run)
+		}
+		if response.XDispatchContinue == "" {
+			return runs, nil
+		}
+		params.Continue = swag.String(response.XDispatchContinue)
 	}
-	return runs, nil
 }
 
 // CreateFunction creates and adds a new function
@@ -169,21 +184,28 @@ func (c *DefaultFunctionsClient) GetFunction(ctx context.Context, organizationID
 	return response.Payload, nil
 }
 
-// ListFunctions lists all functions
-func (c *DefaultFunctionsClient) ListFunctions(ctx context.Context, organizationID string) ([]v1.Function, error) {
+// ListFunctions lists all functions matching a label selector
+func (c *DefaultFunctionsClient) ListFunctions(ctx context.Context, organizationID string, selector string) ([]v1.Function, error) {
 	params := store.GetFunctionsParams{
 		Context:      ctx,
 		XDispatchOrg: c.getOrgID(organizationID),
-	}
-	response, err := c.client.Store.GetFunctions(&params, c.auth)
-	if err != nil {
-		return nil, errors.Wrap(err, "error when retrieving the functions")
+		Tags:         SelectorTerms(selector),
+		Limit:        swag.Int64(listPageSize),
 	}
 	functions := []v1.Function{}
-	for _, f := range response.Payload {
-		functions = append(functions, *f)
+	for {
+		response, err := c.client.Store.GetFunctions(&params, c.auth)
+		if err != nil {
+			return nil, errors.Wrap(err, "error when retrieving the functions")
+		}
+		for _, f := range response.Payload {
+			functions = append(functions, *f)
+		}
+		if response.XDispatchContinue == "" {
+			return functions, nil
+		}
+		params.Continue = swag.String(response.XDispatchContinue)
 	}
-	return functions, nil
 }
 
 // UpdateFunction updates a specific function
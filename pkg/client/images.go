@@ -10,6 +10,7 @@ import (
 
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
 	"github.com/pkg/errors"
 	"github.com/vmware/dispatch/pkg/api/v1"
 	swaggerclient "github.com/vmware/dispatch/pkg/image-manager/gen/client"
@@ -24,14 +25,14 @@ type ImagesClient interface {
 	DeleteImage(ctx context.Context, organizationID string, imageName string) (*v1.Image, error)
 	UpdateImage(ctx context.Context, organizationID string, image *v1.Image) (*v1.Image, error)
 	GetImage(ctx context.Context, organizationID string, imageName string) (*v1.Image, error)
-	ListImages(ctx context.Context, organizationID string) ([]v1.Image, error)
+	ListImages(ctx context.Context, organizationID string, selector string) ([]v1.Image, error)
 
 	// BaseImages
 	CreateBaseImage(ctx context.Context, organizationID string, baseImage *v1.BaseImage) (*v1.BaseImage, error)
 	DeleteBaseImage(ctx context.Context, organizationID string, baseImageName string) (*v1.BaseImage, error)
 	UpdateBaseImage(ctx context.Context, organizationID string, baseImage *v1.BaseImage) (*v1.BaseImage, error)
 	GetBaseImage(ctx context.Context, organizationID string, baseImageName string) (*v1.BaseImage, error)
-	ListBaseImages(ctx context.Context, organizationID string) ([]v1.BaseImage, error)
+	ListBaseImages(ctx context.Context, organizationID string, selector string) ([]v1.BaseImage, error)
 }
 
 // NewImagesClient is used to create a new Images client
@@ -111,21 +112,28 @@ func (c *DefaultImagesClient) GetImage(ctx context.Context, organizationID strin
 	return response.Payload, nil
 }
 
-// ListImages returns a list of images
-func (c *DefaultImagesClient) ListImages(ctx context.Context, organizationID string) ([]v1.Image, error) {
+// ListImages returns a list of images matching a label selector, all if the selector is empty
+func (c *DefaultImagesClient) ListImages(ctx context.Context, organizationID string, selector string) ([]v1.Image, error) {
 	params := imageclient.GetImagesParams{
 		Context:      ctx,
 		XDispatchOrg: c.getOrgID(organizationID),
-	}
-	response, err := c.client.Image.GetImages(&params, c.auth)
-	if err != nil {
-		return nil, errors.Wrap(err, "error when listing images")
+		Tags:         SelectorTerms(selector),
+		Limit:        swag.Int64(listPageSize),
 	}
 	images := []v1.Image{}
-	for _, image := range response.Payload {
-		images = append(images, *image)
+	for {
+		response, err := c.client.Image.GetImages(&params, c.auth)
+		if err != nil {
+			return nil, errors.Wrap(err, "error when listing images")
+		}
+		for _, image := range response.Payload {
+			images = append(images, *image)
+		}
+		if response.XDispatchContinue == "" {
+			return images, nil
+		}
+		params.Continue = swag.String(response.XDispatchContinue)
 	}
-	return images, nil
 }
 
 // CreateBaseImage creates new base image
@@ -185,19 +193,26 @@ func (c *DefaultImagesClient) GetBaseImage(ctx context.Context, organizationID s
 	return response.Payload, nil
 }
 
-// ListBaseImages returns a list of base images
-func (c *DefaultImagesClient) ListBaseImages(ctx context.Context, organizationID string) ([]v1.BaseImage, error) {
+// ListBaseImages returns a list of base images matching a label selector, all if the selector is empty
+func (c *DefaultImagesClient) ListBaseImages(ctx context.Context, organizationID string, selector string) ([]v1.BaseImage, error) {
 	params := baseimageclient.GetBaseImagesParams{
 		Context:      ctx,
 		XDispatchOrg: c.getOrgID(organizationID),
-	}
-	response, err := c.client.BaseImage.GetBaseImages(&params, c.auth)
-	if err != nil {
-		return nil, errors.Wrap(err, "error when listing base images")
+		Tags:         SelectorTerms(selector),
+		Limit:        swag.Int64(listPageSize),
 	}
 	images := []v1.BaseImage{}
-	for _, image := range response.Payload {
-		images = append(images, *image)
+	for {
+		response, err := c.client.BaseImage.GetBaseImages(&params, c.auth)
+		if err != nil {
+			return nil, errors.Wrap(err, "error when listing base images")
+		}
+		for _, image := range response.Payload {
+			images = append(images, *image)
+		}
+		if response.XDispatchContinue == "" {
+			return images, nil
+		}
+		params.Continue = swag.String(response.XDispatchContinue)
 	}
-	return images, nil
 }
@@ -103,13 +103,13 @@ func (_m *FunctionsClient) GetFunctionRun(ctx context.Context, organizationID st
 	return r0, r1
 }
 
-// ListFunctionRuns provides a mock function with given fields: ctx, organizationID, functionName
-func (_m *FunctionsClient) ListFunctionRuns(ctx context.Context, organizationID string, functionName string) ([]v1.Run, error) {
-	ret := _m.Called(ctx, organizationID, functionName)
+// ListFunctionRuns provides a mock function with given fields: ctx, organizationID, functionName, selector
+func (_m *FunctionsClient) ListFunctionRuns(ctx context.Context, organizationID string, functionName string, selector string) ([]v1.Run, error) {
+	ret := _m.Called(ctx, organizationID, functionName, selector)
 
 	var r0 []v1.Run
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) []v1.Run); ok {
-		r0 = rf(ctx, organizationID, functionName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) []v1.Run); ok {
+		r0 = rf(ctx, organizationID, functionName, selector)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]v1.Run)
@@ -117,8 +117,8 @@ func (_m *FunctionsClient) ListFunctionRuns(ctx context.Context, organizationID
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, organizationID, functionName)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, organizationID, functionName, selector)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -126,13 +126,13 @@ func (_m *FunctionsClient) ListFunctionRuns(ctx context.Context, organizationID
 	return r0, r1
 }
 
-// ListFunctions provides a mock function with given fields: ctx, organizationID
-func (_m *FunctionsClient) ListFunctions(ctx context.Context, organizationID string) ([]v1.Function, error) {
-	ret := _m.Called(ctx, organizationID)
+// ListFunctions provides a mock function with given fields: ctx, organizationID, selector
+func (_m *FunctionsClient) ListFunctions(ctx context.Context, organizationID string, selector string) ([]v1.Function, error) {
+	ret := _m.Called(ctx, organizationID, selector)
 
 	var r0 []v1.Function
-	if rf, ok := ret.Get(0).(func(context.Context, string) []v1.Function); ok {
-		r0 = rf(ctx, organizationID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []v1.Function); ok {
+		r0 = rf(ctx, organizationID, selector)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]v1.Function)
@@ -140,8 +140,8 @@ func (_m *FunctionsClient) ListFunctions(ctx context.Context, organizationID str
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, organizationID)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, organizationID, selector)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -149,13 +149,13 @@ func (_m *FunctionsClient) ListFunctions(ctx context.Context, organizationID str
 	return r0, r1
 }
 
-// ListRuns provides a mock function with given fields: ctx, organizationID
-func (_m *FunctionsClient) ListRuns(ctx context.Context, organizationID string) ([]v1.Run, error) {
-	ret := _m.Called(ctx, organizationID)
+// ListRuns provides a mock function with given fields: ctx, organizationID, selector
+func (_m *FunctionsClient) ListRuns(ctx context.Context, organizationID string, selector string) ([]v1.Run, error) {
+	ret := _m.Called(ctx, organizationID, selector)
 
 	var r0 []v1.Run
-	if rf, ok := ret.Get(0).(func(context.Context, string) []v1.Run); ok {
-		r0 = rf(ctx, organizationID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []v1.Run); ok {
+		r0 = rf(ctx, organizationID, selector)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]v1.Run)
@@ -163,8 +163,8 @@ func (_m *FunctionsClient) ListRuns(ctx context.Context, organizationID string)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, organizationID)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, organizationID, selector)
 	} else {
 		r1 = ret.Error(1)
 	}
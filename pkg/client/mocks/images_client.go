@@ -149,13 +149,13 @@ func (_m *ImagesClient) GetImage(ctx context.Context, organizationID string, ima
 	return r0, r1
 }
 
-// ListBaseImages provides a mock function with given fields: ctx, organizationID
-func (_m *ImagesClient) ListBaseImages(ctx context.Context, organizationID string) ([]v1.BaseImage, error) {
-	ret := _m.Called(ctx, organizationID)
+// ListBaseImages provides a mock function with given fields: ctx, organizationID, selector
+func (_m *ImagesClient) ListBaseImages(ctx context.Context, organizationID string, selector string) ([]v1.BaseImage, error) {
+	ret := _m.Called(ctx, organizationID, selector)
 
 	var r0 []v1.BaseImage
-	if rf, ok := ret.Get(0).(func(context.Context, string) []v1.BaseImage); ok {
-		r0 = rf(ctx, organizationID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []v1.BaseImage); ok {
+		r0 = rf(ctx, organizationID, selector)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]v1.BaseImage)
@@ -163,8 +163,8 @@ func (_m *ImagesClient) ListBaseImages(ctx context.Context, organizationID strin
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, organizationID)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, organizationID, selector)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -172,13 +172,13 @@ func (_m *ImagesClient) ListBaseImages(ctx context.Context, organizationID strin
 	return r0, r1
 }
 
-// ListImages provides a mock function with given fields: ctx, organizationID
-func (_m *ImagesClient) ListImages(ctx context.Context, organizationID string) ([]v1.Image, error) {
-	ret := _m.Called(ctx, organizationID)
+// ListImages provides a mock function with given fields: ctx, organizationID, selector
+func (_m *ImagesClient) ListImages(ctx context.Context, organizationID string, selector string) ([]v1.Image, error) {
+	ret := _m.Called(ctx, organizationID, selector)
 
 	var r0 []v1.Image
-	if rf, ok := ret.Get(0).(func(context.Context, string) []v1.Image); ok {
-		r0 = rf(ctx, organizationID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []v1.Image); ok {
+		r0 = rf(ctx, organizationID, selector)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]v1.Image)
@@ -186,8 +186,8 @@ func (_m *ImagesClient) ListImages(ctx context.Context, organizationID string) (
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, organizationID)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, organizationID, selector)
 	} else {
 		r1 = ret.Error(1)
 	}
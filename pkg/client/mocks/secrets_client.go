@@ -71,6 +71,75 @@ func (_m *SecretsClient) GetSecret(ctx context.Context, organizationID string, s
 	return r0, r1
 }
 
+// GetSecretAudit provides a mock function with given fields: ctx, organizationID, secretName
+func (_m *SecretsClient) GetSecretAudit(ctx context.Context, organizationID string, secretName string) ([]v1.SecretAudit, error) {
+	ret := _m.Called(ctx, organizationID, secretName)
+
+	var r0 []v1.SecretAudit
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []v1.SecretAudit); ok {
+		r0 = rf(ctx, organizationID, secretName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]v1.SecretAudit)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, organizationID, secretName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSecretVersion provides a mock function with given fields: ctx, organizationID, secretName, version
+func (_m *SecretsClient) GetSecretVersion(ctx context.Context, organizationID string, secretName string, version int64) (*v1.Secret, error) {
+	ret := _m.Called(ctx, organizationID, secretName, version)
+
+	var r0 *v1.Secret
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64) *v1.Secret); ok {
+		r0 = rf(ctx, organizationID, secretName, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.Secret)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int64) error); ok {
+		r1 = rf(ctx, organizationID, secretName, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListSecretVersions provides a mock function with given fields: ctx, organizationID, secretName
+func (_m *SecretsClient) ListSecretVersions(ctx context.Context, organizationID string, secretName string) ([]v1.SecretVersion, error) {
+	ret := _m.Called(ctx, organizationID, secretName)
+
+	var r0 []v1.SecretVersion
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []v1.SecretVersion); ok {
+		r0 = rf(ctx, organizationID, secretName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]v1.SecretVersion)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, organizationID, secretName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListSecrets provides a mock function with given fields: ctx, organizationID
 func (_m *SecretsClient) ListSecrets(ctx context.Context, organizationID string) ([]v1.Secret, error) {
 	ret := _m.Called(ctx, organizationID)
@@ -94,6 +163,52 @@ func (_m *SecretsClient) ListSecrets(ctx context.Context, organizationID string)
 	return r0, r1
 }
 
+// RollbackSecret provides a mock function with given fields: ctx, organizationID, secretName, version
+func (_m *SecretsClient) RollbackSecret(ctx context.Context, organizationID string, secretName string, version int64) (*v1.Secret, error) {
+	ret := _m.Called(ctx, organizationID, secretName, version)
+
+	var r0 *v1.Secret
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64) *v1.Secret); ok {
+		r0 = rf(ctx, organizationID, secretName, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.Secret)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int64) error); ok {
+		r1 = rf(ctx, organizationID, secretName, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RotateSecret provides a mock function with given fields: ctx, organizationID, secretName
+func (_m *SecretsClient) RotateSecret(ctx context.Context, organizationID string, secretName string) (*v1.Secret, error) {
+	ret := _m.Called(ctx, organizationID, secretName)
+
+	var r0 *v1.Secret
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *v1.Secret); ok {
+		r0 = rf(ctx, organizationID, secretName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.Secret)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, organizationID, secretName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateSecret provides a mock function with given fields: ctx, organizationID, secret
 func (_m *SecretsClient) UpdateSecret(ctx context.Context, organizationID string, secret *v1.Secret) (*v1.Secret, error) {
 	ret := _m.Called(ctx, organizationID, secret)
@@ -11,13 +11,59 @@ type ServicesClient struct {
 	mock.Mock
 }
 
-// CreateServiceInstance provides a mock function with given fields: _a0, _a1
-func (_m *ServicesClient) CreateServiceInstance(_a0 context.Context, _a1 *v1.ServiceInstance) (*v1.ServiceInstance, error) {
-	ret := _m.Called(_a0, _a1)
+// CreateServiceBinding provides a mock function with given fields: ctx, organizationID, serviceBinding
+func (_m *ServicesClient) CreateServiceBinding(ctx context.Context, organizationID string, serviceBinding *v1.ServiceBinding) (*v1.ServiceBinding, error) {
+	ret := _m.Called(ctx, organizationID, serviceBinding)
+
+	var r0 *v1.ServiceBinding
+	if rf, ok := ret.Get(0).(func(context.Context, string, *v1.ServiceBinding) *v1.ServiceBinding); ok {
+		r0 = rf(ctx, organizationID, serviceBinding)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.ServiceBinding)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *v1.ServiceBinding) error); ok {
+		r1 = rf(ctx, organizationID, serviceBinding)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateServiceBroker provides a mock function with given fields: ctx, serviceBroker
+func (_m *ServicesClient) CreateServiceBroker(ctx context.Context, serviceBroker *v1.ServiceBroker) (*v1.ServiceBroker, error) {
+	ret := _m.Called(ctx, serviceBroker)
+
+	var r0 *v1.ServiceBroker
+	if rf, ok := ret.Get(0).(func(context.Context, *v1.ServiceBroker) *v1.ServiceBroker); ok {
+		r0 = rf(ctx, serviceBroker)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.ServiceBroker)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *v1.ServiceBroker) error); ok {
+		r1 = rf(ctx, serviceBroker)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateServiceInstance provides a mock function with given fields: ctx, organizationID, serviceInstance
+func (_m *ServicesClient) CreateServiceInstance(ctx context.Context, organizationID string, serviceInstance *v1.ServiceInstance) (*v1.ServiceInstance, error) {
+	ret := _m.Called(ctx, organizationID, serviceInstance)
 
 	var r0 *v1.ServiceInstance
-	if rf, ok := ret.Get(0).(func(context.Context, *v1.ServiceInstance) *v1.ServiceInstance); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, string, *v1.ServiceInstance) *v1.ServiceInstance); ok {
+		r0 = rf(ctx, organizationID, serviceInstance)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*v1.ServiceInstance)
@@ -25,8 +71,8 @@ func (_m *ServicesClient) CreateServiceInstance(_a0 context.Context, _a1 *v1.Ser
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *v1.ServiceInstance) error); ok {
-		r1 = rf(_a0, _a1)
+	if rf, ok := ret.Get(1).(func(context.Context, string, *v1.ServiceInstance) error); ok {
+		r1 = rf(ctx, organizationID, serviceInstance)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -34,13 +80,27 @@ func (_m *ServicesClient) CreateServiceInstance(_a0 context.Context, _a1 *v1.Ser
 	return r0, r1
 }
 
-// DeleteServiceInstance provides a mock function with given fields: ctx, serviceInstanceName
-func (_m *ServicesClient) DeleteServiceInstance(ctx context.Context, serviceInstanceName string) error {
-	ret := _m.Called(ctx, serviceInstanceName)
+// DeleteServiceBinding provides a mock function with given fields: ctx, organizationID, serviceBindingName
+func (_m *ServicesClient) DeleteServiceBinding(ctx context.Context, organizationID string, serviceBindingName string) error {
+	ret := _m.Called(ctx, organizationID, serviceBindingName)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, organizationID, serviceBindingName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteServiceBroker provides a mock function with given fields: ctx, serviceBrokerName
+func (_m *ServicesClient) DeleteServiceBroker(ctx context.Context, serviceBrokerName string) error {
+	ret := _m.Called(ctx, serviceBrokerName)
 
 	var r0 error
 	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
-		r0 = rf(ctx, serviceInstanceName)
+		r0 = rf(ctx, serviceBrokerName)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -48,6 +108,66 @@ func (_m *ServicesClient) DeleteServiceInstance(ctx context.Context, serviceInst
 	return r0
 }
 
+// DeleteServiceInstance provides a mock function with given fields: ctx, organizationID, serviceInstanceName
+func (_m *ServicesClient) DeleteServiceInstance(ctx context.Context, organizationID string, serviceInstanceName string) error {
+	ret := _m.Called(ctx, organizationID, serviceInstanceName)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, organizationID, serviceInstanceName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetServiceBinding provides a mock function with given fields: ctx, organizationID, serviceBindingName
+func (_m *ServicesClient) GetServiceBinding(ctx context.Context, organizationID string, serviceBindingName string) (*v1.ServiceBinding, error) {
+	ret := _m.Called(ctx, organizationID, serviceBindingName)
+
+	var r0 *v1.ServiceBinding
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *v1.ServiceBinding); ok {
+		r0 = rf(ctx, organizationID, serviceBindingName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.ServiceBinding)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, organizationID, serviceBindingName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetServiceBroker provides a mock function with given fields: ctx, serviceBrokerName
+func (_m *ServicesClient) GetServiceBroker(ctx context.Context, serviceBrokerName string) (*v1.ServiceBroker, error) {
+	ret := _m.Called(ctx, serviceBrokerName)
+
+	var r0 *v1.ServiceBroker
+	if rf, ok := ret.Get(0).(func(context.Context, string) *v1.ServiceBroker); ok {
+		r0 = rf(ctx, serviceBrokerName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.ServiceBroker)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, serviceBrokerName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetServiceClass provides a mock function with given fields: ctx, serviceClassName
 func (_m *ServicesClient) GetServiceClass(ctx context.Context, serviceClassName string) (*v1.ServiceClass, error) {
 	ret := _m.Called(ctx, serviceClassName)
@@ -71,22 +191,68 @@ func (_m *ServicesClient) GetServiceClass(ctx context.Context, serviceClassName
 	return r0, r1
 }
 
-// GetServiceInstance provides a mock function with given fields: ctx, serviceInstanceName
-func (_m *ServicesClient) GetServiceInstance(ctx context.Context, serviceInstanceName string) (*v1.ServiceInstance, error) {
-	ret := _m.Called(ctx, serviceInstanceName)
+// GetServiceInstance provides a mock function with given fields: ctx, organizationID, serviceInstanceName
+func (_m *ServicesClient) GetServiceInstance(ctx context.Context, organizationID string, serviceInstanceName string) (*v1.ServiceInstance, error) {
+	ret := _m.Called(ctx, organizationID, serviceInstanceName)
 
 	var r0 *v1.ServiceInstance
-	if rf, ok := ret.Get(0).(func(context.Context, string) *v1.ServiceInstance); ok {
-		r0 = rf(ctx, serviceInstanceName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *v1.ServiceInstance); ok {
+		r0 = rf(ctx, organizationID, serviceInstanceName)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*v1.ServiceInstance)
 		}
 	}
 
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, organizationID, serviceInstanceName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListServiceBindings provides a mock function with given fields: ctx, organizationID
+func (_m *ServicesClient) ListServiceBindings(ctx context.Context, organizationID string) ([]v1.ServiceBinding, error) {
+	ret := _m.Called(ctx, organizationID)
+
+	var r0 []v1.ServiceBinding
+	if rf, ok := ret.Get(0).(func(context.Context, string) []v1.ServiceBinding); ok {
+		r0 = rf(ctx, organizationID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]v1.ServiceBinding)
+		}
+	}
+
 	var r1 error
 	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, serviceInstanceName)
+		r1 = rf(ctx, organizationID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListServiceBrokers provides a mock function with given fields: _a0
+func (_m *ServicesClient) ListServiceBrokers(_a0 context.Context) ([]v1.ServiceBroker, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []v1.ServiceBroker
+	if rf, ok := ret.Get(0).(func(context.Context) []v1.ServiceBroker); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]v1.ServiceBroker)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -117,13 +283,13 @@ func (_m *ServicesClient) ListServiceClasses(_a0 context.Context) ([]v1.ServiceC
 	return r0, r1
 }
 
-// ListServiceInstances provides a mock function with given fields: _a0
-func (_m *ServicesClient) ListServiceInstances(_a0 context.Context) ([]v1.ServiceInstance, error) {
-	ret := _m.Called(_a0)
+// ListServiceInstances provides a mock function with given fields: ctx, organizationID
+func (_m *ServicesClient) ListServiceInstances(ctx context.Context, organizationID string) ([]v1.ServiceInstance, error) {
+	ret := _m.Called(ctx, organizationID)
 
 	var r0 []v1.ServiceInstance
-	if rf, ok := ret.Get(0).(func(context.Context) []v1.ServiceInstance); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, string) []v1.ServiceInstance); ok {
+		r0 = rf(ctx, organizationID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]v1.ServiceInstance)
@@ -131,8 +297,77 @@ func (_m *ServicesClient) ListServiceInstances(_a0 context.Context) ([]v1.Servic
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, organizationID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RefreshServiceBroker provides a mock function with given fields: ctx, serviceBrokerName
+func (_m *ServicesClient) RefreshServiceBroker(ctx context.Context, serviceBrokerName string) (*v1.ServiceBroker, error) {
+	ret := _m.Called(ctx, serviceBrokerName)
+
+	var r0 *v1.ServiceBroker
+	if rf, ok := ret.Get(0).(func(context.Context, string) *v1.ServiceBroker); ok {
+		r0 = rf(ctx, serviceBrokerName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.ServiceBroker)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, serviceBrokerName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RotateServiceBinding provides a mock function with given fields: ctx, organizationID, serviceBindingName
+func (_m *ServicesClient) RotateServiceBinding(ctx context.Context, organizationID string, serviceBindingName string) (*v1.ServiceBinding, error) {
+	ret := _m.Called(ctx, organizationID, serviceBindingName)
+
+	var r0 *v1.ServiceBinding
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *v1.ServiceBinding); ok {
+		r0 = rf(ctx, organizationID, serviceBindingName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.ServiceBinding)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, organizationID, serviceBindingName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateServiceInstance provides a mock function with given fields: ctx, organizationID, serviceInstance
+func (_m *ServicesClient) UpdateServiceInstance(ctx context.Context, organizationID string, serviceInstance *v1.ServiceInstance) (*v1.ServiceInstance, error) {
+	ret := _m.Called(ctx, organizationID, serviceInstance)
+
+	var r0 *v1.ServiceInstance
+	if rf, ok := ret.Get(0).(func(context.Context, string, *v1.ServiceInstance) *v1.ServiceInstance); ok {
+		r0 = rf(ctx, organizationID, serviceInstance)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.ServiceInstance)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *v1.ServiceInstance) error); ok {
+		r1 = rf(ctx, organizationID, serviceInstance)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,92 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	// HeaderRequester identifies the principals a request is made for, e.g. "subscription/on-push,function/build".
+	// Requests coming through the ingress get it from the identity manager, internal services set it themselves.
+	HeaderRequester = "X-Dispatch-Requester"
+	// HeaderPurpose describes why a request is made, e.g. "run 8f6c2a2e"
+	HeaderPurpose = "X-Dispatch-Purpose"
+)
+
+// Kinds of requesters
+const (
+	RequesterFunction       = "function"
+	RequesterSubscription   = "subscription"
+	RequesterDriver         = "driver"
+	RequesterServiceAccount = "serviceaccount"
+	RequesterUser           = "user"
+	// RequesterAPI identifies requests coming through an API endpoint, no secret access rule applies to them
+	RequesterAPI = "api"
+)
+
+// Requester returns the requester identifying a principal of the given kind, e.g. function/hello
+func Requester(kind string, name string) string {
+	return kind + "/" + name
+}
+
+// ParseRequesters splits the value of the requester header into the principals it identifies
+func ParseRequesters(header string) []string {
+	var requesters []string
+	for _, r := range strings.Split(header, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			requesters = append(requesters, r)
+		}
+	}
+	return requesters
+}
+
+type requesterKey struct{}
+
+type requesterValue struct {
+	requesters []string
+	purpose    string
+}
+
+// WithRequester returns a copy of ctx whose requests are made for the given requesters and purpose
+func WithRequester(ctx context.Context, purpose string, requesters ...string) context.Context {
+	return context.WithValue(ctx, requesterKey{}, requesterValue{requesters: requesters, purpose: purpose})
+}
+
+// RequesterFromContext returns the requesters and purpose set with WithRequester
+func RequesterFromContext(ctx context.Context) ([]string, string) {
+	v, _ := ctx.Value(requesterKey{}).(requesterValue)
+	return v.requesters, v.purpose
+}
+
+// NewRequesterRoundTripper returns new instance of RoundTripper
+func NewRequesterRoundTripper(next http.RoundTripper) *RequesterRoundTripper {
+	return &RequesterRoundTripper{
+		next: next,
+	}
+}
+
+// RequesterRoundTripper injects the requester headers into the request based on the context
+type RequesterRoundTripper struct {
+	next http.RoundTripper
+}
+
+// RoundTrip injects the requester and purpose into HTTP headers if request context contains them
+func (t *RequesterRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	if requesters, purpose := RequesterFromContext(r.Context()); len(requesters) > 0 {
+		r.Header.Set(HeaderRequester, strings.Join(requesters, ","))
+		if purpose != "" {
+			r.Header.Set(HeaderPurpose, purpose)
+		}
+	}
+	if t.next != nil {
+		return t.next.RoundTrip(r)
+	}
+	return nil, errors.New("missing next round tripper")
+}
@@ -0,0 +1,35 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware/dispatch/pkg/client"
+)
+
+func TestRequesterRoundTrip(t *testing.T) {
+	rt := client.NewRequesterRoundTripper(nil)
+	r, err := http.NewRequest("GET", "http://example.com", nil)
+	assert.NoError(t, err)
+
+	_, err = rt.RoundTrip(r)
+	assert.Error(t, err)
+	assert.Empty(t, r.Header.Get(client.HeaderRequester))
+
+	ctx := client.WithRequester(context.Background(), "run 42",
+		client.Requester(client.RequesterSubscription, "on-push"), client.Requester(client.RequesterFunction, "build"))
+	r = r.WithContext(ctx)
+	_, err = rt.RoundTrip(r)
+	assert.Error(t, err)
+	assert.Equal(t, "subscription/on-push,function/build", r.Header.Get(client.HeaderRequester))
+	assert.Equal(t, "run 42", r.Header.Get(client.HeaderPurpose))
+	assert.Equal(t, []string{"subscription/on-push", "function/build"}, client.ParseRequesters(r.Header.Get(client.HeaderRequester)))
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
 	"github.com/pkg/errors"
 
 	"github.com/vmware/dispatch/pkg/api/v1"
@@ -24,6 +25,11 @@ type SecretsClient interface {
 	UpdateSecret(ctx context.Context, organizationID string, secret *v1.Secret) (*v1.Secret, error)
 	GetSecret(ctx context.Context, organizationID string, secretName string) (*v1.Secret, error)
 	ListSecrets(ctx context.Context, organizationID string) ([]v1.Secret, error)
+	ListSecretVersions(ctx context.Context, organizationID string, secretName string) ([]v1.SecretVersion, error)
+	GetSecretVersion(ctx context.Context, organizationID string, secretName string, version int64) (*v1.Secret, error)
+	RollbackSecret(ctx context.Context, organizationID string, secretName string, version int64) (*v1.Secret, error)
+	RotateSecret(ctx context.Context, organizationID string, secretName string) (*v1.Secret, error)
+	GetSecretAudit(ctx context.Context, organizationID string, secretName string) ([]v1.SecretAudit, error)
 }
 
 // NewSecretsClient is used to create a new secrets client
@@ -107,14 +113,100 @@ func (c *DefaultSecretsClient) ListSecrets(ctx context.Context, organizationID s
 	params := secretclient.GetSecretsParams{
 		Context:      ctx,
 		XDispatchOrg: c.getOrgID(organizationID),
+		Limit:        swag.Int64(listPageSize),
 	}
-	response, err := c.client.Secret.GetSecrets(&params, c.auth)
+	secrets := []v1.Secret{}
+	for {
+		response, err := c.client.Secret.GetSecrets(&params, c.auth)
+		if err != nil {
+			return nil, errors.Wrap(err, "error when retrieving a secret")
+		}
+		for _, secret := range response.Payload {
+			secrets = append(secrets, *secret)
+		}
+		if response.XDispatchContinue == "" {
+			return secrets, nil
+		}
+		params.Continue = swag.String(response.XDispatchContinue)
+	}
+}
+
+// ListSecretVersions lists the versions of a secret, oldest first
+func (c *DefaultSecretsClient) ListSecretVersions(ctx context.Context, organizationID string, secretName string) ([]v1.SecretVersion, error) {
+	params := secretclient.GetSecretVersionsParams{
+		Context:      ctx,
+		XDispatchOrg: c.getOrgID(organizationID),
+		SecretName:   secretName,
+	}
+	response, err := c.client.Secret.GetSecretVersions(&params, c.auth)
 	if err != nil {
-		return nil, errors.Wrap(err, "error when retrieving a secret")
+		return nil, errors.Wrap(err, "error when retrieving the versions of a secret")
 	}
-	secrets := []v1.Secret{}
-	for _, secret := range response.Payload {
-		secrets = append(secrets, *secret)
+	versions := []v1.SecretVersion{}
+	for _, version := range response.Payload {
+		versions = append(versions, *version)
+	}
+	return versions, nil
+}
+
+// GetSecretVersion retrieves a version of a secret
+func (c *DefaultSecretsClient) GetSecretVersion(ctx context.Context, organizationID string, secretName string, version int64) (*v1.Secret, error) {
+	params := secretclient.GetSecretVersionParams{
+		Context:      ctx,
+		XDispatchOrg: c.getOrgID(organizationID),
+		SecretName:   secretName,
+		Version:      version,
+	}
+	response, err := c.client.Secret.GetSecretVersion(&params, c.auth)
+	if err != nil {
+		return nil, errors.Wrap(err, "error when retrieving a secret version")
+	}
+	return response.Payload, nil
+}
+
+// RollbackSecret makes the values of a previous version current, as a new version of the secret
+func (c *DefaultSecretsClient) RollbackSecret(ctx context.Context, organizationID string, secretName string, version int64) (*v1.Secret, error) {
+	params := secretclient.RollbackSecretParams{
+		Context:      ctx,
+		XDispatchOrg: c.getOrgID(organizationID),
+		SecretName:   secretName,
+		Version:      version,
+	}
+	response, err := c.client.Secret.RollbackSecret(&params, c.auth)
+	if err != nil {
+		return nil, errors.Wrap(err, "error when rolling back a secret")
+	}
+	return response.Payload, nil
+}
+
+// RotateSecret rotates a secret, by running its rotation function
+func (c *DefaultSecretsClient) RotateSecret(ctx context.Context, organizationID string, secretName string) (*v1.Secret, error) {
+	params := secretclient.RotateSecretParams{
+		Context:      ctx,
+		XDispatchOrg: c.getOrgID(organizationID),
+		SecretName:   secretName,
+	}
+	response, err := c.client.Secret.RotateSecret(&params, c.auth)
+	if err != nil {
+		return nil, errors.Wrap(err, "error when rotating a secret")
+	}
+	return response.Payload, nil
+}
+
+// GetSecretAudit lists the reads of the values of a secret, oldest first
+func (c *DefaultSecretsClient) GetSecretAudit(ctx context.Context, organizationID string, secretName string) ([]v1.SecretAudit, error) {
+	params := secretclient.GetSecretAuditParams{
+		Context:      ctx,
+		XDispatchOrg: c.getOrgID(organizationID),
+		SecretName:   secretName,
+	}
+	response, err := c.client.Secret.GetSecretAudit(&params, c.auth)
+	if err != nil {
+		return nil, errors.Wrap(err, "error when retrieving the audit of a secret")
+	}
+	audit := []v1.SecretAudit{}
+	for _, read := range response.Payload {
+		audit = append(audit, *read)
 	}
-	return secrets, nil
+	return audit, nil
 }
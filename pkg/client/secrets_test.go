@@ -36,3 +36,18 @@ func TestCreateSecret(t *testing.T) {
 	assert.Equal(t, secretResponse, secretBody)
 
 }
+
+func TestRollbackSecret(t *testing.T) {
+	fakeServer := fakeserver.NewFakeServer(nil)
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	sclient := client.NewSecretsClient(server.URL, nil, testOrgID)
+
+	secretBody := &v1.Secret{Version: 3, Secrets: v1.SecretValue{"password": "v1"}}
+	secretMap := toMap(t, secretBody)
+	fakeServer.AddResponse("POST", "/v1/secret/psql-creds/versions/1/rollback", nil, secretMap, 200)
+	secretResponse, err := sclient.RollbackSecret(context.Background(), testOrgID, "psql-creds", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, secretBody, secretResponse)
+}
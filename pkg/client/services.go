@@ -10,10 +10,13 @@ import (
 
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
 	"github.com/pkg/errors"
 
 	"github.com/vmware/dispatch/pkg/api/v1"
 	swaggerclient "github.com/vmware/dispatch/pkg/service-manager/gen/client"
+	servicebindingclient "github.com/vmware/dispatch/pkg/service-manager/gen/client/service_binding"
+	servicebrokerclient "github.com/vmware/dispatch/pkg/service-manager/gen/client/service_broker"
 	serviceclassclient "github.com/vmware/dispatch/pkg/service-manager/gen/client/service_class"
 	serviceinstanceclient "github.com/vmware/dispatch/pkg/service-manager/gen/client/service_instance"
 )
@@ -21,14 +24,29 @@ import (
 // ServicesClient defines the services client interface
 type ServicesClient interface {
 	// Service Instances
-	CreateServiceInstance(ctx context.Context, serviceInstance *v1.ServiceInstance) (*v1.ServiceInstance, error)
-	DeleteServiceInstance(ctx context.Context, serviceInstanceName string) error
-	GetServiceInstance(ctx context.Context, serviceInstanceName string) (*v1.ServiceInstance, error)
-	ListServiceInstances(ctx context.Context) ([]v1.ServiceInstance, error)
+	CreateServiceInstance(ctx context.Context, organizationID string, serviceInstance *v1.ServiceInstance) (*v1.ServiceInstance, error)
+	DeleteServiceInstance(ctx context.Context, organizationID string, serviceInstanceName string) error
+	GetServiceInstance(ctx context.Context, organizationID string, serviceInstanceName string) (*v1.ServiceInstance, error)
+	ListServiceInstances(ctx context.Context, organizationID string) ([]v1.ServiceInstance, error)
+	UpdateServiceInstance(ctx context.Context, organizationID string, serviceInstance *v1.ServiceInstance) (*v1.ServiceInstance, error)
+
+	// Service Bindings
+	CreateServiceBinding(ctx context.Context, organizationID string, serviceBinding *v1.ServiceBinding) (*v1.ServiceBinding, error)
+	DeleteServiceBinding(ctx context.Context, organizationID string, serviceBindingName string) error
+	GetServiceBinding(ctx context.Context, organizationID string, serviceBindingName string) (*v1.ServiceBinding, error)
+	ListServiceBindings(ctx context.Context, organizationID string) ([]v1.ServiceBinding, error)
+	RotateServiceBinding(ctx context.Context, organizationID string, serviceBindingName string) (*v1.ServiceBinding, error)
 
 	// Service Classes
 	GetServiceClass(ctx context.Context, serviceClassName string) (*v1.ServiceClass, error)
 	ListServiceClasses(ctx context.Context) ([]v1.ServiceClass, error)
+
+	// Service Brokers
+	CreateServiceBroker(ctx context.Context, serviceBroker *v1.ServiceBroker) (*v1.ServiceBroker, error)
+	DeleteServiceBroker(ctx context.Context, serviceBrokerName string) error
+	GetServiceBroker(ctx context.Context, serviceBrokerName string) (*v1.ServiceBroker, error)
+	ListServiceBrokers(ctx context.Context) ([]v1.ServiceBroker, error)
+	RefreshServiceBroker(ctx context.Context, serviceBrokerName string) (*v1.ServiceBroker, error)
 }
 
 // NewServicesClient is used to create a new serviceInstances client
@@ -52,10 +70,11 @@ type DefaultServicesClient struct {
 }
 
 // CreateServiceInstance creates a service instance
-func (c *DefaultServicesClient) CreateServiceInstance(ctx context.Context, instance *v1.ServiceInstance) (*v1.ServiceInstance, error) {
+func (c *DefaultServicesClient) CreateServiceInstance(ctx context.Context, organizationID string, instance *v1.ServiceInstance) (*v1.ServiceInstance, error) {
 	params := serviceinstanceclient.AddServiceInstanceParams{
-		Context: ctx,
-		Body:    instance,
+		Context:      ctx,
+		Body:         instance,
+		XDispatchOrg: c.getOrgID(organizationID),
 	}
 	response, err := c.client.ServiceInstance.AddServiceInstance(&params, c.auth)
 	if err != nil {
@@ -65,10 +84,11 @@ func (c *DefaultServicesClient) CreateServiceInstance(ctx context.Context, insta
 }
 
 // DeleteServiceInstance deletes a service instance
-func (c *DefaultServicesClient) DeleteServiceInstance(ctx context.Context, serviceInstanceName string) error {
+func (c *DefaultServicesClient) DeleteServiceInstance(ctx context.Context, organizationID string, serviceInstanceName string) error {
 	params := serviceinstanceclient.DeleteServiceInstanceByNameParams{
 		Context:             ctx,
 		ServiceInstanceName: serviceInstanceName,
+		XDispatchOrg:        c.getOrgID(organizationID),
 	}
 	_, err := c.client.ServiceInstance.DeleteServiceInstanceByName(&params, c.auth)
 	if err != nil {
@@ -78,10 +98,11 @@ func (c *DefaultServicesClient) DeleteServiceInstance(ctx context.Context, servi
 }
 
 // GetServiceInstance retrieves a service instance
-func (c *DefaultServicesClient) GetServiceInstance(ctx context.Context, serviceInstanceName string) (*v1.ServiceInstance, error) {
+func (c *DefaultServicesClient) GetServiceInstance(ctx context.Context, organizationID string, serviceInstanceName string) (*v1.ServiceInstance, error) {
 	params := serviceinstanceclient.GetServiceInstanceByNameParams{
 		Context:             ctx,
 		ServiceInstanceName: serviceInstanceName,
+		XDispatchOrg:        c.getOrgID(organizationID),
 	}
 	response, err := c.client.ServiceInstance.GetServiceInstanceByName(&params, c.auth)
 	if err != nil {
@@ -91,19 +112,120 @@ func (c *DefaultServicesClient) GetServiceInstance(ctx context.Context, serviceI
 }
 
 // ListServiceInstances lists service instances
-func (c *DefaultServicesClient) ListServiceInstances(ctx context.Context) ([]v1.ServiceInstance, error) {
+func (c *DefaultServicesClient) ListServiceInstances(ctx context.Context, organizationID string) ([]v1.ServiceInstance, error) {
 	params := serviceinstanceclient.GetServiceInstancesParams{
-		Context: ctx,
+		Context:      ctx,
+		XDispatchOrg: c.getOrgID(organizationID),
+		Limit:        swag.Int64(listPageSize),
+	}
+	var serviceInstances []v1.ServiceInstance
+	for {
+		response, err := c.client.ServiceInstance.GetServiceInstances(&params, c.auth)
+		if err != nil {
+			return nil, errors.Wrap(err, "error when retrieving service instances")
+		}
+		for _, serviceInstance := range response.Payload {
+			serviceInstances = append(serviceInstances, *serviceInstance)
+		}
+		if response.XDispatchContinue == "" {
+			return serviceInstances, nil
+		}
+		params.Continue = swag.String(response.XDispatchContinue)
+	}
+}
+
+// UpdateServiceInstance updates the plan and parameters of a service instance
+func (c *DefaultServicesClient) UpdateServiceInstance(ctx context.Context, organizationID string, instance *v1.ServiceInstance) (*v1.ServiceInstance, error) {
+	params := serviceinstanceclient.UpdateServiceInstanceByNameParams{
+		Context:             ctx,
+		Body:                instance,
+		ServiceInstanceName: *instance.Name,
+		XDispatchOrg:        c.getOrgID(organizationID),
 	}
-	response, err := c.client.ServiceInstance.GetServiceInstances(&params, c.auth)
+	response, err := c.client.ServiceInstance.UpdateServiceInstanceByName(&params, c.auth)
 	if err != nil {
-		return nil, errors.Wrap(err, "error when retrieving service instances")
+		return nil, errors.Wrap(err, "error when updating a service instance")
 	}
-	var serviceInstances []v1.ServiceInstance
-	for _, serviceInstance := range response.Payload {
-		serviceInstances = append(serviceInstances, *serviceInstance)
+	return response.Payload, nil
+}
+
+// CreateServiceBinding creates a service binding
+func (c *DefaultServicesClient) CreateServiceBinding(ctx context.Context, organizationID string, binding *v1.ServiceBinding) (*v1.ServiceBinding, error) {
+	params := servicebindingclient.AddServiceBindingParams{
+		Context:      ctx,
+		Body:         binding,
+		XDispatchOrg: c.getOrgID(organizationID),
+	}
+	response, err := c.client.ServiceBinding.AddServiceBinding(&params, c.auth)
+	if err != nil {
+		return nil, errors.Wrap(err, "error when creating a service binding")
+	}
+	return response.Payload, nil
+}
+
+// DeleteServiceBinding deletes a service binding, revoking its credentials
+func (c *DefaultServicesClient) DeleteServiceBinding(ctx context.Context, organizationID string, serviceBindingName string) error {
+	params := servicebindingclient.DeleteServiceBindingByNameParams{
+		Context:            ctx,
+		ServiceBindingName: serviceBindingName,
+		XDispatchOrg:       c.getOrgID(organizationID),
+	}
+	_, err := c.client.ServiceBinding.DeleteServiceBindingByName(&params, c.auth)
+	if err != nil {
+		return errors.Wrap(err, "error when deleting a service binding")
+	}
+	return nil
+}
+
+// GetServiceBinding retrieves a service binding
+func (c *DefaultServicesClient) GetServiceBinding(ctx context.Context, organizationID string, serviceBindingName string) (*v1.ServiceBinding, error) {
+	params := servicebindingclient.GetServiceBindingByNameParams{
+		Context:            ctx,
+		ServiceBindingName: serviceBindingName,
+		XDispatchOrg:       c.getOrgID(organizationID),
+	}
+	response, err := c.client.ServiceBinding.GetServiceBindingByName(&params, c.auth)
+	if err != nil {
+		return nil, errors.Wrap(err, "error when retrieving a service binding")
+	}
+	return response.Payload, nil
+}
+
+// ListServiceBindings lists service bindings
+func (c *DefaultServicesClient) ListServiceBindings(ctx context.Context, organizationID string) ([]v1.ServiceBinding, error) {
+	params := servicebindingclient.GetServiceBindingsParams{
+		Context:      ctx,
+		XDispatchOrg: c.getOrgID(organizationID),
+		Limit:        swag.Int64(listPageSize),
+	}
+	var serviceBindings []v1.ServiceBinding
+	for {
+		response, err := c.client.ServiceBinding.GetServiceBindings(&params, c.auth)
+		if err != nil {
+			return nil, errors.Wrap(err, "error when retrieving service bindings")
+		}
+		for _, serviceBinding := range response.Payload {
+			serviceBindings = append(serviceBindings, *serviceBinding)
+		}
+		if response.XDispatchContinue == "" {
+			return serviceBindings, nil
+		}
+		params.Continue = swag.String(response.XDispatchContinue)
+	}
+}
+
+// RotateServiceBinding binds the service of a service binding again, replacing its credentials
+func (c *DefaultServicesClient) RotateServiceBinding(ctx context.Context, organizationID string, serviceBindingName string) (*v1.ServiceBinding, error) {
+	params := servicebindingclient.RotateServiceBindingParams{
+		Context:            ctx,
+		ServiceBindingName: serviceBindingName,
+		XDispatchOrg:       c.getOrgID(organizationID),
+	}
+	response, err := c.client.ServiceBinding.RotateServiceBinding(&params, c.auth)
+	if err != nil {
+		return nil, errors.Wrap(err, "error when rotating a service binding")
 	}
-	return serviceInstances, nil
+	return response.Payload, nil
 }
 
 // GetServiceClass retrieves a service class
@@ -123,14 +245,94 @@ func (c *DefaultServicesClient) GetServiceClass(ctx context.Context, serviceClas
 func (c *DefaultServicesClient) ListServiceClasses(ctx context.Context) ([]v1.ServiceClass, error) {
 	params := serviceclassclient.GetServiceClassesParams{
 		Context: ctx,
+		Limit:   swag.Int64(listPageSize),
+	}
+	serviceClasses := []v1.ServiceClass{}
+	for {
+		response, err := c.client.ServiceClass.GetServiceClasses(&params, c.auth)
+		if err != nil {
+			return nil, errors.Wrap(err, "error when retrieving a service class")
+		}
+		for _, serviceClass := range response.Payload {
+			serviceClasses = append(serviceClasses, *serviceClass)
+		}
+		if response.XDispatchContinue == "" {
+			return serviceClasses, nil
+		}
+		params.Continue = swag.String(response.XDispatchContinue)
+	}
+}
+
+// CreateServiceBroker registers a service broker
+func (c *DefaultServicesClient) CreateServiceBroker(ctx context.Context, broker *v1.ServiceBroker) (*v1.ServiceBroker, error) {
+	params := servicebrokerclient.AddServiceBrokerParams{
+		Context: ctx,
+		Body:    broker,
 	}
-	response, err := c.client.ServiceClass.GetServiceClasses(&params, c.auth)
+	response, err := c.client.ServiceBroker.AddServiceBroker(&params, c.auth)
 	if err != nil {
-		return nil, errors.Wrap(err, "error when retrieving a service class")
+		return nil, errors.Wrap(err, "error when creating a service broker")
 	}
-	serviceClasses := []v1.ServiceClass{}
-	for _, serviceClass := range response.Payload {
-		serviceClasses = append(serviceClasses, *serviceClass)
+	return response.Payload, nil
+}
+
+// DeleteServiceBroker deletes a service broker
+func (c *DefaultServicesClient) DeleteServiceBroker(ctx context.Context, serviceBrokerName string) error {
+	params := servicebrokerclient.DeleteServiceBrokerByNameParams{
+		Context:           ctx,
+		ServiceBrokerName: serviceBrokerName,
+	}
+	_, err := c.client.ServiceBroker.DeleteServiceBrokerByName(&params, c.auth)
+	if err != nil {
+		return errors.Wrap(err, "error when deleting a service broker")
 	}
-	return serviceClasses, nil
+	return nil
+}
+
+// GetServiceBroker retrieves a service broker
+func (c *DefaultServicesClient) GetServiceBroker(ctx context.Context, serviceBrokerName string) (*v1.ServiceBroker, error) {
+	params := servicebrokerclient.GetServiceBrokerByNameParams{
+		Context:           ctx,
+		ServiceBrokerName: serviceBrokerName,
+	}
+	response, err := c.client.ServiceBroker.GetServiceBrokerByName(&params, c.auth)
+	if err != nil {
+		return nil, errors.Wrap(err, "error when retrieving a service broker")
+	}
+	return response.Payload, nil
+}
+
+// ListServiceBrokers lists service brokers
+func (c *DefaultServicesClient) ListServiceBrokers(ctx context.Context) ([]v1.ServiceBroker, error) {
+	params := servicebrokerclient.GetServiceBrokersParams{
+		Context: ctx,
+		Limit:   swag.Int64(listPageSize),
+	}
+	serviceBrokers := []v1.ServiceBroker{}
+	for {
+		response, err := c.client.ServiceBroker.GetServiceBrokers(&params, c.auth)
+		if err != nil {
+			return nil, errors.Wrap(err, "error when retrieving service brokers")
+		}
+		for _, serviceBroker := range response.Payload {
+			serviceBrokers = append(serviceBrokers, *serviceBroker)
+		}
+		if response.XDispatchContinue == "" {
+			return serviceBrokers, nil
+		}
+		params.Continue = swag.String(response.XDispatchContinue)
+	}
+}
+
+// RefreshServiceBroker refreshes the catalog of a service broker
+func (c *DefaultServicesClient) RefreshServiceBroker(ctx context.Context, serviceBrokerName string) (*v1.ServiceBroker, error) {
+	params := servicebrokerclient.RefreshServiceBrokerParams{
+		Context:           ctx,
+		ServiceBrokerName: serviceBrokerName,
+	}
+	response, err := c.client.ServiceBroker.RefreshServiceBroker(&params, c.auth)
+	if err != nil {
+		return nil, errors.Wrap(err, "error when refreshing a service broker")
+	}
+	return response.Payload, nil
 }
@@ -8,12 +8,12 @@ package controller
 import (
 	"context"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
-	"golang.org/x/sync/semaphore"
 
 	"github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/trace"
@@ -31,7 +31,19 @@ type EntityHandler interface {
 	Sync(ctx context.Context, resyncPeriod time.Duration) ([]entitystore.Entity, error)
 }
 
-const defaultWorkers = 1
+// WatchFilterer is implemented by the entity handlers filtering the changes of the entities they watch. Handlers
+// returning a nil filter only process the entities pushed to the watcher and the entities synced.
+type WatchFilterer interface {
+	WatchFilter() entitystore.Filter
+}
+
+const (
+	defaultWorkers    = 1
+	defaultMaxRetries = 5
+	defaultRetryDelay = time.Second
+	// maxRetryDelay caps the exponential backoff of the retries
+	maxRetryDelay = 5 * time.Minute
+)
 
 // Options defines controller configuration
 type Options struct {
@@ -39,6 +51,18 @@ type Options struct {
 
 	ResyncPeriod time.Duration
 	Workers      int
+
+	// MaxRetries is how many times an entity failing to be processed is retried, with exponential backoff from
+	// RetryDelay, before its status is set to ERROR
+	MaxRetries int
+	RetryDelay time.Duration
+
+	// Store, if set, is watched for the changes of the entities, including the changes made by other replicas
+	Store entitystore.EntityStore
+
+	// Ownership, if set, decides which replica reconciles the entities synced and the changes watched. The entities
+	// pushed to the watcher are reconciled by the replica they are pushed to, unless their changes are watched.
+	Ownership Ownership
 }
 
 // WatchEvent captures entity together with the associated context
@@ -74,6 +98,15 @@ type Controller interface {
 	Watcher() Watcher
 
 	AddEntityHandler(h EntityHandler)
+
+	Status() Status
+}
+
+// Status is the status of a controller, as reported by the health checks
+type Status struct {
+	// Ownership is the ownership of the replica, if the controller runs several replicas
+	Ownership *OwnershipStatus `json:"ownership,omitempty"`
+	Queue     QueueMetrics     `json:"queue"`
 }
 
 // DefaultController defines a struct for a generic controller
@@ -83,6 +116,12 @@ type DefaultController struct {
 	options Options
 
 	entityHandlers map[reflect.Type]EntityHandler
+	queue          *workQueue
+
+	mu sync.Mutex
+	// processed holds the last revisions processed of the entities watched
+	processed map[string]uint64
+	watched   map[reflect.Type]bool
 }
 
 // NewController creates a new controller
@@ -90,6 +129,12 @@ func NewController(options Options) Controller {
 	if options.Workers == 0 {
 		options.Workers = defaultWorkers
 	}
+	if options.MaxRetries == 0 {
+		options.MaxRetries = defaultMaxRetries
+	}
+	if options.RetryDelay == 0 {
+		options.RetryDelay = defaultRetryDelay
+	}
 
 	return &DefaultController{
 		done:    make(chan bool),
@@ -97,12 +142,62 @@ func NewController(options Options) Controller {
 		options: options,
 
 		entityHandlers: map[reflect.Type]EntityHandler{},
+		queue:          newWorkQueue(),
+		processed:      map[string]uint64{},
+		watched:        map[reflect.Type]bool{},
 	}
 }
 
 // Start starts the controller watch loop
 func (dc *DefaultController) Start() {
-	go dc.run(dc.done)
+	// the changes are watched once started
+	watchCtx, cancelWatch := context.WithCancel(context.Background())
+	storeEvents := make(chan entitystore.Event)
+	dc.watch(watchCtx, storeEvents)
+	dc.own(watchCtx)
+	go func() {
+		defer cancelWatch()
+		dc.run(dc.done, storeEvents)
+	}()
+}
+
+// own maintains the ownership of the entities until the context is done. The entities are synced when the entities
+// owned change, not to wait for the next periodic sync.
+func (dc *DefaultController) own(ctx context.Context) {
+	if dc.options.Ownership == nil {
+		return
+	}
+	changed := make(chan struct{}, 1)
+	go dc.options.Ownership.Run(ctx, changed)
+	go func() {
+		for {
+			select {
+			case <-changed:
+				log.Printf("syncing, the entities owned changed")
+				if err := dc.sync(); err != nil {
+					log.Error(err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// owns returns whether the replica reconciles an entity
+func (dc *DefaultController) owns(e entitystore.Entity, watched bool) bool {
+	if dc.options.Ownership == nil {
+		return true
+	}
+	if !watched {
+		dc.mu.Lock()
+		typeWatched := dc.watched[reflect.TypeOf(e)]
+		dc.mu.Unlock()
+		if !typeWatched {
+			return true
+		}
+	}
+	return dc.options.Ownership.Owns(e)
 }
 
 // Shutdown stops the controller loop
@@ -120,6 +215,23 @@ func (dc *DefaultController) AddEntityHandler(h EntityHandler) {
 	dc.entityHandlers[h.Type()] = h
 }
 
+// Status returns the ownership of the replica and the metrics of the work queue
+func (dc *DefaultController) Status() Status {
+	status := Status{Queue: dc.queue.snapshot()}
+	if dc.options.Ownership != nil {
+		ownership := dc.options.Ownership.Status()
+		status.Ownership = &ownership
+	}
+	return status
+}
+
+// HealthStatus returns the status of a controller, served with the health checks of its service
+func HealthStatus(c Controller) func() interface{} {
+	return func() interface{} {
+		return map[string]interface{}{"controller": c.Status()}
+	}
+}
+
 func (dc *DefaultController) processItem(ctx context.Context, e entitystore.Entity) error {
 	span, ctx := trace.Trace(ctx, "")
 	defer span.Finish()
@@ -150,6 +262,18 @@ func (dc *DefaultController) processItem(ctx context.Context, e entitystore.Enti
 	return err
 }
 
+func defaultWatchFilter() entitystore.Filter {
+	return entitystore.FilterEverything().Add(
+		entitystore.FilterStat{
+			Scope:   entitystore.FilterScopeField,
+			Subject: "Status",
+			Verb:    entitystore.FilterVerbIn,
+			Object: []entitystore.Status{
+				entitystore.StatusCREATING, entitystore.StatusUPDATING, entitystore.StatusDELETING,
+			},
+		})
+}
+
 func defaultSyncFilter(resyncPeriod time.Duration) entitystore.Filter {
 	now := time.Now().Add(-resyncPeriod)
 	return entitystore.FilterEverything().Add(
@@ -204,53 +328,231 @@ func DefaultSync(ctx context.Context, store entitystore.EntityStore, entityType
 func (dc *DefaultController) sync() error {
 	span, ctx := trace.Trace(context.Background(), "controller sync")
 	defer span.Finish()
-	sem := semaphore.NewWeighted(int64(dc.options.Workers))
 	for _, handler := range dc.entityHandlers {
 		entities, err := handler.Sync(ctx, dc.options.ResyncPeriod)
 		if err != nil {
 			return err
 		}
 		for _, e := range entities {
-			if err := sem.Acquire(ctx, 1); err != nil {
-				log.Printf("Failed to acquire semaphore: %v", err)
-				break
+			if !dc.owns(e, true) {
+				continue
+			}
+			if dc.queue.add(&queueItem{ctx: ctx, entity: e, source: sourceSynced}) {
+				log.Printf("sync: queued entity %s", e.GetName())
 			}
-			go func(e entitystore.Entity) {
-				defer sem.Release(1)
-				log.Printf("sync: processing entity %s", e.GetName())
-				if err := dc.processItem(ctx, e); err != nil {
-					span.LogKV("error", err)
-					log.Error(err)
-				}
-			}(e)
 		}
 	}
 	return nil
 }
 
+func entityKey(e entitystore.Entity) string {
+	return e.GetOrganizationID() + "/" + entitystore.GetDataType(e) + "/" + e.GetName()
+}
+
+// watch watches the store for the changes of the entities of the handlers, and forwards them to events until the
+// context is done
+func (dc *DefaultController) watch(ctx context.Context, events chan<- entitystore.Event) {
+	if dc.options.Store == nil {
+		return
+	}
+	for t, h := range dc.entityHandlers {
+		filter := defaultWatchFilter()
+		if f, ok := h.(WatchFilterer); ok {
+			if filter = f.WatchFilter(); filter == nil {
+				continue
+			}
+		}
+		ch, err := dc.options.Store.Watch(ctx, entitystore.WatchAllOrganizations, t, filter)
+		if err != nil {
+			log.Errorf("error watching %v, relying on periodic syncing: %+v", t, err)
+			continue
+		}
+		dc.mu.Lock()
+		dc.watched[t] = true
+		dc.mu.Unlock()
+		go dc.forward(ctx, t, filter, ch, events)
+	}
+}
+
+// forward forwards the changes watched of the entities of a type to events until the context is done. When the store
+// ends the watch because changes were lost, the entities are watched again and synced.
+func (dc *DefaultController) forward(ctx context.Context, t reflect.Type, filter entitystore.Filter, ch <-chan entitystore.Event, events chan<- entitystore.Event) {
+	for {
+		for event := range ch {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		log.Warnf("watch of %v lost changes, watching again and syncing", t)
+		var err error
+		if ch, err = dc.options.Store.Watch(ctx, entitystore.WatchAllOrganizations, t, filter); err != nil {
+			log.Errorf("error watching %v, relying on periodic syncing: %+v", t, err)
+			dc.mu.Lock()
+			dc.watched[t] = false
+			dc.mu.Unlock()
+			return
+		}
+		if err := dc.sync(); err != nil {
+			log.Error(err)
+		}
+	}
+}
+
+// seen returns whether the revision of a change watched was processed already
+func (dc *DefaultController) seen(e entitystore.Entity) bool {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	revision, ok := dc.processed[entityKey(e)]
+	return ok && e.GetRevision() <= revision
+}
+
+// markProcessed records the revision processed of an entity
+func (dc *DefaultController) markProcessed(e entitystore.Entity, key string) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	// only the revisions of the entities watched are needed, they are forgotten when the entities are deleted
+	if dc.watched[reflect.TypeOf(e)] {
+		dc.processed[key] = e.GetRevision()
+	}
+}
+
+// forget forgets the revisions processed of a deleted entity
+func (dc *DefaultController) forget(e entitystore.Entity) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	delete(dc.processed, entityKey(e))
+}
+
+// retryDelay returns the backoff before retrying an entity which failed to be processed a number of times
+func (dc *DefaultController) retryDelay(failures int) time.Duration {
+	delay := dc.options.RetryDelay
+	for i := 1; i < failures && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// refresh gets the latest revision of an entity from the store. It returns nil if the entity is deleted, or settled
+// by its handler: only the entities still pending are retried.
+func (dc *DefaultController) refresh(ctx context.Context, e entitystore.Entity) entitystore.Entity {
+	if dc.options.Store == nil {
+		return e
+	}
+	latest := reflect.New(reflect.TypeOf(e).Elem()).Interface().(entitystore.Entity)
+	found, err := dc.options.Store.Find(ctx, e.GetOrganizationID(), e.GetName(), entitystore.Options{}, latest)
+	if err != nil {
+		log.Errorf("error getting %s to retry it, retrying the revision which failed: %+v", entityKey(e), err)
+		return e
+	}
+	if !found {
+		return nil
+	}
+	switch latest.GetStatus() {
+	case entitystore.StatusREADY, entitystore.StatusERROR:
+		if !latest.GetDelete() {
+			return nil
+		}
+	}
+	return latest
+}
+
+// fail retries an entity which failed to be processed with exponential backoff, and sets its status to ERROR once it
+// failed too many times
+func (dc *DefaultController) fail(key string, item *queueItem, err error) {
+	failures := dc.queue.fail(key)
+	if failures <= dc.options.MaxRetries {
+		delay := dc.retryDelay(failures)
+		log.Warnf("error processing %s, retrying in %s (%d/%d): %v", key, delay, failures, dc.options.MaxRetries, err)
+		dc.queue.addAfter(delay, func() *queueItem {
+			e := dc.refresh(context.Background(), item.entity)
+			if e == nil {
+				dc.queue.forget(key, false)
+				return nil
+			}
+			return &queueItem{ctx: item.ctx, entity: e}
+		})
+		return
+	}
+
+	log.Errorf("error processing %s, giving up after %d retries: %v", key, dc.options.MaxRetries, err)
+	dc.queue.forget(key, true)
+	if dc.options.Store == nil {
+		return
+	}
+	ctx := context.Background()
+	e := dc.refresh(ctx, item.entity)
+	if e == nil {
+		return
+	}
+	e.SetStatus(entitystore.StatusERROR)
+	e.SetReason([]string{err.Error()})
+	if _, err := dc.options.Store.Update(ctx, e.GetRevision(), e); err != nil {
+		log.Errorf("error setting the status of %s to ERROR: %+v", key, err)
+	}
+}
+
+// work processes the entities of the queue until it is shut down
+func (dc *DefaultController) work() {
+	for {
+		key, item, ok := dc.queue.get()
+		if !ok {
+			return
+		}
+		started := time.Now()
+		log.Printf("received event=%s entity=%s", item.entity.GetStatus(), item.entity.GetName())
+		if err := dc.processItem(item.ctx, item.entity); err != nil {
+			dc.fail(key, item, err)
+		} else {
+			dc.queue.forget(key, false)
+		}
+		dc.markProcessed(item.entity, key)
+		dc.queue.done(key, started)
+	}
+}
+
 // run runs the control loop
-func (dc *DefaultController) run(stopChan <-chan bool) {
+func (dc *DefaultController) run(stopChan <-chan bool, storeEvents <-chan entitystore.Event) {
 	resyncTicker := time.NewTicker(dc.options.ResyncPeriod)
 	defer resyncTicker.Stop()
 
 	defer close(dc.watcher)
+	defer dc.queue.stop()
+
+	// Start a worker pool of dc.options.Workers workers
+	for i := 0; i < dc.options.Workers; i++ {
+		go dc.work()
+	}
 
-	// Start a worker pool.  The pool scales up to dc.options.Workers.
 	go func() {
-		sem := semaphore.NewWeighted(int64(dc.options.Workers))
-		for watchEvent := range dc.watcher {
-			if err := sem.Acquire(context.Background(), 1); err != nil {
-				log.Printf("Failed to acquire semaphore: %v", err)
-				break
-			}
-			go func(event WatchEvent) {
-				e := event.Entity
-				defer sem.Release(1)
-				log.Printf("received event=%s entity=%s", e.GetStatus(), e.GetName())
-				if err := dc.processItem(event.Ctx, e); err != nil {
-					log.Error(err)
+		for {
+			select {
+			case watchEvent, ok := <-dc.watcher:
+				if !ok {
+					return
+				}
+				if dc.owns(watchEvent.Entity, false) {
+					dc.queue.add(&queueItem{ctx: watchEvent.Ctx, entity: watchEvent.Entity, source: sourcePushed})
 				}
-			}(watchEvent)
+			case event := <-storeEvents:
+				if event.Type == entitystore.EventDelete {
+					dc.forget(event.Entity)
+					continue
+				}
+				if dc.owns(event.Entity, true) && !dc.seen(event.Entity) {
+					dc.queue.add(&queueItem{ctx: context.Background(), entity: event.Entity, source: sourceWatched})
+				}
+			}
 		}
 	}()
 
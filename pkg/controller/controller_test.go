@@ -8,9 +8,11 @@ package controller
 import (
 	"context"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/vmware/dispatch/pkg/entity-store"
@@ -110,3 +112,141 @@ func TestController(t *testing.T) {
 		t.Logf("deleted %s", name)
 	}
 }
+
+func TestControllerWatch(t *testing.T) {
+	ctx := context.Background()
+	store := helpers.MakeEntityStore(t)
+
+	deleteCounter := make(chan string, 100)
+	addCounter := make(chan string, 100)
+
+	controller := NewController(Options{
+		ResyncPeriod: time.Hour,
+		Store:        store,
+	})
+	controller.AddEntityHandler(&testEntityHandler{t: t, store: store, addCounter: addCounter, deleteCounter: deleteCounter})
+
+	controller.Start()
+	defer controller.Shutdown()
+
+	// the entities changed in the store are processed without being pushed to the watcher
+	ent := &testEntity{entitystore.BaseEntity{
+		OrganizationID: testOrgID,
+		Name:           "test-watch",
+		Status:         entitystore.StatusCREATING,
+	}}
+	_, err := store.Add(ctx, ent)
+	assert.NoError(t, err)
+	select {
+	case name := <-addCounter:
+		assert.Equal(t, "test-watch", name)
+	case <-time.After(testSleepDuration):
+		t.Error("timeout waiting for the added entity to be processed")
+	}
+
+	assert.NoError(t, store.SoftDelete(ctx, ent))
+	select {
+	case name := <-deleteCounter:
+		assert.Equal(t, "test-watch", name)
+	case <-time.After(testSleepDuration):
+		t.Error("timeout waiting for the deleted entity to be processed")
+	}
+}
+
+// failingEntityHandler fails to add the entities a number of times
+type failingEntityHandler struct {
+	testEntityHandler
+	failures int
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (h *failingEntityHandler) Add(ctx context.Context, obj entitystore.Entity) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls++
+	if h.calls <= h.failures {
+		return errors.Errorf("failure %d", h.calls)
+	}
+	return h.testEntityHandler.Add(ctx, obj)
+}
+
+func TestControllerRetry(t *testing.T) {
+	ctx := context.Background()
+	store := helpers.MakeEntityStore(t)
+
+	addCounter := make(chan string, 100)
+	controller := NewController(Options{
+		ResyncPeriod: time.Hour,
+		Store:        store,
+		MaxRetries:   3,
+		RetryDelay:   10 * time.Millisecond,
+	})
+	controller.AddEntityHandler(&failingEntityHandler{
+		testEntityHandler: testEntityHandler{t: t, store: store, addCounter: addCounter, deleteCounter: make(chan string, 100)},
+		failures:          2,
+	})
+
+	controller.Start()
+	defer controller.Shutdown()
+
+	_, err := store.Add(ctx, &testEntity{entitystore.BaseEntity{
+		OrganizationID: testOrgID,
+		Name:           "test-retry",
+		Status:         entitystore.StatusCREATING,
+	}})
+	assert.NoError(t, err)
+	select {
+	case name := <-addCounter:
+		assert.Equal(t, "test-retry", name)
+	case <-time.After(testSleepDuration):
+		t.Fatal("timeout waiting for the entity to be retried")
+	}
+	queue := controller.Status().Queue
+	assert.Equal(t, uint64(2), queue.Retries)
+	assert.Equal(t, uint64(0), queue.Failures)
+}
+
+func TestControllerMaxRetries(t *testing.T) {
+	ctx := context.Background()
+	store := helpers.MakeEntityStore(t)
+
+	controller := NewController(Options{
+		ResyncPeriod: time.Hour,
+		Store:        store,
+		MaxRetries:   2,
+		RetryDelay:   10 * time.Millisecond,
+	})
+	controller.AddEntityHandler(&failingEntityHandler{
+		testEntityHandler: testEntityHandler{t: t, store: store, addCounter: make(chan string, 100), deleteCounter: make(chan string, 100)},
+		failures:          100,
+	})
+
+	controller.Start()
+	defer controller.Shutdown()
+
+	_, err := store.Add(ctx, &testEntity{entitystore.BaseEntity{
+		OrganizationID: testOrgID,
+		Name:           "test-max-retries",
+		Status:         entitystore.StatusCREATING,
+	}})
+	assert.NoError(t, err)
+
+	// the entity is set to ERROR once it failed too many times
+	e := &testEntity{}
+	deadline := time.Now().Add(testSleepDuration)
+	for time.Now().Before(deadline) {
+		_, err := store.Find(ctx, testOrgID, "test-max-retries", entitystore.Options{}, e)
+		assert.NoError(t, err)
+		if e.GetStatus() == entitystore.StatusERROR {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, entitystore.StatusERROR, e.GetStatus())
+	assert.Equal(t, entitystore.Reason{"failure 3"}, e.GetReason())
+	queue := controller.Status().Queue
+	assert.Equal(t, uint64(2), queue.Retries)
+	assert.Equal(t, uint64(1), queue.Failures)
+}
@@ -0,0 +1,338 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/vmware/dispatch/pkg/entity-store"
+)
+
+const defaultRetryPeriod = 5 * time.Second
+
+// Lock is held by one replica at a time, the replica holding it is the leader
+type Lock interface {
+	// TryAcquire acquires the lock, or renews it if it is held already. It returns whether the lock is held.
+	TryAcquire(ctx context.Context) (bool, error)
+	// Release releases the lock if it is held
+	Release(ctx context.Context) error
+}
+
+// LeaderElection is the ownership of all the entities by the replica holding a lock
+type LeaderElection struct {
+	ownership   string
+	lock        Lock
+	identity    string
+	retryPeriod time.Duration
+
+	mu     sync.RWMutex
+	leader bool
+	err    error
+}
+
+// NewLeaderElection creates a leader election, the lock is acquired or renewed every retry period
+func NewLeaderElection(ownership string, lock Lock, identity string, retryPeriod time.Duration) *LeaderElection {
+	if retryPeriod <= 0 {
+		retryPeriod = defaultRetryPeriod
+	}
+	return &LeaderElection{ownership: ownership, lock: lock, identity: identity, retryPeriod: retryPeriod}
+}
+
+// Run campaigns until the context is done, the lock is released then
+func (l *LeaderElection) Run(ctx context.Context, changed chan<- struct{}) {
+	ticker := time.NewTicker(l.retryPeriod)
+	defer ticker.Stop()
+	for {
+		held, err := l.lock.TryAcquire(ctx)
+		if err != nil {
+			log.Errorf("error campaigning for the leadership of the controller: %+v", err)
+		}
+		l.set(held, err, changed)
+
+		select {
+		case <-ctx.Done():
+			l.set(false, nil, changed)
+			if err := l.lock.Release(context.Background()); err != nil {
+				log.Errorf("error releasing the leadership of the controller: %+v", err)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *LeaderElection) set(leader bool, err error, changed chan<- struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.err = err
+	if leader == l.leader {
+		return
+	}
+	l.leader = leader
+	if leader {
+		log.Infof("%s is the leader of the controller", l.identity)
+	} else {
+		log.Infof("%s is no longer the leader of the controller", l.identity)
+	}
+	select {
+	case changed <- struct{}{}:
+	default:
+	}
+}
+
+// Owns returns whether the replica is the leader, the leader owns all the entities
+func (l *LeaderElection) Owns(e entitystore.Entity) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.leader
+}
+
+// Status reports whether the replica is the leader
+func (l *LeaderElection) Status() OwnershipStatus {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	status := OwnershipStatus{Ownership: l.ownership, Identity: l.identity, Leader: l.leader}
+	if l.err != nil {
+		status.Error = l.err.Error()
+	}
+	return status
+}
+
+// PostgresLock is a session advisory lock of a postgres database. The lock is held by a connection, it is released
+// by the database if the connection is lost.
+type PostgresLock struct {
+	db  *sql.DB
+	key int64
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// lockKey returns the key of the lock of the controller of a service
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("dispatch/controller/" + name))
+	return int64(h.Sum64())
+}
+
+// NewPostgresLock creates the lock of the controller of a service, in the database of its entity store
+func NewPostgresLock(config entitystore.BackendConfig, name string) (*PostgresLock, error) {
+	db, err := entitystore.ConnectPostgres(config)
+	if err != nil {
+		return nil, err
+	}
+	return &PostgresLock{db: db.DB, key: lockKey(name)}, nil
+}
+
+// TryAcquire takes the lock, or checks the connection holding it is alive
+func (l *PostgresLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if _, err := l.conn.ExecContext(ctx, `SELECT 1`); err != nil {
+			l.conn.Close()
+			l.conn = nil
+			return false, errors.Wrap(err, "error checking the connection holding the leader lock")
+		}
+		return true, nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "error connecting to take the leader lock")
+	}
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, l.key).Scan(&locked); err != nil {
+		conn.Close()
+		return false, errors.Wrap(err, "error taking the leader lock")
+	}
+	if !locked {
+		conn.Close()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+// Release unlocks the lock and closes the connection which held it
+func (l *PostgresLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	defer func() { l.conn = nil }()
+	defer l.conn.Close()
+	_, err := l.conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, l.key)
+	return errors.Wrap(err, "error releasing the leader lock")
+}
+
+// leaderAnnotation is the annotation of the config map of a kubernetes lock holding the leader record
+const leaderAnnotation = "dispatchframework.io/leader"
+
+// leaderRecord is the lease of the leader of a kubernetes lock
+type leaderRecord struct {
+	HolderIdentity       string    `json:"holderIdentity"`
+	LeaseDurationSeconds int       `json:"leaseDurationSeconds"`
+	AcquireTime          time.Time `json:"acquireTime"`
+	RenewTime            time.Time `json:"renewTime"`
+}
+
+func (r *leaderRecord) expired(now time.Time) bool {
+	return r.HolderIdentity == "" || r.RenewTime.Add(time.Duration(r.LeaseDurationSeconds)*time.Second).Before(now)
+}
+
+// KubernetesLock is a lease on a kubernetes config map, the lease is held until it expires without being renewed.
+// The config map is updated with optimistic concurrency, a single replica acquires an expired lease.
+type KubernetesLock struct {
+	configMaps    typedcorev1.ConfigMapInterface
+	name          string
+	identity      string
+	leaseDuration time.Duration
+}
+
+// NewKubernetesLock creates the lock of the controller of a service, on a config map of a namespace
+func NewKubernetesLock(k8sConfig, namespace, name, identity string, leaseDuration time.Duration) (*KubernetesLock, error) {
+	var config *rest.Config
+	var err error
+	if k8sConfig == "" {
+		config, err = rest.InClusterConfig()
+	} else {
+		config, err = clientcmd.BuildConfigFromFlags("", k8sConfig)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting kubernetes config")
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating kubernetes client")
+	}
+	return newKubernetesLock(clientset.CoreV1().ConfigMaps(namespace), name, identity, leaseDuration), nil
+}
+
+func newKubernetesLock(configMaps typedcorev1.ConfigMapInterface, name, identity string, leaseDuration time.Duration) *KubernetesLock {
+	return &KubernetesLock{
+		configMaps:    configMaps,
+		name:          "dispatch-" + name + "-leader",
+		identity:      identity,
+		leaseDuration: leaseDuration,
+	}
+}
+
+func (l *KubernetesLock) record(cm *corev1.ConfigMap) (*leaderRecord, error) {
+	record := &leaderRecord{}
+	if value, ok := cm.Annotations[leaderAnnotation]; ok {
+		if err := json.Unmarshal([]byte(value), record); err != nil {
+			return nil, errors.Wrapf(err, "error decoding the leader record of %s", l.name)
+		}
+	}
+	return record, nil
+}
+
+func (l *KubernetesLock) setRecord(cm *corev1.ConfigMap, record *leaderRecord) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "error encoding the leader record")
+	}
+	if cm.Annotations == nil {
+		cm.Annotations = map[string]string{}
+	}
+	cm.Annotations[leaderAnnotation] = string(value)
+	return nil
+}
+
+// TryAcquire acquires the lease if it is expired, or renews it
+func (l *KubernetesLock) TryAcquire(ctx context.Context) (bool, error) {
+	now := time.Now()
+	record := &leaderRecord{
+		HolderIdentity:       l.identity,
+		LeaseDurationSeconds: int(l.leaseDuration / time.Second),
+		AcquireTime:          now,
+		RenewTime:            now,
+	}
+
+	cm, err := l.configMaps.Get(l.name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: l.name}}
+		if err := l.setRecord(cm, record); err != nil {
+			return false, err
+		}
+		_, err = l.configMaps.Create(cm)
+		if k8serrors.IsAlreadyExists(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, errors.Wrapf(err, "error creating the leader lease %s", l.name)
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "error getting the leader lease %s", l.name)
+	}
+
+	current, err := l.record(cm)
+	if err != nil {
+		return false, err
+	}
+	if current.HolderIdentity == l.identity {
+		record.AcquireTime = current.AcquireTime
+	} else if !current.expired(now) {
+		return false, nil
+	}
+	if err := l.setRecord(cm, record); err != nil {
+		return false, err
+	}
+	_, err = l.configMaps.Update(cm)
+	if k8serrors.IsConflict(err) {
+		// another replica acquired or renewed the lease
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "error updating the leader lease %s", l.name)
+	}
+	return true, nil
+}
+
+// Release expires the lease if it is held, another replica acquires it without waiting for it to expire
+func (l *KubernetesLock) Release(ctx context.Context) error {
+	cm, err := l.configMaps.Get(l.name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error getting the leader lease %s", l.name)
+	}
+	record, err := l.record(cm)
+	if err != nil || record.HolderIdentity != l.identity {
+		return err
+	}
+	if err := l.setRecord(cm, &leaderRecord{LeaseDurationSeconds: record.LeaseDurationSeconds}); err != nil {
+		return err
+	}
+	if _, err := l.configMaps.Update(cm); err != nil && !k8serrors.IsConflict(err) {
+		return errors.Wrapf(err, "error releasing the leader lease %s", l.name)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package controller
+
+import (
+	"context"
+	"hash/fnv"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware/dispatch/pkg/entity-store"
+)
+
+// Ownerships configurable with the flags
+const (
+	OwnershipNone       = "none"
+	OwnershipPostgres   = "postgres"
+	OwnershipKubernetes = "kubernetes"
+	OwnershipShard      = "shard"
+)
+
+// Ownership decides which replica of a controller reconciles an entity, so that controllers can run with several
+// replicas reconciling each entity once
+type Ownership interface {
+	// Run maintains the ownership of the replica until the context is done. A value is sent to changed, without
+	// blocking, when the entities owned change.
+	Run(ctx context.Context, changed chan<- struct{})
+	// Owns returns whether the replica reconciles an entity
+	Owns(e entitystore.Entity) bool
+	// Status reports the ownership of the replica
+	Status() OwnershipStatus
+}
+
+// OwnershipStatus is the ownership of a replica, as reported by the health checks
+type OwnershipStatus struct {
+	Ownership string `json:"ownership"`
+	Identity  string `json:"identity,omitempty"`
+	// Leader is whether the replica is elected, with leader election
+	Leader bool `json:"leader"`
+	// Shard and Shards are the shard of the replica and the number of shards, with sharding
+	Shard  *int `json:"shard,omitempty"`
+	Shards int  `json:"shards,omitempty"`
+	// Error is the last error maintaining the ownership
+	Error string `json:"error,omitempty"`
+}
+
+// OwnershipFlags configure how the replicas of the controllers of the services share the entities
+type OwnershipFlags struct {
+	Ownership     string `long:"controller-ownership" description:"How the replicas of the controller share the entities: none (a single replica), postgres (a leader elected with an advisory lock), kubernetes (a leader elected with a lease) or shard (the entities are sharded by hash)" default:"none"`
+	Identity      string `long:"controller-identity" description:"Identity of the replica in leader elections, the hostname by default"`
+	LeaseDuration int    `long:"controller-lease-duration" description:"How long the leader lease lasts without being renewed, in seconds, with the kubernetes ownership" default:"15"`
+	K8sConfig     string `long:"controller-kubeconfig" description:"Path to the kubeconfig of the cluster of the lease, in-cluster config by default, with the kubernetes ownership"`
+	K8sNamespace  string `long:"controller-lease-namespace" description:"Namespace of the lease, with the kubernetes ownership" default:"default"`
+	Shard         int    `long:"controller-shard" description:"Shard of the replica, -1 takes the ordinal of the hostname of the replica of a stateful set, with the shard ownership" default:"-1"`
+	Shards        int    `long:"controller-shards" description:"Number of shards, with the shard ownership" default:"1"`
+}
+
+// NewOwnership creates the ownership configured by the flags, for the controller of a service. The postgres ownership
+// takes a lock in the postgres database of the entity store of the service. The ownership is nil when the controller
+// runs a single replica.
+func NewOwnership(flags *OwnershipFlags, name string, db entitystore.BackendConfig) (Ownership, error) {
+	identity := flags.Identity
+	if identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, errors.Wrap(err, "error getting the identity of the replica")
+		}
+		identity = hostname
+	}
+	leaseDuration := time.Duration(flags.LeaseDuration) * time.Second
+
+	switch flags.Ownership {
+	case OwnershipNone, "":
+		return nil, nil
+	case OwnershipPostgres:
+		if db.Backend != "postgres" {
+			return nil, errors.Errorf("the postgres ownership requires the postgres entity store, not %s", db.Backend)
+		}
+		lock, err := NewPostgresLock(db, name)
+		if err != nil {
+			return nil, err
+		}
+		return NewLeaderElection(OwnershipPostgres, lock, identity, leaseDuration/3), nil
+	case OwnershipKubernetes:
+		lock, err := NewKubernetesLock(flags.K8sConfig, flags.K8sNamespace, name, identity, leaseDuration)
+		if err != nil {
+			return nil, err
+		}
+		return NewLeaderElection(OwnershipKubernetes, lock, identity, leaseDuration/3), nil
+	case OwnershipShard:
+		shard := flags.Shard
+		if shard < 0 {
+			var err error
+			if shard, err = ordinal(identity); err != nil {
+				return nil, err
+			}
+		}
+		sharding, err := NewHashSharding(identity, shard, flags.Shards)
+		if err != nil {
+			return nil, err
+		}
+		return sharding, nil
+	}
+	return nil, errors.Errorf("controller ownership %s is not supported, pick one of [none,postgres,kubernetes,shard]", flags.Ownership)
+}
+
+var ordinalRegexp = regexp.MustCompile(`-(\d+)$`)
+
+// ordinal returns the ordinal of a replica of a stateful set, from its hostname
+func ordinal(hostname string) (int, error) {
+	m := ordinalRegexp.FindStringSubmatch(hostname)
+	if m == nil {
+		return 0, errors.Errorf("cannot take the shard from hostname %s: not a replica of a stateful set", hostname)
+	}
+	return strconv.Atoi(m[1])
+}
+
+// HashSharding shards the entities by the hash of their keys. The shards are static, each replica owns one.
+type HashSharding struct {
+	identity string
+	shard    int
+	shards   int
+}
+
+// NewHashSharding creates the ownership of a shard of the entities
+func NewHashSharding(identity string, shard, shards int) (*HashSharding, error) {
+	if shards < 1 || shard < 0 || shard >= shards {
+		return nil, errors.Errorf("invalid shard %d of %d shards", shard, shards)
+	}
+	return &HashSharding{identity: identity, shard: shard, shards: shards}, nil
+}
+
+// Run does nothing, the shards do not change
+func (s *HashSharding) Run(ctx context.Context, changed chan<- struct{}) {
+	<-ctx.Done()
+}
+
+// Owns returns whether an entity hashes to the shard of the replica
+func (s *HashSharding) Owns(e entitystore.Entity) bool {
+	h := fnv.New32a()
+	h.Write([]byte(entityKey(e)))
+	return int(h.Sum32()%uint32(s.shards)) == s.shard
+}
+
+// Status reports the shard of the replica
+func (s *HashSharding) Status() OwnershipStatus {
+	shard := s.shard
+	return OwnershipStatus{Ownership: OwnershipShard, Identity: s.identity, Shard: &shard, Shards: s.shards}
+}
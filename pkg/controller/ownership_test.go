@@ -0,0 +1,247 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/vmware/dispatch/pkg/entity-store"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+	"github.com/vmware/dispatch/pkg/testing/dev"
+)
+
+func TestHashSharding(t *testing.T) {
+	var shards []*HashSharding
+	for i := 0; i < 3; i++ {
+		s, err := NewHashSharding(fmt.Sprintf("replica-%d", i), i, 3)
+		require.NoError(t, err)
+		shards = append(shards, s)
+	}
+
+	owned := make([]int, 3)
+	for i := 0; i < 100; i++ {
+		e := &testEntity{entitystore.BaseEntity{OrganizationID: testOrgID, Name: fmt.Sprintf("test-%d", i)}}
+		owners := 0
+		for j, s := range shards {
+			if s.Owns(e) {
+				owners++
+				owned[j]++
+			}
+		}
+		assert.Equal(t, 1, owners)
+	}
+	for _, n := range owned {
+		assert.NotZero(t, n)
+	}
+	assert.Equal(t, 1, *shards[1].Status().Shard)
+
+	_, err := NewHashSharding("replica", 3, 3)
+	assert.Error(t, err)
+}
+
+func TestNewOwnership(t *testing.T) {
+	ownership, err := NewOwnership(&OwnershipFlags{Ownership: OwnershipNone}, "test", entitystore.BackendConfig{})
+	assert.NoError(t, err)
+	assert.Nil(t, ownership)
+
+	ownership, err = NewOwnership(&OwnershipFlags{Ownership: OwnershipShard, Identity: "test-manager-2", Shard: -1, Shards: 3}, "test", entitystore.BackendConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, *ownership.Status().Shard)
+
+	_, err = NewOwnership(&OwnershipFlags{Ownership: OwnershipShard, Identity: "test-manager", Shard: -1, Shards: 3}, "test", entitystore.BackendConfig{})
+	assert.Error(t, err)
+	_, err = NewOwnership(&OwnershipFlags{Ownership: OwnershipPostgres}, "test", entitystore.BackendConfig{Backend: "boltdb"})
+	assert.Error(t, err)
+	_, err = NewOwnership(&OwnershipFlags{Ownership: "raft"}, "test", entitystore.BackendConfig{})
+	assert.Error(t, err)
+}
+
+// testLock is held while held is true
+type testLock struct {
+	mu       sync.Mutex
+	held     bool
+	released bool
+}
+
+func (l *testLock) set(held bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.held = held
+}
+
+func (l *testLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.held, nil
+}
+
+func (l *testLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.released = true
+	return nil
+}
+
+func TestLeaderElection(t *testing.T) {
+	lock := &testLock{}
+	election := NewLeaderElection("test", lock, "replica", 10*time.Millisecond)
+	changed := make(chan struct{}, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		election.Run(ctx, changed)
+		close(done)
+	}()
+
+	e := &testEntity{entitystore.BaseEntity{OrganizationID: testOrgID, Name: "test-leader"}}
+	assert.False(t, election.Owns(e))
+
+	lock.set(true)
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the election")
+	}
+	assert.True(t, election.Owns(e))
+	assert.True(t, election.Status().Leader)
+
+	lock.set(false)
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the leadership to be lost")
+	}
+	assert.False(t, election.Owns(e))
+
+	cancel()
+	<-done
+	assert.True(t, lock.released)
+}
+
+func TestKubernetesLock(t *testing.T) {
+	configMaps := k8sfake.NewSimpleClientset().CoreV1().ConfigMaps("dispatch")
+	ctx := context.Background()
+	a := newKubernetesLock(configMaps, "test", "replica-a", time.Minute)
+	b := newKubernetesLock(configMaps, "test", "replica-b", time.Minute)
+
+	held, err := a.TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, held)
+	held, err = b.TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.False(t, held)
+	// the lease is renewed by its holder
+	held, err = a.TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, held)
+
+	// a lease released is acquired by another replica
+	assert.NoError(t, a.Release(ctx))
+	held, err = b.TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, held)
+	held, err = a.TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.False(t, held)
+
+	// a lease not renewed expires
+	cm, err := configMaps.Get(b.name, metav1.GetOptions{})
+	require.NoError(t, err)
+	expired, _ := json.Marshal(&leaderRecord{HolderIdentity: "replica-b", LeaseDurationSeconds: 60, RenewTime: time.Now().Add(-time.Hour)})
+	cm.Annotations[leaderAnnotation] = string(expired)
+	_, err = configMaps.Update(cm)
+	require.NoError(t, err)
+	held, err = a.TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, held)
+}
+
+func TestPostgresLock(t *testing.T) {
+
+	dev.EnsureLocal(t)
+
+	config := entitystore.BackendConfig{Backend: "postgres", Address: "localhost:5432", Username: "testuser", Password: "testpasswd", Bucket: "testdb"}
+	a, err := NewPostgresLock(config, "test")
+	require.NoError(t, err, "Cannot connect to postgres DB")
+	b, err := NewPostgresLock(config, "test")
+	require.NoError(t, err, "Cannot connect to postgres DB")
+	ctx := context.Background()
+
+	held, err := a.TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, held)
+	held, err = b.TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.False(t, held)
+
+	assert.NoError(t, a.Release(ctx))
+	held, err = b.TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, held)
+	assert.NoError(t, b.Release(ctx))
+}
+
+// testOwnership owns the entities of the names given
+type testOwnership map[string]bool
+
+func (o testOwnership) Run(ctx context.Context, changed chan<- struct{}) {
+	<-ctx.Done()
+}
+
+func (o testOwnership) Owns(e entitystore.Entity) bool {
+	return o[e.GetName()]
+}
+
+func (o testOwnership) Status() OwnershipStatus {
+	return OwnershipStatus{Ownership: "test"}
+}
+
+func TestControllerOwnership(t *testing.T) {
+	ctx := context.Background()
+	store := helpers.MakeEntityStore(t)
+
+	addCounter := make(chan string, 100)
+	controller := NewController(Options{
+		ResyncPeriod: time.Hour,
+		Store:        store,
+		Ownership:    testOwnership{"test-owned": true},
+	})
+	controller.AddEntityHandler(&testEntityHandler{t: t, store: store, addCounter: addCounter, deleteCounter: make(chan string, 100)})
+
+	controller.Start()
+	defer controller.Shutdown()
+
+	// the changes of the entities not owned are reconciled by other replicas
+	for _, name := range []string{"test-not-owned", "test-owned"} {
+		_, err := store.Add(ctx, &testEntity{entitystore.BaseEntity{
+			OrganizationID: testOrgID,
+			Name:           name,
+			Status:         entitystore.StatusCREATING,
+		}})
+		assert.NoError(t, err)
+	}
+	select {
+	case name := <-addCounter:
+		assert.Equal(t, "test-owned", name)
+	case <-time.After(testSleepDuration):
+		t.Error("timeout waiting for the owned entity to be processed")
+	}
+	select {
+	case name := <-addCounter:
+		t.Errorf("entity %s not owned was processed", name)
+	case <-time.After(testResyncPeriod):
+	}
+}
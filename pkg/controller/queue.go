@@ -0,0 +1,230 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/vmware/dispatch/pkg/entity-store"
+)
+
+// itemSource is where a queued entity comes from
+type itemSource int
+
+const (
+	// sourcePushed entities are pushed to the watcher
+	sourcePushed itemSource = iota
+	// sourceWatched entities are changes watched in the store
+	sourceWatched
+	// sourceSynced entities are returned by the handlers syncing
+	sourceSynced
+	// sourceRetry entities failed to be processed
+	sourceRetry
+)
+
+// queueItem is an entity to process
+type queueItem struct {
+	ctx    context.Context
+	entity entitystore.Entity
+	source itemSource
+	queued time.Time
+}
+
+// QueueMetrics are the metrics of the work queue of a controller
+type QueueMetrics struct {
+	// Depth is the number of entities waiting to be processed
+	Depth int `json:"depth"`
+	// Processing is the number of entities being processed
+	Processing int `json:"processing"`
+	// Retrying is the number of entities waiting for their backoff to be retried
+	Retrying int `json:"retrying"`
+
+	Added     uint64 `json:"added"`
+	Coalesced uint64 `json:"coalesced"`
+	Processed uint64 `json:"processed"`
+	Retries   uint64 `json:"retries"`
+	// Failures is the number of entities set to ERROR after failing to be processed too many times
+	Failures uint64 `json:"failures"`
+
+	// WaitLatency and ProcessLatency are the average times the entities waited in the queue and were processed for,
+	// in milliseconds
+	WaitLatency    float64 `json:"waitLatencyMs"`
+	ProcessLatency float64 `json:"processLatencyMs"`
+}
+
+// workQueue is a FIFO queue of entities keyed by organization, type and name. An entity queued again while it waits
+// is coalesced with the entity waiting, the latest revision is processed. An entity is processed by a single worker
+// at a time: an entity pushed while it is processed waits for it to be done, while the changes watched and the
+// entities synced are skipped, as handlers update the entities they process.
+type workQueue struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	keys []string
+	// waiting are the items of the keys queued, or of the keys processed to be queued again once done
+	waiting map[string]*queueItem
+	// processing holds the revisions of the entities being processed
+	processing map[string]uint64
+	// failures counts the consecutive failures of the entities
+	failures map[string]int
+	shutdown bool
+
+	metrics      QueueMetrics
+	totalWait    time.Duration
+	totalProcess time.Duration
+}
+
+func newWorkQueue() *workQueue {
+	q := &workQueue{
+		waiting:    map[string]*queueItem{},
+		processing: map[string]uint64{},
+		failures:   map[string]int{},
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// add queues an entity, it returns false if the entity is skipped
+func (q *workQueue) add(item *queueItem) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if item.source == sourceRetry {
+		q.metrics.Retrying--
+	}
+	if q.shutdown {
+		return false
+	}
+	key := entityKey(item.entity)
+	if revision, ok := q.processing[key]; ok {
+		if item.source == sourceWatched || item.source == sourceSynced {
+			return false
+		}
+		if item.source == sourcePushed && item.entity.GetRevision() <= revision {
+			return false
+		}
+	}
+
+	if waiting, ok := q.waiting[key]; ok {
+		q.metrics.Coalesced++
+		if item.entity.GetRevision() < waiting.entity.GetRevision() {
+			return true
+		}
+		item.queued = waiting.queued
+		q.waiting[key] = item
+		return true
+	}
+
+	q.metrics.Added++
+	item.queued = time.Now()
+	q.waiting[key] = item
+	if _, ok := q.processing[key]; !ok {
+		q.keys = append(q.keys, key)
+		q.cond.Signal()
+	}
+	return true
+}
+
+// addAfter queues an entity after a delay, with f returning the entity to queue, or nil if it is not retried
+func (q *workQueue) addAfter(delay time.Duration, f func() *queueItem) {
+	q.mu.Lock()
+	q.metrics.Retrying++
+	q.metrics.Retries++
+	q.mu.Unlock()
+
+	time.AfterFunc(delay, func() {
+		item := f()
+		if item == nil {
+			q.mu.Lock()
+			q.metrics.Retrying--
+			q.mu.Unlock()
+			return
+		}
+		item.source = sourceRetry
+		q.add(item)
+	})
+}
+
+// get waits for an entity to process, it returns false once the queue is shut down
+func (q *workQueue) get() (string, *queueItem, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.keys) == 0 && !q.shutdown {
+		q.cond.Wait()
+	}
+	if q.shutdown {
+		return "", nil, false
+	}
+	key := q.keys[0]
+	q.keys = q.keys[1:]
+	item := q.waiting[key]
+	delete(q.waiting, key)
+	q.processing[key] = item.entity.GetRevision()
+	q.totalWait += time.Since(item.queued)
+	return key, item, true
+}
+
+// done marks an entity as processed, the entity is queued again if it was pushed while processed
+func (q *workQueue) done(key string, started time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.processing, key)
+	q.metrics.Processed++
+	q.totalProcess += time.Since(started)
+	if _, ok := q.waiting[key]; ok && !q.shutdown {
+		q.keys = append(q.keys, key)
+		q.cond.Signal()
+	}
+}
+
+// fail counts a failure to process an entity, and returns the number of consecutive failures
+func (q *workQueue) fail(key string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.failures[key]++
+	return q.failures[key]
+}
+
+// forget resets the failures of an entity, once processed or given up on
+func (q *workQueue) forget(key string, failed bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.failures, key)
+	if failed {
+		q.metrics.Failures++
+	}
+}
+
+// stop shuts the queue down, the workers waiting return
+func (q *workQueue) stop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.shutdown = true
+	q.cond.Broadcast()
+}
+
+func (q *workQueue) snapshot() QueueMetrics {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	metrics := q.metrics
+	metrics.Depth = len(q.waiting)
+	metrics.Processing = len(q.processing)
+	if processed := q.metrics.Processed + uint64(len(q.processing)); processed > 0 {
+		metrics.WaitLatency = float64(q.totalWait) / float64(processed) / float64(time.Millisecond)
+	}
+	if q.metrics.Processed > 0 {
+		metrics.ProcessLatency = float64(q.totalProcess) / float64(q.metrics.Processed) / float64(time.Millisecond)
+	}
+	return metrics
+}
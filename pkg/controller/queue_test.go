@@ -0,0 +1,117 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/entity-store"
+)
+
+func testQueueItem(name string, revision uint64, source itemSource) *queueItem {
+	return &queueItem{
+		ctx:    context.Background(),
+		entity: &testEntity{entitystore.BaseEntity{OrganizationID: testOrgID, Name: name, Revision: revision}},
+		source: source,
+	}
+}
+
+func TestWorkQueueCoalesce(t *testing.T) {
+	q := newWorkQueue()
+
+	assert.True(t, q.add(testQueueItem("test-a", 1, sourcePushed)))
+	assert.True(t, q.add(testQueueItem("test-b", 1, sourceWatched)))
+	// the latest revision of an entity waiting is processed
+	assert.True(t, q.add(testQueueItem("test-a", 3, sourceWatched)))
+	assert.True(t, q.add(testQueueItem("test-a", 2, sourceSynced)))
+
+	metrics := q.snapshot()
+	assert.Equal(t, 2, metrics.Depth)
+	assert.Equal(t, uint64(2), metrics.Added)
+	assert.Equal(t, uint64(2), metrics.Coalesced)
+
+	key, item, ok := q.get()
+	require.True(t, ok)
+	assert.Equal(t, entityKey(item.entity), key)
+	assert.Equal(t, "test-a", item.entity.GetName())
+	assert.Equal(t, uint64(3), item.entity.GetRevision())
+	_, item, ok = q.get()
+	require.True(t, ok)
+	assert.Equal(t, "test-b", item.entity.GetName())
+}
+
+func TestWorkQueueProcessing(t *testing.T) {
+	q := newWorkQueue()
+
+	q.add(testQueueItem("test-a", 1, sourcePushed))
+	key, _, ok := q.get()
+	require.True(t, ok)
+	started := time.Now()
+
+	// the changes watched and the entities synced are skipped while processed, the revision processed is stale
+	assert.False(t, q.add(testQueueItem("test-a", 2, sourceWatched)))
+	assert.False(t, q.add(testQueueItem("test-a", 2, sourceSynced)))
+	assert.False(t, q.add(testQueueItem("test-a", 1, sourcePushed)))
+	// a later revision pushed waits for the entity to be processed
+	assert.True(t, q.add(testQueueItem("test-a", 2, sourcePushed)))
+	assert.Equal(t, 1, q.snapshot().Processing)
+
+	got := make(chan *queueItem)
+	go func() {
+		_, item, _ := q.get()
+		got <- item
+	}()
+	select {
+	case <-got:
+		t.Fatal("entity processed by two workers")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	q.done(key, started)
+	select {
+	case item := <-got:
+		assert.Equal(t, uint64(2), item.entity.GetRevision())
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the entity pushed")
+	}
+	assert.Equal(t, uint64(1), q.snapshot().Processed)
+}
+
+func TestWorkQueueRetry(t *testing.T) {
+	q := newWorkQueue()
+
+	q.add(testQueueItem("test-a", 1, sourceWatched))
+	key, _, _ := q.get()
+	assert.Equal(t, 1, q.fail(key))
+	assert.Equal(t, 2, q.fail(key))
+	q.addAfter(10*time.Millisecond, func() *queueItem {
+		return testQueueItem("test-a", 1, sourceWatched)
+	})
+	q.done(key, time.Now())
+	assert.Equal(t, 1, q.snapshot().Retrying)
+
+	_, item, ok := q.get()
+	require.True(t, ok)
+	assert.Equal(t, sourceRetry, item.source)
+	metrics := q.snapshot()
+	assert.Equal(t, 0, metrics.Retrying)
+	assert.Equal(t, uint64(1), metrics.Retries)
+
+	// an entity given up on is counted as a failure
+	q.forget(key, true)
+	assert.Equal(t, 1, q.fail(key))
+	assert.Equal(t, uint64(1), q.snapshot().Failures)
+
+	q.stop()
+	_, _, ok = q.get()
+	assert.False(t, ok)
+	assert.False(t, q.add(testQueueItem("test-b", 1, sourcePushed)))
+}
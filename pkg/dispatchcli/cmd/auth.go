@@ -6,16 +6,19 @@
 package cmd
 
 import (
+	"crypto"
 	"fmt"
 	"io/ioutil"
 	"time"
 
-	"crypto/rsa"
 	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/go-openapi/runtime"
 	apiclient "github.com/go-openapi/runtime/client"
 	"github.com/go-openapi/strfmt"
 	"github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+
+	"github.com/vmware/dispatch/pkg/identity-manager/keys"
 )
 
 const (
@@ -63,6 +66,10 @@ func GetAuthInfoWriter() runtime.ClientAuthInfoWriter {
 		return apiclient.BearerToken(token)
 	}
 
+	if dispatchConfig.IDToken != "" {
+		return apiclient.BearerToken(dispatchConfig.IDToken)
+	}
+
 	// Oauth2Proxy always expects a cookie header even if the server is setup with SkipAuth. Hence, set a dummy default.
 	cookie := "unset"
 	if dispatchConfig.Cookie != "" {
@@ -71,8 +78,9 @@ func GetAuthInfoWriter() runtime.ClientAuthInfoWriter {
 	return apiclient.APIKeyAuth("cookie", "header", cookie)
 }
 
-// Generate and sign JWT,
-func generateAndSignJWToken(serviceAccount string, rsaPvtKey *rsa.PrivateKey, pemKeyPath *string) (string, error) {
+// Generate and sign JWT, with either a RSA, ECDSA or Ed25519 private key. The kid header identifies the public key
+// of the service account verifying the token.
+func generateAndSignJWToken(serviceAccount string, pvtKey crypto.Signer, pemKeyPath *string) (string, error) {
 
 	if pemKeyPath != nil {
 		signBytes, err := ioutil.ReadFile(*pemKeyPath)
@@ -80,25 +88,38 @@ func generateAndSignJWToken(serviceAccount string, rsaPvtKey *rsa.PrivateKey, pe
 			fmt.Printf("error reading key file: %s\n", err.Error())
 			return "", err
 		}
-		rsaPvtKey, err = jwt.ParseRSAPrivateKeyFromPEM(signBytes)
+		pvtKey, err = keys.ParsePrivateKey(signBytes)
 		if err != nil {
-			fmt.Printf("error parsing RSA private key from pem: %s\n", err.Error())
+			fmt.Printf("error parsing private key from pem: %s\n", err.Error())
 			return "", err
 		}
 	}
 
-	if rsaPvtKey == nil {
-		return "", errors.New("either rsa pvt key or path to pem encoded file should be provided")
+	if pvtKey == nil {
+		return "", errors.New("either pvt key or path to pem encoded file should be provided")
+	}
+
+	method, err := keys.SigningMethod(pvtKey)
+	if err != nil {
+		return "", err
+	}
+	keyID, err := keys.ID(pvtKey.Public())
+	if err != nil {
+		return "", err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+	now := time.Now()
+	token := jwt.NewWithClaims(method, jwt.MapClaims{
+		"jti": uuid.NewV4().String(),
 		"iss": serviceAccount,
+		"org": dispatchConfig.Organization,
 		// Handle clock skew on the server side
-		"iat": time.Now().Add(-time.Minute).Unix(),
-		"exp": time.Now().Add(jwtExpDuration).Unix(),
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(jwtExpDuration).Unix(),
 	})
+	token.Header["kid"] = keyID
 
-	tokenString, err := token.SignedString(rsaPvtKey)
+	tokenString, err := token.SignedString(pvtKey)
 	if err != nil {
 		fmt.Printf("error signing token: %s\n", err.Error())
 		return "", err
@@ -0,0 +1,39 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/identity-manager/keys"
+)
+
+func TestGenerateAndSignJWToken(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	signed, err := generateAndSignJWToken("ci", key, nil)
+	require.NoError(t, err)
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(signed, claims, func(token *jwt.Token) (interface{}, error) {
+		return &key.PublicKey, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ES256", token.Header["alg"])
+	keyID, err := keys.ID(&key.PublicKey)
+	require.NoError(t, err)
+	assert.Equal(t, keyID, token.Header["kid"])
+	assert.Equal(t, "ci", claims["iss"])
+	assert.NotEmpty(t, claims["jti"])
+}
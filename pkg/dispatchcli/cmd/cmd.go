@@ -38,6 +38,9 @@ type hostConfig struct {
 	Token          string `json:"-"`
 	ServiceAccount string `json:"serviceaccount,omitempty"`
 	JWTPrivateKey  string `json:"jwtprivatekey,omitempty"`
+	OIDCIssuer     string `json:"oidc-issuer,omitempty"`
+	OIDCClientID   string `json:"oidc-client-id,omitempty"`
+	IDToken        string `json:"idtoken,omitempty"`
 }
 
 // Current Config Context
@@ -59,6 +62,7 @@ var (
 	dispatchConfigPath = ""
 
 	cmdFlagApplication = i18n.T(``)
+	cmdFlagSelector    = i18n.T(``)
 
 	cmds *cobra.Command
 
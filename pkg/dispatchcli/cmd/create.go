@@ -70,6 +70,7 @@ func importFile(out io.Writer, errOut io.Writer, cmd *cobra.Command, args []stri
 	type output struct {
 		APIs             []*v1.API             `json:"api"`
 		BaseImages       []*v1.BaseImage       `json:"baseImages"`
+		Certificates     []*v1.Certificate     `json:"certificates"`
 		Images           []*v1.Image           `json:"images"`
 		DriverTypes      []*v1.EventDriverType `json:"driverTypes"`
 		Drivers          []*v1.EventDriver     `json:"drivers"`
@@ -77,8 +78,12 @@ func importFile(out io.Writer, errOut io.Writer, cmd *cobra.Command, args []stri
 		Functions        []*v1.Function        `json:"functions"`
 		Secrets          []*v1.Secret          `json:"secrets"`
 		Policies         []*v1.Policy          `json:"policies"`
+		ServiceBindings  []*v1.ServiceBinding  `json:"serviceBindings"`
+		ServiceBrokers   []*v1.ServiceBroker   `json:"serviceBrokers"`
 		ServiceInstances []*v1.ServiceInstance `json:"serviceInstances"`
 		ServiceAccounts  []*v1.ServiceAccount  `json:"serviceaccounts"`
+		Roles            []*v1.Role            `json:"roles"`
+		RoleBindings     []*v1.RoleBinding     `json:"rolebindings"`
 	}
 
 	o := output{}
@@ -102,6 +107,18 @@ func importFile(out io.Writer, errOut io.Writer, cmd *cobra.Command, args []stri
 			}
 			o.APIs = append(o.APIs, m)
 			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, *m.Name)
+		case utils.CertificateKind:
+			m := &v1.Certificate{}
+			err = yaml.Unmarshal(doc, m)
+			if err != nil {
+				return errors.Wrapf(err, "Error decoding certificate document %s", string(doc))
+			}
+			err = actionMap[docKind](m)
+			if err != nil {
+				return err
+			}
+			o.Certificates = append(o.Certificates, m)
+			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, *m.Name)
 		case utils.BaseImageKind:
 			m := &v1.BaseImage{}
 			err = yaml.Unmarshal(doc, m)
@@ -220,6 +237,18 @@ func importFile(out io.Writer, errOut io.Writer, cmd *cobra.Command, args []stri
 			}
 			o.Policies = append(o.Policies, m)
 			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, *m.Name)
+		case utils.ServiceBrokerKind:
+			m := &v1.ServiceBroker{}
+			err := yaml.Unmarshal(doc, m)
+			if err != nil {
+				return errors.Wrapf(err, "Error decoding service broker document %s", string(doc))
+			}
+			err = actionMap[docKind](m)
+			if err != nil {
+				return err
+			}
+			o.ServiceBrokers = append(o.ServiceBrokers, m)
+			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, *m.Name)
 		case utils.ServiceInstanceKind:
 			m := &v1.ServiceInstance{}
 			err := yaml.Unmarshal(doc, m)
@@ -232,6 +261,18 @@ func importFile(out io.Writer, errOut io.Writer, cmd *cobra.Command, args []stri
 			}
 			o.ServiceInstances = append(o.ServiceInstances, m)
 			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, *m.Name)
+		case utils.ServiceBindingKind:
+			m := &v1.ServiceBinding{}
+			err := yaml.Unmarshal(doc, m)
+			if err != nil {
+				return errors.Wrapf(err, "Error decoding service binding document %s", string(doc))
+			}
+			err = actionMap[docKind](m)
+			if err != nil {
+				return err
+			}
+			o.ServiceBindings = append(o.ServiceBindings, m)
+			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, m.Name)
 		case utils.ServiceAccountKind:
 			m := &v1.ServiceAccount{}
 			err = yaml.Unmarshal(doc, m)
@@ -244,6 +285,30 @@ func importFile(out io.Writer, errOut io.Writer, cmd *cobra.Command, args []stri
 			}
 			o.ServiceAccounts = append(o.ServiceAccounts, m)
 			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, *m.Name)
+		case utils.RoleKind:
+			m := &v1.Role{}
+			err = yaml.Unmarshal(doc, m)
+			if err != nil {
+				return errors.Wrapf(err, "Error decoding role document %s", string(doc))
+			}
+			err = actionMap[docKind](m)
+			if err != nil {
+				return err
+			}
+			o.Roles = append(o.Roles, m)
+			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, *m.Name)
+		case utils.RoleBindingKind:
+			m := &v1.RoleBinding{}
+			err = yaml.Unmarshal(doc, m)
+			if err != nil {
+				return errors.Wrapf(err, "Error decoding role binding document %s", string(doc))
+			}
+			err = actionMap[docKind](m)
+			if err != nil {
+				return err
+			}
+			o.RoleBindings = append(o.RoleBindings, m)
+			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, *m.Name)
 		default:
 			continue
 		}
@@ -282,14 +347,19 @@ func NewCmdCreate(out io.Writer, errOut io.Writer) *cobra.Command {
 				utils.BaseImageKind:       CallCreateBaseImage(imgClient),
 				utils.FunctionKind:        CallCreateFunction(fnClient),
 				utils.SecretKind:          CallCreateSecret,
+				utils.ServiceBrokerKind:   CallCreateServiceBroker,
 				utils.ServiceInstanceKind: CallCreateServiceInstance,
+				utils.ServiceBindingKind:  CallCreateServiceBinding,
 				utils.PolicyKind:          CallCreatePolicy,
 				utils.ApplicationKind:     CallCreateApplication,
 				utils.ServiceAccountKind:  CallCreateServiceAccount,
+				utils.RoleKind:            CallCreateRole,
+				utils.RoleBindingKind:     CallCreateRoleBinding,
 				utils.DriverTypeKind:      CallCreateEventDriverType(eventClient),
 				utils.DriverKind:          CallCreateEventDriver(eventClient),
 				utils.SubscriptionKind:    CallCreateSubscription(eventClient),
 				utils.APIKind:             CallCreateAPI(apiClient),
+				utils.CertificateKind:     CallCreateCertificate(apiClient),
 			}
 
 			err := importFile(out, errOut, cmd, args, createMap, "Created")
@@ -306,10 +376,13 @@ func NewCmdCreate(out io.Writer, errOut io.Writer) *cobra.Command {
 	cmd.AddCommand(NewCmdCreateFunction(out, errOut))
 	cmd.AddCommand(NewCmdCreateSecret(out, errOut))
 	cmd.AddCommand(NewCmdCreateAPI(out, errOut))
+	cmd.AddCommand(NewCmdCreateCertificate(out, errOut))
 	cmd.AddCommand(NewCmdCreateSubscription(out, errOut))
 	cmd.AddCommand(NewCmdCreateEventDriver(out, errOut))
 	cmd.AddCommand(NewCmdCreateEventDriverType(out, errOut))
 	cmd.AddCommand(NewCmdCreateApplication(out, errOut))
 	cmd.AddCommand(NewCmdCreateServiceInstance(out, errOut))
+	cmd.AddCommand(NewCmdCreateServiceBroker(out, errOut))
+	cmd.AddCommand(NewCmdCreateServiceBinding(out, errOut))
 	return cmd
 }
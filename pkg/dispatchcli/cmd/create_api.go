@@ -24,7 +24,10 @@ var (
 		`Create dispatch function api.
 
 Note:
-  Import your own tls certificates if you want to use your own domain name with HTTPS secure connection
+  Create a certificate (see "dispatch create certificate") and reference it with --tls
+  if you want to use your own domain name with HTTPS secure connection.
+  --tls used to take a secret name: such a secret is converted into a certificate of the
+  same name, serving the domains of the api
 		`)
 	// TODO: add examples
 	createAPIExample = i18n.T(``)
@@ -36,12 +39,13 @@ Note:
 	paths     = []string{"/"}
 	methods   = []string{"GET"}
 	auth      = "public"
+	tlsCert   = ""
 )
 
 // NewCmdCreateAPI creates command responsible for dispatch function api creation.
 func NewCmdCreateAPI(out io.Writer, errOut io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "api API_NAME FUNCTION_NAME [--auth AUTH_METHOD] [--domain DOMAINNAME...] [--method METHOD...] [--path PATH...] [--disable] [--cors] [--https-only]",
+		Use:     "api API_NAME FUNCTION_NAME [--auth AUTH_METHOD] [--domain DOMAINNAME...] [--method METHOD...] [--path PATH...] [--disable] [--cors] [--https-only] [--tls CERT_NAME]",
 		Short:   i18n.T("Create api"),
 		Long:    createAPILong,
 		Example: createAPIExample,
@@ -60,6 +64,7 @@ func NewCmdCreateAPI(out io.Writer, errOut io.Writer) *cobra.Command {
 	cmd.Flags().BoolVar(&httpsOnly, "https-only", false, "only support https connections, default: false")
 	cmd.Flags().BoolVar(&disable, "disable", false, "disable the api, default: false")
 	cmd.Flags().BoolVar(&cors, "cors", false, "enable CORS, default: false")
+	cmd.Flags().StringVar(&tlsCert, "tls", "", "name of the certificate used for https connections to the api domains (a secret name is converted into a certificate)")
 	cmd.Flags().StringVar(&auth, "auth", "public", "specify end-user authentication method, (e.g. public, basic, oauth2), default: public")
 	return cmd
 }
@@ -98,6 +103,7 @@ func createAPI(out, errOut io.Writer, cmd *cobra.Command, args []string, c clien
 		Authentication: auth,
 		Enabled:        !disable,
 		Cors:           cors,
+		TLS:            tlsCert,
 		Tags:           []*v1.Tag{},
 	}
 	if cmdFlagApplication != "" {
@@ -0,0 +1,105 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2017 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/swag"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	createCertificateLong = i18n.T(
+		`Create a tls certificate for custom api domains.
+
+The certificate (chain) and private key are read from a secret, with the PEM encoded values
+stored under the "cert" and "key" keys. With --acme the certificate is issued (and renewed)
+through the ACME directory configured in the api-manager, and written to the secret.
+Use the certificate with "dispatch create api --tls CERT_NAME".
+		`)
+	createCertificateExample = i18n.T(`
+# create a secret holding the certificate and key, and a certificate served for www.example.com
+dispatch create secret example-tls example-tls.json
+dispatch create certificate example example-tls --domain www.example.com
+
+# issue a certificate through ACME
+dispatch create certificate example example-tls --domain www.example.com --acme`)
+
+	certHosts = []string{}
+	certACME  = false
+)
+
+// NewCmdCreateCertificate creates command responsible for tls certificate creation.
+func NewCmdCreateCertificate(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "certificate CERT_NAME SECRET_NAME --domain DOMAINNAME... [--acme]",
+		Short:   i18n.T("Create certificate"),
+		Long:    createCertificateLong,
+		Example: createCertificateExample,
+		Args:    cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			c := apiManagerClient()
+			err := createCertificate(out, errOut, cmd, args, c)
+			CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&cmdFlagApplication, "application", "a", "", "associate with an application")
+	cmd.Flags().StringArrayVarP(&certHosts, "domain", "d", []string{}, "domain names (SNI) served with the certificate (multi-values)")
+	cmd.Flags().BoolVar(&certACME, "acme", false, "issue and renew the certificate through ACME, default: false")
+	return cmd
+}
+
+// CallCreateCertificate makes the API call to create a certificate
+func CallCreateCertificate(c client.APIsClient) ModelAction {
+	return func(f interface{}) error {
+		cert := f.(*v1.Certificate)
+
+		created, err := c.CreateCertificate(context.TODO(), "", cert)
+		if err != nil {
+			return formatAPIError(err, cert)
+		}
+		*cert = *created
+		return nil
+	}
+}
+
+func createCertificate(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.APIsClient) error {
+
+	cert := &v1.Certificate{
+		Name:   swag.String(args[0]),
+		Secret: args[1],
+		Hosts:  certHosts,
+		Acme:   certACME,
+		Tags:   []*v1.Tag{},
+	}
+	if cmdFlagApplication != "" {
+		cert.Tags = append(cert.Tags, &v1.Tag{
+			Key:   "Application",
+			Value: cmdFlagApplication,
+		})
+	}
+
+	err := CallCreateCertificate(c)(cert)
+	if err != nil {
+		return err
+	}
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(cert)
+	}
+	fmt.Fprintf(out, "Created certificate: %s\n", *cert.Name)
+	return nil
+}
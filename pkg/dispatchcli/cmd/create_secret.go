@@ -10,11 +10,15 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"strings"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"golang.org/x/net/context"
 
 	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
 	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
 	secret "github.com/vmware/dispatch/pkg/secret-store/gen/client/secret"
 )
@@ -28,8 +32,15 @@ var (
 			"secret-key": "secret-value"
 		}`)
 
-	// TODO: add examples
-	createSecretExample = i18n.T(`create a secret`)
+	createSecretExample = i18n.T(`# create a secret
+dispatch create secret psql-creds secret.json
+
+# only allow the build function and the functions triggered by the on-push subscription to read it
+dispatch create secret psql-creds secret.json --allow function/build,subscription/on-push`)
+
+	createSecretRotationFunction = ""
+	createSecretRotationInterval time.Duration
+	createSecretAllow            []string
 )
 
 // CallCreateSecret makes the API call to create a secret
@@ -65,6 +76,9 @@ func NewCmdCreateSecret(out io.Writer, errOut io.Writer) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&cmdFlagApplication, "application", "a", "", "associate with an application")
+	cmd.Flags().StringVar(&createSecretRotationFunction, "rotation-function", "", "function producing the new secret values on rotation")
+	cmd.Flags().DurationVar(&createSecretRotationInterval, "rotation-interval", 0, "rotate the secret periodically, e.g. 24h (requires --rotation-function)")
+	cmd.Flags().StringSliceVar(&createSecretAllow, "allow", []string{}, "principals allowed to read the secret values, as KIND/NAME (function, subscription, driver, serviceaccount or user), names may contain wildcards")
 	return cmd
 }
 
@@ -87,6 +101,21 @@ func createSecret(out, errOut io.Writer, cmd *cobra.Command, args []string) erro
 		}
 	}
 
+	if createSecretRotationFunction != "" {
+		body.Rotation = &v1.SecretRotation{
+			Function: &createSecretRotationFunction,
+			Interval: int64(createSecretRotationInterval / time.Second),
+		}
+	}
+
+	if len(createSecretAllow) > 0 {
+		access, err := parseSecretAccess(createSecretAllow)
+		if err != nil {
+			return formatCliError(err, "invalid --allow flag")
+		}
+		body.Access = access
+	}
+
 	if cmdFlagApplication != "" {
 		body.Tags = append(body.Tags, &v1.Tag{
 			Key:   "Application",
@@ -105,3 +134,29 @@ func createSecret(out, errOut io.Writer, cmd *cobra.Command, args []string) erro
 	fmt.Fprintf(out, "Created secret: %s\n", *body.Name)
 	return nil
 }
+
+// parseSecretAccess builds the access rules of a secret from principals given as KIND/NAME
+func parseSecretAccess(principals []string) (*v1.SecretAccess, error) {
+	access := &v1.SecretAccess{}
+	for _, principal := range principals {
+		parts := strings.SplitN(principal, "/", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, errors.Errorf("principal %s is not of the form KIND/NAME", principal)
+		}
+		switch kind, name := parts[0], parts[1]; kind {
+		case client.RequesterFunction:
+			access.Functions = append(access.Functions, name)
+		case client.RequesterSubscription:
+			access.Subscriptions = append(access.Subscriptions, name)
+		case client.RequesterDriver:
+			access.Drivers = append(access.Drivers, name)
+		case client.RequesterServiceAccount:
+			access.ServiceAccounts = append(access.ServiceAccounts, name)
+		case client.RequesterUser:
+			access.Users = append(access.Users, name)
+		default:
+			return nil, errors.Errorf("unknown principal kind %s", kind)
+		}
+	}
+	return access, nil
+}
@@ -23,3 +23,17 @@ func TestCmdCreateSecret(t *testing.T) {
 	assert.Nil(t, err)
 	assert.True(t, strings.Contains(buf.String(), "Create a dispatch secret"))
 }
+
+func TestParseSecretAccess(t *testing.T) {
+	access, err := parseSecretAccess([]string{"function/build", "subscription/on-*", "user/joe@example.com"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"build"}, access.Functions)
+	assert.Equal(t, []string{"on-*"}, access.Subscriptions)
+	assert.Equal(t, []string{"joe@example.com"}, access.Users)
+	assert.Empty(t, access.Drivers)
+
+	_, err = parseSecretAccess([]string{"build"})
+	assert.NotNil(t, err)
+	_, err = parseSecretAccess([]string{"team/build"})
+	assert.NotNil(t, err)
+}
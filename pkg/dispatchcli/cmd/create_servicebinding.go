@@ -0,0 +1,105 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	servicebinding "github.com/vmware/dispatch/pkg/service-manager/gen/client/service_binding"
+)
+
+var (
+	createServiceBindingLong = i18n.T(`Create a service binding. A binding has its own credentials, which the functions
+naming the binding get. A service instance may have any number of bindings, the default binding is named after the
+instance.`)
+
+	createServiceBindingExample = i18n.T(`# bind a service instance for a read-only consumer
+dispatch create servicebinding mydb-reader mydb --params '{"role": "reader"}'`)
+
+	createServiceBindingParams    = i18n.T(``)
+	createServiceBindingSecrets   = []string{}
+	createServiceBindingSecretKey = i18n.T(``)
+)
+
+// CallCreateServiceBinding makes the API call to create a service binding
+func CallCreateServiceBinding(b interface{}) error {
+	client := serviceManagerClient()
+	body := b.(*v1.ServiceBinding)
+
+	params := &servicebinding.AddServiceBindingParams{
+		Body:    body,
+		Context: context.Background(),
+	}
+
+	created, err := client.ServiceBinding.AddServiceBinding(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+
+	*body = *created.Payload
+	return nil
+}
+
+// NewCmdCreateServiceBinding creates command responsible for service binding creation.
+func NewCmdCreateServiceBinding(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "servicebinding SERVICE_BINDING_NAME SERVICE_INSTANCE_NAME",
+		Short:   i18n.T("Create servicebinding"),
+		Long:    createServiceBindingLong,
+		Example: createServiceBindingExample,
+		Args:    cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := createServiceBinding(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&cmdFlagApplication, "application", "a", "", "associate with an application")
+	cmd.Flags().StringVarP(&createServiceBindingParams, "params", "p", "", "service binding parameters (JSON)")
+	cmd.Flags().StringArrayVarP(&createServiceBindingSecrets, "secret", "s", []string{}, "service binding secrets")
+	cmd.Flags().StringVarP(&createServiceBindingSecretKey, "secret-key", "B", "", "service binding secret key")
+	return cmd
+}
+
+func createServiceBinding(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	body := &v1.ServiceBinding{
+		Name:             args[0],
+		ServiceInstance:  args[1],
+		SecretParameters: createServiceBindingSecrets,
+		BindingSecret:    createServiceBindingSecretKey,
+	}
+
+	if cmdFlagApplication != "" {
+		body.Tags = append(body.Tags, &v1.Tag{
+			Key:   "Application",
+			Value: cmdFlagApplication,
+		})
+	}
+
+	p, err := parseParameters(createServiceBindingParams)
+	if err != nil {
+		return err
+	}
+	body.Parameters = p
+
+	err = CallCreateServiceBinding(body)
+	if err != nil {
+		return err
+	}
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(body)
+	}
+	fmt.Fprintf(out, "Created servicebinding: %s\n", body.Name)
+	return nil
+}
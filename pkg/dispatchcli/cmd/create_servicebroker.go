@@ -0,0 +1,99 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	servicebroker "github.com/vmware/dispatch/pkg/service-manager/gen/client/service_broker"
+)
+
+var (
+	createServiceBrokerLong = i18n.T(`Register an Open Service Broker API broker. The services of its catalog become service classes.
+	--username and --password - authenticate to the broker with basic authentication
+	--token - authenticate to the broker with a bearer token`)
+
+	createServiceBrokerExample = i18n.T(`# register a broker authenticating with basic authentication
+dispatch create servicebroker mysql https://mysql-broker.example.com --username admin --password secret`)
+
+	serviceBrokerUsername = ""
+	serviceBrokerPassword = ""
+	serviceBrokerToken    = ""
+)
+
+// CallCreateServiceBroker makes the API call to register a service broker
+func CallCreateServiceBroker(b interface{}) error {
+	client := serviceManagerClient()
+	body := b.(*v1.ServiceBroker)
+
+	params := &servicebroker.AddServiceBrokerParams{
+		Body:    body,
+		Context: context.Background(),
+	}
+
+	created, err := client.ServiceBroker.AddServiceBroker(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+
+	*body = *created.Payload
+	return nil
+}
+
+// NewCmdCreateServiceBroker creates command responsible for service broker registration.
+func NewCmdCreateServiceBroker(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "servicebroker SERVICE_BROKER_NAME URL [--username USERNAME --password PASSWORD | --token TOKEN]",
+		Short:   i18n.T("Register servicebroker"),
+		Long:    createServiceBrokerLong,
+		Example: createServiceBrokerExample,
+		Args:    cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := createServiceBroker(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVar(&serviceBrokerUsername, "username", "", "username of the broker basic authentication")
+	cmd.Flags().StringVar(&serviceBrokerPassword, "password", "", "password of the broker basic authentication")
+	cmd.Flags().StringVar(&serviceBrokerToken, "token", "", "bearer token of the broker authentication")
+	return cmd
+}
+
+func createServiceBroker(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	if serviceBrokerToken != "" && (serviceBrokerUsername != "" || serviceBrokerPassword != "") {
+		return formatCliError(errors.New("--token and --username/--password are exclusive"), "invalid flags")
+	}
+	if (serviceBrokerUsername == "") != (serviceBrokerPassword == "") {
+		return formatCliError(errors.New("--username and --password go together"), "invalid flags")
+	}
+	body := &v1.ServiceBroker{
+		Name:     &args[0],
+		URL:      &args[1],
+		Username: serviceBrokerUsername,
+		Password: serviceBrokerPassword,
+		Token:    serviceBrokerToken,
+	}
+
+	err := CallCreateServiceBroker(body)
+	if err != nil {
+		return err
+	}
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(body)
+	}
+	fmt.Fprintf(out, "Created servicebroker: %s\n", *body.Name)
+	return nil
+}
@@ -51,11 +51,16 @@ func NewCmdDelete(out io.Writer, errOut io.Writer) *cobra.Command {
 				utils.ApplicationKind:     CallDeleteApplication,
 				utils.PolicyKind:          CallDeletePolicy,
 				utils.ServiceAccountKind:  CallDeleteServiceAccount,
+				utils.RoleKind:            CallDeleteRole,
+				utils.RoleBindingKind:     CallDeleteRoleBinding,
 				utils.ServiceInstanceKind: CallDeleteServiceInstance,
+				utils.ServiceBindingKind:  CallDeleteServiceBinding,
+				utils.ServiceBrokerKind:   CallDeleteServiceBroker,
 				utils.DriverTypeKind:      CallDeleteEventDriverType(eventClient),
 				utils.DriverKind:          CallDeleteEventDriver(eventClient),
 				utils.SubscriptionKind:    CallDeleteSubscription(eventClient),
 				utils.APIKind:             CallDeleteAPI(apiClient),
+				utils.CertificateKind:     CallDeleteCertificate(apiClient),
 			}
 
 			err := importFile(out, errOut, cmd, args, deleteMap, "Deleted")
@@ -68,11 +73,14 @@ func NewCmdDelete(out io.Writer, errOut io.Writer) *cobra.Command {
 	cmd.AddCommand(NewCmdDeleteFunction(out, errOut))
 	cmd.AddCommand(NewCmdDeleteSecret(out, errOut))
 	cmd.AddCommand(NewCmdDeleteAPI(out, errOut))
+	cmd.AddCommand(NewCmdDeleteCertificate(out, errOut))
 	cmd.AddCommand(NewCmdDeleteSubscription(out, errOut))
 	cmd.AddCommand(NewCmdDeleteEventDriver(out, errOut))
 	cmd.AddCommand(NewCmdDeleteEventDriverType(out, errOut))
 	cmd.AddCommand(NewCmdDeleteApplication(out, errOut))
 	cmd.AddCommand(NewCmdDeleteServiceInstance(out, errOut))
+	cmd.AddCommand(NewCmdDeleteServiceBroker(out, errOut))
+	cmd.AddCommand(NewCmdDeleteServiceBinding(out, errOut))
 
 	cmd.Flags().StringVarP(&file, "file", "f", "", "Path to YAML file")
 	cmd.Flags().StringVarP(&workDir, "work-dir", "w", "", "Working directory relative paths are based on")
@@ -0,0 +1,76 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2017 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	deleteCertificateLong = i18n.T(`Delete certificate.`)
+
+	// TODO: add examples
+	deleteCertificateExample = i18n.T(``)
+)
+
+// NewCmdDeleteCertificate creates command responsible for deleting tls certificates.
+func NewCmdDeleteCertificate(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "certificate CERT_NAME",
+		Short:   i18n.T("Delete certificate"),
+		Long:    deleteCertificateLong,
+		Example: deleteCertificateExample,
+		Args:    cobra.ExactArgs(1),
+		Aliases: []string{"certificates"},
+		Run: func(cmd *cobra.Command, args []string) {
+			c := apiManagerClient()
+			err := deleteCertificate(out, errOut, cmd, args, c)
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&cmdFlagApplication, "application", "a", "", "filter by application")
+	return cmd
+}
+
+// CallDeleteCertificate makes the API call to delete a certificate
+func CallDeleteCertificate(c client.APIsClient) ModelAction {
+	return func(i interface{}) error {
+		certModel := i.(*v1.Certificate)
+
+		deleted, err := c.DeleteCertificate(context.TODO(), "", *certModel.Name)
+		if err != nil {
+			return formatAPIError(err, certModel)
+		}
+		*certModel = *deleted
+		return nil
+	}
+}
+
+func deleteCertificate(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.APIsClient) error {
+	certModel := v1.Certificate{
+		Name: &args[0],
+	}
+	err := CallDeleteCertificate(c)(&certModel)
+	if err != nil {
+		return err
+	}
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(certModel)
+	}
+	_, err = fmt.Fprintf(out, "Deleted certificate: %s\n", *certModel.Name)
+	return err
+}
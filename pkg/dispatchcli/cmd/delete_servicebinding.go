@@ -0,0 +1,77 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	servicebinding "github.com/vmware/dispatch/pkg/service-manager/gen/client/service_binding"
+)
+
+var (
+	deleteServiceBindingLong = i18n.T(`Delete a service binding, revoking its credentials. The other bindings of the
+service instance are left untouched.`)
+
+	deleteServiceBindingExample = i18n.T(`# revoke the access of a consumer of a service instance
+dispatch delete servicebinding mydb-reader`)
+)
+
+// NewCmdDeleteServiceBinding creates command responsible for deleting a service binding
+func NewCmdDeleteServiceBinding(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "servicebinding SERVICE_BINDING_NAME",
+		Short:   i18n.T("Delete service binding"),
+		Long:    deleteServiceBindingLong,
+		Example: deleteServiceBindingExample,
+		Args:    cobra.ExactArgs(1),
+		Aliases: []string{"servicebindings"},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := deleteServiceBinding(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+// CallDeleteServiceBinding makes the API call to delete a service binding
+func CallDeleteServiceBinding(b interface{}) error {
+	client := serviceManagerClient()
+	serviceBindingModel := b.(*v1.ServiceBinding)
+	params := &servicebinding.DeleteServiceBindingByNameParams{
+		ServiceBindingName: serviceBindingModel.Name,
+		Context:            context.Background(),
+	}
+	deleted, err := client.ServiceBinding.DeleteServiceBindingByName(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	*serviceBindingModel = *deleted.Payload
+	return nil
+}
+
+func deleteServiceBinding(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	serviceBindingModel := v1.ServiceBinding{
+		Name: args[0],
+	}
+	err := CallDeleteServiceBinding(&serviceBindingModel)
+	if err != nil {
+		return err
+	}
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(serviceBindingModel)
+	}
+	_, err = fmt.Fprintf(out, "Deleted service binding: %s\n", serviceBindingModel.Name)
+	return err
+}
@@ -0,0 +1,77 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"golang.org/x/net/context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	servicebroker "github.com/vmware/dispatch/pkg/service-manager/gen/client/service_broker"
+)
+
+var (
+	deleteServiceBrokerLong = i18n.T(`Delete service broker. The service classes of its catalog are removed, a broker whose service classes have service instances cannot be deleted.`)
+
+	// TODO: add examples
+	deleteServiceBrokerExample = i18n.T(``)
+)
+
+// NewCmdDeleteServiceBroker creates command responsible for deleting a service broker
+func NewCmdDeleteServiceBroker(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "servicebroker SERVICE_BROKER_NAME",
+		Short:   i18n.T("Delete service broker"),
+		Long:    deleteServiceBrokerLong,
+		Example: deleteServiceBrokerExample,
+		Args:    cobra.ExactArgs(1),
+		Aliases: []string{"servicebrokers"},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := deleteServiceBroker(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+// CallDeleteServiceBroker makes the API call to delete a service broker
+func CallDeleteServiceBroker(b interface{}) error {
+	client := serviceManagerClient()
+	serviceBrokerModel := b.(*v1.ServiceBroker)
+	params := &servicebroker.DeleteServiceBrokerByNameParams{
+		ServiceBrokerName: *serviceBrokerModel.Name,
+		Context:           context.Background(),
+	}
+	deleted, err := client.ServiceBroker.DeleteServiceBrokerByName(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	*serviceBrokerModel = *deleted.Payload
+	return nil
+}
+
+func deleteServiceBroker(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	serviceBrokerModel := v1.ServiceBroker{
+		Name: &args[0],
+	}
+	err := CallDeleteServiceBroker(&serviceBrokerModel)
+	if err != nil {
+		return err
+	}
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(serviceBrokerModel)
+	}
+	_, err = fmt.Fprintf(out, "Deleted service broker: %s\n", *serviceBrokerModel.Name)
+	return err
+}
@@ -7,12 +7,15 @@ package cmd
 
 import (
 	"github.com/pkg/errors"
+	certificate "github.com/vmware/dispatch/pkg/api-manager/gen/client/certificate"
 	endpoint "github.com/vmware/dispatch/pkg/api-manager/gen/client/endpoint"
 	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
 	runner "github.com/vmware/dispatch/pkg/function-manager/gen/client/runner"
 	function "github.com/vmware/dispatch/pkg/function-manager/gen/client/store"
+	audit "github.com/vmware/dispatch/pkg/identity-manager/gen/client/audit"
 	policy "github.com/vmware/dispatch/pkg/identity-manager/gen/client/policy"
 	serviceaccount "github.com/vmware/dispatch/pkg/identity-manager/gen/client/serviceaccount"
+	setup "github.com/vmware/dispatch/pkg/identity-manager/gen/client/setup"
 	baseimage "github.com/vmware/dispatch/pkg/image-manager/gen/client/base_image"
 	image "github.com/vmware/dispatch/pkg/image-manager/gen/client/image"
 	secret "github.com/vmware/dispatch/pkg/secret-store/gen/client/secret"
@@ -157,6 +160,8 @@ func formatAPIError(err error, params interface{}) error {
 	// Get
 	case *secret.GetSecretNotFound:
 		return i18n.Errorf("[Code: %d] get Secret not found: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *secret.GetSecretForbidden:
+		return i18n.Errorf("[Code: %d] get Secret forbidden: %s", v.Payload.Code, msg(v.Payload.Message))
 	case *secret.GetSecretDefault:
 		return i18n.Errorf("[Code: %d] get Secret error: %s", v.Payload.Code, msg(v.Payload.Message))
 	case *secret.GetSecretsDefault:
@@ -171,6 +176,35 @@ func formatAPIError(err error, params interface{}) error {
 		return i18n.Errorf("[Code: %d] Conflict: %s", v.Payload.Code, msg(v.Payload.Message))
 	case *secret.AddSecretDefault:
 		return i18n.Errorf("[Code: %d] create Secret error: %s", v.Payload.Code, msg(v.Payload.Message))
+	// Versions
+	case *secret.GetSecretVersionsNotFound:
+		return i18n.Errorf("[Code: %d] get Secret versions not found: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *secret.GetSecretVersionsDefault:
+		return i18n.Errorf("[Code: %d] get Secret versions error: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *secret.GetSecretVersionNotFound:
+		return i18n.Errorf("[Code: %d] get Secret version not found: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *secret.GetSecretVersionForbidden:
+		return i18n.Errorf("[Code: %d] get Secret version forbidden: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *secret.GetSecretVersionDefault:
+		return i18n.Errorf("[Code: %d] get Secret version error: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *secret.RollbackSecretNotFound:
+		return i18n.Errorf("[Code: %d] rollback Secret not found: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *secret.RollbackSecretDefault:
+		return i18n.Errorf("[Code: %d] rollback Secret error: %s", v.Payload.Code, msg(v.Payload.Message))
+	// Audit
+	case *secret.GetSecretAuditBadRequest:
+		return i18n.Errorf("[Code: %d] get Secret audit error: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *secret.GetSecretAuditNotFound:
+		return i18n.Errorf("[Code: %d] get Secret audit not found: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *secret.GetSecretAuditDefault:
+		return i18n.Errorf("[Code: %d] get Secret audit error: %s", v.Payload.Code, msg(v.Payload.Message))
+	// Rotate
+	case *secret.RotateSecretBadRequest:
+		return i18n.Errorf("[Code: %d] rotate Secret error: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *secret.RotateSecretNotFound:
+		return i18n.Errorf("[Code: %d] rotate Secret not found: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *secret.RotateSecretDefault:
+		return i18n.Errorf("[Code: %d] rotate Secret error: %s", v.Payload.Code, msg(v.Payload.Message))
 
 	// API
 	// List
@@ -207,6 +241,45 @@ func formatAPIError(err error, params interface{}) error {
 	case *endpoint.DeleteAPIInternalServerError:
 		return i18n.Errorf("[Code: %d] delete api error: %s", v.Payload.Code, msg(v.Payload.Message))
 
+	// Certificate
+	// List
+	case *certificate.GetCertificatesBadRequest:
+		return i18n.Errorf("[Code: %d] get certificates error: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *certificate.GetCertificatesInternalServerError:
+		return i18n.Errorf("[Code: %d] get certificates error: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *certificate.GetCertificatesDefault:
+		return i18n.Errorf("[Code: %d] get certificates error: %s", v.Payload.Code, msg(v.Payload.Message))
+	// Get
+	case *certificate.GetCertificateBadRequest:
+		return i18n.Errorf("[Code: %d] get certificate error: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *certificate.GetCertificateNotFound:
+		return i18n.Errorf("[Code: %d] get certificate error: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *certificate.GetCertificateInternalServerError:
+		return i18n.Errorf("[Code: %d] get certificate error: %s", v.Payload.Code, msg(v.Payload.Message))
+	// Create
+	case *certificate.AddCertificateBadRequest:
+		return i18n.Errorf("[Code: %d] create certificate error: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *certificate.AddCertificateUnauthorized:
+		return i18n.Errorf("[Code: %d] create certificate error: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *certificate.AddCertificateConflict:
+		return i18n.Errorf("[Code: %d] Conflict: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *certificate.AddCertificateInternalServerError:
+		return i18n.Errorf("[Code: %d] create certificate error: %s", v.Payload.Code, msg(v.Payload.Message))
+	// Update
+	case *certificate.UpdateCertificateNotFound:
+		return i18n.Errorf("[Code: %d] update certificate error: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *certificate.UpdateCertificateBadRequest:
+		return i18n.Errorf("[Code: %d] update certificate error: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *certificate.UpdateCertificateInternalServerError:
+		return i18n.Errorf("[Code: %d] update certificate error: %s", v.Payload.Code, msg(v.Payload.Message))
+	// Delete
+	case *certificate.DeleteCertificateBadRequest:
+		return i18n.Errorf("[Code: %d] delete certificate error: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *certificate.DeleteCertificateNotFound:
+		return i18n.Errorf("[Code: %d] delete certificate error: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *certificate.DeleteCertificateInternalServerError:
+		return i18n.Errorf("[Code: %d] delete certificate error: %s", v.Payload.Code, msg(v.Payload.Message))
+
 	// Policy
 	// Add
 	case *policy.AddPolicyConflict:
@@ -267,6 +340,22 @@ func formatAPIError(err error, params interface{}) error {
 	case *serviceaccount.UpdateServiceAccountInternalServerError:
 		return i18n.Errorf("[Code: %d] Update Service Account internal server error: %s", v.Payload.Code, msg(v.Payload.Message))
 
+	// Audit
+	case *audit.GetAuditRecordsInternalServerError:
+		return i18n.Errorf("[Code: %d] Get Audit Records internal server error: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *audit.GetAuditRecordsDefault:
+		return i18n.Errorf("[Code: %d] Get Audit Records error: %s", v.Payload.Code, msg(v.Payload.Message))
+
+	// Setup
+	case *setup.CompleteSetupBadRequest:
+		return i18n.Errorf("[Code: %d] Setup bad request: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *setup.CompleteSetupUnauthorized:
+		return i18n.Errorf("[Code: %d] Setup unauthorized: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *setup.CompleteSetupConflict:
+		return i18n.Errorf("[Code: %d] Setup conflict: %s", v.Payload.Code, msg(v.Payload.Message))
+	case *setup.CompleteSetupInternalServerError:
+		return i18n.Errorf("[Code: %d] Setup internal server error: %s", v.Payload.Code, msg(v.Payload.Message))
+
 	default:
 		return i18n.Errorf("received unexpected error: %+v", v)
 	}
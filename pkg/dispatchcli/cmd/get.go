@@ -6,6 +6,7 @@
 package cmd
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/spf13/cobra"
@@ -13,6 +14,9 @@ import (
 	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
 )
 
+// getPageSize is the number of resources the get commands request per page, they page until the listing is complete
+const getPageSize = 500
+
 var (
 	getLong = `Display one or many resources.` + validResources
 
@@ -45,15 +49,26 @@ func NewCmdGet(out io.Writer, errOut io.Writer) *cobra.Command {
 	cmd.AddCommand(NewCmdGetRun(out, errOut))
 	cmd.AddCommand(NewCmdGetSecret(out, errOut))
 	cmd.AddCommand(NewCmdGetAPI(out, errOut))
+	cmd.AddCommand(NewCmdGetCertificate(out, errOut))
 	cmd.AddCommand(NewCmdGetSubscription(out, errOut))
 	cmd.AddCommand(NewCmdGetEventDriver(out, errOut))
 	cmd.AddCommand(NewCmdGetEventDriverType(out, errOut))
 	cmd.AddCommand(NewCmdGetApplication(out, errOut))
 	cmd.AddCommand(NewCmdGetServiceClass(out, errOut))
 	cmd.AddCommand(NewCmdGetServiceInstance(out, errOut))
+	cmd.AddCommand(NewCmdGetServiceBroker(out, errOut))
+	cmd.AddCommand(NewCmdGetServiceBinding(out, errOut))
 	return cmd
 }
 
+// getSelector returns the label selector of the get commands, the selector flag and the application flag
+func getSelector() string {
+	if cmdFlagApplication == "" {
+		return cmdFlagSelector
+	}
+	return fmt.Sprintf("application=%s,%s", cmdFlagApplication, cmdFlagSelector)
+}
+
 func runGet(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
 	runHelp(cmd, args)
 	return nil
@@ -49,12 +49,13 @@ func NewCmdGetAPI(out io.Writer, errOut io.Writer) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&cmdFlagApplication, "application", "a", "", "filter by application")
+	cmd.Flags().StringVarP(&cmdFlagSelector, "selector", "l", "", "filter by label selector, e.g. 'tier in (web,api),!canary'")
 	cmd.Flags().StringVarP(&functionName, "func", "f", "", "get all apis for specified function")
 	return cmd
 }
 
 func getAPIs(out, errOut io.Writer, cmd *cobra.Command, c client.APIsClient) error {
-	get, err := c.ListAPIs(context.TODO(), "")
+	get, err := c.ListAPIs(context.TODO(), "", getSelector())
 	if err != nil {
 		return formatAPIError(err, get)
 	}
@@ -10,12 +10,14 @@ import (
 	"io"
 	"time"
 
+	"github.com/go-openapi/swag"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"golang.org/x/net/context"
 
 	"github.com/vmware/dispatch/pkg/api/v1"
 	"github.com/vmware/dispatch/pkg/application-manager/gen/client/application"
+	"github.com/vmware/dispatch/pkg/dispatchcli/cmd/utils"
 	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
 )
 
@@ -45,6 +47,7 @@ func NewCmdGetApplication(out io.Writer, errOut io.Writer) *cobra.Command {
 			CheckErr(err)
 		},
 	}
+	cmd.Flags().StringVarP(&cmdFlagSelector, "selector", "l", "", "filter by label selector, e.g. 'tier in (web,api),!canary'")
 	return cmd
 }
 
@@ -66,12 +69,23 @@ func getApplications(out, errOut io.Writer, cmd *cobra.Command) error {
 	client := applicationManagerClient()
 	params := &application.GetAppsParams{
 		Context: context.Background(),
+		Tags:    []string{},
+		Limit:   swag.Int64(getPageSize),
 	}
-	resp, err := client.Application.GetApps(params, GetAuthInfoWriter())
-	if err != nil {
-		return formatAPIError(err, params)
+	utils.AppendSelector(&params.Tags, cmdFlagSelector)
+	var applications []*v1.Application
+	for {
+		resp, err := client.Application.GetApps(params, GetAuthInfoWriter())
+		if err != nil {
+			return formatAPIError(err, params)
+		}
+		applications = append(applications, resp.Payload...)
+		if resp.XDispatchContinue == "" {
+			break
+		}
+		params.Continue = swag.String(resp.XDispatchContinue)
 	}
-	return formatApplicationOutput(out, true, resp.Payload)
+	return formatApplicationOutput(out, true, applications)
 }
 
 func formatApplicationOutput(out io.Writer, list bool, applications []*v1.Application) error {
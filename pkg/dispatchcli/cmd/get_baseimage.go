@@ -47,6 +47,7 @@ func NewCmdGetBaseImage(out io.Writer, errOut io.Writer) *cobra.Command {
 			CheckErr(err)
 		},
 	}
+	cmd.Flags().StringVarP(&cmdFlagSelector, "selector", "l", "", "filter by label selector, e.g. 'tier in (web,api),!canary'")
 	return cmd
 }
 
@@ -60,7 +61,7 @@ func getBaseImage(out, errOut io.Writer, cmd *cobra.Command, args []string, c cl
 }
 
 func getBaseImages(out, errOut io.Writer, cmd *cobra.Command, c client.ImagesClient) error {
-	resp, err := c.ListBaseImages(context.TODO(), dispatchConfig.Organization)
+	resp, err := c.ListBaseImages(context.TODO(), dispatchConfig.Organization, getSelector())
 	if err != nil {
 		return formatAPIError(err, nil)
 	}
@@ -0,0 +1,99 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2017 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	getCertificateLong = i18n.T(
+		`Get tls certificates.`)
+	// TODO: add examples
+	getCertificateExample = i18n.T(``)
+)
+
+// NewCmdGetCertificate gets command responsible for getting tls certificates.
+func NewCmdGetCertificate(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "certificate [CERT_NAME]",
+		Short:   i18n.T("Get certificate"),
+		Long:    getCertificateLong,
+		Example: getCertificateExample,
+		Args:    cobra.MaximumNArgs(1),
+		Aliases: []string{"certificates"},
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			c := apiManagerClient()
+			if len(args) == 1 {
+				err = getCertificate(out, errOut, cmd, args, c)
+			} else {
+				err = getCertificates(out, errOut, cmd, c)
+			}
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&cmdFlagApplication, "application", "a", "", "filter by application")
+	cmd.Flags().StringVarP(&cmdFlagSelector, "selector", "l", "", "filter by label selector, e.g. 'tier in (web,api),!canary'")
+	return cmd
+}
+
+func getCertificates(out, errOut io.Writer, cmd *cobra.Command, c client.APIsClient) error {
+	get, err := c.ListCertificates(context.TODO(), "", getSelector())
+	if err != nil {
+		return formatAPIError(err, get)
+	}
+	return formatCertificateOutput(out, true, get)
+}
+
+func getCertificate(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.APIsClient) error {
+	get, err := c.GetCertificate(context.TODO(), "", args[0])
+	if err != nil {
+		return formatAPIError(err, get)
+	}
+	return formatCertificateOutput(out, false, []v1.Certificate{*get})
+}
+
+func formatCertificateOutput(out io.Writer, list bool, certs []v1.Certificate) error {
+
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		if list {
+			return encoder.Encode(certs)
+		}
+		return encoder.Encode(certs[0])
+	}
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Name", "Domain", "Secret", "Issuer", "Expiration", "ACME", "Status"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("-")
+	table.SetRowLine(true)
+	for _, c := range certs {
+		expiration := ""
+		if c.Expiration != 0 {
+			expiration = time.Unix(c.Expiration, 0).Local().Format(time.UnixDate)
+		}
+		table.Append([]string{
+			*c.Name, strings.Join(c.Hosts, "\n"), c.Secret, c.Issuer, expiration,
+			fmt.Sprintf("%t", c.Acme), string(c.Status),
+		})
+	}
+	table.Render()
+	return nil
+}
@@ -48,12 +48,13 @@ func NewCmdGetEventDriver(out io.Writer, errOut io.Writer) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&cmdFlagApplication, "application", "a", "", "filter by application")
+	cmd.Flags().StringVarP(&cmdFlagSelector, "selector", "l", "", "filter by label selector, e.g. 'tier in (web,api),!canary'")
 	return cmd
 }
 
 func getEventDrivers(out, errOut io.Writer, cmd *cobra.Command, c client.EventsClient) error {
 
-	get, err := c.ListEventDrivers(context.TODO(), "")
+	get, err := c.ListEventDrivers(context.TODO(), "", getSelector())
 	if err != nil {
 		return formatAPIError(err, get)
 	}
@@ -47,13 +47,14 @@ func NewCmdGetEventDriverType(out io.Writer, errOut io.Writer) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&cmdFlagApplication, "application", "a", "", "filter by application")
+	cmd.Flags().StringVarP(&cmdFlagSelector, "selector", "l", "", "filter by label selector, e.g. 'tier in (web,api),!canary'")
 	cmd.Flags().BoolVar(&getEventDriverTypeShowBuiltIn, "show-builtin", false, "Include built-in driver types in result")
 	return cmd
 }
 
 func getEventDriverTypes(out, errOut io.Writer, cmd *cobra.Command, c client.EventsClient) error {
 
-	get, err := c.ListEventDriverTypes(context.TODO(), "")
+	get, err := c.ListEventDriverTypes(context.TODO(), "", getSelector())
 	if err != nil {
 		return formatAPIError(err, get)
 	}
@@ -47,6 +47,7 @@ func NewCmdGetFunction(out io.Writer, errOut io.Writer) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&cmdFlagApplication, "application", "a", "", "filter by application")
+	cmd.Flags().StringVarP(&cmdFlagSelector, "selector", "l", "", "filter by label selector, e.g. 'tier in (web,api),!canary'")
 	return cmd
 }
 
@@ -62,7 +63,7 @@ func getFunction(out, errOut io.Writer, cmd *cobra.Command, args []string, c cli
 }
 
 func getFunctions(out, errOut io.Writer, cmd *cobra.Command, c client.FunctionsClient) error {
-	resp, err := c.ListFunctions(context.TODO(), dispatchConfig.Organization)
+	resp, err := c.ListFunctions(context.TODO(), dispatchConfig.Organization, getSelector())
 	if err != nil {
 		return formatAPIError(err, nil)
 	}
@@ -47,6 +47,7 @@ func NewCmdGetImage(out io.Writer, errOut io.Writer) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&cmdFlagApplication, "application", "a", "", "filter by application")
+	cmd.Flags().StringVarP(&cmdFlagSelector, "selector", "l", "", "filter by label selector, e.g. 'tier in (web,api),!canary'")
 	return cmd
 }
 
@@ -61,7 +62,7 @@ func getImage(out, errOut io.Writer, cmd *cobra.Command, args []string, c client
 }
 
 func getImages(out, errOut io.Writer, cmd *cobra.Command, c client.ImagesClient) error {
-	resp, err := c.ListImages(context.TODO(), dispatchConfig.Organization)
+	resp, err := c.ListImages(context.TODO(), dispatchConfig.Organization, getSelector())
 	if err != nil {
 		return formatAPIError(err, nil)
 	}
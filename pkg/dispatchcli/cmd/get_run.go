@@ -49,6 +49,7 @@ func NewCmdGetRun(out io.Writer, errOut io.Writer) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&cmdFlagApplication, "application", "a", "", "filter by application")
+	cmd.Flags().StringVarP(&cmdFlagSelector, "selector", "l", "", "filter by label selector, e.g. 'tier in (web,api),!canary'")
 	return cmd
 }
 
@@ -67,7 +68,7 @@ func getFunctionRun(out, errOut io.Writer, cmd *cobra.Command, args []string, c
 }
 
 func getRuns(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.FunctionsClient) error {
-	resp, err := c.ListRuns(context.TODO(), "")
+	resp, err := c.ListRuns(context.TODO(), "", getSelector())
 
 	if err != nil {
 		return formatAPIError(err, resp)
@@ -78,7 +79,7 @@ func getRuns(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.
 func getFunctionRuns(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.FunctionsClient) error {
 	fnName := args[0]
 
-	resp, err := c.ListFunctionRuns(context.TODO(), "", fnName)
+	resp, err := c.ListFunctionRuns(context.TODO(), "", fnName, getSelector())
 
 	if err != nil {
 		return formatAPIError(err, resp)
@@ -9,6 +9,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
@@ -25,7 +27,10 @@ var (
 	// TODO: add examples
 	getSecretsExample = i18n.T(``)
 
-	getSecretContent = false
+	getSecretContent  = false
+	getSecretVersions = false
+	getSecretVersion  int64
+	getSecretAudit    = false
 )
 
 // NewCmdGetSecret creates command responsible for getting secrets.
@@ -39,7 +44,13 @@ func NewCmdGetSecret(out io.Writer, errOut io.Writer) *cobra.Command {
 		Aliases: []string{"secrets"},
 		Run: func(cmd *cobra.Command, args []string) {
 			var err error
-			if len(args) == 1 {
+			if len(args) == 1 && getSecretAudit {
+				err = getSecretAuditList(out, errOut, cmd, args)
+			} else if len(args) == 1 && getSecretVersions {
+				err = getSecretVersionList(out, errOut, cmd, args)
+			} else if len(args) == 1 && getSecretVersion > 0 {
+				err = getSecretAtVersion(out, errOut, cmd, args)
+			} else if len(args) == 1 {
 				err = getSecret(out, errOut, cmd, args)
 			} else {
 				err = getSecrets(out, errOut, cmd)
@@ -48,7 +59,11 @@ func NewCmdGetSecret(out io.Writer, errOut io.Writer) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&cmdFlagApplication, "application", "a", "", "filter by application")
+	cmd.Flags().StringVarP(&cmdFlagSelector, "selector", "l", "", "filter by label selector, e.g. 'tier in (web,api),!canary'")
 	cmd.Flags().BoolVarP(&getSecretContent, "all", "", false, "also get secret content (in json format)")
+	cmd.Flags().BoolVarP(&getSecretVersions, "versions", "", false, "list the versions of the secret")
+	cmd.Flags().Int64VarP(&getSecretVersion, "version", "", 0, "get a specific version of the secret")
+	cmd.Flags().BoolVarP(&getSecretAudit, "audit", "", false, "list the reads of the secret values")
 	return cmd
 }
 
@@ -78,6 +93,93 @@ func getSecret(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
 	return formatSecretOutput(out, false, []*v1.Secret{resp.Payload})
 }
 
+func getSecretAtVersion(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	client := secretStoreClient()
+	params := &secret.GetSecretVersionParams{
+		Context:    context.Background(),
+		SecretName: args[0],
+		Version:    getSecretVersion,
+	}
+
+	resp, err := client.Secret.GetSecretVersion(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	return formatSecretOutput(out, false, []*v1.Secret{resp.Payload})
+}
+
+func getSecretVersionList(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	client := secretStoreClient()
+	params := &secret.GetSecretVersionsParams{
+		Context:    context.Background(),
+		SecretName: args[0],
+	}
+
+	resp, err := client.Secret.GetSecretVersions(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(resp.Payload)
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Version", "Created Date", "Current"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	for _, version := range resp.Payload {
+		current := ""
+		if version.Current {
+			current = "*"
+		}
+		table.Append([]string{
+			strconv.FormatInt(version.Version, 10),
+			time.Unix(version.CreatedTime, 0).Local().Format(time.UnixDate),
+			current,
+		})
+	}
+	table.Render()
+	return nil
+}
+
+func getSecretAuditList(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	client := secretStoreClient()
+	params := &secret.GetSecretAuditParams{
+		Context:    context.Background(),
+		SecretName: args[0],
+	}
+
+	resp, err := client.Secret.GetSecretAudit(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(resp.Payload)
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Time", "Version", "Requester", "Purpose", "Allowed"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	for _, read := range resp.Payload {
+		table.Append([]string{
+			time.Unix(read.ReadTime, 0).Local().Format(time.UnixDate),
+			strconv.FormatInt(read.Version, 10),
+			read.Requester,
+			read.Purpose,
+			strconv.FormatBool(read.Allowed),
+		})
+	}
+	table.Render()
+	return nil
+}
+
 func getSecrets(out, errOut io.Writer, cmd *cobra.Command) error {
 	client := secretStoreClient()
 	params := &secret.GetSecretsParams{
@@ -85,6 +187,7 @@ func getSecrets(out, errOut io.Writer, cmd *cobra.Command) error {
 		Tags:    []string{},
 	}
 	utils.AppendApplication(&params.Tags, cmdFlagApplication)
+	utils.AppendSelector(&params.Tags, cmdFlagSelector)
 
 	resp, err := client.Secret.GetSecrets(params, GetAuthInfoWriter())
 	if err != nil {
@@ -107,11 +210,11 @@ func formatSecretOutput(out io.Writer, list bool, secrets []*v1.Secret) error {
 	fmt.Fprintf(out, "Note: secret values are hidden, please use --all flag to get them\n\n")
 
 	table := tablewriter.NewWriter(out)
-	table.SetHeader([]string{"ID", "Name", "Content"})
+	table.SetHeader([]string{"ID", "Name", "Version", "Content"})
 	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
 	table.SetCenterSeparator("")
 	for _, secret := range secrets {
-		table.Append([]string{secret.ID.String(), *secret.Name, "<hidden>"})
+		table.Append([]string{secret.ID.String(), *secret.Name, strconv.FormatInt(secret.Version, 10), "<hidden>"})
 	}
 	table.Render()
 	return nil
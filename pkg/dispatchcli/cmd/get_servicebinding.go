@@ -0,0 +1,117 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/go-openapi/swag"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/cmd/utils"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	servicebinding "github.com/vmware/dispatch/pkg/service-manager/gen/client/service_binding"
+)
+
+var (
+	getServiceBindingsLong = i18n.T(`Get service bindings.`)
+
+	getServiceBindingsExample = i18n.T(`# list the bindings of a service instance
+dispatch get servicebindings --serviceinstance mydb`)
+
+	getServiceBindingsInstance = i18n.T(``)
+)
+
+// NewCmdGetServiceBinding creates command responsible for getting service bindings.
+func NewCmdGetServiceBinding(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "servicebinding [SERVICE_BINDING_NAME]",
+		Short:   i18n.T("Get servicebindings"),
+		Long:    getServiceBindingsLong,
+		Example: getServiceBindingsExample,
+		Args:    cobra.MaximumNArgs(1),
+		Aliases: []string{"servicebindings"},
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			if len(args) == 1 {
+				err = getServiceBinding(out, errOut, cmd, args)
+			} else {
+				err = getServiceBindings(out, errOut, cmd)
+			}
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVar(&getServiceBindingsInstance, "serviceinstance", "", "filter by service instance")
+	cmd.Flags().StringVarP(&cmdFlagSelector, "selector", "l", "", "filter by label selector, e.g. 'tier in (web,api),!canary'")
+	return cmd
+}
+
+func getServiceBinding(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	client := serviceManagerClient()
+	params := &servicebinding.GetServiceBindingByNameParams{
+		Context:            context.Background(),
+		ServiceBindingName: args[0],
+	}
+
+	resp, err := client.ServiceBinding.GetServiceBindingByName(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	return formatServiceBindingOutput(out, false, []*v1.ServiceBinding{resp.Payload})
+}
+
+func getServiceBindings(out, errOut io.Writer, cmd *cobra.Command) error {
+	client := serviceManagerClient()
+	params := &servicebinding.GetServiceBindingsParams{
+		Context: context.Background(),
+		Tags:    []string{},
+		Limit:   swag.Int64(getPageSize),
+	}
+	if getServiceBindingsInstance != "" {
+		params.Serviceinstance = swag.String(getServiceBindingsInstance)
+	}
+	utils.AppendApplication(&params.Tags, cmdFlagApplication)
+	utils.AppendSelector(&params.Tags, cmdFlagSelector)
+
+	var serviceBindings []*v1.ServiceBinding
+	for {
+		resp, err := client.ServiceBinding.GetServiceBindings(params, GetAuthInfoWriter())
+		if err != nil {
+			return formatAPIError(err, params)
+		}
+		serviceBindings = append(serviceBindings, resp.Payload...)
+		if resp.XDispatchContinue == "" {
+			break
+		}
+		params.Continue = swag.String(resp.XDispatchContinue)
+	}
+	return formatServiceBindingOutput(out, true, serviceBindings)
+}
+
+func formatServiceBindingOutput(out io.Writer, list bool, serviceBindings []*v1.ServiceBinding) error {
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		if list {
+			return encoder.Encode(serviceBindings)
+		}
+		return encoder.Encode(serviceBindings[0])
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Name", "Instance", "Status"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	for _, binding := range serviceBindings {
+		table.Append([]string{binding.Name, binding.ServiceInstance, string(binding.Status)})
+	}
+	table.Render()
+	return nil
+}
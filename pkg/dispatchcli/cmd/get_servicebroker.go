@@ -0,0 +1,116 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/cmd/utils"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	servicebroker "github.com/vmware/dispatch/pkg/service-manager/gen/client/service_broker"
+	"golang.org/x/net/context"
+)
+
+var (
+	getServiceBrokersLong = i18n.T(`Get service brokers.`)
+
+	// TODO: add examples
+	getServiceBrokersExample = i18n.T(``)
+)
+
+// NewCmdGetServiceBroker creates command responsible for getting service brokers.
+func NewCmdGetServiceBroker(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "servicebroker [SERVICE_BROKER_NAME ...]",
+		Short:   i18n.T("Get servicebrokers"),
+		Long:    getServiceBrokersLong,
+		Example: getServiceBrokersExample,
+		Args:    cobra.MaximumNArgs(1),
+		Aliases: []string{"servicebrokers"},
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			if len(args) == 1 {
+				err = getServiceBroker(out, errOut, cmd, args)
+			} else {
+				err = getServiceBrokers(out, errOut, cmd)
+			}
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&cmdFlagSelector, "selector", "l", "", "filter by label selector, e.g. 'tier in (web,api),!canary'")
+	return cmd
+}
+
+func getServiceBroker(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	client := serviceManagerClient()
+	params := &servicebroker.GetServiceBrokerByNameParams{
+		Context:           context.Background(),
+		ServiceBrokerName: args[0],
+	}
+
+	resp, err := client.ServiceBroker.GetServiceBrokerByName(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	return formatServiceBrokerOutput(out, false, []*v1.ServiceBroker{resp.Payload})
+}
+
+func getServiceBrokers(out, errOut io.Writer, cmd *cobra.Command) error {
+	client := serviceManagerClient()
+	params := &servicebroker.GetServiceBrokersParams{
+		Context: context.Background(),
+		Tags:    []string{},
+		Limit:   swag.Int64(getPageSize),
+	}
+	utils.AppendSelector(&params.Tags, cmdFlagSelector)
+
+	var serviceBrokers []*v1.ServiceBroker
+	for {
+		resp, err := client.ServiceBroker.GetServiceBrokers(params, GetAuthInfoWriter())
+		if err != nil {
+			return formatAPIError(err, params)
+		}
+		serviceBrokers = append(serviceBrokers, resp.Payload...)
+		if resp.XDispatchContinue == "" {
+			break
+		}
+		params.Continue = swag.String(resp.XDispatchContinue)
+	}
+	return formatServiceBrokerOutput(out, true, serviceBrokers)
+}
+
+func formatServiceBrokerOutput(out io.Writer, list bool, serviceBrokers []*v1.ServiceBroker) error {
+
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		if list {
+			return encoder.Encode(serviceBrokers)
+		}
+		return encoder.Encode(serviceBrokers[0])
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Name", "URL", "Status", "Refreshed", "Reason"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	for _, broker := range serviceBrokers {
+		refreshed := ""
+		if broker.RefreshedTime != 0 {
+			refreshed = time.Unix(broker.RefreshedTime, 0).Local().Format(time.UnixDate)
+		}
+		table.Append([]string{*broker.Name, *broker.URL, string(broker.Status), refreshed, strings.Join(broker.Reason, "\n")})
+	}
+	table.Render()
+	return nil
+}
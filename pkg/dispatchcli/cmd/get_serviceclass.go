@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/go-openapi/swag"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"github.com/vmware/dispatch/pkg/api/v1"
@@ -46,6 +47,7 @@ func NewCmdGetServiceClass(out io.Writer, errOut io.Writer) *cobra.Command {
 			CheckErr(err)
 		},
 	}
+	cmd.Flags().StringVarP(&cmdFlagSelector, "selector", "l", "", "filter by label selector, e.g. 'tier in (web,api),!canary'")
 	return cmd
 }
 
@@ -78,14 +80,24 @@ func getServiceClasses(out, errOut io.Writer, cmd *cobra.Command) error {
 	params := &serviceclass.GetServiceClassesParams{
 		Context: context.Background(),
 		Tags:    []string{},
+		Limit:   swag.Int64(getPageSize),
 	}
 	utils.AppendApplication(&params.Tags, cmdFlagApplication)
+	utils.AppendSelector(&params.Tags, cmdFlagSelector)
 
-	resp, err := client.ServiceClass.GetServiceClasses(params, GetAuthInfoWriter())
-	if err != nil {
-		return formatAPIError(err, params)
+	var serviceClasses []*v1.ServiceClass
+	for {
+		resp, err := client.ServiceClass.GetServiceClasses(params, GetAuthInfoWriter())
+		if err != nil {
+			return formatAPIError(err, params)
+		}
+		serviceClasses = append(serviceClasses, resp.Payload...)
+		if resp.XDispatchContinue == "" {
+			break
+		}
+		params.Continue = swag.String(resp.XDispatchContinue)
 	}
-	return formatServiceClassOutput(out, true, resp.Payload)
+	return formatServiceClassOutput(out, true, serviceClasses)
 }
 
 func formatServiceClassOutput(out io.Writer, list bool, serviceClasses []*v1.ServiceClass) error {
@@ -10,6 +10,7 @@ import (
 	"io"
 	"strconv"
 
+	"github.com/go-openapi/swag"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"github.com/vmware/dispatch/pkg/api/v1"
@@ -45,6 +46,7 @@ func NewCmdGetServiceInstance(out io.Writer, errOut io.Writer) *cobra.Command {
 			CheckErr(err)
 		},
 	}
+	cmd.Flags().StringVarP(&cmdFlagSelector, "selector", "l", "", "filter by label selector, e.g. 'tier in (web,api),!canary'")
 	return cmd
 }
 
@@ -77,14 +79,24 @@ func getServiceInstances(out, errOut io.Writer, cmd *cobra.Command) error {
 	params := &serviceinstance.GetServiceInstancesParams{
 		Context: context.Background(),
 		Tags:    []string{},
+		Limit:   swag.Int64(getPageSize),
 	}
 	utils.AppendApplication(&params.Tags, cmdFlagApplication)
+	utils.AppendSelector(&params.Tags, cmdFlagSelector)
 
-	resp, err := client.ServiceInstance.GetServiceInstances(params, GetAuthInfoWriter())
-	if err != nil {
-		return formatAPIError(err, params)
+	var serviceInstances []*v1.ServiceInstance
+	for {
+		resp, err := client.ServiceInstance.GetServiceInstances(params, GetAuthInfoWriter())
+		if err != nil {
+			return formatAPIError(err, params)
+		}
+		serviceInstances = append(serviceInstances, resp.Payload...)
+		if resp.XDispatchContinue == "" {
+			break
+		}
+		params.Continue = swag.String(resp.XDispatchContinue)
 	}
-	return formatServiceInstanceOutput(out, true, resp.Payload)
+	return formatServiceInstanceOutput(out, true, serviceInstances)
 }
 
 func formatServiceInstanceOutput(out io.Writer, list bool, serviceInstances []*v1.ServiceInstance) error {
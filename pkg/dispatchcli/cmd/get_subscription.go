@@ -47,6 +47,7 @@ func NewCmdGetSubscription(out io.Writer, errOut io.Writer) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&cmdFlagApplication, "application", "a", "", "filter by application")
+	cmd.Flags().StringVarP(&cmdFlagSelector, "selector", "l", "", "filter by label selector, e.g. 'tier in (web,api),!canary'")
 	return cmd
 }
 
@@ -60,7 +61,7 @@ func getSubscription(out, errOut io.Writer, cmd *cobra.Command, args []string, c
 }
 
 func getSubscriptions(out, errOut io.Writer, cmd *cobra.Command, c client.EventsClient) error {
-	resp, err := c.ListSubscriptions(context.TODO(), "")
+	resp, err := c.ListSubscriptions(context.TODO(), "", getSelector())
 	if err != nil {
 		return formatAPIError(err, resp)
 	}
@@ -31,5 +31,9 @@ func NewCmdIam(out, errOut io.Writer) *cobra.Command {
 	cmd.AddCommand(NewCmdIamGet(out, errOut))
 	cmd.AddCommand(NewCmdIamDelete(out, errOut))
 	cmd.AddCommand(NewCmdUpdate(out, errOut))
+	cmd.AddCommand(NewCmdIamCheck(out, errOut))
+	cmd.AddCommand(NewCmdIamAudit(out, errOut))
+	cmd.AddCommand(NewCmdIamToken(out, errOut))
+	cmd.AddCommand(NewCmdIamRevoke(out, errOut))
 	return cmd
 }
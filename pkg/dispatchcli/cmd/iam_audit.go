@@ -0,0 +1,140 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/audit"
+)
+
+var (
+	iamAuditLong = i18n.T(`List the audit records of the calls creating, updating or deleting resources, and of the requests denied, oldest first.`)
+
+	iamAuditExample = i18n.T(`
+# List the calls of the last hour
+dispatch iam audit --since 1h
+
+# List the calls made by a user on a given day
+dispatch iam audit --principal user/jane@example.com --since 2018-06-01T00:00:00Z --until 2018-06-02T00:00:00Z
+`)
+
+	auditSince     string
+	auditUntil     string
+	auditPrincipal string
+)
+
+// NewCmdIamAudit creates command listing the audit records
+func NewCmdIamAudit(out, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     i18n.T("audit [--since TIME] [--until TIME] [--principal PRINCIPAL]"),
+		Short:   i18n.T("List the audit records of mutating API calls"),
+		Long:    iamAuditLong,
+		Example: iamAuditExample,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := iamAudit(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVar(&auditSince, "since", "", "only the records from this time, either RFC 3339 or a duration ago, e.g. 24h")
+	cmd.Flags().StringVar(&auditUntil, "until", "", "only the records up to this time, either RFC 3339 or a duration ago, e.g. 1h")
+	cmd.Flags().StringVarP(&auditPrincipal, "principal", "p", "", "only the records of calls made by this principal, e.g. user/jane@example.com")
+	return cmd
+}
+
+// parseAuditTime parses a time given either in RFC 3339 or as a duration before now
+func parseAuditTime(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid time %s, must be either RFC 3339 or a duration", value)
+	}
+	return t, nil
+}
+
+func iamAudit(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	client := identityManagerClient()
+	params := &audit.GetAuditRecordsParams{
+		XDispatchOrg: dispatchConfig.Organization,
+		Context:      context.Background(),
+	}
+	now := time.Now()
+	if auditSince != "" {
+		since, err := parseAuditTime(auditSince, now)
+		if err != nil {
+			return err
+		}
+		params.Since = swag.Int64(since.Unix())
+	}
+	if auditUntil != "" {
+		until, err := parseAuditTime(auditUntil, now)
+		if err != nil {
+			return err
+		}
+		params.Until = swag.Int64(until.Unix())
+	}
+	if auditPrincipal != "" {
+		params.Principal = swag.String(auditPrincipal)
+	}
+
+	resp, err := client.Audit.GetAuditRecords(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	return formatAuditRecordsOutput(out, resp.Payload)
+}
+
+func formatAuditRecordsOutput(out io.Writer, records []*v1.AuditRecord) error {
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(records)
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Time", "Service", "Principal", "Action", "Resource", "Outcome", "Status", "Revision", "Request"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	table.SetAutoWrapText(false)
+	for _, record := range records {
+		resource := record.Resource
+		if record.Name != "" {
+			resource += "/" + record.Name
+		}
+		revision := ""
+		if record.BeforeRevision != 0 || record.AfterRevision != 0 {
+			revision = fmt.Sprintf("%d -> %d", record.BeforeRevision, record.AfterRevision)
+		}
+		table.Append([]string{
+			time.Unix(record.Time, 0).Local().Format(time.UnixDate),
+			record.Service,
+			record.Principal,
+			record.Action,
+			resource,
+			record.Outcome,
+			strconv.FormatInt(record.Status, 10),
+			revision,
+			record.RequestID,
+		})
+	}
+	table.Render()
+	return nil
+}
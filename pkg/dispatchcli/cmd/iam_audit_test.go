@@ -0,0 +1,57 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+)
+
+func TestParseAuditTime(t *testing.T) {
+	now := time.Date(2018, 6, 2, 12, 0, 0, 0, time.UTC)
+
+	since, err := parseAuditTime("2h", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-2*time.Hour), since)
+
+	since, err = parseAuditTime("2018-06-01T00:00:00Z", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC), since.UTC())
+
+	_, err = parseAuditTime("yesterday", now)
+	assert.Error(t, err)
+}
+
+func TestFormatAuditRecordsOutput(t *testing.T) {
+	var buf bytes.Buffer
+
+	records := []*v1.AuditRecord{
+		{
+			Time:           time.Now().Unix(),
+			Service:        "function-manager",
+			Principal:      "user/jane@example.com",
+			Resource:       "function",
+			Name:           "hello",
+			Action:         "update",
+			Outcome:        "succeeded",
+			Status:         200,
+			RequestID:      "req-1",
+			BeforeRevision: 3,
+			AfterRevision:  4,
+		},
+	}
+	assert.NoError(t, formatAuditRecordsOutput(&buf, records))
+	assert.Contains(t, buf.String(), "user/jane@example.com")
+	assert.Contains(t, buf.String(), "function/hello")
+	assert.Contains(t, buf.String(), "3 -> 4")
+	assert.Contains(t, buf.String(), "req-1")
+}
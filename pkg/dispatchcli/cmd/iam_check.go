@@ -0,0 +1,111 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/swag"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	access "github.com/vmware/dispatch/pkg/identity-manager/gen/client/access"
+)
+
+var (
+	iamCheckLong = i18n.T(`Check whether a request is allowed, and explain why. The rules of the policies and role bindings applying to the subject are listed, with the reason they allow the request or not.`)
+
+	iamCheckExample = i18n.T(`
+# Check whether you can update the billing-charge function
+dispatch iam check update function/billing-charge
+
+# Check whether a user of the billing group can get the APIs of the billing application
+dispatch iam check get api --subject user1@example.com --group billing --application billing
+`)
+
+	checkSubject     string
+	checkGroups      *[]string
+	checkApplication string
+)
+
+// NewCmdIamCheck creates command checking whether a request is allowed
+func NewCmdIamCheck(out, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     i18n.T("check ACTION RESOURCE[/NAME] [--subject SUBJECT] [--group GROUPS] [--application APPLICATION]"),
+		Short:   i18n.T("Check whether a request is allowed"),
+		Long:    iamCheckLong,
+		Example: iamCheckExample,
+		Args:    cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := iamCheck(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&checkSubject, "subject", "s", "", "subject of the request, you by default")
+	checkGroups = cmd.Flags().StringSliceP("group", "g", []string{}, "groups of the subject, separated by comma")
+	cmd.Flags().StringVarP(&checkApplication, "application", "a", "", "application of the request")
+	return cmd
+}
+
+func iamCheck(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	client := identityManagerClient()
+	params := &access.CheckAccessParams{
+		XDispatchOrg: dispatchConfig.Organization,
+		Action:       swag.String(args[0]),
+		Resource:     swag.String(args[1]),
+		Group:        *checkGroups,
+		Context:      context.Background(),
+	}
+	if checkSubject != "" {
+		params.Subject = swag.String(checkSubject)
+	}
+	if checkApplication != "" {
+		params.Application = swag.String(checkApplication)
+	}
+
+	resp, err := client.Access.CheckAccess(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	return formatAccessDecisionOutput(out, resp.Payload)
+}
+
+func formatAccessDecisionOutput(out io.Writer, decision *v1.AccessDecision) error {
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(decision)
+	}
+
+	verdict := "denied"
+	if swag.BoolValue(decision.Allowed) {
+		verdict = "allowed"
+	}
+	fmt.Fprintf(out, "%s %s on %s in organization %s: %s\n", decision.Subject, decision.Action, decision.Resource, decision.Organization, verdict)
+	fmt.Fprintf(out, "Reason: %s\n", decision.Reason)
+	if len(decision.Rules) == 0 {
+		return nil
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Source", "Role", "Subject", "Resource", "Action", "Application", "Allowed", "Reason"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	table.SetAutoWrapText(false)
+	for _, rule := range decision.Rules {
+		table.Append([]string{
+			rule.Source, rule.Role, rule.Subject, rule.Resource, rule.Action, rule.Application,
+			fmt.Sprintf("%t", rule.Allowed), rule.Reason,
+		})
+	}
+	table.Render()
+	return nil
+}
@@ -0,0 +1,45 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+)
+
+func TestFormatAccessDecisionOutput(t *testing.T) {
+	var buf bytes.Buffer
+
+	decision := &v1.AccessDecision{
+		Subject:      "user1@example.com",
+		Organization: "dispatch",
+		Resource:     "function/billing-charge",
+		Action:       "update",
+		Allowed:      swag.Bool(true),
+		Reason:       "allowed by rolebinding/billing-developers",
+		Rules: []*v1.AccessRule{
+			{
+				Source:      "rolebinding/billing-developers",
+				Role:        "billing-developer",
+				Subject:     "group:billing",
+				Resource:    "function/billing-*",
+				Action:      "update",
+				Application: "*",
+				Allowed:     true,
+				Reason:      "group:billing allows update on function/billing-*",
+			},
+		},
+	}
+	assert.NoError(t, formatAccessDecisionOutput(&buf, decision))
+	assert.Contains(t, buf.String(), "user1@example.com update on function/billing-charge in organization dispatch: allowed")
+	assert.Contains(t, buf.String(), "Reason: allowed by rolebinding/billing-developers")
+	assert.Contains(t, buf.String(), "billing-developer")
+}
@@ -29,6 +29,10 @@ func NewCmdIamCreate(out io.Writer, errOut io.Writer) *cobra.Command {
 		Run:     runHelp,
 	}
 	cmd.AddCommand(NewCmdIamCreatePolicy(out, errOut))
+	cmd.AddCommand(NewCmdIamCreateRole(out, errOut))
+	cmd.AddCommand(NewCmdIamCreateRoleBinding(out, errOut))
 	cmd.AddCommand(NewCmdIamCreateServiceAccount(out, errOut))
+	cmd.AddCommand(NewCmdIamCreateOrganization(out, errOut))
+	cmd.AddCommand(NewCmdIamCreateToken(out, errOut))
 	return cmd
 }
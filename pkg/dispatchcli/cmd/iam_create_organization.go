@@ -0,0 +1,87 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/organization"
+)
+
+var (
+	createOrganizationLong = i18n.T(`Create a dispatch organization. Organizations are managed from the default organization.`)
+
+	createOrganizationExample = i18n.T(`
+# Create an organization with two members
+dispatch iam create organization example_org --member user1@example.com --member user2@example.com
+`)
+
+	organizationMembers *[]string
+)
+
+// NewCmdIamCreateOrganization creates command responsible for dispatch organization creation
+func NewCmdIamCreateOrganization(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     i18n.T(`organization ORGANIZATION_NAME [--member MEMBERS]`),
+		Short:   i18n.T("Create organization"),
+		Long:    createOrganizationLong,
+		Example: createOrganizationExample,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := createOrganization(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+
+	organizationMembers = cmd.Flags().StringSliceP("member", "m", []string{}, "members of the organization, separated by comma")
+	return cmd
+}
+
+// CallCreateOrganization makes the api call to create an organization
+func CallCreateOrganization(o interface{}) error {
+	client := identityManagerClient()
+	organizationModel := o.(*v1.Organization)
+
+	params := &organization.AddOrganizationParams{
+		Body:    organizationModel,
+		Context: context.Background(),
+	}
+
+	created, err := client.Organization.AddOrganization(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	*organizationModel = *created.Payload
+	return nil
+}
+
+func createOrganization(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+
+	organizationName := args[0]
+	organizationModel := &v1.Organization{
+		Name:    &organizationName,
+		Members: *organizationMembers,
+	}
+
+	err := CallCreateOrganization(organizationModel)
+	if err != nil {
+		return err
+	}
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(organizationModel)
+	}
+	fmt.Fprintf(out, "Created organization: %s\n", *organizationModel.Name)
+	return nil
+}
@@ -0,0 +1,97 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+
+	role "github.com/vmware/dispatch/pkg/identity-manager/gen/client/role"
+)
+
+var (
+	createRoleLong = i18n.T(`Create a dispatch role, a named set of permissions granted to users, groups and service accounts with role bindings`)
+
+	createRoleExample = i18n.T(`
+# Create a role allowing to read and update the functions whose names start with billing-
+dispatch iam create role billing-developer --action get,update --resource "function/billing-*"
+
+# Create a role allowing to read all the functions and APIs
+dispatch iam create role reader --action get --resource function,api
+`)
+
+	roleActions   *[]string
+	roleResources *[]string
+)
+
+// NewCmdIamCreateRole creates command responsible for dispatch role creation
+func NewCmdIamCreateRole(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     i18n.T(`role ROLE_NAME --action ACTIONS --resource RESOURCES`),
+		Short:   i18n.T("Create role"),
+		Long:    createRoleLong,
+		Example: createRoleExample,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := createRole(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+
+	roleActions = cmd.Flags().StringSliceP("action", "a", []string{""}, "actions of role rule, separated by comma")
+	roleResources = cmd.Flags().StringSliceP("resource", "r", []string{""}, "resources or resource instances of role rule, e.g. function/billing-*, separated by comma")
+	return cmd
+}
+
+// CallCreateRole makes the api call to create a role
+func CallCreateRole(r interface{}) error {
+	client := identityManagerClient()
+	roleModel := r.(*v1.Role)
+
+	params := &role.AddRoleParams{
+		Body:    roleModel,
+		Context: context.Background(),
+	}
+
+	created, err := client.Role.AddRole(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	*roleModel = *created.Payload
+	return nil
+}
+
+func createRole(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+
+	roleName := args[0]
+	roleModel := &v1.Role{
+		Name: &roleName,
+		Rules: []*v1.RoleRule{
+			{
+				Actions:   *roleActions,
+				Resources: *roleResources,
+			},
+		},
+	}
+
+	err := CallCreateRole(roleModel)
+	if err != nil {
+		return err
+	}
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(roleModel)
+	}
+	fmt.Fprintf(out, "Created role: %s\n", *roleModel.Name)
+	return nil
+}
@@ -0,0 +1,96 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+
+	rolebinding "github.com/vmware/dispatch/pkg/identity-manager/gen/client/rolebinding"
+)
+
+var (
+	createRoleBindingLong = i18n.T(`Create a dispatch role binding, granting a role to users, groups and service accounts, on all the applications or some of them only`)
+
+	createRoleBindingExample = i18n.T(`
+# Grant the billing-developer role to a user and to the users of the billing group
+dispatch iam create rolebinding billing-developers --role billing-developer --subject user1@example.com,group:billing
+
+# Grant the reader role to a service account, on the resources of the billing application only
+dispatch iam create rolebinding billing-readers --role reader --subject billing-svc --application billing
+`)
+
+	roleBindingRole         string
+	roleBindingSubjects     *[]string
+	roleBindingApplications *[]string
+)
+
+// NewCmdIamCreateRoleBinding creates command responsible for dispatch role binding creation
+func NewCmdIamCreateRoleBinding(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     i18n.T(`rolebinding ROLE_BINDING_NAME --role ROLE --subject SUBJECTS [--application APPLICATIONS]`),
+		Short:   i18n.T("Create role binding"),
+		Long:    createRoleBindingLong,
+		Example: createRoleBindingExample,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := createRoleBinding(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVar(&roleBindingRole, "role", "", "role granted by the role binding")
+	roleBindingSubjects = cmd.Flags().StringSliceP("subject", "s", []string{}, "subjects of the role binding, separated by comma")
+	roleBindingApplications = cmd.Flags().StringSliceP("application", "a", []string{}, "applications the role is granted on, all by default, separated by comma")
+	return cmd
+}
+
+// CallCreateRoleBinding makes the api call to create a role binding
+func CallCreateRoleBinding(b interface{}) error {
+	client := identityManagerClient()
+	bindingModel := b.(*v1.RoleBinding)
+
+	params := &rolebinding.AddRoleBindingParams{
+		Body:    bindingModel,
+		Context: context.Background(),
+	}
+
+	created, err := client.Rolebinding.AddRoleBinding(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	*bindingModel = *created.Payload
+	return nil
+}
+
+func createRoleBinding(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+
+	bindingName := args[0]
+	bindingModel := &v1.RoleBinding{
+		Name:         &bindingName,
+		Role:         &roleBindingRole,
+		Subjects:     *roleBindingSubjects,
+		Applications: *roleBindingApplications,
+	}
+
+	err := CallCreateRoleBinding(bindingModel)
+	if err != nil {
+		return err
+	}
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(bindingModel)
+	}
+	fmt.Fprintf(out, "Created role binding: %s\n", *bindingModel.Name)
+	return nil
+}
@@ -23,7 +23,7 @@ var (
 	createServiceAccountLong = i18n.T(`Create a dispatch service account`)
 
 	createServiceAccountExample = i18n.T(`
-# Create a service account by specifying a public key (public key file path)
+# Create a service account by specifying a public key (public key file path), either RSA, ECDSA or Ed25519
 dispatch iam create serviceaccount test_service_account --public-key ./app_rsa.pub
 `)
 
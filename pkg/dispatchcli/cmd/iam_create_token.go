@@ -0,0 +1,102 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/apitoken"
+)
+
+var (
+	createAPITokenLong = i18n.T(`Create a personal API token, authenticating requests as you, e.g. in scripts.
+The token is only shown once, pass it to dispatch with --token or DISPATCH_TOKEN.
+A token can be restricted to some resources and actions, it never has more permissions than you.`)
+
+	createAPITokenExample = i18n.T(`
+# Create a token valid for 30 days
+dispatch iam create token --description "release script" --expires 720h
+
+# Create a token which can only read functions
+dispatch iam create token --resource function --action get
+`)
+
+	apiTokenDescription string
+	apiTokenExpires     time.Duration
+	apiTokenResources   []string
+	apiTokenActions     []string
+)
+
+// NewCmdIamCreateToken creates command responsible for personal API token creation
+func NewCmdIamCreateToken(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     i18n.T(`token [--description DESCRIPTION] [--expires DURATION] [--resource RESOURCE] [--action ACTION]`),
+		Short:   i18n.T("Create personal API token"),
+		Long:    createAPITokenLong,
+		Example: createAPITokenExample,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := createAPIToken(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&apiTokenDescription, "description", "d", "", "what the token is used for")
+	cmd.Flags().DurationVar(&apiTokenExpires, "expires", 0, "lifetime of the token, the token does not expire when not set")
+	cmd.Flags().StringSliceVarP(&apiTokenResources, "resource", "r", []string{}, "resources the token is restricted to, separated by comma")
+	cmd.Flags().StringSliceVarP(&apiTokenActions, "action", "a", []string{}, "actions the token is restricted to, separated by comma: get, create, update or delete")
+	return cmd
+}
+
+// CallCreateAPIToken makes the api call to create a personal API token
+func CallCreateAPIToken(t interface{}) error {
+	client := identityManagerClient()
+	apiTokenModel := t.(*v1.APIToken)
+
+	params := &apitoken.AddAPITokenParams{
+		Body:    apiTokenModel,
+		Context: context.Background(),
+	}
+
+	created, err := client.Apitoken.AddAPIToken(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	*apiTokenModel = *created.Payload
+	return nil
+}
+
+func createAPIToken(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	apiTokenModel := &v1.APIToken{
+		Description: apiTokenDescription,
+		Resources:   apiTokenResources,
+		Actions:     apiTokenActions,
+	}
+	if apiTokenExpires > 0 {
+		apiTokenModel.ExpiresTime = time.Now().Add(apiTokenExpires).Unix()
+	}
+
+	err := CallCreateAPIToken(apiTokenModel)
+	if err != nil {
+		return err
+	}
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(apiTokenModel)
+	}
+	fmt.Fprintf(out, "Created API token: %s\n", apiTokenModel.Name)
+	fmt.Fprintf(out, "Copy the token now, it is not shown again:\n%s\n", apiTokenModel.Token)
+	return nil
+}
@@ -29,6 +29,10 @@ func NewCmdIamDelete(out io.Writer, errOut io.Writer) *cobra.Command {
 		Run:     runHelp,
 	}
 	cmd.AddCommand(NewCmdIamDeletePolicy(out, errOut))
+	cmd.AddCommand(NewCmdIamDeleteRole(out, errOut))
+	cmd.AddCommand(NewCmdIamDeleteRoleBinding(out, errOut))
 	cmd.AddCommand(NewCmdIamDeleteServiceAccount(out, errOut))
+	cmd.AddCommand(NewCmdIamDeleteOrganization(out, errOut))
+	cmd.AddCommand(NewCmdIamDeleteToken(out, errOut))
 	return cmd
 }
@@ -0,0 +1,78 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/organization"
+)
+
+var (
+	deleteOrganizationLong = i18n.T(`Delete a dispatch organization, along with its policies and service accounts`)
+
+	// TODO: add examples
+	deleteOrganizationExample = i18n.T(``)
+)
+
+// NewCmdIamDeleteOrganization deletes organization
+func NewCmdIamDeleteOrganization(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     i18n.T("organization ORGANIZATION_NAME"),
+		Short:   i18n.T("Delete organization"),
+		Long:    deleteOrganizationLong,
+		Example: deleteOrganizationExample,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := deleteOrganization(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+// CallDeleteOrganization makes the API call to delete organization
+func CallDeleteOrganization(i interface{}) error {
+	client := identityManagerClient()
+	organizationModel := i.(*v1.Organization)
+
+	params := &organization.DeleteOrganizationParams{
+		OrganizationName: *organizationModel.Name,
+		Context:          context.Background(),
+	}
+
+	deleted, err := client.Organization.DeleteOrganization(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	*organizationModel = *deleted.Payload
+	return nil
+}
+
+func deleteOrganization(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	organizationModel := v1.Organization{
+		Name: &args[0],
+	}
+
+	err := CallDeleteOrganization(&organizationModel)
+	if err != nil {
+		return err
+	}
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(organizationModel)
+	}
+	fmt.Fprintf(out, "Deleted organization: %s\n", *organizationModel.Name)
+	return nil
+}
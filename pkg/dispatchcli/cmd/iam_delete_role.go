@@ -0,0 +1,78 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	"golang.org/x/net/context"
+
+	role "github.com/vmware/dispatch/pkg/identity-manager/gen/client/role"
+)
+
+var (
+	deleteRoleLong = i18n.T(`Delete a dispatch role`)
+
+	// TODO: add examples
+	deleteRoleExample = i18n.T(``)
+)
+
+// NewCmdIamDeleteRole deletes role
+func NewCmdIamDeleteRole(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     i18n.T("role ROLE_NAME"),
+		Short:   i18n.T("Delete role"),
+		Long:    deleteRoleLong,
+		Example: deleteRoleExample,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := deleteRole(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+// CallDeleteRole makes the API call to delete role
+func CallDeleteRole(i interface{}) error {
+	client := identityManagerClient()
+	roleModel := i.(*v1.Role)
+
+	params := &role.DeleteRoleParams{
+		RoleName: *roleModel.Name,
+		Context:  context.Background(),
+	}
+
+	deleted, err := client.Role.DeleteRole(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	*roleModel = *deleted.Payload
+	return nil
+}
+
+func deleteRole(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	roleModel := v1.Role{
+		Name: &args[0],
+	}
+
+	err := CallDeleteRole(&roleModel)
+	if err != nil {
+		return err
+	}
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(roleModel)
+	}
+	fmt.Fprintf(out, "Deleted role: %s\n", *roleModel.Name)
+	return nil
+}
@@ -0,0 +1,78 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	"golang.org/x/net/context"
+
+	rolebinding "github.com/vmware/dispatch/pkg/identity-manager/gen/client/rolebinding"
+)
+
+var (
+	deleteRoleBindingLong = i18n.T(`Delete a dispatch role binding`)
+
+	// TODO: add examples
+	deleteRoleBindingExample = i18n.T(``)
+)
+
+// NewCmdIamDeleteRoleBinding deletes role binding
+func NewCmdIamDeleteRoleBinding(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     i18n.T("rolebinding ROLE_BINDING_NAME"),
+		Short:   i18n.T("Delete role binding"),
+		Long:    deleteRoleBindingLong,
+		Example: deleteRoleBindingExample,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := deleteRoleBinding(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+// CallDeleteRoleBinding makes the API call to delete role binding
+func CallDeleteRoleBinding(i interface{}) error {
+	client := identityManagerClient()
+	bindingModel := i.(*v1.RoleBinding)
+
+	params := &rolebinding.DeleteRoleBindingParams{
+		RoleBindingName: *bindingModel.Name,
+		Context:         context.Background(),
+	}
+
+	deleted, err := client.Rolebinding.DeleteRoleBinding(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	*bindingModel = *deleted.Payload
+	return nil
+}
+
+func deleteRoleBinding(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	bindingModel := v1.RoleBinding{
+		Name: &args[0],
+	}
+
+	err := CallDeleteRoleBinding(&bindingModel)
+	if err != nil {
+		return err
+	}
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(bindingModel)
+	}
+	fmt.Fprintf(out, "Deleted role binding: %s\n", *bindingModel.Name)
+	return nil
+}
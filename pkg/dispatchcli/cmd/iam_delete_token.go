@@ -0,0 +1,78 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/apitoken"
+)
+
+var (
+	deleteAPITokenLong = i18n.T(`Delete a personal API token, the requests authenticated by it are rejected`)
+
+	// TODO: add examples
+	deleteAPITokenExample = i18n.T(``)
+)
+
+// NewCmdIamDeleteToken deletes personal API tokens
+func NewCmdIamDeleteToken(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     i18n.T("token TOKEN_NAME"),
+		Short:   i18n.T("Delete personal API token"),
+		Long:    deleteAPITokenLong,
+		Example: deleteAPITokenExample,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := deleteAPIToken(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+// CallDeleteAPIToken makes the API call to delete a personal API token
+func CallDeleteAPIToken(t interface{}) error {
+	client := identityManagerClient()
+	apiTokenModel := t.(*v1.APIToken)
+
+	params := &apitoken.DeleteAPITokenParams{
+		APITokenName: apiTokenModel.Name,
+		Context:      context.Background(),
+	}
+
+	deleted, err := client.Apitoken.DeleteAPIToken(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	*apiTokenModel = *deleted.Payload
+	return nil
+}
+
+func deleteAPIToken(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	apiTokenModel := v1.APIToken{
+		Name: args[0],
+	}
+
+	err := CallDeleteAPIToken(&apiTokenModel)
+	if err != nil {
+		return err
+	}
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(apiTokenModel)
+	}
+	fmt.Fprintf(out, "Deleted API token: %s\n", apiTokenModel.Name)
+	return nil
+}
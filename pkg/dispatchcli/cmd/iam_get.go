@@ -29,6 +29,10 @@ func NewCmdIamGet(out io.Writer, errOut io.Writer) *cobra.Command {
 		Run:     runHelp,
 	}
 	cmd.AddCommand(NewCmdIamGetPolicy(out, errOut))
+	cmd.AddCommand(NewCmdIamGetRole(out, errOut))
+	cmd.AddCommand(NewCmdIamGetRoleBinding(out, errOut))
 	cmd.AddCommand(NewCmdIamGetServiceAccount(out, errOut))
+	cmd.AddCommand(NewCmdIamGetOrganization(out, errOut))
+	cmd.AddCommand(NewCmdIamGetToken(out, errOut))
 	return cmd
 }
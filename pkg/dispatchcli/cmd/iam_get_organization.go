@@ -0,0 +1,101 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/organization"
+)
+
+var (
+	getOrganizationsLong = i18n.T(`Get organizations`)
+
+	// TODO: examples
+	getOrganizationsExample = i18n.T(``)
+)
+
+// NewCmdIamGetOrganization creates command for getting organizations
+func NewCmdIamGetOrganization(out, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     i18n.T("organization [ORGANIZATION_NAME]"),
+		Short:   i18n.T("Get organizations"),
+		Long:    getOrganizationsLong,
+		Example: getOrganizationsExample,
+		Args:    cobra.MaximumNArgs(1),
+		Aliases: []string{"organizations"},
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			if len(args) > 0 {
+				err = getIamOrganization(out, errOut, cmd, args)
+			} else {
+				err = getIamOrganizations(out, errOut, cmd)
+			}
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+func getIamOrganization(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+
+	client := identityManagerClient()
+	params := &organization.GetOrganizationParams{
+		OrganizationName: args[0],
+		Context:          context.Background(),
+	}
+
+	resp, err := client.Organization.GetOrganization(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	return formatOrganizationOutput(out, false, []*v1.Organization{resp.Payload})
+}
+
+func getIamOrganizations(out, errOut io.Writer, cmd *cobra.Command) error {
+
+	client := identityManagerClient()
+	params := &organization.GetOrganizationsParams{
+		Context: context.Background(),
+	}
+
+	resp, err := client.Organization.GetOrganizations(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	return formatOrganizationOutput(out, true, resp.Payload)
+}
+
+func formatOrganizationOutput(out io.Writer, list bool, organizations []*v1.Organization) error {
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		if list {
+			return encoder.Encode(organizations)
+		}
+		return encoder.Encode(organizations[0])
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Name", "Members", "Created Date"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	table.SetAutoWrapText(false)
+	for _, org := range organizations {
+		table.Append([]string{*org.Name, strings.Join(org.Members, ","), time.Unix(org.CreatedTime, 0).Local().Format(time.UnixDate)})
+	}
+	table.Render()
+	return nil
+}
@@ -0,0 +1,108 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	role "github.com/vmware/dispatch/pkg/identity-manager/gen/client/role"
+)
+
+var (
+	getRolesLong = i18n.T(`Get roles`)
+
+	// TODO: examples
+	getRolesExample = i18n.T(``)
+)
+
+// NewCmdIamGetRole creates command for getting roles
+func NewCmdIamGetRole(out, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     i18n.T("role [ROLE_NAME]"),
+		Short:   i18n.T("Get roles"),
+		Long:    getRolesLong,
+		Example: getRolesExample,
+		Args:    cobra.MaximumNArgs(1),
+		Aliases: []string{"roles"},
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			if len(args) > 0 {
+				err = getRole(out, errOut, cmd, args)
+			} else {
+				err = getRoles(out, errOut, cmd)
+			}
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+func getRole(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+
+	client := identityManagerClient()
+	params := &role.GetRoleParams{
+		RoleName: args[0],
+		Context:  context.Background(),
+	}
+
+	resp, err := client.Role.GetRole(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	return formatRoleOutput(out, false, []*v1.Role{resp.Payload})
+}
+
+func getRoles(out, errOut io.Writer, cmd *cobra.Command) error {
+
+	client := identityManagerClient()
+	params := &role.GetRolesParams{
+		Context: context.Background(),
+	}
+
+	resp, err := client.Role.GetRoles(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	return formatRoleOutput(out, true, resp.Payload)
+}
+
+func formatRoleOutput(out io.Writer, list bool, roles []*v1.Role) error {
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		if list {
+			return encoder.Encode(roles)
+		}
+		return encoder.Encode(roles[0])
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Name", "Resources", "Actions", "Created Date"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	table.SetAutoWrapText(false)
+	for _, role := range roles {
+		for i, rule := range role.Rules {
+			row := []string{"", strings.Join(rule.Resources, ","), strings.Join(rule.Actions, ","), ""}
+			if i == 0 {
+				row[0] = *role.Name
+				row[3] = time.Unix(role.CreatedTime, 0).Local().Format(time.UnixDate)
+			}
+			table.Append(row)
+		}
+	}
+	table.Render()
+	return nil
+}
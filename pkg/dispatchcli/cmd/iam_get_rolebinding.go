@@ -0,0 +1,111 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	rolebinding "github.com/vmware/dispatch/pkg/identity-manager/gen/client/rolebinding"
+)
+
+var (
+	getRoleBindingsLong = i18n.T(`Get role bindings`)
+
+	// TODO: examples
+	getRoleBindingsExample = i18n.T(``)
+)
+
+// NewCmdIamGetRoleBinding creates command for getting role bindings
+func NewCmdIamGetRoleBinding(out, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     i18n.T("rolebinding [ROLE_BINDING_NAME]"),
+		Short:   i18n.T("Get role bindings"),
+		Long:    getRoleBindingsLong,
+		Example: getRoleBindingsExample,
+		Args:    cobra.MaximumNArgs(1),
+		Aliases: []string{"rolebindings"},
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			if len(args) > 0 {
+				err = getRoleBinding(out, errOut, cmd, args)
+			} else {
+				err = getRoleBindings(out, errOut, cmd)
+			}
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+func getRoleBinding(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+
+	client := identityManagerClient()
+	params := &rolebinding.GetRoleBindingParams{
+		RoleBindingName: args[0],
+		Context:         context.Background(),
+	}
+
+	resp, err := client.Rolebinding.GetRoleBinding(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	return formatRoleBindingOutput(out, false, []*v1.RoleBinding{resp.Payload})
+}
+
+func getRoleBindings(out, errOut io.Writer, cmd *cobra.Command) error {
+
+	client := identityManagerClient()
+	params := &rolebinding.GetRoleBindingsParams{
+		Context: context.Background(),
+	}
+
+	resp, err := client.Rolebinding.GetRoleBindings(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	return formatRoleBindingOutput(out, true, resp.Payload)
+}
+
+func formatRoleBindingOutput(out io.Writer, list bool, bindings []*v1.RoleBinding) error {
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		if list {
+			return encoder.Encode(bindings)
+		}
+		return encoder.Encode(bindings[0])
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Name", "Role", "Subjects", "Applications", "Created Date"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	table.SetAutoWrapText(false)
+	for _, binding := range bindings {
+		applications := "*"
+		if len(binding.Applications) > 0 {
+			applications = strings.Join(binding.Applications, ",")
+		}
+		table.Append([]string{
+			*binding.Name,
+			*binding.Role,
+			strings.Join(binding.Subjects, ","),
+			applications,
+			time.Unix(binding.CreatedTime, 0).Local().Format(time.UnixDate),
+		})
+	}
+	table.Render()
+	return nil
+}
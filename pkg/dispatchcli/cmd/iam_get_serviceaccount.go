@@ -8,7 +8,9 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/olekukonko/tablewriter"
@@ -97,13 +99,17 @@ func formatServiceAccountOutput(out io.Writer, list bool, serviceAccounts []*v1.
 		return encoder.Encode(serviceAccounts[0])
 	}
 
-	headers := []string{"Name", "Created Date"}
+	headers := []string{"Name", "Keys", "Created Date"}
 	table := tablewriter.NewWriter(out)
 	table.SetHeader(headers)
 	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
 	table.SetCenterSeparator("")
 	for _, serviceAccount := range serviceAccounts {
-		row := []string{*serviceAccount.Name, time.Unix(serviceAccount.CreatedTime, 0).Local().Format(time.UnixDate)}
+		var keyIDs []string
+		for _, k := range serviceAccount.Keys {
+			keyIDs = append(keyIDs, fmt.Sprintf("%s (%s)", k.ID, k.Algorithm))
+		}
+		row := []string{*serviceAccount.Name, strings.Join(keyIDs, "\n"), time.Unix(serviceAccount.CreatedTime, 0).Local().Format(time.UnixDate)}
 		table.Append(row)
 	}
 	table.Render()
@@ -0,0 +1,114 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/apitoken"
+)
+
+var (
+	getAPITokensLong = i18n.T(`Get your personal API tokens`)
+
+	// TODO: examples
+	getAPITokensExample = i18n.T(``)
+)
+
+// NewCmdIamGetToken creates command for getting personal API tokens
+func NewCmdIamGetToken(out, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     i18n.T("token [TOKEN_NAME]"),
+		Short:   i18n.T("Get personal API tokens"),
+		Long:    getAPITokensLong,
+		Example: getAPITokensExample,
+		Args:    cobra.MaximumNArgs(1),
+		Aliases: []string{"tokens"},
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			if len(args) > 0 {
+				err = getAPIToken(out, errOut, cmd, args)
+			} else {
+				err = getAPITokens(out, errOut, cmd)
+			}
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+func getAPIToken(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+
+	client := identityManagerClient()
+	params := &apitoken.GetAPITokenParams{
+		APITokenName: args[0],
+		Context:      context.Background(),
+	}
+
+	resp, err := client.Apitoken.GetAPIToken(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	return formatAPITokenOutput(out, false, []*v1.APIToken{resp.Payload})
+}
+
+func getAPITokens(out, errOut io.Writer, cmd *cobra.Command) error {
+
+	client := identityManagerClient()
+	params := &apitoken.GetAPITokensParams{
+		Context: context.Background(),
+	}
+
+	resp, err := client.Apitoken.GetAPITokens(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	return formatAPITokenOutput(out, true, resp.Payload)
+}
+
+func formatAPITokenOutput(out io.Writer, list bool, tokens []*v1.APIToken) error {
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		if list {
+			return encoder.Encode(tokens)
+		}
+		return encoder.Encode(tokens[0])
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Name", "Description", "Resources", "Actions", "Expires", "Created Date"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	table.SetAutoWrapText(false)
+	for _, token := range tokens {
+		resources, actions, expires := "*", "*", "never"
+		if len(token.Resources) > 0 {
+			resources = strings.Join(token.Resources, ",")
+		}
+		if len(token.Actions) > 0 {
+			actions = strings.Join(token.Actions, ",")
+		}
+		if token.ExpiresTime != 0 {
+			expires = time.Unix(token.ExpiresTime, 0).Local().Format(time.UnixDate)
+		}
+		table.Append([]string{
+			token.Name, token.Description, resources, actions, expires,
+			time.Unix(token.CreatedTime, 0).Local().Format(time.UnixDate),
+		})
+	}
+	table.Render()
+	return nil
+}
@@ -0,0 +1,77 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/token"
+)
+
+var (
+	iamRevokeLong = i18n.T(`Revoke a token by its ID (jti claim), or all the tokens of a service account issued until now.`)
+
+	iamRevokeExample = i18n.T(`
+# Revoke an access token
+dispatch iam revoke 0b5a8bd6-6f43-4c1e-a1a4-7f4f2d7c9d1e
+
+# Revoke all the tokens of a service account, e.g. once its private key leaked
+dispatch iam revoke --all-tokens-of ci
+`)
+
+	iamRevokeServiceAccount string
+)
+
+// NewCmdIamRevoke creates command revoking tokens
+func NewCmdIamRevoke(out, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     i18n.T("revoke [TOKEN_ID] [--all-tokens-of SERVICE_ACCOUNT_NAME]"),
+		Short:   i18n.T("Revoke tokens"),
+		Long:    iamRevokeLong,
+		Example: iamRevokeExample,
+		Args:    cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := iamRevoke(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVar(&iamRevokeServiceAccount, "all-tokens-of", "", "revoke all the tokens of this service account")
+	return cmd
+}
+
+func iamRevoke(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	revocation := &v1.TokenRevocation{ServiceAccount: iamRevokeServiceAccount}
+	if len(args) > 0 {
+		revocation.ID = args[0]
+	}
+	if revocation.ID == "" && revocation.ServiceAccount == "" {
+		return formatCliError(errors.New("either a token ID or --all-tokens-of is required"), "invalid arguments")
+	}
+
+	client := identityManagerClient()
+	params := &token.RevokeTokenParams{
+		XDispatchOrg: dispatchConfig.Organization,
+		Body:         revocation,
+		Context:      context.Background(),
+	}
+	if _, err := client.Token.RevokeToken(params, GetAuthInfoWriter()); err != nil {
+		return formatAPIError(err, params)
+	}
+	if revocation.ID != "" {
+		fmt.Fprintf(out, "Revoked token: %s\n", revocation.ID)
+	}
+	if revocation.ServiceAccount != "" {
+		fmt.Fprintf(out, "Revoked tokens of service account: %s\n", revocation.ServiceAccount)
+	}
+	return nil
+}
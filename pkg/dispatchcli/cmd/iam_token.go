@@ -0,0 +1,94 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/token"
+)
+
+var (
+	iamTokenLong = i18n.T(`Get a short-lived access token for the service account configured, by exchanging a token signed with its private key.
+The access token is printed, and can be passed to dispatch with --token or DISPATCH_TOKEN.`)
+
+	iamTokenExample = i18n.T(`
+# Get an access token valid for 10 minutes
+dispatch iam token --service-account ci --jwt-private-key ./ci_ecdsa --lifetime 10m
+`)
+
+	iamTokenLifetime time.Duration
+)
+
+// NewCmdIamToken creates command getting access tokens for service accounts
+func NewCmdIamToken(out, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     i18n.T("token [--lifetime DURATION]"),
+		Short:   i18n.T("Get an access token for a service account"),
+		Long:    iamTokenLong,
+		Example: iamTokenExample,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := iamToken(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().DurationVar(&iamTokenLifetime, "lifetime", 0, "lifetime of the access token, bounded by the maximum lifetime of the identity manager")
+	return cmd
+}
+
+// serviceAccountCredentials returns the service account and the path of its private key, set either by flags or in
+// the configuration
+func serviceAccountCredentials() (string, string) {
+	serviceAccount := viperCtx.GetString("serviceAccount")
+	signKeyPath := viperCtx.GetString("jwtPrivateKey")
+	if serviceAccount != "" && signKeyPath != "" {
+		return serviceAccount, signKeyPath
+	}
+	return dispatchConfig.ServiceAccount, dispatchConfig.JWTPrivateKey
+}
+
+func iamToken(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	serviceAccount, signKeyPath := serviceAccountCredentials()
+	if serviceAccount == "" || signKeyPath == "" {
+		return formatCliError(errors.New("a service account and its private key are required"), "missing service account")
+	}
+	assertion, err := generateAndSignJWToken(serviceAccount, nil, &signKeyPath)
+	if err != nil {
+		return formatCliError(err, "error signing assertion")
+	}
+
+	client := identityManagerClient()
+	params := &token.IssueTokenParams{
+		Body: &v1.TokenRequest{
+			Assertion: swag.String(assertion),
+			Lifetime:  int64(iamTokenLifetime / time.Second),
+		},
+		Context: context.Background(),
+	}
+	resp, err := client.Token.IssueToken(params)
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(resp.Payload)
+	}
+	fmt.Fprintln(out, *resp.Payload.AccessToken)
+	return nil
+}
@@ -171,6 +171,11 @@ type oauth2ProxyConfig struct {
 	ClientSecret  string `json:"clientSecret,omitempty" validate:"required"`
 	CookieSecret  string `json:"cookieSecret,omitempty" validate:"omitempty"`
 }
+type oidcConfig struct {
+	IssuerURL   string `json:"issuerURL,omitempty" validate:"required,uri"`
+	ClientID    string `json:"clientID,omitempty" validate:"omitempty"`
+	GroupsClaim string `json:"groupsClaim,omitempty" validate:"omitempty"`
+}
 type imageRegistryConfig struct {
 	Name     string `json:"name,omitempty" validate:"required"`
 	Password string `json:"password,omitempty" validate:"omitempty"`
@@ -182,7 +187,7 @@ type k8sServiceCatalogConfig struct {
 	Namespace string `json:"namespace,omitempty" validate:"required"`
 }
 type serviceCatalogConfig struct {
-	Catalog           string                   `json:"catalog,omitemtpy" validate:"required,eq=k8sservicecatalog"`
+	Catalog           string                   `json:"catalog,omitemtpy" validate:"required,eq=k8sservicecatalog|eq=osb"`
 	K8sServiceCatalog *k8sServiceCatalogConfig `json:"k8sservicecatalog,omitempty"`
 }
 type dispatchInstallConfig struct {
@@ -197,6 +202,7 @@ type dispatchInstallConfig struct {
 	ImageRegistry   *imageRegistryConfig  `json:"imageRegistry,omitempty" validate:"omitempty"`
 	ImagePullSecret string                `json:"imagePullSecret,omitempty" validate:"omitempty"`
 	OAuth2Proxy     *oauth2ProxyConfig    `json:"oauth2Proxy,omitempty" validate:"required"`
+	OIDC            *oidcConfig           `json:"oidc,omitempty" validate:"omitempty"`
 	TLS             *tlsConfig            `json:"tls,omitempty" validate:"required"`
 	SkipAuth        bool                  `json:"skipAuth,omitempty" validate:"omitempty"`
 	Faas            string                `json:"faas,omitempty" validate:"required,eq=openfaas|eq=riff|eq=kubeless"`
@@ -1121,6 +1127,16 @@ func runInstall(out, errOut io.Writer, cmd *cobra.Command, args []string) error
 				dispatchOpts["global.image.tag"] = config.DispatchConfig.Image.Tag
 			}
 		}
+		// Native OpenID Connect login replaces the oauth2proxy sidecar
+		if config.DispatchConfig.OIDC != nil {
+			dispatchOpts["identity-manager.oidc.issuerURL"] = config.DispatchConfig.OIDC.IssuerURL
+			if config.DispatchConfig.OIDC.ClientID != "" {
+				dispatchOpts["identity-manager.oidc.clientID"] = config.DispatchConfig.OIDC.ClientID
+			}
+			if config.DispatchConfig.OIDC.GroupsClaim != "" {
+				dispatchOpts["identity-manager.oidc.groupsClaim"] = config.DispatchConfig.OIDC.GroupsClaim
+			}
+		}
 		if installDebug {
 			for k, v := range dispatchOpts {
 				fmt.Fprintf(out, "%v: %v\n", k, v)
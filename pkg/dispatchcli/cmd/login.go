@@ -23,11 +23,18 @@ import (
 )
 
 var (
-	loginLong = i18n.T(`Login to VMware Dispatch.`)
-
-	// TODO: Add examples
-	loginExample = i18n.T(``)
-	loginDebug   = false
+	loginLong = i18n.T(`Login to VMware Dispatch.
+
+With an OpenID Connect issuer, logs in with the authorization code flow and PKCE, the ID token is saved in the
+configuration. The issuer and client are saved too, and used by the next logins.`)
+
+	loginExample = i18n.T(`
+# Login with an OpenID Connect provider
+dispatch login --oidc-issuer https://accounts.example.com --oidc-client-id dispatch-cli
+`)
+	loginDebug        = false
+	loginOIDCIssuer   = ""
+	loginOIDCClientID = ""
 )
 
 // NewCmdLogin creates a command to login to VMware Dispatch.
@@ -44,6 +51,8 @@ func NewCmdLogin(in io.Reader, out, errOut io.Writer) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&loginDebug, "debug", false, "Extra debug output")
+	cmd.Flags().StringVar(&loginOIDCIssuer, "oidc-issuer", "", "OpenID Connect issuer to login with, instead of the oauth2 proxy of Dispatch")
+	cmd.Flags().StringVar(&loginOIDCClientID, "oidc-client-id", "dispatch-cli", "OpenID Connect client of Dispatch")
 
 	return cmd
 }
@@ -89,6 +98,13 @@ func login(in io.Reader, out, errOut io.Writer, cmd *cobra.Command, args []strin
 		return serviceAccountLogin(in, out, errOut, cmd, args)
 	}
 
+	if loginOIDCIssuer != "" {
+		dispatchConfig.OIDCIssuer = loginOIDCIssuer
+		dispatchConfig.OIDCClientID = loginOIDCClientID
+	}
+	if dispatchConfig.OIDCIssuer != "" {
+		return pkceLogin(in, out, errOut, cmd, args)
+	}
 	return oidcLogin(in, out, errOut, cmd, args)
 }
 
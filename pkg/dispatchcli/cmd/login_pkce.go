@@ -0,0 +1,133 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/toqueteos/webbrowser"
+
+	"github.com/vmware/dispatch/pkg/identity-manager/oidc"
+)
+
+const (
+	pkceCallbackPath = "/callback"
+	pkceLoginTimeout = 5 * time.Minute
+)
+
+// pkceCallback is the redirection to the local server ending the login at the provider
+type pkceCallback struct {
+	code  string
+	state string
+	err   string
+}
+
+// login Dispatch by the OIDC authorization code flow with PKCE, the CLI being a public client of the provider
+func pkceLogin(in io.Reader, out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), pkceLoginTimeout)
+	defer cancel()
+
+	idToken, err := loginWithPKCE(ctx, out, dispatchConfig.OIDCIssuer, dispatchConfig.OIDCClientID, webbrowser.Open)
+	if err != nil {
+		return err
+	}
+
+	dispatchConfig.IDToken = idToken
+	cmdConfig.Contexts[cmdConfig.Current] = &dispatchConfig
+	vsConfigJSON, err := json.MarshalIndent(cmdConfig, "", "    ")
+	if err != nil {
+		return errors.Wrap(err, "error marshalling json")
+	}
+
+	err = ioutil.WriteFile(viper.ConfigFileUsed(), vsConfigJSON, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "error writing configuration to file: %s", viper.ConfigFileUsed())
+	}
+	fmt.Fprintf(out, "You have successfully logged in, ID token saved to %s\n", viper.ConfigFileUsed())
+	return nil
+}
+
+// loginWithPKCE logs in at an OIDC provider, and returns the ID token of the user. The login page is opened with
+// openPage, and redirects to a local server with the authorization code.
+func loginWithPKCE(ctx context.Context, out io.Writer, issuer, clientID string, openPage func(string) error) (string, error) {
+	provider, err := oidc.NewProvider(ctx, issuer, clientID, nil)
+	if err != nil {
+		return "", err
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return "", err
+	}
+	state, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return "", err
+	}
+
+	// Loopback redirection, RFC 8252
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", errors.Wrap(err, "error starting the local server")
+	}
+	callbacks := make(chan pkceCallback, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(pkceCallbackPath, func(w http.ResponseWriter, req *http.Request) {
+		values := req.URL.Query()
+		callback := pkceCallback{code: values.Get("code"), state: values.Get("state"), err: values.Get("error")}
+		if callback.code == "" {
+			io.WriteString(w, "Login failed, please try again.\n")
+		} else {
+			io.WriteString(w, "Login succeeded. Please close this page.\n")
+		}
+		select {
+		case callbacks <- callback:
+		default:
+		}
+	})
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	redirectURI := fmt.Sprintf("http://%s%s", listener.Addr(), pkceCallbackPath)
+	loginURL := provider.AuthCodeURL(redirectURI, state, oidc.CodeChallenge(verifier), nil)
+	if loginDebug {
+		fmt.Fprintf(out, "Logging into: %s\n", loginURL)
+	}
+	if err := openPage(loginURL); err != nil {
+		return "", errors.Wrap(err, "error opening web browser")
+	}
+
+	var callback pkceCallback
+	select {
+	case callback = <-callbacks:
+	case <-ctx.Done():
+		return "", errors.New("timed out waiting for the login")
+	}
+	if callback.err != "" {
+		return "", errors.Errorf("login failed: %s", callback.err)
+	}
+	if callback.state != state {
+		return "", errors.New("login failed: state mismatch")
+	}
+
+	rawIDToken, idToken, err := provider.Exchange(ctx, redirectURI, callback.code, verifier)
+	if err != nil {
+		return "", err
+	}
+	if loginDebug {
+		fmt.Fprintf(out, "Logged in as %s\n", idToken.Email)
+	}
+	return rawIDToken, nil
+}
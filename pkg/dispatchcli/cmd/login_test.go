@@ -6,11 +6,16 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"fmt"
+	"net/http"
 	"os"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	fakeoidc "github.com/vmware/dispatch/pkg/testing/oidc"
 )
 
 func TestCmdLogin(t *testing.T) {
@@ -23,3 +28,28 @@ func TestCmdLogin(t *testing.T) {
 	assert.Nil(t, err)
 	assert.True(t, strings.Contains(buf.String(), "Login to VMware"))
 }
+
+func TestLoginWithPKCE(t *testing.T) {
+	fake := fakeoidc.NewFakeProvider("dispatch-cli", "user@example.com")
+	defer fake.Close()
+
+	var buf bytes.Buffer
+	// The fake provider logs in without prompting, and redirects to the local server
+	openPage := func(url string) error {
+		resp, err := http.Get(url)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("login page error: %d", resp.StatusCode)
+		}
+		return nil
+	}
+	idToken, err := loginWithPKCE(context.Background(), &buf, fake.Issuer(), "dispatch-cli", openPage)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, idToken)
+
+	_, err = loginWithPKCE(context.Background(), &buf, fake.Issuer(), "other-client", openPage)
+	assert.Error(t, err)
+}
@@ -44,6 +44,7 @@ func logout(in io.Reader, out, errOut io.Writer, cmd *cobra.Command, args []stri
 	dispatchConfig.Cookie = ""
 	dispatchConfig.ServiceAccount = ""
 	dispatchConfig.JWTPrivateKey = ""
+	dispatchConfig.IDToken = ""
 	cmdConfig.Contexts[cmdConfig.Current] = &dispatchConfig
 	vsConfigJSON, err := json.MarshalIndent(cmdConfig, "", "    ")
 	if err != nil {
@@ -28,5 +28,8 @@ func NewCmdManage(out, errOut io.Writer) *cobra.Command {
 
 	cmd.AddCommand(NewCmdManageBootstrap(out, errOut))
 	cmd.AddCommand(NewCmdManageContext(out, errOut))
+	cmd.AddCommand(NewCmdManageMigrate(out, errOut))
+	cmd.AddCommand(NewCmdManageBackup(out, errOut))
+	cmd.AddCommand(NewCmdManageRestore(out, errOut))
 	return cmd
 }
@@ -0,0 +1,597 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/application-manager/gen/client/application"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/organization"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/policy"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/role"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/rolebinding"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/serviceaccount"
+	"github.com/vmware/dispatch/pkg/secret-store/gen/client/secret"
+	"github.com/vmware/dispatch/pkg/utils"
+)
+
+var (
+	manageBackupLong = i18n.T(`Back up the state of Dispatch to an archive. The resources of every organization are
+exported: policies, service accounts, roles and role bindings, secrets, applications, base images, images, functions
+with their source, APIs, event driver types, event drivers and subscriptions. The archive is restored with
+"dispatch manage restore".
+
+The secrets are encrypted in the archive with --passphrase, they are stored in clear otherwise. The images are not
+exported from the registry, they are rebuilt when restored.`)
+
+	manageBackupExample = i18n.T(`
+# Back up all the organizations, with the secrets encrypted
+dispatch manage backup dispatch-backup.tar.gz --passphrase <PASSPHRASE>`)
+
+	backupPassphrase = ""
+)
+
+const (
+	// backupArchiveVersion is the version of the layout of the backup archives
+	backupArchiveVersion = 1
+	backupManifestFile   = "manifest.json"
+	// backupOrganizationsFile holds the organizations, the resources of each organization are in a directory named
+	// after it, a file per kind
+	backupOrganizationsFile = "organizations.json"
+	backupEncryptedSuffix   = ".enc"
+
+	// backupKeyIterations is the number of PBKDF2 iterations deriving the key of the encrypted resources
+	backupKeyIterations = 100000
+	backupSaltSize      = 16
+)
+
+// backupManifest describes a backup archive
+type backupManifest struct {
+	Version       int      `json:"version"`
+	CreatedTime   int64    `json:"createdTime"`
+	Host          string   `json:"host"`
+	Organizations []string `json:"organizations"`
+	Encrypted     bool     `json:"encrypted"`
+}
+
+// backupArchive is the content of a backup archive, the resources of each kind of each organization
+type backupArchive struct {
+	manifest      backupManifest
+	organizations []json.RawMessage
+	// resources are indexed by organization then kind
+	resources map[string]map[string][]json.RawMessage
+}
+
+// backupKind exports and restores the resources of a kind of the current organization
+type backupKind struct {
+	kind string
+	// list lists the resources of the kind
+	list func() (interface{}, error)
+	// newResource returns the model create is called with
+	newResource func() interface{}
+	create      ModelAction
+	// volatile are the fields Dispatch sets, they are neither restored nor compared
+	volatile []string
+}
+
+// backupVolatileFields are the fields Dispatch sets on the resources of every kind
+var backupVolatileFields = []string{"id", "kind", "createdTime", "modifiedTime", "status", "reason"}
+
+// backupSensitiveKinds are the kinds of the resources encrypted with the passphrase of the backup
+var backupSensitiveKinds = map[string]bool{utils.SecretKind: true}
+
+// backupKinds returns the kinds of resources backed up, in the order they are restored: the resources referenced by
+// other resources are restored first. The clients are bound to the current organization.
+func backupKinds() []backupKind {
+	fnClient := functionManagerClient()
+	imgClient := imageManagerClient()
+	eventClient := eventManagerClient()
+	apiClient := apiManagerClient()
+	organizationID := getOrganization()
+
+	return []backupKind{
+		{
+			kind: utils.PolicyKind,
+			list: func() (interface{}, error) {
+				params := &policy.GetPoliciesParams{Context: context.Background()}
+				resp, err := identityManagerClient().Policy.GetPolicies(params, GetAuthInfoWriter())
+				if err != nil {
+					return nil, formatAPIError(err, params)
+				}
+				return resp.Payload, nil
+			},
+			newResource: func() interface{} { return &v1.Policy{} },
+			create:      CallCreatePolicy,
+		},
+		{
+			kind: utils.ServiceAccountKind,
+			list: func() (interface{}, error) {
+				params := &serviceaccount.GetServiceAccountsParams{Context: context.Background()}
+				resp, err := identityManagerClient().Serviceaccount.GetServiceAccounts(params, GetAuthInfoWriter())
+				if err != nil {
+					return nil, formatAPIError(err, params)
+				}
+				return resp.Payload, nil
+			},
+			newResource: func() interface{} { return &v1.ServiceAccount{} },
+			create:      CallCreateServiceAccount,
+		},
+		{
+			kind: utils.RoleKind,
+			list: func() (interface{}, error) {
+				params := &role.GetRolesParams{Context: context.Background()}
+				resp, err := identityManagerClient().Role.GetRoles(params, GetAuthInfoWriter())
+				if err != nil {
+					return nil, formatAPIError(err, params)
+				}
+				return resp.Payload, nil
+			},
+			newResource: func() interface{} { return &v1.Role{} },
+			create:      CallCreateRole,
+		},
+		{
+			kind: utils.RoleBindingKind,
+			list: func() (interface{}, error) {
+				params := &rolebinding.GetRoleBindingsParams{Context: context.Background()}
+				resp, err := identityManagerClient().Rolebinding.GetRoleBindings(params, GetAuthInfoWriter())
+				if err != nil {
+					return nil, formatAPIError(err, params)
+				}
+				return resp.Payload, nil
+			},
+			newResource: func() interface{} { return &v1.RoleBinding{} },
+			create:      CallCreateRoleBinding,
+		},
+		{
+			kind: utils.SecretKind,
+			list: func() (interface{}, error) {
+				params := &secret.GetSecretsParams{Context: context.Background(), Tags: []string{}}
+				resp, err := secretStoreClient().Secret.GetSecrets(params, GetAuthInfoWriter())
+				if err != nil {
+					return nil, formatAPIError(err, params)
+				}
+				return resp.Payload, nil
+			},
+			newResource: func() interface{} { return &v1.Secret{} },
+			create:      CallCreateSecret,
+			volatile:    []string{"version"},
+		},
+		{
+			kind: utils.ApplicationKind,
+			list: func() (interface{}, error) {
+				params := &application.GetAppsParams{
+					Context: context.Background(),
+					Tags:    []string{},
+					Limit:   swag.Int64(getPageSize),
+				}
+				var applications []*v1.Application
+				for {
+					resp, err := applicationManagerClient().Application.GetApps(params, GetAuthInfoWriter())
+					if err != nil {
+						return nil, formatAPIError(err, params)
+					}
+					applications = append(applications, resp.Payload...)
+					if resp.XDispatchContinue == "" {
+						return applications, nil
+					}
+					params.Continue = swag.String(resp.XDispatchContinue)
+				}
+			},
+			newResource: func() interface{} { return &v1.Application{} },
+			create:      CallCreateApplication,
+		},
+		{
+			kind: utils.BaseImageKind,
+			list: func() (interface{}, error) {
+				return imgClient.ListBaseImages(context.TODO(), organizationID, "")
+			},
+			newResource: func() interface{} { return &v1.BaseImage{} },
+			create:      CallCreateBaseImage(imgClient),
+		},
+		{
+			kind: utils.ImageKind,
+			list: func() (interface{}, error) {
+				return imgClient.ListImages(context.TODO(), organizationID, "")
+			},
+			newResource: func() interface{} { return &v1.Image{} },
+			create:      CallCreateImage(imgClient),
+			volatile:    []string{"dockerUrl"},
+		},
+		{
+			kind: utils.FunctionKind,
+			list: func() (interface{}, error) {
+				return fnClient.ListFunctions(context.TODO(), organizationID, "")
+			},
+			newResource: func() interface{} { return &v1.Function{} },
+			create:      CallCreateFunction(fnClient),
+			volatile:    []string{"faasId", "functionImageURL"},
+		},
+		{
+			kind: utils.APIKind,
+			list: func() (interface{}, error) {
+				return apiClient.ListAPIs(context.TODO(), organizationID, "")
+			},
+			newResource: func() interface{} { return &v1.API{} },
+			create:      CallCreateAPI(apiClient),
+		},
+		{
+			kind: utils.DriverTypeKind,
+			list: func() (interface{}, error) {
+				return eventClient.ListEventDriverTypes(context.TODO(), organizationID, "")
+			},
+			newResource: func() interface{} { return &v1.EventDriverType{} },
+			create:      CallCreateEventDriverType(eventClient),
+		},
+		{
+			kind: utils.DriverKind,
+			list: func() (interface{}, error) {
+				return eventClient.ListEventDrivers(context.TODO(), organizationID, "")
+			},
+			newResource: func() interface{} { return &v1.EventDriver{} },
+			create:      CallCreateEventDriver(eventClient),
+		},
+		{
+			kind: utils.SubscriptionKind,
+			list: func() (interface{}, error) {
+				return eventClient.ListSubscriptions(context.TODO(), organizationID, "")
+			},
+			newResource: func() interface{} { return &v1.Subscription{} },
+			create:      CallCreateSubscription(eventClient),
+		},
+	}
+}
+
+// NewCmdManageBackup backs up the resources of all the organizations
+func NewCmdManageBackup(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "backup ARCHIVE [--passphrase PASSPHRASE]",
+		Short:   i18n.T("Back up the resources of all the organizations"),
+		Long:    manageBackupLong,
+		Example: manageBackupExample,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := manageBackup(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVar(&backupPassphrase, "passphrase", "", "passphrase the secrets are encrypted with")
+	return cmd
+}
+
+// withOrganization runs f with the clients bound to an organization
+func withOrganization(organizationID string, f func() error) error {
+	current := dispatchConfig.Organization
+	defer func() { dispatchConfig.Organization = current }()
+	dispatchConfig.Organization = organizationID
+	return f()
+}
+
+func listOrganizations() ([]*v1.Organization, error) {
+	params := &organization.GetOrganizationsParams{Context: context.Background()}
+	resp, err := identityManagerClient().Organization.GetOrganizations(params, GetAuthInfoWriter())
+	if err != nil {
+		return nil, formatAPIError(err, params)
+	}
+	return resp.Payload, nil
+}
+
+// toRawMessages converts a list of models to their JSON documents
+func toRawMessages(list interface{}) ([]json.RawMessage, error) {
+	b, err := json.Marshal(list)
+	if err != nil {
+		return nil, err
+	}
+	var messages []json.RawMessage
+	err = json.Unmarshal(b, &messages)
+	return messages, err
+}
+
+func manageBackup(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	organizations, err := listOrganizations()
+	if err != nil {
+		return err
+	}
+
+	archive := &backupArchive{
+		manifest: backupManifest{
+			Version:     backupArchiveVersion,
+			CreatedTime: time.Now().Unix(),
+			Host:        dispatchConfig.Host,
+			Encrypted:   backupPassphrase != "",
+		},
+		resources: map[string]map[string][]json.RawMessage{},
+	}
+	if archive.organizations, err = toRawMessages(organizations); err != nil {
+		return errors.Wrap(err, "error encoding the organizations")
+	}
+
+	count := 0
+	for _, o := range organizations {
+		organizationID := *o.Name
+		archive.manifest.Organizations = append(archive.manifest.Organizations, organizationID)
+		archive.resources[organizationID] = map[string][]json.RawMessage{}
+		err := withOrganization(organizationID, func() error {
+			for _, k := range backupKinds() {
+				list, err := k.list()
+				if err != nil {
+					return errors.Wrapf(err, "error listing the %s resources of organization %s", k.kind, organizationID)
+				}
+				resources, err := toRawMessages(list)
+				if err != nil {
+					return errors.Wrapf(err, "error encoding the %s resources of organization %s", k.kind, organizationID)
+				}
+				if k.kind == utils.SecretKind {
+					warnRedactedSecrets(errOut, organizationID, resources)
+				}
+				archive.resources[organizationID][k.kind] = resources
+				count += len(resources)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Create(args[0])
+	if err != nil {
+		return errors.Wrapf(err, "error creating the archive %s", args[0])
+	}
+	defer f.Close()
+	if err := writeBackupArchive(f, archive, backupPassphrase); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrapf(err, "error writing the archive %s", args[0])
+	}
+
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(archive.manifest)
+	}
+	if !archive.manifest.Encrypted {
+		fmt.Fprintln(errOut, "Warning: the secrets are not encrypted, use --passphrase to encrypt them")
+	}
+	fmt.Fprintf(out, "Backed up %d resources of %d organizations to %s\n", count, len(organizations), args[0])
+	return nil
+}
+
+// warnRedactedSecrets warns of the secrets the values of which the user is not allowed to read, they are backed up
+// without their values
+func warnRedactedSecrets(errOut io.Writer, organizationID string, secrets []json.RawMessage) {
+	for _, s := range secrets {
+		var values struct {
+			Name    string          `json:"name"`
+			Secrets json.RawMessage `json:"secrets"`
+		}
+		if json.Unmarshal(s, &values) == nil && len(values.Secrets) == 0 {
+			fmt.Fprintf(errOut, "Warning: secret %s of organization %s is backed up without its values, access is denied\n", values.Name, organizationID)
+		}
+	}
+}
+
+// writeBackupArchive writes a backup archive as a gzipped tarball. The resources of the sensitive kinds are encrypted
+// with the passphrase, if any.
+func writeBackupArchive(w io.Writer, archive *backupArchive, passphrase string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	add := func(name string, v interface{}) error {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return errors.Wrapf(err, "error encoding %s", name)
+		}
+		return addTarFile(tw, name, b)
+	}
+
+	if err := add(backupManifestFile, archive.manifest); err != nil {
+		return err
+	}
+	if err := add(backupOrganizationsFile, archive.organizations); err != nil {
+		return err
+	}
+	for _, organizationID := range archive.manifest.Organizations {
+		kinds := archive.resources[organizationID]
+		names := make([]string, 0, len(kinds))
+		for kind := range kinds {
+			names = append(names, kind)
+		}
+		sort.Strings(names)
+		for _, kind := range names {
+			name := path.Join(organizationID, kind+".json")
+			if !backupSensitiveKinds[kind] || passphrase == "" {
+				if err := add(name, kinds[kind]); err != nil {
+					return err
+				}
+				continue
+			}
+			b, err := json.Marshal(kinds[kind])
+			if err != nil {
+				return errors.Wrapf(err, "error encoding %s", name)
+			}
+			encrypted, err := encryptBackup(passphrase, b)
+			if err != nil {
+				return errors.Wrapf(err, "error encrypting %s", name)
+			}
+			if err := addTarFile(tw, name+backupEncryptedSuffix, encrypted); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return errors.Wrap(err, "error writing the archive")
+	}
+	return errors.Wrap(gz.Close(), "error writing the archive")
+}
+
+func addTarFile(tw *tar.Writer, name string, b []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(b)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return errors.Wrapf(err, "error writing %s to the archive", name)
+	}
+	_, err := tw.Write(b)
+	return errors.Wrapf(err, "error writing %s to the archive", name)
+}
+
+// readBackupArchive reads a backup archive, the encrypted resources are decrypted with the passphrase
+func readBackupArchive(r io.Reader, passphrase string) (*backupArchive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading the archive")
+	}
+	defer gz.Close()
+
+	archive := &backupArchive{resources: map[string]map[string][]json.RawMessage{}}
+	manifest := false
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading the archive")
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading %s from the archive", header.Name)
+		}
+
+		switch name := header.Name; {
+		case name == backupManifestFile:
+			manifest = true
+			err = json.Unmarshal(b, &archive.manifest)
+		case name == backupOrganizationsFile:
+			err = json.Unmarshal(b, &archive.organizations)
+		default:
+			organizationID, file := path.Split(name)
+			organizationID = strings.TrimSuffix(organizationID, "/")
+			if strings.HasSuffix(file, backupEncryptedSuffix) {
+				if passphrase == "" {
+					return nil, errors.Errorf("%s is encrypted, the passphrase of the backup is required", name)
+				}
+				if b, err = decryptBackup(passphrase, b); err != nil {
+					return nil, errors.Wrapf(err, "error decrypting %s", name)
+				}
+				file = strings.TrimSuffix(file, backupEncryptedSuffix)
+			}
+			var resources []json.RawMessage
+			err = json.Unmarshal(b, &resources)
+			if archive.resources[organizationID] == nil {
+				archive.resources[organizationID] = map[string][]json.RawMessage{}
+			}
+			archive.resources[organizationID][strings.TrimSuffix(file, ".json")] = resources
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "error decoding %s", header.Name)
+		}
+	}
+
+	if !manifest {
+		return nil, errors.New("not a backup archive: the manifest is missing")
+	}
+	if archive.manifest.Version > backupArchiveVersion {
+		return nil, errors.Errorf("the backup archive version %d is not supported, the latest is %d", archive.manifest.Version, backupArchiveVersion)
+	}
+	return archive, nil
+}
+
+// backupKey derives the key of the encrypted resources from a passphrase, with PBKDF2-HMAC-SHA256
+func backupKey(passphrase string, salt []byte) []byte {
+	prf := hmac.New(sha256.New, []byte(passphrase))
+	// the key is a single block of output
+	block := make([]byte, 4)
+	binary.BigEndian.PutUint32(block, 1)
+	prf.Write(salt)
+	prf.Write(block)
+	u := prf.Sum(nil)
+	key := append([]byte{}, u...)
+	for i := 1; i < backupKeyIterations; i++ {
+		prf.Reset()
+		prf.Write(u)
+		u = prf.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
+
+func backupCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(backupKey(passphrase, salt))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptBackup encrypts data with AES-GCM, the salt of the key and the nonce are prepended to the cipher text
+func encryptBackup(passphrase string, data []byte) ([]byte, error) {
+	salt := make([]byte, backupSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := backupCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	encrypted := append(salt, nonce...)
+	return aead.Seal(encrypted, nonce, data, nil), nil
+}
+
+// decryptBackup decrypts data encrypted by encryptBackup
+func decryptBackup(passphrase string, data []byte) ([]byte, error) {
+	if len(data) < backupSaltSize {
+		return nil, errors.New("the encrypted data is truncated")
+	}
+	aead, err := backupCipher(passphrase, data[:backupSaltSize])
+	if err != nil {
+		return nil, err
+	}
+	data = data[backupSaltSize:]
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("the encrypted data is truncated")
+	}
+	decrypted, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("the passphrase is wrong or the encrypted data is corrupted")
+	}
+	return decrypted, nil
+}
@@ -0,0 +1,84 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/utils"
+)
+
+func testBackupArchive() *backupArchive {
+	return &backupArchive{
+		manifest: backupManifest{
+			Version:       backupArchiveVersion,
+			Organizations: []string{"dispatch"},
+			Encrypted:     true,
+		},
+		organizations: []json.RawMessage{json.RawMessage(`{"name":"dispatch"}`)},
+		resources: map[string]map[string][]json.RawMessage{
+			"dispatch": {
+				utils.FunctionKind: {json.RawMessage(`{"name":"hello","image":"nodejs","source":"aGVsbG8="}`)},
+				utils.SecretKind:   {json.RawMessage(`{"name":"password","secrets":{"password":"s3cr3t"}}`)},
+			},
+		},
+	}
+}
+
+func TestBackupArchive(t *testing.T) {
+	archive := testBackupArchive()
+
+	var buf bytes.Buffer
+	require.NoError(t, writeBackupArchive(&buf, archive, "passphrase"))
+	assert.NotContains(t, buf.String(), "s3cr3t")
+
+	read, err := readBackupArchive(bytes.NewReader(buf.Bytes()), "passphrase")
+	require.NoError(t, err)
+	assert.Equal(t, archive.manifest, read.manifest)
+	assert.Equal(t, archive.organizations, read.organizations)
+	assert.Equal(t, archive.resources, read.resources)
+
+	_, err = readBackupArchive(bytes.NewReader(buf.Bytes()), "")
+	assert.Error(t, err)
+	_, err = readBackupArchive(bytes.NewReader(buf.Bytes()), "wrong")
+	assert.Error(t, err)
+}
+
+func TestBackupArchiveNotEncrypted(t *testing.T) {
+	archive := testBackupArchive()
+	archive.manifest.Encrypted = false
+
+	var buf bytes.Buffer
+	require.NoError(t, writeBackupArchive(&buf, archive, ""))
+
+	read, err := readBackupArchive(bytes.NewReader(buf.Bytes()), "")
+	require.NoError(t, err)
+	assert.Equal(t, archive.resources, read.resources)
+
+	_, err = readBackupArchive(bytes.NewReader([]byte("not an archive")), "")
+	assert.Error(t, err)
+}
+
+func TestCompareResource(t *testing.T) {
+	backedUp, err := restorable(json.RawMessage(`{"id":"1","name":"hello","image":"nodejs","faasId":"2","status":"READY"}`), []string{"faasId"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "hello", "image": "nodejs"}, backedUp)
+	assert.Equal(t, "hello", restorableName(backedUp))
+
+	same, err := restorable(json.RawMessage(`{"id":"3","name":"hello","image":"nodejs","faasId":"4","status":"ERROR"}`), []string{"faasId"})
+	require.NoError(t, err)
+	changed, err := restorable(json.RawMessage(`{"name":"hello","image":"python3"}`), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, restoreCreate, compareResource(backedUp, nil))
+	assert.Equal(t, restoreUnchanged, compareResource(backedUp, same))
+	assert.Equal(t, restoreDiffers, compareResource(backedUp, changed))
+}
@@ -41,6 +41,7 @@ import (
 	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/organization"
 	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/policy"
 	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/serviceaccount"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/setup"
 )
 
 var (
@@ -52,11 +53,16 @@ var (
 	bootstrapUser       = ""
 	bootstrapOrg        = ""
 	bootstrapTimeout    time.Duration
+	bootstrapSetupToken = ""
 
 	kubeconfigPath = ""
 
 	bootstrapExample = i18n.T(`
-# Bootstrap Dispatch - creates a default service account, organization and policies
+# Complete the setup of Dispatch with the setup token logged by the identity manager on first start - creates a default
+# service account, organization and policies
+dispatch manage bootstrap --setup-token <SETUP_TOKEN>
+
+# Bootstrap Dispatch in bootstrap mode (deprecated)
 dispatch manage bootstrap
 
 # Bootstrap Dispatch by specifying a specific bootstrap user and organization name
@@ -93,6 +99,7 @@ func NewCmdManageBootstrap(out io.Writer, errOut io.Writer) *cobra.Command {
 	cmd.Flags().StringVar(&bootstrapOrg, "bootstrap-org", defaultOrgName, "specify bootstrap org")
 	cmd.Flags().DurationVar(&bootstrapTimeout, "timeout", 2*time.Minute, "specify timeout for checking bootstrap status")
 	cmd.Flags().BoolVarP(&disableBootstrapModeFlag, "disable", "d", false, "disable bootstrap mode")
+	cmd.Flags().StringVar(&bootstrapSetupToken, "setup-token", "", "the single-use setup token logged by the identity manager on first start")
 	cmd.Flags().StringVar(&kubeconfigPath, "kubeconfig", "", "customized absolute path to k8s config file (optional)")
 	return cmd
 }
@@ -237,16 +244,55 @@ func waitForBootstrapStatus(out io.Writer, key *rsa.PrivateKey, enable bool) err
 	return nil
 }
 
+// newSvcAccountKey generates the key pair of the bootstrap service account, and returns the encoded public key
+func newSvcAccountKey() (*rsa.PrivateKey, string, error) {
+	svcKey, err := rsa.GenerateKey(rand.Reader, 4096)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to generate RSA key pair")
+	}
+	svcPubKeyPEM, err := pemEncodePubKey(&svcKey.PublicKey, "")
+	if err != nil {
+		return nil, "", err
+	}
+	return svcKey, base64.StdEncoding.EncodeToString(svcPubKeyPEM), nil
+}
+
+// useSvcAccountKey writes the private key of the bootstrap service account, and sets up the CLI to use it
+func useSvcAccountKey(out io.Writer, svcKey *rsa.PrivateKey) error {
+	configFilePath := viper.ConfigFileUsed()
+	pvtKeyFilePath := path.Join(filepath.Dir(configFilePath), fmt.Sprintf("%s", bootstrapSvcAccount))
+	fmt.Fprintf(out, "Writing pvt key for service account %s to file %s\n", bootstrapSvcAccount, pvtKeyFilePath)
+	if _, err := pemEncodePvtKey(svcKey, pvtKeyFilePath); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Setting up CLI current context to use service account %s\n", bootstrapSvcAccount)
+	dispatchConfig.ServiceAccount = bootstrapSvcAccount
+	dispatchConfig.JWTPrivateKey = pvtKeyFilePath
+	return nil
+}
+
+// writeBootstrapConfig writes the current context to the configuration file
+func writeBootstrapConfig() error {
+	cmdConfig.Contexts[cmdConfig.Current] = &dispatchConfig
+	vsConfigJSON, err := json.MarshalIndent(cmdConfig, "", "    ")
+	if err != nil {
+		return errors.Wrap(err, "error marshalling json")
+	}
+
+	err = ioutil.WriteFile(viper.ConfigFileUsed(), vsConfigJSON, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "error writing configuration to file: %s", viper.ConfigFileUsed())
+	}
+	return nil
+}
+
 func createSvcAccount(out, errOut io.Writer) error {
 	iamClient := identityManagerClient()
 
-	// Create RSA Key Pair
-	svcKey, err := rsa.GenerateKey(rand.Reader, 4096)
+	svcKey, svcPubKeyBase64, err := newSvcAccountKey()
 	if err != nil {
-		return errors.Wrap(err, "failed to generate RSA key pair")
+		return err
 	}
-	svcPubKeyPEM, err := pemEncodePubKey(&svcKey.PublicKey, "")
-	svcPubKeyBase64 := base64.StdEncoding.EncodeToString(svcPubKeyPEM)
 	serviceAccountModel := &v1.ServiceAccount{
 		Name:      &bootstrapSvcAccount,
 		PublicKey: &svcPubKeyBase64,
@@ -273,24 +319,59 @@ func createSvcAccount(out, errOut io.Writer) error {
 	}
 
 	// Write the private key to config dir
-	configFilePath := viper.ConfigFileUsed()
-	pvtKeyFilePath := path.Join(filepath.Dir(configFilePath), fmt.Sprintf("%s", bootstrapSvcAccount))
-	fmt.Fprintf(out, "Writing pvt key for service account %s to file %s\n", bootstrapSvcAccount, pvtKeyFilePath)
-	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("error creating pvt key file for service account at %s", pvtKeyFilePath))
+	return useSvcAccountKey(out, svcKey)
+}
+
+// runSetup completes the first-run setup with the setup token, without bootstrap mode. The policy is granted to the
+// bootstrap user, or to a new service account.
+func runSetup(out, errOut io.Writer) error {
+	setupModel := &v1.Setup{
+		SetupToken:   &bootstrapSetupToken,
+		Organization: bootstrapOrg,
+		User:         bootstrapUser,
 	}
-	_, err = pemEncodePvtKey(svcKey, pvtKeyFilePath)
-	if err != nil {
-		return err
+	var svcKey *rsa.PrivateKey
+	if bootstrapUser == "" {
+		key, pubKey, err := newSvcAccountKey()
+		if err != nil {
+			return err
+		}
+		svcKey = key
+		setupModel.ServiceAccount = bootstrapSvcAccount
+		setupModel.PublicKey = pubKey
 	}
-	fmt.Fprintf(out, "Setting up CLI current context to use service account %s\n", bootstrapSvcAccount)
-	dispatchConfig.ServiceAccount = bootstrapSvcAccount
-	dispatchConfig.JWTPrivateKey = pvtKeyFilePath
-	return nil
+
+	params := &setup.CompleteSetupParams{
+		Body:    setupModel,
+		Context: context.Background(),
+	}
+	fmt.Fprintln(out, "Completing setup")
+	if _, err := identityManagerClient().Setup.CompleteSetup(params); err != nil {
+		return formatAPIError(err, params)
+	}
+	fmt.Fprintf(out, "Created Policy %s for %s\n", defaultPolicyName, setupSubject())
+	if svcKey != nil {
+		if err := useSvcAccountKey(out, svcKey); err != nil {
+			return err
+		}
+	}
+	return writeBootstrapConfig()
+}
+
+// setupSubject returns the subject the policy is granted to
+func setupSubject() string {
+	if bootstrapUser == "" {
+		return bootstrapSvcAccount
+	}
+	return bootstrapUser
 }
 
 func runBootstrap(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
 
+	if bootstrapSetupToken != "" {
+		return runSetup(out, errOut)
+	}
+
 	namespace = cmdConfig.Contexts[cmdConfig.Current].Namespace
 
 	// get k8s client
@@ -302,6 +383,7 @@ func runBootstrap(out, errOut io.Writer, cmd *cobra.Command, args []string) erro
 	if disableBootstrapModeFlag {
 		return disableBootstrapMode(out, client)
 	}
+	fmt.Fprintln(errOut, "warning: bootstrap mode is deprecated, complete the setup with --setup-token instead")
 
 	// Create RSA Key Pair
 	key, err := rsa.GenerateKey(rand.Reader, 4096)
@@ -398,15 +480,8 @@ func runBootstrap(out, errOut io.Writer, cmd *cobra.Command, args []string) erro
 	}
 
 	// write dispatchConfig to file
-	cmdConfig.Contexts[cmdConfig.Current] = &dispatchConfig
-	vsConfigJSON, err := json.MarshalIndent(cmdConfig, "", "    ")
-	if err != nil {
-		return errors.Wrap(err, "error marshalling json")
-	}
-
-	err = ioutil.WriteFile(viper.ConfigFileUsed(), vsConfigJSON, 0644)
-	if err != nil {
-		return errors.Wrapf(err, "error writing configuration to file: %s", viper.ConfigFileUsed())
+	if err := writeBootstrapConfig(); err != nil {
+		return err
 	}
 
 	// Disable bootstrap mode
@@ -0,0 +1,91 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+)
+
+var (
+	manageMigrateLong = i18n.T(`Migrate the schema of the database of the entity store. The services migrate the schema on
+start, migrating beforehand keeps the upgrades of the services short.`)
+
+	manageMigrateExample = i18n.T(`
+# List the migrations pending for the database
+dispatch manage migrate --db-file postgres.example.com:5432 --dry-run
+
+# Apply the pending migrations
+dispatch manage migrate --db-file postgres.example.com:5432 --db-username dispatch --db-password <PASSWORD>`)
+
+	migrateDryRun   = false
+	migrateDbConfig = entitystore.BackendConfig{}
+)
+
+// NewCmdManageMigrate handles the schema migrations of the entity store
+func NewCmdManageMigrate(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "migrate [--dry-run]",
+		Short:   i18n.T("Migrate the entity store schema"),
+		Long:    manageMigrateLong,
+		Example: manageMigrateExample,
+		Args:    cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := manageMigrate(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+
+	cmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "list the pending migrations without applying them")
+	cmd.Flags().StringVar(&migrateDbConfig.Backend, "db-backend", "postgres", "backend DB name")
+	cmd.Flags().StringVar(&migrateDbConfig.Address, "db-file", "localhost:5432", "backend DB URL/path")
+	cmd.Flags().StringVar(&migrateDbConfig.Username, "db-username", "dispatch", "backend DB username")
+	cmd.Flags().StringVar(&migrateDbConfig.Password, "db-password", "dispatch", "backend DB password")
+	cmd.Flags().StringVar(&migrateDbConfig.Bucket, "db-database", "dispatch", "backend DB database")
+	return cmd
+}
+
+func manageMigrate(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	migrations, err := entitystore.Migrate(migrateDbConfig, migrateDryRun)
+	if err != nil {
+		return err
+	}
+	return formatMigrationOutput(out, migrations)
+}
+
+func formatMigrationOutput(out io.Writer, migrations []entitystore.Migration) error {
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(migrations)
+	}
+	if len(migrations) == 0 {
+		fmt.Fprintln(out, "The schema is up to date")
+		return nil
+	}
+
+	state := "applied"
+	if migrateDryRun {
+		state = "pending"
+	}
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Version", "Description", "State"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	for _, m := range migrations {
+		table.Append([]string{strconv.Itoa(m.Version), m.Description, state})
+	}
+	table.Render()
+	return nil
+}
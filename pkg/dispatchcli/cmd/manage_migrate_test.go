@@ -0,0 +1,25 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCmdManageMigrate(t *testing.T) {
+	var buf bytes.Buffer
+
+	cli := NewCLI(os.Stdin, &buf, &buf)
+	cli.SetOutput(&buf)
+	cli.SetArgs([]string{"manage", "migrate", "--db-backend", "boltdb", "--dry-run"})
+	err := cli.Execute()
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), "The schema is up to date")
+}
@@ -0,0 +1,227 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	"github.com/vmware/dispatch/pkg/utils"
+)
+
+var (
+	manageRestoreLong = i18n.T(`Restore a backup made with "dispatch manage backup". The organizations and the resources
+missing from Dispatch are created, the resources which exist are left as they are. With --dry-run, the resources of
+the backup are compared with the resources of Dispatch and nothing is created.`)
+
+	manageRestoreExample = i18n.T(`
+# Compare a backup with the resources of Dispatch
+dispatch manage restore dispatch-backup.tar.gz --passphrase <PASSPHRASE> --dry-run
+
+# Restore a backup
+dispatch manage restore dispatch-backup.tar.gz --passphrase <PASSPHRASE>`)
+
+	restoreDryRun     = false
+	restorePassphrase = ""
+)
+
+// The actions of the resources of a backup being restored
+const (
+	restoreCreate    = "create"
+	restoreCreated   = "created"
+	restoreUnchanged = "unchanged"
+	restoreDiffers   = "differs"
+)
+
+// restoreResult is the action taken for a resource of a backup
+type restoreResult struct {
+	Kind         string `json:"kind"`
+	Organization string `json:"organization"`
+	Name         string `json:"name"`
+	Action       string `json:"action"`
+}
+
+// NewCmdManageRestore restores a backup of the resources of all the organizations
+func NewCmdManageRestore(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "restore ARCHIVE [--passphrase PASSPHRASE] [--dry-run]",
+		Short:   i18n.T("Restore a backup of the resources of all the organizations"),
+		Long:    manageRestoreLong,
+		Example: manageRestoreExample,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := manageRestore(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+
+	cmd.Flags().BoolVar(&restoreDryRun, "dry-run", false, "compare the backup with the resources without restoring it")
+	cmd.Flags().StringVar(&restorePassphrase, "passphrase", "", "passphrase the secrets were encrypted with")
+	return cmd
+}
+
+// restorable returns the fields of a resource which are restored
+func restorable(resource json.RawMessage, volatile []string) (map[string]interface{}, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(resource, &fields); err != nil {
+		return nil, err
+	}
+	for _, field := range backupVolatileFields {
+		delete(fields, field)
+	}
+	for _, field := range volatile {
+		delete(fields, field)
+	}
+	return fields, nil
+}
+
+// restorableName returns the name of a resource, all the kinds are named
+func restorableName(fields map[string]interface{}) string {
+	name, _ := fields["name"].(string)
+	return name
+}
+
+// compareResource returns the action restoring a resource takes, given the resource of the same name, if any
+func compareResource(restored, existing map[string]interface{}) string {
+	switch {
+	case existing == nil:
+		return restoreCreate
+	case reflect.DeepEqual(restored, existing):
+		return restoreUnchanged
+	default:
+		return restoreDiffers
+	}
+}
+
+func manageRestore(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	f, err := os.Open(args[0])
+	if err != nil {
+		return errors.Wrapf(err, "error opening the archive %s", args[0])
+	}
+	defer f.Close()
+	archive, err := readBackupArchive(f, restorePassphrase)
+	if err != nil {
+		return err
+	}
+
+	organizations, err := listOrganizations()
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for _, o := range organizations {
+		existing[*o.Name] = true
+	}
+
+	var results []restoreResult
+	for _, o := range archive.organizations {
+		organization := &v1.Organization{}
+		if err := json.Unmarshal(o, organization); err != nil || organization.Name == nil {
+			return errors.Errorf("error decoding organization %s", string(o))
+		}
+		organizationID := *organization.Name
+		if existing[organizationID] {
+			continue
+		}
+		result := restoreResult{Kind: utils.OrganizationKind, Organization: organizationID, Name: organizationID, Action: restoreCreate}
+		if !restoreDryRun {
+			organization = &v1.Organization{Name: organization.Name, Members: organization.Members}
+			if err := CallCreateOrganization(organization); err != nil {
+				return err
+			}
+			result.Action = restoreCreated
+		}
+		results = append(results, result)
+	}
+
+	for _, organizationID := range archive.manifest.Organizations {
+		err := withOrganization(organizationID, func() error {
+			for _, k := range backupKinds() {
+				resources := archive.resources[organizationID][k.kind]
+				if len(resources) == 0 {
+					continue
+				}
+				current := map[string]map[string]interface{}{}
+				// a new organization has no resources, and cannot be listed in a dry run as it is not created
+				if existing[organizationID] {
+					list, err := k.list()
+					if err != nil {
+						return errors.Wrapf(err, "error listing the %s resources of organization %s", k.kind, organizationID)
+					}
+					currentResources, err := toRawMessages(list)
+					if err != nil {
+						return errors.Wrapf(err, "error decoding the %s resources of organization %s", k.kind, organizationID)
+					}
+					for _, r := range currentResources {
+						fields, err := restorable(r, k.volatile)
+						if err != nil {
+							return errors.Wrapf(err, "error decoding the %s resources of organization %s", k.kind, organizationID)
+						}
+						current[restorableName(fields)] = fields
+					}
+				}
+
+				for _, r := range resources {
+					fields, err := restorable(r, k.volatile)
+					if err != nil {
+						return errors.Wrapf(err, "error decoding %s %s", k.kind, string(r))
+					}
+					result := restoreResult{Kind: k.kind, Organization: organizationID, Name: restorableName(fields)}
+					result.Action = compareResource(fields, current[result.Name])
+					if result.Action == restoreCreate && !restoreDryRun {
+						if err := restoreResource(k, fields); err != nil {
+							return errors.Wrapf(err, "error restoring %s %s of organization %s", k.kind, result.Name, organizationID)
+						}
+						result.Action = restoreCreated
+					}
+					results = append(results, result)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return formatRestoreOutput(out, results)
+}
+
+func restoreResource(k backupKind, fields map[string]interface{}) error {
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	resource := k.newResource()
+	if err := json.Unmarshal(b, resource); err != nil {
+		return err
+	}
+	return k.create(resource)
+}
+
+func formatRestoreOutput(out io.Writer, results []restoreResult) error {
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(results)
+	}
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Kind", "Organization", "Name", "Action"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	for _, r := range results {
+		table.Append([]string{r.Kind, r.Organization, r.Name, r.Action})
+	}
+	table.Render()
+	return nil
+}
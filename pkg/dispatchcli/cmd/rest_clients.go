@@ -32,12 +32,34 @@ func tlsClient() *http.Client {
 	}
 }
 
+// organizationTransport sets the organization header on requests that were built without one
+type organizationTransport struct {
+	organization string
+	transport    http.RoundTripper
+}
+
+func (t *organizationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("X-Dispatch-Org") == "" && t.organization != "" {
+		req.Header.Set("X-Dispatch-Org", t.organization)
+	}
+	return t.transport.RoundTrip(req)
+}
+
 func httpTransport(path string) *httptransport.Runtime {
 	host := fmt.Sprintf("%s:%d", dispatchConfig.Host, dispatchConfig.Port)
 	if dispatchConfig.Scheme == "http" {
-		return httptransport.NewWithClient(host, path, []string{"http"}, &http.Client{})
+		return httptransport.NewWithClient(host, path, []string{"http"}, organizationClient(&http.Client{}))
+	}
+	return httptransport.NewWithClient(host, path, []string{"https"}, organizationClient(tlsClient()))
+}
+
+func organizationClient(c *http.Client) *http.Client {
+	transport := c.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
 	}
-	return httptransport.NewWithClient(host, path, []string{"https"}, tlsClient())
+	c.Transport = &organizationTransport{organization: getOrganization(), transport: transport}
+	return c
 }
 
 func getDispatchHost() string {
@@ -17,6 +17,8 @@ import (
 	"github.com/vmware/dispatch/pkg/dispatchcli/cmd/utils"
 	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
 	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/policy"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/role"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/rolebinding"
 	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/serviceaccount"
 	"github.com/vmware/dispatch/pkg/secret-store/gen/client/secret"
 	pkgUtils "github.com/vmware/dispatch/pkg/utils"
@@ -48,17 +50,21 @@ func NewCmdUpdate(out io.Writer, errOut io.Writer) *cobra.Command {
 			apiClient := apiManagerClient()
 
 			updateMap := map[string]ModelAction{
-				pkgUtils.APIKind:            CallUpdateAPI(apiClient),
-				pkgUtils.ApplicationKind:    CallUpdateApplication,
-				pkgUtils.BaseImageKind:      CallUpdateBaseImage(imgClient),
-				pkgUtils.DriverKind:         CallUpdateDriver(eventClient),
-				pkgUtils.DriverTypeKind:     CallUpdateDriverType(eventClient),
-				pkgUtils.FunctionKind:       CallUpdateFunction(fnClient),
-				pkgUtils.ImageKind:          CallUpdateImage(imgClient),
-				pkgUtils.SecretKind:         CallUpdateSecret,
-				pkgUtils.SubscriptionKind:   CallUpdateSubscription(eventClient),
-				pkgUtils.PolicyKind:         CallUpdatePolicy,
-				pkgUtils.ServiceAccountKind: CallUpdateServiceAccount,
+				pkgUtils.APIKind:             CallUpdateAPI(apiClient),
+				pkgUtils.ApplicationKind:     CallUpdateApplication,
+				pkgUtils.BaseImageKind:       CallUpdateBaseImage(imgClient),
+				pkgUtils.CertificateKind:     CallUpdateCertificate(apiClient),
+				pkgUtils.DriverKind:          CallUpdateDriver(eventClient),
+				pkgUtils.DriverTypeKind:      CallUpdateDriverType(eventClient),
+				pkgUtils.FunctionKind:        CallUpdateFunction(fnClient),
+				pkgUtils.ImageKind:           CallUpdateImage(imgClient),
+				pkgUtils.SecretKind:          CallUpdateSecret,
+				pkgUtils.SubscriptionKind:    CallUpdateSubscription(eventClient),
+				pkgUtils.PolicyKind:          CallUpdatePolicy,
+				pkgUtils.ServiceAccountKind:  CallUpdateServiceAccount,
+				pkgUtils.ServiceInstanceKind: CallUpdateServiceInstance,
+				pkgUtils.RoleKind:            CallUpdateRole,
+				pkgUtils.RoleBindingKind:     CallUpdateRoleBinding,
 			}
 
 			err := importFile(out, errOut, cmd, args, updateMap, "Updated")
@@ -69,6 +75,11 @@ func NewCmdUpdate(out io.Writer, errOut io.Writer) *cobra.Command {
 	cmd.Flags().StringVarP(&file, "file", "f", "", "Path to YAML file")
 	cmd.Flags().StringVarP(&workDir, "work-dir", "w", "", "Working directory relative paths are based on")
 
+	cmd.AddCommand(NewCmdUpdateSecret(out, errOut))
+	cmd.AddCommand(NewCmdUpdateServiceAccount(out, errOut))
+	cmd.AddCommand(NewCmdUpdateServiceBroker(out, errOut))
+	cmd.AddCommand(NewCmdUpdateServiceInstance(out, errOut))
+	cmd.AddCommand(NewCmdUpdateServiceBinding(out, errOut))
 	return cmd
 }
 
@@ -115,6 +126,20 @@ func CallUpdateBaseImage(c client.ImagesClient) ModelAction {
 	}
 }
 
+// CallUpdateCertificate makes the backend service call to update a certificate
+func CallUpdateCertificate(c client.APIsClient) ModelAction {
+	return func(input interface{}) error {
+		certBody := input.(*v1.Certificate)
+
+		_, err := c.UpdateCertificate(context.TODO(), "", certBody)
+		if err != nil {
+			return formatAPIError(err, certBody)
+		}
+
+		return nil
+	}
+}
+
 // CallUpdateDriver makes the API call to update an event driver
 func CallUpdateDriver(c client.EventsClient) ModelAction {
 	return func(input interface{}) error {
@@ -176,6 +201,44 @@ func CallUpdatePolicy(p interface{}) error {
 	return nil
 }
 
+// CallUpdateRole updates a role
+func CallUpdateRole(r interface{}) error {
+
+	roleModel := r.(*v1.Role)
+
+	params := &role.UpdateRoleParams{
+		RoleName: *roleModel.Name,
+		Body:     roleModel,
+		Context:  context.Background(),
+	}
+
+	_, err := identityManagerClient().Role.UpdateRole(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+
+	return nil
+}
+
+// CallUpdateRoleBinding updates a role binding
+func CallUpdateRoleBinding(b interface{}) error {
+
+	bindingModel := b.(*v1.RoleBinding)
+
+	params := &rolebinding.UpdateRoleBindingParams{
+		RoleBindingName: *bindingModel.Name,
+		Body:            bindingModel,
+		Context:         context.Background(),
+	}
+
+	_, err := identityManagerClient().Rolebinding.UpdateRoleBinding(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+
+	return nil
+}
+
 // CallUpdateServiceAccount updates a serviceaccount
 func CallUpdateServiceAccount(p interface{}) error {
 
@@ -0,0 +1,95 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	secret "github.com/vmware/dispatch/pkg/secret-store/gen/client/secret"
+)
+
+var (
+	updateSecretLong = i18n.T(`Roll back or rotate a dispatch secret.
+	--rollback VERSION - make the values of VERSION current again (as a new version)
+	--rotate - run the rotation function of the secret now`)
+
+	updateSecretExample = i18n.T(`# roll back to version 2
+dispatch update secret psql-creds --rollback 2
+
+# rotate the secret now
+dispatch update secret psql-creds --rotate`)
+
+	updateSecretRollback int64
+	updateSecretRotate   = false
+)
+
+// NewCmdUpdateSecret creates command responsible for secret rollback and rotation.
+func NewCmdUpdateSecret(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "secret SECRET_NAME",
+		Short:   i18n.T("Roll back or rotate secret"),
+		Long:    updateSecretLong,
+		Example: updateSecretExample,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := updateSecret(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().Int64Var(&updateSecretRollback, "rollback", 0, "version to roll back to")
+	cmd.Flags().BoolVar(&updateSecretRotate, "rotate", false, "rotate the secret")
+	return cmd
+}
+
+func updateSecret(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	client := secretStoreClient()
+
+	var updated *v1.Secret
+	switch {
+	case updateSecretRollback > 0 && updateSecretRotate:
+		return formatCliError(errors.New("--rollback and --rotate are mutually exclusive"), "invalid flags")
+	case updateSecretRollback > 0:
+		params := &secret.RollbackSecretParams{
+			Context:    context.Background(),
+			SecretName: args[0],
+			Version:    updateSecretRollback,
+		}
+		resp, err := client.Secret.RollbackSecret(params, GetAuthInfoWriter())
+		if err != nil {
+			return formatAPIError(err, params)
+		}
+		updated = resp.Payload
+	case updateSecretRotate:
+		params := &secret.RotateSecretParams{
+			Context:    context.Background(),
+			SecretName: args[0],
+		}
+		resp, err := client.Secret.RotateSecret(params, GetAuthInfoWriter())
+		if err != nil {
+			return formatAPIError(err, params)
+		}
+		updated = resp.Payload
+	default:
+		runHelp(cmd, args)
+		return nil
+	}
+
+	if dispatchConfig.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(updated)
+	}
+	fmt.Fprintf(out, "Updated secret: %s (version %d)\n", *updated.Name, updated.Version)
+	return nil
+}
@@ -0,0 +1,25 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCmdUpdateSecret(t *testing.T) {
+	var buf bytes.Buffer
+
+	cli := NewCLI(os.Stdin, &buf, &buf)
+	cli.SetOutput(&buf)
+	cli.SetArgs([]string{"update", "secret", "--help"})
+	err := cli.Execute()
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(buf.String(), "Roll back or rotate a dispatch secret"))
+}
@@ -0,0 +1,121 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/client/serviceaccount"
+)
+
+var (
+	updateServiceAccountLong = i18n.T(`Rotate the public keys of a dispatch service account.
+	--add-key PUBLIC_KEY_PATH - add a public key, the tokens signed with any of the keys are valid
+	--remove-key KEY_ID - remove a public key, the tokens signed with it are no longer valid`)
+
+	updateServiceAccountExample = i18n.T(`# add a new key, then remove the previous one once the clients sign with the new key
+dispatch iam update serviceaccount ci --add-key ./ci_ed25519.pub
+dispatch iam update serviceaccount ci --remove-key 4f2a9c0d1e3b5a67`)
+
+	updateServiceAccountAddKeys    []string
+	updateServiceAccountRemoveKeys []string
+)
+
+// NewCmdUpdateServiceAccount creates command responsible for service account key rotation.
+func NewCmdUpdateServiceAccount(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "serviceaccount SERVICE_ACCOUNT_NAME [--add-key PUBLIC_KEY_PATH] [--remove-key KEY_ID]",
+		Short:   i18n.T("Rotate the public keys of a service account"),
+		Long:    updateServiceAccountLong,
+		Example: updateServiceAccountExample,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := updateServiceAccount(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().StringSliceVar(&updateServiceAccountAddKeys, "add-key", []string{}, "path of a public key file to add")
+	cmd.Flags().StringSliceVar(&updateServiceAccountRemoveKeys, "remove-key", []string{}, "ID of a public key to remove")
+	return cmd
+}
+
+// rotateServiceAccountKeys adds and removes public keys of a service account. The first remaining key becomes the
+// public key of the service account.
+func rotateServiceAccountKeys(svcAccount *v1.ServiceAccount, add []string, remove []string) error {
+	removed := map[string]bool{}
+	for _, id := range remove {
+		removed[id] = true
+	}
+	var active []*v1.ServiceAccountKey
+	for _, k := range svcAccount.Keys {
+		if removed[k.ID] {
+			delete(removed, k.ID)
+			continue
+		}
+		active = append(active, k)
+	}
+	for id := range removed {
+		return errors.Errorf("service account %s has no key %s", *svcAccount.Name, id)
+	}
+	for _, publicKey := range add {
+		active = append(active, &v1.ServiceAccountKey{PublicKey: swag.String(publicKey)})
+	}
+	if len(active) == 0 {
+		return errors.Errorf("service account %s must have at least one key", *svcAccount.Name)
+	}
+	svcAccount.PublicKey = active[0].PublicKey
+	svcAccount.Keys = active
+	return nil
+}
+
+func updateServiceAccount(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	if len(updateServiceAccountAddKeys) == 0 && len(updateServiceAccountRemoveKeys) == 0 {
+		return formatCliError(errors.New("either --add-key or --remove-key is required"), "invalid flags")
+	}
+	var add []string
+	for _, path := range updateServiceAccountAddKeys {
+		publicKeyBytes, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("Error reading public key file: %s", err.Error())
+		}
+		add = append(add, base64.StdEncoding.EncodeToString(publicKeyBytes))
+	}
+
+	client := identityManagerClient()
+	getParams := &serviceaccount.GetServiceAccountParams{
+		ServiceAccountName: args[0],
+		Context:            context.Background(),
+	}
+	resp, err := client.Serviceaccount.GetServiceAccount(getParams, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, getParams)
+	}
+	svcAccount := resp.Payload
+	if err := rotateServiceAccountKeys(svcAccount, add, updateServiceAccountRemoveKeys); err != nil {
+		return formatCliError(err, "invalid keys")
+	}
+
+	params := &serviceaccount.UpdateServiceAccountParams{
+		ServiceAccountName: args[0],
+		Body:               svcAccount,
+		Context:            context.Background(),
+	}
+	updated, err := client.Serviceaccount.UpdateServiceAccount(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	return formatServiceAccountOutput(out, false, []*v1.ServiceAccount{updated.Payload})
+}
@@ -0,0 +1,40 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+)
+
+func TestRotateServiceAccountKeys(t *testing.T) {
+	svcAccount := &v1.ServiceAccount{
+		Name:      swag.String("ci"),
+		PublicKey: swag.String("old"),
+		Keys: []*v1.ServiceAccountKey{
+			{ID: "1", PublicKey: swag.String("old")},
+		},
+	}
+
+	require.NoError(t, rotateServiceAccountKeys(svcAccount, []string{"new"}, nil))
+	require.Len(t, svcAccount.Keys, 2)
+	assert.Equal(t, "old", *svcAccount.PublicKey)
+	assert.Equal(t, "new", *svcAccount.Keys[1].PublicKey)
+
+	// The first remaining key becomes the public key
+	svcAccount.Keys[1].ID = "2"
+	require.NoError(t, rotateServiceAccountKeys(svcAccount, nil, []string{"1"}))
+	require.Len(t, svcAccount.Keys, 1)
+	assert.Equal(t, "new", *svcAccount.PublicKey)
+
+	assert.Error(t, rotateServiceAccountKeys(svcAccount, nil, []string{"unknown"}))
+	assert.Error(t, rotateServiceAccountKeys(svcAccount, nil, []string{"2"}))
+}
@@ -0,0 +1,61 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	servicebinding "github.com/vmware/dispatch/pkg/service-manager/gen/client/service_binding"
+)
+
+var (
+	updateServiceBindingLong = i18n.T(`Update a service binding.
+	--rotate - bind the service instance again, the new credentials of the binding replace the previous ones`)
+
+	updateServiceBindingExample = i18n.T(`# rotate the credentials of a binding
+dispatch update servicebinding mydb-reader --rotate`)
+
+	updateServiceBindingRotate = false
+)
+
+// NewCmdUpdateServiceBinding creates command responsible for service binding updates.
+func NewCmdUpdateServiceBinding(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "servicebinding SERVICE_BINDING_NAME --rotate",
+		Short:   i18n.T("Rotate the credentials of a service binding"),
+		Long:    updateServiceBindingLong,
+		Example: updateServiceBindingExample,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := updateServiceBinding(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().BoolVar(&updateServiceBindingRotate, "rotate", false, "rotate the credentials of the binding")
+	return cmd
+}
+
+func updateServiceBinding(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	if !updateServiceBindingRotate {
+		return formatCliError(errors.New("--rotate is required"), "invalid flags")
+	}
+	client := serviceManagerClient()
+	params := &servicebinding.RotateServiceBindingParams{
+		ServiceBindingName: args[0],
+		Context:            context.Background(),
+	}
+	rotated, err := client.ServiceBinding.RotateServiceBinding(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	return formatServiceBindingOutput(out, false, []*v1.ServiceBinding{rotated.Payload})
+}
@@ -0,0 +1,61 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	servicebroker "github.com/vmware/dispatch/pkg/service-manager/gen/client/service_broker"
+)
+
+var (
+	updateServiceBrokerLong = i18n.T(`Update a service broker.
+	--refresh - fetch the catalog of the broker now, rather than at the next periodic refresh`)
+
+	updateServiceBrokerExample = i18n.T(`# pick up the services just added to the catalog of a broker
+dispatch update servicebroker mysql --refresh`)
+
+	updateServiceBrokerRefresh = false
+)
+
+// NewCmdUpdateServiceBroker creates command responsible for service broker updates.
+func NewCmdUpdateServiceBroker(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "servicebroker SERVICE_BROKER_NAME --refresh",
+		Short:   i18n.T("Refresh the catalog of a service broker"),
+		Long:    updateServiceBrokerLong,
+		Example: updateServiceBrokerExample,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := updateServiceBroker(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().BoolVar(&updateServiceBrokerRefresh, "refresh", false, "refresh the catalog of the broker")
+	return cmd
+}
+
+func updateServiceBroker(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	if !updateServiceBrokerRefresh {
+		return formatCliError(errors.New("--refresh is required"), "invalid flags")
+	}
+	client := serviceManagerClient()
+	params := &servicebroker.RefreshServiceBrokerParams{
+		ServiceBrokerName: args[0],
+		Context:           context.Background(),
+	}
+	refreshed, err := client.ServiceBroker.RefreshServiceBroker(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+	return formatServiceBrokerOutput(out, false, []*v1.ServiceBroker{refreshed.Payload})
+}
@@ -0,0 +1,100 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	serviceinstance "github.com/vmware/dispatch/pkg/service-manager/gen/client/service_instance"
+)
+
+var (
+	updateServiceInstanceLong = i18n.T(`Update the plan or the parameters of a service instance.
+	--plan - the plan to switch the service instance to, if the service class allows plan changes
+	--params - the parameters replacing those of the service instance (JSON)
+The bindings of the service instance are refreshed once the broker updated it.`)
+
+	updateServiceInstanceExample = i18n.T(`# switch a service instance to a larger plan
+dispatch update serviceinstance mydb --plan large`)
+
+	updateServicePlan       = ""
+	updateServiceParameters = ""
+)
+
+// CallUpdateServiceInstance makes the API call to update a service instance
+func CallUpdateServiceInstance(s interface{}) error {
+	client := serviceManagerClient()
+	body := s.(*v1.ServiceInstance)
+
+	params := &serviceinstance.UpdateServiceInstanceByNameParams{
+		ServiceInstanceName: *body.Name,
+		Body:                body,
+		Context:             context.Background(),
+	}
+
+	updated, err := client.ServiceInstance.UpdateServiceInstanceByName(params, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, params)
+	}
+
+	*body = *updated.Payload
+	return nil
+}
+
+// NewCmdUpdateServiceInstance creates command responsible for service instance updates.
+func NewCmdUpdateServiceInstance(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "serviceinstance SERVICE_INSTANCE_NAME [--plan SERVICE_PLAN_NAME] [--params JSON]",
+		Short:   i18n.T("Update the plan or the parameters of a service instance"),
+		Long:    updateServiceInstanceLong,
+		Example: updateServiceInstanceExample,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := updateServiceInstance(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVar(&updateServicePlan, "plan", "", "the plan to switch the service instance to")
+	cmd.Flags().StringVarP(&updateServiceParameters, "params", "p", "", "service instance update parameters (JSON)")
+	return cmd
+}
+
+func updateServiceInstance(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	if updateServicePlan == "" && updateServiceParameters == "" {
+		return formatCliError(errors.New("--plan or --params is required"), "invalid flags")
+	}
+	client := serviceManagerClient()
+	getParams := &serviceinstance.GetServiceInstanceByNameParams{
+		ServiceInstanceName: args[0],
+		Context:             context.Background(),
+	}
+	resp, err := client.ServiceInstance.GetServiceInstanceByName(getParams, GetAuthInfoWriter())
+	if err != nil {
+		return formatAPIError(err, getParams)
+	}
+	body := resp.Payload
+	if updateServicePlan != "" {
+		body.ServicePlan = &updateServicePlan
+	}
+	if updateServiceParameters != "" {
+		p, err := parseParameters(updateServiceParameters)
+		if err != nil {
+			return err
+		}
+		body.Parameters = p
+	}
+
+	if err := CallUpdateServiceInstance(body); err != nil {
+		return err
+	}
+	return formatServiceInstanceOutput(out, false, []*v1.ServiceInstance{body})
+}
@@ -7,7 +7,11 @@ package utils
 
 // NO TESTS
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/vmware/dispatch/pkg/client"
+)
 
 // AppendApplication append an application k
 func AppendApplication(tags *[]string, application string) {
@@ -15,3 +19,8 @@ func AppendApplication(tags *[]string, application string) {
 		*tags = append(*tags, fmt.Sprintf("Application=%s", application))
 	}
 }
+
+// AppendSelector appends the terms of a label selector
+func AppendSelector(tags *[]string, selector string) {
+	*tags = append(*tags, client.SelectorTerms(selector)...)
+}
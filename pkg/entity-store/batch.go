@@ -0,0 +1,301 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package entitystore
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// batchOp is a write of a batch transaction
+type batchOp struct {
+	eventType EventType
+	// key is the key of the entity, as built by buildKey
+	key string
+	// entity is the entity written, the entity of the event of deletes
+	entity Entity
+	data   []byte
+	// lastRevision is the revision updated entities are expected to have in the store
+	lastRevision uint64
+}
+
+// batchCommit applies the writes of a batch transaction atomically, and sets the revisions of the entities written
+type batchCommit func(ctx context.Context, ops []*batchOp) error
+
+// batchTx is the transaction of a store without transactions. Its writes are buffered, the last write of each entity
+// is committed in a batch.
+type batchTx struct {
+	store EntityStore
+	// keys are the keys written, in the order they were first written
+	keys    []string
+	pending map[string]*batchOp
+}
+
+// runBatchTx runs f with a batch transaction of a store, and commits its writes
+func runBatchTx(ctx context.Context, store EntityStore, commit batchCommit, f func(tx EntityStore) error) error {
+	tx := &batchTx{store: store, pending: map[string]*batchOp{}}
+	if err := f(tx); err != nil {
+		return err
+	}
+	ops := tx.ops()
+	if len(ops) == 0 {
+		return nil
+	}
+	return commit(ctx, ops)
+}
+
+// ops returns the writes to commit
+func (tx *batchTx) ops() []*batchOp {
+	var ops []*batchOp
+	seen := map[string]bool{}
+	for _, key := range tx.keys {
+		if op, ok := tx.pending[key]; ok && !seen[key] {
+			ops = append(ops, op)
+		}
+		seen[key] = true
+	}
+	return ops
+}
+
+func (tx *batchTx) write(op *batchOp) {
+	if _, ok := tx.pending[op.key]; !ok {
+		tx.keys = append(tx.keys, op.key)
+	}
+	tx.pending[op.key] = op
+}
+
+// exists returns whether an entity is in the store
+func (tx *batchTx) exists(ctx context.Context, organizationID string, name string, entity Entity) (bool, error) {
+	return tx.store.Find(ctx, organizationID, name, Options{}, reflect.New(reflect.TypeOf(entity).Elem()).Interface().(Entity))
+}
+
+// Add adds new entities to the store
+func (tx *batchTx) Add(ctx context.Context, entity Entity) (id string, err error) {
+	err = precondition(entity)
+	if err != nil {
+		return "", errors.Wrap(err, "Precondition failed")
+	}
+
+	key := getKey(entity)
+	if op, ok := tx.pending[key]; ok {
+		if op.eventType != EventDelete {
+			return "", &kvUniqueViolation{key}
+		}
+		return "", errors.Errorf("error adding entity: %s was deleted in the transaction", key)
+	}
+	exists, err := tx.exists(ctx, entity.GetOrganizationID(), entity.GetName(), entity)
+	if err != nil {
+		return "", errors.Wrap(err, "error checking if the key exists")
+	}
+	if exists {
+		return "", &kvUniqueViolation{key}
+	}
+
+	id = uuid.NewV4().String()
+	entity.setID(id)
+	now := time.Now()
+	entity.setCreatedTime(now)
+	entity.setModifiedTime(now)
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return "", errors.Wrap(err, "serialization error, before adding")
+	}
+	tx.write(&batchOp{eventType: EventAdd, key: key, entity: entity, data: data})
+	return id, nil
+}
+
+// Update updates existing entities to the store, an entity written more than once in the transaction is written once
+func (tx *batchTx) Update(ctx context.Context, lastRevision uint64, entity Entity) (revision int64, err error) {
+	key := getKey(entity)
+	op, ok := tx.pending[key]
+	if ok && op.eventType == EventDelete {
+		return 0, errors.Errorf("Entity not found, cannot update")
+	}
+
+	entity.setModifiedTime(time.Now())
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return 0, errors.Wrap(err, "serialization error, before updating")
+	}
+	if ok {
+		op.entity, op.data = entity, data
+	} else {
+		tx.write(&batchOp{eventType: EventUpdate, key: key, entity: entity, data: data, lastRevision: lastRevision})
+	}
+	return int64(entity.GetRevision()), nil
+}
+
+// Delete deletes a single entity from the store
+func (tx *batchTx) Delete(ctx context.Context, organizationID string, name string, entity Entity) error {
+	key := buildKey(organizationID, getDataType(entity), name)
+	op, ok := tx.pending[key]
+	switch {
+	case ok && op.eventType == EventDelete:
+		return errors.New("error deleting: no such entity")
+	case ok && op.eventType == EventAdd:
+		// the entity is not in the store
+		delete(tx.pending, key)
+		return nil
+	case !ok:
+		exists, err := tx.exists(ctx, organizationID, name, entity)
+		if err != nil {
+			return errors.Wrap(err, "error deleting an entity")
+		}
+		if !exists {
+			return errors.New("error deleting: no such entity")
+		}
+	}
+	tx.write(&batchOp{eventType: EventDelete, key: key, entity: deletedEntity(reflect.TypeOf(entity), organizationID, name)})
+	return nil
+}
+
+// SoftDelete marks a single entity for deletion
+func (tx *batchTx) SoftDelete(ctx context.Context, entity Entity) error {
+	entity.SetDelete(true)
+	entity.SetStatus(StatusDELETING)
+	_, err := tx.Update(ctx, entity.GetRevision(), entity)
+	return err
+}
+
+// UpdateWithError is used by entity handlers to save changes and/or error status
+// e.g. `defer func() { h.store.UpdateWithError(e, err) }()`
+func (tx *batchTx) UpdateWithError(ctx context.Context, e Entity, err error) {
+	if err != nil {
+		e.SetStatus(StatusERROR)
+		e.SetReason([]string{err.Error()})
+	}
+	if _, err2 := tx.Update(ctx, e.GetRevision(), e); err2 != nil {
+		log.Error(err2)
+	}
+}
+
+// pendingEntity decodes the entity of a pending write
+func (tx *batchTx) pendingEntity(op *batchOp, opts Options, entity Entity) (bool, error) {
+	if err := json.Unmarshal(op.data, entity); err != nil {
+		return false, errors.Wrap(err, "deserialization error, while getting")
+	}
+	if opts.Filter != nil {
+		ok, err := doFilter(opts.Filter, entity)
+		if err != nil {
+			return false, errors.Wrap(err, "error filtering entity")
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	entity.setRevision(op.entity.GetRevision())
+	return true, nil
+}
+
+// Find gets a single entity by name from the store and returns a touple of found, error
+func (tx *batchTx) Find(ctx context.Context, organizationID string, name string, opts Options, entity Entity) (bool, error) {
+	op, ok := tx.pending[buildKey(organizationID, getDataType(entity), name)]
+	if !ok {
+		return tx.store.Find(ctx, organizationID, name, opts, entity)
+	}
+	if op.eventType == EventDelete {
+		return false, nil
+	}
+	return tx.pendingEntity(op, opts, entity)
+}
+
+// Get gets a single entity by name from the store
+func (tx *batchTx) Get(ctx context.Context, organizationID string, name string, opts Options, entity Entity) error {
+	found, err := tx.Find(ctx, organizationID, name, opts, entity)
+	if err != nil || !found {
+		return errors.New("error getting: no such entity")
+	}
+	return nil
+}
+
+// List fetches a list of entities of a single data type satisfying the filter, the entities of the store are listed
+// with the pending writes
+func (tx *batchTx) List(ctx context.Context, organizationID string, opts Options, entities interface{}) error {
+	rv := reflect.ValueOf(entities)
+	if entities == nil || rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return errors.New("need a non-nil entity slice pointer")
+	}
+	elemType := rv.Elem().Type().Elem()
+	if !elemType.Implements(reflect.TypeOf((*Entity)(nil)).Elem()) {
+		return errors.New("non-entity element type: maybe use pointers")
+	}
+	if err := opts.Validate(); err != nil {
+		return errors.Wrap(err, "error listing")
+	}
+
+	stored := reflect.New(rv.Elem().Type())
+	if err := tx.store.List(ctx, organizationID, Options{Filter: opts.Filter}, stored.Interface()); err != nil {
+		return err
+	}
+	var listed []Entity
+	for i := 0; i < stored.Elem().Len(); i++ {
+		entity := stored.Elem().Index(i).Interface().(Entity)
+		if _, ok := tx.pending[getKey(entity)]; !ok {
+			listed = append(listed, entity)
+		}
+	}
+	dt := dataType(elemType.Elem().Name())
+	for _, op := range tx.ops() {
+		if op.eventType == EventDelete || getDataType(op.entity) != dt || op.entity.GetOrganizationID() != organizationID {
+			continue
+		}
+		entity := reflect.New(elemType.Elem()).Interface().(Entity)
+		ok, err := tx.pendingEntity(op, Options{Filter: opts.Filter}, entity)
+		if err != nil {
+			return errors.Wrap(err, "error listing")
+		}
+		if ok {
+			listed = append(listed, entity)
+		}
+	}
+
+	listed, err := sortAndPage(listed, opts)
+	if err != nil {
+		return errors.Wrap(err, "error listing")
+	}
+	slice := reflect.MakeSlice(rv.Elem().Type(), 0, len(listed))
+	for _, entity := range listed {
+		slice = reflect.Append(slice, reflect.ValueOf(entity))
+	}
+	rv.Elem().Set(slice)
+	return nil
+}
+
+// ListOrgIDs fetches a list of organization ID's, including the organizations of the entities added
+func (tx *batchTx) ListOrgIDs(ctx context.Context) ([]string, error) {
+	orgIDs, err := tx.store.ListOrgIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, orgID := range orgIDs {
+		seen[orgID] = true
+	}
+	for _, op := range tx.ops() {
+		if orgID := op.entity.GetOrganizationID(); op.eventType == EventAdd && !seen[orgID] {
+			seen[orgID] = true
+			orgIDs = append(orgIDs, orgID)
+		}
+	}
+	return orgIDs, nil
+}
+
+// Watch streams the changes of the entities committed to the store
+func (tx *batchTx) Watch(ctx context.Context, organizationID string, entityType reflect.Type, filter Filter) (<-chan Event, error) {
+	return tx.store.Watch(ctx, organizationID, entityType, filter)
+}
+
+// Tx runs f in the transaction
+func (tx *batchTx) Tx(ctx context.Context, f func(tx EntityStore) error) error {
+	return f(tx)
+}
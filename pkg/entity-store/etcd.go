@@ -0,0 +1,493 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package entitystore
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/etcdserver/api/v3rpc/rpctypes"
+	"go.etcd.io/etcd/mvcc/mvccpb"
+)
+
+const (
+	// defaultEtcdNamespace prefixes the keys of the entities when the backend config has no bucket
+	defaultEtcdNamespace = "dispatch"
+
+	// etcdDialTimeout bounds the connection to the cluster when the store is created
+	etcdDialTimeout = 5 * time.Second
+
+	etcdWatchReconnect = time.Second
+)
+
+type etcdEntityStore struct {
+	client *clientv3.Client
+	// prefix namespaces the keys of the entities, the stores sharing a cluster have different buckets
+	prefix string
+
+	// ctx is canceled when the store is closed, which stops the watch of the entities
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	broadcaster *broadcaster
+	watcherOnce sync.Once
+	watcherErr  error
+}
+
+// newEtcd creates an etcd v3 entity store, the address of the backend config is a comma separated list of the
+// endpoints of the cluster
+func newEtcd(config BackendConfig) (EntityStore, error) {
+	var endpoints []string
+	for _, endpoint := range strings.Split(config.Address, ",") {
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		Username:    config.Username,
+		Password:    config.Password,
+		DialTimeout: etcdDialTimeout,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to connect to the etcd cluster")
+	}
+
+	bucket := config.Bucket
+	if bucket == "" {
+		bucket = defaultEtcdNamespace
+	}
+	es := &etcdEntityStore{
+		client:      client,
+		prefix:      "/" + bucket + "/",
+		broadcaster: newBroadcaster(),
+	}
+	es.ctx, es.cancel = context.WithCancel(context.Background())
+
+	// the cluster is checked on creation, as the other backends
+	ctx, cancel := context.WithTimeout(context.Background(), etcdDialTimeout)
+	defer cancel()
+	if _, err := client.Get(ctx, es.prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly(), clientv3.WithLimit(1)); err != nil {
+		es.Close()
+		return nil, errors.Wrap(err, "Unable to connect to the etcd cluster")
+	}
+	return es, nil
+}
+
+// Close stops the watch of the entities, and closes the connection to the cluster
+func (es *etcdEntityStore) Close() error {
+	es.cancel()
+	return es.client.Close()
+}
+
+func (es *etcdEntityStore) key(entity Entity) string {
+	return es.prefix + getKey(entity)
+}
+
+// parseKey returns the organization, data type and name of the entity of a key
+func (es *etcdEntityStore) parseKey(key []byte) (organizationID string, dt dataType, name string, ok bool) {
+	parts := strings.SplitN(strings.TrimPrefix(string(key), es.prefix), "/", 3)
+	if len(parts) != 3 {
+		return "", "", "", false
+	}
+	return parts[0], dataType(parts[1]), parts[2], true
+}
+
+// grantLease grants the lease of an entity which expires, the entity keeps it until it is deleted. It returns no
+// lease for the other entities.
+func (es *etcdEntityStore) grantLease(ctx context.Context, entity Entity) (clientv3.LeaseID, error) {
+	e, ok := entity.(Expiring)
+	if !ok || e.TTL() <= 0 {
+		return clientv3.NoLease, nil
+	}
+	// the TTLs are in seconds, rounded up
+	seconds := int64((e.TTL() + time.Second - 1) / time.Second)
+	resp, err := es.client.Grant(ctx, seconds)
+	if err != nil {
+		return clientv3.NoLease, errors.Wrap(err, "error granting the lease of the entity")
+	}
+	return resp.ID, nil
+}
+
+// revokeLeases revokes the leases of the keys deleted, the leases of expiring entities are not shared. The leases
+// which can't be revoked expire.
+func (es *etcdEntityStore) revokeLeases(ctx context.Context, kvs ...*mvccpb.KeyValue) {
+	for _, kv := range kvs {
+		es.revokeLease(ctx, clientv3.LeaseID(kv.Lease))
+	}
+}
+
+func (es *etcdEntityStore) revokeLease(ctx context.Context, lease clientv3.LeaseID) {
+	if lease == clientv3.NoLease {
+		return
+	}
+	if _, err := es.client.Revoke(ctx, lease); err != nil && err != rpctypes.ErrLeaseNotFound {
+		log.Warnf("error revoking the etcd lease %x: %s", lease, err)
+	}
+}
+
+// Add adds new entities to the store
+func (es *etcdEntityStore) Add(ctx context.Context, entity Entity) (id string, err error) {
+	err = precondition(entity)
+	if err != nil {
+		return "", errors.Wrap(err, "Precondition failed")
+	}
+
+	id = uuid.NewV4().String()
+	entity.setID(id)
+	now := time.Now()
+	entity.setCreatedTime(now)
+	entity.setModifiedTime(now)
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return "", errors.Wrap(err, "serialization error, before adding")
+	}
+	lease, err := es.grantLease(ctx, entity)
+	if err != nil {
+		return "", errors.Wrap(err, "error adding entity")
+	}
+
+	// the key is created, unless it exists
+	key := es.key(entity)
+	resp, err := es.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, string(data), clientv3.WithLease(lease))).
+		Commit()
+	if err != nil || !resp.Succeeded {
+		es.revokeLease(ctx, lease)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "error adding entity")
+	}
+	if !resp.Succeeded {
+		return "", &kvUniqueViolation{key}
+	}
+	entity.setRevision(uint64(resp.Header.Revision))
+	return id, nil
+}
+
+// Update updates existing entities to the store, the revision of an entity is the revision of the cluster it was last
+// modified at. The entities keep the lease they were added with.
+func (es *etcdEntityStore) Update(ctx context.Context, lastRevision uint64, entity Entity) (revision int64, err error) {
+	entity.setModifiedTime(time.Now())
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return 0, errors.Wrap(err, "serialization error, before updating")
+	}
+	key := es.key(entity)
+	resp, err := es.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), ">", 0),
+			clientv3.Compare(clientv3.ModRevision(key), "=", int64(lastRevision))).
+		Then(clientv3.OpPut(key, string(data), clientv3.WithIgnoreLease())).
+		Commit()
+	if err != nil {
+		return 0, errors.Wrap(err, "error updating entity")
+	}
+	if !resp.Succeeded {
+		return 0, errors.Errorf("error updating entity: no such entity or there's intermidate update")
+	}
+	entity.setRevision(uint64(resp.Header.Revision))
+	return resp.Header.Revision, nil
+}
+
+// Delete deletes a single entity from the store, and revokes its lease
+func (es *etcdEntityStore) Delete(ctx context.Context, organizationID string, name string, entity Entity) error {
+	key := es.prefix + buildKey(organizationID, getDataType(entity), name)
+	resp, err := es.client.Delete(ctx, key, clientv3.WithPrevKV())
+	if err != nil {
+		return errors.Wrap(err, "error deleting an entity")
+	}
+	if resp.Deleted == 0 {
+		return errors.New("error deleting: no such entity")
+	}
+	es.revokeLeases(ctx, resp.PrevKvs...)
+	return nil
+}
+
+// SoftDelete marks a single entity for deletion
+func (es *etcdEntityStore) SoftDelete(ctx context.Context, entity Entity) error {
+	entity.SetDelete(true)
+	entity.SetStatus(StatusDELETING)
+	_, err := es.Update(ctx, entity.GetRevision(), entity)
+	return err
+}
+
+// UpdateWithError is used by entity handlers to save changes and/or error status
+// e.g. `defer func() { h.store.UpdateWithError(e, err) }()`
+func (es *etcdEntityStore) UpdateWithError(ctx context.Context, e Entity, err error) {
+	if err != nil {
+		e.SetStatus(StatusERROR)
+		e.SetReason([]string{err.Error()})
+	}
+	if _, err2 := es.Update(ctx, e.GetRevision(), e); err2 != nil {
+		log.Error(err2)
+	}
+}
+
+// Find gets a single entity by name from the store and returns a touple of found, error
+func (es *etcdEntityStore) Find(ctx context.Context, organizationID string, name string, opts Options, entity Entity) (bool, error) {
+	key := es.prefix + buildKey(organizationID, getDataType(entity), name)
+	resp, err := es.client.Get(ctx, key)
+	if err != nil {
+		return false, errors.Wrap(err, "error getting entity")
+	}
+	if len(resp.Kvs) == 0 {
+		return false, nil
+	}
+	kv := resp.Kvs[0]
+	if err := json.Unmarshal(kv.Value, entity); err != nil {
+		return false, errors.Wrap(err, "deserialization error, while getting")
+	}
+	if opts.Filter != nil {
+		ok, err := doFilter(opts.Filter, entity)
+		if err != nil {
+			return false, errors.Wrap(err, "error filtering entity")
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	entity.setRevision(uint64(kv.ModRevision))
+	return true, nil
+}
+
+// Get gets a single entity by name from the store
+func (es *etcdEntityStore) Get(ctx context.Context, organizationID string, name string, opts Options, entity Entity) error {
+	found, err := es.Find(ctx, organizationID, name, opts, entity)
+	if err != nil || !found {
+		return errors.New("error getting: no such entity")
+	}
+	return nil
+}
+
+// List fetches a list of entities of a single data type satisfying the filter, the entities of the type are listed
+// by the prefix of their keys
+func (es *etcdEntityStore) List(ctx context.Context, organizationID string, opts Options, entities interface{}) error {
+	rv := reflect.ValueOf(entities)
+	if entities == nil || rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return errors.New("need a non-nil entity slice pointer")
+	}
+	elemType := rv.Elem().Type().Elem()
+	if !elemType.Implements(reflect.TypeOf((*Entity)(nil)).Elem()) {
+		return errors.New("non-entity element type: maybe use pointers")
+	}
+	if err := opts.Validate(); err != nil {
+		return errors.Wrap(err, "error listing")
+	}
+
+	prefix := es.prefix + buildKey(organizationID, dataType(elemType.Elem().Name()))
+	resp, err := es.client.Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return errors.Wrap(err, "error listing entities")
+	}
+	var listed []Entity
+	for _, kv := range resp.Kvs {
+		entity := reflect.New(elemType.Elem()).Interface().(Entity)
+		if err := json.Unmarshal(kv.Value, entity); err != nil {
+			return errors.Wrap(err, "deserialization error, while listing")
+		}
+		if opts.Filter != nil {
+			ok, err := doFilter(opts.Filter, entity)
+			if err != nil {
+				log.Print(err)
+			}
+			if !ok {
+				continue
+			}
+		}
+		entity.setRevision(uint64(kv.ModRevision))
+		listed = append(listed, entity)
+	}
+
+	listed, err = sortAndPage(listed, opts)
+	if err != nil {
+		return errors.Wrap(err, "error listing")
+	}
+	slice := reflect.MakeSlice(rv.Elem().Type(), 0, len(listed))
+	for _, entity := range listed {
+		slice = reflect.Append(slice, reflect.ValueOf(entity))
+	}
+	rv.Elem().Set(slice)
+	return nil
+}
+
+// ListOrgIDs fetches a list of organization ID's, i.e. the organizations holding at least one entity
+func (es *etcdEntityStore) ListOrgIDs(ctx context.Context) ([]string, error) {
+	resp, err := es.client.Get(ctx, es.prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing organizations")
+	}
+	orgIDs := []string{}
+	seen := map[string]bool{}
+	for _, kv := range resp.Kvs {
+		orgID, _, _, ok := es.parseKey(kv.Key)
+		if ok && !seen[orgID] {
+			seen[orgID] = true
+			orgIDs = append(orgIDs, orgID)
+		}
+	}
+	sort.Strings(orgIDs)
+	return orgIDs, nil
+}
+
+// Watch streams the changes of the entities of a single data type satisfying the filter, watched on the cluster
+func (es *etcdEntityStore) Watch(ctx context.Context, organizationID string, entityType reflect.Type, filter Filter) (<-chan Event, error) {
+	es.watcherOnce.Do(func() {
+		es.watcherErr = es.watch()
+	})
+	if es.watcherErr != nil {
+		return nil, es.watcherErr
+	}
+	w, err := es.broadcaster.watch(ctx, organizationID, entityType, filter)
+	if err != nil {
+		return nil, err
+	}
+	return w.events, nil
+}
+
+// watch starts watching the keys of the entities, until the store is closed. The client reconnects the watch when the
+// cluster is unreachable. The watch is recreated from the revision following the last change received when it is
+// canceled, the changes compacted meanwhile are lost.
+func (es *etcdEntityStore) watch() error {
+	// the watch starts at the current revision, the changes made before Watch returns are received
+	resp, err := es.client.Get(es.ctx, es.prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly(), clientv3.WithLimit(1))
+	if err != nil {
+		return errors.Wrap(err, "error watching the entities")
+	}
+	revision := resp.Header.Revision + 1
+
+	go func() {
+		for es.ctx.Err() == nil {
+			for resp := range es.client.Watch(es.ctx, es.prefix, clientv3.WithPrefix(), clientv3.WithRev(revision)) {
+				if resp.CompactRevision > 0 {
+					log.Warnf("entity events watch: the changes before revision %d were compacted", resp.CompactRevision)
+					revision = resp.CompactRevision
+				}
+				if err := resp.Err(); err != nil {
+					log.Warnf("entity events watch: %s", err)
+					continue
+				}
+				for _, event := range resp.Events {
+					es.dispatch(event)
+					revision = event.Kv.ModRevision + 1
+				}
+			}
+			select {
+			case <-es.ctx.Done():
+			case <-time.After(etcdWatchReconnect):
+			}
+		}
+	}()
+	return nil
+}
+
+// dispatch sends the event of a change of a key to the watches it matches
+func (es *etcdEntityStore) dispatch(event *clientv3.Event) {
+	organizationID, dt, name, ok := es.parseKey(event.Kv.Key)
+	if !ok {
+		return
+	}
+	eventType := EventUpdate
+	switch {
+	case event.Type == mvccpb.DELETE:
+		eventType = EventDelete
+	case event.IsCreate():
+		eventType = EventAdd
+	}
+
+	for _, w := range es.broadcaster.matching(organizationID, dt) {
+		if eventType == EventDelete {
+			es.broadcaster.send(w, Event{Type: eventType, Entity: deletedEntity(w.entityType, organizationID, name)})
+			continue
+		}
+		entity := reflect.New(w.entityType.Elem()).Interface().(Entity)
+		if err := json.Unmarshal(event.Kv.Value, entity); err != nil {
+			log.Errorf("error parsing the %s entity %s: %s", dt, name, err)
+			continue
+		}
+		entity.setRevision(uint64(event.Kv.ModRevision))
+		if w.filter != nil {
+			if ok, err := doFilter(w.filter, entity); err != nil || !ok {
+				continue
+			}
+		}
+		es.broadcaster.send(w, Event{Type: eventType, Entity: entity})
+	}
+}
+
+// Tx runs f with a transaction committed as a batch, in an etcd transaction. The transactions write at most the
+// --max-txn-ops of the cluster, 128 by default.
+func (es *etcdEntityStore) Tx(ctx context.Context, f func(tx EntityStore) error) error {
+	return runBatchTx(ctx, es, es.commit, f)
+}
+
+// commit applies the writes of a transaction if none of the entities changed meanwhile. The entities added are
+// granted their leases beforehand, which are revoked when the transaction fails.
+func (es *etcdEntityStore) commit(ctx context.Context, ops []*batchOp) (err error) {
+	var compares []clientv3.Cmp
+	var writes []clientv3.Op
+	var leases []clientv3.LeaseID
+	defer func() {
+		if err != nil {
+			for _, lease := range leases {
+				es.revokeLease(ctx, lease)
+			}
+		}
+	}()
+	for _, op := range ops {
+		key := es.prefix + op.key
+		switch op.eventType {
+		case EventAdd:
+			lease, err := es.grantLease(ctx, op.entity)
+			if err != nil {
+				return errors.Wrap(err, "error committing the transaction")
+			}
+			if lease != clientv3.NoLease {
+				leases = append(leases, lease)
+			}
+			compares = append(compares, clientv3.Compare(clientv3.CreateRevision(key), "=", 0))
+			writes = append(writes, clientv3.OpPut(key, string(op.data), clientv3.WithLease(lease)))
+		case EventUpdate:
+			compares = append(compares,
+				clientv3.Compare(clientv3.CreateRevision(key), ">", 0),
+				clientv3.Compare(clientv3.ModRevision(key), "=", int64(op.lastRevision)))
+			writes = append(writes, clientv3.OpPut(key, string(op.data), clientv3.WithIgnoreLease()))
+		case EventDelete:
+			compares = append(compares, clientv3.Compare(clientv3.CreateRevision(key), ">", 0))
+			writes = append(writes, clientv3.OpDelete(key, clientv3.WithPrevKV()))
+		}
+	}
+
+	resp, err := es.client.Txn(ctx).If(compares...).Then(writes...).Commit()
+	if err != nil {
+		return errors.Wrap(err, "error committing the transaction")
+	}
+	if !resp.Succeeded {
+		return errors.New("error committing the transaction: an entity was added, updated or deleted meanwhile")
+	}
+	for _, r := range resp.Responses {
+		if deleted := r.GetResponseDeleteRange(); deleted != nil {
+			es.revokeLeases(ctx, deleted.PrevKvs...)
+		}
+	}
+	for _, op := range ops {
+		if op.eventType != EventDelete {
+			op.entity.setRevision(uint64(resp.Header.Revision))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package entitystore
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/clientv3"
+
+	"github.com/vmware/dispatch/pkg/testing/dev"
+)
+
+var (
+	etcdConfig = BackendConfig{
+		Backend: "etcd",
+		Address: "localhost:2379",
+	}
+)
+
+// newTestEtcd returns an entity store of an empty bucket of the etcd cluster of the tests
+func newTestEtcd(t *testing.T, bucket string) *etcdEntityStore {
+	config := etcdConfig
+	config.Bucket = bucket
+	es, err := NewFromBackend(config)
+	require.NoError(t, err, "Cannot connect to etcd")
+	etcd := es.(*etcdEntityStore)
+	_, err = etcd.client.Delete(context.Background(), etcd.prefix, clientv3.WithPrefix())
+	require.NoError(t, err)
+	return etcd
+}
+
+func TestEtcdEntityStore(t *testing.T) {
+
+	dev.EnsureLocal(t)
+
+	es := newTestEtcd(t, "test")
+	defer es.Close()
+
+	testGet(t, es)
+	testAdd(t, es)
+	testPut(t, es)
+	testList(t, es)
+	testListWithFilter(t, es)
+	testListWithFilterOnTags(t, es)
+	testListWithFilterVerbs(t, es)
+	testDelete(t, es)
+	testInvalidNames(t, es)
+	testMixedTypes(t, es)
+	testListOrgIDs(t, es)
+	testListSortAndPage(t, es)
+	testWatch(t, es)
+	testTx(t, es)
+	testExpiring(t, es)
+}
+
+func TestEtcdEndpointFailover(t *testing.T) {
+
+	dev.EnsureLocal(t)
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	downAddress := strings.TrimPrefix(down.URL, "http://")
+
+	config := etcdConfig
+	config.Address = downAddress + "," + etcdConfig.Address
+	config.Bucket = "test-failover"
+	es, err := NewFromBackend(config)
+	require.NoError(t, err)
+	defer es.(*etcdEntityStore).Close()
+	_, err = es.Add(context.Background(), &testEntity{BaseEntity: BaseEntity{OrganizationID: "testOrg", Name: "testFailover"}})
+	assert.NoError(t, err)
+	require.NoError(t, es.Delete(context.Background(), "testOrg", "testFailover", &testEntity{}))
+
+	_, err = NewFromBackend(BackendConfig{Backend: "etcd", Address: downAddress})
+	assert.Error(t, err)
+}
+
+func TestEtcdWatchClose(t *testing.T) {
+
+	dev.EnsureLocal(t)
+
+	es := newTestEtcd(t, "test-watch")
+	events, err := es.Watch(context.Background(), "testOrg", reflect.TypeOf(&testEntity{}), nil)
+	require.NoError(t, err)
+	_, err = es.Add(context.Background(), &testEntity{BaseEntity: BaseEntity{OrganizationID: "testOrg", Name: "testWatchClose"}})
+	require.NoError(t, err)
+	assert.Equal(t, EventAdd, nextEvent(t, events).Type)
+
+	// the watch stops with the store, the changes made afterwards aren't received
+	require.NoError(t, es.Close())
+	other := newTestEtcd(t, "test-watch")
+	defer other.Close()
+	_, err = other.Add(context.Background(), &testEntity{BaseEntity: BaseEntity{OrganizationID: "testOrg", Name: "testWatchClosed"}})
+	require.NoError(t, err)
+	select {
+	case event := <-events:
+		t.Errorf("unexpected event %s of %s after the store closed", event.Type, event.Entity.GetName())
+	case <-time.After(time.Second):
+	}
+}
+
+type expiringEntity struct {
+	BaseEntity
+}
+
+func (e *expiringEntity) TTL() time.Duration {
+	return time.Second
+}
+
+func testExpiring(t *testing.T, es EntityStore) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := es.Watch(ctx, "testOrg", reflect.TypeOf(&expiringEntity{}), nil)
+	require.NoError(t, err)
+
+	e := &expiringEntity{BaseEntity: BaseEntity{OrganizationID: "testOrg", Name: "testExpiring"}}
+	_, err = es.Add(context.Background(), e)
+	require.NoError(t, err)
+	assert.Equal(t, EventAdd, nextEvent(t, events).Type)
+
+	// the entity is deleted once its lease expires
+	event := nextEvent(t, events)
+	assert.Equal(t, EventDelete, event.Type)
+	assert.Equal(t, "testExpiring", event.Entity.GetName())
+	found, err := es.Find(context.Background(), "testOrg", "testExpiring", Options{}, &expiringEntity{})
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestEtcdLeases(t *testing.T) {
+
+	dev.EnsureLocal(t)
+
+	es := newTestEtcd(t, "test-leases")
+	defer es.Close()
+	ctx := context.Background()
+	// the cluster may hold the leases of others, only those granted by the test are counted
+	leases := func() int {
+		resp, err := es.client.Leases(ctx)
+		require.NoError(t, err)
+		return len(resp.Leases)
+	}
+	granted := leases()
+
+	// the entity keeps the lease it was added with
+	e := &expiringEntity{BaseEntity: BaseEntity{OrganizationID: "testOrg", Name: "testLeases"}}
+	_, err := es.Add(ctx, e)
+	require.NoError(t, err)
+	_, err = es.Update(ctx, e.GetRevision(), e)
+	require.NoError(t, err)
+	assert.Equal(t, granted+1, leases())
+
+	// a failed add revokes its lease
+	_, err = es.Add(ctx, &expiringEntity{BaseEntity: BaseEntity{OrganizationID: "testOrg", Name: "testLeases"}})
+	assert.True(t, IsUniqueViolation(err))
+	assert.Equal(t, granted+1, leases())
+
+	// and deleting the entity revokes its lease
+	require.NoError(t, es.Delete(ctx, "testOrg", "testLeases", e))
+	assert.Equal(t, granted, leases())
+
+	err = es.Tx(ctx, func(tx EntityStore) error {
+		_, err := tx.Add(ctx, &expiringEntity{BaseEntity: BaseEntity{OrganizationID: "testOrg", Name: "testTxLeases"}})
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, granted+1, leases())
+	err = es.Tx(ctx, func(tx EntityStore) error {
+		return tx.Delete(ctx, "testOrg", "testTxLeases", &expiringEntity{})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, granted, leases())
+}
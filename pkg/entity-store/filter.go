@@ -5,6 +5,12 @@
 
 package entitystore
 
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+)
+
 const (
 	// FilterVerbIn tests containment
 	FilterVerbIn Verb = "in"
@@ -18,6 +24,30 @@ const (
 	// FilterVerbAfter tests two time.Time
 	FilterVerbAfter Verb = "after"
 
+	// FilterVerbNotEqual tests inequality
+	FilterVerbNotEqual Verb = "notEqual"
+
+	// FilterVerbNotIn tests non-containment
+	FilterVerbNotIn Verb = "notIn"
+
+	// FilterVerbExists tests whether a tag is set, the object is a bool: false tests the tag is not set
+	FilterVerbExists Verb = "exists"
+
+	// FilterVerbPrefix tests a string starts with the object
+	FilterVerbPrefix Verb = "prefix"
+
+	// FilterVerbContains tests a string contains the object
+	FilterVerbContains Verb = "contains"
+
+	// FilterVerbGreater tests a number is greater than the object, subjects which are not numbers do not match
+	FilterVerbGreater Verb = "greater"
+
+	// FilterVerbLess tests a number is less than the object, subjects which are not numbers do not match
+	FilterVerbLess Verb = "less"
+
+	// FilterVerbOr tests any of the filters of the object, a []Filter, matches. The scope and subject are not used.
+	FilterVerbOr Verb = "or"
+
 	// FilterScopeField defines that the subject is a BaseEntity field
 	FilterScopeField Scope = "field"
 
@@ -28,6 +58,12 @@ const (
 	FilterScopeExtra Scope = "extra"
 )
 
+// numberPattern matches the strings the 'greater' and 'less' verbs compare as numbers, in both the SQL and the
+// in-memory filters
+const numberPattern = `^[-+]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][-+]?[0-9]+)?$`
+
+var numberRegexp = regexp.MustCompile(numberPattern)
+
 // Verb describe the filter verb
 type Verb string
 
@@ -58,6 +94,14 @@ func FilterStatByApplication(name string) FilterStat {
 	}
 }
 
+// FilterStatOr defines one filter statement matching the entities any of the filters matches
+func FilterStatOr(filters ...Filter) FilterStat {
+	return FilterStat{
+		Verb:   FilterVerbOr,
+		Object: filters,
+	}
+}
+
 type filter struct {
 	statements []FilterStat
 }
@@ -96,3 +140,23 @@ func (f *filter) Add(stats ...FilterStat) Filter {
 func (f filter) FilterStats() []FilterStat {
 	return f.statements
 }
+
+// filterNumber returns the value of a number or of a string matching numberPattern, and whether it is a number
+func filterNumber(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.String:
+		if !numberRegexp.MatchString(rv.String()) {
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(rv.String(), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -9,6 +9,8 @@ import (
 	"context"
 	"encoding/json"
 	"reflect"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/libkv/store"
@@ -19,12 +21,17 @@ import (
 
 type libkvEntityStore struct {
 	kv store.Store
+	// libkv stores are not shared between processes, the changes are broadcast in process
+	broadcaster *broadcaster
+	// mu serializes the writes, the transactions are committed holding it
+	mu sync.Mutex
 }
 
 // newLibkv is the EntityStore constructor
 func newLibkv(kv store.Store) EntityStore {
 	return &libkvEntityStore{
-		kv: kv,
+		kv:          kv,
+		broadcaster: newBroadcaster(),
 	}
 }
 
@@ -50,6 +57,13 @@ func (*kvUniqueViolation) UniqueViolation() bool {
 	return true
 }
 
+const (
+	// orgIndexPrefix prefixes the keys indexing the organizations, it can't be part of an organization ID
+	orgIndexPrefix = "#organization/"
+	// defaultOrgID is the organization of the entities added before the organizations were indexed
+	defaultOrgID = "dispatch"
+)
+
 // Add adds new entities to the store
 func (es *libkvEntityStore) Add(ctx context.Context, entity Entity) (id string, err error) {
 	err = precondition(entity)
@@ -57,6 +71,9 @@ func (es *libkvEntityStore) Add(ctx context.Context, entity Entity) (id string,
 		return "", errors.Wrap(err, "Precondition failed")
 	}
 
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
 	key := getKey(entity)
 	exists, err := es.kv.Exists(key)
 	if err != nil && err != store.ErrKeyNotFound {
@@ -83,11 +100,20 @@ func (es *libkvEntityStore) Add(ctx context.Context, entity Entity) (id string,
 		return "", err
 	}
 	entity.setRevision(resp.LastIndex)
+
+	// libkv can't list the keys of the whole store, the organizations are indexed for ListOrgIDs
+	if err := es.kv.Put(orgIndexPrefix+entity.GetOrganizationID(), nil, nil); err != nil {
+		return "", errors.Wrap(err, "error indexing the organization")
+	}
+	es.broadcaster.publish(EventAdd, entity)
 	return id, nil
 }
 
 // Update updates existing entities to the store
 func (es *libkvEntityStore) Update(ctx context.Context, lastRevision uint64, entity Entity) (revision int64, err error) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
 	key := getKey(entity)
 
 	exists, err := es.kv.Exists(key)
@@ -113,14 +139,22 @@ func (es *libkvEntityStore) Update(ctx context.Context, lastRevision uint64, ent
 		return 0, err
 	}
 	entity.setRevision(kv.LastIndex)
+	es.broadcaster.publish(EventUpdate, entity)
 	return int64(kv.LastIndex), nil
 }
 
 // Delete delets a single entity from the store
 // entity should be a zero-value of entity to be deleted.
 func (es *libkvEntityStore) Delete(ctx context.Context, organizationID string, name string, entity Entity) error {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
 	key := buildKey(organizationID, getDataType(entity), name)
-	return es.kv.Delete(key)
+	if err := es.kv.Delete(key); err != nil {
+		return err
+	}
+	es.broadcaster.publish(EventDelete, deletedEntity(reflect.TypeOf(entity), organizationID, name))
+	return nil
 }
 
 // SoftDelete marks a single entity for deletion
@@ -170,9 +204,27 @@ func (es *libkvEntityStore) Get(ctx context.Context, organizationID string, name
 
 func doFilterStat(fs FilterStat, entity Entity) (bool, error) {
 
+	if fs.Verb == FilterVerbOr {
+		filters, ok := fs.Object.([]Filter)
+		if !ok {
+			return false, errors.Errorf("error filtering: object of a 'or' verb must be a slice of filters")
+		}
+		for _, f := range filters {
+			ok, err := doFilter(f, entity)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
 	rv := reflect.ValueOf(entity).Elem()
 
 	var subjectValue interface{}
+	subjectSet := true
 	switch fs.Scope {
 	case FilterScopeField, FilterScopeExtra:
 		field := rv.FieldByName(fs.Subject)
@@ -189,23 +241,61 @@ func doFilterStat(fs FilterStat, entity Entity) (bool, error) {
 		if !ok {
 			return false, errors.Errorf("unexpected error: should be the an instance of type Tags")
 		}
-		subjectValue = tags[fs.Subject]
+		value, ok := tags[fs.Subject]
+		subjectValue, subjectSet = value, ok
 	}
 
 	switch fs.Verb {
 	case FilterVerbEqual:
 		return reflect.DeepEqual(subjectValue, fs.Object), nil
-	case FilterVerbIn:
+	case FilterVerbNotEqual:
+		return !reflect.DeepEqual(subjectValue, fs.Object), nil
+	case FilterVerbIn, FilterVerbNotIn:
 		objects := reflect.ValueOf(fs.Object)
 		if objects.Kind() != reflect.Slice {
-			return false, errors.Errorf("error filtering: object of a 'in' operator must be a slice")
+			return false, errors.Errorf("error filtering: object of a '%s' operator must be a slice", fs.Verb)
 		}
 		for i := 0; i < objects.Len(); i++ {
 			if reflect.DeepEqual(subjectValue, objects.Index(i).Interface()) {
-				return true, nil
+				return fs.Verb == FilterVerbIn, nil
 			}
 		}
-		return false, nil
+		return fs.Verb == FilterVerbNotIn, nil
+	case FilterVerbExists:
+		if fs.Scope != FilterScopeTag {
+			return false, errors.Errorf("error filtering: subject of a 'exists' verb must be a tag")
+		}
+		exists, ok := fs.Object.(bool)
+		if !ok {
+			return false, errors.Errorf("error filtering: object of a 'exists' verb must be a bool")
+		}
+		return subjectSet == exists, nil
+	case FilterVerbPrefix, FilterVerbContains:
+		object, ok := fs.Object.(string)
+		if !ok {
+			return false, errors.Errorf("error filtering: object of a '%s' verb must be a string", fs.Verb)
+		}
+		subject := reflect.ValueOf(subjectValue)
+		if subject.Kind() != reflect.String {
+			return false, errors.Errorf("error filtering: subject of a '%s' verb must be a string", fs.Verb)
+		}
+		if fs.Verb == FilterVerbPrefix {
+			return strings.HasPrefix(subject.String(), object), nil
+		}
+		return strings.Contains(subject.String(), object), nil
+	case FilterVerbGreater, FilterVerbLess:
+		object, ok := filterNumber(fs.Object)
+		if !ok {
+			return false, errors.Errorf("error filtering: object of a '%s' verb must be a number", fs.Verb)
+		}
+		subject, ok := filterNumber(subjectValue)
+		if !ok {
+			return false, nil
+		}
+		if fs.Verb == FilterVerbGreater {
+			return subject > object, nil
+		}
+		return subject < object, nil
 	case FilterVerbBefore, FilterVerbAfter:
 		// must be time.Time
 		object, ok := fs.Object.(time.Time)
@@ -239,10 +329,23 @@ func doFilter(filter Filter, entity Entity) (bool, error) {
 	return true, nil
 }
 
-// ListOrgIDs fetches a list of organization ID's
+// ListOrgIDs fetches a list of organization ID's, i.e. the organizations which held entities
 func (es *libkvEntityStore) ListOrgIDs(ctx context.Context) ([]string, error) {
-	// TODO: return org entities when they are implemented. Until then return a default value.
-	return []string{"dispatch"}, nil
+	// entities added before organizations were indexed are all in the default organization
+	orgIDs := []string{defaultOrgID}
+	kvs, err := es.kv.List(orgIndexPrefix)
+	if err != nil {
+		if err == store.ErrKeyNotFound {
+			return orgIDs, nil
+		}
+		return nil, errors.Wrap(err, "error listing organizations")
+	}
+	for _, kv := range kvs {
+		if orgID := strings.TrimPrefix(kv.Key, orgIndexPrefix); orgID != defaultOrgID {
+			orgIDs = append(orgIDs, orgID)
+		}
+	}
+	return orgIDs, nil
 }
 
 // List fetches a list of entities of a single data type satisfying the filter.
@@ -262,13 +365,10 @@ func (es *libkvEntityStore) List(ctx context.Context, organizationID string, opt
 
 	key := buildKey(organizationID, dataType(elemType.Elem().Name()))
 	kvs, err := es.kv.List(key)
-	if err != nil {
-		if err == store.ErrKeyNotFound {
-			rv.Elem().Set(slice)
-			return nil
-		}
+	if err != nil && err != store.ErrKeyNotFound {
 		return err
 	}
+	var listed []Entity
 	for _, kv := range kvs {
 		obj := reflect.New(elemType.Elem())
 		entity := obj.Interface().(Entity)
@@ -287,10 +387,104 @@ func (es *libkvEntityStore) List(ctx context.Context, organizationID string, opt
 			}
 		}
 		entity.setRevision(kv.LastIndex)
+		listed = append(listed, entity)
+	}
 
-		slice = reflect.Append(slice, obj)
+	listed, err = sortAndPage(listed, opts)
+	if err != nil {
+		return errors.Wrap(err, "error listing")
+	}
+	for _, entity := range listed {
+		slice = reflect.Append(slice, reflect.ValueOf(entity))
 	}
 	rv.Elem().Set(slice)
 
 	return nil
 }
+
+// Watch streams the changes of the entities of a single data type satisfying the filter, made in this process
+func (es *libkvEntityStore) Watch(ctx context.Context, organizationID string, entityType reflect.Type, filter Filter) (<-chan Event, error) {
+	w, err := es.broadcaster.watch(ctx, organizationID, entityType, filter)
+	if err != nil {
+		return nil, err
+	}
+	return w.events, nil
+}
+
+// Tx runs f with a transaction committed as a batch
+func (es *libkvEntityStore) Tx(ctx context.Context, f func(tx EntityStore) error) error {
+	return runBatchTx(ctx, es, es.commit, f)
+}
+
+// commit applies the writes of a transaction. The writes are checked before any is applied, and as the writes of the
+// store are serialized they then apply. The writes applied are reverted if one fails nonetheless.
+func (es *libkvEntityStore) commit(ctx context.Context, ops []*batchOp) error {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	previous := make([]*store.KVPair, len(ops))
+	for i, op := range ops {
+		kv, err := es.kv.Get(op.key)
+		if err == store.ErrKeyNotFound {
+			kv, err = nil, nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "error committing the transaction")
+		}
+		switch {
+		case op.eventType == EventAdd && kv != nil:
+			return &kvUniqueViolation{op.key}
+		case op.eventType == EventUpdate && kv == nil:
+			return errors.Errorf("Entity not found, cannot update")
+		case op.eventType == EventUpdate && kv.LastIndex != op.lastRevision:
+			return errors.Wrapf(store.ErrKeyModified, "error updating entity %s", op.key)
+		case op.eventType == EventDelete && kv == nil:
+			return errors.New("error deleting: no such entity")
+		}
+		previous[i] = kv
+	}
+
+	for i, op := range ops {
+		if err := es.apply(op, previous[i]); err != nil {
+			es.revert(ops[:i], previous[:i])
+			return errors.Wrap(err, "error committing the transaction")
+		}
+	}
+	for _, op := range ops {
+		if op.eventType == EventAdd {
+			if err := es.kv.Put(orgIndexPrefix+op.entity.GetOrganizationID(), nil, nil); err != nil {
+				log.Errorf("error indexing the organization %s: %s", op.entity.GetOrganizationID(), err)
+			}
+		}
+		es.broadcaster.publish(op.eventType, op.entity)
+	}
+	return nil
+}
+
+// apply applies a write of a transaction
+func (es *libkvEntityStore) apply(op *batchOp, previous *store.KVPair) error {
+	if op.eventType == EventDelete {
+		return es.kv.Delete(op.key)
+	}
+	_, kv, err := es.kv.AtomicPut(op.key, op.data, previous, &store.WriteOptions{IsDir: false})
+	if err != nil {
+		return err
+	}
+	op.entity.setRevision(kv.LastIndex)
+	return nil
+}
+
+// revert restores the entities written by a transaction which failed
+func (es *libkvEntityStore) revert(ops []*batchOp, previous []*store.KVPair) {
+	for i := len(ops) - 1; i >= 0; i-- {
+		var err error
+		if previous[i] == nil {
+			err = es.kv.Delete(ops[i].key)
+		} else {
+			err = es.kv.Put(ops[i].key, previous[i].Value, nil)
+		}
+		if err != nil {
+			log.Errorf("error reverting the write of %s: %s", ops[i].key, err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package entitystore
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// migrationsLockID is the key of the advisory lock the replicas sharing a database take in turn to migrate it
+const migrationsLockID = 4012
+
+// Migration is a forward change of the schema of the postgres entity store
+type Migration struct {
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+}
+
+type migration struct {
+	Migration
+	sql string
+}
+
+// migrations are applied in order, once. The first two are the schema created before the migrations were tracked, they
+// are idempotent for the databases created then.
+var migrations = []migration{
+	{Migration{1, "create the entity table"}, `
+	CREATE TABLE IF NOT EXISTS entity (
+		key 			TEXT PRIMARY KEY,
+		id 				TEXT,
+		name 			TEXT,
+		type			TEXT,
+		organization_id TEXT,
+		created_time 	TIMESTAMP,
+		modified_time 	TIMESTAMP,
+		revision 		BIGINT,
+		version 		BIGINT,
+		status 			TEXT,
+		delete 			TEXT,
+		spec 			JSONB,
+		reason 			JSONB,
+		tags			JSONB,
+		value 			JSONB
+	)`},
+	// the changes are notified to all the replicas sharing the database
+	{Migration{2, "notify the changes of the entities"}, `
+	CREATE OR REPLACE FUNCTION entity_notify() RETURNS TRIGGER AS $$
+	DECLARE
+		changed RECORD;
+	BEGIN
+		IF TG_OP = 'DELETE' THEN
+			changed := OLD;
+		ELSE
+			changed := NEW;
+		END IF;
+		PERFORM pg_notify('` + entityEventsChannel + `', json_build_object(
+			'op', TG_OP,
+			'type', changed.type,
+			'organizationId', changed.organization_id,
+			'name', changed.name)::TEXT);
+		RETURN NULL;
+	END;
+	$$ LANGUAGE plpgsql;
+
+	DO $$
+	BEGIN
+		IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'entity_notify') THEN
+			CREATE TRIGGER entity_notify AFTER INSERT OR UPDATE OR DELETE ON entity
+			FOR EACH ROW EXECUTE PROCEDURE entity_notify();
+		END IF;
+	END;
+	$$`},
+	{Migration{3, "store the delete flag as a boolean"}, `
+	ALTER TABLE entity ALTER COLUMN delete TYPE BOOLEAN USING COALESCE(delete, 'false')::BOOLEAN`},
+	// the controllers resync the entities of a type by status, and the tags are filtered by containment
+	{Migration{4, "index the entities by organization, type and status, by modified time, and by tags"}, `
+	CREATE INDEX IF NOT EXISTS entity_organization_type_status ON entity (organization_id, type, status);
+	CREATE INDEX IF NOT EXISTS entity_modified_time ON entity (modified_time);
+	CREATE INDEX IF NOT EXISTS entity_tags ON entity USING GIN (tags)`},
+}
+
+// Migrate applies the pending migrations of the schema of the entity store of a backend, and returns them. With
+// dryRun, the pending migrations are returned but not applied. Only the postgres backend has a schema, the other
+// backends have no migrations.
+func Migrate(config BackendConfig, dryRun bool) ([]Migration, error) {
+	if config.Backend != "postgres" {
+		return nil, nil
+	}
+	db, _, err := connectPostgres(config)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return migrate(db, dryRun)
+}
+
+// migrate applies the pending migrations of a postgres database in one transaction, rolled back with dryRun
+func migrate(db *sqlx.DB, dryRun bool) ([]Migration, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, errors.Wrap(err, "error starting the schema migration")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, migrationsLockID); err != nil {
+		return nil, errors.Wrap(err, "error locking the schema migration")
+	}
+	_, err = tx.Exec(`
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version 		INTEGER PRIMARY KEY,
+		description 	TEXT,
+		applied_time 	TIMESTAMP
+	)`)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating the schema migrations table")
+	}
+	var version int
+	if err := tx.Get(&version, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`); err != nil {
+		return nil, errors.Wrap(err, "error getting the schema version")
+	}
+	if latest := migrations[len(migrations)-1].Version; version > latest {
+		log.Warnf("the entity store schema version %d is newer than the latest migration %d", version, latest)
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if m.Version <= version {
+			continue
+		}
+		pending = append(pending, m.Migration)
+		if dryRun {
+			continue
+		}
+		log.Infof("migrating the entity store schema to version %d: %s", m.Version, m.Description)
+		if _, err := tx.Exec(m.sql); err != nil {
+			return nil, errors.Wrapf(err, "error migrating the schema to version %d", m.Version)
+		}
+		_, err := tx.Exec(`INSERT INTO schema_migrations (version, description, applied_time) VALUES ($1, $2, $3)`,
+			m.Version, m.Description, time.Now().UTC())
+		if err != nil {
+			return nil, errors.Wrapf(err, "error recording the schema version %d", m.Version)
+		}
+	}
+	if dryRun {
+		return pending, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "error committing the schema migration")
+	}
+	return pending, nil
+}
@@ -5,7 +5,175 @@
 
 package entitystore
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
 // Options defines a set of query options for list and get
 type Options struct {
 	Filter Filter
+	// Sort is the field the entities listed are sorted by, a BaseEntity field of type string or time.Time, prefixed
+	// with "-" for a descending order. The entities are sorted by name by default.
+	Sort string
+	// Page, if set, lists a page of the entities
+	Page *Page
+}
+
+// Page defines a page of a listing
+type Page struct {
+	// Limit is the maximum number of entities listed, 0 for no limit
+	Limit int
+	// Continue is the token of the page, empty for the first page. Listing sets it to the token of the next page, or
+	// to empty if the page is the last.
+	Continue string
+}
+
+// pageToken is the position of a page in a listing, the sort value and name of the entity preceding it
+type pageToken struct {
+	Sort  string `json:"sort"`
+	Value string `json:"value"`
+	Name  string `json:"name"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// sortField returns the field and the order of the sort, and whether it is valid
+func sortField(s string) (field string, descending bool, valid bool) {
+	if s == "" {
+		return "Name", false, true
+	}
+	descending = strings.HasPrefix(s, "-")
+	field = strings.TrimPrefix(s, "-")
+	if _, ok := reflect.TypeOf(dbEntity{}).FieldByName(field); !ok {
+		return "", false, false
+	}
+	f, ok := reflect.TypeOf(BaseEntity{}).FieldByName(field)
+	if !ok || (f.Type.Kind() != reflect.String && f.Type != timeType) {
+		return "", false, false
+	}
+	return field, descending, true
+}
+
+// Validate validates the sort and page options
+func (o Options) Validate() error {
+	if _, _, ok := sortField(o.Sort); !ok {
+		return errors.Errorf("invalid sort field: %s", o.Sort)
+	}
+	if o.Page == nil {
+		return nil
+	}
+	if o.Page.Limit < 0 {
+		return errors.Errorf("invalid page limit: %d", o.Page.Limit)
+	}
+	_, err := o.pageToken()
+	return err
+}
+
+// pageToken decodes the token of the page, nil for the first page
+func (o Options) pageToken() (*pageToken, error) {
+	if o.Page == nil || o.Page.Continue == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(o.Page.Continue)
+	if err != nil {
+		return nil, errors.New("invalid continue token")
+	}
+	var token pageToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, errors.New("invalid continue token")
+	}
+	if token.Sort != o.Sort {
+		return nil, errors.New("invalid continue token: the sort changed")
+	}
+	return &token, nil
+}
+
+// sortValue returns the value of the sort field of an entity, formatted as in the page tokens
+func sortValue(entity Entity, field string) string {
+	v := reflect.ValueOf(entity).Elem().FieldByName(field).Interface()
+	if t, ok := v.(time.Time); ok {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return reflect.ValueOf(v).String()
+}
+
+// nextPage sets the token of the page following the last entity listed
+func (o Options) nextPage(last Entity) error {
+	field, _, _ := sortField(o.Sort)
+	data, err := json.Marshal(pageToken{Sort: o.Sort, Value: sortValue(last, field), Name: last.GetName()})
+	if err != nil {
+		return errors.Wrap(err, "error encoding the continue token")
+	}
+	o.Page.Continue = base64.RawURLEncoding.EncodeToString(data)
+	return nil
+}
+
+// compareEntities compares the entities by the sort field then by name, the values of time fields are compared as
+// times
+func compareEntities(field string, value1, name1, value2, name2 string) int {
+	c := 0
+	if f, _ := reflect.TypeOf(BaseEntity{}).FieldByName(field); f.Type == timeType {
+		t1, _ := time.Parse(time.RFC3339Nano, value1)
+		t2, _ := time.Parse(time.RFC3339Nano, value2)
+		switch {
+		case t1.Before(t2):
+			c = -1
+		case t1.After(t2):
+			c = 1
+		}
+	} else {
+		c = strings.Compare(value1, value2)
+	}
+	if c == 0 {
+		c = strings.Compare(name1, name2)
+	}
+	return c
+}
+
+// sortAndPage sorts the entities listed in memory, and returns the page of the options
+func sortAndPage(entities []Entity, opts Options) ([]Entity, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	field, descending, _ := sortField(opts.Sort)
+	values := make(map[Entity]string, len(entities))
+	for _, e := range entities {
+		values[e] = sortValue(e, field)
+	}
+	order := func(value1, name1, value2, name2 string) int {
+		c := compareEntities(field, value1, name1, value2, name2)
+		if descending {
+			return -c
+		}
+		return c
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		return order(values[entities[i]], entities[i].GetName(), values[entities[j]], entities[j].GetName()) < 0
+	})
+	if opts.Page == nil {
+		return entities, nil
+	}
+
+	token, _ := opts.pageToken()
+	if token != nil {
+		start := sort.Search(len(entities), func(i int) bool {
+			return order(values[entities[i]], entities[i].GetName(), token.Value, token.Name) > 0
+		})
+		entities = entities[start:]
+	}
+	opts.Page.Continue = ""
+	if opts.Page.Limit > 0 && len(entities) > opts.Page.Limit {
+		entities = entities[:opts.Page.Limit]
+		if err := opts.nextPage(entities[len(entities)-1]); err != nil {
+			return nil, err
+		}
+	}
+	return entities, nil
 }
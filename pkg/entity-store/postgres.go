@@ -7,11 +7,13 @@ package entitystore
 
 import (
 	"context"
+	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -26,6 +28,28 @@ import (
 
 type postgresEntityStore struct {
 	db *sqlx.DB
+	// ext runs the queries, the database or the transaction of the store
+	ext sqlExecutor
+	// tx is the transaction of the store, nil out of transactions
+	tx *sqlx.Tx
+	// conn is the connection string, the changes are listened to on a dedicated connection
+	conn string
+	// listener is shared by the transactions of the store
+	listener *pgListener
+}
+
+// sqlExecutor runs queries, *sqlx.DB and *sqlx.Tx implement it
+type sqlExecutor interface {
+	sqlx.Ext
+	NamedExec(query string, arg interface{}) (sql.Result, error)
+	Select(dest interface{}, query string, args ...interface{}) error
+}
+
+// pgListener listens to the changes of the entities for the watches of a store
+type pgListener struct {
+	broadcaster *broadcaster
+	once        sync.Once
+	err         error
 }
 
 type dbEntity struct {
@@ -98,36 +122,11 @@ func (v *Tags) Scan(src interface{}) error {
 	return scan(v, src)
 }
 
-func (p *postgresEntityStore) createTable() error {
-
-	sql := `
-		CREATE TABLE IF NOT EXISTS entity (
-		key 			TEXT PRIMARY KEY,
-		id 				TEXT,
-		name 			TEXT,
-		type			TEXT,
-		organization_id TEXT,
-		created_time 	TIMESTAMP,
-		modified_time 	TIMESTAMP,
-		revision 		BIGINT,
-		version 		BIGINT,
-		status 			TEXT,
-		delete 			TEXT,
-		spec 			JSONB,
-		reason 			JSONB,
-		tags			JSONB,
-		value 			JSONB
-	)`
-	_, err := p.db.Exec(sql)
-	if err != nil {
-		return errors.Wrap(err, "fail to create the entity table")
-	}
-	return nil
-}
-
 func (p *postgresEntityStore) dropTable() error {
 	sql := `
-	DROP TABLE IF EXISTS entity`
+	DROP TABLE IF EXISTS entity;
+	DROP TABLE IF EXISTS schema_migrations;
+	DROP FUNCTION IF EXISTS entity_notify()`
 	_, err := p.db.Exec(sql)
 	if err != nil {
 		log.Debug(err)
@@ -144,23 +143,39 @@ func getHostAndPort(addr string) (string, string, error) {
 	return res[0], res[1], nil
 }
 
-// newPostgres creates a postgres entity store
-func newPostgres(config BackendConfig) (EntityStore, error) {
+// connectPostgres connects to the postgres database of a backend, and returns the connection string
+func connectPostgres(config BackendConfig) (*sqlx.DB, string, error) {
 
 	opts := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable", config.Username, config.Password, config.Address, config.Bucket)
 	log.Debugf("postgresql database options: %s", opts)
 	db, err := sqlx.Connect("postgres", opts)
 	if err != nil {
 		log.Debugf("error connecting to postgresql DB")
-		return nil, errors.Wrap(err, "Unable to connect to the postgres db server")
+		return nil, "", errors.Wrap(err, "Unable to connect to the postgres db server")
 	}
-	store := &postgresEntityStore{db: db}
+	return db, opts, nil
+}
+
+// ConnectPostgres connects to the postgres database of a backend, for the services sharing the database of their
+// entity store
+func ConnectPostgres(config BackendConfig) (*sqlx.DB, error) {
+	db, _, err := connectPostgres(config)
+	return db, err
+}
 
-	// create tables if not exists
-	err = store.createTable()
+// newPostgres creates a postgres entity store
+func newPostgres(config BackendConfig) (EntityStore, error) {
+
+	db, opts, err := connectPostgres(config)
 	if err != nil {
 		return nil, err
 	}
+	store := &postgresEntityStore{db: db, ext: db, conn: opts, listener: &pgListener{broadcaster: newBroadcaster()}}
+
+	// create or migrate the tables
+	if _, err = migrate(db, false); err != nil {
+		return nil, err
+	}
 	return store, nil
 }
 
@@ -248,7 +263,7 @@ func (p *postgresEntityStore) Add(ctx context.Context, entity Entity) (id string
 	VALUES
 		(:key, :id, :name, :type, :organization_id, :created_time, :modified_time, :revision, :version,
 		:spec, :status, :reason, :tags, :delete, :value)`
-	_, err = p.db.NamedExec(sql, row)
+	_, err = p.ext.NamedExec(sql, row)
 	if err != nil {
 		if err, ok := err.(*pq.Error); ok {
 			if err.Code.Name() == "unique_violation" {
@@ -282,7 +297,7 @@ func (p *postgresEntityStore) Update(ctx context.Context, lastRevision uint64, e
 		return 0, err
 	}
 
-	result, err := p.db.NamedExec(sql, row)
+	result, err := p.ext.NamedExec(sql, row)
 	if err != nil {
 		return 0, errors.Wrap(err, "error updating entity")
 	}
@@ -313,15 +328,16 @@ func (p *postgresEntityStore) Find(ctx context.Context, organizationID string, n
 		Object:  key,
 	})
 
-	sql, args, err := makeListQuery(organizationID, opts.Filter, reflect.TypeOf(entity).Elem())
+	sql, args, err := makeListQuery(organizationID, Options{Filter: opts.Filter}, reflect.TypeOf(entity).Elem())
 	if err != nil {
 		return false, errors.Wrap(err, "error makeListQuery")
 	}
-	sql = p.db.Rebind(sql)
-	rows, err := p.db.Queryx(sql, args...)
+	sql = p.ext.Rebind(sql)
+	rows, err := p.ext.Queryx(sql, args...)
 	if err != nil {
 		return false, errors.Wrap(err, "error getting: ")
 	}
+	defer rows.Close()
 
 	if rows.Next() == false {
 		return false, nil
@@ -349,7 +365,127 @@ func (p *postgresEntityStore) Get(ctx context.Context, organizationID string, na
 	return nil
 }
 
-func makeListQuery(organizationID string, filter Filter, entityType reflect.Type) (sql string, args []interface{}, err error) {
+// filterConditions makes the SQL conditions of the statements of a filter, adding their arguments to argsMap
+func filterConditions(filter Filter, entityType reflect.Type, argsMap map[string]interface{}) ([]string, error) {
+	var where []string
+	for _, fs := range filter.FilterStats() {
+		// the arguments are numbered, the statements may have the same subject. They are never removed from argsMap, the
+		// arguments not in the query are ignored.
+		object := fmt.Sprintf("filter_%d", len(argsMap))
+		argsMap[object] = fs.Object
+
+		if fs.Verb == FilterVerbOr {
+			filters, ok := fs.Object.([]Filter)
+			if !ok {
+				return nil, errors.Errorf("error listing: object of a 'or' verb must be a slice of filters")
+			}
+			groups := []string{}
+			for _, f := range filters {
+				conditions, err := filterConditions(f, entityType, argsMap)
+				if err != nil {
+					return nil, err
+				}
+				if len(conditions) == 0 {
+					conditions = []string{"TRUE"}
+				}
+				groups = append(groups, fmt.Sprintf("(%s)", strings.Join(conditions, " AND ")))
+			}
+			if len(groups) == 0 {
+				groups = []string{"FALSE"}
+			}
+			where = append(where, fmt.Sprintf("(%s)", strings.Join(groups, " OR ")))
+			continue
+		}
+
+		column := ""
+		switch fs.Scope {
+		case FilterScopeField:
+			field, ok := reflect.TypeOf(dbEntity{}).FieldByName(fs.Subject)
+			if !ok {
+				return nil, errors.Errorf("error listing: no such field: %s", fs.Subject)
+			}
+			// find the column name by struct tag
+			column = field.Tag.Get("db")
+		case FilterScopeTag:
+			// the tag keys are user input, they are passed as arguments
+			key := fmt.Sprintf("%s_key", object)
+			argsMap[key] = fs.Subject
+			column = fmt.Sprintf("tags->>CAST(:%s AS text)", key)
+		case FilterScopeExtra:
+			field, ok := entityType.FieldByName(fs.Subject)
+			if !ok {
+				return nil, errors.Errorf("error listing: no such extra field: %s", fs.Subject)
+			}
+			// remove the "omitempty"
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			// the value is inside the JSONB field 'value'
+			column = fmt.Sprintf("value->>'%s'", name)
+		}
+
+		switch fs.Verb {
+		case FilterVerbEqual:
+			if _, ok := fs.Object.(string); ok && fs.Scope == FilterScopeTag {
+				// by containment, which the GIN index of the tags serves
+				where = append(where, fmt.Sprintf("tags @> jsonb_build_object(CAST(:%s_key AS text), CAST(:%s AS text))", object, object))
+				break
+			}
+			where = append(where, fmt.Sprintf("%s = :%s", column, object))
+		case FilterVerbNotEqual:
+			where = append(where, fmt.Sprintf("%s IS DISTINCT FROM :%s", column, object))
+		case FilterVerbIn:
+			where = append(where, fmt.Sprintf("%s IN (:%s)", column, object))
+		case FilterVerbNotIn:
+			where = append(where, fmt.Sprintf("(%s IS NULL OR %s NOT IN (:%s))", column, column, object))
+		case FilterVerbBefore:
+			where = append(where, fmt.Sprintf("%s < :%s", column, object))
+		case FilterVerbAfter:
+			where = append(where, fmt.Sprintf("%s > :%s", column, object))
+		case FilterVerbExists:
+			exists, ok := fs.Object.(bool)
+			if !ok || fs.Scope != FilterScopeTag {
+				return nil, errors.Errorf("error listing: a 'exists' verb must have a tag subject and a bool object")
+			}
+			if exists {
+				where = append(where, fmt.Sprintf("%s IS NOT NULL", column))
+			} else {
+				where = append(where, fmt.Sprintf("%s IS NULL", column))
+			}
+		case FilterVerbPrefix, FilterVerbContains:
+			value, ok := fs.Object.(string)
+			if !ok {
+				return nil, errors.Errorf("error listing: object of a '%s' verb must be a string", fs.Verb)
+			}
+			value = likeEscaper.Replace(value) + "%"
+			if fs.Verb == FilterVerbContains {
+				value = "%" + value
+			}
+			argsMap[object] = value
+			where = append(where, fmt.Sprintf("%s LIKE :%s", column, object))
+		case FilterVerbGreater, FilterVerbLess:
+			value, ok := filterNumber(fs.Object)
+			if !ok {
+				return nil, errors.Errorf("error listing: object of a '%s' verb must be a number", fs.Verb)
+			}
+			argsMap[object] = value
+			argsMap["number_pattern"] = numberPattern
+			compare := ">"
+			if fs.Verb == FilterVerbLess {
+				compare = "<"
+			}
+			// the values which are not numbers are NULL, and do not match
+			where = append(where, fmt.Sprintf("CASE WHEN CAST(%s AS text) ~ :number_pattern THEN CAST(CAST(%s AS text) AS numeric) END %s :%s",
+				column, column, compare, object))
+		default:
+			return nil, errors.Errorf("error listing: invalid filter")
+		}
+	}
+	return where, nil
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func makeListQuery(organizationID string, opts Options, entityType reflect.Type) (sql string, args []interface{}, err error) {
 
 	sql = ""
 	argsMap := map[string]interface{}{
@@ -360,52 +496,38 @@ func makeListQuery(organizationID string, filter Filter, entityType reflect.Type
 		"organization_id = :organization_id",
 		"type = :type",
 	}
-	if filter != nil {
-		for _, fs := range filter.FilterStats() {
-			column := ""
-			object := ""
-			switch fs.Scope {
-			case FilterScopeField:
-				field, ok := reflect.TypeOf(dbEntity{}).FieldByName(fs.Subject)
-				if !ok {
-					err = errors.Errorf("error listing: no such field: %s", fs.Subject)
-					return
-				}
-				// find the column name by struct tag
-				column = field.Tag.Get("db")
-				object = column
-			case FilterScopeTag:
-				object = fmt.Sprintf("tag_%s", fs.Subject)
-				column = fmt.Sprintf("tags->>'%s'", fs.Subject)
-			case FilterScopeExtra:
-				field, ok := entityType.FieldByName(fs.Subject)
-				if !ok {
-					err = errors.Errorf("error listing: no such extra field: %s", fs.Subject)
-					return
-				}
-				// remove the "omitempty"
-				object = strings.Split(field.Tag.Get("json"), ",")[0]
-				// the value is inside the JSONB field 'value'
-				column = fmt.Sprintf("value->>'%s'", object)
-			}
-			argsMap[object] = fs.Object
-
-			switch fs.Verb {
-			case FilterVerbEqual:
-				where = append(where, fmt.Sprintf("%s = :%s", column, object))
-			case FilterVerbIn:
-				where = append(where, fmt.Sprintf("%s IN (:%s)", column, object))
-			case FilterVerbBefore:
-				where = append(where, fmt.Sprintf("%s < :%s", column, object))
-			case FilterVerbAfter:
-				where = append(where, fmt.Sprintf("%s > :%s", column, object))
-			default:
-				err = errors.Errorf("error listing: invalid filter")
-				return
-			}
+	if err = opts.Validate(); err != nil {
+		return
+	}
+	if opts.Filter != nil {
+		var conditions []string
+		conditions, err = filterConditions(opts.Filter, entityType, argsMap)
+		if err != nil {
+			return
 		}
+		where = append(where, conditions...)
+	}
+
+	// the entities are sorted by name after the sort field, the pages start after the entity of the continue token
+	field, descending, _ := sortField(opts.Sort)
+	sortColumn, _ := reflect.TypeOf(dbEntity{}).FieldByName(field)
+	order, compare := "ASC", ">"
+	if descending {
+		order, compare = "DESC", "<"
+	}
+	token, _ := opts.pageToken()
+	if token != nil {
+		argsMap["page_value"] = token.Value
+		argsMap["page_name"] = token.Name
+		where = append(where, fmt.Sprintf("(%s, name) %s (:page_value, :page_name)", sortColumn.Tag.Get("db"), compare))
+	}
+	sql = fmt.Sprintf("SELECT * FROM entity WHERE %s ORDER BY %s %s, name %s",
+		strings.Join(where, " AND "), sortColumn.Tag.Get("db"), order, order)
+	if opts.Page != nil && opts.Page.Limit > 0 {
+		// one more entity is fetched to know whether the page is the last
+		argsMap["page_limit"] = opts.Page.Limit + 1
+		sql += " LIMIT :page_limit"
 	}
-	sql = fmt.Sprintf("SELECT * FROM entity WHERE %s", strings.Join(where, " AND "))
 	sql, args, err = sqlx.Named(sql, argsMap)
 	if err != nil {
 		err = errors.Wrap(err, "error making sql query: sqlx.Named")
@@ -419,10 +541,16 @@ func makeListQuery(organizationID string, filter Filter, entityType reflect.Type
 	return
 }
 
-// ListOrgIDs fetches a list of organization ID's
+// ListOrgIDs fetches a list of organization ID's, i.e. the organizations holding at least one entity
 func (p *postgresEntityStore) ListOrgIDs(ctx context.Context) ([]string, error) {
-	// TODO: return org entities when they are implemented. Until then return a default value.
-	return []string{"dispatch"}, nil
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	orgIDs := []string{}
+	if err := p.ext.Select(&orgIDs, `SELECT DISTINCT organization_id FROM entity ORDER BY organization_id`); err != nil {
+		return nil, errors.Wrap(err, "error listing organizations from db")
+	}
+	return orgIDs, nil
 }
 
 // List fetches a list of entities of a single data type satisfying the filter.
@@ -441,16 +569,17 @@ func (p *postgresEntityStore) List(ctx context.Context, organizationID string, o
 		return errors.New("non-entity element type: maybe use pointers")
 	}
 
-	sql, args, err := makeListQuery(organizationID, opts.Filter, entityPtrType.Elem())
+	sql, args, err := makeListQuery(organizationID, opts, entityPtrType.Elem())
 	if err != nil {
 		return errors.Wrap(err, "error makeListQuery")
 	}
 
-	sql = p.db.Rebind(sql)
-	rows, err := p.db.Queryx(sql, args...)
+	sql = p.ext.Rebind(sql)
+	rows, err := p.ext.Queryx(sql, args...)
 	if err != nil {
 		return errors.Wrap(err, "error listing entity from db")
 	}
+	defer rows.Close()
 
 	slice := reflect.MakeSlice(rv.Elem().Type(), 0, 0)
 	for rows.Next() {
@@ -466,6 +595,15 @@ func (p *postgresEntityStore) List(ctx context.Context, organizationID string, o
 		dbToEntity(row, entity)
 		slice = reflect.Append(slice, entityPtr)
 	}
+	if opts.Page != nil {
+		opts.Page.Continue = ""
+		if opts.Page.Limit > 0 && slice.Len() > opts.Page.Limit {
+			slice = slice.Slice(0, opts.Page.Limit)
+			if err := opts.nextPage(slice.Index(opts.Page.Limit - 1).Interface().(Entity)); err != nil {
+				return err
+			}
+		}
+	}
 	rv.Elem().Set(slice)
 	return nil
 }
@@ -478,7 +616,7 @@ func (p *postgresEntityStore) Delete(ctx context.Context, organizationID string,
 	key := buildKey(organizationID, getDataType(entity), name)
 
 	sql := `DELETE FROM entity WHERE key = $1`
-	result, err := p.db.Exec(sql, key)
+	result, err := p.ext.Exec(sql, key)
 	if err != nil {
 		return errors.Wrap(err, "error deleting an entity")
 	}
@@ -522,3 +660,119 @@ func (p *postgresEntityStore) UpdateWithError(ctx context.Context, e Entity, err
 	}
 	return
 }
+
+// Tx runs f with a store querying a SQL transaction
+func (p *postgresEntityStore) Tx(ctx context.Context, f func(tx EntityStore) error) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	if p.tx != nil {
+		return f(p)
+	}
+	tx, err := p.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "error starting a transaction")
+	}
+	// rolled back unless committed
+	defer tx.Rollback()
+
+	if err := f(&postgresEntityStore{db: p.db, ext: tx, tx: tx, conn: p.conn, listener: p.listener}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "error committing the transaction")
+	}
+	return nil
+}
+
+const (
+	// entityEventsChannel is the channel the changes of the entities are notified on
+	entityEventsChannel = "entity_events"
+
+	listenerMinReconnect = 10 * time.Second
+	listenerMaxReconnect = time.Minute
+)
+
+// pgNotification is the payload of the notifications of the entity_notify trigger
+type pgNotification struct {
+	Op             string `json:"op"`
+	Type           string `json:"type"`
+	OrganizationID string `json:"organizationId"`
+	Name           string `json:"name"`
+}
+
+// Watch streams the changes of the entities of a single data type satisfying the filter, notified by the database
+func (p *postgresEntityStore) Watch(ctx context.Context, organizationID string, entityType reflect.Type, filter Filter) (<-chan Event, error) {
+	p.listener.once.Do(func() {
+		p.listener.err = p.listen()
+	})
+	if p.listener.err != nil {
+		return nil, p.listener.err
+	}
+	w, err := p.listener.broadcaster.watch(ctx, organizationID, entityType, filter)
+	if err != nil {
+		return nil, err
+	}
+	return w.events, nil
+}
+
+// listen starts listening to the notifications of the changes of the entities. The notifications sent while the
+// listener reconnects are lost.
+func (p *postgresEntityStore) listen() error {
+	listener := pq.NewListener(p.conn, listenerMinReconnect, listenerMaxReconnect, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Warnf("entity events listener: %s", err)
+		}
+	})
+	if err := listener.Listen(entityEventsChannel); err != nil {
+		listener.Close()
+		return errors.Wrap(err, "error listening to the entity events")
+	}
+	go func() {
+		for n := range listener.NotificationChannel() {
+			// a nil notification signals a reconnection
+			if n == nil {
+				continue
+			}
+			p.dispatch(n.Extra)
+		}
+	}()
+	return nil
+}
+
+// dispatch sends the event of a notification to the watches it matches
+func (p *postgresEntityStore) dispatch(payload string) {
+	var n pgNotification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		log.Errorf("error parsing the entity event %s: %s", payload, err)
+		return
+	}
+	eventType := EventUpdate
+	switch n.Op {
+	case "INSERT":
+		eventType = EventAdd
+	case "DELETE":
+		eventType = EventDelete
+	}
+
+	for _, w := range p.listener.broadcaster.matching(n.OrganizationID, dataType(n.Type)) {
+		if eventType == EventDelete {
+			p.listener.broadcaster.send(w, Event{Type: eventType, Entity: deletedEntity(w.entityType, n.OrganizationID, n.Name)})
+			continue
+		}
+		// the entity is fetched for each watch, Find adds to the filter it is given
+		filter := FilterEverything()
+		if w.filter != nil {
+			filter.Add(w.filter.FilterStats()...)
+		}
+		entity := reflect.New(w.entityType.Elem()).Interface().(Entity)
+		found, err := p.Find(context.Background(), n.OrganizationID, n.Name, Options{Filter: filter}, entity)
+		if err != nil {
+			log.Errorf("error fetching the %s entity %s: %+v", n.Type, n.Name, err)
+			continue
+		}
+		if found {
+			p.listener.broadcaster.send(w, Event{Type: eventType, Entity: entity})
+		}
+	}
+}
@@ -106,6 +106,12 @@ type Entity interface {
 	getKey(dataType) string
 }
 
+// Expiring is implemented by the entities which expire. The etcd backend deletes them once their TTL elapsed since
+// they were added, the other backends keep them.
+type Expiring interface {
+	TTL() time.Duration
+}
+
 // BaseEntity is the base struct for all stored objects
 type BaseEntity struct {
 	ID             string          `json:"id"`
@@ -279,6 +285,15 @@ type EntityStore interface {
 	UpdateWithError(ctx context.Context, e Entity, err error)
 	// ListOrgIds fetches a list of organization IDs
 	ListOrgIDs(ctx context.Context) ([]string, error)
+	// Watch streams the changes of the entities of a single data type satisfying the filter, including the changes
+	// made by other replicas sharing the store. entityType is the pointer type of the entities, organizationID can be
+	// WatchAllOrganizations. The channel is closed when the context is done, or when the consumer does not keep up and
+	// events are lost: the consumer then watches again and lists the entities it missed the changes of.
+	Watch(ctx context.Context, organizationID string, entityType reflect.Type, filter Filter) (<-chan Event, error)
+	// Tx runs f with a store whose writes are applied atomically when f returns nil, and discarded when it returns an
+	// error, which Tx returns. The reads of the store see the writes of the transaction. The revisions of the entities
+	// written may only be set once the transaction is committed. Tx called within a transaction runs f in it.
+	Tx(ctx context.Context, f func(tx EntityStore) error) error
 }
 
 type uniqueViolation interface {
@@ -311,6 +326,13 @@ func NewFromBackend(config BackendConfig) (EntityStore, error) {
 		}
 		return es, nil
 
+	case "etcd":
+		es, err := newEtcd(config)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error creating a(n) %s entity store", config.Backend)
+		}
+		return es, nil
+
 	case string(store.BOLTDB):
 		boltdb.Register()
 		kv, err := libkv.NewStore(
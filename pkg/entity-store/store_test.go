@@ -60,9 +60,14 @@ func TestPostgresEntityStore(t *testing.T) {
 	testList(t, es)
 	testListWithFilter(t, es)
 	testListWithFilterOnTags(t, es)
+	testListWithFilterVerbs(t, es)
 	testDelete(t, es)
 	testInvalidNames(t, es)
 	testMixedTypes(t, es)
+	testListOrgIDs(t, es)
+	testListSortAndPage(t, es)
+	testWatch(t, es)
+	testTx(t, es)
 }
 
 func TestLibkvEntityStore(t *testing.T) {
@@ -85,9 +90,14 @@ func TestLibkvEntityStore(t *testing.T) {
 	testList(t, es)
 	testListWithFilter(t, es)
 	testListWithFilterOnTags(t, es)
+	testListWithFilterVerbs(t, es)
 	testDelete(t, es)
 	testInvalidNames(t, es)
 	testMixedTypes(t, es)
+	testListOrgIDs(t, es)
+	testListSortAndPage(t, es)
+	testWatch(t, es)
+	testTx(t, es)
 
 	os.Remove(file.Name())
 }
@@ -348,6 +358,81 @@ func testListWithFilterOnTags(t *testing.T, es EntityStore) {
 	es.Delete(context.Background(), "testOrg", testFoo.Name, testFoo)
 }
 
+type sizedEntity struct {
+	BaseEntity
+	Size int `json:"size"`
+}
+
+func testListWithFilterVerbs(t *testing.T, es EntityStore) {
+	ctx := context.Background()
+	for i, name := range []string{"web-1", "web-2", "db-1", "db_x"} {
+		e := &sizedEntity{
+			BaseEntity: BaseEntity{
+				OrganizationID: "testVerbOrg",
+				Name:           name,
+				Status:         StatusREADY,
+				Tags:           Tags{"weight": fmt.Sprintf("%d", i*10)},
+			},
+			Size: i,
+		}
+		if i%2 == 0 {
+			e.Tags["tier"] = "front"
+		}
+		_, err := es.Add(ctx, e)
+		require.NoError(t, err)
+	}
+
+	list := func(stats ...FilterStat) []string {
+		var result []*sizedEntity
+		err := es.List(ctx, "testVerbOrg", Options{Filter: FilterEverything().Add(stats...)}, &result)
+		require.NoError(t, err)
+		names := []string{}
+		for _, e := range result {
+			names = append(names, e.Name)
+		}
+		return names
+	}
+	name := func(verb Verb, object interface{}) FilterStat {
+		return FilterStat{Scope: FilterScopeField, Subject: "Name", Verb: verb, Object: object}
+	}
+	tag := func(key string, verb Verb, object interface{}) FilterStat {
+		return FilterStat{Scope: FilterScopeTag, Subject: key, Verb: verb, Object: object}
+	}
+	size := func(verb Verb, object interface{}) FilterStat {
+		return FilterStat{Scope: FilterScopeExtra, Subject: "Size", Verb: verb, Object: object}
+	}
+
+	assert.Equal(t, []string{"db-1", "db_x", "web-2"}, list(name(FilterVerbNotEqual, "web-1")))
+	assert.Equal(t, []string{"db_x", "web-1"}, list(name(FilterVerbNotIn, []string{"web-2", "db-1"})))
+	assert.Equal(t, []string{"db_x", "web-2"}, list(tag("tier", FilterVerbNotEqual, "front")))
+	assert.Equal(t, []string{"db_x", "web-2"}, list(tag("tier", FilterVerbNotIn, []string{"front"})))
+	assert.Equal(t, []string{"db-1", "web-1"}, list(tag("tier", FilterVerbExists, true)))
+	assert.Equal(t, []string{"db_x", "web-2"}, list(tag("tier", FilterVerbExists, false)))
+	assert.Equal(t, []string{"web-1", "web-2"}, list(name(FilterVerbPrefix, "web")))
+	assert.Equal(t, []string{"db_x"}, list(name(FilterVerbContains, "_")))
+	assert.Equal(t, []string{"db-1", "web-1"}, list(tag("tier", FilterVerbPrefix, "fr"), name(FilterVerbContains, "-1")))
+	assert.Equal(t, []string{"db-1", "db_x"}, list(size(FilterVerbGreater, 1)))
+	assert.Equal(t, []string{"web-1"}, list(size(FilterVerbLess, 0.5)))
+	assert.Equal(t, []string{"db-1", "web-2"}, list(tag("weight", FilterVerbGreater, "5"), tag("weight", FilterVerbLess, 25)))
+	assert.Equal(t, []string{}, list(tag("tier", FilterVerbGreater, 0)))
+	assert.Equal(t, []string{"db_x", "web-1"}, list(FilterStatOr(
+		FilterEverything().Add(name(FilterVerbEqual, "web-1")),
+		FilterEverything().Add(size(FilterVerbGreater, 2)),
+	)))
+	assert.Equal(t, []string{"db-1"}, list(
+		tag("tier", FilterVerbExists, true),
+		FilterStatOr(
+			FilterEverything().Add(name(FilterVerbPrefix, "db")),
+			FilterEverything().Add(name(FilterVerbPrefix, "api")),
+		),
+	))
+	assert.Equal(t, []string{}, list(FilterStatOr()))
+
+	for _, n := range []string{"web-1", "web-2", "db-1", "db_x"} {
+		es.Delete(ctx, "testVerbOrg", n, &sizedEntity{})
+	}
+}
+
 func testListWithFilter(t *testing.T, es EntityStore) {
 
 	testTimeBeforeEntity := &testEntity{
@@ -483,6 +568,30 @@ func testMixedTypes(t *testing.T, es EntityStore) {
 	assert.NoError(t, err, "Error clean up")
 }
 
+func testListOrgIDs(t *testing.T, es EntityStore) {
+	for _, orgID := range []string{"orgA", "orgB"} {
+		e := &testEntity{
+			BaseEntity: BaseEntity{
+				OrganizationID: orgID,
+				Name:           "testEntityOrg",
+			},
+		}
+		_, err := es.Add(context.Background(), e)
+		assert.NoError(t, err, "Error adding entity")
+	}
+
+	orgIDs, err := es.ListOrgIDs(context.Background())
+	assert.NoError(t, err)
+	assert.Contains(t, orgIDs, "orgA")
+	assert.Contains(t, orgIDs, "orgB")
+
+	// clean up
+	for _, orgID := range []string{"orgA", "orgB"} {
+		err = es.Delete(context.Background(), orgID, "testEntityOrg", &testEntity{})
+		assert.NoError(t, err, "Error clean up")
+	}
+}
+
 func Test_getType(t *testing.T) {
 	var something interface{} = &BaseEntity{}
 
@@ -491,6 +600,36 @@ func Test_getType(t *testing.T) {
 	assert.True(t, reflect.TypeOf(something).Implements(eType))
 }
 
+func TestMigrations(t *testing.T) {
+	// the versions are applied in order, once
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.Version)
+		assert.NotEmpty(t, m.Description)
+	}
+
+	applied, err := Migrate(BackendConfig{Backend: "boltdb"}, false)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+}
+
+func Test_makeListQueryFilterVerbs(t *testing.T) {
+	filter := FilterEverything().Add(
+		FilterStat{Scope: FilterScopeTag, Subject: "tier'--", Verb: FilterVerbExists, Object: true},
+		FilterStat{Scope: FilterScopeField, Subject: "Name", Verb: FilterVerbPrefix, Object: "web_1%"},
+		FilterStatByApplication("shop"),
+		FilterStatOr(
+			FilterEverything().Add(FilterStat{Scope: FilterScopeExtra, Subject: "Size", Verb: FilterVerbGreater, Object: 2}),
+			FilterEverything().Add(FilterStat{Scope: FilterScopeField, Subject: "Status", Verb: FilterVerbNotIn, Object: []Status{StatusERROR}}),
+		),
+	)
+	sql, args, err := makeListQuery("testOrg", Options{Filter: filter}, reflect.TypeOf(sizedEntity{}))
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM entity WHERE organization_id = ? AND type = ? AND tags->>CAST(? AS text) IS NOT NULL "+
+		"AND name LIKE ? AND tags @> jsonb_build_object(CAST(? AS text), CAST(? AS text)) AND ((CASE WHEN CAST(value->>'size' AS text) ~ ? THEN CAST(CAST(value->>'size' AS text) AS numeric) END > ?) "+
+		"OR ((status IS NULL OR status NOT IN (?)))) ORDER BY name ASC, name ASC", sql)
+	assert.Equal(t, []interface{}{"testOrg", dataType("sizedEntity"), "tier'--", `web\_1\%%`, "Application", "shop", numberPattern, float64(2), StatusERROR}, args)
+}
+
 func testDelete(t *testing.T, es EntityStore) {
 
 	e := &testEntity{
@@ -514,3 +653,267 @@ func testDelete(t *testing.T, es EntityStore) {
 	err = es.Get(context.Background(), "testOrg", "testEntityDelete", Options{}, &retreived)
 	assert.Error(t, err)
 }
+
+func nextEvent(t *testing.T, events <-chan Event) Event {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for an event")
+	}
+	return Event{}
+}
+
+func testWatch(t *testing.T, es EntityStore) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	filter := FilterEverything().Add(FilterStat{
+		Scope:   FilterScopeField,
+		Subject: "Status",
+		Verb:    FilterVerbEqual,
+		Object:  StatusCREATING,
+	})
+	events, err := es.Watch(ctx, "testWatchOrg", reflect.TypeOf(&testEntity{}), filter)
+	require.NoError(t, err)
+	allEvents, err := es.Watch(ctx, WatchAllOrganizations, reflect.TypeOf(&testEntity{}), nil)
+	require.NoError(t, err)
+
+	_, err = es.Watch(ctx, "testWatchOrg", reflect.TypeOf(testEntity{}), nil)
+	assert.Error(t, err)
+
+	// neither the type nor the organization are watched
+	_, err = es.Add(context.Background(), &otherEntity{BaseEntity: BaseEntity{OrganizationID: "testWatchOrg", Name: "testWatchOther"}})
+	require.NoError(t, err)
+	_, err = es.Add(context.Background(), &testEntity{BaseEntity: BaseEntity{OrganizationID: "testWatchOtherOrg", Name: "testWatchOtherOrg"}})
+	require.NoError(t, err)
+	event := nextEvent(t, allEvents)
+	assert.Equal(t, EventAdd, event.Type)
+	assert.Equal(t, "testWatchOtherOrg", event.Entity.GetOrganizationID())
+
+	e := &testEntity{
+		BaseEntity: BaseEntity{
+			OrganizationID: "testWatchOrg",
+			Name:           "testWatch",
+			Status:         StatusCREATING,
+		},
+		Value: "testValue",
+	}
+	_, err = es.Add(context.Background(), e)
+	require.NoError(t, err)
+	event = nextEvent(t, events)
+	assert.Equal(t, EventAdd, event.Type)
+	assert.Equal(t, "testWatch", event.Entity.GetName())
+	assert.Equal(t, "testValue", event.Entity.(*testEntity).Value)
+	allEvent := nextEvent(t, allEvents)
+	assert.Equal(t, EventAdd, allEvent.Type)
+	// each watch gets its own copy of the entity
+	assert.False(t, event.Entity == allEvent.Entity)
+
+	// the update is filtered out
+	e.Status = StatusREADY
+	_, err = es.Update(context.Background(), e.GetRevision(), e)
+	require.NoError(t, err)
+	event = nextEvent(t, allEvents)
+	assert.Equal(t, EventUpdate, event.Type)
+	assert.Equal(t, StatusREADY, event.Entity.GetStatus())
+
+	e.Status = StatusCREATING
+	e.Value = "updatedValue"
+	_, err = es.Update(context.Background(), e.GetRevision(), e)
+	require.NoError(t, err)
+	event = nextEvent(t, events)
+	assert.Equal(t, EventUpdate, event.Type)
+	assert.Equal(t, "updatedValue", event.Entity.(*testEntity).Value)
+	assert.Equal(t, e.GetRevision(), event.Entity.GetRevision())
+	nextEvent(t, allEvents)
+
+	err = es.Delete(context.Background(), "testWatchOrg", "testWatch", &testEntity{})
+	require.NoError(t, err)
+	event = nextEvent(t, events)
+	assert.Equal(t, EventDelete, event.Type)
+	assert.Equal(t, "testWatchOrg", event.Entity.GetOrganizationID())
+	assert.Equal(t, "testWatch", event.Entity.GetName())
+	assert.IsType(t, &testEntity{}, event.Entity)
+
+	cancel()
+	for range events {
+	}
+	for range allEvents {
+	}
+}
+
+func TestWatchOverflow(t *testing.T) {
+	b := newBroadcaster()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w, err := b.watch(ctx, "testOrg", reflect.TypeOf(&testEntity{}), nil)
+	require.NoError(t, err)
+
+	// the watch not keeping up is ended once its buffer is full, after the events buffered
+	e := &testEntity{BaseEntity: BaseEntity{OrganizationID: "testOrg", Name: "testOverflow"}}
+	for i := 0; i <= watchBufferSize; i++ {
+		b.publish(EventUpdate, e)
+	}
+	received := 0
+	for range w.events {
+		received++
+	}
+	assert.Equal(t, watchBufferSize, received)
+	assert.Empty(t, b.matching("testOrg", getDataType(e)))
+
+	// ending the watch with the context doesn't close the channel again
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+}
+
+func testListSortAndPage(t *testing.T, es EntityStore) {
+
+	for _, name := range []string{"testPage-c", "testPage-a", "testPage-d", "testPage-b", "testPage-e"} {
+		e := &testEntity{
+			BaseEntity: BaseEntity{
+				OrganizationID: "testPageOrg",
+				Name:           name,
+				Status:         StatusREADY,
+			},
+		}
+		_, err := es.Add(context.Background(), e)
+		require.NoError(t, err)
+		// the modified times follow the order of the additions
+		time.Sleep(time.Millisecond)
+	}
+
+	names := func(entities []*testEntity) (names []string) {
+		for _, e := range entities {
+			names = append(names, e.Name)
+		}
+		return
+	}
+	listAll := func(opts Options) (all []string) {
+		for {
+			var entities []*testEntity
+			require.NoError(t, es.List(context.Background(), "testPageOrg", opts, &entities))
+			assert.True(t, len(entities) <= opts.Page.Limit)
+			all = append(all, names(entities)...)
+			if opts.Page.Continue == "" {
+				return
+			}
+		}
+	}
+
+	var entities []*testEntity
+	require.NoError(t, es.List(context.Background(), "testPageOrg", Options{}, &entities))
+	assert.Equal(t, []string{"testPage-a", "testPage-b", "testPage-c", "testPage-d", "testPage-e"}, names(entities))
+
+	page := &Page{Limit: 2}
+	entities = nil
+	require.NoError(t, es.List(context.Background(), "testPageOrg", Options{Page: page}, &entities))
+	assert.Equal(t, []string{"testPage-a", "testPage-b"}, names(entities))
+	assert.NotEmpty(t, page.Continue)
+
+	assert.Equal(t, []string{"testPage-a", "testPage-b", "testPage-c", "testPage-d", "testPage-e"}, listAll(Options{Page: &Page{Limit: 2}}))
+	assert.Equal(t, []string{"testPage-e", "testPage-d", "testPage-c", "testPage-b", "testPage-a"}, listAll(Options{Sort: "-Name", Page: &Page{Limit: 3}}))
+	assert.Equal(t, []string{"testPage-c", "testPage-a", "testPage-d", "testPage-b", "testPage-e"}, listAll(Options{Sort: "ModifiedTime", Page: &Page{Limit: 2}}))
+
+	// the page limit applies after filtering
+	filter := FilterEverything().Add(FilterStat{
+		Scope:   FilterScopeField,
+		Subject: "Name",
+		Verb:    FilterVerbIn,
+		Object:  []string{"testPage-b", "testPage-d", "testPage-e"},
+	})
+	assert.Equal(t, []string{"testPage-b", "testPage-d", "testPage-e"}, listAll(Options{Filter: filter, Page: &Page{Limit: 1}}))
+
+	entities = nil
+	assert.Error(t, es.List(context.Background(), "testPageOrg", Options{Sort: "Tags"}, &entities))
+	assert.Error(t, es.List(context.Background(), "testPageOrg", Options{Sort: "Value"}, &entities))
+	assert.Error(t, es.List(context.Background(), "testPageOrg", Options{Page: &Page{Continue: "invalid"}}, &entities))
+	assert.Error(t, es.List(context.Background(), "testPageOrg", Options{Sort: "-Name", Page: &Page{Continue: page.Continue}}, &entities))
+
+	// an empty listing is the last page
+	page = &Page{Limit: 2, Continue: page.Continue}
+	require.NoError(t, es.List(context.Background(), "testPageEmptyOrg", Options{Page: page}, &entities))
+	assert.Empty(t, entities)
+	assert.Empty(t, page.Continue)
+}
+
+func testTx(t *testing.T, es EntityStore) {
+	ctx := context.Background()
+	existing := &testEntity{BaseEntity: BaseEntity{OrganizationID: "testTxOrg", Name: "testTxExisting"}, Value: "existing"}
+	_, err := es.Add(ctx, existing)
+	require.NoError(t, err)
+
+	// the writes are committed together, and are seen in the transaction
+	err = es.Tx(ctx, func(tx EntityStore) error {
+		for _, name := range []string{"testTx1", "testTx2"} {
+			if _, err := tx.Add(ctx, &testEntity{BaseEntity: BaseEntity{OrganizationID: "testTxOrg", Name: name}}); err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(ctx, "testTxOrg", "testTxExisting", &testEntity{}); err != nil {
+			return err
+		}
+		var listed []*testEntity
+		if err := tx.List(ctx, "testTxOrg", Options{}, &listed); err != nil {
+			return err
+		}
+		require.Len(t, listed, 2)
+		assert.Equal(t, "testTx1", listed[0].Name)
+		assert.Equal(t, "testTx2", listed[1].Name)
+
+		// the nested transactions run in the transaction
+		return tx.Tx(ctx, func(tx EntityStore) error {
+			var e testEntity
+			if err := tx.Get(ctx, "testTxOrg", "testTx1", Options{}, &e); err != nil {
+				return err
+			}
+			e.Value = "updated"
+			_, err := tx.Update(ctx, e.Revision, &e)
+			return err
+		})
+	})
+	require.NoError(t, err)
+	var e testEntity
+	require.NoError(t, es.Get(ctx, "testTxOrg", "testTx1", Options{}, &e))
+	assert.Equal(t, "updated", e.Value)
+	assert.NotZero(t, e.Revision)
+	found, err := es.Find(ctx, "testTxOrg", "testTxExisting", Options{}, &testEntity{})
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	// none of the writes are committed when the function fails
+	err = es.Tx(ctx, func(tx EntityStore) error {
+		if _, err := tx.Add(ctx, &testEntity{BaseEntity: BaseEntity{OrganizationID: "testTxOrg", Name: "testTx3"}}); err != nil {
+			return err
+		}
+		return fmt.Errorf("failed")
+	})
+	assert.EqualError(t, err, "failed")
+	found, err = es.Find(ctx, "testTxOrg", "testTx3", Options{}, &testEntity{})
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	// nor when an entity changed meanwhile
+	stale := e
+	e.Value = "changed"
+	_, err = es.Update(ctx, e.Revision, &e)
+	require.NoError(t, err)
+	err = es.Tx(ctx, func(tx EntityStore) error {
+		if _, err := tx.Add(ctx, &testEntity{BaseEntity: BaseEntity{OrganizationID: "testTxOrg", Name: "testTx3"}}); err != nil {
+			return err
+		}
+		stale.Value = "stale"
+		_, err := tx.Update(ctx, stale.Revision, &stale)
+		return err
+	})
+	assert.Error(t, err)
+	found, err = es.Find(ctx, "testTxOrg", "testTx3", Options{}, &testEntity{})
+	assert.NoError(t, err)
+	assert.False(t, found)
+	require.NoError(t, es.Get(ctx, "testTxOrg", "testTx1", Options{}, &e))
+	assert.Equal(t, "changed", e.Value)
+
+	// clean up
+	for _, name := range []string{"testTx1", "testTx2"} {
+		assert.NoError(t, es.Delete(ctx, "testTxOrg", name, &testEntity{}), "Error clean up")
+	}
+}
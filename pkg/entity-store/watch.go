@@ -0,0 +1,172 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package entitystore
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// EventAdd is the event of an entity added to the store
+	EventAdd EventType = "add"
+
+	// EventUpdate is the event of an entity updated, including soft deletes
+	EventUpdate EventType = "update"
+
+	// EventDelete is the event of an entity deleted from the store
+	EventDelete EventType = "delete"
+
+	// WatchAllOrganizations watches the entities of all the organizations
+	WatchAllOrganizations = "*"
+
+	// watchBufferSize is the number of events buffered for each watch. Watches not keeping up are ended, see Watch.
+	watchBufferSize = 256
+)
+
+// EventType is the type of a change of an entity
+type EventType string
+
+// Event is a change of an entity
+type Event struct {
+	Type EventType
+	// Entity is the entity after the change. The entity of delete events only has its organization and name set.
+	Entity Entity
+}
+
+// watch is a watch of the entities of a data type
+type watch struct {
+	organizationID string
+	dataType       dataType
+	entityType     reflect.Type
+	filter         Filter
+	events         chan Event
+}
+
+// matches returns whether the watch is interested in the entities of an organization and data type
+func (w *watch) matches(organizationID string, dt dataType) bool {
+	return w.dataType == dt && (w.organizationID == WatchAllOrganizations || w.organizationID == organizationID)
+}
+
+// broadcaster dispatches the events of a store to its watches
+type broadcaster struct {
+	mu      sync.Mutex
+	watches map[*watch]struct{}
+}
+
+func newBroadcaster() *broadcaster {
+	return &broadcaster{watches: map[*watch]struct{}{}}
+}
+
+// watch registers a watch of the entities of a type, a pointer to an entity struct. The watch ends and the event
+// channel is closed when the context is done, or when the watch does not keep up with the events.
+func (b *broadcaster) watch(ctx context.Context, organizationID string, entityType reflect.Type, filter Filter) (*watch, error) {
+	if entityType == nil || entityType.Kind() != reflect.Ptr || !entityType.Implements(reflect.TypeOf((*Entity)(nil)).Elem()) {
+		return nil, errors.Errorf("error watching: %v is not an entity pointer type", entityType)
+	}
+	w := &watch{
+		organizationID: organizationID,
+		dataType:       dataType(entityType.Elem().Name()),
+		entityType:     entityType,
+		filter:         filter,
+		events:         make(chan Event, watchBufferSize),
+	}
+	b.mu.Lock()
+	b.watches[w] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		b.end(w)
+		b.mu.Unlock()
+	}()
+	return w, nil
+}
+
+// matching returns the watches interested in the entities of an organization and data type
+func (b *broadcaster) matching(organizationID string, dt dataType) []*watch {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var watches []*watch
+	for w := range b.watches {
+		if w.matches(organizationID, dt) {
+			watches = append(watches, w)
+		}
+	}
+	return watches
+}
+
+// end ends a watch and closes its event channel, unless the watch ended already. b.mu must be held.
+func (b *broadcaster) end(w *watch) {
+	if _, ok := b.watches[w]; !ok {
+		return
+	}
+	delete(b.watches, w)
+	close(w.events)
+}
+
+// send sends an event to a watch, unless the watch ended. Rather than blocking the store, a watch whose buffer is full
+// is ended, so its consumer knows the event is lost.
+func (b *broadcaster) send(w *watch, event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.watches[w]; !ok {
+		return
+	}
+	select {
+	case w.events <- event:
+	default:
+		log.Warnf("watch of %s not keeping up, dropping %s event of %s and ending the watch", w.dataType, event.Type, event.Entity.GetName())
+		b.end(w)
+	}
+}
+
+// publish sends the event of an entity changed in the process to the watches it matches. Each watch gets its own
+// copy of the entity, which is still owned by the caller.
+func (b *broadcaster) publish(eventType EventType, entity Entity) {
+	for _, w := range b.matching(entity.GetOrganizationID(), getDataType(entity)) {
+		copied, err := copyEntity(entity)
+		if err != nil {
+			log.Errorf("error publishing %s event of %s: %+v", eventType, entity.GetName(), err)
+			return
+		}
+		if eventType != EventDelete && w.filter != nil {
+			if ok, err := doFilter(w.filter, copied); err != nil || !ok {
+				continue
+			}
+		}
+		b.send(w, Event{Type: eventType, Entity: copied})
+	}
+}
+
+// copyEntity returns a deep copy of an entity
+func copyEntity(entity Entity) (Entity, error) {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, errors.Wrap(err, "serialization error, while copying")
+	}
+	copied := reflect.New(reflect.TypeOf(entity).Elem()).Interface().(Entity)
+	if err := json.Unmarshal(data, copied); err != nil {
+		return nil, errors.Wrap(err, "deserialization error, while copying")
+	}
+	return copied, nil
+}
+
+// deletedEntity returns the entity of a delete event
+func deletedEntity(entityType reflect.Type, organizationID string, name string) Entity {
+	entity := reflect.New(entityType.Elem()).Interface().(Entity)
+	entity.setOrganizationID(organizationID)
+	entity.setName(name)
+	return entity
+}
@@ -8,6 +8,7 @@ package eventmanager
 import (
 	"time"
 
+	"github.com/vmware/dispatch/pkg/client"
 	"github.com/vmware/dispatch/pkg/controller"
 	"github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/event-manager/drivers"
@@ -27,7 +28,7 @@ type EventControllerConfig struct {
 }
 
 // NewEventController creates a new controller to manage the reconciliation of event manager entities
-func NewEventController(manager subscriptions.Manager, backend drivers.Backend, store entitystore.EntityStore, config EventControllerConfig) controller.Controller {
+func NewEventController(manager subscriptions.Manager, backend drivers.Backend, secretsClient client.SecretsClient, store entitystore.EntityStore, config EventControllerConfig) controller.Controller {
 	if config.WorkerNumber == 0 {
 		config.WorkerNumber = defaultWorkerNumber
 	}
@@ -39,9 +40,10 @@ func NewEventController(manager subscriptions.Manager, backend drivers.Backend,
 	c := controller.NewController(controller.Options{
 		ResyncPeriod: config.ResyncPeriod,
 		Workers:      config.WorkerNumber,
+		Store:        store,
 	})
 
-	c.AddEntityHandler(drivers.NewEntityHandler(store, backend))
+	c.AddEntityHandler(drivers.NewEntityHandler(store, backend, secretsClient))
 	c.AddEntityHandler(subscriptions.NewEntityHandler(store, manager))
 
 	return c
@@ -9,6 +9,7 @@ import (
 	"context"
 	"testing"
 
+	clientmocks "github.com/vmware/dispatch/pkg/client/mocks"
 	"github.com/vmware/dispatch/pkg/entity-store"
 	mocks2 "github.com/vmware/dispatch/pkg/event-manager/drivers/mocks"
 	"github.com/vmware/dispatch/pkg/event-manager/subscriptions/entities"
@@ -21,7 +22,7 @@ func TestControllerRun(t *testing.T) {
 	k8sBackend := &mocks2.Backend{}
 	es := helpers.MakeEntityStore(t)
 
-	controller := NewEventController(manager, k8sBackend, es, EventControllerConfig{})
+	controller := NewEventController(manager, k8sBackend, &clientmocks.SecretsClient{}, es, EventControllerConfig{})
 	controller.Start()
 	controller.Shutdown()
 }
@@ -31,7 +32,7 @@ func TestControllerRunWithSubs(t *testing.T) {
 	k8sBackend := &mocks2.Backend{}
 	es := helpers.MakeEntityStore(t)
 
-	controller := NewEventController(manager, k8sBackend, es, EventControllerConfig{})
+	controller := NewEventController(manager, k8sBackend, &clientmocks.SecretsClient{}, es, EventControllerConfig{})
 	defer controller.Shutdown()
 	controller.Start()
 
@@ -23,6 +23,8 @@ type Driver struct {
 	Secrets []string          `json:"secrets,omitempty"`
 	Image   string            `json:"image"`
 	Mode    string            `josn:"mode"`
+	// SecretVersions are the versions of the secrets the driver is deployed with
+	SecretVersions map[string]int64 `json:"secretVersions,omitempty"`
 }
 
 // ToModel creates swagger model from the driver struct
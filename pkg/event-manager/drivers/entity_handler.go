@@ -14,6 +14,7 @@ import (
 	ewrapper "github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/vmware/dispatch/pkg/client"
 	"github.com/vmware/dispatch/pkg/controller"
 	"github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/event-manager/drivers/entities"
@@ -22,15 +23,17 @@ import (
 
 // EntityHandler handles driver entity operations
 type EntityHandler struct {
-	store   entitystore.EntityStore
-	backend Backend
+	store         entitystore.EntityStore
+	backend       Backend
+	secretsClient client.SecretsClient
 }
 
 // NewEntityHandler creates new instance of EntityHandler
-func NewEntityHandler(store entitystore.EntityStore, backend Backend) *EntityHandler {
+func NewEntityHandler(store entitystore.EntityStore, backend Backend, secretsClient client.SecretsClient) *EntityHandler {
 	return &EntityHandler{
-		store:   store,
-		backend: backend,
+		store:         store,
+		backend:       backend,
+		secretsClient: secretsClient,
 	}
 }
 
@@ -121,9 +124,36 @@ func (h *EntityHandler) Sync(ctx context.Context, resyncPeriod time.Duration) ([
 		return nil, err
 	}
 
+	// drivers deployed with outdated secrets are re-deployed with the current version
+	for _, e := range syncingEntities {
+		driver := e.(*entities.Driver)
+		if driver.Status == entitystore.StatusREADY && h.secretsOutdated(ctx, driver) {
+			log.Infof("%s-driver %s secrets changed, updating", driver.Type, driver.Name)
+			driver.Status = entitystore.StatusUPDATING
+		}
+	}
+
 	return syncingEntities, nil
 }
 
+// secretsOutdated checks whether any of the driver secrets has a newer version than the one it was deployed with.
+// Only the versions are listed, so the checks don't read the secret values.
+func (h *EntityHandler) secretsOutdated(ctx context.Context, driver *entities.Driver) bool {
+	for _, name := range driver.Secrets {
+		versions, err := h.secretsClient.ListSecretVersions(ctx, driver.OrganizationID, name)
+		if err != nil {
+			log.Warnf("error checking the version of secret %s of driver %s: %s", name, driver.Name, err)
+			continue
+		}
+		for _, version := range versions {
+			if version.Current && version.Version != driver.SecretVersions[name] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Error handles error state
 func (h *EntityHandler) Error(ctx context.Context, obj entitystore.Entity) error {
 	span, ctx := trace.Trace(ctx, "")
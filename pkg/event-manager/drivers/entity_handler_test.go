@@ -8,10 +8,13 @@ package drivers
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/vmware/dispatch/pkg/api/v1"
+	clientmocks "github.com/vmware/dispatch/pkg/client/mocks"
 	"github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/event-manager/drivers/entities"
 	"github.com/vmware/dispatch/pkg/event-manager/drivers/mocks"
@@ -20,8 +23,9 @@ import (
 
 func mockDriverHandler(backend Backend, es entitystore.EntityStore) *EntityHandler {
 	return &EntityHandler{
-		store:   es,
-		backend: backend,
+		store:         es,
+		backend:       backend,
+		secretsClient: &clientmocks.SecretsClient{},
 	}
 }
 
@@ -60,3 +64,46 @@ func TestDriverDelete(t *testing.T) {
 	es.List(context.Background(), "", entitystore.Options{}, drivers)
 	assert.Len(t, drivers, 0)
 }
+
+func TestDriverSyncSecretsOutdated(t *testing.T) {
+	backend := &mocks.Backend{}
+	es := helpers.MakeEntityStore(t)
+	handler := mockDriverHandler(backend, es)
+	secretsClient := &clientmocks.SecretsClient{}
+	handler.secretsClient = secretsClient
+	current := &entities.Driver{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: "dispatch",
+			Name:           "current",
+			Status:         entitystore.StatusREADY,
+		},
+		Type:           "vcenter",
+		Secrets:        []string{"vcenter-creds"},
+		SecretVersions: map[string]int64{"vcenter-creds": 2},
+	}
+	outdated := &entities.Driver{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: "dispatch",
+			Name:           "outdated",
+			Status:         entitystore.StatusREADY,
+		},
+		Type:           "vcenter",
+		Secrets:        []string{"vcenter-creds"},
+		SecretVersions: map[string]int64{"vcenter-creds": 1},
+	}
+	es.Add(context.Background(), current)
+	es.Add(context.Background(), outdated)
+	secretsClient.On("ListSecretVersions", mock.Anything, "dispatch", "vcenter-creds").Return([]v1.SecretVersion{
+		{Version: 1}, {Version: 2, Current: true},
+	}, nil)
+
+	// a negative resync period, so that the drivers just added are synced
+	synced, err := handler.Sync(context.Background(), -time.Minute)
+	assert.NoError(t, err)
+	statuses := make(map[string]entitystore.Status)
+	for _, e := range synced {
+		statuses[e.GetName()] = e.GetStatus()
+	}
+	assert.Equal(t, entitystore.StatusREADY, statuses["current"])
+	assert.Equal(t, entitystore.StatusUPDATING, statuses["outdated"])
+}
@@ -275,3 +275,43 @@ func TestDriversDeleteDriverTypeHandler(t *testing.T) {
 	getResponder = api.DriversGetDriverTypesHandler.Handle(get, "testCookie")
 	helpers.HandlerRequest(t, getResponder, &getBody, 200)
 }
+
+func TestDriverTypesOrganizationIsolation(t *testing.T) {
+	api := operations.NewEventManagerAPI(nil)
+	es := helpers.MakeEntityStore(t)
+	h := testHandlers(es)
+	helpers.MakeAPI(t, h.ConfigureHandlers, api)
+
+	add := drivers.AddDriverTypeParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/event/eventdrivertypes", nil),
+		Body: &v1.EventDriverType{
+			Name:  swag.String("mydrivertype"),
+			Image: swag.String("test/image:latest"),
+		},
+		XDispatchOrg: "org-a",
+	}
+	helpers.HandlerRequest(t, api.DriversAddDriverTypeHandler.Handle(add, "testCookie"), &v1.EventDriverType{}, 201)
+
+	// The driver types of org-a can't be used by the drivers of org-b
+	addDriver := drivers.AddDriverParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/event/eventdrivers", nil),
+		Body: &v1.EventDriver{
+			Name: swag.String("mydriver"),
+			Type: swag.String("mydrivertype"),
+		},
+		XDispatchOrg: "org-b",
+	}
+	helpers.HandlerRequest(t, api.DriversAddDriverHandler.Handle(addDriver, "testCookie"), &v1.Error{}, http.StatusBadRequest)
+
+	get := drivers.GetDriverTypeParams{
+		HTTPRequest:    httptest.NewRequest("GET", "/v1/event/eventdrivertypes/mydrivertype", nil),
+		DriverTypeName: "mydrivertype",
+		XDispatchOrg:   "org-b",
+	}
+	helpers.HandlerRequest(t, api.DriversGetDriverTypeHandler.Handle(get, "testCookie"), &v1.Error{}, http.StatusNotFound)
+
+	addDriver.XDispatchOrg = "org-a"
+	var driver v1.EventDriver
+	helpers.HandlerRequest(t, api.DriversAddDriverHandler.Handle(addDriver, "testCookie"), &driver, http.StatusCreated)
+	assert.Equal(t, "mydriver", *driver.Name)
+}
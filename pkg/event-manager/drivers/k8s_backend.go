@@ -147,7 +147,7 @@ func (k *k8sBackend) Deploy(ctx context.Context, driver *entities.Driver) error
 	span, ctx := trace.Trace(ctx, "")
 	defer span.Finish()
 
-	secrets, err := k.getSecrets(ctx, driver.Secrets)
+	secrets, err := k.getSecrets(ctx, driver)
 	if err != nil {
 		return ewrapper.Wrapf(err, "failed to retrieve secrets")
 	}
@@ -296,7 +296,7 @@ func getReasonForUnavailablePods(pods *corev1.PodList, fullname string) error {
 }
 
 func (k *k8sBackend) Update(ctx context.Context, driver *entities.Driver) error {
-	secrets, err := k.getSecrets(ctx, driver.Secrets)
+	secrets, err := k.getSecrets(ctx, driver)
 	if err != nil {
 		return ewrapper.Wrapf(err, "failed to retrieve secrets")
 	}
@@ -368,20 +368,25 @@ func (k *k8sBackend) Update(ctx context.Context, driver *entities.Driver) error
 	return nil
 }
 
-func (k *k8sBackend) getSecrets(ctx context.Context, secretNames []string) (map[string]string, error) {
+// getSecrets returns the values of the driver secrets, and records their versions on the driver
+func (k *k8sBackend) getSecrets(ctx context.Context, driver *entities.Driver) (map[string]string, error) {
 	span, ctx := trace.Trace(ctx, "")
 	defer span.Finish()
 
+	ctx = client.WithRequester(ctx, "deploy driver", client.Requester(client.RequesterDriver, driver.Name))
 	secrets := make(map[string]string)
-	for _, name := range secretNames {
-		resp, err := k.secretsClient.GetSecret(ctx, k.config.OrgID, name)
+	versions := make(map[string]int64)
+	for _, name := range driver.Secrets {
+		resp, err := k.secretsClient.GetSecret(ctx, driver.OrganizationID, name)
 		if err != nil {
 			return secrets, ewrapper.Wrapf(err, "failed to get secrets from secret store")
 		}
 		for key, value := range resp.Secrets {
 			secrets[key] = value
 		}
+		versions[name] = resp.Version
 	}
+	driver.SecretVersions = versions
 	return secrets, nil
 }
 
@@ -397,7 +402,7 @@ func (k *k8sBackend) buildSidecarEnv(d *entities.Driver) []corev1.EnvVar {
 		},
 		{
 			Name:  "DISPATCH_TENANT",
-			Value: k.config.OrgID,
+			Value: d.OrganizationID,
 		},
 		{
 			Name:  "DISPATCH_TRANSPORT",
@@ -56,7 +56,7 @@ func addSubscriptionEntityWithError(t *testing.T, api *operations.EventManagerAP
 func TestSubscriptionsAddSubscriptionHandlerError(t *testing.T) {
 	api := operations.NewEventManagerAPI(nil)
 	es := helpers.MakeEntityStore(t)
-	h := Handlers{"", es, nil}
+	h := Handlers{es, nil}
 	helpers.MakeAPI(t, h.ConfigureHandlers, api)
 
 	respBody := addSubscriptionEntityWithError(t, api, "test.topic", "testfunction")
@@ -67,7 +67,7 @@ func TestSubscriptionsAddSubscriptionHandlerError(t *testing.T) {
 func TestSubscriptionsAddSubscriptionHandler(t *testing.T) {
 	api := operations.NewEventManagerAPI(nil)
 	es := helpers.MakeEntityStore(t)
-	h := Handlers{"", es, nil}
+	h := Handlers{es, nil}
 	helpers.MakeAPI(t, h.ConfigureHandlers, api)
 
 	respBody := addSubscriptionEntity(t, api, "mysubscription", "test.topic", "testfunction")
@@ -78,7 +78,7 @@ func TestSubscriptionsAddSubscriptionHandler(t *testing.T) {
 func TestSubscriptionsGetSubscriptionHandler(t *testing.T) {
 	api := operations.NewEventManagerAPI(nil)
 	es := helpers.MakeEntityStore(t)
-	h := Handlers{"", es, nil}
+	h := Handlers{es, nil}
 	helpers.MakeAPI(t, h.ConfigureHandlers, api)
 
 	addBody := addSubscriptionEntity(t, api, "mysubscription", "test.topic", "testfunction")
@@ -114,7 +114,7 @@ func TestSubscriptionsGetSubscriptionHandler(t *testing.T) {
 func TestSubscriptionsDeleteSubscriptionHandler(t *testing.T) {
 	api := operations.NewEventManagerAPI(nil)
 	es := helpers.MakeEntityStore(t)
-	h := Handlers{"", es, nil}
+	h := Handlers{es, nil}
 	helpers.MakeAPI(t, h.ConfigureHandlers, api)
 
 	addBody := addSubscriptionEntity(t, api, "mysubscription", "test.topic", "testfunction")
@@ -143,3 +143,42 @@ func TestSubscriptionsDeleteSubscriptionHandler(t *testing.T) {
 	getResponder = api.SubscriptionsGetSubscriptionsHandler.Handle(get, "testCookie")
 	helpers.HandlerRequest(t, getResponder, &getBody, 200)
 }
+
+func TestSubscriptionsOrganizationIsolation(t *testing.T) {
+	api := operations.NewEventManagerAPI(nil)
+	es := helpers.MakeEntityStore(t)
+	h := Handlers{es, nil}
+	helpers.MakeAPI(t, h.ConfigureHandlers, api)
+
+	add := subscriptions.AddSubscriptionParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/event/subscriptions", nil),
+		Body: &v1.Subscription{
+			Name:       swag.String("mysubscription"),
+			EventType:  swag.String("test.topic"),
+			Function:   swag.String("testfunction"),
+			SourceType: swag.String("dispatch"),
+		},
+		XDispatchOrg: "org-a",
+	}
+	helpers.HandlerRequest(t, api.SubscriptionsAddSubscriptionHandler.Handle(add, "testCookie"), &v1.Subscription{}, 201)
+
+	get := subscriptions.GetSubscriptionParams{
+		HTTPRequest:      httptest.NewRequest("GET", "/v1/event/subscriptions/mysubscription", nil),
+		SubscriptionName: "mysubscription",
+		XDispatchOrg:     "org-b",
+	}
+	helpers.HandlerRequest(t, api.SubscriptionsGetSubscriptionHandler.Handle(get, "testCookie"), &v1.Error{}, 404)
+
+	list := subscriptions.GetSubscriptionsParams{
+		HTTPRequest:  httptest.NewRequest("GET", "/v1/event/subscriptions", nil),
+		XDispatchOrg: "org-b",
+	}
+	var listBody []v1.Subscription
+	helpers.HandlerRequest(t, api.SubscriptionsGetSubscriptionsHandler.Handle(list, "testCookie"), &listBody, 200)
+	assert.Empty(t, listBody)
+
+	get.XDispatchOrg = "org-a"
+	var getBody v1.Subscription
+	helpers.HandlerRequest(t, api.SubscriptionsGetSubscriptionHandler.Handle(get, "testCookie"), &getBody, 200)
+	assert.Equal(t, "mysubscription", *getBody.Name)
+}
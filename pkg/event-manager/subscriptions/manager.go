@@ -78,7 +78,7 @@ func (m *defaultManager) Create(ctx context.Context, sub *entities.Subscription)
 		delete(m.activeSubs, sub.ID)
 	}
 	topic := fmt.Sprintf("%s.%s", sub.SourceType, sub.EventType)
-	eventSub, err := m.queue.Subscribe(ctx, topic, m.handler(ctx, sub))
+	eventSub, err := m.queue.Subscribe(ctx, topic, sub.OrganizationID, m.handler(ctx, sub))
 	if err != nil {
 		err = errors.Wrapf(err, "unable to create a subscription for event %s and function %s", sub.EventType, sub.Function)
 		span.LogKV("error", err)
@@ -137,6 +137,8 @@ func (m *defaultManager) handler(ctx context.Context, sub *entities.Subscription
 		span.SetTag("eventType", sub.EventType)
 		span.SetTag("functionName", sub.Function)
 
+		// the function runs for the subscription, which may be allowed to read secrets the function itself can't
+		ctx = client.WithRequester(ctx, "event "+event.EventID, client.Requester(client.RequesterSubscription, sub.Name))
 		m.runFunction(ctx, sub.OrganizationID, sub.Function, event, sub.Secrets)
 	}
 }
@@ -158,6 +160,7 @@ func (m *defaultManager) runFunction(ctx context.Context, organizationID string,
 		Blocking:     false,
 		FunctionName: fnName,
 		Input:        processedData,
+		Secrets:      secrets,
 	}
 	eventCopy := *event
 	eventCopy.Data = ""
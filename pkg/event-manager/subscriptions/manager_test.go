@@ -10,11 +10,14 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	"github.com/vmware/dispatch/pkg/api/v1"
 	"github.com/vmware/dispatch/pkg/client"
 	clientmocks "github.com/vmware/dispatch/pkg/client/mocks"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/event-manager/subscriptions/entities"
 	"github.com/vmware/dispatch/pkg/events"
 	eventsmocks "github.com/vmware/dispatch/pkg/events/mocks"
 )
@@ -34,8 +37,24 @@ func TestRunFunction(t *testing.T) {
 	ev := &events.CloudEvent{}
 	fnClient.On("RunFunction", mock.Anything, "testOrg", mock.AnythingOfType("*v1.Run")).Return(&v1.Run{}, nil).Once()
 	manager.runFunction(context.Background(), "testOrg", "testFunction", ev, []string{"secret1", "secret2"})
+	assert.Equal(t, []string{"secret1", "secret2"}, fnClient.Calls[0].Arguments.Get(2).(*v1.Run).Secrets)
 
 	fnClient.On("RunFunction", mock.Anything, "testOrg", mock.AnythingOfType("*v1.Run")).Return(&v1.Run{}, errors.New("testerror")).Once()
 	manager.runFunction(context.Background(), "testOrg", "testFunction", ev, nil)
 	fnClient.AssertNumberOfCalls(t, "RunFunction", 2)
 }
+
+func TestHandlerRequester(t *testing.T) {
+	fnClient := &clientmocks.FunctionsClient{}
+	manager := mockSubscriptionManager(&eventsmocks.Transport{}, fnClient)
+	sub := &entities.Subscription{
+		BaseEntity: entitystore.BaseEntity{Name: "on-order", OrganizationID: "testOrg"},
+		Function:   "billing",
+	}
+	fnClient.On("RunFunction", mock.Anything, "testOrg", mock.AnythingOfType("*v1.Run")).Return(&v1.Run{}, nil).Once()
+	manager.handler(context.Background(), sub)(context.Background(), &events.CloudEvent{EventID: "42"})
+
+	requesters, purpose := client.RequesterFromContext(fnClient.Calls[0].Arguments.Get(0).(context.Context))
+	assert.Equal(t, []string{"subscription/on-order"}, requesters, "the function runs for the subscription")
+	assert.Equal(t, "event 42", purpose)
+}
@@ -59,7 +59,15 @@ func NewKafka(brokerAddrs []string, options ...func(k *Kafka) error) (*Kafka, er
 	return &k, err
 }
 
-// Publish publishes an event
+// tenantTopic returns the Kafka topic of a tenant, the tenants don't share topics
+func tenantTopic(topic string, tenant string) string {
+	if tenant == "" {
+		return topic
+	}
+	return tenant + "." + topic
+}
+
+// Publish publishes an event on the topic of the tenant
 func (k *Kafka) Publish(ctx context.Context, event *events.CloudEvent, topic string, tenant string) error {
 	span, ctx := trace.Trace(ctx, "")
 	defer span.Finish()
@@ -68,7 +76,7 @@ func (k *Kafka) Publish(ctx context.Context, event *events.CloudEvent, topic str
 	if err != nil {
 		return errors.Wrapf(err, "error when creating Kafka message from CloudEvent for topic %s", topic)
 	}
-	msg.Topic = topic
+	msg.Topic = tenantTopic(topic, tenant)
 
 	err = injectSpan(span, msg)
 	if err != nil {
@@ -81,8 +89,8 @@ func (k *Kafka) Publish(ctx context.Context, event *events.CloudEvent, topic str
 	return nil
 }
 
-// Subscribe subscribes to an event
-func (k *Kafka) Subscribe(ctx context.Context, topic string, handler events.Handler) (events.Subscription, error) {
+// Subscribe subscribes to the events of the topic of the tenant
+func (k *Kafka) Subscribe(ctx context.Context, topic string, tenant string, handler events.Handler) (events.Subscription, error) {
 	span, ctx := trace.Trace(ctx, "")
 	defer span.Finish()
 
@@ -90,6 +98,7 @@ func (k *Kafka) Subscribe(ctx context.Context, topic string, handler events.Hand
 
 	// create partition consumer. Since we are creating only one consumer, we should automatically consume messages
 	// from all partitions regardless of the partition number we select
+	topic = tenantTopic(topic, tenant)
 	partitionConsumer, err := k.consumer.ConsumePartition(topic, 0, sarama.OffsetNewest)
 	if err != nil {
 		return nil, errors.Wrapf(err, "error creating a partition consumer for topic %s", topic)
@@ -44,7 +44,7 @@ func TestPublish(t *testing.T) {
 
 func TestSubscribe(t *testing.T) {
 	consumer := mocks.NewConsumer(t, nil)
-	pc := consumer.ExpectConsumePartition("dispatch", 0, sarama.OffsetNewest)
+	pc := consumer.ExpectConsumePartition("dispatch.dispatch", 0, sarama.OffsetNewest)
 	pc.ExpectMessagesDrainedOnClose()
 
 	event := events.NewCloudEventWithDefaults("dispatch")
@@ -59,7 +59,7 @@ func TestSubscribe(t *testing.T) {
 
 	done := make(chan struct{})
 
-	_, err := kafka.Subscribe(context.Background(), "dispatch", func(ctx context.Context, e *events.CloudEvent) {
+	_, err := kafka.Subscribe(context.Background(), "dispatch", "dispatch", func(ctx context.Context, e *events.CloudEvent) {
 		assert.Equal(t, event.EventID, e.EventID)
 		done <- struct{}{}
 	})
@@ -68,3 +68,8 @@ func TestSubscribe(t *testing.T) {
 	<-done
 
 }
+
+func TestTenantTopic(t *testing.T) {
+	assert.Equal(t, "org-a.dispatch", tenantTopic("dispatch", "org-a"))
+	assert.Equal(t, "dispatch", tenantTopic("dispatch", ""))
+}
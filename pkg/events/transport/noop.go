@@ -36,9 +36,9 @@ func (t *Noop) Publish(ctx context.Context, event *events.CloudEvent, topic stri
 }
 
 // Subscribe subscribes to event.
-func (t *Noop) Subscribe(ctx context.Context, topic string, handler events.Handler) (events.Subscription, error) {
+func (t *Noop) Subscribe(ctx context.Context, topic string, tenant string, handler events.Handler) (events.Subscription, error) {
 	if t.out != nil {
-		fmt.Fprintf(t.out, "Subscription to topic %s using handler %T\n", topic, handler)
+		fmt.Fprintf(t.out, "Subscription to topic %s and tenant %s using handler %T\n", topic, tenant, handler)
 	}
 	return nil, nil
 }
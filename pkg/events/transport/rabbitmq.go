@@ -79,23 +79,31 @@ func NewRabbitMQ(url string, defaultExchangeName string, options ...func(mq *Rab
 	}
 	defer ch.Close()
 
-	err = ch.ExchangeDeclare(
-		defaultExchangeName, // name
-		"topic",             // kind
-		true,                // durable
-		false,               // delete when unused
-		false,               // internal
-		false,               // no-wait
-		nil,                 // arguments
-	)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to to declare an exchange")
+	if err = declareExchange(ch, defaultExchangeName); err != nil {
+		return nil, err
 	}
 
 	return mq, nil
 }
 
-// Publish sends an event to RabbitMQ. tenant specifies the tenant
+// declareExchange declares the topic exchange of a tenant, declaring an existing exchange has no effect
+func declareExchange(ch *amqp.Channel, name string) error {
+	err := ch.ExchangeDeclare(
+		name,    // name
+		"topic", // kind
+		true,    // durable
+		false,   // delete when unused
+		false,   // internal
+		false,   // no-wait
+		nil,     // arguments
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to to declare an exchange")
+	}
+	return nil
+}
+
+// Publish sends an event to RabbitMQ. tenant specifies the tenant, each tenant has its own exchange
 func (mq *RabbitMQ) Publish(ctx context.Context, event *events.CloudEvent, topic string, tenant string) error {
 	span, ctx := trace.Trace(ctx, "")
 	defer span.Finish()
@@ -111,6 +119,10 @@ func (mq *RabbitMQ) Publish(ctx context.Context, event *events.CloudEvent, topic
 
 	if tenant == "" {
 		tenant = mq.exchangeName
+	} else if tenant != mq.exchangeName {
+		if err = declareExchange(ch, tenant); err != nil {
+			return err
+		}
 	}
 
 	msg := mq.eventToMsg(event)
@@ -132,15 +144,18 @@ func (mq *RabbitMQ) Publish(ctx context.Context, event *events.CloudEvent, topic
 	return nil
 }
 
-// Subscribe creates an active subscription on specified topic, and invokes handler function
+// Subscribe creates an active subscription on specified topic of the tenant, and invokes handler function
 // for every event received on given topic.
-func (mq *RabbitMQ) Subscribe(ctx context.Context, topic string, handler events.Handler) (events.Subscription, error) {
+func (mq *RabbitMQ) Subscribe(ctx context.Context, topic string, tenant string, handler events.Handler) (events.Subscription, error) {
 	span, ctx := trace.Trace(ctx, "")
 	defer span.Finish()
 
-	ch, q, err := mq.initQueue(topic)
+	if tenant == "" {
+		tenant = mq.exchangeName
+	}
+	ch, q, err := mq.initQueue(topic, tenant)
 	if err != nil {
-		return nil, errors.Wrapf(err, "error initializing from queue %s", q.Name)
+		return nil, errors.Wrapf(err, "error initializing a queue for topic %s", topic)
 	}
 
 	msgs, err := ch.Consume(
@@ -219,12 +234,15 @@ func (mq *RabbitMQ) msgToEvent(message amqp.Delivery) *events.CloudEvent {
 	}
 }
 
-// initQueue initializes and binds to a queue
-func (mq *RabbitMQ) initQueue(topic string) (*amqp.Channel, *amqp.Queue, error) {
+// initQueue initializes and binds to a queue on the exchange of the tenant
+func (mq *RabbitMQ) initQueue(topic string, tenant string) (*amqp.Channel, *amqp.Queue, error) {
 	ch, err := mq.recvConn.Channel()
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to acquire a RabbitMQ channel")
 	}
+	if err = declareExchange(ch, tenant); err != nil {
+		return nil, nil, err
+	}
 	q, err := ch.QueueDeclare(
 		"",    // name
 		false, // durable
@@ -238,14 +256,14 @@ func (mq *RabbitMQ) initQueue(topic string) (*amqp.Channel, *amqp.Queue, error)
 	}
 
 	err = ch.QueueBind(
-		q.Name,          // queue name
-		topic,           // routing key
-		mq.exchangeName, // exchange
-		false,           // noWait
-		nil,             // args
+		q.Name, // queue name
+		topic,  // routing key
+		tenant, // exchange
+		false,  // noWait
+		nil,    // args
 	)
 	if err != nil {
-		return nil, nil, errors.Wrapf(err, "error when binding to a queue %s with topic %s and exchange %s", q.Name, topic, mq.exchangeName)
+		return nil, nil, errors.Wrapf(err, "error when binding to a queue %s with topic %s and exchange %s", q.Name, topic, tenant)
 	}
 
 	return ch, &q, nil
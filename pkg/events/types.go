@@ -19,8 +19,8 @@ type Transport interface {
 	// Publish publishes the event using the underlying transport
 	Publish(ctx context.Context, event *CloudEvent, topic string, tenant string) error
 
-	// Subscribe takes a handler to run on every event received on topic. Returns a cancelable Subscription
-	Subscribe(ctx context.Context, topic string, handler Handler) (Subscription, error)
+	// Subscribe takes a handler to run on every event received on topic of the tenant. Returns a cancelable Subscription
+	Subscribe(ctx context.Context, topic string, tenant string, handler Handler) (Subscription, error)
 	Close()
 }
 
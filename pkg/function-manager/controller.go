@@ -16,6 +16,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
 	"github.com/vmware/dispatch/pkg/controller"
 	"github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/functions"
@@ -25,8 +26,13 @@ import (
 // ControllerConfig is the function manager controller configuration
 type ControllerConfig struct {
 	ResyncPeriod time.Duration
+	// Ownership decides which replica reconciles the functions and runs, nil with a single replica
+	Ownership controller.Ownership
 }
 
+// runDeleteBatchSize bounds the runs of a function deleted in a transaction
+const runDeleteBatchSize = 100
+
 type funcEntityHandler struct {
 	FaaS         functions.FaaSDriver
 	Store        entitystore.EntityStore
@@ -108,14 +114,29 @@ func (h *funcEntityHandler) Delete(ctx context.Context, obj entitystore.Entity)
 		return errors.Wrapf(err, "Driver error when deleting a FaaS function")
 	}
 
-	runs, err := getFilteredRuns(ctx, h.Store, e.OrganizationID, &e.Name, nil)
+	// The runs are deleted in transactions of at most runDeleteBatchSize runs, the transactions of some stores are
+	// bounded (etcd writes at most 128 keys by default), then the function. The deletion isn't atomic: when it fails,
+	// the runs deleted stay deleted and the function, still DELETING, is deleted again on the next resync.
+	runs, err := getFilteredRuns(ctx, h.Store, e.OrganizationID, &e.Name, nil, entitystore.Options{})
 	if err != nil {
 		return errors.Wrapf(err, "store error listing runs for function %s", e.Name)
 	}
-	for _, r := range runs {
-		if err := h.Store.Delete(ctx, e.OrganizationID, r.Name, r); err != nil {
-			log.Debugf("fail to delete entity because of %s", err)
-			return errors.Wrap(err, "store error when deleting function run")
+	for start := 0; start < len(runs); start += runDeleteBatchSize {
+		end := start + runDeleteBatchSize
+		if end > len(runs) {
+			end = len(runs)
+		}
+		err := h.Store.Tx(ctx, func(tx entitystore.EntityStore) error {
+			for _, r := range runs[start:end] {
+				if err := tx.Delete(ctx, e.OrganizationID, r.Name, r); err != nil {
+					log.Debugf("fail to delete entity because of %s", err)
+					return errors.Wrap(err, "store error when deleting function run")
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 	}
 
@@ -159,6 +180,20 @@ func syncFilter(resyncPeriod time.Duration) entitystore.Filter {
 		})
 }
 
+// WatchFilter returns the filter of the function entities changed which must be resolved, the function entities
+// in CREATING status are being resolved
+func (h *funcEntityHandler) WatchFilter() entitystore.Filter {
+	return entitystore.FilterEverything().Add(
+		entitystore.FilterStat{
+			Scope:   entitystore.FilterScopeField,
+			Subject: "Status",
+			Verb:    entitystore.FilterVerbIn,
+			Object: []entitystore.Status{
+				entitystore.StatusINITIALIZED, entitystore.StatusUPDATING, entitystore.StatusDELETING,
+			},
+		})
+}
+
 // Sync compares actual and desired state to return a list of function entities which must be resolved
 func (h *funcEntityHandler) Sync(ctx context.Context, resyncPeriod time.Duration) ([]entitystore.Entity, error) {
 	span, ctx := trace.Trace(ctx, "")
@@ -240,9 +275,10 @@ func (h *runEntityHandler) Add(ctx context.Context, obj entitystore.Entity) (err
 			SchemaIn:  f.Schema.In,
 			SchemaOut: f.Schema.Out,
 		},
-		Cookie:   "cookie",
-		Secrets:  run.Secrets,
-		Services: run.Services,
+		Cookie:     "cookie",
+		Secrets:    run.Secrets,
+		Services:   run.Services,
+		Requesters: append(run.Requesters, client.Requester(client.RequesterFunction, run.FunctionName)),
 	}, run.Input)
 	logs := fctx.Logs()
 	run.Logs = &logs
@@ -303,6 +339,11 @@ func (h *runEntityHandler) Delete(ctx context.Context, obj entitystore.Entity) (
 	return errors.Errorf("deleting runs not supported, fn: '%s'", run.FunctionName)
 }
 
+// WatchFilter returns nil, function executions (runs) are executed by the replica they are submitted to
+func (h *runEntityHandler) WatchFilter() entitystore.Filter {
+	return nil
+}
+
 // Sync compares actual and desired state to return a list of function execution (run) entities which must be resolved
 func (h *runEntityHandler) Sync(ctx context.Context, resyncPeriod time.Duration) ([]entitystore.Entity, error) {
 	span, ctx := trace.Trace(ctx, "")
@@ -326,6 +367,8 @@ func NewController(config *ControllerConfig, store entitystore.EntityStore, faas
 	c := controller.NewController(controller.Options{
 		ResyncPeriod: config.ResyncPeriod,
 		Workers:      1000, // want more functions concurrently? add more workers // TODO configure workers
+		Store:        store,
+		Ownership:    config.Ownership,
 	})
 	c.AddEntityHandler(&funcEntityHandler{Store: store, FaaS: faas, ImgClient: imgClient, ImageBuilder: imageBuilder})
 	c.AddEntityHandler(&runEntityHandler{Store: store, FaaS: faas, Runner: runner})
@@ -7,6 +7,7 @@ package functionmanager
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -120,6 +121,77 @@ func TestFuncEntityHandler_Delete(t *testing.T) {
 	faas.AssertExpectations(t)
 }
 
+// boundedTxStore fails the transactions deleting more than max entities, as etcd bounds the writes of transactions
+type boundedTxStore struct {
+	entitystore.EntityStore
+	max int
+}
+
+type deleteCountingStore struct {
+	entitystore.EntityStore
+	deleted int
+}
+
+func (s *deleteCountingStore) Delete(ctx context.Context, organizationID string, name string, entity entitystore.Entity) error {
+	s.deleted++
+	return s.EntityStore.Delete(ctx, organizationID, name, entity)
+}
+
+func (s *boundedTxStore) Tx(ctx context.Context, f func(tx entitystore.EntityStore) error) error {
+	return s.EntityStore.Tx(ctx, func(tx entitystore.EntityStore) error {
+		counting := &deleteCountingStore{EntityStore: tx}
+		if err := f(counting); err != nil {
+			return err
+		}
+		if counting.deleted > s.max {
+			return fmt.Errorf("too many operations in the transaction: %d", counting.deleted)
+		}
+		return nil
+	})
+}
+
+func TestFuncEntityHandler_DeleteRuns(t *testing.T) {
+	faas := &fnmocks.FaaSDriver{}
+	function := &functions.Function{
+		BaseEntity: entitystore.BaseEntity{
+			Name:           "testFunction",
+			OrganizationID: "testOrg",
+			Status:         entitystore.StatusDELETING,
+		},
+		ImageName: "testImage",
+		Handler:   "main",
+	}
+	faas.On("Delete", mock.Anything, function).Return(nil)
+
+	h := &funcEntityHandler{
+		Store: &boundedTxStore{EntityStore: helpers.MakeEntityStore(t), max: runDeleteBatchSize},
+		FaaS:  faas,
+	}
+
+	_, err := h.Store.Add(context.Background(), function)
+	require.NoError(t, err)
+	for i := 0; i < 2*runDeleteBatchSize+1; i++ {
+		_, err := h.Store.Add(context.Background(), &functions.FnRun{
+			BaseEntity: entitystore.BaseEntity{
+				Name:           fmt.Sprintf("testRun%d", i),
+				OrganizationID: "testOrg",
+			},
+			FunctionName: "testFunction",
+		})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, h.Delete(context.Background(), function))
+
+	var runs []*functions.FnRun
+	require.NoError(t, h.Store.List(context.Background(), "testOrg", entitystore.Options{}, &runs))
+	assert.Empty(t, runs)
+	found, err := h.Store.Find(context.Background(), "testOrg", "testFunction", entitystore.Options{}, &functions.Function{})
+	assert.NoError(t, err)
+	assert.False(t, found)
+	faas.AssertExpectations(t)
+}
+
 func TestRunEntityHandler_Add(t *testing.T) {
 	faas := &fnmocks.FaaSDriver{}
 	function := &functions.Function{
@@ -151,9 +223,9 @@ func TestRunEntityHandler_Add(t *testing.T) {
 		return f
 	}
 	secretInjector := &fnmocks.SecretInjector{}
-	secretInjector.On("GetMiddleware", "testOrg", mock.Anything, "cookie").Return(simw)
+	secretInjector.On("GetMiddleware", mock.Anything, "testOrg", mock.Anything, "cookie").Return(simw)
 	serviceInjector := &fnmocks.ServiceInjector{}
-	serviceInjector.On("GetMiddleware", "testOrg", mock.Anything, "cookie").Return(simw)
+	serviceInjector.On("GetMiddleware", mock.Anything, "testOrg", mock.Anything, "cookie").Return(simw)
 
 	h := &runEntityHandler{
 		Store: helpers.MakeEntityStore(t),
@@ -21,6 +21,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
 	"github.com/vmware/dispatch/pkg/controller"
 	"github.com/vmware/dispatch/pkg/entity-store"
 	dispatcherrors "github.com/vmware/dispatch/pkg/errors"
@@ -386,6 +387,9 @@ func (h *Handlers) getFunctions(params fnstore.GetFunctionsParams, principal int
 		Filter: entitystore.FilterEverything(),
 	}
 	opts.Filter, err = utils.ParseTags(opts.Filter, params.Tags)
+	if err == nil {
+		err = utils.ParsePaging(&opts, params.Limit, params.Continue, params.Sort)
+	}
 	if err != nil {
 		log.Errorf(err.Error())
 		return fnstore.NewGetFunctionsBadRequest().WithPayload(
@@ -404,7 +408,7 @@ func (h *Handlers) getFunctions(params fnstore.GetFunctionsParams, principal int
 			Message: swag.String("error when listing functions"),
 		})
 	}
-	return fnstore.NewGetFunctionsOK().WithPayload(functionListToModel(funcs))
+	return fnstore.NewGetFunctionsOK().WithPayload(functionListToModel(funcs)).WithXDispatchContinue(utils.NextPage(opts))
 }
 
 func (h *Handlers) updateFunction(params fnstore.UpdateFunctionParams, principal interface{}) middleware.Responder {
@@ -513,6 +517,8 @@ func (h *Handlers) runFunction(params fnrunner.RunFunctionParams, principal inte
 	run := runModelToEntity(params.Body, f)
 	run.OrganizationID = params.XDispatchOrg
 	run.Status = entitystore.StatusINITIALIZED
+	// the requester is set by the ingress for external requests, and by the event manager for subscriptions
+	run.Requesters = client.ParseRequesters(params.HTTPRequest.Header.Get(client.HeaderRequester))
 
 	if _, err := h.Store.Add(ctx, run); err != nil {
 		log.Errorf("Store error when adding new function run %s: %+v", run.Name, err)
@@ -568,12 +574,11 @@ func (h *Handlers) getRun(params fnrunner.GetRunParams, principal interface{}) m
 	return fnrunner.NewGetRunOK().WithPayload(runEntityToModel(&run))
 }
 
-func getFilteredRuns(ctx context.Context, store entitystore.EntityStore, orgID string, functionName *string, tags []string) ([]*functions.FnRun, error) {
+// getFilteredRuns lists the runs of an organization, opts defines the sort and page of the listing
+func getFilteredRuns(ctx context.Context, store entitystore.EntityStore, orgID string, functionName *string, tags []string, opts entitystore.Options) ([]*functions.FnRun, error) {
 	var runs []*functions.FnRun
 	var err error
-	opts := entitystore.Options{
-		Filter: entitystore.FilterEverything(),
-	}
+	opts.Filter = entitystore.FilterEverything()
 
 	if functionName != nil {
 		opts.Filter.Add(
@@ -605,7 +610,15 @@ func (h *Handlers) getRuns(params fnrunner.GetRunsParams, principal interface{})
 	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
 	defer span.Finish()
 
-	runs, err := getFilteredRuns(ctx, h.Store, params.XDispatchOrg, params.FunctionName, params.Tags)
+	var opts entitystore.Options
+	if err := utils.ParsePaging(&opts, params.Limit, params.Continue, params.Sort); err != nil {
+		return fnrunner.NewGetRunsBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
+	runs, err := getFilteredRuns(ctx, h.Store, params.XDispatchOrg, params.FunctionName, params.Tags, opts)
 
 	switch err.(type) {
 	case *dispatcherrors.RequestError:
@@ -620,5 +633,5 @@ func (h *Handlers) getRuns(params fnrunner.GetRunsParams, principal interface{})
 			Message: swag.String("error when listing function runs"),
 		})
 	}
-	return fnrunner.NewGetRunsOK().WithPayload(runListToModel(runs))
+	return fnrunner.NewGetRunsOK().WithPayload(runListToModel(runs)).WithXDispatchContinue(utils.NextPage(opts))
 }
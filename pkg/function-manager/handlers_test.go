@@ -221,3 +221,100 @@ func Test_runModelToEntitySecret(t *testing.T) {
 	fnRun := runModelToEntity(&runModel, &f)
 	assert.Equal(t, secrets, fnRun.Secrets)
 }
+
+func TestStoreFunctionOrganizationIsolation(t *testing.T) {
+	handlers := &Handlers{
+		Store: helpers.MakeEntityStore(t),
+	}
+
+	api := operations.NewFunctionManagerAPI(nil)
+	helpers.MakeAPI(t, handlers.ConfigureHandlers, api)
+
+	reqBody := &v1.Function{
+		Name:   swag.String("testEntity"),
+		Source: []byte("some source"),
+		Image:  swag.String("imageID"),
+	}
+	add := fnstore.AddFunctionParams{
+		HTTPRequest:  httptest.NewRequest("POST", "/v1/function", nil),
+		Body:         reqBody,
+		XDispatchOrg: "org-a",
+	}
+	addResponder := api.StoreAddFunctionHandler.Handle(add, "testCookie")
+	var addBody v1.Function
+	helpers.HandlerRequest(t, addResponder, &addBody, 201)
+
+	get := fnstore.GetFunctionParams{
+		HTTPRequest:  httptest.NewRequest("GET", "/v1/function/testEntity", nil),
+		FunctionName: "testEntity",
+		XDispatchOrg: "org-b",
+	}
+	getResponder := api.StoreGetFunctionHandler.Handle(get, "testCookie")
+	var errBody v1.Error
+	helpers.HandlerRequest(t, getResponder, &errBody, 404)
+
+	list := fnstore.GetFunctionsParams{
+		HTTPRequest:  httptest.NewRequest("GET", "/v1/function", nil),
+		XDispatchOrg: "org-b",
+	}
+	listResponder := api.StoreGetFunctionsHandler.Handle(list, "testCookie")
+	var listBody []v1.Function
+	helpers.HandlerRequest(t, listResponder, &listBody, 200)
+	assert.Empty(t, listBody)
+
+	get.XDispatchOrg = "org-a"
+	getResponder = api.StoreGetFunctionHandler.Handle(get, "testCookie")
+	var getBody v1.Function
+	helpers.HandlerRequest(t, getResponder, &getBody, 200)
+	assert.Equal(t, addBody.ID, getBody.ID)
+}
+
+func TestStoreGetFunctionsPaging(t *testing.T) {
+	handlers := &Handlers{
+		Store: helpers.MakeEntityStore(t),
+	}
+
+	api := operations.NewFunctionManagerAPI(nil)
+	helpers.MakeAPI(t, handlers.ConfigureHandlers, api)
+
+	for _, name := range []string{"fn-a", "fn-b", "fn-c"} {
+		add := fnstore.AddFunctionParams{
+			HTTPRequest: httptest.NewRequest("POST", "/v1/function", nil),
+			Body: &v1.Function{
+				Name:   swag.String(name),
+				Source: []byte("some source"),
+				Image:  swag.String("imageID"),
+			},
+			XDispatchOrg: "testOrg",
+		}
+		helpers.HandlerRequest(t, api.StoreAddFunctionHandler.Handle(add, "testCookie"), &v1.Function{}, 201)
+	}
+
+	list := fnstore.GetFunctionsParams{
+		HTTPRequest:  httptest.NewRequest("GET", "/v1/function", nil),
+		XDispatchOrg: "testOrg",
+		Limit:        swag.Int64(2),
+		Sort:         swag.String("-name"),
+	}
+	responder := api.StoreGetFunctionsHandler.Handle(list, "testCookie")
+	continueToken := responder.(*fnstore.GetFunctionsOK).XDispatchContinue
+	assert.NotEmpty(t, continueToken)
+	var page []v1.Function
+	helpers.HandlerRequest(t, responder, &page, 200)
+	assert.Len(t, page, 2)
+	assert.Equal(t, "fn-c", *page[0].Name)
+	assert.Equal(t, "fn-b", *page[1].Name)
+
+	list.Continue = swag.String(continueToken)
+	responder = api.StoreGetFunctionsHandler.Handle(list, "testCookie")
+	assert.Empty(t, responder.(*fnstore.GetFunctionsOK).XDispatchContinue)
+	page = nil
+	helpers.HandlerRequest(t, responder, &page, 200)
+	assert.Len(t, page, 1)
+	assert.Equal(t, "fn-a", *page[0].Name)
+
+	list.Continue = nil
+	list.Sort = swag.String("source")
+	var errBody v1.Error
+	helpers.HandlerRequest(t, api.StoreGetFunctionsHandler.Handle(list, "testCookie"), &errBody, 400)
+}
@@ -54,6 +54,8 @@ type FnRun struct {
 	Logs         *v1.Logs               `json:"logs,omitempty"`
 	Error        *v1.InvocationError    `json:"error,omitempty"`
 	FinishedTime time.Time              `json:"finishedTime,omitempty"`
+	// Requesters are the principals the run was requested for, e.g. subscription/on-order (see client.Requester)
+	Requesters []string `json:"requesters,omitempty"`
 
 	WaitChan chan struct{} `json:"-"`
 }
@@ -7,12 +7,15 @@ package injectors
 
 import (
 	"context"
+	"strings"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/vmware/dispatch/pkg/client"
 	"github.com/vmware/dispatch/pkg/functions"
+	"github.com/vmware/dispatch/pkg/secret-store/access"
+	secretclient "github.com/vmware/dispatch/pkg/secret-store/gen/client/secret"
 )
 
 type secretInjector struct {
@@ -26,12 +29,16 @@ func NewSecretInjector(secretClient client.SecretsClient) functions.SecretInject
 	}
 }
 
-func getSecrets(client client.SecretsClient, organizationID string, secretNames []string) (map[string]interface{}, error) {
+func getSecrets(ctx context.Context, secretsClient client.SecretsClient, organizationID string, secretNames []string) (map[string]interface{}, error) {
 
+	requesters, _ := client.RequesterFromContext(ctx)
 	secrets := make(map[string]interface{})
 	for _, name := range secretNames {
-		resp, err := client.GetSecret(context.Background(), organizationID, name)
+		resp, err := secretsClient.GetSecret(ctx, organizationID, name)
 		if err != nil {
+			if _, ok := errors.Cause(err).(*secretclient.GetSecretForbidden); ok {
+				return secrets, errors.Errorf("secret %s may not be read by %q", name, strings.Join(requesters, ","))
+			}
 			return secrets, errors.Wrapf(err, "failed to get secrets from secret store")
 		}
 		if resp.Name == nil {
@@ -39,6 +46,10 @@ func getSecrets(client client.SecretsClient, organizationID string, secretNames
 
 			return secrets, err
 		}
+		// the secret store enforces the access rules as well, this guards against injecting values it returned anyway
+		if !access.Allowed(resp, requesters) {
+			return secrets, errors.Errorf("secret %s may not be read by %q", name, strings.Join(requesters, ","))
+		}
 
 		for key, value := range resp.Secrets {
 			secrets[key] = value
@@ -47,10 +58,10 @@ func getSecrets(client client.SecretsClient, organizationID string, secretNames
 	return secrets, nil
 }
 
-func (i *secretInjector) GetMiddleware(organizationID string, secretNames []string, cookie string) functions.Middleware {
+func (i *secretInjector) GetMiddleware(requestCtx context.Context, organizationID string, secretNames []string, cookie string) functions.Middleware {
 	return func(f functions.Runnable) functions.Runnable {
 		return func(ctx functions.Context, in interface{}) (interface{}, error) {
-			secrets, err := getSecrets(i.secretClient, organizationID, secretNames)
+			secrets, err := getSecrets(requestCtx, i.secretClient, organizationID, secretNames)
 			if err != nil {
 				log.Errorf("error when getting secrets from secret store %+v", err)
 				return nil, &injectorError{errors.Wrap(err, "error when retrieving secrets from secret store")}
@@ -5,15 +5,19 @@
 package injectors
 
 import (
+	"context"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/vmware/dispatch/pkg/client/mocks"
 
 	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
 	"github.com/vmware/dispatch/pkg/functions"
+	secretclient "github.com/vmware/dispatch/pkg/secret-store/gen/client/secret"
 )
 
 func TestInjectSecret(t *testing.T) {
@@ -39,7 +43,40 @@ func TestInjectSecret(t *testing.T) {
 	}
 
 	ctx := functions.Context{}
-	output, err := injector.GetMiddleware("testOrg", []string{expectedSecretName}, cookie)(printSecretsFn)(ctx, nil)
+	output, err := injector.GetMiddleware(context.Background(), "testOrg", []string{expectedSecretName}, cookie)(printSecretsFn)(ctx, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedOutput, output)
 }
+
+func TestInjectSecretAccess(t *testing.T) {
+	name := "psql-creds"
+	secretsClient := &mocks.SecretsClient{}
+	secretsClient.On("GetSecret", mock.Anything, "testOrg", name).Return(
+		&v1.Secret{
+			Name:    &name,
+			Secrets: v1.SecretValue{"password": "secret"},
+			Access:  &v1.SecretAccess{Functions: []string{"billing"}},
+		}, nil)
+	secretsClient.On("GetSecret", mock.Anything, "testOrg", "forbidden").Return(
+		nil, errors.Wrap(secretclient.NewGetSecretForbidden(), "error when retrieving a secret"))
+
+	injector := NewSecretInjector(secretsClient)
+	printSecretsFn := func(ctx functions.Context, _ interface{}) (interface{}, error) {
+		return ctx["secrets"], nil
+	}
+
+	ctx := client.WithRequester(context.Background(), "run 42", client.Requester(client.RequesterFunction, "billing"))
+	output, err := injector.GetMiddleware(ctx, "testOrg", []string{name}, "cookie")(printSecretsFn)(functions.Context{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"password": "secret"}, output)
+	requesters, purpose := client.RequesterFromContext(secretsClient.Calls[0].Arguments.Get(0).(context.Context))
+	assert.Equal(t, []string{"function/billing"}, requesters, "the secrets are read for the requesters of the run")
+	assert.Equal(t, "run 42", purpose)
+
+	ctx = client.WithRequester(context.Background(), "run 43", client.Requester(client.RequesterFunction, "shipping"))
+	_, err = injector.GetMiddleware(ctx, "testOrg", []string{name}, "cookie")(printSecretsFn)(functions.Context{}, nil)
+	assert.Error(t, err, "values of secrets the requesters may not read are not injected")
+
+	_, err = injector.GetMiddleware(ctx, "testOrg", []string{"forbidden"}, "cookie")(printSecretsFn)(functions.Context{}, nil)
+	assert.Contains(t, err.Error(), `secret forbidden may not be read by "function/shipping"`)
+}
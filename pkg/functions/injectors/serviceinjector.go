@@ -29,33 +29,35 @@ func NewServiceInjector(secretClient client.SecretsClient, serviceClient client.
 	}
 }
 
-func getServiceBindings(serviceClient client.ServicesClient, secretClient client.SecretsClient, organizationID string, serviceNames []string) (map[string]interface{}, error) {
+// getServiceBindings returns the credentials of the named service bindings. The default binding of a service instance
+// is named after it, so a function may name the instance.
+func getServiceBindings(ctx context.Context, serviceClient client.ServicesClient, secretClient client.SecretsClient, organizationID string, bindingNames []string) (map[string]interface{}, error) {
 	bindings := make(map[string]interface{})
-	for _, name := range serviceNames {
-		log.Debugf("getting service instance %s", name)
-		resp, err := serviceClient.GetServiceInstance(context.TODO(), name)
+	for _, name := range bindingNames {
+		log.Debugf("getting service binding %s", name)
+		resp, err := serviceClient.GetServiceBinding(ctx, organizationID, name)
 		if err != nil {
-			return nil, errors.Wrapf(err, "failed to get service instance %s from service manager", name)
+			return nil, errors.Wrapf(err, "failed to get service binding %s from service manager", name)
 		}
-		log.Debugf("found service instance %s", name)
-		if string(resp.Binding.Status) != string(entitystore.StatusREADY) {
-			return nil, errors.Errorf("failed to get service bindings current status %s", resp.Binding.Status)
+		log.Debugf("found service binding %s of service %s", name, resp.ServiceInstance)
+		if string(resp.Status) != string(entitystore.StatusREADY) {
+			return nil, errors.Errorf("failed to get service binding %s current status %s", name, resp.Status)
 		}
-		log.Debugf("getting service binding %s for service %s", resp.ID, name)
-		secrets, err := getSecrets(secretClient, organizationID, []string{resp.ID.String()})
+		// the credentials of a binding are stored in the secret named after its binding ID
+		secrets, err := getSecrets(ctx, secretClient, organizationID, []string{resp.BindingID})
 		if err != nil {
-			return nil, errors.Wrapf(err, "failed to get service binding secrets for service instance %s", name)
+			return nil, errors.Wrapf(err, "failed to get the secrets of service binding %s", name)
 		}
-		log.Debugf("found service binding %s for service %s", resp.ID, name)
+		log.Debugf("found credentials %s of service binding %s", resp.BindingID, name)
 		bindings[name] = secrets
 	}
 	return bindings, nil
 }
 
-func (i *serviceInjector) GetMiddleware(organizationID string, serviceNames []string, cookie string) functions.Middleware {
+func (i *serviceInjector) GetMiddleware(requestCtx context.Context, organizationID string, serviceNames []string, cookie string) functions.Middleware {
 	return func(f functions.Runnable) functions.Runnable {
 		return func(ctx functions.Context, in interface{}) (interface{}, error) {
-			bindings, err := getServiceBindings(i.serviceClient, i.secretClient, organizationID, serviceNames)
+			bindings, err := getServiceBindings(requestCtx, i.serviceClient, i.secretClient, organizationID, serviceNames)
 			if err != nil {
 				log.Errorf("error when getting service bindings from service manager %+v", err)
 				return nil, &injectorError{errors.Wrap(err, "error when retrieving bindings from service manager")}
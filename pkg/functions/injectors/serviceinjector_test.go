@@ -5,6 +5,7 @@
 package injectors
 
 import (
+	"context"
 	"testing"
 
 	"github.com/go-openapi/strfmt"
@@ -20,24 +21,25 @@ import (
 func TestInjectService(t *testing.T) {
 
 	expectedSecretValue := v1.SecretValue{"secret1": "value1", "secret2": "value2"}
-	expectedServiceName := "testService"
+	expectedBindingName := "testBinding"
 	expectedOutput := map[string]interface{}{"secret1": "value1", "secret2": "value2"}
 
-	serviceID := uuid.NewV4().String()
+	bindingID := uuid.NewV4().String()
 
 	servicesClient := &mocks.ServicesClient{}
-	servicesClient.On("GetServiceInstance", mock.Anything, mock.Anything).Return(
-		&v1.ServiceInstance{
-			Name: &expectedServiceName,
-			ID:   strfmt.UUID(serviceID),
-			Binding: &v1.ServiceBinding{
-				Status: v1.StatusREADY,
-			}}, nil)
+	servicesClient.On("GetServiceBinding", mock.Anything, "testOrg", expectedBindingName).Return(
+		&v1.ServiceBinding{
+			Name:            expectedBindingName,
+			ID:              strfmt.UUID(uuid.NewV4().String()),
+			ServiceInstance: "testService",
+			BindingID:       bindingID,
+			Status:          v1.StatusREADY,
+		}, nil)
 
 	secretsClient := &mocks.SecretsClient{}
-	secretsClient.On("GetSecret", mock.Anything, "testOrg", mock.Anything).Return(
+	secretsClient.On("GetSecret", mock.Anything, "testOrg", bindingID).Return(
 		&v1.Secret{
-			Name:    &serviceID,
+			Name:    &bindingID,
 			Secrets: expectedSecretValue,
 		}, nil)
 
@@ -46,11 +48,32 @@ func TestInjectService(t *testing.T) {
 	cookie := "testCookie"
 
 	printServiceFn := func(ctx functions.Context, _ interface{}) (interface{}, error) {
-		return ctx["serviceBindings"].(map[string]interface{})[expectedServiceName], nil
+		return ctx["serviceBindings"].(map[string]interface{})[expectedBindingName], nil
 	}
 
 	ctx := functions.Context{}
-	output, err := injector.GetMiddleware("testOrg", []string{expectedServiceName}, cookie)(printServiceFn)(ctx, nil)
+	output, err := injector.GetMiddleware(context.Background(), "testOrg", []string{expectedBindingName}, cookie)(printServiceFn)(ctx, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedOutput, output)
 }
+
+func TestInjectServiceBindingNotReady(t *testing.T) {
+	servicesClient := &mocks.ServicesClient{}
+	servicesClient.On("GetServiceBinding", mock.Anything, "testOrg", "rotating").Return(
+		&v1.ServiceBinding{
+			Name:            "rotating",
+			ServiceInstance: "testService",
+			BindingID:       uuid.NewV4().String(),
+			Status:          v1.StatusUPDATING,
+		}, nil)
+	secretsClient := &mocks.SecretsClient{}
+
+	injector := NewServiceInjector(secretsClient, servicesClient)
+
+	printServiceFn := func(ctx functions.Context, _ interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	_, err := injector.GetMiddleware(context.Background(), "testOrg", []string{"rotating"}, "testCookie")(printServiceFn)(functions.Context{}, nil)
+	assert.Error(t, err)
+	secretsClient.AssertNotCalled(t, "GetSecret", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -4,6 +4,7 @@
 
 package mocks
 
+import context "context"
 import functions "github.com/vmware/dispatch/pkg/functions"
 import mock "github.com/stretchr/testify/mock"
 
@@ -12,13 +13,13 @@ type SecretInjector struct {
 	mock.Mock
 }
 
-// GetMiddleware provides a mock function with given fields: organizationID, secrets, cookie
-func (_m *SecretInjector) GetMiddleware(organizationID string, secrets []string, cookie string) functions.Middleware {
-	ret := _m.Called(organizationID, secrets, cookie)
+// GetMiddleware provides a mock function with given fields: ctx, organizationID, secrets, cookie
+func (_m *SecretInjector) GetMiddleware(ctx context.Context, organizationID string, secrets []string, cookie string) functions.Middleware {
+	ret := _m.Called(ctx, organizationID, secrets, cookie)
 
 	var r0 functions.Middleware
-	if rf, ok := ret.Get(0).(func(string, []string, string) functions.Middleware); ok {
-		r0 = rf(organizationID, secrets, cookie)
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, string) functions.Middleware); ok {
+		r0 = rf(ctx, organizationID, secrets, cookie)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(functions.Middleware)
@@ -4,6 +4,7 @@
 
 package mocks
 
+import context "context"
 import functions "github.com/vmware/dispatch/pkg/functions"
 import mock "github.com/stretchr/testify/mock"
 
@@ -12,13 +13,13 @@ type ServiceInjector struct {
 	mock.Mock
 }
 
-// GetMiddleware provides a mock function with given fields: organizationID, services, cookie
-func (_m *ServiceInjector) GetMiddleware(organizationID string, services []string, cookie string) functions.Middleware {
-	ret := _m.Called(organizationID, services, cookie)
+// GetMiddleware provides a mock function with given fields: ctx, organizationID, services, cookie
+func (_m *ServiceInjector) GetMiddleware(ctx context.Context, organizationID string, services []string, cookie string) functions.Middleware {
+	ret := _m.Called(ctx, organizationID, services, cookie)
 
 	var r0 functions.Middleware
-	if rf, ok := ret.Get(0).(func(string, []string, string) functions.Middleware); ok {
-		r0 = rf(organizationID, services, cookie)
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, string) functions.Middleware); ok {
+		r0 = rf(ctx, organizationID, services, cookie)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(functions.Middleware)
@@ -6,6 +6,9 @@
 package runner
 
 import (
+	"context"
+
+	"github.com/vmware/dispatch/pkg/client"
 	"github.com/vmware/dispatch/pkg/functions"
 )
 
@@ -28,10 +31,12 @@ func New(config *Config) functions.Runner {
 
 func (r *impl) Run(fn *functions.FunctionExecution, in interface{}) (interface{}, error) {
 	f := r.Faas.GetRunnable(fn)
+	// the injectors read the secrets for the requesters of the run
+	ctx := client.WithRequester(context.Background(), "run "+fn.RunID, fn.Requesters...)
 	m := Compose(
 		r.Validator.GetMiddleware(fn.Schemas),
-		r.SecretInjector.GetMiddleware(fn.OrganizationID, fn.Secrets, fn.Cookie),
-		r.ServiceInjector.GetMiddleware(fn.OrganizationID, fn.Services, fn.Cookie),
+		r.SecretInjector.GetMiddleware(ctx, fn.OrganizationID, fn.Secrets, fn.Cookie),
+		r.ServiceInjector.GetMiddleware(ctx, fn.OrganizationID, fn.Services, fn.Cookie),
 	)
 	return m(f)(fn.Context, in)
 }
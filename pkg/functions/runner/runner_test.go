@@ -10,6 +10,7 @@ import (
 	"errors"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/vmware/dispatch/pkg/functions"
 	"github.com/vmware/dispatch/pkg/functions/mocks"
 )
@@ -47,8 +48,8 @@ func TestRun(t *testing.T) {
 
 	faas.On("GetRunnable", fe).Return(functions.Runnable(runnable0))
 	v.On("GetMiddleware", testSchemas).Return(functions.Middleware(mw0(validation)))
-	secretInjector.On("GetMiddleware", "testOrg", []string{}, "cookie").Return(functions.Middleware(mw0(injection)))
-	serviceInjector.On("GetMiddleware", "testOrg", []string{}, "cookie").Return(functions.Middleware(mw0(injection)))
+	secretInjector.On("GetMiddleware", mock.Anything, "testOrg", []string{}, "cookie").Return(functions.Middleware(mw0(injection)))
+	serviceInjector.On("GetMiddleware", mock.Anything, "testOrg", []string{}, "cookie").Return(functions.Middleware(mw0(injection)))
 
 	testRunner := New(&Config{faas, v, secretInjector, serviceInjector})
 
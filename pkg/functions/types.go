@@ -61,6 +61,10 @@ type FunctionExecution struct {
 	Secrets  []string
 	Services []string
 	Cookie   string
+
+	// Requesters are the principals the function runs for, e.g. function/hello (see client.Requester).
+	// The secrets are only injected if one of them may read them.
+	Requesters []string
 }
 
 //go:generate mockery -name FaaSDriver -case underscore -dir . -note "CLOSE THIS FILE AS QUICKLY AS POSSIBLE"
@@ -105,14 +109,14 @@ type Validator interface {
 
 // SecretInjector injects secrets into function execution
 type SecretInjector interface {
-	GetMiddleware(organizationID string, secrets []string, cookie string) Middleware
+	GetMiddleware(ctx context.Context, organizationID string, secrets []string, cookie string) Middleware
 }
 
 //go:generate mockery -name ServiceInjector -case underscore -dir . -note "CLOSE THIS FILE AS QUICKLY AS POSSIBLE"
 
 // ServiceInjector injects service bindings into function execution
 type ServiceInjector interface {
-	GetMiddleware(organizationID string, services []string, cookie string) Middleware
+	GetMiddleware(ctx context.Context, organizationID string, services []string, cookie string) Middleware
 }
 
 // InputError represents user/input error
@@ -0,0 +1,108 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware/dispatch/pkg/entity-store"
+)
+
+// APITokenPrefix prefixes the personal API tokens, e.g. dpat_<ID>_<SECRET>, to tell them apart from JWTs
+const APITokenPrefix = "dpat_"
+
+// apiTokenPath is the path of the API tokens, in the iam resource. Users manage their own API tokens without policy.
+const apiTokenPath = "apitoken"
+
+// newAPITokenSecret generates the ID and the secret of an API token, and returns the token
+func newAPITokenSecret() (id string, secret string, token string, err error) {
+	idBytes := make([]byte, 8)
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", "", "", errors.Wrap(err, "error generating API token")
+	}
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", "", errors.Wrap(err, "error generating API token")
+	}
+	id = hex.EncodeToString(idBytes)
+	secret = base64.RawURLEncoding.EncodeToString(secretBytes)
+	return id, secret, APITokenPrefix + id + "_" + secret, nil
+}
+
+// hashAPITokenSecret hashes the secret of an API token. The secret is random, a salt or a slow hash do not make it
+// harder to guess.
+func hashAPITokenSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// isAPIToken returns whether a bearer token is a personal API token
+func isAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// parseAPIToken returns the ID and the secret of an API token
+func parseAPIToken(token string) (string, string, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(token, APITokenPrefix), "_", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// verifyAPIToken fetches and verifies an API token
+func (h *Handlers) verifyAPIToken(ctx context.Context, token string, now time.Time) (*APIToken, error) {
+	id, secret, ok := parseAPIToken(token)
+	if !ok {
+		return nil, errors.New("malformed API token")
+	}
+	var e APIToken
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	if err := h.store.Get(ctx, IdentityManagerFlags.OrgID, id, opts, &e); err != nil {
+		return nil, errors.Errorf("unknown API token %s", id)
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAPITokenSecret(secret)), []byte(e.SecretHash)) != 1 {
+		return nil, errors.Errorf("invalid secret of API token %s", id)
+	}
+	if !e.ExpiresTime.IsZero() && !now.Before(e.ExpiresTime) {
+		return nil, errors.Errorf("API token %s expired", id)
+	}
+	return &e, nil
+}
+
+// allows returns whether the scope of an API token includes a request. Tokens without scope have all the permissions
+// of their owner.
+func (e *APIToken) allows(attrs *attributesRecord) bool {
+	return inScope(e.Resources, attrs.resource) && inScope(e.Actions, string(attrs.action))
+}
+
+func inScope(scope []string, value string) bool {
+	if len(scope) == 0 {
+		return true
+	}
+	for _, s := range scope {
+		if s == "*" || s == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isAPITokenManagement returns whether a request manages API tokens, e.g. /v1/iam/apitoken/<NAME>
+func isAPITokenManagement(attrs *attributesRecord) bool {
+	return Resource(attrs.resource) == ResourceIAM && attrs.name == apiTokenPath
+}
@@ -0,0 +1,242 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	middleware "github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/entity-store"
+	apiTokenOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/apitoken"
+	"github.com/vmware/dispatch/pkg/trace"
+	"github.com/vmware/dispatch/pkg/utils"
+)
+
+func apiTokenEntityToModel(e *APIToken) *v1.APIToken {
+	m := v1.APIToken{
+		Name:        e.Name,
+		Kind:        utils.APITokenKind,
+		Description: e.Description,
+		Owner:       e.Owner,
+		CreatedTime: e.CreatedTime.Unix(),
+		Resources:   e.Resources,
+		Actions:     e.Actions,
+	}
+	if !e.ExpiresTime.IsZero() {
+		m.ExpiresTime = e.ExpiresTime.Unix()
+	}
+	return &m
+}
+
+// validateAPITokenScope validates the actions an API token is restricted to
+func validateAPITokenScope(m *v1.APIToken) error {
+	for _, action := range m.Actions {
+		switch Action(action) {
+		case ActionGet, ActionCreate, ActionUpdate, ActionDelete, "*":
+		default:
+			return errors.Errorf("invalid action %s", action)
+		}
+	}
+	for _, resource := range m.Resources {
+		if resource == "" {
+			return errors.New("invalid empty resource")
+		}
+	}
+	return nil
+}
+
+// apiTokenOwner returns the user owning the API tokens managed by a request. Only users own API tokens, and they cannot
+// be created with an API token: a token would otherwise outlive its revocation, or escape its scope.
+func apiTokenOwner(principal interface{}) (string, error) {
+	p := requestPrincipal(principal)
+	if p.ServiceAccount {
+		return "", errors.New("API tokens are only issued to users")
+	}
+	return p.Subject, nil
+}
+
+func (h *Handlers) addAPIToken(params apiTokenOperations.AddAPITokenParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	owner, err := apiTokenOwner(principal)
+	if err == nil && requestPrincipal(principal).APIToken != nil {
+		err = errors.New("API tokens cannot be created with an API token")
+	}
+	if err != nil {
+		return apiTokenOperations.NewAddAPITokenDefault(http.StatusForbidden).WithPayload(&v1.Error{
+			Code:    http.StatusForbidden,
+			Message: swag.String(err.Error()),
+		})
+	}
+
+	now := time.Now()
+	if params.Body.ExpiresTime != 0 && !time.Unix(params.Body.ExpiresTime, 0).After(now) {
+		err = errors.New("expiry time is in the past")
+	}
+	if err == nil {
+		err = validateAPITokenScope(params.Body)
+	}
+	if err != nil {
+		return apiTokenOperations.NewAddAPITokenBadRequest().WithPayload(&v1.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String(fmt.Sprintf("error validating API token: %s", err)),
+		})
+	}
+
+	id, secret, token, err := newAPITokenSecret()
+	if err != nil {
+		log.Errorf("error generating API token: %+v", err)
+		return apiTokenOperations.NewAddAPITokenInternalServerError().WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when creating API token"),
+		})
+	}
+	e := &APIToken{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: IdentityManagerFlags.OrgID,
+			Name:           id,
+			Status:         entitystore.StatusREADY,
+		},
+		Description: params.Body.Description,
+		Owner:       owner,
+		SecretHash:  hashAPITokenSecret(secret),
+		Resources:   params.Body.Resources,
+		Actions:     params.Body.Actions,
+	}
+	if params.Body.ExpiresTime != 0 {
+		e.ExpiresTime = time.Unix(params.Body.ExpiresTime, 0)
+	}
+	if _, err := h.store.Add(ctx, e); err != nil {
+		if entitystore.IsUniqueViolation(err) {
+			return apiTokenOperations.NewAddAPITokenConflict().WithPayload(&v1.Error{
+				Code:    http.StatusConflict,
+				Message: swag.String("error creating API token: non-unique name"),
+			})
+		}
+		log.Errorf("store error when adding a new API token: %+v", err)
+		return apiTokenOperations.NewAddAPITokenInternalServerError().WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when storing new API token"),
+		})
+	}
+	log.Infof("API token %s created for %s", e.Name, owner)
+
+	m := apiTokenEntityToModel(e)
+	m.Token = token
+	return apiTokenOperations.NewAddAPITokenCreated().WithPayload(m)
+}
+
+// getOwnedAPIToken gets an API token of a user. The API tokens of other users are not found.
+func (h *Handlers) getOwnedAPIToken(ctx context.Context, owner string, name string) (*APIToken, error) {
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	var e APIToken
+	if err := h.store.Get(ctx, IdentityManagerFlags.OrgID, name, opts, &e); err != nil {
+		return nil, err
+	}
+	if e.Owner != owner {
+		return nil, errors.Errorf("API token %s not owned by %s", name, owner)
+	}
+	return &e, nil
+}
+
+func (h *Handlers) getAPIToken(params apiTokenOperations.GetAPITokenParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	owner, err := apiTokenOwner(principal)
+	var e *APIToken
+	if err == nil {
+		e, err = h.getOwnedAPIToken(ctx, owner, params.APITokenName)
+	}
+	if err != nil {
+		log.Debugf("error getting API token %s: %s", params.APITokenName, err)
+		return apiTokenOperations.NewGetAPITokenNotFound().WithPayload(&v1.Error{
+			Code:    http.StatusNotFound,
+			Message: swag.String("API token not found"),
+		})
+	}
+	return apiTokenOperations.NewGetAPITokenOK().WithPayload(apiTokenEntityToModel(e))
+}
+
+func (h *Handlers) getAPITokens(params apiTokenOperations.GetAPITokensParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	owner, err := apiTokenOwner(principal)
+	if err != nil {
+		return apiTokenOperations.NewGetAPITokensDefault(http.StatusForbidden).WithPayload(&v1.Error{
+			Code:    http.StatusForbidden,
+			Message: swag.String(err.Error()),
+		})
+	}
+
+	var tokens []*APIToken
+	filter := entitystore.FilterExists()
+	filter.Add(entitystore.FilterStat{
+		Scope:   entitystore.FilterScopeExtra,
+		Subject: "Owner",
+		Verb:    entitystore.FilterVerbEqual,
+		Object:  owner,
+	})
+	opts := entitystore.Options{Filter: filter}
+	if err := utils.ParsePaging(&opts, params.Limit, params.Continue, params.Sort); err != nil {
+		return apiTokenOperations.NewGetAPITokensDefault(http.StatusBadRequest).WithPayload(&v1.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String(err.Error()),
+		})
+	}
+	if err := h.store.List(ctx, IdentityManagerFlags.OrgID, opts, &tokens); err != nil {
+		log.Errorf("store error when listing API tokens: %+v", err)
+		return apiTokenOperations.NewGetAPITokensInternalServerError().WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when listing API tokens"),
+		})
+	}
+	models := []*v1.APIToken{}
+	for _, e := range tokens {
+		models = append(models, apiTokenEntityToModel(e))
+	}
+	return apiTokenOperations.NewGetAPITokensOK().WithPayload(models).WithXDispatchContinue(utils.NextPage(opts))
+}
+
+func (h *Handlers) deleteAPIToken(params apiTokenOperations.DeleteAPITokenParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	owner, err := apiTokenOwner(principal)
+	var e *APIToken
+	if err == nil {
+		e, err = h.getOwnedAPIToken(ctx, owner, params.APITokenName)
+	}
+	if err != nil {
+		log.Debugf("error getting API token %s: %s", params.APITokenName, err)
+		return apiTokenOperations.NewDeleteAPITokenNotFound().WithPayload(&v1.Error{
+			Code:    http.StatusNotFound,
+			Message: swag.String("API token not found"),
+		})
+	}
+
+	if err := h.store.Delete(ctx, e.OrganizationID, e.Name, e); err != nil {
+		log.Errorf("store error when deleting API token %s: %+v", e.Name, err)
+		return apiTokenOperations.NewDeleteAPITokenInternalServerError().WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when deleting API token"),
+		})
+	}
+	log.Infof("API token %s of %s revoked", e.Name, owner)
+	return apiTokenOperations.NewDeleteAPITokenOK().WithPayload(apiTokenEntityToModel(e))
+}
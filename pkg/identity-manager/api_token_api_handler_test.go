@@ -0,0 +1,162 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations"
+	apiTokenOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/apitoken"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+func setupAPITokenTestAPI(t *testing.T) (*operations.IdentityManagerAPI, *Handlers) {
+	api := operations.NewIdentityManagerAPI(nil)
+	es := helpers.MakeEntityStore(t)
+	addTestData(es)
+	handlers := NewHandlers(nil, es, SetupEnforcer(es))
+	helpers.MakeAPI(t, handlers.ConfigureHandlers, api)
+	return api, handlers
+}
+
+func addTestAPIToken(t *testing.T, api *operations.IdentityManagerAPI, owner string, m *v1.APIToken, status int) *v1.APIToken {
+	params := apiTokenOperations.AddAPITokenParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/iam/apitoken", nil),
+		Body:        m,
+	}
+	var respBody v1.APIToken
+	helpers.HandlerRequest(t, api.ApitokenAddAPITokenHandler.Handle(params, &Principal{Subject: owner}), &respBody, status)
+	return &respBody
+}
+
+func TestAddAPIToken(t *testing.T) {
+	api, h := setupAPITokenTestAPI(t)
+
+	token := addTestAPIToken(t, api, "jane@example.com", &v1.APIToken{Description: "ci"}, http.StatusCreated)
+	assert.True(t, strings.HasPrefix(token.Token, APITokenPrefix+token.Name+"_"))
+	assert.Equal(t, "jane@example.com", token.Owner)
+	assert.Equal(t, "ci", token.Description)
+
+	principal, err := h.authenticateBearer("Bearer " + token.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", principal.(*Principal).Subject)
+	assert.Equal(t, token.Name, principal.(*Principal).APIToken.Name)
+
+	// Only the hash of the secret is stored, the token is not returned afterwards
+	e, err := h.getOwnedAPIToken(context.Background(), "jane@example.com", token.Name)
+	require.NoError(t, err)
+	assert.NotContains(t, token.Token, e.SecretHash)
+	params := apiTokenOperations.GetAPITokenParams{
+		HTTPRequest:  httptest.NewRequest("GET", "/v1/iam/apitoken/"+token.Name, nil),
+		APITokenName: token.Name,
+	}
+	var respBody v1.APIToken
+	helpers.HandlerRequest(t, api.ApitokenGetAPITokenHandler.Handle(params, &Principal{Subject: "jane@example.com"}), &respBody, http.StatusOK)
+	assert.Empty(t, respBody.Token)
+
+	_, err = h.authenticateBearer("Bearer " + token.Token + "x")
+	assert.Error(t, err)
+	_, err = h.authenticateBearer("Bearer " + APITokenPrefix + "unknown_secret")
+	assert.Error(t, err)
+}
+
+func TestAddAPITokenInvalid(t *testing.T) {
+	api, _ := setupAPITokenTestAPI(t)
+
+	addTestAPIToken(t, api, "jane@example.com", &v1.APIToken{Actions: []string{"read"}}, http.StatusBadRequest)
+	addTestAPIToken(t, api, "jane@example.com", &v1.APIToken{ExpiresTime: time.Now().Add(-time.Hour).Unix()}, http.StatusBadRequest)
+
+	// API tokens are not issued to service accounts, nor created with API tokens
+	params := apiTokenOperations.AddAPITokenParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/iam/apitoken", nil),
+		Body:        &v1.APIToken{},
+	}
+	var respBody v1.Error
+	svcAccount := &Principal{Subject: "test-svc", ServiceAccount: true}
+	helpers.HandlerRequest(t, api.ApitokenAddAPITokenHandler.Handle(params, svcAccount), &respBody, http.StatusForbidden)
+	apiToken := &Principal{Subject: "jane@example.com", APIToken: &APIToken{}}
+	helpers.HandlerRequest(t, api.ApitokenAddAPITokenHandler.Handle(params, apiToken), &respBody, http.StatusForbidden)
+}
+
+func TestAPITokenExpiry(t *testing.T) {
+	api, h := setupAPITokenTestAPI(t)
+
+	expires := time.Now().Add(time.Hour)
+	token := addTestAPIToken(t, api, "jane@example.com", &v1.APIToken{ExpiresTime: expires.Unix()}, http.StatusCreated)
+	assert.Equal(t, expires.Unix(), token.ExpiresTime)
+
+	_, err := h.verifyAPIToken(context.Background(), token.Token, time.Now())
+	assert.NoError(t, err)
+	_, err = h.verifyAPIToken(context.Background(), token.Token, expires)
+	assert.EqualError(t, err, "API token "+token.Name+" expired")
+}
+
+func TestAPITokensOfOwner(t *testing.T) {
+	api, h := setupAPITokenTestAPI(t)
+
+	token := addTestAPIToken(t, api, "jane@example.com", &v1.APIToken{}, http.StatusCreated)
+	addTestAPIToken(t, api, "joe@example.com", &v1.APIToken{}, http.StatusCreated)
+
+	listParams := apiTokenOperations.GetAPITokensParams{
+		HTTPRequest: httptest.NewRequest("GET", "/v1/iam/apitoken", nil),
+	}
+	var tokens []*v1.APIToken
+	helpers.HandlerRequest(t, api.ApitokenGetAPITokensHandler.Handle(listParams, &Principal{Subject: "jane@example.com"}), &tokens, http.StatusOK)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, token.Name, tokens[0].Name)
+
+	// The tokens of other users are not found
+	deleteParams := apiTokenOperations.DeleteAPITokenParams{
+		HTTPRequest:  httptest.NewRequest("DELETE", "/v1/iam/apitoken/"+token.Name, nil),
+		APITokenName: token.Name,
+	}
+	var respBody v1.APIToken
+	helpers.HandlerRequest(t, api.ApitokenDeleteAPITokenHandler.Handle(deleteParams, &Principal{Subject: "joe@example.com"}), &respBody, http.StatusNotFound)
+
+	helpers.HandlerRequest(t, api.ApitokenDeleteAPITokenHandler.Handle(deleteParams, &Principal{Subject: "jane@example.com"}), &respBody, http.StatusOK)
+	_, err := h.authenticateBearer("Bearer " + token.Token)
+	assert.Error(t, err)
+}
+
+func TestAuthHandlerAPIToken(t *testing.T) {
+	api, h := setupAPITokenTestAPI(t)
+
+	token := addTestAPIToken(t, api, "super-admin@example.com", &v1.APIToken{
+		Resources: []string{"function"},
+		Actions:   []string{"get"},
+	}, http.StatusCreated)
+	principal, err := h.authenticateBearer("Bearer " + token.Token)
+	require.NoError(t, err)
+
+	// Requests are made as the owner, within the scope of the token
+	responder := api.AuthHandler.Handle(newOrgAuthParams("", "/v1/function", "GET"), principal)
+	helpers.HandlerRequest(t, responder, nil, http.StatusAccepted)
+	assert.Equal(t, "user/super-admin@example.com", responder.(*operations.AuthAccepted).XDispatchRequester)
+
+	helpers.HandlerRequest(t, api.AuthHandler.Handle(newOrgAuthParams("", "/v1/function", "POST"), principal), nil, http.StatusForbidden)
+	helpers.HandlerRequest(t, api.AuthHandler.Handle(newOrgAuthParams("", "/v1/image", "GET"), principal), nil, http.StatusForbidden)
+
+	// The scope of a token doesn't extend the policies of the owner
+	token = addTestAPIToken(t, api, "readonly-user@example.com", &v1.APIToken{}, http.StatusCreated)
+	principal, err = h.authenticateBearer("Bearer " + token.Token)
+	require.NoError(t, err)
+	helpers.HandlerRequest(t, api.AuthHandler.Handle(newOrgAuthParams("", "/v1/function", "POST"), principal), nil, http.StatusForbidden)
+
+	// Users manage their API tokens without policy, service accounts can't
+	params := newOrgAuthParams("", "/v1/iam/apitoken", "POST")
+	helpers.HandlerRequest(t, api.AuthHandler.Handle(params, &Principal{Subject: "nopolicy-user@example.com"}), nil, http.StatusAccepted)
+	svcAccount := &Principal{Subject: "test-svc", ServiceAccount: true}
+	helpers.HandlerRequest(t, api.AuthHandler.Handle(params, svcAccount), nil, http.StatusForbidden)
+}
@@ -0,0 +1,133 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/casbin/casbin/util"
+	log "github.com/sirupsen/logrus"
+
+	apimanager "github.com/vmware/dispatch/pkg/api-manager"
+	"github.com/vmware/dispatch/pkg/entity-store"
+	driverentities "github.com/vmware/dispatch/pkg/event-manager/drivers/entities"
+	subscriptionentities "github.com/vmware/dispatch/pkg/event-manager/subscriptions/entities"
+	"github.com/vmware/dispatch/pkg/functions"
+	imagemanager "github.com/vmware/dispatch/pkg/image-manager"
+	secretstore "github.com/vmware/dispatch/pkg/secret-store"
+	serviceentities "github.com/vmware/dispatch/pkg/service-manager/entities"
+)
+
+// applicationTag is the tag holding the application of entities
+const applicationTag = "Application"
+
+// targetEntity returns an empty entity of the type targeted by a request path, and the name of the target, e.g. the
+// function hello of /v1/function/hello. The name is empty for collections, e.g. /v1/function. The entity is nil for the
+// resources which don't belong to applications.
+func targetEntity(path string) (entitystore.Entity, string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 {
+		return nil, ""
+	}
+	resource, rest := parts[1], parts[2:]
+	var entity entitystore.Entity
+	switch resource {
+	case "function":
+		entity = &functions.Function{}
+	case "image":
+		entity = &imagemanager.Image{}
+	case "baseimage":
+		entity = &imagemanager.BaseImage{}
+	case "secret":
+		entity = &secretstore.SecretEntity{}
+	case "serviceinstance":
+		entity = &serviceentities.ServiceInstance{}
+	case "servicebinding":
+		entity = &serviceentities.ServiceBinding{}
+	case "api":
+		// Certificates are managed under /v1/api/certificate
+		entity = &apimanager.API{}
+		if len(rest) > 0 && rest[0] == "certificate" {
+			entity, rest = &apimanager.Certificate{}, rest[1:]
+		}
+	case "event":
+		// Event resources are managed under /v1/event/<resources>
+		if len(rest) == 0 {
+			return nil, ""
+		}
+		switch rest[0] {
+		case "subscriptions":
+			entity = &subscriptionentities.Subscription{}
+		case "drivers":
+			entity = &driverentities.Driver{}
+		case "drivertypes":
+			entity = &driverentities.DriverType{}
+		default:
+			return nil, ""
+		}
+		rest = rest[1:]
+	default:
+		return nil, ""
+	}
+	if len(rest) == 0 {
+		return entity, ""
+	}
+	return entity, rest[0]
+}
+
+// targetApplication resolves the application of a request from the entity it targets: the application tag of the
+// entity, read from the store the services share with the identity manager, or the application resource itself. It
+// returns whether the request targets a collection instead, e.g. to create or list functions: only the service knows
+// their application, from the request body or the tags filtering the listing.
+func (h *Handlers) targetApplication(ctx context.Context, orgID string, attrs *attributesRecord, requestURI string) (string, bool) {
+	if Resource(attrs.resource) == ResourceApplication {
+		return attrs.name, false
+	}
+	requestURL, err := url.Parse(requestURI)
+	if err != nil {
+		return "", false
+	}
+	entity, name := targetEntity(requestURL.Path)
+	if entity == nil {
+		return "", false
+	}
+	if name == "" {
+		return "", true
+	}
+	found, err := h.store.Find(ctx, orgID, name, entitystore.Options{Filter: entitystore.FilterExists()}, entity)
+	if err != nil {
+		log.Errorf("store error when resolving the application of %s %s: %+v", entitystore.GetDataType(entity), name, err)
+		return "", false
+	}
+	if !found {
+		return "", false
+	}
+	return entity.GetTags()[applicationTag], false
+}
+
+// ruleApplications returns the applications of the rules allowing a request to the subject, or one of its groups, in
+// some applications only
+func (h *Handlers) ruleApplications(orgID string, attrs *attributesRecord, groups []string) []string {
+	subjects := append([]string{attrs.subject}, groups...)
+	seen := make(map[string]bool)
+	var applications []string
+	// Filtering on the default organization, empty, returns the rules of all the organizations
+	for _, rule := range h.enforcer.GetFilteredPolicy(0, orgID) {
+		if rule[0] != orgID || seen[rule[4]] || !resourceMatch(attrs.object(), rule[2]) || !util.KeyMatch(string(attrs.action), rule[3]) {
+			continue
+		}
+		for _, subject := range subjects {
+			if util.KeyMatch(subject, rule[1]) {
+				seen[rule[4]] = true
+				applications = append(applications, rule[4])
+				break
+			}
+		}
+	}
+	return applications
+}
@@ -0,0 +1,49 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"net/http"
+	"time"
+
+	middleware "github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/swag"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/audit"
+	auditOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/audit"
+	"github.com/vmware/dispatch/pkg/trace"
+)
+
+func (h *Handlers) getAuditRecords(params auditOperations.GetAuditRecordsParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	query := audit.Query{
+		Principal: swag.StringValue(params.Principal),
+	}
+	if params.Since != nil {
+		query.Since = time.Unix(*params.Since, 0)
+	}
+	if params.Until != nil {
+		query.Until = time.Unix(*params.Until, 0)
+	}
+	records, err := h.auditLog.List(ctx, params.XDispatchOrg, query)
+	if err != nil {
+		log.Errorf("store error when listing audit records: %+v", err)
+		return auditOperations.NewGetAuditRecordsInternalServerError().WithPayload(
+			&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String("internal server error when getting audit records"),
+			})
+	}
+	models := []*v1.AuditRecord{}
+	for _, record := range records {
+		models = append(models, record.ToModel())
+	}
+	return auditOperations.NewGetAuditRecordsOK().WithPayload(models)
+}
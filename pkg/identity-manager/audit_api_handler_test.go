@@ -0,0 +1,114 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/audit"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations"
+	auditOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/audit"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+func setupAuditTestAPI(t *testing.T) (*operations.IdentityManagerAPI, *audit.StoreSink) {
+	api := operations.NewIdentityManagerAPI(nil)
+	es := helpers.MakeEntityStore(t)
+	addTestData(es)
+	addTestRoleBindings(es)
+	sink := audit.NewStoreSink(es, 0, 0)
+	handlers := NewHandlers(nil, es, SetupEnforcer(es))
+	handlers.SetAuditSink(sink)
+	helpers.MakeAPI(t, handlers.ConfigureHandlers, api)
+	return api, sink
+}
+
+func getAuditRecords(t *testing.T, api *operations.IdentityManagerAPI, params auditOperations.GetAuditRecordsParams) []*v1.AuditRecord {
+	params.HTTPRequest = httptest.NewRequest("GET", "/v1/iam/audit", nil)
+	var records []*v1.AuditRecord
+	helpers.HandlerRequest(t, api.AuditGetAuditRecordsHandler.Handle(params, "super-admin@example.com"), &records, http.StatusOK)
+	return records
+}
+
+func TestAuthHandlerAudit(t *testing.T) {
+	api, _ := setupAuditTestAPI(t)
+
+	// Decisions on mutating requests and denied requests are recorded, allowed reads are not
+	params := newOrgAuthParams("", "/v1/function/billing-charge", "PUT")
+	params.HTTPRequest.Header.Set(audit.HeaderRequestID, "req-1")
+	helpers.HandlerRequest(t, api.AuthHandler.Handle(params, &Principal{Subject: "dev@example.com"}), nil, http.StatusAccepted)
+	helpers.HandlerRequest(t, api.AuthHandler.Handle(newOrgAuthParams("", "/v1/function/billing-charge", "DELETE"), &Principal{Subject: "dev@example.com"}), nil, http.StatusForbidden)
+	helpers.HandlerRequest(t, api.AuthHandler.Handle(newOrgAuthParams("", "/v1/function/payroll", "GET"), &Principal{Subject: "dev@example.com"}), nil, http.StatusForbidden)
+	helpers.HandlerRequest(t, api.AuthHandler.Handle(newOrgAuthParams("", "/v1/function", "GET"), &Principal{Subject: "readonly-user@example.com"}), nil, http.StatusAccepted)
+	helpers.HandlerRequest(t, api.AuthHandler.Handle(newOrgAuthParams("", "/v1/iam/policy/admins", "DELETE"), &Principal{Subject: "super-admin@example.com"}), nil, http.StatusAccepted)
+
+	records := getAuditRecords(t, api, auditOperations.GetAuditRecordsParams{})
+	require.Len(t, records, 4)
+	byOutcome := map[string][]*v1.AuditRecord{}
+	for _, r := range records {
+		assert.Equal(t, "identity-manager", r.Service)
+		byOutcome[r.Outcome] = append(byOutcome[r.Outcome], r)
+	}
+	require.Len(t, byOutcome[audit.OutcomeAllowed], 2)
+	require.Len(t, byOutcome[audit.OutcomeDenied], 2)
+	for _, r := range byOutcome[audit.OutcomeAllowed] {
+		if r.Principal == "user/dev@example.com" {
+			assert.Equal(t, "function", r.Resource)
+			assert.Equal(t, "billing-charge", r.Name)
+			assert.Equal(t, "update", r.Action)
+			assert.Equal(t, "req-1", r.RequestID)
+			assert.Equal(t, int64(http.StatusAccepted), r.Status)
+		} else {
+			// IAM resources are recorded by their type
+			assert.Equal(t, "user/super-admin@example.com", r.Principal)
+			assert.Equal(t, "policy", r.Resource)
+			assert.Equal(t, "admins", r.Name)
+			assert.Equal(t, "delete", r.Action)
+		}
+	}
+
+	// Records are filtered by principal and time
+	records = getAuditRecords(t, api, auditOperations.GetAuditRecordsParams{Principal: swag.String("user/super-admin@example.com")})
+	require.Len(t, records, 1)
+	assert.Equal(t, "policy", records[0].Resource)
+	records = getAuditRecords(t, api, auditOperations.GetAuditRecordsParams{Since: swag.Int64(time.Now().Add(time.Hour).Unix())})
+	assert.Empty(t, records)
+	records = getAuditRecords(t, api, auditOperations.GetAuditRecordsParams{Until: swag.Int64(time.Now().Add(-time.Hour).Unix())})
+	assert.Empty(t, records)
+}
+
+func TestGetAuditRecordsHandler(t *testing.T) {
+	api, sink := setupAuditTestAPI(t)
+
+	ctx := context.Background()
+	require.NoError(t, sink.Write(ctx, &audit.Record{
+		Service: "function-manager", Principal: "user/dev@example.com", Organization: IdentityManagerFlags.OrgID,
+		Resource: "function", Name: "hello", Action: "create", Outcome: audit.OutcomeSucceeded, Status: http.StatusCreated,
+		AfterRevision: 1,
+	}))
+	require.NoError(t, sink.Write(ctx, &audit.Record{
+		Service: "function-manager", Principal: "user/dev@example.com", Organization: "org-a",
+		Resource: "function", Name: "hello", Action: "delete", Outcome: audit.OutcomeSucceeded,
+	}))
+
+	// Only the records of the organization are listed
+	records := getAuditRecords(t, api, auditOperations.GetAuditRecordsParams{XDispatchOrg: IdentityManagerFlags.OrgID})
+	require.Len(t, records, 1)
+	assert.Equal(t, "function-manager", records[0].Service)
+	assert.Equal(t, "create", records[0].Action)
+	assert.Equal(t, int64(http.StatusCreated), records[0].Status)
+	assert.Equal(t, int64(1), records[0].AfterRevision)
+	assert.NotZero(t, records[0].Time)
+}
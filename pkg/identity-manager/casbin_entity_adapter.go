@@ -27,32 +27,92 @@ func NewCasbinEntityAdapter(store entitystore.EntityStore) *CasbinEntityAdapter
 	return &CasbinEntityAdapter{store: store}
 }
 
+// Sources of the casbin rules, the policy or the role binding defining them
+const (
+	sourcePolicy      = "policy"
+	sourceRoleBinding = "rolebinding"
+)
+
 // LoadPolicy loads a policy into the casbin entity adapter
 func (a *CasbinEntityAdapter) LoadPolicy(model casbinModel.Model) error {
-	var policies []*Policy
-
 	// We ignore policy status since the status is only meaningful to the controllers.
 	opts := entitystore.Options{
 		Filter: entitystore.FilterExists(),
 	}
 
 	log.Debug("Reloading policies")
-	err := a.store.List(context.TODO(), IdentityManagerFlags.OrgID, opts, &policies)
-	for _, policy := range policies {
-		// Casbin authorization rules are of the form (subject, resource, action) and hence the need to iterate over all rule fields.
-		for _, rule := range policy.Rules {
-			for _, subject := range rule.Subjects {
+	orgIDs, err := a.store.ListOrgIDs(context.TODO())
+	if err != nil {
+		return err
+	}
+	for _, orgID := range orgIDs {
+		var policies []*Policy
+		if err := a.store.List(context.TODO(), orgID, opts, &policies); err != nil {
+			return err
+		}
+		for _, policy := range policies {
+			// Casbin authorization rules are of the form (organization, subject, resource, action, application, source)
+			// and hence the need to iterate over all rule fields. Policies apply to all applications.
+			source := sourcePolicy + "/" + policy.Name
+			for _, rule := range policy.Rules {
+				for _, subject := range rule.Subjects {
+					for _, resource := range rule.Resources {
+						for _, action := range rule.Actions {
+							log.Debugf("Loading policy %s/%s: rule %s, %s, %s", orgID, policy.Name, subject, resource, action)
+							lineText := fmt.Sprintf("p, %s, %s, %s, %s, *, %s", orgID, subject, resource, action, source)
+							persist.LoadPolicyLine(lineText, model)
+						}
+					}
+				}
+			}
+		}
+		if err := a.loadRoleBindings(orgID, opts, model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadRoleBindings loads the rules of the roles granted by the role bindings of an organization
+func (a *CasbinEntityAdapter) loadRoleBindings(orgID string, opts entitystore.Options, model casbinModel.Model) error {
+	var roles []*Role
+	if err := a.store.List(context.TODO(), orgID, opts, &roles); err != nil {
+		return err
+	}
+	roleRules := make(map[string][]RoleRule)
+	for _, role := range roles {
+		roleRules[role.Name] = role.Rules
+	}
+	var bindings []*RoleBinding
+	if err := a.store.List(context.TODO(), orgID, opts, &bindings); err != nil {
+		return err
+	}
+	for _, binding := range bindings {
+		rules, ok := roleRules[binding.Role]
+		if !ok {
+			log.Warnf("Skipping role binding %s/%s: role %s not found", orgID, binding.Name, binding.Role)
+			continue
+		}
+		applications := binding.Applications
+		if len(applications) == 0 {
+			applications = []string{"*"}
+		}
+		source := sourceRoleBinding + "/" + binding.Name + "/" + binding.Role
+		for _, rule := range rules {
+			for _, subject := range binding.Subjects {
 				for _, resource := range rule.Resources {
 					for _, action := range rule.Actions {
-						log.Debugf("Loading policy %s: rule %s, %s, %s", policy.Name, subject, resource, action)
-						lineText := fmt.Sprintf("p, %s, %s, %s", subject, resource, action)
-						persist.LoadPolicyLine(lineText, model)
+						for _, application := range applications {
+							log.Debugf("Loading role binding %s/%s: rule %s, %s, %s, %s", orgID, binding.Name, subject, resource, action, application)
+							lineText := fmt.Sprintf("p, %s, %s, %s, %s, %s, %s", orgID, subject, resource, action, application, source)
+							persist.LoadPolicyLine(lineText, model)
+						}
 					}
 				}
 			}
 		}
 	}
-	return err
+	return nil
 }
 
 // SavePolicy saves all policy rules to the storage.
@@ -0,0 +1,173 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/casbin/casbin/util"
+	middleware "github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/swag"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/entity-store"
+	accessOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/access"
+	"github.com/vmware/dispatch/pkg/trace"
+)
+
+// resourceMatch returns whether the object of a request matches the resource of a rule, as the matcher of
+// casbinPolicyModel does. Rules on a resource apply to all its instances, e.g. function to function/billing-charge.
+func resourceMatch(object string, resource string) bool {
+	return util.KeyMatch(object, resource) || util.KeyMatch(object, resource+"/*")
+}
+
+// explainRule returns an access rule explaining whether a casbin rule, of the form (organization, subject, resource,
+// action, application, source), allows a request
+func explainRule(attrs *attributesRecord, rule []string) *v1.AccessRule {
+	r := &v1.AccessRule{
+		Subject:     rule[1],
+		Resource:    rule[2],
+		Action:      rule[3],
+		Application: rule[4],
+		Source:      rule[5],
+	}
+	// Role bindings sources are of the form rolebinding/<binding>/<role>
+	if parts := strings.Split(r.Source, "/"); len(parts) == 3 && parts[0] == sourceRoleBinding {
+		r.Source = parts[0] + "/" + parts[1]
+		r.Role = parts[2]
+	}
+	switch {
+	case !resourceMatch(attrs.object(), r.Resource):
+		r.Reason = fmt.Sprintf("resource %s doesn't match %s", attrs.object(), r.Resource)
+	case !util.KeyMatch(string(attrs.action), r.Action):
+		r.Reason = fmt.Sprintf("action %s doesn't match %s", attrs.action, r.Action)
+	case attrs.application == "" && !util.KeyMatch(attrs.application, r.Application):
+		r.Reason = fmt.Sprintf("the application of the request is unknown, the rule only applies to application %s", r.Application)
+	case !util.KeyMatch(attrs.application, r.Application):
+		r.Reason = fmt.Sprintf("application %s doesn't match %s", attrs.application, r.Application)
+	default:
+		r.Allowed = true
+		r.Reason = fmt.Sprintf("%s allows %s on %s", r.Subject, r.Action, r.Resource)
+	}
+	return r
+}
+
+// isCheckMember returns whether the subject of an access check is a member of the organization, as a user, one of its
+// groups or a service account of the organization
+func (h *Handlers) isCheckMember(ctx context.Context, orgID string, subject string, groups []string) (bool, error) {
+	var svcAccount ServiceAccount
+	found, err := h.store.Find(ctx, orgID, subject, entitystore.Options{Filter: entitystore.FilterExists()}, &svcAccount)
+	if err != nil || found {
+		return found, err
+	}
+	return h.isMember(ctx, orgID, subject, groups)
+}
+
+func (h *Handlers) checkAccess(params accessOperations.CheckAccessParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	badRequest := func(msg string) middleware.Responder {
+		return accessOperations.NewCheckAccessBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(msg),
+			})
+	}
+
+	attrs := &attributesRecord{
+		subject:           swag.StringValue(params.Subject),
+		action:            Action(swag.StringValue(params.Action)),
+		isResourceRequest: true,
+	}
+	switch attrs.action {
+	case ActionGet, ActionCreate, ActionUpdate, ActionDelete:
+	default:
+		return badRequest(fmt.Sprintf("invalid action %q, must be one of get, create, update or delete", attrs.action))
+	}
+	resource := strings.Trim(swag.StringValue(params.Resource), "/")
+	if resource == "" {
+		return badRequest("missing resource")
+	}
+	parts := strings.SplitN(resource, "/", 2)
+	attrs.resource = parts[0]
+	if len(parts) == 2 {
+		attrs.name = parts[1]
+	}
+	orgID := params.XDispatchOrg
+	attrs.application, _ = h.targetApplication(ctx, orgID, attrs, "/v1/"+resource)
+	if params.Application != nil {
+		attrs.application = *params.Application
+	}
+
+	// Check the caller by default, with their groups, otherwise the subject with the groups given
+	var groups []string
+	if attrs.subject == "" {
+		p := requestPrincipal(principal)
+		attrs.subject = p.Subject
+		groups = p.Groups
+	}
+	for _, group := range params.Group {
+		if !strings.HasPrefix(group, PolicySubjectGroupPrefix) {
+			group = PolicySubjectGroupPrefix + group
+		}
+		groups = append(groups, group)
+	}
+	if attrs.subject == "" {
+		return badRequest("missing subject")
+	}
+
+	decision := &v1.AccessDecision{
+		Subject:      attrs.subject,
+		Groups:       groups,
+		Organization: orgID,
+		Resource:     attrs.object(),
+		Action:       string(attrs.action),
+		Application:  attrs.application,
+	}
+	subjects := append([]string{attrs.subject}, groups...)
+	for _, rule := range h.enforcer.GetFilteredPolicy(0, orgID) {
+		for _, subject := range subjects {
+			if util.KeyMatch(subject, rule[1]) {
+				decision.Rules = append(decision.Rules, explainRule(attrs, rule))
+				break
+			}
+		}
+	}
+
+	member, err := h.isCheckMember(ctx, orgID, attrs.subject, groups)
+	if err != nil {
+		log.Errorf("store error when checking the members of organization %s: %+v", orgID, err)
+		return accessOperations.NewCheckAccessInternalServerError().WithPayload(
+			&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String("internal server error when checking access"),
+			})
+	}
+	allowed := false
+	switch {
+	case !member:
+		decision.Reason = fmt.Sprintf("%s is not a member of organization %s", attrs.subject, orgID)
+	case Resource(attrs.resource) == ResourceIAM && attrs.name == "organization" && orgID != IdentityManagerFlags.OrgID:
+		decision.Reason = fmt.Sprintf("organizations can only be managed from organization %s", IdentityManagerFlags.OrgID)
+	case h.enforce(orgID, attrs, groups):
+		allowed = true
+		for _, rule := range decision.Rules {
+			if rule.Allowed {
+				decision.Reason = fmt.Sprintf("allowed by %s", rule.Source)
+				break
+			}
+		}
+	default:
+		decision.Reason = fmt.Sprintf("no rule allows %s %s on %s", attrs.subject, attrs.action, attrs.object())
+	}
+	decision.Allowed = swag.Bool(allowed)
+	return accessOperations.NewCheckAccessOK().WithPayload(decision)
+}
@@ -0,0 +1,257 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware/dispatch/pkg/api-manager"
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations"
+	accessOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/access"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+func addTestRoleBindings(store entitystore.EntityStore) {
+	store.Add(context.Background(), &Role{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: IdentityManagerFlags.OrgID,
+			Name:           "billing-developer",
+			Status:         entitystore.StatusREADY,
+		},
+		Rules: []RoleRule{
+			{
+				Resources: []string{"function/billing-*"},
+				Actions:   []string{"get", "update"},
+			}},
+	})
+	store.Add(context.Background(), &Role{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: IdentityManagerFlags.OrgID,
+			Name:           "api-reader",
+			Status:         entitystore.StatusREADY,
+		},
+		Rules: []RoleRule{
+			{
+				Resources: []string{"api"},
+				Actions:   []string{"get"},
+			}},
+	})
+	store.Add(context.Background(), &RoleBinding{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: IdentityManagerFlags.OrgID,
+			Name:           "billing-developers",
+			Status:         entitystore.StatusREADY,
+		},
+		Role:     "billing-developer",
+		Subjects: []string{"dev@example.com", "group:billing"},
+	})
+	store.Add(context.Background(), &RoleBinding{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: IdentityManagerFlags.OrgID,
+			Name:           "billing-api-readers",
+			Status:         entitystore.StatusREADY,
+		},
+		Role:         "api-reader",
+		Subjects:     []string{"dev@example.com"},
+		Applications: []string{"billing"},
+	})
+	// The application of resources is the application tag of their entity
+	store.Add(context.Background(), &apimanager.API{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: IdentityManagerFlags.OrgID,
+			Name:           "invoices",
+			Status:         entitystore.StatusREADY,
+			Tags:           entitystore.Tags{"Application": "billing"},
+		},
+	})
+	store.Add(context.Background(), &apimanager.API{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: IdentityManagerFlags.OrgID,
+			Name:           "salaries",
+			Status:         entitystore.StatusREADY,
+			Tags:           entitystore.Tags{"Application": "payroll"},
+		},
+	})
+	// Bindings to missing roles grant nothing
+	store.Add(context.Background(), &RoleBinding{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: IdentityManagerFlags.OrgID,
+			Name:           "missing-role",
+			Status:         entitystore.StatusREADY,
+		},
+		Role:     "missing",
+		Subjects: []string{"*"},
+	})
+}
+
+func setupRoleBindingTestAPI(t *testing.T) *operations.IdentityManagerAPI {
+	api := operations.NewIdentityManagerAPI(nil)
+	es := helpers.MakeEntityStore(t)
+	addTestData(es)
+	addTestRoleBindings(es)
+	addTestOrganization(es, "org-a", "org-admin@example.com")
+	handlers := NewHandlers(nil, es, SetupEnforcer(es))
+	helpers.MakeAPI(t, handlers.ConfigureHandlers, api)
+	return api
+}
+
+func checkAccess(t *testing.T, api *operations.IdentityManagerAPI, params accessOperations.CheckAccessParams) *v1.AccessDecision {
+	params.HTTPRequest = httptest.NewRequest("GET", "/v1/iam/check", nil)
+	var decision v1.AccessDecision
+	helpers.HandlerRequest(t, api.AccessCheckAccessHandler.Handle(params, &Principal{Subject: "dev@example.com"}), &decision, http.StatusOK)
+	return &decision
+}
+
+func TestCheckAccessHandler(t *testing.T) {
+	api := setupRoleBindingTestAPI(t)
+
+	// The caller is checked by default
+	decision := checkAccess(t, api, accessOperations.CheckAccessParams{
+		Action:   swag.String("update"),
+		Resource: swag.String("function/billing-charge"),
+	})
+	assert.True(t, *decision.Allowed)
+	assert.Equal(t, "dev@example.com", decision.Subject)
+	assert.Equal(t, "function/billing-charge", decision.Resource)
+	assert.Equal(t, "allowed by rolebinding/billing-developers", decision.Reason)
+	var allowing []*v1.AccessRule
+	for _, rule := range decision.Rules {
+		if rule.Allowed {
+			allowing = append(allowing, rule)
+		}
+	}
+	if assert.Len(t, allowing, 1) {
+		assert.Equal(t, "billing-developer", allowing[0].Role)
+		assert.Equal(t, "function/billing-*", allowing[0].Resource)
+	}
+
+	decision = checkAccess(t, api, accessOperations.CheckAccessParams{
+		Action:   swag.String("delete"),
+		Resource: swag.String("function/billing-charge"),
+	})
+	assert.False(t, *decision.Allowed)
+	assert.Equal(t, "no rule allows dev@example.com delete on function/billing-charge", decision.Reason)
+
+	decision = checkAccess(t, api, accessOperations.CheckAccessParams{
+		Action:   swag.String("get"),
+		Resource: swag.String("function/payroll"),
+	})
+	assert.False(t, *decision.Allowed)
+
+	// Groups are checked with the subject
+	decision = checkAccess(t, api, accessOperations.CheckAccessParams{
+		Subject:  swag.String("someone@example.com"),
+		Group:    []string{"billing"},
+		Action:   swag.String("get"),
+		Resource: swag.String("function/billing-charge"),
+	})
+	assert.True(t, *decision.Allowed)
+	assert.Equal(t, []string{"group:billing"}, decision.Groups)
+
+	// Policies apply to all the instances of a resource
+	decision = checkAccess(t, api, accessOperations.CheckAccessParams{
+		Subject:  swag.String("readonly-user@example.com"),
+		Action:   swag.String("get"),
+		Resource: swag.String("function/payroll"),
+	})
+	assert.True(t, *decision.Allowed)
+	assert.Equal(t, "allowed by policy/test-policy-1", decision.Reason)
+}
+
+func TestCheckAccessHandlerApplication(t *testing.T) {
+	api := setupRoleBindingTestAPI(t)
+
+	decision := checkAccess(t, api, accessOperations.CheckAccessParams{
+		Action:      swag.String("get"),
+		Resource:    swag.String("api"),
+		Application: swag.String("billing"),
+	})
+	assert.True(t, *decision.Allowed)
+
+	decision = checkAccess(t, api, accessOperations.CheckAccessParams{
+		Action:      swag.String("get"),
+		Resource:    swag.String("api"),
+		Application: swag.String("payroll"),
+	})
+	assert.False(t, *decision.Allowed)
+
+	// Without application, rules scoped to applications don't apply
+	decision = checkAccess(t, api, accessOperations.CheckAccessParams{
+		Action:   swag.String("get"),
+		Resource: swag.String("api"),
+	})
+	assert.False(t, *decision.Allowed)
+	for _, rule := range decision.Rules {
+		if rule.Source == "rolebinding/billing-api-readers" {
+			assert.Equal(t, "the application of the request is unknown, the rule only applies to application billing", rule.Reason)
+		}
+	}
+
+	// The application is resolved from the resource
+	decision = checkAccess(t, api, accessOperations.CheckAccessParams{
+		Action:   swag.String("get"),
+		Resource: swag.String("api/invoices"),
+	})
+	assert.True(t, *decision.Allowed)
+	assert.Equal(t, "billing", decision.Application)
+
+	decision = checkAccess(t, api, accessOperations.CheckAccessParams{
+		Action:   swag.String("get"),
+		Resource: swag.String("api/salaries"),
+	})
+	assert.False(t, *decision.Allowed)
+	assert.Equal(t, "payroll", decision.Application)
+
+	// The application resource names the application
+	decision = checkAccess(t, api, accessOperations.CheckAccessParams{
+		Action:   swag.String("get"),
+		Resource: swag.String("application/billing"),
+	})
+	assert.Equal(t, "billing", decision.Application)
+}
+
+func TestCheckAccessHandlerOrganization(t *testing.T) {
+	api := setupRoleBindingTestAPI(t)
+
+	decision := checkAccess(t, api, accessOperations.CheckAccessParams{
+		XDispatchOrg: "org-a",
+		Action:       swag.String("get"),
+		Resource:     swag.String("function"),
+	})
+	assert.False(t, *decision.Allowed)
+	assert.Equal(t, "dev@example.com is not a member of organization org-a", decision.Reason)
+
+	decision = checkAccess(t, api, accessOperations.CheckAccessParams{
+		XDispatchOrg: "org-a",
+		Subject:      swag.String("org-admin@example.com"),
+		Action:       swag.String("get"),
+		Resource:     swag.String("iam/organization"),
+	})
+	assert.False(t, *decision.Allowed)
+	assert.Equal(t, "organizations can only be managed from organization ", decision.Reason)
+}
+
+func TestCheckAccessHandlerBadRequest(t *testing.T) {
+	api := setupRoleBindingTestAPI(t)
+
+	params := accessOperations.CheckAccessParams{
+		HTTPRequest: httptest.NewRequest("GET", "/v1/iam/check", nil),
+		Action:      swag.String("list"),
+		Resource:    swag.String("function"),
+	}
+	helpers.HandlerRequest(t, api.AccessCheckAccessHandler.Handle(params, &Principal{Subject: "dev@example.com"}), &v1.Error{}, http.StatusBadRequest)
+	params.Action = swag.String("get")
+	params.Resource = nil
+	helpers.HandlerRequest(t, api.AccessCheckAccessHandler.Handle(params, &Principal{Subject: "dev@example.com"}), &v1.Error{}, http.StatusBadRequest)
+}
@@ -14,14 +14,19 @@ import (
 	"github.com/vmware/dispatch/pkg/entity-store"
 )
 
-// NewIdentityController creates a new controller to manage the reconciliation of policy entities
+// NewIdentityController creates a new controller to manage the reconciliation of policy, organization, role and
+// role binding entities
 func NewIdentityController(store entitystore.EntityStore, enforcer *casbin.SyncedEnforcer) controller.Controller {
 	c := controller.NewController(controller.Options{
 		ResyncPeriod: time.Duration(IdentityManagerFlags.ResyncPeriod) * time.Second,
 		Workers:      5, // TODO: make this configurable
+		Store:        store,
 	})
 
 	c.AddEntityHandler(&policyEntityHandler{store: store, enforcer: enforcer})
+	c.AddEntityHandler(&organizationEntityHandler{store: store, enforcer: enforcer})
+	c.AddEntityHandler(&roleEntityHandler{store: store, enforcer: enforcer})
+	c.AddEntityHandler(&roleBindingEntityHandler{store: store, enforcer: enforcer})
 
 	return c
 }
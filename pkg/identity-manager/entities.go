@@ -8,6 +8,8 @@ package identitymanager
 // NO TEST
 
 import (
+	"time"
+
 	entitystore "github.com/vmware/dispatch/pkg/entity-store"
 )
 
@@ -31,9 +33,87 @@ type ServiceAccount struct {
 	PublicKey    string `json:"publicKey"`
 	Domain       string `json:"domain"`
 	JWTAlgorithm string `json:"jwtAlgorithm"`
+	// Keys are the active public keys, the public key first. Several keys are active while rotating keys.
+	Keys []ServiceAccountKey `json:"keys,omitempty"`
+	// RevokedTime revokes the tokens of the service account issued before it
+	RevokedTime time.Time `json:"revokedTime,omitempty"`
+}
+
+// ServiceAccountKey is a data struct to store a public key of a service account
+type ServiceAccountKey struct {
+	ID          string    `json:"id"`
+	PublicKey   string    `json:"publicKey"`
+	Algorithm   string    `json:"algorithm"`
+	CreatedTime time.Time `json:"createdTime"`
+}
+
+// RevokedToken is a data struct used to store the revoked tokens into entity store, named after the token ID. It is
+// kept until the token expires.
+type RevokedToken struct {
+	entitystore.BaseEntity
+	ServiceAccount string    `json:"serviceAccount"`
+	ExpiresTime    time.Time `json:"expiresTime"`
 }
 
-// Organization is a data struct used to store organization (tenants) into entity store
+// TTL returns the time left until the token expires, the revoked token is deleted then by the stores supporting it,
+// and pruned otherwise
+func (t *RevokedToken) TTL() time.Duration {
+	if t.ExpiresTime.IsZero() {
+		return 0
+	}
+	return time.Until(t.ExpiresTime)
+}
+
+// APIToken is a data struct used to store the personal API tokens of users into entity store, named after the token
+// ID. Only the hash of the token secret is stored. API tokens are stored in the default organization.
+type APIToken struct {
+	entitystore.BaseEntity
+	Description string    `json:"description,omitempty"`
+	Owner       string    `json:"owner"`
+	SecretHash  string    `json:"secretHash"`
+	ExpiresTime time.Time `json:"expiresTime,omitempty"`
+	// Resources and Actions restrict the permissions of the owner granted to the token, all when empty
+	Resources []string `json:"resources,omitempty"`
+	Actions   []string `json:"actions,omitempty"`
+}
+
+// Organization is a data struct used to store organization (tenants) into entity store. Organizations are stored in
+// the default organization.
 type Organization struct {
 	entitystore.BaseEntity
+	// Members are the users of the organization, service accounts are members of the organization they belong to
+	Members []string `json:"members,omitempty"`
+}
+
+// RoleRule is a data struct to store the permissions of a role
+type RoleRule struct {
+	Resources []string `json:"resources"`
+	Actions   []string `json:"actions"`
+}
+
+// Role is a data struct used to store roles, named sets of permissions, into entity store
+type Role struct {
+	entitystore.BaseEntity
+	Rules []RoleRule `json:"rules"`
+}
+
+// RoleBinding is a data struct used to store role bindings into entity store. A role binding grants a role to users,
+// groups and service accounts, optionally on some applications only.
+type RoleBinding struct {
+	entitystore.BaseEntity
+	Role         string   `json:"role"`
+	Subjects     []string `json:"subjects"`
+	Applications []string `json:"applications,omitempty"`
+}
+
+// Setup is a data struct used to store the state of the first-run setup into entity store, in the default
+// organization. Only the hash of the single-use setup token is stored. Once the setup is completed, the bootstrap mode
+// is permanently disabled.
+type Setup struct {
+	entitystore.BaseEntity
+	TokenHash     string    `json:"tokenHash,omitempty"`
+	Completed     bool      `json:"completed"`
+	CompletedTime time.Time `json:"completedTime,omitempty"`
+	// Admins are the subjects of the policy created by the setup
+	Admins []string `json:"admins,omitempty"`
 }
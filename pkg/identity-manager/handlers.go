@@ -7,13 +7,15 @@ package identitymanager
 
 import (
 	"context"
-	"encoding/base64"
+	"crypto"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/casbin/casbin"
 	jwt "github.com/dgrijalva/jwt-go"
@@ -25,19 +27,32 @@ import (
 	"github.com/vmware/dispatch/pkg/version"
 
 	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/audit"
+	"github.com/vmware/dispatch/pkg/client"
 	"github.com/vmware/dispatch/pkg/controller"
 	"github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations"
+	accessOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/access"
+	apiTokenOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/apitoken"
+	auditOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/audit"
 	orgOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/organization"
 	policyOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/policy"
+	roleOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/role"
+	roleBindingOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/rolebinding"
 	svcAccountOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/serviceaccount"
+	setupOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/setup"
+	tokenOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/token"
+	"github.com/vmware/dispatch/pkg/identity-manager/keys"
+	"github.com/vmware/dispatch/pkg/identity-manager/oidc"
+	"github.com/vmware/dispatch/pkg/trace"
 )
 
 // IdentityManagerFlags are configuration flags for the identity manager
 var IdentityManagerFlags = struct {
 	CookieName           string `long:"cookie-name" description:"The cookie name used to identify users" default:"_oauth2_proxy"`
 	SkipAuth             bool   `long:"skip-auth" description:"Skips authorization, not to be used in production env"`
-	BootstrapConfigPath  string `long:"bootstrap-config-path" description:"The path that contains the bootstrap keys" default:"/bootstrap"`
+	BootstrapConfigPath  string `long:"bootstrap-config-path" description:"The path that contains the bootstrap keys, ignored once the setup is completed" default:"/bootstrap"`
+	SetupTokenFile       string `long:"setup-token-file" description:"The file the single-use setup token is written to, until the setup is completed" default:""`
 	DbFile               string `long:"db-file" description:"Backend DB URL/Path" default:"./db.bolt"`
 	DbBackend            string `long:"db-backend" description:"Backend DB Name" default:"boltdb"`
 	DbUser               string `long:"db-username" description:"Backend DB Username" default:"dispatch"`
@@ -45,22 +60,32 @@ var IdentityManagerFlags = struct {
 	DbDatabase           string `long:"db-database" description:"Backend DB Name" default:"dispatch"`
 	ResyncPeriod         int    `long:"resync-period" description:"The time period (in seconds) to refresh policies" default:"30"`
 	OAuth2ProxyAuthURL   string `long:"oauth2-proxy-auth-url" description:"The localhost url for oauth2proxy service's auth endpoint'" default:"http://localhost:4180/v1/iam/oauth2/auth"`
+	OIDCIssuer           string `long:"oidc-issuer" description:"The OpenID Connect issuer of the ID tokens authenticating users, replaces oauth2proxy" default:""`
+	OIDCClientID         string `long:"oidc-client-id" description:"The OpenID Connect client the ID tokens are issued to" default:"dispatch-cli"`
+	OIDCGroupsClaim      string `long:"oidc-groups-claim" description:"The ID token claim listing the groups of users" default:"groups"`
 	ServiceAccountDomain string `long:"service-account-domain" description:"The default domain name to use for service accounts" default:"svc.dispatch.local"`
-	OrgID                string `long:"organization" description:"(temporary) Static organization id" default:"dispatch"`
+	TokenSigningKey      string `long:"token-signing-key" description:"The path of the PEM private key signing the access tokens issued to service accounts, a key is generated when not set" default:""`
+	TokenLifetime        int    `long:"token-lifetime" description:"The lifetime (in seconds) of the access tokens issued to service accounts, when not requested" default:"900"`
+	TokenMaxLifetime     int    `long:"token-max-lifetime" description:"The maximum lifetime (in seconds) of the access tokens issued to service accounts" default:"3600"`
+	AssertionMaxLifetime int    `long:"assertion-max-lifetime" description:"The maximum lifetime (in seconds) of the tokens signed by service accounts, 0 for no limit" default:"3600"`
+	TokenAudience        string `long:"token-audience" description:"The audience of the tokens, tokens with an aud claim must include it" default:"dispatch"`
+	OrgID                string `long:"organization" description:"The default organization, of the requests not selecting one" default:"dispatch"`
 	Tracer               string `long:"tracer" description:"Open Tracing Tracer endpoint" default:""`
 }{}
 
 const (
-	// Policy Model - Use an ACL model that matches request attributes
+	// Policy Model - Use an ACL model that matches request attributes. The object of a request is a resource, or a
+	// resource instance, e.g. function/billing-charge. Rules on a resource apply to all its instances. The source of
+	// rules is the policy or the role binding defining them, only used to explain decisions.
 	casbinPolicyModel = `
 [request_definition]
-r = sub, obj, act
+r = org, sub, obj, act, app
 [policy_definition]
-p = sub, obj, act
+p = org, sub, obj, act, app, src
 [policy_effect]
 e = some(where (p.eft == allow))
 [matchers]
-m = keyMatch(r.sub, p.sub) && keyMatch(r.obj, p.obj) && keyMatch(r.act, p.act)
+m = r.org == p.org && keyMatch(r.sub, p.sub) && (keyMatch(r.obj, p.obj) || keyMatch(r.obj, p.obj + "/*")) && keyMatch(r.act, p.act) && keyMatch(r.app, p.app)
 `
 )
 
@@ -69,8 +94,17 @@ const (
 	HTTPHeaderReqURI     = "X-Auth-Request-Redirect"
 	HTTPHeaderOrigMethod = "X-Original-Method"
 	HTTPHeaderEmail      = "X-Auth-Request-Email"
+	HTTPHeaderOrg        = "X-Dispatch-Org"
 )
 
+// JWTClaimOrg is the claim of service account tokens naming the organization of the service account, tokens without
+// it are issued by service accounts of the default organization
+const JWTClaimOrg = "org"
+
+// PolicySubjectGroupPrefix prefixes the groups of users in policy subjects, e.g. group:admins applies to the users of
+// the admins group
+const PolicySubjectGroupPrefix = "group:"
+
 // Identity manager action constants
 const (
 	ActionGet    Action = "get"
@@ -84,7 +118,8 @@ type Action string
 
 // Identity manager resources type constants
 const (
-	ResourceIAM Resource = "iam"
+	ResourceIAM         Resource = "iam"
+	ResourceApplication Resource = "application"
 )
 
 // Resource defines the type for a resource
@@ -95,6 +130,14 @@ type Handlers struct {
 	watcher  controller.Watcher
 	store    entitystore.EntityStore
 	enforcer *casbin.SyncedEnforcer
+	oidc     *oidc.Provider
+	tokens   *TokenIssuer
+	audit    audit.Sink
+	auditLog *audit.StoreSink
+
+	// setupCompleted is set once the first-run setup is completed, disabling the bootstrap mode
+	setupCompleted   int32
+	bootstrapWarning sync.Once
 }
 
 // NewHandlers create a new Policy Manager Handler
@@ -103,9 +146,20 @@ func NewHandlers(watcher controller.Watcher, store entitystore.EntityStore, enfo
 		watcher:  watcher,
 		store:    store,
 		enforcer: enforcer,
+		auditLog: audit.NewStoreSink(store, 0, 0),
 	}
 }
 
+// SetAuditSink sets the sink the authorization decisions are recorded to
+func (h *Handlers) SetAuditSink(sink audit.Sink) {
+	h.audit = sink
+}
+
+// SetOIDCProvider sets the OpenID Connect provider authenticating users, instead of oauth2proxy
+func (h *Handlers) SetOIDCProvider(provider *oidc.Provider) {
+	h.oidc = provider
+}
+
 // SetupEnforcer sets up the casbin enforcer
 func SetupEnforcer(store entitystore.EntityStore) *casbin.SyncedEnforcer {
 	model := casbin.NewModel(casbinPolicyModel)
@@ -118,7 +172,17 @@ func (h *Handlers) authenticateCookie(token string) (interface{}, error) {
 	// For testing/dev environments only
 	if IdentityManagerFlags.SkipAuth {
 		log.Warn("Skipping authentication. This is not recommended in production environments.")
-		return "", nil
+		return &Principal{}, nil
+	}
+	if h.oidc != nil {
+		// The session cookie holds the ID token of the user
+		idToken, ok := sessionToken(token)
+		if !ok {
+			msg := "authentication failed: missing %s cookie"
+			log.Debugf(msg, IdentityManagerFlags.CookieName)
+			return nil, apiErrors.New(http.StatusUnauthorized, msg, IdentityManagerFlags.CookieName)
+		}
+		return h.authenticateIDToken(idToken)
 	}
 	// Make a request to Oauth2Proxy to validate the cookie. Oauth2Proxy must be setup locally
 	proxyReq, err := http.NewRequest(http.MethodGet, IdentityManagerFlags.OAuth2ProxyAuthURL, nil)
@@ -150,14 +214,14 @@ func (h *Handlers) authenticateCookie(token string) (interface{}, error) {
 		return nil, apiErrors.New(http.StatusUnauthorized, msg, HTTPHeaderEmail)
 	}
 	// Valid Cookie return the subject
-	return principal, nil
+	return &Principal{Subject: principal}, nil
 }
 
 func (h *Handlers) authenticateBearer(token string) (interface{}, error) {
 	// For testing/dev environments only
 	if IdentityManagerFlags.SkipAuth {
 		log.Warn("Skipping authentication. This is not recommended in production environments.")
-		return "", nil
+		return &Principal{}, nil
 	}
 
 	parts := strings.Split(token, " ")
@@ -168,22 +232,95 @@ func (h *Handlers) authenticateBearer(token string) (interface{}, error) {
 	}
 
 	jwtToken := parts[1]
+	if isAPIToken(jwtToken) {
+		return h.authenticateAPIToken(jwtToken)
+	}
+	if h.isIDToken(jwtToken) {
+		return h.authenticateIDToken(jwtToken)
+	}
 	claims, err := h.parseAndValidateToken(jwtToken)
+	if err == nil {
+		var revoked bool
+		if revoked, err = h.isRevoked(context.TODO(), claims); err == nil && revoked {
+			err = errors.Errorf("token %s has been revoked", claims[JWTClaimID])
+		}
+	}
 	if err != nil {
 		msg := "unable to validate bearer token: %s"
 		log.Debugf(msg, err)
 		return nil, apiErrors.New(http.StatusUnauthorized, msg, err)
 	}
-	// Valid token - return issuer as principal
-	return claims["iss"].(string), nil
+	// Valid token - return the service account as principal, in the organization of the token
+	return &Principal{
+		Subject:        tokenSubject(claims),
+		Organization:   tokenOrganization(claims),
+		ServiceAccount: true,
+	}, nil
+}
+
+// isIDToken returns whether a token was issued by the OpenID Connect provider, not by a service account
+func (h *Handlers) isIDToken(token string) bool {
+	if h.oidc == nil {
+		return false
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(token, claims); err != nil {
+		return false
+	}
+	return h.oidc.IsIDToken(claims)
+}
+
+// authenticateIDToken verifies the ID token of a user, and returns the user email as principal, with its groups. The
+// email identifies the user only once verified by the provider, otherwise anyone could claim it.
+func (h *Handlers) authenticateIDToken(token string) (interface{}, error) {
+	idToken, err := h.oidc.Verify(context.TODO(), token)
+	if err != nil {
+		msg := "unable to validate id token: %s"
+		log.Debugf(msg, err)
+		return nil, apiErrors.New(http.StatusUnauthorized, msg, err)
+	}
+	principal := &Principal{Subject: idToken.Subject}
+	if idToken.Email != "" && idToken.EmailVerified {
+		principal.Subject = idToken.Email
+	}
+	for _, group := range idToken.Strings(IdentityManagerFlags.OIDCGroupsClaim) {
+		principal.Groups = append(principal.Groups, PolicySubjectGroupPrefix+group)
+	}
+	return principal, nil
+}
+
+// authenticateAPIToken verifies the personal API token of a user, and returns its owner as principal
+func (h *Handlers) authenticateAPIToken(token string) (interface{}, error) {
+	e, err := h.verifyAPIToken(context.TODO(), token, time.Now())
+	if err != nil {
+		msg := "unable to validate API token: %s"
+		log.Debugf(msg, err)
+		return nil, apiErrors.New(http.StatusUnauthorized, msg, err)
+	}
+	return &Principal{Subject: e.Owner, APIToken: e}, nil
+}
+
+// sessionToken returns the value of the session cookie from a Cookie header
+func sessionToken(header string) (string, bool) {
+	request := http.Request{Header: http.Header{"Cookie": {header}}}
+	cookie, err := request.Cookie(IdentityManagerFlags.CookieName)
+	if err != nil || cookie.Value == "" {
+		return "", false
+	}
+	return cookie.Value, true
 }
 
 func (h *Handlers) parseAndValidateToken(token string) (jwt.MapClaims, error) {
+	var svcAccount *ServiceAccount
 	parsedToken, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
 		// Validate algorithm is same as expected. This is important after the vulnerabilities with JWT using asymmetric
 		// keys that don't validate the algorithm.
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		default:
+			if token.Method != keys.SigningMethodEdDSA {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
 		}
 		// Lookup
 		claims := token.Claims.(jwt.MapClaims)
@@ -191,41 +328,51 @@ func (h *Handlers) parseAndValidateToken(token string) (jwt.MapClaims, error) {
 			unverifiedIssuer := s.(string)
 			log.Debugf("Identified issuer %s from unvalidated token", unverifiedIssuer)
 
-			var pubBase64Encoded string
+			if unverifiedIssuer == AccessTokenIssuer {
+				// Access token issued to a service account, which must still exist
+				if h.tokens == nil {
+					return nil, errors.New("access tokens are not issued by this identity manager")
+				}
+				subject, _ := claims[JWTClaimSubject].(string)
+				account, err := h.getTokenServiceAccount(claims, subject)
+				if err != nil {
+					return nil, err
+				}
+				svcAccount = account
+				return h.tokens.verificationKey(token)
+			}
 
+			var publicKey crypto.PublicKey
 			// Get Public Key from secret if bootstrap mode is enabled
-			if bootstrapUser := getBootstrapKey("bootstrap_user"); bootstrapUser == unverifiedIssuer {
-				log.Warn("Bootstrap mode is enabled. Please ensure it is turned off in a production environment.")
-				if bootstrapPubKey := getBootstrapKey("bootstrap_public_key"); bootstrapPubKey != "" {
-					pubBase64Encoded = bootstrapPubKey
-				} else {
+			if bootstrapUser := h.bootstrapKey(context.Background(), "bootstrap_user"); bootstrapUser == unverifiedIssuer {
+				bootstrapPubKey := h.bootstrapKey(context.Background(), "bootstrap_public_key")
+				if bootstrapPubKey == "" {
 					msg := "missing public key in bootstrap mode"
 					log.Debugf(msg)
 					return nil, errors.New(msg)
 				}
+				key, err := keys.ParseEncodedPublicKey(bootstrapPubKey)
+				if err != nil {
+					return nil, errors.Wrap(err, "error while parsing public key")
+				}
+				publicKey = key
 			} else {
-				// Fetch Public Key from service account record
-				svcAccount := ServiceAccount{}
-				opts := entitystore.Options{
-					Filter: entitystore.FilterExists(),
+				// Fetch Public Key from service account record, selected by the key ID of the token
+				account, err := h.getTokenServiceAccount(claims, unverifiedIssuer)
+				if err != nil {
+					return nil, err
 				}
-				log.Debugf("Fetching service account %s from backend", unverifiedIssuer)
-				if err := h.store.Get(context.TODO(), IdentityManagerFlags.OrgID, unverifiedIssuer, opts, &svcAccount); err != nil {
-					return nil, errors.Wrap(err, fmt.Sprintf("store error when getting service account %s", unverifiedIssuer))
+				svcAccount = account
+				key, err := serviceAccountKey(svcAccount, token)
+				if err != nil {
+					return nil, errors.Wrap(err, "error while parsing public key")
 				}
-				pubBase64Encoded = svcAccount.PublicKey
+				publicKey = key
 			}
-
-			// Decode and validate token with the Public Key
-			pubPEM, err := base64.StdEncoding.DecodeString(pubBase64Encoded)
-			publicRSAKey, err := jwt.ParseRSAPublicKeyFromPEM(pubPEM)
-			if err != nil {
-				return nil, errors.Wrap(err, "error while parsing public key")
+			if !keys.Verifies(token.Method, publicKey) {
+				return nil, fmt.Errorf("signing method %v does not match the public key", token.Header["alg"])
 			}
-			// TODO: Validate Audience claim to ensure the token was issued to this Dispatch Service. Technically speaking
-			// the public key must not be re-used for another Dispatch service but it's best to validae this.
-			// TODO: Validate Token issued duration was not more than 1 hour (or min duration setting)
-			return publicRSAKey, nil
+			return publicKey, nil
 		}
 		// Missing issuer claim
 		return nil, errors.New("missing issuer claim in unvalidated token")
@@ -237,6 +384,14 @@ func (h *Handlers) parseAndValidateToken(token string) (jwt.MapClaims, error) {
 	}
 
 	if claims, ok := parsedToken.Claims.(jwt.MapClaims); ok && parsedToken.Valid {
+		if err := validateTokenPolicy(claims, time.Now()); err != nil {
+			return nil, errors.Wrap(err, "invalid bearer token")
+		}
+		if svcAccount != nil && !svcAccount.RevokedTime.IsZero() {
+			if issued, _ := claimTime(claims, JWTClaimIssuedAt); issued.Before(svcAccount.RevokedTime) {
+				return nil, errors.New("invalid bearer token: the tokens of the service account have been revoked")
+			}
+		}
 		// Token is valid and we return the claims
 		return claims, nil
 	}
@@ -245,6 +400,20 @@ func (h *Handlers) parseAndValidateToken(token string) (jwt.MapClaims, error) {
 	return nil, errors.New("invalid bearer token")
 }
 
+// getTokenServiceAccount fetches the service account authenticated by a token from the organization of the token
+func (h *Handlers) getTokenServiceAccount(claims jwt.MapClaims, name string) (*ServiceAccount, error) {
+	svcAccount := ServiceAccount{}
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	orgID := tokenOrganization(claims)
+	log.Debugf("Fetching service account %s of organization %s from backend", name, orgID)
+	if err := h.store.Get(context.TODO(), orgID, name, opts, &svcAccount); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("store error when getting service account %s", name))
+	}
+	return &svcAccount, nil
+}
+
 // ConfigureHandlers registers the identity manager handlers to the API
 func (h *Handlers) ConfigureHandlers(api middleware.RoutableAPI) {
 
@@ -280,6 +449,31 @@ func (h *Handlers) ConfigureHandlers(api middleware.RoutableAPI) {
 	a.OrganizationGetOrganizationsHandler = orgOperations.GetOrganizationsHandlerFunc(h.getOrganizations)
 	a.OrganizationDeleteOrganizationHandler = orgOperations.DeleteOrganizationHandlerFunc(h.deleteOrganization)
 	a.OrganizationUpdateOrganizationHandler = orgOperations.UpdateOrganizationHandlerFunc(h.updateOrganization)
+	// Role API Handlers
+	a.RoleAddRoleHandler = roleOperations.AddRoleHandlerFunc(h.addRole)
+	a.RoleGetRoleHandler = roleOperations.GetRoleHandlerFunc(h.getRole)
+	a.RoleGetRolesHandler = roleOperations.GetRolesHandlerFunc(h.getRoles)
+	a.RoleDeleteRoleHandler = roleOperations.DeleteRoleHandlerFunc(h.deleteRole)
+	a.RoleUpdateRoleHandler = roleOperations.UpdateRoleHandlerFunc(h.updateRole)
+	// Role Binding API Handlers
+	a.RolebindingAddRoleBindingHandler = roleBindingOperations.AddRoleBindingHandlerFunc(h.addRoleBinding)
+	a.RolebindingGetRoleBindingHandler = roleBindingOperations.GetRoleBindingHandlerFunc(h.getRoleBinding)
+	a.RolebindingGetRoleBindingsHandler = roleBindingOperations.GetRoleBindingsHandlerFunc(h.getRoleBindings)
+	a.RolebindingDeleteRoleBindingHandler = roleBindingOperations.DeleteRoleBindingHandlerFunc(h.deleteRoleBinding)
+	a.RolebindingUpdateRoleBindingHandler = roleBindingOperations.UpdateRoleBindingHandlerFunc(h.updateRoleBinding)
+	// Access API Handlers
+	a.AccessCheckAccessHandler = accessOperations.CheckAccessHandlerFunc(h.checkAccess)
+	// Audit API Handlers
+	a.AuditGetAuditRecordsHandler = auditOperations.GetAuditRecordsHandlerFunc(h.getAuditRecords)
+	// Token API Handlers
+	a.TokenIssueTokenHandler = tokenOperations.IssueTokenHandlerFunc(h.issueToken)
+	a.TokenRevokeTokenHandler = tokenOperations.RevokeTokenHandlerFunc(h.revokeToken)
+	a.SetupCompleteSetupHandler = setupOperations.CompleteSetupHandlerFunc(h.completeSetup)
+	// API Token API Handlers
+	a.ApitokenAddAPITokenHandler = apiTokenOperations.AddAPITokenHandlerFunc(h.addAPIToken)
+	a.ApitokenGetAPITokenHandler = apiTokenOperations.GetAPITokenHandlerFunc(h.getAPIToken)
+	a.ApitokenGetAPITokensHandler = apiTokenOperations.GetAPITokensHandlerFunc(h.getAPITokens)
+	a.ApitokenDeleteAPITokenHandler = apiTokenOperations.DeleteAPITokenHandlerFunc(h.deleteAPIToken)
 }
 
 func (h *Handlers) root(params operations.RootParams) middleware.Responder {
@@ -295,15 +489,114 @@ func (h *Handlers) home(params operations.HomeParams, principal interface{}) mid
 		&v1.Message{Message: swag.String(message)})
 }
 
+// Principal is the subject authenticated by a request, with the attributes verified from its credentials. The
+// authorization of the request relies on them only, never on the request headers: a request may carry several
+// credentials, of which only one was verified.
+type Principal struct {
+	Subject string
+	// Organization is the organization of a service account
+	Organization   string
+	ServiceAccount bool
+	// Groups are the policy subjects of the groups of a user authenticated by an ID token, e.g. group:admins
+	Groups []string
+	// APIToken is the API token authenticating a user, if any
+	APIToken *APIToken
+}
+
+// requestPrincipal returns the principal returned by the authentication of a request
+func requestPrincipal(principal interface{}) *Principal {
+	if p, ok := principal.(*Principal); ok && p != nil {
+		return p
+	}
+	return &Principal{}
+}
+
+// requester returns the requester identifying the subject of a request, e.g. user/joe@example.com. The ingress passes
+// it to the services, overriding the one the client may have sent.
+func (p *Principal) requester() string {
+	if p.Subject == "" {
+		return ""
+	}
+	if p.ServiceAccount {
+		return client.Requester(client.RequesterServiceAccount, p.Subject)
+	}
+	return client.Requester(client.RequesterUser, p.Subject)
+}
+
+// tokenOrganization returns the organization of the service account issuing a token
+func tokenOrganization(claims jwt.MapClaims) string {
+	if orgID, ok := claims[JWTClaimOrg].(string); ok && orgID != "" {
+		return orgID
+	}
+	return IdentityManagerFlags.OrgID
+}
+
+// organization resolves the organization of a request, selected with the X-Dispatch-Org header. By default, requests
+// of service accounts are made in the organization of the service account, and the others in the default organization.
+// It returns whether the subject, or one of its groups, is a member of the organization.
+func (h *Handlers) organization(ctx context.Context, request *http.Request, p *Principal) (string, bool, error) {
+	orgID := request.Header.Get(HTTPHeaderOrg)
+	if p.ServiceAccount {
+		if orgID == "" {
+			orgID = p.Organization
+		}
+		return orgID, orgID == p.Organization, nil
+	}
+	if orgID == "" {
+		orgID = IdentityManagerFlags.OrgID
+	}
+	member, err := h.isMember(ctx, orgID, p.Subject, p.Groups)
+	return orgID, member, err
+}
+
+// isMember returns whether a user, or one of its groups, is a member of an organization
+func (h *Handlers) isMember(ctx context.Context, orgID string, subject string, groups []string) (bool, error) {
+	if orgID == IdentityManagerFlags.OrgID {
+		// Any user is a member of the default organization, their policies define what they can do.
+		return true, nil
+	}
+	var org Organization
+	found, err := h.store.Find(ctx, IdentityManagerFlags.OrgID, orgID, entitystore.Options{Filter: entitystore.FilterExists()}, &org)
+	if err != nil || !found {
+		return false, err
+	}
+	for _, member := range org.Members {
+		if member == subject {
+			return true, nil
+		}
+		for _, group := range groups {
+			if member == group {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// isOrganizationPath returns whether a request path manages the organizations. Organizations can only be managed from
+// the default organization.
+func isOrganizationPath(path string) bool {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	return len(parts) > 2 && Resource(parts[1]) == ResourceIAM && parts[2] == "organization"
+}
+
 func (h *Handlers) auth(params operations.AuthParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
 	// For development use cases, not recommended in production env.
 	if IdentityManagerFlags.SkipAuth {
 		log.Warn("Skipping authorization. This is not recommended in production environments.")
-		return operations.NewAuthAccepted().WithXDispatchOrg(IdentityManagerFlags.OrgID)
+		orgID := params.HTTPRequest.Header.Get(HTTPHeaderOrg)
+		if orgID == "" {
+			orgID = IdentityManagerFlags.OrgID
+		}
+		return operations.NewAuthAccepted().WithXDispatchOrg(orgID)
 	}
 
 	// Represents a  Service Account or an User Account principal in our policies
-	subject := principal.(string)
+	p := requestPrincipal(principal)
+	subject := p.Subject
 
 	// At this point, the user is authenticated, let's do a policy check.
 	attrs, err := getRequestAttributes(params.HTTPRequest, subject)
@@ -311,36 +604,128 @@ func (h *Handlers) auth(params operations.AuthParams, principal interface{}) mid
 		log.Debugf("Unable to parse request attributes: %s", err)
 		return operations.NewAuthForbidden()
 	}
-	log.Debugf("Enforcing Policy: %s, %s, %s\n", attrs.subject, attrs.resource, attrs.action)
+
+	orgID, member, err := h.organization(ctx, params.HTTPRequest, p)
+	if err != nil {
+		log.Errorf("store error when resolving the organization of %s: %+v", subject, err)
+		return operations.NewAuthForbidden()
+	}
+	log.Debugf("Enforcing Policy: %s, %s, %s, %s, %s\n", orgID, attrs.subject, attrs.object(), attrs.action, attrs.application)
 
 	// Skip policy check for bootstrap user.
-	if bootstrapUser := getBootstrapKey("bootstrap_user"); bootstrapUser != "" {
-		log.Warn("Bootstrap mode is enabled. Please ensure it is turned off in a production environment.")
+	if bootstrapUser := h.bootstrapKey(ctx, "bootstrap_user"); bootstrapUser != "" {
 		if bootstrapUser == attrs.subject {
-			// Bootstrap user can only perform on IAM resource
+			// Bootstrap user can only perform on IAM resource, in any organization
 			if Resource(attrs.resource) != ResourceIAM {
 				log.Warn("Cannot operate on a non-iam resource during bootstrap, auth forbidden")
 				return operations.NewAuthForbidden()
 			}
 			log.Info("Bootstrap auth accepted")
-			return operations.NewAuthAccepted()
+			h.auditDecision(ctx, params.HTTPRequest, p, orgID, attrs, true)
+			return operations.NewAuthAccepted().WithXDispatchOrg(orgID).
+				WithXDispatchRequester(p.requester())
 		}
 	}
 
 	// Note: Non-Resource requests are currently not authz enforced.
 	if !attrs.isResourceRequest {
-		return operations.NewAuthAccepted()
+		return operations.NewAuthAccepted().WithXDispatchRequester(p.requester())
 	}
 
-	if h.enforcer.Enforce(attrs.subject, attrs.resource, string(attrs.action)) == true {
-		// TODO: Return the org-id associated with this user.
-		return operations.NewAuthAccepted().WithXDispatchOrg(IdentityManagerFlags.OrgID)
+	if !member {
+		log.Debugf("%s is not a member of organization %s, auth forbidden", subject, orgID)
+		h.auditDecision(ctx, params.HTTPRequest, p, orgID, attrs, false)
+		return operations.NewAuthForbidden()
+	}
+
+	if isOrganizationPath(params.HTTPRequest.Header.Get(HTTPHeaderReqURI)) && orgID != IdentityManagerFlags.OrgID {
+		log.Debugf("Cannot manage organizations from organization %s, auth forbidden", orgID)
+		h.auditDecision(ctx, params.HTTPRequest, p, orgID, attrs, false)
+		return operations.NewAuthForbidden()
+	}
+
+	if p.APIToken != nil && !p.APIToken.allows(attrs) {
+		log.Debugf("Request out of the scope of the API token of %s, auth forbidden", subject)
+		h.auditDecision(ctx, params.HTTPRequest, p, orgID, attrs, false)
+		return operations.NewAuthForbidden()
+	}
+
+	// Users manage their own API tokens, the API only exposes the tokens of the requester
+	if isAPITokenManagement(attrs) && !p.ServiceAccount {
+		h.auditDecision(ctx, params.HTTPRequest, p, orgID, attrs, true)
+		return operations.NewAuthAccepted().WithXDispatchOrg(orgID).
+			WithXDispatchRequester(p.requester())
+	}
+
+	application, collection := h.targetApplication(ctx, orgID, attrs, params.HTTPRequest.Header.Get(HTTPHeaderReqURI))
+	attrs.application = application
+	if h.enforce(orgID, attrs, p.Groups) {
+		h.auditDecision(ctx, params.HTTPRequest, p, orgID, attrs, true)
+		return operations.NewAuthAccepted().WithXDispatchOrg(orgID).
+			WithXDispatchRequester(p.requester())
+	}
+
+	// Creating or listing resources is allowed by rules scoped to applications, restricted by the service to the
+	// resources of these applications
+	if collection && (attrs.action == ActionCreate || attrs.action == ActionGet) {
+		if applications := h.ruleApplications(orgID, attrs, p.Groups); len(applications) > 0 {
+			h.auditDecision(ctx, params.HTTPRequest, p, orgID, attrs, true)
+			return operations.NewAuthAccepted().WithXDispatchOrg(orgID).
+				WithXDispatchRequester(p.requester()).
+				WithXDispatchApplications(strings.Join(applications, ","))
+		}
 	}
 
 	// deny the request, show an error
+	h.auditDecision(ctx, params.HTTPRequest, p, orgID, attrs, false)
 	return operations.NewAuthForbidden()
 }
 
+// auditDecision records an authorization decision, on the requests changing resources and all the denied ones
+func (h *Handlers) auditDecision(ctx context.Context, request *http.Request, p *Principal, orgID string, attrs *attributesRecord, allowed bool) {
+	if h.audit == nil || (allowed && attrs.action == ActionGet) {
+		return
+	}
+	record := &audit.Record{
+		Time:         time.Now(),
+		Service:      "identity-manager",
+		Principal:    p.requester(),
+		Organization: orgID,
+		Resource:     attrs.resource,
+		Name:         attrs.name,
+		Action:       string(attrs.action),
+		Outcome:      audit.OutcomeDenied,
+		Status:       http.StatusForbidden,
+		RequestID:    request.Header.Get(audit.HeaderRequestID),
+	}
+	if Resource(attrs.resource) == ResourceIAM && attrs.name != "" {
+		// Record IAM resources as the audit middleware does, e.g. policy/admins rather than iam/policy
+		record.Resource, record.Name = attrs.name, ""
+		if u, err := url.Parse(request.Header.Get(HTTPHeaderReqURI)); err == nil {
+			if parts := strings.Split(strings.Trim(u.Path, "/"), "/"); len(parts) > 3 {
+				record.Name = parts[3]
+			}
+		}
+	}
+	if allowed {
+		record.Outcome = audit.OutcomeAllowed
+		record.Status = http.StatusAccepted
+	}
+	if err := h.audit.Write(ctx, record); err != nil {
+		log.Errorf("error writing the audit record of %s %s %s: %+v", record.Principal, attrs.action, attrs.object(), err)
+	}
+}
+
+// enforce returns whether the policies of an organization allow a request, to the subject or one of its groups
+func (h *Handlers) enforce(orgID string, attrs *attributesRecord, groups []string) bool {
+	for _, subject := range append([]string{attrs.subject}, groups...) {
+		if h.enforcer.Enforce(orgID, subject, attrs.object(), string(attrs.action), attrs.application) {
+			return true
+		}
+	}
+	return false
+}
+
 func (h *Handlers) redirect(params operations.RedirectParams, principal interface{}) middleware.Responder {
 
 	redirect := *params.Redirect
@@ -401,7 +786,11 @@ func getRequestAttributes(request *http.Request, subject string) (*attributesRec
 	if requestPath == "" {
 		return nil, fmt.Errorf("%s header not found", HTTPHeaderReqURI)
 	}
-	currentParts := strings.Split(strings.Trim(requestPath, "/"), "/")
+	requestURL, err := url.Parse(requestPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s header: %s", HTTPHeaderReqURI, err)
+	}
+	currentParts := strings.Split(strings.Trim(requestURL.Path, "/"), "/")
 	// Check if a nonResource path is requested
 	if len(currentParts) < 2 {
 		return &attributesRecord{
@@ -412,12 +801,16 @@ func getRequestAttributes(request *http.Request, subject string) (*attributesRec
 		}, nil
 	}
 	// Note: skipping version information in parts[0]. This can be used in the future to narrow down the request scope.
-	return &attributesRecord{
+	attrs := &attributesRecord{
 		subject:           subject,
 		isResourceRequest: true,
 		resource:          currentParts[1],
 		action:            action,
-	}, nil
+	}
+	if len(currentParts) > 2 {
+		attrs.name = currentParts[2]
+	}
+	return attrs, nil
 }
 
 func getBootstrapKey(key string) string {
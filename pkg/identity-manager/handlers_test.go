@@ -22,10 +22,15 @@ import (
 	"github.com/go-openapi/swag"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/vmware/dispatch/pkg/api-manager"
 	"github.com/vmware/dispatch/pkg/api/v1"
 	"github.com/vmware/dispatch/pkg/entity-store"
+	subscriptionentities "github.com/vmware/dispatch/pkg/event-manager/subscriptions/entities"
+	"github.com/vmware/dispatch/pkg/functions"
 	"github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations"
+	"github.com/vmware/dispatch/pkg/identity-manager/oidc"
 	helpers "github.com/vmware/dispatch/pkg/testing/api"
+	fakeoidc "github.com/vmware/dispatch/pkg/testing/oidc"
 )
 
 func createTestJWT(issuer string) string {
@@ -43,6 +48,19 @@ func createTestJWT(issuer string) string {
 	return signedToken
 }
 
+func createTestJWTWithOrg(issuer string, orgID string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":       issuer,
+		JWTClaimOrg: orgID,
+		"iat":       time.Now().Unix(),
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	})
+	pvtKeyData, _ := ioutil.ReadFile("testdata/test_key")
+	pvtKey, _ := jwt.ParseRSAPrivateKeyFromPEM(pvtKeyData)
+	signedToken, _ := token.SignedString(pvtKey)
+	return signedToken
+}
+
 func createTestJWTHMAC(issuer string) string {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS384, jwt.MapClaims{
 		"iss": issuer,
@@ -124,8 +142,16 @@ func TestAuthHandlerPolicyPass(t *testing.T) {
 	params := operations.AuthParams{
 		HTTPRequest: request,
 	}
-	responder := api.AuthHandler.Handle(params, "readonly-user@example.com")
+	responder := api.AuthHandler.Handle(params, &Principal{Subject: "readonly-user@example.com"})
+	assert.Equal(t, "user/readonly-user@example.com", responder.(*operations.AuthAccepted).XDispatchRequester)
 	helpers.HandlerRequest(t, responder, nil, http.StatusAccepted)
+
+	// The requester is a service account only when authenticated as one, whatever the headers
+	request.Header.Set("Authorization", "Bearer token")
+	responder = api.AuthHandler.Handle(params, &Principal{Subject: "readonly-user@example.com"})
+	assert.Equal(t, "user/readonly-user@example.com", responder.(*operations.AuthAccepted).XDispatchRequester)
+	responder = api.AuthHandler.Handle(params, &Principal{Subject: "readonly-user@example.com", ServiceAccount: true})
+	assert.Equal(t, "serviceaccount/readonly-user@example.com", responder.(*operations.AuthAccepted).XDispatchRequester)
 }
 
 func TestAuthHandlerWithoutPolicyData(t *testing.T) {
@@ -137,7 +163,7 @@ func TestAuthHandlerWithoutPolicyData(t *testing.T) {
 	params := operations.AuthParams{
 		HTTPRequest: request,
 	}
-	responder := api.AuthHandler.Handle(params, "readonly-user@example.com")
+	responder := api.AuthHandler.Handle(params, &Principal{Subject: "readonly-user@example.com"})
 	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
 }
 
@@ -150,7 +176,7 @@ func TestAuthHandlerNonResourcePass(t *testing.T) {
 	params := operations.AuthParams{
 		HTTPRequest: request,
 	}
-	responder := api.AuthHandler.Handle(params, "noname@example.com")
+	responder := api.AuthHandler.Handle(params, &Principal{Subject: "noname@example.com"})
 	helpers.HandlerRequest(t, responder, nil, http.StatusAccepted)
 }
 
@@ -167,7 +193,7 @@ func TestAuthHandlerBootstrapPass(t *testing.T) {
 	// Set bootstrap mode and user
 	IdentityManagerFlags.BootstrapConfigPath = "testdata"
 
-	responder := api.AuthHandler.Handle(params, "bootstrap-user@example.com")
+	responder := api.AuthHandler.Handle(params, &Principal{Subject: "bootstrap-user@example.com"})
 	helpers.HandlerRequest(t, responder, nil, http.StatusAccepted)
 	// Reset flag
 	IdentityManagerFlags.BootstrapConfigPath = "/bootstrap"
@@ -185,7 +211,7 @@ func TestAuthHandlerBootstrapForbid(t *testing.T) {
 	}
 	// Set bootstrap mode and user
 	IdentityManagerFlags.BootstrapConfigPath = "testdata"
-	responder := api.AuthHandler.Handle(params, "bootstrap-user@example.com")
+	responder := api.AuthHandler.Handle(params, &Principal{Subject: "bootstrap-user@example.com"})
 	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
 	// Reset flag
 	IdentityManagerFlags.BootstrapConfigPath = "/bootstrap"
@@ -205,12 +231,12 @@ func TestAuthHandlerNonBootstrapUserInBootstrapMode(t *testing.T) {
 	}
 	// Set bootstrap mode and user
 	IdentityManagerFlags.BootstrapConfigPath = "testdata"
-	responder := api.AuthHandler.Handle(params, "non-bootstrap-user@example.com")
+	responder := api.AuthHandler.Handle(params, &Principal{Subject: "non-bootstrap-user@example.com"})
 	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
 
 	// try access non-iam resources
 	request.Header.Set(HTTPHeaderReqURI, "v1/image")
-	responder = api.AuthHandler.Handle(params, "non-bootstrap-user@example.com")
+	responder = api.AuthHandler.Handle(params, &Principal{Subject: "non-bootstrap-user@example.com"})
 	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
 	// Reset flag
 	IdentityManagerFlags.BootstrapConfigPath = "/bootstrap"
@@ -225,7 +251,7 @@ func TestAuthHandlerPolicyFail(t *testing.T) {
 	params := operations.AuthParams{
 		HTTPRequest: request,
 	}
-	responder := api.AuthHandler.Handle(params, "readonly-user@example.com")
+	responder := api.AuthHandler.Handle(params, &Principal{Subject: "readonly-user@example.com"})
 	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
 }
 
@@ -238,10 +264,152 @@ func TestAuthHandlerPolicyNoValidHeader(t *testing.T) {
 	params := operations.AuthParams{
 		HTTPRequest: request,
 	}
-	responder := api.AuthHandler.Handle(params, "readonly-user@example.com")
+	responder := api.AuthHandler.Handle(params, &Principal{Subject: "readonly-user@example.com"})
 	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
 }
 
+func addTestOrganization(store entitystore.EntityStore, name string, members ...string) {
+	store.Add(context.Background(), &Organization{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: IdentityManagerFlags.OrgID,
+			Name:           name,
+			Status:         entitystore.StatusREADY,
+		},
+		Members: members,
+	})
+	store.Add(context.Background(), &Policy{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: name,
+			Name:           "admin-policy",
+			Status:         entitystore.StatusREADY,
+		},
+		Rules: []Rule{
+			{
+				Subjects:  []string{"org-admin@example.com", "org-svc"},
+				Resources: []string{"*"},
+				Actions:   []string{"*"},
+			}},
+	})
+}
+
+func setupOrganizationTestAPI(t *testing.T) (*operations.IdentityManagerAPI, entitystore.EntityStore) {
+	api := operations.NewIdentityManagerAPI(nil)
+	es := helpers.MakeEntityStore(t)
+	addTestData(es)
+	addTestOrganization(es, "org-a", "org-admin@example.com", "readonly-user@example.com")
+	addTestOrganization(es, "org-b")
+	handlers := NewHandlers(nil, es, SetupEnforcer(es))
+	helpers.MakeAPI(t, handlers.ConfigureHandlers, api)
+	return api, es
+}
+
+func newOrgAuthParams(orgID string, uri string, method string) operations.AuthParams {
+	request := httptest.NewRequest("GET", "/auth", nil)
+	request.Header.Add(HTTPHeaderReqURI, uri)
+	request.Header.Add(HTTPHeaderOrigMethod, method)
+	if orgID != "" {
+		request.Header.Add(HTTPHeaderOrg, orgID)
+	}
+	return operations.AuthParams{
+		HTTPRequest: request,
+	}
+}
+
+func TestAuthHandlerOrganizationMember(t *testing.T) {
+	api, _ := setupOrganizationTestAPI(t)
+
+	responder := api.AuthHandler.Handle(newOrgAuthParams("org-a", "/v1/function", "POST"), &Principal{Subject: "org-admin@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusAccepted)
+	assert.Equal(t, "org-a", responder.(*operations.AuthAccepted).XDispatchOrg)
+
+	// Members are only allowed what the policies of the organization allow
+	responder = api.AuthHandler.Handle(newOrgAuthParams("org-a", "/v1/function", "POST"), &Principal{Subject: "readonly-user@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
+
+	// Policies of the default organization don't apply to the others
+	responder = api.AuthHandler.Handle(newOrgAuthParams("org-a", "/v1/function", "GET"), &Principal{Subject: "super-admin@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
+	responder = api.AuthHandler.Handle(newOrgAuthParams("", "/v1/function", "GET"), &Principal{Subject: "org-admin@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
+
+	// The policies of org-b allow org-admin, but it isn't a member
+	responder = api.AuthHandler.Handle(newOrgAuthParams("org-b", "/v1/function", "GET"), &Principal{Subject: "org-admin@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
+
+	responder = api.AuthHandler.Handle(newOrgAuthParams("missing-org", "/v1/function", "GET"), &Principal{Subject: "org-admin@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
+}
+
+func TestAuthHandlerOrganizationPath(t *testing.T) {
+	api, _ := setupOrganizationTestAPI(t)
+
+	// Organizations are managed from the default organization only
+	responder := api.AuthHandler.Handle(newOrgAuthParams("org-a", "/v1/iam/organization", "GET"), &Principal{Subject: "org-admin@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
+	responder = api.AuthHandler.Handle(newOrgAuthParams("org-a", "/v1/iam/policy", "GET"), &Principal{Subject: "org-admin@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusAccepted)
+	responder = api.AuthHandler.Handle(newOrgAuthParams("", "/v1/iam/organization", "GET"), &Principal{Subject: "super-admin@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusAccepted)
+}
+
+func TestAuthHandlerServiceAccountOrganization(t *testing.T) {
+	api, _ := setupOrganizationTestAPI(t)
+
+	// Service accounts make their requests in their organization by default
+	svcAccount := &Principal{Subject: "org-svc", Organization: "org-a", ServiceAccount: true}
+	params := newOrgAuthParams("", "/v1/function", "GET")
+	responder := api.AuthHandler.Handle(params, svcAccount)
+	helpers.HandlerRequest(t, responder, nil, http.StatusAccepted)
+	assert.Equal(t, "org-a", responder.(*operations.AuthAccepted).XDispatchOrg)
+	assert.Equal(t, "serviceaccount/org-svc", responder.(*operations.AuthAccepted).XDispatchRequester)
+
+	// and can't select another one
+	params.HTTPRequest.Header.Set(HTTPHeaderOrg, "org-b")
+	responder = api.AuthHandler.Handle(params, svcAccount)
+	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
+
+	// The organization is never taken from the headers: a user authenticated by a cookie can't select one with a
+	// bearer token sent along
+	params = newOrgAuthParams("org-a", "/v1/function", "GET")
+	params.HTTPRequest.Header.Set("Authorization", "Bearer "+createTestJWTWithOrg("org-svc", "org-a"))
+	responder = api.AuthHandler.Handle(params, &Principal{Subject: "nopolicy-user@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
+}
+
+func TestAuthHandlerRoleBinding(t *testing.T) {
+	api := setupRoleBindingTestAPI(t)
+
+	// Role bindings grant the rules of roles on resource instances
+	responder := api.AuthHandler.Handle(newOrgAuthParams("", "/v1/function/billing-charge", "PUT"), &Principal{Subject: "dev@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusAccepted)
+	responder = api.AuthHandler.Handle(newOrgAuthParams("", "/v1/function/billing-charge", "DELETE"), &Principal{Subject: "dev@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
+	responder = api.AuthHandler.Handle(newOrgAuthParams("", "/v1/function/payroll", "GET"), &Principal{Subject: "dev@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
+	responder = api.AuthHandler.Handle(newOrgAuthParams("", "/v1/function", "GET"), &Principal{Subject: "dev@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
+
+	// and on the resources of some applications only, resolved from the resource rather than the request
+	responder = api.AuthHandler.Handle(newOrgAuthParams("", "/v1/api/invoices", "GET"), &Principal{Subject: "dev@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusAccepted)
+	assert.Equal(t, "", responder.(*operations.AuthAccepted).XDispatchApplications)
+	responder = api.AuthHandler.Handle(newOrgAuthParams("", "/v1/api/salaries?tags=Application=billing", "GET"), &Principal{Subject: "dev@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
+	responder = api.AuthHandler.Handle(newOrgAuthParams("", "/v1/api/missing", "GET"), &Principal{Subject: "dev@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
+
+	// Listings are restricted to these applications, by the service
+	responder = api.AuthHandler.Handle(newOrgAuthParams("", "/v1/api", "GET"), &Principal{Subject: "dev@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusAccepted)
+	assert.Equal(t, "billing", responder.(*operations.AuthAccepted).XDispatchApplications)
+	responder = api.AuthHandler.Handle(newOrgAuthParams("", "/v1/api", "POST"), &Principal{Subject: "dev@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
+
+	// Policies still apply to all the instances of resources
+	responder = api.AuthHandler.Handle(newOrgAuthParams("", "/v1/function/payroll", "GET"), &Principal{Subject: "readonly-user@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusAccepted)
+}
+
 func TestGetRequestAttributesNoSubject(t *testing.T) {
 
 	request := httptest.NewRequest("GET", "/auth", nil)
@@ -306,6 +474,43 @@ func TestGetRequestAttributesValidSubResource(t *testing.T) {
 	assert.Equal(t, "", attrRecord.path)
 }
 
+func TestGetRequestAttributesResourceInstance(t *testing.T) {
+
+	request := httptest.NewRequest("GET", "/auth", nil)
+	request.Header.Add(HTTPHeaderReqURI, "/v1/function/billing-charge?tags=app=billing")
+	request.Header.Add(HTTPHeaderOrigMethod, "GET")
+	attrRecord, _ := getRequestAttributes(request, "super-admin@example.com")
+	assert.Equal(t, "function", attrRecord.resource)
+	assert.Equal(t, "billing-charge", attrRecord.name)
+	assert.Equal(t, "function/billing-charge", attrRecord.object())
+	// The application is resolved from the resource, never from the request
+	assert.Equal(t, "", attrRecord.application)
+
+	request.Header.Set(HTTPHeaderReqURI, "/v1/function?tags=Application=billing")
+	attrRecord, _ = getRequestAttributes(request, "super-admin@example.com")
+	assert.Equal(t, "function", attrRecord.object())
+	assert.Equal(t, "", attrRecord.application)
+}
+
+func TestTargetEntity(t *testing.T) {
+	entity, name := targetEntity("/v1/function/hello")
+	assert.IsType(t, &functions.Function{}, entity)
+	assert.Equal(t, "hello", name)
+
+	entity, name = targetEntity("/v1/api/certificate/example.com")
+	assert.IsType(t, &apimanager.Certificate{}, entity)
+	assert.Equal(t, "example.com", name)
+
+	entity, name = targetEntity("/v1/event/subscriptions")
+	assert.IsType(t, &subscriptionentities.Subscription{}, entity)
+	assert.Equal(t, "", name)
+
+	entity, _ = targetEntity("/v1/iam/policy/admins")
+	assert.Nil(t, entity)
+	entity, _ = targetEntity("/v1/event")
+	assert.Nil(t, entity)
+}
+
 func TestRedirectHandler(t *testing.T) {
 
 	api := operations.NewIdentityManagerAPI(nil)
@@ -440,6 +645,32 @@ func TestParseValidJWT(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestParseValidJWTOrganization(t *testing.T) {
+	es := helpers.MakeEntityStore(t)
+	enforcer := SetupEnforcer(es)
+	h := NewHandlers(nil, es, enforcer)
+
+	pubKey, _ := ioutil.ReadFile("testdata/test_key.pub")
+	svcAccount := &ServiceAccount{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: "org-a",
+			Name:           "test_svc1",
+		},
+		PublicKey: base64.StdEncoding.EncodeToString(pubKey),
+	}
+	es.Add(context.Background(), svcAccount)
+
+	claims, err := h.parseAndValidateToken(createTestJWTWithOrg("test_svc1", "org-a"))
+	assert.NoError(t, err)
+	assert.Equal(t, "org-a", claims[JWTClaimOrg])
+
+	// The service account is looked up in the organization of the token only
+	_, err = h.parseAndValidateToken(createTestJWTWithOrg("test_svc1", "org-b"))
+	assert.Error(t, err)
+	_, err = h.parseAndValidateToken(createTestJWT("test_svc1"))
+	assert.Error(t, err)
+}
+
 func TestAuthenticateBearerInvalidHeader(t *testing.T) {
 	es := helpers.MakeEntityStore(t)
 	enforcer := SetupEnforcer(es)
@@ -476,7 +707,7 @@ func TestAuthenticateBearerPass(t *testing.T) {
 
 	token := createTestJWT("test_svc1")
 	principal, err := h.authenticateBearer("bearer " + token)
-	assert.Equal(t, "test_svc1", principal)
+	assert.Equal(t, "test_svc1", principal.(*Principal).Subject)
 	assert.NoError(t, err)
 }
 
@@ -508,7 +739,7 @@ func TestBootstrapModeBearerToken(t *testing.T) {
 	IdentityManagerFlags.BootstrapConfigPath = "testdata"
 	token := createTestJWT("bootstrap-user@example.com")
 	principal, err := h.authenticateBearer("bearer " + token)
-	assert.Equal(t, "bootstrap-user@example.com", principal)
+	assert.Equal(t, "bootstrap-user@example.com", principal.(*Principal).Subject)
 	assert.NoError(t, err)
 	// Reset flag
 	IdentityManagerFlags.BootstrapConfigPath = "/bootstrap"
@@ -567,7 +798,7 @@ func TestAuthenticateCookiePass(t *testing.T) {
 	IdentityManagerFlags.OAuth2ProxyAuthURL = testHttpserver.URL
 	cookieString := IdentityManagerFlags.CookieName + "=testing"
 	principal, err := h.authenticateCookie(cookieString)
-	assert.Equal(t, "test-user1@example.com", principal)
+	assert.Equal(t, "test-user1@example.com", principal.(*Principal).Subject)
 	assert.NoError(t, err)
 }
 
@@ -602,3 +833,128 @@ func TestAuthenticateCookieMissingEmailHeader(t *testing.T) {
 	assert.Nil(t, principal)
 	assert.EqualError(t, err, "authentication failed: missing X-Auth-Request-Email header in response from oauth2proxy")
 }
+
+func setupOIDCTestHandlers(t *testing.T, fake *fakeoidc.FakeProvider) (*Handlers, entitystore.EntityStore) {
+	es := helpers.MakeEntityStore(t)
+	provider, err := oidc.NewProvider(context.Background(), fake.Issuer(), fake.ClientID, nil)
+	assert.NoError(t, err)
+	h := NewHandlers(nil, es, SetupEnforcer(es))
+	h.SetOIDCProvider(provider)
+	return h, es
+}
+
+func TestAuthenticateBearerIDToken(t *testing.T) {
+	fake := fakeoidc.NewFakeProvider("dispatch-cli", "user@example.com", "admins")
+	defer fake.Close()
+	h, es := setupOIDCTestHandlers(t, fake)
+
+	groupsClaim := IdentityManagerFlags.OIDCGroupsClaim
+	IdentityManagerFlags.OIDCGroupsClaim = "groups"
+	defer func() { IdentityManagerFlags.OIDCGroupsClaim = groupsClaim }()
+
+	principal, err := h.authenticateBearer("bearer " + fake.IDToken(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "user@example.com", principal.(*Principal).Subject)
+	assert.Equal(t, []string{PolicySubjectGroupPrefix + "admins"}, principal.(*Principal).Groups)
+	assert.False(t, principal.(*Principal).ServiceAccount)
+
+	// Unverified emails don't identify the user, the subject does
+	principal, err = h.authenticateBearer("bearer " + fake.IDToken(jwt.MapClaims{"sub": "12345", "email_verified": false}))
+	assert.NoError(t, err)
+	assert.Equal(t, "12345", principal.(*Principal).Subject)
+	principal, err = h.authenticateBearer("bearer " + fake.IDToken(jwt.MapClaims{"sub": "12345", "email_verified": nil}))
+	assert.NoError(t, err)
+	assert.Equal(t, "12345", principal.(*Principal).Subject)
+	principal, err = h.authenticateBearer("bearer " + fake.IDToken(jwt.MapClaims{"sub": "12345", "email_verified": "true"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "user@example.com", principal.(*Principal).Subject)
+
+	_, err = h.authenticateBearer("bearer " + fake.IDToken(jwt.MapClaims{"aud": "other-client"}))
+	assert.Error(t, err)
+	_, err = h.authenticateBearer("bearer " + fake.IDToken(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}))
+	assert.Error(t, err)
+
+	// Service accounts still authenticate with their own tokens
+	pubKey, _ := ioutil.ReadFile("testdata/test_key.pub")
+	es.Add(context.Background(), &ServiceAccount{
+		BaseEntity: entitystore.BaseEntity{
+			Name: "test_svc1",
+		},
+		PublicKey: base64.StdEncoding.EncodeToString(pubKey),
+	})
+	principal, err = h.authenticateBearer("bearer " + createTestJWT("test_svc1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "test_svc1", principal.(*Principal).Subject)
+}
+
+func TestAuthenticateCookieIDToken(t *testing.T) {
+	fake := fakeoidc.NewFakeProvider("dispatch-cli", "user@example.com")
+	defer fake.Close()
+	h, _ := setupOIDCTestHandlers(t, fake)
+
+	cookieName := IdentityManagerFlags.CookieName
+	IdentityManagerFlags.CookieName = "_dispatch_session"
+	defer func() { IdentityManagerFlags.CookieName = cookieName }()
+
+	principal, err := h.authenticateCookie("_dispatch_session=" + fake.IDToken(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "user@example.com", principal.(*Principal).Subject)
+
+	_, err = h.authenticateCookie("other=" + fake.IDToken(nil))
+	assert.Error(t, err)
+	_, err = h.authenticateCookie("_dispatch_session=invalid")
+	assert.Error(t, err)
+}
+
+func TestAuthHandlerIDTokenGroups(t *testing.T) {
+	fake := fakeoidc.NewFakeProvider("dispatch-cli", "user@example.com", "admins")
+	defer fake.Close()
+	h, es := setupOIDCTestHandlers(t, fake)
+
+	es.Add(context.Background(), &Policy{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: IdentityManagerFlags.OrgID,
+			Name:           "admins-policy",
+			Status:         entitystore.StatusREADY,
+		},
+		Rules: []Rule{
+			{
+				Subjects:  []string{PolicySubjectGroupPrefix + "admins"},
+				Resources: []string{"*"},
+				Actions:   []string{"*"},
+			}},
+	})
+	// org-a lists the admins group as a member
+	addTestOrganization(es, "org-a", PolicySubjectGroupPrefix+"admins")
+	h.enforcer.LoadPolicy()
+
+	groupsClaim := IdentityManagerFlags.OIDCGroupsClaim
+	IdentityManagerFlags.OIDCGroupsClaim = "groups"
+	defer func() { IdentityManagerFlags.OIDCGroupsClaim = groupsClaim }()
+
+	authenticate := func(claims jwt.MapClaims) interface{} {
+		principal, err := h.authenticateBearer("bearer " + fake.IDToken(claims))
+		assert.NoError(t, err)
+		return principal
+	}
+
+	responder := h.auth(newOrgAuthParams("", "/v1/function", "POST"), authenticate(nil))
+	helpers.HandlerRequest(t, responder, nil, http.StatusAccepted)
+	// Users authenticated by ID tokens aren't service accounts
+	assert.Equal(t, "user/user@example.com", responder.(*operations.AuthAccepted).XDispatchRequester)
+
+	responder = h.auth(newOrgAuthParams("", "/v1/function", "POST"), authenticate(jwt.MapClaims{"groups": []string{"developers"}}))
+	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
+
+	// Group members are members of the organization, the policies of org-a allow org-admin only
+	params := newOrgAuthParams("org-a", "/v1/function", "GET")
+	responder = h.auth(params, authenticate(jwt.MapClaims{"email": "org-admin@example.com"}))
+	helpers.HandlerRequest(t, responder, nil, http.StatusAccepted)
+	assert.Equal(t, "org-a", responder.(*operations.AuthAccepted).XDispatchOrg)
+
+	// Groups are only those of the verified principal, not of another token sent along
+	params = newOrgAuthParams("", "/v1/function", "POST")
+	params.HTTPRequest.Header.Set("Authorization", "Bearer "+fake.IDToken(nil))
+	responder = h.auth(params, &Principal{Subject: "user@example.com"})
+	helpers.HandlerRequest(t, responder, nil, http.StatusForbidden)
+}
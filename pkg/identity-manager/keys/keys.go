@@ -0,0 +1,134 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+// Package keys parses the keys signing the tokens of service accounts. RSA, ECDSA and Ed25519 keys are supported,
+// signing tokens with the RS256, ES256, ES384, ES512 and EdDSA algorithms.
+package keys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+// ParsePublicKey parses a PEM encoded public key, either PKIX or PKCS1, or the public key of a PEM encoded certificate
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, jwt.ErrKeyMustBePEMEncoded
+	}
+	var key crypto.PublicKey
+	if parsed, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		key = parsed
+	} else if parsed, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		key = parsed
+	} else if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+		key = cert.PublicKey
+	} else {
+		return nil, errors.New("unable to parse public key, it must be PKIX, PKCS1 or a certificate")
+	}
+	if _, err := SigningMethod(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ParseEncodedPublicKey parses a base64 encoded PEM public key, as the public keys of service accounts are stored
+func ParseEncodedPublicKey(encoded string) (crypto.PublicKey, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, jwt.ErrKeyMustBePEMEncoded
+	}
+	return ParsePublicKey(data)
+}
+
+// ParsePrivateKey parses a PEM encoded private key, either PKCS1, PKCS8 or EC
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, jwt.ErrKeyMustBePEMEncoded
+	}
+	var key interface{}
+	if parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		key = parsed
+	} else if parsed, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		key = parsed
+	} else if parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		key = parsed
+	} else {
+		return nil, errors.New("unable to parse private key, it must be PKCS1, PKCS8 or EC")
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.Errorf("unsupported private key type %T", key)
+	}
+	if _, err := SigningMethod(signer.Public()); err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+// EncodePublicKey encodes a public key as PKIX PEM
+func EncodePublicKey(key crypto.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshalling public key")
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// SigningMethod returns the method signing tokens with a key, either public or private
+func SigningMethod(key interface{}) (jwt.SigningMethod, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey, *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PrivateKey:
+		return SigningMethod(&k.PublicKey)
+	case *ecdsa.PublicKey:
+		switch k.Curve.Params().BitSize {
+		case 256:
+			return jwt.SigningMethodES256, nil
+		case 384:
+			return jwt.SigningMethodES384, nil
+		case 521:
+			return jwt.SigningMethodES512, nil
+		}
+		return nil, errors.Errorf("unsupported elliptic curve %s", k.Curve.Params().Name)
+	case ed25519.PublicKey, ed25519.PrivateKey:
+		return SigningMethodEdDSA, nil
+	}
+	return nil, errors.Errorf("unsupported key type %T", key)
+}
+
+// Verifies returns whether a token signed with a method can be verified with a public key. The algorithm of tokens
+// must be validated against the key, not trusted, or tokens could be forged e.g. with a public RSA key as HMAC secret.
+func Verifies(method jwt.SigningMethod, key crypto.PublicKey) bool {
+	if _, ok := key.(*rsa.PublicKey); ok {
+		// RS256, RS384 and RS512
+		_, ok := method.(*jwt.SigningMethodRSA)
+		return ok
+	}
+	expected, err := SigningMethod(key)
+	return err == nil && method.Alg() == expected.Alg()
+}
+
+// ID returns the ID of a public key, a fingerprint of the key set as kid header of the tokens it signs
+func ID(key crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", errors.Wrap(err, "error marshalling public key")
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:8]), nil
+}
@@ -0,0 +1,118 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package keys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateKeys(t *testing.T) map[string]crypto.Signer {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return map[string]crypto.Signer{"RS256": rsaKey, "ES256": p256Key, "ES384": p384Key, "EdDSA": edKey}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	for alg, privateKey := range generateKeys(t) {
+		der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		require.NoError(t, err)
+		parsedPrivateKey, err := ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+		require.NoError(t, err, alg)
+
+		pubPEM, err := EncodePublicKey(privateKey.Public())
+		require.NoError(t, err)
+		publicKey, err := ParsePublicKey(pubPEM)
+		require.NoError(t, err, alg)
+
+		method, err := SigningMethod(parsedPrivateKey)
+		require.NoError(t, err)
+		assert.Equal(t, alg, method.Alg())
+		assert.True(t, Verifies(method, publicKey), alg)
+
+		signed, err := jwt.NewWithClaims(method, jwt.MapClaims{"iss": "ci"}).SignedString(parsedPrivateKey)
+		require.NoError(t, err, alg)
+		token, err := jwt.Parse(signed, func(token *jwt.Token) (interface{}, error) {
+			return publicKey, nil
+		})
+		require.NoError(t, err, alg)
+		assert.True(t, token.Valid)
+		assert.Equal(t, alg, token.Header["alg"])
+	}
+}
+
+func TestVerifies(t *testing.T) {
+	generated := generateKeys(t)
+	rsaKey := generated["RS256"].Public()
+	assert.True(t, Verifies(jwt.SigningMethodRS512, rsaKey))
+	assert.False(t, Verifies(jwt.SigningMethodES256, rsaKey))
+	assert.False(t, Verifies(jwt.SigningMethodHS256, rsaKey))
+	// The algorithm of ECDSA keys depends on their curve
+	assert.False(t, Verifies(jwt.SigningMethodES256, generated["ES384"].Public()))
+	assert.False(t, Verifies(SigningMethodEdDSA, generated["ES256"].Public()))
+	assert.False(t, Verifies(jwt.SigningMethodRS256, generated["EdDSA"].Public()))
+}
+
+func TestEdDSAInvalidSignature(t *testing.T) {
+	generated := generateKeys(t)
+	signed, err := jwt.NewWithClaims(SigningMethodEdDSA, jwt.MapClaims{"iss": "ci"}).SignedString(generated["EdDSA"])
+	require.NoError(t, err)
+
+	otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, err = jwt.Parse(signed, func(token *jwt.Token) (interface{}, error) {
+		return otherPublicKey, nil
+	})
+	assert.EqualError(t, err, ErrEdDSAVerification.Error())
+
+	_, err = SigningMethodEdDSA.Sign("payload", generated["RS256"])
+	assert.Equal(t, jwt.ErrInvalidKeyType, err)
+}
+
+func TestParseInvalidKeys(t *testing.T) {
+	_, err := ParsePublicKey([]byte("invalid"))
+	assert.Equal(t, jwt.ErrKeyMustBePEMEncoded, err)
+	_, err = ParseEncodedPublicKey("invalid")
+	assert.Equal(t, jwt.ErrKeyMustBePEMEncoded, err)
+	_, err = ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("invalid")}))
+	assert.Error(t, err)
+}
+
+func TestID(t *testing.T) {
+	generated := generateKeys(t)
+	id, err := ID(generated["ES256"].Public())
+	require.NoError(t, err)
+	assert.Len(t, id, 16)
+
+	pubPEM, err := EncodePublicKey(generated["ES256"].Public())
+	require.NoError(t, err)
+	parsed, err := ParsePublicKey(pubPEM)
+	require.NoError(t, err)
+	parsedID, err := ID(parsed)
+	require.NoError(t, err)
+	assert.Equal(t, id, parsedID)
+
+	otherID, err := ID(generated["EdDSA"].Public())
+	require.NoError(t, err)
+	assert.NotEqual(t, id, otherID)
+}
@@ -0,0 +1,218 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// keys are refreshed after jwksTTL, or when a token is signed by an unknown key, e.g. after a key rotation
+	jwksTTL = time.Hour
+	// unknown keys don't refresh the keys more often than jwksMinRefresh, tokens signed by unknown keys are cheap to forge
+	jwksMinRefresh = 10 * time.Second
+)
+
+// JSONWebKey is a public key of a JSON Web Key Set, RFC 7517
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC keys
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JSONWebKeySet is a JSON Web Key Set, as served at the jwks_uri of a provider
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// keySet caches the signing keys of a provider
+type keySet struct {
+	sync.Mutex
+
+	uri    string
+	client *http.Client
+
+	keys      map[string]interface{}
+	fetched   time.Time
+	now       func() time.Time
+	fetchings int
+}
+
+func newKeySet(uri string, client *http.Client) *keySet {
+	return &keySet{uri: uri, client: client, now: time.Now}
+}
+
+// key returns the public key of a key id. A token without key id may be signed by any key of the provider, if it has
+// only one.
+func (s *keySet) key(ctx context.Context, kid string) (interface{}, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	age := s.now().Sub(s.fetched)
+	if key, ok := s.lookup(kid); ok && age < jwksTTL {
+		return key, nil
+	}
+	if s.keys == nil || age >= jwksMinRefresh {
+		if err := s.fetch(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if key, ok := s.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, errors.Errorf("unknown signing key %s", kid)
+}
+
+func (s *keySet) lookup(kid string) (interface{}, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func (s *keySet) fetch(ctx context.Context) error {
+	log.Debugf("Fetching signing keys from %s", s.uri)
+	req, err := http.NewRequest(http.MethodGet, s.uri, nil)
+	if err != nil {
+		return errors.Wrap(err, "error creating signing keys request")
+	}
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrapf(err, "error fetching signing keys from %s", s.uri)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("error fetching signing keys from %s: status %d", s.uri, resp.StatusCode)
+	}
+	var set JSONWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return errors.Wrapf(err, "error decoding signing keys from %s", s.uri)
+	}
+	keys := make(map[string]interface{})
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.PublicKey()
+		if err != nil {
+			log.Warnf("Skipping signing key %s of %s: %s", k.Kid, s.uri, err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	s.keys = keys
+	s.fetched = s.now()
+	s.fetchings++
+	return nil
+}
+
+// PublicKey returns the RSA or ECDSA public key of a JSON web key
+func (k *JSONWebKey) PublicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("invalid EC key")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, errors.Errorf("unsupported key type %s", k.Kty)
+}
+
+// NewJSONWebKey returns the JSON web key of an RSA or ECDSA public key
+func NewJSONWebKey(kid string, key interface{}) (JSONWebKey, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return JSONWebKey{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			N:   encodeInt(k.N),
+			E:   encodeInt(big.NewInt(int64(k.E))),
+		}, nil
+	case *ecdsa.PublicKey:
+		return JSONWebKey{
+			Kty: "EC",
+			Kid: kid,
+			Use: "sig",
+			Crv: k.Curve.Params().Name,
+			X:   encodeCoordinate(k.Curve, k.X),
+			Y:   encodeCoordinate(k.Curve, k.Y),
+		}, nil
+	}
+	return JSONWebKey{}, errors.Errorf("unsupported key type %T", key)
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "error decoding key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func encodeInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+// encodeCoordinate encodes an EC coordinate with the full length of the curve, as required by RFC 7518
+func encodeCoordinate(curve elliptic.Curve, i *big.Int) string {
+	b := make([]byte, (curve.Params().BitSize+7)/8)
+	ib := i.Bytes()
+	copy(b[len(b)-len(ib):], ib)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
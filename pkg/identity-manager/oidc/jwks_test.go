@@ -0,0 +1,70 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySetCaching(t *testing.T) {
+	key1, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	key2, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	jwk1, _ := NewJSONWebKey("1", &key1.PublicKey)
+	jwk2, _ := NewJSONWebKey("2", &key2.PublicKey)
+	set := JSONWebKeySet{Keys: []JSONWebKey{jwk1}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(set)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	keys := newKeySet(server.URL, http.DefaultClient)
+	keys.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	key, err := keys.key(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, &key1.PublicKey, key)
+	// A single key signs tokens without key id
+	_, err = keys.key(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, keys.fetchings)
+
+	// The provider rotates its key
+	set.Keys = append(set.Keys, jwk2)
+	_, err = keys.key(ctx, "2")
+	assert.Error(t, err)
+	assert.Equal(t, 1, keys.fetchings)
+
+	now = now.Add(jwksMinRefresh)
+	key, err = keys.key(ctx, "2")
+	require.NoError(t, err)
+	assert.Equal(t, &key2.PublicKey, key)
+	assert.Equal(t, 2, keys.fetchings)
+
+	// Known keys are cached until they expire
+	_, err = keys.key(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, keys.fetchings)
+	set.Keys = []JSONWebKey{jwk2}
+	now = now.Add(jwksTTL)
+	_, err = keys.key(ctx, "1")
+	assert.Error(t, err)
+	assert.Equal(t, 3, keys.fetchings)
+}
@@ -0,0 +1,253 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+// DefaultScopes are the scopes requested at login. "groups" isn't standard, but most providers issuing group claims
+// require it.
+var DefaultScopes = []string{"openid", "email", "profile", "groups"}
+
+const discoveryPath = "/.well-known/openid-configuration"
+
+// Provider is an OpenID Connect provider, configured by discovery
+type Provider struct {
+	// Issuer identifies the provider, it is the iss claim of its ID tokens
+	Issuer string
+	// ClientID is the client the ID tokens are issued to, it is the aud claim of the ID tokens
+	ClientID string
+
+	AuthorizationEndpoint string
+	TokenEndpoint         string
+	JWKSURI               string
+
+	client *http.Client
+	keys   *keySet
+}
+
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// NewProvider discovers the provider of an issuer. The HTTP client is optional.
+func NewProvider(ctx context.Context, issuer, clientID string, client *http.Client) (*Provider, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(issuer, "/")+discoveryPath, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating discovery request")
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error fetching the configuration of issuer %s", issuer)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("error fetching the configuration of issuer %s: status %d", issuer, resp.StatusCode)
+	}
+	var d discovery
+	if err := json.NewDecoder(resp.Body).Decode(&d); err != nil {
+		return nil, errors.Wrapf(err, "error decoding the configuration of issuer %s", issuer)
+	}
+	// The issuer must match the one requested, or the provider could impersonate another
+	if strings.TrimSuffix(d.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return nil, errors.Errorf("issuer %s did not match the issuer %s returned by discovery", issuer, d.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.Errorf("incomplete configuration of issuer %s", issuer)
+	}
+	return &Provider{
+		Issuer:                d.Issuer,
+		ClientID:              clientID,
+		AuthorizationEndpoint: d.AuthorizationEndpoint,
+		TokenEndpoint:         d.TokenEndpoint,
+		JWKSURI:               d.JWKSURI,
+		client:                client,
+		keys:                  newKeySet(d.JWKSURI, client),
+	}, nil
+}
+
+// AuthCodeURL returns the URL of the provider login page, redirecting to redirectURI with an authorization code. The
+// challenge is the PKCE code challenge of the verifier later passed to Exchange.
+func (p *Provider) AuthCodeURL(redirectURI, state, challenge string, scopes []string) string {
+	if scopes == nil {
+		scopes = DefaultScopes
+	}
+	values := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+	if strings.Contains(p.AuthorizationEndpoint, "?") {
+		return p.AuthorizationEndpoint + "&" + values.Encode()
+	}
+	return p.AuthorizationEndpoint + "?" + values.Encode()
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange exchanges an authorization code for an ID token, which is verified
+func (p *Provider) Exchange(ctx context.Context, redirectURI, code, verifier string) (string, *IDToken, error) {
+	values := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {p.ClientID},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequest(http.MethodPost, p.TokenEndpoint, strings.NewReader(values.Encode()))
+	if err != nil {
+		return "", nil, errors.Wrap(err, "error creating token request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", nil, errors.Wrap(err, "error exchanging the authorization code")
+	}
+	defer resp.Body.Close()
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", nil, errors.Wrapf(err, "error decoding the token response: status %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, errors.Errorf("error exchanging the authorization code: %s %s", token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return "", nil, errors.New("missing id_token in the token response")
+	}
+	idToken, err := p.Verify(ctx, token.IDToken)
+	if err != nil {
+		return "", nil, err
+	}
+	return token.IDToken, idToken, nil
+}
+
+// IDToken is a verified ID token
+type IDToken struct {
+	Subject string
+	Email   string
+	// EmailVerified is whether the provider verified the user owns the email
+	EmailVerified bool
+	Claims        jwt.MapClaims
+}
+
+// Strings returns the values of a string or string list claim, e.g. the groups of the user
+func (t *IDToken) Strings(claim string) []string {
+	switch v := t.Claims[claim].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var values []string
+		for _, value := range v {
+			if s, ok := value.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// IsIDToken returns whether the unverified claims of a token were issued by the provider
+func (p *Provider) IsIDToken(claims jwt.MapClaims) bool {
+	iss, _ := claims["iss"].(string)
+	return iss != "" && strings.TrimSuffix(iss, "/") == strings.TrimSuffix(p.Issuer, "/")
+}
+
+// Verify verifies the signature, issuer, audience and expiry of an ID token
+func (p *Provider) Verify(ctx context.Context, rawIDToken string) (*IDToken, error) {
+	token, err := jwt.Parse(rawIDToken, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.keys.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error validating id token")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid id token")
+	}
+	if !p.IsIDToken(claims) {
+		return nil, errors.Errorf("id token not issued by %s", p.Issuer)
+	}
+	if !audience(claims, p.ClientID) {
+		return nil, errors.Errorf("id token not issued to client %s", p.ClientID)
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("missing exp claim in id token")
+	}
+	idToken := &IDToken{Claims: claims}
+	idToken.Subject, _ = claims["sub"].(string)
+	idToken.Email, _ = claims["email"].(string)
+	// Some providers issue the email_verified claim as a string
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		idToken.EmailVerified = verified
+	case string:
+		idToken.EmailVerified = verified == "true"
+	}
+	return idToken, nil
+}
+
+func audience(claims jwt.MapClaims, clientID string) bool {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return aud == clientID
+	case []interface{}:
+		for _, a := range aud {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewCodeVerifier creates a PKCE code verifier
+func NewCodeVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "error generating code verifier")
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge returns the S256 PKCE code challenge of a code verifier
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
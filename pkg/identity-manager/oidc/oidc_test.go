@@ -0,0 +1,157 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package oidc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/identity-manager/oidc"
+	fakeoidc "github.com/vmware/dispatch/pkg/testing/oidc"
+)
+
+const redirectURI = "http://127.0.0.1:5555/catcher"
+
+// authorize follows the login page of the provider, returning the authorization code
+func authorize(t *testing.T, p *oidc.Provider, state, challenge string) string {
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get(p.AuthCodeURL(redirectURI, state, challenge, nil))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, state, location.Query().Get("state"))
+	return location.Query().Get("code")
+}
+
+func TestLogin(t *testing.T) {
+	fake := fakeoidc.NewFakeProvider("dispatch-cli", "user@example.com", "admins", "developers")
+	defer fake.Close()
+
+	ctx := context.Background()
+	p, err := oidc.NewProvider(ctx, fake.Issuer(), "dispatch-cli", nil)
+	require.NoError(t, err)
+
+	verifier, err := oidc.NewCodeVerifier()
+	require.NoError(t, err)
+	code := authorize(t, p, "state", oidc.CodeChallenge(verifier))
+
+	raw, idToken, err := p.Exchange(ctx, redirectURI, code, verifier)
+	require.NoError(t, err)
+	assert.NotEmpty(t, raw)
+	assert.Equal(t, "user@example.com", idToken.Email)
+	assert.Equal(t, []string{"admins", "developers"}, idToken.Strings("groups"))
+
+	// codes are single use
+	_, _, err = p.Exchange(ctx, redirectURI, code, verifier)
+	assert.Error(t, err)
+}
+
+func TestLoginWrongVerifier(t *testing.T) {
+	fake := fakeoidc.NewFakeProvider("dispatch-cli", "user@example.com")
+	defer fake.Close()
+
+	ctx := context.Background()
+	p, err := oidc.NewProvider(ctx, fake.Issuer(), "dispatch-cli", nil)
+	require.NoError(t, err)
+
+	verifier, _ := oidc.NewCodeVerifier()
+	code := authorize(t, p, "state", oidc.CodeChallenge(verifier))
+
+	other, _ := oidc.NewCodeVerifier()
+	_, _, err = p.Exchange(ctx, redirectURI, code, other)
+	assert.Error(t, err)
+}
+
+func TestNewProviderIssuerMismatch(t *testing.T) {
+	fake := fakeoidc.NewFakeProvider("dispatch-cli", "user@example.com")
+	defer fake.Close()
+
+	_, err := oidc.NewProvider(context.Background(), fake.Issuer()+"/other", "dispatch-cli", nil)
+	assert.Error(t, err)
+}
+
+func TestVerify(t *testing.T) {
+	fake := fakeoidc.NewFakeProvider("dispatch-cli", "user@example.com")
+	defer fake.Close()
+
+	ctx := context.Background()
+	p, err := oidc.NewProvider(ctx, fake.Issuer(), "dispatch-cli", nil)
+	require.NoError(t, err)
+
+	idToken, err := p.Verify(ctx, fake.IDToken(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", idToken.Subject)
+
+	_, err = p.Verify(ctx, fake.IDToken(jwt.MapClaims{"aud": "other-client"}))
+	assert.Error(t, err)
+	_, err = p.Verify(ctx, fake.IDToken(jwt.MapClaims{"aud": []string{"other-client", "dispatch-cli"}}))
+	assert.NoError(t, err)
+	_, err = p.Verify(ctx, fake.IDToken(jwt.MapClaims{"iss": "https://other.example.com"}))
+	assert.Error(t, err)
+	_, err = p.Verify(ctx, fake.IDToken(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}))
+	assert.Error(t, err)
+
+	// Tokens signed by another key
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": fake.Issuer(),
+		"aud": "dispatch-cli",
+		"sub": "admin@example.com",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString(key)
+	require.NoError(t, err)
+	_, err = p.Verify(ctx, forged)
+	assert.Error(t, err)
+}
+
+func TestVerifyKeyRotation(t *testing.T) {
+	fake := fakeoidc.NewFakeProvider("dispatch-cli", "user@example.com")
+	defer fake.Close()
+
+	ctx := context.Background()
+	p, err := oidc.NewProvider(ctx, fake.Issuer(), "dispatch-cli", nil)
+	require.NoError(t, err)
+
+	_, err = p.Verify(ctx, fake.IDToken(nil))
+	require.NoError(t, err)
+
+	// The keys were just fetched, a token signed by an unknown key fetches them again later only
+	fake.RotateKey()
+	_, err = p.Verify(ctx, fake.IDToken(nil))
+	assert.Error(t, err)
+}
+
+func TestJSONWebKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	jwk, err := oidc.NewJSONWebKey("1", &key.PublicKey)
+	require.NoError(t, err)
+	assert.Equal(t, "P-384", jwk.Crv)
+
+	public, err := jwk.PublicKey()
+	require.NoError(t, err)
+	assert.Equal(t, &key.PublicKey, public)
+
+	_, err = (&oidc.JSONWebKey{Kty: "oct"}).PublicKey()
+	assert.Error(t, err)
+}
@@ -27,6 +27,7 @@ func organizationModelToEntity(m *v1.Organization) *Organization {
 			OrganizationID: IdentityManagerFlags.OrgID,
 			Name:           *m.Name,
 		},
+		Members: m.Members,
 	}
 	return &e
 }
@@ -39,6 +40,7 @@ func organizationEntityToModel(e *Organization) *v1.Organization {
 		Status:       v1.Status(e.Status),
 		CreatedTime:  e.CreatedTime.Unix(),
 		ModifiedTime: e.ModifiedTime.Unix(),
+		Members:      e.Members,
 	}
 	return &m
 }
@@ -52,6 +54,13 @@ func (h *Handlers) getOrganizations(params organizationOperations.GetOrganizatio
 	opts := entitystore.Options{
 		Filter: entitystore.FilterExists(),
 	}
+	if err := utils.ParsePaging(&opts, params.Limit, params.Continue, params.Sort); err != nil {
+		return organizationOperations.NewGetOrganizationsDefault(http.StatusBadRequest).WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
 	err := h.store.List(ctx, IdentityManagerFlags.OrgID, opts, &organizations)
 	if err != nil {
 		log.Errorf("store error when listing organizations: %+v", err)
@@ -65,7 +74,7 @@ func (h *Handlers) getOrganizations(params organizationOperations.GetOrganizatio
 	for _, organization := range organizations {
 		organizationModels = append(organizationModels, organizationEntityToModel(organization))
 	}
-	return organizationOperations.NewGetOrganizationsOK().WithPayload(organizationModels)
+	return organizationOperations.NewGetOrganizationsOK().WithPayload(organizationModels).WithXDispatchContinue(utils.NextPage(opts))
 }
 
 func (h *Handlers) getOrganization(params organizationOperations.GetOrganizationParams, principal interface{}) middleware.Responder {
@@ -0,0 +1,134 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/casbin/casbin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/controller"
+	"github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/trace"
+)
+
+type organizationEntityHandler struct {
+	store    entitystore.EntityStore
+	enforcer *casbin.SyncedEnforcer
+}
+
+func (h *organizationEntityHandler) Type() reflect.Type {
+	return reflect.TypeOf(&Organization{})
+}
+
+func (h *organizationEntityHandler) Add(ctx context.Context, obj entitystore.Entity) (err error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	org := obj.(*Organization)
+	defer func() { h.store.UpdateWithError(ctx, org, err) }()
+
+	org.Status = entitystore.StatusREADY
+
+	log.Infof("organization %s has been created", org.Name)
+	return nil
+}
+
+func (h *organizationEntityHandler) Update(ctx context.Context, obj entitystore.Entity) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	return h.Add(ctx, obj)
+}
+
+// Delete deletes the policies, role bindings, roles, service accounts and revoked tokens of the organization before the
+// organization itself
+func (h *organizationEntityHandler) Delete(ctx context.Context, obj entitystore.Entity) (err error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	org := obj.(*Organization)
+
+	opts := entitystore.Options{
+		Filter: entitystore.FilterEverything(),
+	}
+	var policies []*Policy
+	if err := h.store.List(ctx, org.Name, opts, &policies); err != nil {
+		return errors.Wrapf(err, "store error when listing policies of organization %s", org.Name)
+	}
+	for _, policy := range policies {
+		if err := h.store.Delete(ctx, org.Name, policy.Name, policy); err != nil {
+			return errors.Wrapf(err, "store error when deleting policy %s", policy.Name)
+		}
+	}
+	var bindings []*RoleBinding
+	if err := h.store.List(ctx, org.Name, opts, &bindings); err != nil {
+		return errors.Wrapf(err, "store error when listing role bindings of organization %s", org.Name)
+	}
+	for _, binding := range bindings {
+		if err := h.store.Delete(ctx, org.Name, binding.Name, binding); err != nil {
+			return errors.Wrapf(err, "store error when deleting role binding %s", binding.Name)
+		}
+	}
+	var roles []*Role
+	if err := h.store.List(ctx, org.Name, opts, &roles); err != nil {
+		return errors.Wrapf(err, "store error when listing roles of organization %s", org.Name)
+	}
+	for _, role := range roles {
+		if err := h.store.Delete(ctx, org.Name, role.Name, role); err != nil {
+			return errors.Wrapf(err, "store error when deleting role %s", role.Name)
+		}
+	}
+	var svcAccounts []*ServiceAccount
+	if err := h.store.List(ctx, org.Name, opts, &svcAccounts); err != nil {
+		return errors.Wrapf(err, "store error when listing service accounts of organization %s", org.Name)
+	}
+	for _, svcAccount := range svcAccounts {
+		if err := h.store.Delete(ctx, org.Name, svcAccount.Name, svcAccount); err != nil {
+			return errors.Wrapf(err, "store error when deleting service account %s", svcAccount.Name)
+		}
+	}
+	var revokedTokens []*RevokedToken
+	if err := h.store.List(ctx, org.Name, opts, &revokedTokens); err != nil {
+		return errors.Wrapf(err, "store error when listing revoked tokens of organization %s", org.Name)
+	}
+	for _, revoked := range revokedTokens {
+		if err := h.store.Delete(ctx, org.Name, revoked.Name, revoked); err != nil {
+			return errors.Wrapf(err, "store error when deleting revoked token %s", revoked.Name)
+		}
+	}
+
+	// hard deletion
+	if err := h.store.Delete(ctx, org.OrganizationID, org.Name, org); err != nil {
+		return errors.Wrap(err, "store error when deleting organization")
+	}
+
+	if err := h.enforcer.LoadPolicy(); err != nil {
+		return errors.Wrap(err, "error when re-loading policies")
+	}
+
+	log.Infof("organization %s deleted from the entity store", org.Name)
+	return nil
+}
+
+func (h *organizationEntityHandler) Sync(ctx context.Context, resyncPeriod time.Duration) ([]entitystore.Entity, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	return controller.DefaultSync(ctx, h.store, h.Type(), resyncPeriod, nil)
+}
+
+func (h *organizationEntityHandler) Error(ctx context.Context, obj entitystore.Entity) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	log.Errorf("handleError func not implemented yet")
+	return nil
+}
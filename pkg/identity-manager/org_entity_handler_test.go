@@ -0,0 +1,73 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/casbin/casbin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/identity-manager/mocks"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+func TestOrganizationAdd(t *testing.T) {
+	es := helpers.MakeEntityStore(t)
+	adapter := &mocks.AdapterMock{}
+	adapter.On("LoadPolicy", mock.Anything).Return(nil)
+	handler := &organizationEntityHandler{
+		store:    es,
+		enforcer: casbin.NewSyncedEnforcer(casbin.NewModel(casbinPolicyModel), adapter),
+	}
+	e := &Organization{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: IdentityManagerFlags.OrgID,
+			Name:           "test-org-1",
+			Status:         entitystore.StatusCREATING,
+		},
+	}
+	es.Add(context.Background(), e)
+	assert.NoError(t, handler.Add(context.Background(), e))
+
+	var org Organization
+	assert.NoError(t, es.Get(context.Background(), IdentityManagerFlags.OrgID, "test-org-1", entitystore.Options{}, &org))
+	assert.Equal(t, entitystore.StatusREADY, org.Status)
+}
+
+func TestOrganizationDelete(t *testing.T) {
+	es := helpers.MakeEntityStore(t)
+	adapter := &mocks.AdapterMock{}
+	adapter.On("LoadPolicy", mock.Anything).Return(nil)
+	handler := &organizationEntityHandler{
+		store:    es,
+		enforcer: casbin.NewSyncedEnforcer(casbin.NewModel(casbinPolicyModel), adapter),
+	}
+	e := &Organization{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: IdentityManagerFlags.OrgID,
+			Name:           "test-org-1",
+			Status:         entitystore.StatusDELETING,
+		},
+	}
+	es.Add(context.Background(), e)
+	es.Add(context.Background(), &Policy{BaseEntity: entitystore.BaseEntity{OrganizationID: "test-org-1", Name: "test-policy-1"}})
+	es.Add(context.Background(), &ServiceAccount{BaseEntity: entitystore.BaseEntity{OrganizationID: "test-org-1", Name: "test-sa-1"}})
+	es.Add(context.Background(), &Policy{BaseEntity: entitystore.BaseEntity{OrganizationID: "test-org-2", Name: "test-policy-1"}})
+
+	assert.NoError(t, handler.Delete(context.Background(), e))
+	// Ensures LoadPolicy is called after delete
+	adapter.AssertNumberOfCalls(t, "LoadPolicy", 2)
+
+	assert.Error(t, es.Get(context.Background(), IdentityManagerFlags.OrgID, "test-org-1", entitystore.Options{}, &Organization{}))
+	assert.Error(t, es.Get(context.Background(), "test-org-1", "test-policy-1", entitystore.Options{}, &Policy{}))
+	assert.Error(t, es.Get(context.Background(), "test-org-1", "test-sa-1", entitystore.Options{}, &ServiceAccount{}))
+	// The other organizations are untouched
+	assert.NoError(t, es.Get(context.Background(), "test-org-2", "test-policy-1", entitystore.Options{}, &Policy{}))
+}
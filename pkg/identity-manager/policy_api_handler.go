@@ -21,10 +21,10 @@ import (
 	"github.com/vmware/dispatch/pkg/utils"
 )
 
-func policyModelToEntity(m *v1.Policy) *Policy {
+func policyModelToEntity(organizationID string, m *v1.Policy) *Policy {
 	e := Policy{
 		BaseEntity: entitystore.BaseEntity{
-			OrganizationID: IdentityManagerFlags.OrgID,
+			OrganizationID: organizationID,
 			Name:           *m.Name,
 		},
 	}
@@ -68,7 +68,14 @@ func (h *Handlers) getPolicies(params policyOperations.GetPoliciesParams, princi
 	opts := entitystore.Options{
 		Filter: entitystore.FilterExists(),
 	}
-	err := h.store.List(ctx, IdentityManagerFlags.OrgID, opts, &policies)
+	if err := utils.ParsePaging(&opts, params.Limit, params.Continue, params.Sort); err != nil {
+		return policyOperations.NewGetPoliciesDefault(http.StatusBadRequest).WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
+	err := h.store.List(ctx, params.XDispatchOrg, opts, &policies)
 	if err != nil {
 		log.Errorf("store error when listing policies: %+v", err)
 		return policyOperations.NewGetPoliciesInternalServerError().WithPayload(
@@ -81,7 +88,7 @@ func (h *Handlers) getPolicies(params policyOperations.GetPoliciesParams, princi
 	for _, policy := range policies {
 		policyModels = append(policyModels, policyEntityToModel(policy))
 	}
-	return policyOperations.NewGetPoliciesOK().WithPayload(policyModels)
+	return policyOperations.NewGetPoliciesOK().WithPayload(policyModels).WithXDispatchContinue(utils.NextPage(opts))
 }
 
 func (h *Handlers) getPolicy(params policyOperations.GetPolicyParams, principal interface{}) middleware.Responder {
@@ -95,7 +102,7 @@ func (h *Handlers) getPolicy(params policyOperations.GetPolicyParams, principal
 	}
 
 	name := params.PolicyName
-	if err := h.store.Get(ctx, IdentityManagerFlags.OrgID, name, opts, &policy); err != nil {
+	if err := h.store.Get(ctx, params.XDispatchOrg, name, opts, &policy); err != nil {
 		log.Errorf("store error when getting policy '%s': %+v", name, err)
 		return policyOperations.NewGetPolicyNotFound().WithPayload(
 			&v1.Error{
@@ -114,7 +121,7 @@ func (h *Handlers) addPolicy(params policyOperations.AddPolicyParams, principal
 	defer span.Finish()
 
 	policyRequest := params.Body
-	e := policyModelToEntity(policyRequest)
+	e := policyModelToEntity(params.XDispatchOrg, policyRequest)
 	for _, rule := range e.Rules {
 		// Do some basic validation although this must be handled at the goswagger server.
 		if rule.Subjects == nil || rule.Actions == nil || rule.Resources == nil {
@@ -158,7 +165,7 @@ func (h *Handlers) deletePolicy(params policyOperations.DeletePolicyParams, prin
 	}
 
 	var e Policy
-	if err := h.store.Get(ctx, IdentityManagerFlags.OrgID, name, opts, &e); err != nil {
+	if err := h.store.Get(ctx, params.XDispatchOrg, name, opts, &e); err != nil {
 		log.Errorf("store error when getting policy: %+v", err)
 		return policyOperations.NewDeletePolicyNotFound().WithPayload(
 			&v1.Error{
@@ -198,7 +205,7 @@ func (h *Handlers) updatePolicy(params policyOperations.UpdatePolicyParams, prin
 	}
 
 	e := Policy{}
-	if err := h.store.Get(ctx, IdentityManagerFlags.OrgID, params.PolicyName, opts, &e); err != nil {
+	if err := h.store.Get(ctx, params.XDispatchOrg, params.PolicyName, opts, &e); err != nil {
 		log.Errorf("store error when getting policy: %+v", err)
 		return policyOperations.NewUpdatePolicyNotFound().WithPayload(
 			&v1.Error{
@@ -207,7 +214,7 @@ func (h *Handlers) updatePolicy(params policyOperations.UpdatePolicyParams, prin
 			})
 	}
 
-	updateEntity := policyModelToEntity(params.Body)
+	updateEntity := policyModelToEntity(params.XDispatchOrg, params.Body)
 	updateEntity.CreatedTime = e.CreatedTime
 	updateEntity.ID = e.ID
 	updateEntity.Status = entitystore.StatusUPDATING
@@ -114,6 +114,43 @@ func TestGetPoliciesHandler(t *testing.T) {
 	assert.Equal(t, []string{"get"}, respBody[0].Rules[0].Actions)
 }
 
+func TestGetPoliciesHandlerPaging(t *testing.T) {
+	api := setupTestAPI(t, true)
+	addParams := policyOperations.AddPolicyParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/iam/policy", nil),
+		Body:        newPolicyModel("test-policy-2", []string{"user@example.com"}, []string{"*"}, []string{"get"}),
+	}
+	helpers.HandlerRequest(t, api.PolicyAddPolicyHandler.Handle(addParams, "testCookie"), &v1.Policy{}, http.StatusCreated)
+
+	params := policyOperations.GetPoliciesParams{
+		HTTPRequest: httptest.NewRequest("GET", "/v1/iam/policy", nil),
+		Limit:       swag.Int64(1),
+		Sort:        swag.String("-name"),
+	}
+	responder := api.PolicyGetPoliciesHandler.Handle(params, "testCookie")
+	var respBody []v1.Policy
+	helpers.HandlerRequest(t, responder, &respBody, http.StatusOK)
+	if assert.Len(t, respBody, 1) {
+		assert.Equal(t, "test-policy-2", *respBody[0].Name)
+	}
+	continueToken := responder.(*policyOperations.GetPoliciesOK).XDispatchContinue
+	assert.NotEmpty(t, continueToken)
+
+	params.Continue = swag.String(continueToken)
+	responder = api.PolicyGetPoliciesHandler.Handle(params, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, http.StatusOK)
+	if assert.Len(t, respBody, 1) {
+		assert.Equal(t, "test-policy-1", *respBody[0].Name)
+	}
+	assert.Empty(t, responder.(*policyOperations.GetPoliciesOK).XDispatchContinue)
+
+	params = policyOperations.GetPoliciesParams{
+		HTTPRequest: httptest.NewRequest("GET", "/v1/iam/policy", nil),
+		Sort:        swag.String("owner"),
+	}
+	helpers.HandlerRequest(t, api.PolicyGetPoliciesHandler.Handle(params, "testCookie"), &v1.Error{}, http.StatusBadRequest)
+}
+
 func TestDeletePolicyHandler(t *testing.T) {
 
 	r := httptest.NewRequest("DELETE", "/v1/iam/policy/test-policy-1", nil)
@@ -240,3 +277,35 @@ func TestUpdatePolicyHandlerNotFound(t *testing.T) {
 	var respBody v1.Policy
 	helpers.HandlerRequest(t, responder, &respBody, http.StatusNotFound)
 }
+
+func TestPolicyHandlersOrganizationIsolation(t *testing.T) {
+	api := setupTestAPI(t, false)
+
+	reqBody := newPolicyModel("test-policy-1", []string{"user@example.com"}, []string{"*"}, []string{"get"})
+	addParams := policyOperations.AddPolicyParams{
+		HTTPRequest:  httptest.NewRequest("POST", "/v1/iam/policy", nil),
+		Body:         reqBody,
+		XDispatchOrg: "org-a",
+	}
+	helpers.HandlerRequest(t, api.PolicyAddPolicyHandler.Handle(addParams, "testCookie"), &v1.Policy{}, http.StatusCreated)
+
+	getParams := policyOperations.GetPolicyParams{
+		HTTPRequest:  httptest.NewRequest("GET", "/v1/iam/policy/test-policy-1", nil),
+		PolicyName:   "test-policy-1",
+		XDispatchOrg: "org-b",
+	}
+	helpers.HandlerRequest(t, api.PolicyGetPolicyHandler.Handle(getParams, "testCookie"), &v1.Error{}, http.StatusNotFound)
+
+	var policies []*v1.Policy
+	listParams := policyOperations.GetPoliciesParams{
+		HTTPRequest:  httptest.NewRequest("GET", "/v1/iam/policy", nil),
+		XDispatchOrg: "org-b",
+	}
+	helpers.HandlerRequest(t, api.PolicyGetPoliciesHandler.Handle(listParams, "testCookie"), &policies, http.StatusOK)
+	assert.Empty(t, policies)
+
+	getParams.XDispatchOrg = "org-a"
+	var respBody v1.Policy
+	helpers.HandlerRequest(t, api.PolicyGetPolicyHandler.Handle(getParams, "testCookie"), &respBody, http.StatusOK)
+	assert.Equal(t, "test-policy-1", *respBody.Name)
+}
@@ -0,0 +1,231 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"fmt"
+	"net/http"
+
+	middleware "github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/entity-store"
+	roleOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/role"
+	"github.com/vmware/dispatch/pkg/trace"
+	"github.com/vmware/dispatch/pkg/utils"
+)
+
+func roleModelToEntity(organizationID string, m *v1.Role) *Role {
+	e := Role{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: organizationID,
+			Name:           *m.Name,
+		},
+	}
+	for _, r := range m.Rules {
+		rule := RoleRule{
+			Resources: r.Resources,
+			Actions:   r.Actions,
+		}
+		e.Rules = append(e.Rules, rule)
+	}
+	return &e
+}
+
+func roleEntityToModel(e *Role) *v1.Role {
+	m := v1.Role{
+		ID:           strfmt.UUID(e.ID),
+		Name:         swag.String(e.Name),
+		Kind:         utils.RoleKind,
+		Status:       v1.Status(e.Status),
+		CreatedTime:  e.CreatedTime.Unix(),
+		ModifiedTime: e.ModifiedTime.Unix(),
+	}
+	for _, r := range e.Rules {
+		rule := v1.RoleRule{
+			Resources: r.Resources,
+			Actions:   r.Actions,
+		}
+		m.Rules = append(m.Rules, &rule)
+	}
+	return &m
+}
+
+func (h *Handlers) getRoles(params roleOperations.GetRolesParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	var roles []*Role
+
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	if err := utils.ParsePaging(&opts, params.Limit, params.Continue, params.Sort); err != nil {
+		return roleOperations.NewGetRolesDefault(http.StatusBadRequest).WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
+	err := h.store.List(ctx, params.XDispatchOrg, opts, &roles)
+	if err != nil {
+		log.Errorf("store error when listing roles: %+v", err)
+		return roleOperations.NewGetRolesInternalServerError().WithPayload(
+			&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String("internal server error when getting roles"),
+			})
+	}
+	var roleModels []*v1.Role
+	for _, role := range roles {
+		roleModels = append(roleModels, roleEntityToModel(role))
+	}
+	return roleOperations.NewGetRolesOK().WithPayload(roleModels).WithXDispatchContinue(utils.NextPage(opts))
+}
+
+func (h *Handlers) getRole(params roleOperations.GetRoleParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	var role Role
+
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+
+	name := params.RoleName
+	if err := h.store.Get(ctx, params.XDispatchOrg, name, opts, &role); err != nil {
+		log.Errorf("store error when getting role '%s': %+v", name, err)
+		return roleOperations.NewGetRoleNotFound().WithPayload(
+			&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String("role not found"),
+			})
+	}
+
+	roleModel := roleEntityToModel(&role)
+
+	return roleOperations.NewGetRoleOK().WithPayload(roleModel)
+}
+
+func (h *Handlers) addRole(params roleOperations.AddRoleParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	roleRequest := params.Body
+	e := roleModelToEntity(params.XDispatchOrg, roleRequest)
+	for _, rule := range e.Rules {
+		// Do some basic validation although this must be handled at the goswagger server.
+		if rule.Actions == nil || rule.Resources == nil {
+			return roleOperations.NewAddRoleBadRequest().WithPayload(
+				&v1.Error{
+					Code:    http.StatusBadRequest,
+					Message: swag.String("invalid rule definition, missing required fields"),
+				})
+		}
+	}
+
+	e.Status = entitystore.StatusCREATING
+
+	if _, err := h.store.Add(ctx, e); err != nil {
+		if entitystore.IsUniqueViolation(err) {
+			return roleOperations.NewAddRoleConflict().WithPayload(&v1.Error{
+				Code:    http.StatusConflict,
+				Message: swag.String("error creating role: non-unique name"),
+			})
+		}
+		log.Errorf("store error when adding a new role %s: %+v", e.Name, err)
+		return roleOperations.NewAddRoleInternalServerError().WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when storing new role"),
+		})
+	}
+
+	h.watcher.OnAction(ctx, e)
+
+	return roleOperations.NewAddRoleCreated().WithPayload(roleEntityToModel(e))
+}
+
+func (h *Handlers) deleteRole(params roleOperations.DeleteRoleParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	name := params.RoleName
+
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+
+	var e Role
+	if err := h.store.Get(ctx, params.XDispatchOrg, name, opts, &e); err != nil {
+		log.Errorf("store error when getting role: %+v", err)
+		return roleOperations.NewDeleteRoleNotFound().WithPayload(
+			&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String("role not found"),
+			})
+	}
+
+	if e.Status == entitystore.StatusDELETING {
+		log.Warnf("Attempting to delete role %s which already is in DELETING state", e.Name)
+		return roleOperations.NewDeleteRoleBadRequest().WithPayload(&v1.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String(fmt.Sprintf("Unable to delete role %s: role is already being deleted", e.Name)),
+		})
+	}
+
+	e.Status = entitystore.StatusDELETING
+	if _, err := h.store.Update(ctx, e.Revision, &e); err != nil {
+		log.Errorf("store error when deleting a role %s: %+v", e.Name, err)
+		return roleOperations.NewDeleteRoleInternalServerError().WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when deleting a role"),
+		})
+	}
+
+	h.watcher.OnAction(ctx, &e)
+
+	return roleOperations.NewDeleteRoleOK().WithPayload(roleEntityToModel(&e))
+}
+
+func (h *Handlers) updateRole(params roleOperations.UpdateRoleParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+
+	e := Role{}
+	if err := h.store.Get(ctx, params.XDispatchOrg, params.RoleName, opts, &e); err != nil {
+		log.Errorf("store error when getting role: %+v", err)
+		return roleOperations.NewUpdateRoleNotFound().WithPayload(
+			&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String("role not found"),
+			})
+	}
+
+	updateEntity := roleModelToEntity(params.XDispatchOrg, params.Body)
+	updateEntity.CreatedTime = e.CreatedTime
+	updateEntity.ID = e.ID
+	updateEntity.Status = entitystore.StatusUPDATING
+
+	if _, err := h.store.Update(ctx, e.Revision, updateEntity); err != nil {
+		log.Errorf("store error when updating a role %s: %+v", e.Name, err)
+		return roleOperations.NewUpdateRoleInternalServerError().WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when updating a role"),
+		})
+	}
+
+	h.watcher.OnAction(ctx, updateEntity)
+
+	return roleOperations.NewUpdateRoleOK().WithPayload(roleEntityToModel(updateEntity))
+}
@@ -0,0 +1,131 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	roleOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/role"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+	"github.com/vmware/dispatch/pkg/utils"
+)
+
+func newRoleModel(name string, resources []string, actions []string) *v1.Role {
+	return &v1.Role{
+		Name: swag.String(name),
+		Rules: []*v1.RoleRule{
+			{
+				Resources: resources,
+				Actions:   actions,
+			},
+		},
+	}
+}
+
+func TestAddRoleHandler(t *testing.T) {
+	resources := []string{"function/billing-*"}
+	actions := []string{"get", "update"}
+
+	params := roleOperations.AddRoleParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/iam/role", nil),
+		Body:        newRoleModel("billing-developer", resources, actions),
+	}
+	api := setupTestAPI(t, false)
+	responder := api.RoleAddRoleHandler.Handle(params, "testCookie")
+	var respBody v1.Role
+	helpers.HandlerRequest(t, responder, &respBody, http.StatusCreated)
+
+	assert.NotEmpty(t, respBody.ID)
+	assert.Equal(t, "billing-developer", *respBody.Name)
+	assert.Equal(t, utils.RoleKind, respBody.Kind)
+	assert.Equal(t, resources, respBody.Rules[0].Resources)
+	assert.Equal(t, actions, respBody.Rules[0].Actions)
+
+	// Names are unique
+	responder = api.RoleAddRoleHandler.Handle(params, "testCookie")
+	helpers.HandlerRequest(t, responder, &v1.Error{}, http.StatusConflict)
+}
+
+func TestAddRoleHandlerBasicValidation(t *testing.T) {
+	params := roleOperations.AddRoleParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/iam/role", nil),
+		Body:        newRoleModel("billing-developer", nil, []string{"get"}),
+	}
+	api := setupTestAPI(t, false)
+	responder := api.RoleAddRoleHandler.Handle(params, "testCookie")
+	var respBody v1.Error
+	helpers.HandlerRequest(t, responder, &respBody, http.StatusBadRequest)
+	assert.EqualValues(t, http.StatusBadRequest, respBody.Code)
+}
+
+func TestRoleHandlers(t *testing.T) {
+	api := setupTestAPI(t, false)
+	addParams := roleOperations.AddRoleParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/iam/role", nil),
+		Body:        newRoleModel("billing-developer", []string{"function/billing-*"}, []string{"get"}),
+	}
+	helpers.HandlerRequest(t, api.RoleAddRoleHandler.Handle(addParams, "testCookie"), &v1.Role{}, http.StatusCreated)
+
+	var roles []*v1.Role
+	listParams := roleOperations.GetRolesParams{
+		HTTPRequest: httptest.NewRequest("GET", "/v1/iam/role", nil),
+	}
+	helpers.HandlerRequest(t, api.RoleGetRolesHandler.Handle(listParams, "testCookie"), &roles, http.StatusOK)
+	assert.Len(t, roles, 1)
+
+	updateParams := roleOperations.UpdateRoleParams{
+		HTTPRequest: httptest.NewRequest("PUT", "/v1/iam/role/billing-developer", nil),
+		RoleName:    "billing-developer",
+		Body:        newRoleModel("billing-developer", []string{"function/billing-*"}, []string{"*"}),
+	}
+	var respBody v1.Role
+	helpers.HandlerRequest(t, api.RoleUpdateRoleHandler.Handle(updateParams, "testCookie"), &respBody, http.StatusOK)
+	assert.Equal(t, []string{"*"}, respBody.Rules[0].Actions)
+
+	getParams := roleOperations.GetRoleParams{
+		HTTPRequest: httptest.NewRequest("GET", "/v1/iam/role/billing-developer", nil),
+		RoleName:    "billing-developer",
+	}
+	helpers.HandlerRequest(t, api.RoleGetRoleHandler.Handle(getParams, "testCookie"), &respBody, http.StatusOK)
+	assert.Equal(t, []string{"*"}, respBody.Rules[0].Actions)
+
+	deleteParams := roleOperations.DeleteRoleParams{
+		HTTPRequest: httptest.NewRequest("DELETE", "/v1/iam/role/billing-developer", nil),
+		RoleName:    "billing-developer",
+	}
+	helpers.HandlerRequest(t, api.RoleDeleteRoleHandler.Handle(deleteParams, "testCookie"), &respBody, http.StatusOK)
+	assert.Equal(t, v1.StatusDELETING, respBody.Status)
+	helpers.HandlerRequest(t, api.RoleDeleteRoleHandler.Handle(deleteParams, "testCookie"), &v1.Error{}, http.StatusBadRequest)
+}
+
+func TestRoleHandlersNotFound(t *testing.T) {
+	api := setupTestAPI(t, false)
+
+	getParams := roleOperations.GetRoleParams{
+		HTTPRequest: httptest.NewRequest("GET", "/v1/iam/role/missing", nil),
+		RoleName:    "missing",
+	}
+	helpers.HandlerRequest(t, api.RoleGetRoleHandler.Handle(getParams, "testCookie"), &v1.Error{}, http.StatusNotFound)
+
+	updateParams := roleOperations.UpdateRoleParams{
+		HTTPRequest: httptest.NewRequest("PUT", "/v1/iam/role/missing", nil),
+		RoleName:    "missing",
+		Body:        newRoleModel("missing", []string{"*"}, []string{"*"}),
+	}
+	helpers.HandlerRequest(t, api.RoleUpdateRoleHandler.Handle(updateParams, "testCookie"), &v1.Error{}, http.StatusNotFound)
+
+	deleteParams := roleOperations.DeleteRoleParams{
+		HTTPRequest: httptest.NewRequest("DELETE", "/v1/iam/role/missing", nil),
+		RoleName:    "missing",
+	}
+	helpers.HandlerRequest(t, api.RoleDeleteRoleHandler.Handle(deleteParams, "testCookie"), &v1.Error{}, http.StatusNotFound)
+}
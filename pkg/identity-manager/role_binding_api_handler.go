@@ -0,0 +1,237 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	middleware "github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/entity-store"
+	roleBindingOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/rolebinding"
+	"github.com/vmware/dispatch/pkg/trace"
+	"github.com/vmware/dispatch/pkg/utils"
+)
+
+func roleBindingModelToEntity(organizationID string, m *v1.RoleBinding) *RoleBinding {
+	e := RoleBinding{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: organizationID,
+			Name:           *m.Name,
+		},
+		Role:         *m.Role,
+		Subjects:     m.Subjects,
+		Applications: m.Applications,
+	}
+	return &e
+}
+
+func roleBindingEntityToModel(e *RoleBinding) *v1.RoleBinding {
+	m := v1.RoleBinding{
+		ID:           strfmt.UUID(e.ID),
+		Name:         swag.String(e.Name),
+		Kind:         utils.RoleBindingKind,
+		Status:       v1.Status(e.Status),
+		CreatedTime:  e.CreatedTime.Unix(),
+		ModifiedTime: e.ModifiedTime.Unix(),
+		Role:         swag.String(e.Role),
+		Subjects:     e.Subjects,
+		Applications: e.Applications,
+	}
+	return &m
+}
+
+// roleExists returns whether the role granted by a role binding exists in the organization
+func (h *Handlers) roleExists(ctx context.Context, organizationID string, name string) bool {
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	var role Role
+	return h.store.Get(ctx, organizationID, name, opts, &role) == nil
+}
+
+func (h *Handlers) getRoleBindings(params roleBindingOperations.GetRoleBindingsParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	var bindings []*RoleBinding
+
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	if err := utils.ParsePaging(&opts, params.Limit, params.Continue, params.Sort); err != nil {
+		return roleBindingOperations.NewGetRoleBindingsDefault(http.StatusBadRequest).WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
+	err := h.store.List(ctx, params.XDispatchOrg, opts, &bindings)
+	if err != nil {
+		log.Errorf("store error when listing role bindings: %+v", err)
+		return roleBindingOperations.NewGetRoleBindingsInternalServerError().WithPayload(
+			&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String("internal server error when getting role bindings"),
+			})
+	}
+	var bindingModels []*v1.RoleBinding
+	for _, binding := range bindings {
+		bindingModels = append(bindingModels, roleBindingEntityToModel(binding))
+	}
+	return roleBindingOperations.NewGetRoleBindingsOK().WithPayload(bindingModels).WithXDispatchContinue(utils.NextPage(opts))
+}
+
+func (h *Handlers) getRoleBinding(params roleBindingOperations.GetRoleBindingParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	var binding RoleBinding
+
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+
+	name := params.RoleBindingName
+	if err := h.store.Get(ctx, params.XDispatchOrg, name, opts, &binding); err != nil {
+		log.Errorf("store error when getting role binding '%s': %+v", name, err)
+		return roleBindingOperations.NewGetRoleBindingNotFound().WithPayload(
+			&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String("role binding not found"),
+			})
+	}
+
+	bindingModel := roleBindingEntityToModel(&binding)
+
+	return roleBindingOperations.NewGetRoleBindingOK().WithPayload(bindingModel)
+}
+
+func (h *Handlers) addRoleBinding(params roleBindingOperations.AddRoleBindingParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	bindingRequest := params.Body
+	e := roleBindingModelToEntity(params.XDispatchOrg, bindingRequest)
+	if !h.roleExists(ctx, params.XDispatchOrg, e.Role) {
+		return roleBindingOperations.NewAddRoleBindingBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(fmt.Sprintf("invalid role binding definition, role %s not found", e.Role)),
+			})
+	}
+
+	e.Status = entitystore.StatusCREATING
+
+	if _, err := h.store.Add(ctx, e); err != nil {
+		if entitystore.IsUniqueViolation(err) {
+			return roleBindingOperations.NewAddRoleBindingConflict().WithPayload(&v1.Error{
+				Code:    http.StatusConflict,
+				Message: swag.String("error creating role binding: non-unique name"),
+			})
+		}
+		log.Errorf("store error when adding a new role binding %s: %+v", e.Name, err)
+		return roleBindingOperations.NewAddRoleBindingInternalServerError().WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when storing new role binding"),
+		})
+	}
+
+	h.watcher.OnAction(ctx, e)
+
+	return roleBindingOperations.NewAddRoleBindingCreated().WithPayload(roleBindingEntityToModel(e))
+}
+
+func (h *Handlers) deleteRoleBinding(params roleBindingOperations.DeleteRoleBindingParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	name := params.RoleBindingName
+
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+
+	var e RoleBinding
+	if err := h.store.Get(ctx, params.XDispatchOrg, name, opts, &e); err != nil {
+		log.Errorf("store error when getting role binding: %+v", err)
+		return roleBindingOperations.NewDeleteRoleBindingNotFound().WithPayload(
+			&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String("role binding not found"),
+			})
+	}
+
+	if e.Status == entitystore.StatusDELETING {
+		log.Warnf("Attempting to delete role binding %s which already is in DELETING state", e.Name)
+		return roleBindingOperations.NewDeleteRoleBindingBadRequest().WithPayload(&v1.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String(fmt.Sprintf("Unable to delete role binding %s: role binding is already being deleted", e.Name)),
+		})
+	}
+
+	e.Status = entitystore.StatusDELETING
+	if _, err := h.store.Update(ctx, e.Revision, &e); err != nil {
+		log.Errorf("store error when deleting a role binding %s: %+v", e.Name, err)
+		return roleBindingOperations.NewDeleteRoleBindingInternalServerError().WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when deleting a role binding"),
+		})
+	}
+
+	h.watcher.OnAction(ctx, &e)
+
+	return roleBindingOperations.NewDeleteRoleBindingOK().WithPayload(roleBindingEntityToModel(&e))
+}
+
+func (h *Handlers) updateRoleBinding(params roleBindingOperations.UpdateRoleBindingParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+
+	e := RoleBinding{}
+	if err := h.store.Get(ctx, params.XDispatchOrg, params.RoleBindingName, opts, &e); err != nil {
+		log.Errorf("store error when getting role binding: %+v", err)
+		return roleBindingOperations.NewUpdateRoleBindingNotFound().WithPayload(
+			&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String("role binding not found"),
+			})
+	}
+
+	updateEntity := roleBindingModelToEntity(params.XDispatchOrg, params.Body)
+	if !h.roleExists(ctx, params.XDispatchOrg, updateEntity.Role) {
+		return roleBindingOperations.NewUpdateRoleBindingBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(fmt.Sprintf("invalid role binding definition, role %s not found", updateEntity.Role)),
+			})
+	}
+	updateEntity.CreatedTime = e.CreatedTime
+	updateEntity.ID = e.ID
+	updateEntity.Status = entitystore.StatusUPDATING
+
+	if _, err := h.store.Update(ctx, e.Revision, updateEntity); err != nil {
+		log.Errorf("store error when updating a role binding %s: %+v", e.Name, err)
+		return roleBindingOperations.NewUpdateRoleBindingInternalServerError().WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when updating a role binding"),
+		})
+	}
+
+	h.watcher.OnAction(ctx, updateEntity)
+
+	return roleBindingOperations.NewUpdateRoleBindingOK().WithPayload(roleBindingEntityToModel(updateEntity))
+}
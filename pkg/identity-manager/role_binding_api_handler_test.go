@@ -0,0 +1,109 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	roleOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/role"
+	roleBindingOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/rolebinding"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+	"github.com/vmware/dispatch/pkg/utils"
+)
+
+func newRoleBindingModel(name string, role string, subjects []string, applications []string) *v1.RoleBinding {
+	return &v1.RoleBinding{
+		Name:         swag.String(name),
+		Role:         swag.String(role),
+		Subjects:     subjects,
+		Applications: applications,
+	}
+}
+
+func TestAddRoleBindingHandler(t *testing.T) {
+	api := setupTestAPI(t, false)
+	subjects := []string{"dev@example.com", "group:billing"}
+	applications := []string{"billing"}
+
+	params := roleBindingOperations.AddRoleBindingParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/iam/rolebinding", nil),
+		Body:        newRoleBindingModel("billing-developers", "developer", subjects, applications),
+	}
+	// The role must exist
+	helpers.HandlerRequest(t, api.RolebindingAddRoleBindingHandler.Handle(params, "testCookie"), &v1.Error{}, http.StatusBadRequest)
+
+	roleParams := roleOperations.AddRoleParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/iam/role", nil),
+		Body:        newRoleModel("developer", []string{"function"}, []string{"*"}),
+	}
+	helpers.HandlerRequest(t, api.RoleAddRoleHandler.Handle(roleParams, "testCookie"), &v1.Role{}, http.StatusCreated)
+
+	var respBody v1.RoleBinding
+	helpers.HandlerRequest(t, api.RolebindingAddRoleBindingHandler.Handle(params, "testCookie"), &respBody, http.StatusCreated)
+	assert.NotEmpty(t, respBody.ID)
+	assert.Equal(t, "billing-developers", *respBody.Name)
+	assert.Equal(t, utils.RoleBindingKind, respBody.Kind)
+	assert.Equal(t, "developer", *respBody.Role)
+	assert.Equal(t, subjects, respBody.Subjects)
+	assert.Equal(t, applications, respBody.Applications)
+
+	helpers.HandlerRequest(t, api.RolebindingAddRoleBindingHandler.Handle(params, "testCookie"), &v1.Error{}, http.StatusConflict)
+}
+
+func TestRoleBindingHandlers(t *testing.T) {
+	api := setupTestAPI(t, false)
+	roleParams := roleOperations.AddRoleParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/iam/role", nil),
+		Body:        newRoleModel("developer", []string{"function"}, []string{"*"}),
+	}
+	helpers.HandlerRequest(t, api.RoleAddRoleHandler.Handle(roleParams, "testCookie"), &v1.Role{}, http.StatusCreated)
+	addParams := roleBindingOperations.AddRoleBindingParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/iam/rolebinding", nil),
+		Body:        newRoleBindingModel("developers", "developer", []string{"dev@example.com"}, nil),
+	}
+	helpers.HandlerRequest(t, api.RolebindingAddRoleBindingHandler.Handle(addParams, "testCookie"), &v1.RoleBinding{}, http.StatusCreated)
+
+	var bindings []*v1.RoleBinding
+	listParams := roleBindingOperations.GetRoleBindingsParams{
+		HTTPRequest: httptest.NewRequest("GET", "/v1/iam/rolebinding", nil),
+	}
+	helpers.HandlerRequest(t, api.RolebindingGetRoleBindingsHandler.Handle(listParams, "testCookie"), &bindings, http.StatusOK)
+	assert.Len(t, bindings, 1)
+
+	updateParams := roleBindingOperations.UpdateRoleBindingParams{
+		HTTPRequest:     httptest.NewRequest("PUT", "/v1/iam/rolebinding/developers", nil),
+		RoleBindingName: "developers",
+		Body:            newRoleBindingModel("developers", "missing", []string{"dev@example.com"}, nil),
+	}
+	helpers.HandlerRequest(t, api.RolebindingUpdateRoleBindingHandler.Handle(updateParams, "testCookie"), &v1.Error{}, http.StatusBadRequest)
+	updateParams.Body = newRoleBindingModel("developers", "developer", []string{"group:developers"}, []string{"billing"})
+	var respBody v1.RoleBinding
+	helpers.HandlerRequest(t, api.RolebindingUpdateRoleBindingHandler.Handle(updateParams, "testCookie"), &respBody, http.StatusOK)
+	assert.Equal(t, []string{"group:developers"}, respBody.Subjects)
+
+	getParams := roleBindingOperations.GetRoleBindingParams{
+		HTTPRequest:     httptest.NewRequest("GET", "/v1/iam/rolebinding/developers", nil),
+		RoleBindingName: "developers",
+	}
+	helpers.HandlerRequest(t, api.RolebindingGetRoleBindingHandler.Handle(getParams, "testCookie"), &respBody, http.StatusOK)
+	assert.Equal(t, []string{"billing"}, respBody.Applications)
+
+	deleteParams := roleBindingOperations.DeleteRoleBindingParams{
+		HTTPRequest:     httptest.NewRequest("DELETE", "/v1/iam/rolebinding/developers", nil),
+		RoleBindingName: "developers",
+	}
+	helpers.HandlerRequest(t, api.RolebindingDeleteRoleBindingHandler.Handle(deleteParams, "testCookie"), &respBody, http.StatusOK)
+	assert.Equal(t, v1.StatusDELETING, respBody.Status)
+
+	getParams.RoleBindingName = "missing"
+	helpers.HandlerRequest(t, api.RolebindingGetRoleBindingHandler.Handle(getParams, "testCookie"), &v1.Error{}, http.StatusNotFound)
+}
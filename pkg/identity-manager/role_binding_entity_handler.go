@@ -0,0 +1,89 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/casbin/casbin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/controller"
+	"github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/trace"
+)
+
+type roleBindingEntityHandler struct {
+	store    entitystore.EntityStore
+	enforcer *casbin.SyncedEnforcer
+}
+
+func (h *roleBindingEntityHandler) Type() reflect.Type {
+	return reflect.TypeOf(&RoleBinding{})
+}
+
+func (h *roleBindingEntityHandler) Add(ctx context.Context, obj entitystore.Entity) (err error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	binding := obj.(*RoleBinding)
+	defer func() { h.store.UpdateWithError(ctx, binding, err) }()
+
+	binding.Status = entitystore.StatusREADY
+
+	if err := h.enforcer.LoadPolicy(); err != nil {
+		return errors.Wrap(err, "error when re-loading policies")
+	}
+
+	log.Infof("role binding %s has been created", binding.Name)
+
+	return nil
+}
+
+func (h *roleBindingEntityHandler) Update(ctx context.Context, obj entitystore.Entity) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	return h.Add(ctx, obj)
+}
+
+func (h *roleBindingEntityHandler) Delete(ctx context.Context, obj entitystore.Entity) (err error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	binding := obj.(*RoleBinding)
+
+	// hard deletion
+	if err := h.store.Delete(ctx, binding.OrganizationID, binding.Name, binding); err != nil {
+		return errors.Wrap(err, "store error when deleting role binding")
+	}
+
+	if err := h.enforcer.LoadPolicy(); err != nil {
+		return errors.Wrap(err, "error when re-loading policies")
+	}
+
+	log.Infof("role binding %s deleted from the entity store", binding.Name)
+	return nil
+}
+
+func (h *roleBindingEntityHandler) Sync(ctx context.Context, resyncPeriod time.Duration) ([]entitystore.Entity, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	// Policies, including the rules of role bindings, are periodically reloaded by the policy entity handler
+	return controller.DefaultSync(ctx, h.store, h.Type(), resyncPeriod, nil)
+}
+
+func (h *roleBindingEntityHandler) Error(ctx context.Context, obj entitystore.Entity) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	log.Errorf("handleError func not implemented yet")
+	return nil
+}
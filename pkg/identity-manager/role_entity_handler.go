@@ -0,0 +1,89 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/casbin/casbin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/controller"
+	"github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/trace"
+)
+
+type roleEntityHandler struct {
+	store    entitystore.EntityStore
+	enforcer *casbin.SyncedEnforcer
+}
+
+func (h *roleEntityHandler) Type() reflect.Type {
+	return reflect.TypeOf(&Role{})
+}
+
+func (h *roleEntityHandler) Add(ctx context.Context, obj entitystore.Entity) (err error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	role := obj.(*Role)
+	defer func() { h.store.UpdateWithError(ctx, role, err) }()
+
+	role.Status = entitystore.StatusREADY
+
+	if err := h.enforcer.LoadPolicy(); err != nil {
+		return errors.Wrap(err, "error when re-loading policies")
+	}
+
+	log.Infof("role %s has been created", role.Name)
+
+	return nil
+}
+
+func (h *roleEntityHandler) Update(ctx context.Context, obj entitystore.Entity) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	return h.Add(ctx, obj)
+}
+
+func (h *roleEntityHandler) Delete(ctx context.Context, obj entitystore.Entity) (err error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	role := obj.(*Role)
+
+	// hard deletion
+	if err := h.store.Delete(ctx, role.OrganizationID, role.Name, role); err != nil {
+		return errors.Wrap(err, "store error when deleting role")
+	}
+
+	if err := h.enforcer.LoadPolicy(); err != nil {
+		return errors.Wrap(err, "error when re-loading policies")
+	}
+
+	log.Infof("role %s deleted from the entity store", role.Name)
+	return nil
+}
+
+func (h *roleEntityHandler) Sync(ctx context.Context, resyncPeriod time.Duration) ([]entitystore.Entity, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	// Policies, including the rules of role bindings, are periodically reloaded by the policy entity handler
+	return controller.DefaultSync(ctx, h.store, h.Type(), resyncPeriod, nil)
+}
+
+func (h *roleEntityHandler) Error(ctx context.Context, obj entitystore.Entity) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	log.Errorf("handleError func not implemented yet")
+	return nil
+}
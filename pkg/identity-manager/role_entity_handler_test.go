@@ -0,0 +1,75 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/casbin/casbin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/identity-manager/mocks"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+func TestRoleAddDelete(t *testing.T) {
+	es := helpers.MakeEntityStore(t)
+	adapter := &mocks.AdapterMock{}
+	adapter.On("LoadPolicy", mock.Anything).Return(nil)
+	enforcer := casbin.NewSyncedEnforcer(casbin.NewModel(casbinPolicyModel), adapter)
+	handler := &roleEntityHandler{
+		store:    es,
+		enforcer: enforcer,
+	}
+	e := &Role{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: IdentityManagerFlags.OrgID,
+			Name:           "test-role-1",
+			Status:         entitystore.StatusCREATING,
+		},
+		Rules: []RoleRule{},
+	}
+	es.Add(context.Background(), e)
+	assert.NoError(t, handler.Add(context.Background(), e))
+	assert.Equal(t, entitystore.StatusREADY, e.Status)
+	// Ensures LoadPolicy is called after add
+	adapter.AssertNumberOfCalls(t, "LoadPolicy", 2)
+
+	assert.NoError(t, handler.Delete(context.Background(), e))
+	adapter.AssertNumberOfCalls(t, "LoadPolicy", 3)
+	assert.Error(t, es.Get(context.Background(), IdentityManagerFlags.OrgID, "test-role-1", entitystore.Options{}, &Role{}))
+}
+
+func TestRoleBindingAddDelete(t *testing.T) {
+	es := helpers.MakeEntityStore(t)
+	adapter := &mocks.AdapterMock{}
+	adapter.On("LoadPolicy", mock.Anything).Return(nil)
+	enforcer := casbin.NewSyncedEnforcer(casbin.NewModel(casbinPolicyModel), adapter)
+	handler := &roleBindingEntityHandler{
+		store:    es,
+		enforcer: enforcer,
+	}
+	e := &RoleBinding{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: IdentityManagerFlags.OrgID,
+			Name:           "test-binding-1",
+			Status:         entitystore.StatusCREATING,
+		},
+		Role:     "test-role-1",
+		Subjects: []string{"user@example.com"},
+	}
+	es.Add(context.Background(), e)
+	assert.NoError(t, handler.Add(context.Background(), e))
+	assert.Equal(t, entitystore.StatusREADY, e.Status)
+	adapter.AssertNumberOfCalls(t, "LoadPolicy", 2)
+
+	assert.NoError(t, handler.Delete(context.Background(), e))
+	adapter.AssertNumberOfCalls(t, "LoadPolicy", 3)
+	assert.Error(t, es.Get(context.Background(), IdentityManagerFlags.OrgID, "test-binding-1", entitystore.Options{}, &RoleBinding{}))
+}
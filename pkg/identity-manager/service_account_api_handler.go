@@ -8,6 +8,7 @@ package identitymanager
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	middleware "github.com/go-openapi/runtime/middleware"
 	"github.com/go-openapi/strfmt"
@@ -16,25 +17,30 @@ import (
 
 	"encoding/base64"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/pkg/errors"
 	"github.com/vmware/dispatch/pkg/api/v1"
 	"github.com/vmware/dispatch/pkg/entity-store"
 	serviceAccountOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/serviceaccount"
+	"github.com/vmware/dispatch/pkg/identity-manager/keys"
 	"github.com/vmware/dispatch/pkg/trace"
 	"github.com/vmware/dispatch/pkg/utils"
 )
 
-func serviceAccountModelToEntity(m *v1.ServiceAccount) *ServiceAccount {
+func serviceAccountModelToEntity(organizationID string, m *v1.ServiceAccount) *ServiceAccount {
 	e := ServiceAccount{
 		BaseEntity: entitystore.BaseEntity{
-			OrganizationID: IdentityManagerFlags.OrgID,
+			OrganizationID: organizationID,
 			Name:           *m.Name,
 		},
 	}
 	e.PublicKey = *m.PublicKey
-	// We don't allow users to change the algorithm for now.
-	e.JWTAlgorithm = "RS256"
+	// The public key is the first of the active keys, the algorithm of the keys is set once they are validated
+	e.Keys = []ServiceAccountKey{{PublicKey: e.PublicKey}}
+	for _, k := range m.Keys {
+		if k != nil && k.PublicKey != nil && *k.PublicKey != e.PublicKey {
+			e.Keys = append(e.Keys, ServiceAccountKey{PublicKey: *k.PublicKey})
+		}
+	}
 	// TODO: set the domain from user
 	e.Domain = IdentityManagerFlags.ServiceAccountDomain
 	return &e
@@ -50,6 +56,17 @@ func serviceAccountEntityToModel(e *ServiceAccount) *v1.ServiceAccount {
 		ModifiedTime: e.ModifiedTime.Unix(),
 	}
 	m.PublicKey = &e.PublicKey
+	for _, k := range serviceAccountKeys(e) {
+		m.Keys = append(m.Keys, &v1.ServiceAccountKey{
+			ID:          k.ID,
+			PublicKey:   swag.String(k.PublicKey),
+			Algorithm:   k.Algorithm,
+			CreatedTime: k.CreatedTime.Unix(),
+		})
+	}
+	if !e.RevokedTime.IsZero() {
+		m.RevokedTime = e.RevokedTime.Unix()
+	}
 	return &m
 }
 
@@ -62,7 +79,14 @@ func (h *Handlers) getServiceAccounts(params serviceAccountOperations.GetService
 	opts := entitystore.Options{
 		Filter: entitystore.FilterExists(),
 	}
-	err := h.store.List(ctx, IdentityManagerFlags.OrgID, opts, &serviceAccounts)
+	if err := utils.ParsePaging(&opts, params.Limit, params.Continue, params.Sort); err != nil {
+		return serviceAccountOperations.NewGetServiceAccountsDefault(http.StatusBadRequest).WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
+	err := h.store.List(ctx, params.XDispatchOrg, opts, &serviceAccounts)
 	if err != nil {
 		log.Errorf("store error when listing service accounts: %+v", err)
 		return serviceAccountOperations.NewGetServiceAccountsInternalServerError().WithPayload(
@@ -75,7 +99,7 @@ func (h *Handlers) getServiceAccounts(params serviceAccountOperations.GetService
 	for _, serviceAccount := range serviceAccounts {
 		serviceAccountModels = append(serviceAccountModels, serviceAccountEntityToModel(serviceAccount))
 	}
-	return serviceAccountOperations.NewGetServiceAccountsOK().WithPayload(serviceAccountModels)
+	return serviceAccountOperations.NewGetServiceAccountsOK().WithPayload(serviceAccountModels).WithXDispatchContinue(utils.NextPage(opts))
 }
 
 func (h *Handlers) getServiceAccount(params serviceAccountOperations.GetServiceAccountParams, principal interface{}) middleware.Responder {
@@ -89,7 +113,7 @@ func (h *Handlers) getServiceAccount(params serviceAccountOperations.GetServiceA
 	}
 
 	name := params.ServiceAccountName
-	if err := h.store.Get(ctx, IdentityManagerFlags.OrgID, name, opts, &serviceAccount); err != nil {
+	if err := h.store.Get(ctx, params.XDispatchOrg, name, opts, &serviceAccount); err != nil {
 		log.Errorf("store error when getting service account '%s': %+v", name, err)
 		return serviceAccountOperations.NewGetServiceAccountNotFound().WithPayload(
 			&v1.Error{
@@ -108,11 +132,11 @@ func (h *Handlers) addServiceAccount(params serviceAccountOperations.AddServiceA
 	defer span.Finish()
 
 	serviceAccountRequest := params.Body
-	e := serviceAccountModelToEntity(serviceAccountRequest)
+	e := serviceAccountModelToEntity(params.XDispatchOrg, serviceAccountRequest)
 
 	e.Status = entitystore.StatusREADY
 
-	if err := validateServiceAccountEntity(e); err != nil {
+	if err := validateServiceAccountEntity(e, nil); err != nil {
 		return serviceAccountOperations.NewAddServiceAccountBadRequest().WithPayload(&v1.Error{
 			Code:    http.StatusBadRequest,
 			Message: swag.String(fmt.Sprintf("error validating service account: %s", err)),
@@ -147,7 +171,7 @@ func (h *Handlers) deleteServiceAccount(params serviceAccountOperations.DeleteSe
 	}
 
 	var e ServiceAccount
-	if err := h.store.Get(ctx, IdentityManagerFlags.OrgID, name, opts, &e); err != nil {
+	if err := h.store.Get(ctx, params.XDispatchOrg, name, opts, &e); err != nil {
 		log.Errorf("store error when getting service account: %+v", err)
 		return serviceAccountOperations.NewDeleteServiceAccountNotFound().WithPayload(
 			&v1.Error{
@@ -185,7 +209,7 @@ func (h *Handlers) updateServiceAccount(params serviceAccountOperations.UpdateSe
 	}
 
 	e := ServiceAccount{}
-	if err := h.store.Get(ctx, IdentityManagerFlags.OrgID, params.ServiceAccountName, opts, &e); err != nil {
+	if err := h.store.Get(ctx, params.XDispatchOrg, params.ServiceAccountName, opts, &e); err != nil {
 		log.Errorf("store error when getting service account: %+v", err)
 		return serviceAccountOperations.NewUpdateServiceAccountNotFound().WithPayload(
 			&v1.Error{
@@ -194,12 +218,13 @@ func (h *Handlers) updateServiceAccount(params serviceAccountOperations.UpdateSe
 			})
 	}
 
-	updateEntity := serviceAccountModelToEntity(params.Body)
+	updateEntity := serviceAccountModelToEntity(params.XDispatchOrg, params.Body)
 	updateEntity.CreatedTime = e.CreatedTime
 	updateEntity.ID = e.ID
 	updateEntity.Status = entitystore.StatusREADY
+	updateEntity.RevokedTime = e.RevokedTime
 
-	if err := validateServiceAccountEntity(updateEntity); err != nil {
+	if err := validateServiceAccountEntity(updateEntity, &e); err != nil {
 		return serviceAccountOperations.NewUpdateServiceAccountBadRequest().WithPayload(&v1.Error{
 			Code:    http.StatusBadRequest,
 			Message: swag.String(fmt.Sprintf("error validating service account: %s", err)),
@@ -217,17 +242,48 @@ func (h *Handlers) updateServiceAccount(params serviceAccountOperations.UpdateSe
 	return serviceAccountOperations.NewUpdateServiceAccountOK().WithPayload(serviceAccountEntityToModel(updateEntity))
 }
 
-func validateServiceAccountEntity(e *ServiceAccount) error {
-	// Validate public key provided by user
-	pubKeyPEM, err := base64.StdEncoding.DecodeString(e.PublicKey)
-	if err != nil {
-		log.Debugf("Error validating service account %s: error %s", e.Name, err)
-		return errors.New("public key is not base64 encoded")
+// validateServiceAccountEntity validates the public keys of a service account, and sets their ID and algorithm. The
+// creation time of the keys already active before an update is kept.
+func validateServiceAccountEntity(e *ServiceAccount, previous *ServiceAccount) error {
+	created := map[string]time.Time{}
+	if previous != nil {
+		for _, k := range serviceAccountKeys(previous) {
+			created[k.ID] = k.CreatedTime
+		}
 	}
-	_, err = jwt.ParseRSAPublicKeyFromPEM(pubKeyPEM)
-	if err != nil {
-		log.Debugf("Error validating service account %s: error %s", e.Name, err)
-		return errors.New("invalid public key or public key not in PEM format")
+	var active []ServiceAccountKey
+	seen := map[string]bool{}
+	for _, k := range e.Keys {
+		// Validate public key provided by user
+		pubKeyPEM, err := base64.StdEncoding.DecodeString(k.PublicKey)
+		if err != nil {
+			log.Debugf("Error validating service account %s: error %s", e.Name, err)
+			return errors.New("public key is not base64 encoded")
+		}
+		publicKey, err := keys.ParsePublicKey(pubKeyPEM)
+		if err != nil {
+			log.Debugf("Error validating service account %s: error %s", e.Name, err)
+			return errors.New("invalid public key or public key not in PEM format")
+		}
+		method, _ := keys.SigningMethod(publicKey)
+		if k.ID, err = keys.ID(publicKey); err != nil {
+			return err
+		}
+		if seen[k.ID] {
+			continue
+		}
+		seen[k.ID] = true
+		k.Algorithm = method.Alg()
+		k.CreatedTime = time.Now()
+		if t, ok := created[k.ID]; ok && !t.IsZero() {
+			k.CreatedTime = t
+		}
+		active = append(active, k)
+	}
+	if len(active) == 0 {
+		return errors.New("missing public key")
 	}
+	e.Keys = active
+	e.JWTAlgorithm = active[0].Algorithm
 	return nil
 }
@@ -168,3 +168,30 @@ func TestUpdateServiceAccountHandlerInvalidKey(t *testing.T) {
 	helpers.HandlerRequest(t, responder, &respBody, http.StatusBadRequest)
 	assert.Equal(t, "error validating service account: invalid public key or public key not in PEM format", *respBody.Message)
 }
+
+func TestServiceAccountHandlersOrganizationIsolation(t *testing.T) {
+	// The test service account is in the default organization
+	api := setupServiceAccountTestAPI(t)
+
+	getParams := serviceaccountOperations.GetServiceAccountParams{
+		HTTPRequest:        httptest.NewRequest("GET", "/v1/iam/serviceaccount/test-serviceaccount-1", nil),
+		ServiceAccountName: "test-serviceaccount-1",
+		XDispatchOrg:       "org-b",
+	}
+	helpers.HandlerRequest(t, api.ServiceaccountGetServiceAccountHandler.Handle(getParams, "testCookie"), &v1.Error{}, http.StatusNotFound)
+
+	var svcAccounts []*v1.ServiceAccount
+	listParams := serviceaccountOperations.GetServiceAccountsParams{
+		HTTPRequest:  httptest.NewRequest("GET", "/v1/iam/serviceaccount", nil),
+		XDispatchOrg: "org-b",
+	}
+	helpers.HandlerRequest(t, api.ServiceaccountGetServiceAccountsHandler.Handle(listParams, "testCookie"), &svcAccounts, http.StatusOK)
+	assert.Empty(t, svcAccounts)
+
+	deleteParams := serviceaccountOperations.DeleteServiceAccountParams{
+		HTTPRequest:        httptest.NewRequest("DELETE", "/v1/iam/serviceaccount/test-serviceaccount-1", nil),
+		ServiceAccountName: "test-serviceaccount-1",
+		XDispatchOrg:       "org-b",
+	}
+	helpers.HandlerRequest(t, api.ServiceaccountDeleteServiceAccountHandler.Handle(deleteParams, "testCookie"), &v1.Error{}, http.StatusNotFound)
+}
@@ -0,0 +1,274 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	middleware "github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/entity-store"
+	setupOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/setup"
+	"github.com/vmware/dispatch/pkg/trace"
+)
+
+const (
+	// setupName is the name of the setup entity
+	setupName = "setup"
+	// setupPolicyName is the name of the policy granting all the permissions to the admins set up
+	setupPolicyName = "default-policy"
+)
+
+// InitSetup prepares the first-run setup when it is not completed yet, and returns the single-use setup token completing
+// it. A new token is generated on every start until the setup is completed, no token is returned afterwards. An identity
+// manager with policies was set up in bootstrap mode, its setup is recorded as completed.
+func (h *Handlers) InitSetup(ctx context.Context) (string, error) {
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	var e Setup
+	exists := h.store.Get(ctx, IdentityManagerFlags.OrgID, setupName, opts, &e) == nil
+	if exists && e.Completed {
+		atomic.StoreInt32(&h.setupCompleted, 1)
+		return "", nil
+	}
+
+	if !exists {
+		e = Setup{
+			BaseEntity: entitystore.BaseEntity{
+				OrganizationID: IdentityManagerFlags.OrgID,
+				Name:           setupName,
+				Status:         entitystore.StatusREADY,
+			},
+		}
+		var policies []*Policy
+		if err := h.store.List(ctx, IdentityManagerFlags.OrgID, opts, &policies); err != nil {
+			return "", errors.Wrap(err, "store error when listing policies")
+		}
+		if len(policies) > 0 {
+			e.Completed = true
+			e.CompletedTime = time.Now()
+			if _, err := h.store.Add(ctx, &e); err != nil {
+				return "", errors.Wrap(err, "store error when recording the setup")
+			}
+			atomic.StoreInt32(&h.setupCompleted, 1)
+			log.Info("Policies found, the setup is recorded as completed")
+			return "", nil
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.Wrap(err, "error generating setup token")
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+	e.TokenHash = hashAPITokenSecret(token)
+	var err error
+	if exists {
+		_, err = h.store.Update(ctx, e.Revision, &e)
+	} else {
+		_, err = h.store.Add(ctx, &e)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "store error when storing the setup token")
+	}
+	if path := IdentityManagerFlags.SetupTokenFile; path != "" {
+		if err := ioutil.WriteFile(path, []byte(token), 0600); err != nil {
+			return "", errors.Wrapf(err, "error writing setup token to %s", path)
+		}
+	}
+	return token, nil
+}
+
+// isSetupCompleted returns whether the first-run setup is completed. Setups are never reverted, a completed setup is
+// not fetched again.
+func (h *Handlers) isSetupCompleted(ctx context.Context) bool {
+	if atomic.LoadInt32(&h.setupCompleted) == 1 {
+		return true
+	}
+	var e Setup
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	if err := h.store.Get(ctx, IdentityManagerFlags.OrgID, setupName, opts, &e); err != nil || !e.Completed {
+		return false
+	}
+	atomic.StoreInt32(&h.setupCompleted, 1)
+	return true
+}
+
+// bootstrapKey reads a key of the bootstrap mode, which is disabled once the first-run setup is completed
+func (h *Handlers) bootstrapKey(ctx context.Context, key string) string {
+	value := getBootstrapKey(key)
+	if value == "" {
+		return ""
+	}
+	if h.isSetupCompleted(ctx) {
+		h.bootstrapWarning.Do(func() {
+			log.Warn("Bootstrap keys are ignored, bootstrap mode is disabled since the setup is completed.")
+		})
+		return ""
+	}
+	h.bootstrapWarning.Do(func() {
+		log.Warn("Bootstrap mode is enabled. Please ensure it is turned off in a production environment.")
+	})
+	return value
+}
+
+// setupEntities returns the entities created by a setup, and its admins
+func setupEntities(m *v1.Setup) ([]entitystore.Entity, []string, error) {
+	if m.User == "" && m.ServiceAccount == "" {
+		return nil, nil, errors.New("a user or a service account is required")
+	}
+
+	var entities []entitystore.Entity
+	var admins []string
+	if m.Organization != "" {
+		org := organizationModelToEntity(&v1.Organization{Name: swag.String(m.Organization)})
+		if m.User != "" {
+			org.Members = []string{m.User}
+		}
+		org.Status = entitystore.StatusCREATING
+		entities = append(entities, org)
+	}
+	if m.User != "" {
+		admins = append(admins, m.User)
+	}
+	if m.ServiceAccount != "" {
+		svcAccount := serviceAccountModelToEntity(IdentityManagerFlags.OrgID, &v1.ServiceAccount{
+			Name:      swag.String(m.ServiceAccount),
+			PublicKey: swag.String(m.PublicKey),
+		})
+		if err := validateServiceAccountEntity(svcAccount, nil); err != nil {
+			return nil, nil, err
+		}
+		svcAccount.Status = entitystore.StatusREADY
+		entities = append(entities, svcAccount)
+		admins = append(admins, m.ServiceAccount)
+	}
+	policy := &Policy{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: IdentityManagerFlags.OrgID,
+			Name:           setupPolicyName,
+			Status:         entitystore.StatusCREATING,
+		},
+		Rules: []Rule{{
+			Subjects:  admins,
+			Resources: []string{"*"},
+			Actions:   []string{"*"},
+		}},
+	}
+	return append(entities, policy), admins, nil
+}
+
+// completeSetup exchanges the single-use setup token for the policy of the first admins, and their service account.
+// The token is consumed first, and restored if the entities cannot be created.
+func (h *Handlers) completeSetup(params setupOperations.CompleteSetupParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	var e Setup
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	if err := h.store.Get(ctx, IdentityManagerFlags.OrgID, setupName, opts, &e); err == nil && e.Completed {
+		return setupOperations.NewCompleteSetupConflict().WithPayload(&v1.Error{
+			Code:    http.StatusConflict,
+			Message: swag.String("setup already completed"),
+		})
+	} else if err != nil || e.TokenHash == "" ||
+		subtle.ConstantTimeCompare([]byte(hashAPITokenSecret(*params.Body.SetupToken)), []byte(e.TokenHash)) != 1 {
+		return setupOperations.NewCompleteSetupUnauthorized().WithPayload(&v1.Error{
+			Code:    http.StatusUnauthorized,
+			Message: swag.String("invalid setup token"),
+		})
+	}
+
+	entities, admins, err := setupEntities(params.Body)
+	if err != nil {
+		return setupOperations.NewCompleteSetupBadRequest().WithPayload(&v1.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String(fmt.Sprintf("error validating setup: %s", err)),
+		})
+	}
+
+	// Consume the token, the revision of the setup guards against concurrent setups
+	tokenHash := e.TokenHash
+	e.TokenHash = ""
+	e.Completed = true
+	e.CompletedTime = time.Now()
+	e.Admins = admins
+	if _, err := h.store.Update(ctx, e.Revision, &e); err != nil {
+		log.Errorf("store error when completing the setup: %+v", err)
+		return setupOperations.NewCompleteSetupInternalServerError().WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when completing the setup"),
+		})
+	}
+
+	var added []entitystore.Entity
+	for _, entity := range entities {
+		if _, err = h.store.Add(ctx, entity); err != nil {
+			if _, ok := entity.(*Organization); ok && entitystore.IsUniqueViolation(err) {
+				err = nil
+				continue
+			}
+			break
+		}
+		added = append(added, entity)
+	}
+	if err != nil {
+		for _, entity := range added {
+			if err := h.store.Delete(ctx, entity.GetOrganizationID(), entity.GetName(), entity); err != nil {
+				log.Errorf("store error when deleting %s created by the setup: %+v", entity.GetName(), err)
+			}
+		}
+		e.TokenHash = tokenHash
+		e.Completed = false
+		e.CompletedTime = time.Time{}
+		e.Admins = nil
+		if _, err := h.store.Update(ctx, e.Revision, &e); err != nil {
+			log.Errorf("store error when restoring the setup token: %+v", err)
+		}
+		if entitystore.IsUniqueViolation(err) {
+			return setupOperations.NewCompleteSetupConflict().WithPayload(&v1.Error{
+				Code:    http.StatusConflict,
+				Message: swag.String("error completing setup: non-unique service account or policy name"),
+			})
+		}
+		log.Errorf("store error when completing the setup: %+v", err)
+		return setupOperations.NewCompleteSetupInternalServerError().WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when completing the setup"),
+		})
+	}
+
+	for _, entity := range added {
+		if entity.GetStatus() == entitystore.StatusCREATING {
+			h.watcher.OnAction(ctx, entity)
+		}
+	}
+	atomic.StoreInt32(&h.setupCompleted, 1)
+	log.Infof("Setup completed, admins: %v", admins)
+	return setupOperations.NewCompleteSetupOK().WithPayload(&v1.Setup{
+		Completed:      true,
+		Organization:   params.Body.Organization,
+		ServiceAccount: params.Body.ServiceAccount,
+		User:           params.Body.User,
+	})
+}
@@ -0,0 +1,164 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations"
+	setupOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/setup"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+func setupSetupTestAPI(t *testing.T) (*operations.IdentityManagerAPI, *Handlers, entitystore.EntityStore) {
+	api := operations.NewIdentityManagerAPI(nil)
+	es := helpers.MakeEntityStore(t)
+	handlers := NewHandlers(nil, es, SetupEnforcer(es))
+	helpers.MakeAPI(t, handlers.ConfigureHandlers, api)
+	return api, handlers, es
+}
+
+func completeTestSetup(t *testing.T, api *operations.IdentityManagerAPI, m *v1.Setup, status int) {
+	params := setupOperations.CompleteSetupParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/iam/setup", nil),
+		Body:        m,
+	}
+	if status == http.StatusOK {
+		var respBody v1.Setup
+		helpers.HandlerRequest(t, api.SetupCompleteSetupHandler.Handle(params), &respBody, status)
+		assert.True(t, respBody.Completed)
+		return
+	}
+	var respBody v1.Error
+	helpers.HandlerRequest(t, api.SetupCompleteSetupHandler.Handle(params), &respBody, status)
+}
+
+func testSetupPublicKey() string {
+	pubKey, _ := ioutil.ReadFile("testdata/test_key.pub")
+	return base64.StdEncoding.EncodeToString(pubKey)
+}
+
+func TestInitSetup(t *testing.T) {
+	api, h, _ := setupSetupTestAPI(t)
+
+	token, err := h.InitSetup(context.Background())
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.False(t, h.isSetupCompleted(context.Background()))
+
+	// A new token is generated on every start, the previous one is no longer valid
+	newToken, err := h.InitSetup(context.Background())
+	require.NoError(t, err)
+	assert.NotEqual(t, token, newToken)
+	completeTestSetup(t, api, &v1.Setup{SetupToken: swag.String(token), User: "admin@example.com"}, http.StatusUnauthorized)
+	completeTestSetup(t, api, &v1.Setup{SetupToken: swag.String(newToken), User: "admin@example.com"}, http.StatusOK)
+
+	token, err = h.InitSetup(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, token)
+}
+
+func TestInitSetupBootstrapped(t *testing.T) {
+	es := helpers.MakeEntityStore(t)
+	addTestData(es)
+	h := NewHandlers(nil, es, SetupEnforcer(es))
+
+	// An identity manager with policies was set up in bootstrap mode
+	token, err := h.InitSetup(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, token)
+	assert.True(t, h.isSetupCompleted(context.Background()))
+}
+
+func TestCompleteSetup(t *testing.T) {
+	api, h, es := setupSetupTestAPI(t)
+
+	token, err := h.InitSetup(context.Background())
+	require.NoError(t, err)
+
+	completeTestSetup(t, api, &v1.Setup{SetupToken: swag.String("invalid"), User: "admin@example.com"}, http.StatusUnauthorized)
+	completeTestSetup(t, api, &v1.Setup{SetupToken: swag.String(token)}, http.StatusBadRequest)
+	completeTestSetup(t, api, &v1.Setup{SetupToken: swag.String(token), ServiceAccount: "admin-svc", PublicKey: "invalid"}, http.StatusBadRequest)
+
+	completeTestSetup(t, api, &v1.Setup{
+		SetupToken:     swag.String(token),
+		User:           "admin@example.com",
+		ServiceAccount: "admin-svc",
+		PublicKey:      testSetupPublicKey(),
+		Organization:   "admin-org",
+	}, http.StatusOK)
+
+	opts := entitystore.Options{Filter: entitystore.FilterExists()}
+	var policy Policy
+	require.NoError(t, es.Get(context.Background(), IdentityManagerFlags.OrgID, setupPolicyName, opts, &policy))
+	assert.Equal(t, []string{"admin@example.com", "admin-svc"}, policy.Rules[0].Subjects)
+	var svcAccount ServiceAccount
+	assert.NoError(t, es.Get(context.Background(), IdentityManagerFlags.OrgID, "admin-svc", opts, &svcAccount))
+	var org Organization
+	require.NoError(t, es.Get(context.Background(), IdentityManagerFlags.OrgID, "admin-org", opts, &org))
+	assert.Equal(t, []string{"admin@example.com"}, org.Members)
+
+	// The token is single-use
+	completeTestSetup(t, api, &v1.Setup{SetupToken: swag.String(token), User: "admin@example.com"}, http.StatusConflict)
+}
+
+func TestCompleteSetupRestoresToken(t *testing.T) {
+	api, h, es := setupSetupTestAPI(t)
+
+	token, err := h.InitSetup(context.Background())
+	require.NoError(t, err)
+	es.Add(context.Background(), &ServiceAccount{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: IdentityManagerFlags.OrgID,
+			Name:           "admin-svc",
+		},
+	})
+
+	completeTestSetup(t, api, &v1.Setup{
+		SetupToken:     swag.String(token),
+		User:           "admin@example.com",
+		ServiceAccount: "admin-svc",
+		PublicKey:      testSetupPublicKey(),
+	}, http.StatusConflict)
+	assert.False(t, h.isSetupCompleted(context.Background()))
+
+	// The entities created are removed, and the token can be used again
+	var policies []*Policy
+	require.NoError(t, es.List(context.Background(), IdentityManagerFlags.OrgID, entitystore.Options{}, &policies))
+	assert.Empty(t, policies)
+	completeTestSetup(t, api, &v1.Setup{SetupToken: swag.String(token), User: "admin@example.com"}, http.StatusOK)
+}
+
+func TestBootstrapModeDisabledBySetup(t *testing.T) {
+	api, h, _ := setupSetupTestAPI(t)
+
+	IdentityManagerFlags.BootstrapConfigPath = "testdata"
+	defer func() { IdentityManagerFlags.BootstrapConfigPath = "/bootstrap" }()
+
+	token, err := h.InitSetup(context.Background())
+	require.NoError(t, err)
+	_, err = h.authenticateBearer("bearer " + createTestJWT("bootstrap-user@example.com"))
+	assert.NoError(t, err)
+
+	completeTestSetup(t, api, &v1.Setup{SetupToken: swag.String(token), User: "admin@example.com"}, http.StatusOK)
+	assert.Empty(t, h.bootstrapKey(context.Background(), "bootstrap_user"))
+	_, err = h.authenticateBearer("bearer " + createTestJWT("bootstrap-user@example.com"))
+	assert.Error(t, err)
+
+	params := newOrgAuthParams("", "/v1/iam/policy", "GET")
+	helpers.HandlerRequest(t, api.AuthHandler.Handle(params, &Principal{Subject: "bootstrap-user@example.com"}), nil, http.StatusForbidden)
+}
@@ -0,0 +1,239 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io/ioutil"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/identity-manager/keys"
+)
+
+// AccessTokenIssuer is the issuer of the access tokens issued to service accounts. It is not a valid service account
+// name, the service account is the subject of the access tokens.
+const AccessTokenIssuer = "dispatch:identity-manager"
+
+// JWT claims
+const (
+	JWTClaimID       = "jti"
+	JWTClaimIssuer   = "iss"
+	JWTClaimSubject  = "sub"
+	JWTClaimAudience = "aud"
+	JWTClaimIssuedAt = "iat"
+	JWTClaimExpires  = "exp"
+)
+
+// clockSkew is the difference tolerated between the clocks of clients and the identity manager
+const clockSkew = time.Minute
+
+// TokenIssuer signs the access tokens issued to service accounts
+type TokenIssuer struct {
+	key    crypto.Signer
+	method jwt.SigningMethod
+	keyID  string
+}
+
+// NewTokenIssuer creates a token issuer signing with the PEM private key of a file. When no file is given, a P-256
+// ECDSA key is generated: the tokens issued are then only valid until the identity manager restarts, and only with
+// the replica which issued them.
+func NewTokenIssuer(path string) (*TokenIssuer, error) {
+	var key crypto.Signer
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading token signing key %s", path)
+		}
+		if key, err = keys.ParsePrivateKey(data); err != nil {
+			return nil, errors.Wrapf(err, "error parsing token signing key %s", path)
+		}
+	} else {
+		log.Warn("No token signing key set, generating one. The access tokens issued are lost on restart.")
+		generated, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, errors.Wrap(err, "error generating token signing key")
+		}
+		key = generated
+	}
+	method, err := keys.SigningMethod(key)
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := keys.ID(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &TokenIssuer{key: key, method: method, keyID: keyID}, nil
+}
+
+// Issue signs an access token issued to a service account, and returns the token and its ID
+func (i *TokenIssuer) Issue(orgID, svcAccount string, now time.Time, lifetime time.Duration) (string, string, error) {
+	id := uuid.NewV4().String()
+	claims := jwt.MapClaims{
+		JWTClaimID:       id,
+		JWTClaimIssuer:   AccessTokenIssuer,
+		JWTClaimSubject:  svcAccount,
+		JWTClaimOrg:      orgID,
+		JWTClaimIssuedAt: now.Unix(),
+		JWTClaimExpires:  now.Add(lifetime).Unix(),
+	}
+	if IdentityManagerFlags.TokenAudience != "" {
+		claims[JWTClaimAudience] = IdentityManagerFlags.TokenAudience
+	}
+	token := jwt.NewWithClaims(i.method, claims)
+	token.Header["kid"] = i.keyID
+	signed, err := token.SignedString(i.key)
+	if err != nil {
+		return "", "", errors.Wrap(err, "error signing access token")
+	}
+	return signed, id, nil
+}
+
+// verificationKey returns the key verifying the access tokens signed by the issuer
+func (i *TokenIssuer) verificationKey(token *jwt.Token) (interface{}, error) {
+	if token.Method.Alg() != i.method.Alg() {
+		return nil, errors.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	if kid, ok := token.Header["kid"].(string); ok && kid != i.keyID {
+		return nil, errors.Errorf("unknown signing key %s", kid)
+	}
+	return i.key.Public(), nil
+}
+
+// SetTokenIssuer sets the issuer of the access tokens of service accounts
+func (h *Handlers) SetTokenIssuer(issuer *TokenIssuer) {
+	h.tokens = issuer
+}
+
+// serviceAccountKeys returns the active public keys of a service account. The service accounts created before keys
+// were stored only have a public key.
+func serviceAccountKeys(e *ServiceAccount) []ServiceAccountKey {
+	if len(e.Keys) > 0 {
+		return e.Keys
+	}
+	key := ServiceAccountKey{PublicKey: e.PublicKey, Algorithm: e.JWTAlgorithm, CreatedTime: e.CreatedTime}
+	if publicKey, err := keys.ParseEncodedPublicKey(e.PublicKey); err == nil {
+		key.ID, _ = keys.ID(publicKey)
+	}
+	return []ServiceAccountKey{key}
+}
+
+// serviceAccountKey returns the public key of a service account verifying a token, selected by the kid header of the
+// token. Tokens without kid header are verified with the public key of the service account.
+func serviceAccountKey(e *ServiceAccount, token *jwt.Token) (crypto.PublicKey, error) {
+	kid, _ := token.Header["kid"].(string)
+	for _, k := range serviceAccountKeys(e) {
+		if kid != "" && k.ID != kid {
+			continue
+		}
+		return keys.ParseEncodedPublicKey(k.PublicKey)
+	}
+	return nil, errors.Errorf("unknown key %s of service account %s", kid, e.Name)
+}
+
+// hasAudience returns whether the aud claim of a token, either a string or an array, includes an audience
+func hasAudience(claims jwt.MapClaims, audience string) bool {
+	switch aud := claims[JWTClaimAudience].(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// claimTime returns the time of a numeric date claim
+func claimTime(claims jwt.MapClaims, claim string) (time.Time, bool) {
+	switch v := claims[claim].(type) {
+	case float64:
+		return time.Unix(int64(v), 0), true
+	case int64:
+		return time.Unix(v, 0), true
+	}
+	return time.Time{}, false
+}
+
+// validateTokenPolicy enforces the expiry, lifetime and audience of tokens, verified beforehand
+func validateTokenPolicy(claims jwt.MapClaims, now time.Time) error {
+	expires, ok := claimTime(claims, JWTClaimExpires)
+	if !ok {
+		return errors.New("missing expiry claim")
+	}
+	if claims[JWTClaimIssuer] != AccessTokenIssuer && IdentityManagerFlags.AssertionMaxLifetime > 0 {
+		// The lifetime of the access tokens issued is enforced when issuing them
+		issued, ok := claimTime(claims, JWTClaimIssuedAt)
+		if !ok || issued.After(now) {
+			issued = now
+		}
+		maxLifetime := time.Duration(IdentityManagerFlags.AssertionMaxLifetime) * time.Second
+		if expires.Sub(issued) > maxLifetime+clockSkew {
+			return errors.Errorf("token lifetime exceeds the maximum of %s", maxLifetime)
+		}
+	}
+	if _, ok := claims[JWTClaimAudience]; ok && IdentityManagerFlags.TokenAudience != "" {
+		if !hasAudience(claims, IdentityManagerFlags.TokenAudience) {
+			return errors.Errorf("token not issued to audience %s", IdentityManagerFlags.TokenAudience)
+		}
+	}
+	return nil
+}
+
+// isRevoked returns whether a token is in the revocation list of its organization
+func (h *Handlers) isRevoked(ctx context.Context, claims jwt.MapClaims) (bool, error) {
+	id, ok := claims[JWTClaimID].(string)
+	if !ok || id == "" {
+		return false, nil
+	}
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	return h.store.Find(ctx, tokenOrganization(claims), id, opts, &RevokedToken{})
+}
+
+// tokenSubject returns the service account authenticated by a token, the issuer of the tokens signed by service
+// accounts, and the subject of the access tokens issued to them
+func tokenSubject(claims jwt.MapClaims) string {
+	if claims[JWTClaimIssuer] == AccessTokenIssuer {
+		subject, _ := claims[JWTClaimSubject].(string)
+		return subject
+	}
+	issuer, _ := claims[JWTClaimIssuer].(string)
+	return issuer
+}
+
+// pruneRevokedTokens deletes the revoked tokens of an organization which expired
+func (h *Handlers) pruneRevokedTokens(ctx context.Context, orgID string, now time.Time) error {
+	var revoked []*RevokedToken
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	if err := h.store.List(ctx, orgID, opts, &revoked); err != nil {
+		return errors.Wrap(err, "store error when listing revoked tokens")
+	}
+	for _, r := range revoked {
+		if r.ExpiresTime.IsZero() || r.ExpiresTime.After(now) {
+			continue
+		}
+		if err := h.store.Delete(ctx, orgID, r.Name, r); err != nil {
+			return errors.Wrapf(err, "store error when deleting revoked token %s", r.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	middleware "github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/swag"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/entity-store"
+	tokenOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/token"
+	"github.com/vmware/dispatch/pkg/trace"
+)
+
+// issueToken exchanges a token signed by a service account, the assertion, for an access token signed by the
+// identity manager
+func (h *Handlers) issueToken(params tokenOperations.IssueTokenParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	if h.tokens == nil {
+		return tokenOperations.NewIssueTokenInternalServerError().WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("access tokens are not issued by this identity manager"),
+		})
+	}
+	if params.Body.Lifetime < 0 {
+		return tokenOperations.NewIssueTokenBadRequest().WithPayload(&v1.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String("invalid token lifetime: must be positive"),
+		})
+	}
+
+	claims, err := h.parseAndValidateToken(*params.Body.Assertion)
+	if err == nil && claims[JWTClaimIssuer] == AccessTokenIssuer {
+		err = fmt.Errorf("access tokens cannot be exchanged")
+	}
+	if err == nil && claims[JWTClaimIssuer] == h.bootstrapKey(ctx, "bootstrap_user") {
+		err = fmt.Errorf("access tokens are not issued in bootstrap mode")
+	}
+	if err == nil {
+		var revoked bool
+		if revoked, err = h.isRevoked(ctx, claims); err == nil && revoked {
+			err = fmt.Errorf("token %s has been revoked", claims[JWTClaimID])
+		}
+	}
+	if err != nil {
+		log.Debugf("Error validating assertion: %s", err)
+		return tokenOperations.NewIssueTokenUnauthorized().WithPayload(&v1.Error{
+			Code:    http.StatusUnauthorized,
+			Message: swag.String(fmt.Sprintf("invalid assertion: %s", err)),
+		})
+	}
+
+	lifetime := time.Duration(params.Body.Lifetime) * time.Second
+	if lifetime == 0 {
+		lifetime = time.Duration(IdentityManagerFlags.TokenLifetime) * time.Second
+	}
+	if maxLifetime := time.Duration(IdentityManagerFlags.TokenMaxLifetime) * time.Second; maxLifetime > 0 && lifetime > maxLifetime {
+		lifetime = maxLifetime
+	}
+
+	orgID, svcAccount := tokenOrganization(claims), tokenSubject(claims)
+	token, id, err := h.tokens.Issue(orgID, svcAccount, time.Now(), lifetime)
+	if err != nil {
+		log.Errorf("error issuing access token to service account %s: %+v", svcAccount, err)
+		return tokenOperations.NewIssueTokenInternalServerError().WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when issuing access token"),
+		})
+	}
+	log.Infof("access token %s issued to service account %s of organization %s", id, svcAccount, orgID)
+	return tokenOperations.NewIssueTokenOK().WithPayload(&v1.AccessToken{
+		AccessToken: swag.String(token),
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(lifetime / time.Second),
+		ID:          id,
+	})
+}
+
+// revokeToken adds a token to the revocation list of the organization, or revokes all the tokens issued to a service
+// account until now
+func (h *Handlers) revokeToken(params tokenOperations.RevokeTokenParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	revocation := params.Body
+	if revocation.ID == "" && revocation.ServiceAccount == "" {
+		return tokenOperations.NewRevokeTokenBadRequest().WithPayload(&v1.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String("either a token ID or a service account is required"),
+		})
+	}
+
+	now := time.Now()
+	if revocation.ServiceAccount != "" {
+		opts := entitystore.Options{
+			Filter: entitystore.FilterExists(),
+		}
+		var e ServiceAccount
+		if err := h.store.Get(ctx, params.XDispatchOrg, revocation.ServiceAccount, opts, &e); err != nil {
+			log.Errorf("store error when getting service account '%s': %+v", revocation.ServiceAccount, err)
+			return tokenOperations.NewRevokeTokenNotFound().WithPayload(&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String("service account not found"),
+			})
+		}
+		e.RevokedTime = now
+		if _, err := h.store.Update(ctx, e.Revision, &e); err != nil {
+			log.Errorf("store error when revoking the tokens of service account %s: %+v", e.Name, err)
+			return tokenOperations.NewRevokeTokenInternalServerError().WithPayload(&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String("internal server error when revoking tokens"),
+			})
+		}
+		log.Infof("tokens of service account %s issued before %s revoked", e.Name, now)
+	}
+
+	if revocation.ID != "" {
+		// The revoked token is kept until it expires, its expiry is not known but bounded by the maximum lifetimes
+		retention := IdentityManagerFlags.TokenMaxLifetime
+		if IdentityManagerFlags.AssertionMaxLifetime > retention {
+			retention = IdentityManagerFlags.AssertionMaxLifetime
+		}
+		e := &RevokedToken{
+			BaseEntity: entitystore.BaseEntity{
+				OrganizationID: params.XDispatchOrg,
+				Name:           revocation.ID,
+				Status:         entitystore.StatusREADY,
+			},
+			ServiceAccount: revocation.ServiceAccount,
+		}
+		if IdentityManagerFlags.AssertionMaxLifetime > 0 {
+			e.ExpiresTime = now.Add(time.Duration(retention)*time.Second + clockSkew)
+		}
+		if _, err := h.store.Add(ctx, e); err != nil && !entitystore.IsUniqueViolation(err) {
+			log.Errorf("store error when revoking token %s: %+v", revocation.ID, err)
+			return tokenOperations.NewRevokeTokenInternalServerError().WithPayload(&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String("internal server error when revoking token"),
+			})
+		}
+		log.Infof("token %s revoked", revocation.ID)
+	}
+
+	if err := h.pruneRevokedTokens(ctx, params.XDispatchOrg, now); err != nil {
+		log.Warnf("error pruning the revoked tokens: %s", err)
+	}
+	return tokenOperations.NewRevokeTokenOK().WithPayload(revocation)
+}
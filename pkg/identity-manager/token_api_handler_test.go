@@ -0,0 +1,267 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package identitymanager
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations"
+	serviceaccountOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/serviceaccount"
+	tokenOperations "github.com/vmware/dispatch/pkg/identity-manager/gen/restapi/operations/token"
+	"github.com/vmware/dispatch/pkg/identity-manager/keys"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+func setupTokenTestAPI(t *testing.T) (*operations.IdentityManagerAPI, *Handlers) {
+	IdentityManagerFlags.TokenLifetime = 900
+	IdentityManagerFlags.TokenMaxLifetime = 3600
+	IdentityManagerFlags.AssertionMaxLifetime = 3600
+	IdentityManagerFlags.TokenAudience = "dispatch"
+	t.Cleanup(func() {
+		IdentityManagerFlags.TokenLifetime = 0
+		IdentityManagerFlags.TokenMaxLifetime = 0
+		IdentityManagerFlags.AssertionMaxLifetime = 0
+		IdentityManagerFlags.TokenAudience = ""
+	})
+
+	api := operations.NewIdentityManagerAPI(nil)
+	es := helpers.MakeEntityStore(t)
+	handlers := NewHandlers(nil, es, SetupEnforcer(es))
+	issuer, err := NewTokenIssuer("")
+	require.NoError(t, err)
+	handlers.SetTokenIssuer(issuer)
+	helpers.MakeAPI(t, handlers.ConfigureHandlers, api)
+	return api, handlers
+}
+
+func encodedPublicKey(t *testing.T, key crypto.Signer) string {
+	pubPEM, err := keys.EncodePublicKey(key.Public())
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(pubPEM)
+}
+
+func signAssertion(t *testing.T, key crypto.Signer, claims jwt.MapClaims) string {
+	method, err := keys.SigningMethod(key)
+	require.NoError(t, err)
+	kid, err := keys.ID(key.Public())
+	require.NoError(t, err)
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func assertionClaims(issuer string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss": issuer,
+		"jti": issuer + "-assertion",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func addTokenTestServiceAccount(t *testing.T, api *operations.IdentityManagerAPI, name string, publicKeys ...string) *v1.ServiceAccount {
+	model := newServiceAccountModel(name, publicKeys[0])
+	for _, k := range publicKeys[1:] {
+		model.Keys = append(model.Keys, &v1.ServiceAccountKey{PublicKey: swag.String(k)})
+	}
+	params := serviceaccountOperations.AddServiceAccountParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/iam/serviceaccount", nil),
+		Body:        model,
+	}
+	var svcAccount v1.ServiceAccount
+	helpers.HandlerRequest(t, api.ServiceaccountAddServiceAccountHandler.Handle(params, "testCookie"), &svcAccount, http.StatusCreated)
+	return &svcAccount
+}
+
+func issueToken(t *testing.T, api *operations.IdentityManagerAPI, assertion string, lifetime int64, status int) *v1.AccessToken {
+	params := tokenOperations.IssueTokenParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/iam/token", nil),
+		Body:        &v1.TokenRequest{Assertion: swag.String(assertion), Lifetime: lifetime},
+	}
+	var token v1.AccessToken
+	helpers.HandlerRequest(t, api.TokenIssueTokenHandler.Handle(params), &token, status)
+	return &token
+}
+
+func revokeToken(t *testing.T, api *operations.IdentityManagerAPI, revocation *v1.TokenRevocation, status int) {
+	params := tokenOperations.RevokeTokenParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/iam/revocation", nil),
+		Body:        revocation,
+	}
+	var respBody v1.TokenRevocation
+	helpers.HandlerRequest(t, api.TokenRevokeTokenHandler.Handle(params, "testCookie"), &respBody, status)
+}
+
+func TestIssueToken(t *testing.T) {
+	api, h := setupTokenTestAPI(t)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	svcAccount := addTokenTestServiceAccount(t, api, "ci", encodedPublicKey(t, key))
+	require.Len(t, svcAccount.Keys, 1)
+	assert.Equal(t, "ES256", svcAccount.Keys[0].Algorithm)
+	assert.NotEmpty(t, svcAccount.Keys[0].ID)
+
+	token := issueToken(t, api, signAssertion(t, key, assertionClaims("ci")), 0, http.StatusOK)
+	assert.Equal(t, "Bearer", token.TokenType)
+	assert.Equal(t, int64(900), token.ExpiresIn)
+	assert.NotEmpty(t, token.ID)
+
+	// The service account is the subject of the access token
+	principal, err := h.authenticateBearer("Bearer " + *token.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "ci", principal.(*Principal).Subject)
+	claims := jwt.MapClaims{}
+	_, _, err = new(jwt.Parser).ParseUnverified(*token.AccessToken, claims)
+	require.NoError(t, err)
+	assert.Equal(t, AccessTokenIssuer, claims["iss"])
+	assert.Equal(t, "dispatch", claims["aud"])
+
+	// The lifetime requested is bounded by the maximum lifetime
+	token = issueToken(t, api, signAssertion(t, key, assertionClaims("ci")), 24*3600, http.StatusOK)
+	assert.Equal(t, int64(3600), token.ExpiresIn)
+
+	// Access tokens cannot be exchanged for new ones
+	issueToken(t, api, *token.AccessToken, 0, http.StatusUnauthorized)
+
+	// Access tokens of deleted service accounts are rejected
+	params := serviceaccountOperations.DeleteServiceAccountParams{
+		HTTPRequest:        httptest.NewRequest("DELETE", "/v1/iam/serviceaccount/ci", nil),
+		ServiceAccountName: "ci",
+	}
+	var deleted v1.ServiceAccount
+	helpers.HandlerRequest(t, api.ServiceaccountDeleteServiceAccountHandler.Handle(params, "testCookie"), &deleted, http.StatusOK)
+	_, err = h.authenticateBearer("Bearer " + *token.AccessToken)
+	assert.Error(t, err)
+}
+
+func TestIssueTokenInvalidAssertion(t *testing.T) {
+	api, _ := setupTokenTestAPI(t)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	addTokenTestServiceAccount(t, api, "ci", encodedPublicKey(t, key))
+
+	// Without expiry
+	claims := assertionClaims("ci")
+	delete(claims, "exp")
+	issueToken(t, api, signAssertion(t, key, claims), 0, http.StatusUnauthorized)
+
+	// Exceeding the maximum lifetime
+	claims = assertionClaims("ci")
+	claims["exp"] = time.Now().Add(24 * time.Hour).Unix()
+	issueToken(t, api, signAssertion(t, key, claims), 0, http.StatusUnauthorized)
+
+	// For another audience
+	claims = assertionClaims("ci")
+	claims["aud"] = "other-service"
+	issueToken(t, api, signAssertion(t, key, claims), 0, http.StatusUnauthorized)
+	claims["aud"] = []string{"other-service", "dispatch"}
+	issueToken(t, api, signAssertion(t, key, claims), 0, http.StatusOK)
+
+	// Signed with another key
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	issueToken(t, api, signAssertion(t, otherKey, assertionClaims("ci")), 0, http.StatusUnauthorized)
+
+	// With an algorithm not matching the key
+	token := jwt.NewWithClaims(jwt.SigningMethodES384, assertionClaims("ci"))
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	signed, err := token.SignedString(p384Key)
+	require.NoError(t, err)
+	issueToken(t, api, signed, 0, http.StatusUnauthorized)
+}
+
+func TestRevokeToken(t *testing.T) {
+	api, h := setupTokenTestAPI(t)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	addTokenTestServiceAccount(t, api, "ci", encodedPublicKey(t, key))
+
+	first := issueToken(t, api, signAssertion(t, key, assertionClaims("ci")), 0, http.StatusOK)
+	second := issueToken(t, api, signAssertion(t, key, assertionClaims("ci")), 0, http.StatusOK)
+
+	// Revoking a token
+	revokeToken(t, api, &v1.TokenRevocation{ID: first.ID}, http.StatusOK)
+	_, err = h.authenticateBearer("Bearer " + *first.AccessToken)
+	assert.Error(t, err)
+	_, err = h.authenticateBearer("Bearer " + *second.AccessToken)
+	assert.NoError(t, err)
+
+	// Revoking a signed assertion prevents exchanging it
+	assertion := signAssertion(t, key, assertionClaims("ci"))
+	revokeToken(t, api, &v1.TokenRevocation{ID: "ci-assertion"}, http.StatusOK)
+	issueToken(t, api, assertion, 0, http.StatusUnauthorized)
+
+	// Revoking all the tokens of the service account
+	claims := assertionClaims("ci")
+	claims["jti"] = "other-assertion"
+	claims["iat"] = time.Now().Add(-time.Minute).Unix()
+	assertion = signAssertion(t, key, claims)
+	revokeToken(t, api, &v1.TokenRevocation{ServiceAccount: "ci"}, http.StatusOK)
+	_, err = h.authenticateBearer("Bearer " + *second.AccessToken)
+	assert.Error(t, err)
+	_, err = h.authenticateBearer("Bearer " + assertion)
+	assert.Error(t, err)
+
+	revokeToken(t, api, &v1.TokenRevocation{}, http.StatusBadRequest)
+	revokeToken(t, api, &v1.TokenRevocation{ServiceAccount: "unknown"}, http.StatusNotFound)
+}
+
+func TestServiceAccountKeyRotation(t *testing.T) {
+	api, h := setupTokenTestAPI(t)
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, newKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	svcAccount := addTokenTestServiceAccount(t, api, "ci", encodedPublicKey(t, oldKey))
+
+	// Both keys are active while rotating
+	svcAccount.Keys = append(svcAccount.Keys, &v1.ServiceAccountKey{PublicKey: swag.String(encodedPublicKey(t, newKey))})
+	params := serviceaccountOperations.UpdateServiceAccountParams{
+		HTTPRequest:        httptest.NewRequest("PUT", "/v1/iam/serviceaccount/ci", nil),
+		ServiceAccountName: "ci",
+		Body:               svcAccount,
+	}
+	var updated v1.ServiceAccount
+	helpers.HandlerRequest(t, api.ServiceaccountUpdateServiceAccountHandler.Handle(params, "testCookie"), &updated, http.StatusOK)
+	require.Len(t, updated.Keys, 2)
+	assert.Equal(t, svcAccount.Keys[0].ID, updated.Keys[0].ID)
+	assert.Equal(t, svcAccount.Keys[0].CreatedTime, updated.Keys[0].CreatedTime)
+	assert.Equal(t, "EdDSA", updated.Keys[1].Algorithm)
+
+	principal, err := h.authenticateBearer("Bearer " + signAssertion(t, oldKey, assertionClaims("ci")))
+	require.NoError(t, err)
+	assert.Equal(t, "ci", principal.(*Principal).Subject)
+	principal, err = h.authenticateBearer("Bearer " + signAssertion(t, newKey, assertionClaims("ci")))
+	require.NoError(t, err)
+	assert.Equal(t, "ci", principal.(*Principal).Subject)
+
+	// The old key is removed once clients sign with the new key
+	params.Body = &v1.ServiceAccount{Name: swag.String("ci"), PublicKey: updated.Keys[1].PublicKey}
+	helpers.HandlerRequest(t, api.ServiceaccountUpdateServiceAccountHandler.Handle(params, "testCookie"), &updated, http.StatusOK)
+	require.Len(t, updated.Keys, 1)
+	_, err = h.authenticateBearer("Bearer " + signAssertion(t, oldKey, assertionClaims("ci")))
+	assert.Error(t, err)
+	_, err = h.authenticateBearer("Bearer " + signAssertion(t, newKey, assertionClaims("ci")))
+	assert.NoError(t, err)
+}
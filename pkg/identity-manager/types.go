@@ -10,7 +10,17 @@ package identitymanager
 type attributesRecord struct {
 	subject           string
 	resource          string
+	name              string
+	application       string
 	path              string
 	action            Action
 	isResourceRequest bool
 }
+
+// object returns the object of a request in policies, the resource or the resource instance, e.g. function/billing-charge
+func (r *attributesRecord) object() string {
+	if r.name == "" {
+		return r.resource
+	}
+	return r.resource + "/" + r.name
+}
@@ -21,6 +21,8 @@ import (
 // ControllerConfig defines the image manager controller configuration
 type ControllerConfig struct {
 	ResyncPeriod time.Duration
+	// Ownership decides which replica reconciles the images, nil with a single replica
+	Ownership controller.Ownership
 }
 
 type baseImageEntityHandler struct {
@@ -168,6 +170,8 @@ func NewController(config *ControllerConfig, store entitystore.EntityStore, base
 	c := controller.NewController(controller.Options{
 		ResyncPeriod: config.ResyncPeriod,
 		Workers:      10, // want more functions concurrently? add more workers // TODO configure workers
+		Store:        store,
+		Ownership:    config.Ownership,
 	})
 
 	c.AddEntityHandler(&baseImageEntityHandler{Store: store, Builder: baseImageBuilder})
@@ -269,6 +269,17 @@ func (h *Handlers) getBaseImages(params baseimage.GetBaseImagesParams, principal
 	opts := entitystore.Options{
 		Filter: entitystore.FilterExists(),
 	}
+	opts.Filter, err = utils.ParseTags(opts.Filter, params.Tags)
+	if err == nil {
+		err = utils.ParsePaging(&opts, params.Limit, params.Continue, params.Sort)
+	}
+	if err != nil {
+		return baseimage.NewGetBaseImagesDefault(http.StatusBadRequest).WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
 	err = h.Store.List(ctx, params.XDispatchOrg, opts, &images)
 	if err != nil {
 		log.Errorf("store error when listing base images: %+v", err)
@@ -282,7 +293,7 @@ func (h *Handlers) getBaseImages(params baseimage.GetBaseImagesParams, principal
 	for _, i := range images {
 		imageModels = append(imageModels, baseImageEntityToModel(i))
 	}
-	return baseimage.NewGetBaseImagesOK().WithPayload(imageModels)
+	return baseimage.NewGetBaseImagesOK().WithPayload(imageModels).WithXDispatchContinue(utils.NextPage(opts))
 }
 
 func (h *Handlers) updateBaseImageByName(params baseimage.UpdateBaseImageByNameParams, principal interface{}) middleware.Responder {
@@ -432,6 +443,9 @@ func (h *Handlers) getImages(params image.GetImagesParams, principal interface{}
 		Filter: entitystore.FilterExists(),
 	}
 	opts.Filter, err = utils.ParseTags(opts.Filter, params.Tags)
+	if err == nil {
+		err = utils.ParsePaging(&opts, params.Limit, params.Continue, params.Sort)
+	}
 	if err != nil {
 		log.Errorf(err.Error())
 		return image.NewGetImagesBadRequest().WithPayload(
@@ -455,7 +469,7 @@ func (h *Handlers) getImages(params image.GetImagesParams, principal interface{}
 		imageModels = append(imageModels, imageEntityToModel(i))
 	}
 
-	return image.NewGetImagesOK().WithPayload(imageModels)
+	return image.NewGetImagesOK().WithPayload(imageModels).WithXDispatchContinue(utils.NextPage(opts))
 }
 
 func (h *Handlers) updateImageByName(params image.UpdateImageByNameParams, principal interface{}) middleware.Responder {
@@ -369,3 +369,53 @@ func TestImageDeleteImagesByNameHandler(t *testing.T) {
 	helpers.HandlerRequest(t, getResponder, &getBody, 200)
 	assert.Len(t, getBody, 0)
 }
+
+func TestImageOrganizationIsolation(t *testing.T) {
+	api := operations.NewImageManagerAPI(nil)
+	es := helpers.MakeEntityStore(t)
+	h := NewHandlers(nil, nil, nil, es)
+	helpers.MakeAPI(t, h.ConfigureHandlers, api)
+
+	add := baseimage.AddBaseImageParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/baseimage", nil),
+		Body: &v1.BaseImage{
+			Name:      swag.String("testBaseImage"),
+			DockerURL: swag.String("test/base"),
+			Language:  swag.String("python3"),
+		},
+		XDispatchOrg: "org-a",
+	}
+	addResponder := api.BaseImageAddBaseImageHandler.Handle(add, "testCookie")
+	var addBody v1.BaseImage
+	helpers.HandlerRequest(t, addResponder, &addBody, 201)
+
+	get := baseimage.GetBaseImageByNameParams{
+		HTTPRequest:   httptest.NewRequest("GET", "/v1/baseimage/testBaseImage", nil),
+		BaseImageName: "testBaseImage",
+		XDispatchOrg:  "org-b",
+	}
+	getResponder := api.BaseImageGetBaseImageByNameHandler.Handle(get, "testCookie")
+	var errorBody v1.Error
+	helpers.HandlerRequest(t, getResponder, &errorBody, 404)
+
+	list := baseimage.GetBaseImagesParams{
+		HTTPRequest:  httptest.NewRequest("GET", "/v1/baseimage", nil),
+		XDispatchOrg: "org-b",
+	}
+	listResponder := api.BaseImageGetBaseImagesHandler.Handle(list, "testCookie")
+	var listBody []v1.BaseImage
+	helpers.HandlerRequest(t, listResponder, &listBody, 200)
+	assert.Len(t, listBody, 0)
+
+	// An image cannot be built from another organization's base image
+	addImage := image.AddImageParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/image", nil),
+		Body: &v1.Image{
+			Name:          swag.String("testImage"),
+			BaseImageName: swag.String("testBaseImage"),
+		},
+		XDispatchOrg: "org-b",
+	}
+	addImageResponder := api.ImageAddImageHandler.Handle(addImage, "testCookie")
+	helpers.HandlerRequest(t, addImageResponder, &errorBody, 400)
+}
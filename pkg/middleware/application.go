@@ -0,0 +1,127 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/casbin/casbin/util"
+	"github.com/go-openapi/swag"
+	"github.com/justinas/alice"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+)
+
+// HeaderApplications lists the applications a request is restricted to, e.g. "billing,payroll-*". The identity manager
+// sets it when only rules scoped to applications allow a request whose application it can't tell: creating or listing
+// resources.
+const HeaderApplications = "X-Dispatch-Applications"
+
+// applicationTag is the tag holding the application of entities
+const applicationTag = "Application"
+
+// applicationTermRegexp matches a tags filter selecting the entities of a single application, e.g. Application=billing
+var applicationTermRegexp = regexp.MustCompile(`^\s*(?i:application|app)\s*==?\s*([^\s|,()]+)\s*$`)
+
+// ApplicationScope is a middleware restricting requests to the applications of the X-Dispatch-Applications header: the
+// resources created must be tagged with one of them, and the resources listed filtered by one of them.
+type ApplicationScope struct {
+	next http.Handler
+}
+
+// NewApplicationScopeMW creates a new application scope middleware
+func NewApplicationScopeMW() alice.Constructor {
+	return func(next http.Handler) http.Handler {
+		return &ApplicationScope{next: next}
+	}
+}
+
+// ServeHTTP is the middleware interface implementation
+func (a *ApplicationScope) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	header := r.Header.Get(HeaderApplications)
+	if header == "" {
+		a.next.ServeHTTP(rw, r)
+		return
+	}
+
+	var application string
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		application = queryApplication(r.URL.Query()["tags"])
+	case http.MethodPost:
+		application, err = bodyApplication(r)
+	}
+	if err == nil && !inApplications(application, strings.Split(header, ",")) {
+		err = fmt.Errorf("the request is restricted to the resources of applications %s", header)
+		if r.Method == http.MethodGet {
+			err = fmt.Errorf("the request is restricted to the resources of applications %s, filter them with tags=%s=<application>", header, applicationTag)
+		}
+	}
+	if err != nil {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(rw).Encode(&v1.Error{
+			Code:    http.StatusForbidden,
+			Message: swag.String(err.Error()),
+		})
+		return
+	}
+	a.next.ServeHTTP(rw, r)
+}
+
+// queryApplication returns the application filtering a listing, if any. Alternatives don't restrict the listing, nor
+// do filters on several applications.
+func queryApplication(tags []string) string {
+	for _, tag := range tags {
+		if m := applicationTermRegexp.FindStringSubmatch(tag); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// bodyApplication returns the application tag of the resource created by a request. The body is read again by the
+// handler.
+func bodyApplication(r *http.Request) (string, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("error reading the request body: %s", err)
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var resource struct {
+		Tags []*v1.Tag `json:"tags"`
+	}
+	// Bodies which aren't resources have no application, they are out of scope
+	json.Unmarshal(body, &resource)
+	for _, tag := range resource.Tags {
+		if tag != nil && tag.Key == applicationTag {
+			return tag.Value, nil
+		}
+	}
+	return "", nil
+}
+
+// inApplications returns whether an application matches one of the applications, which may be patterns, e.g. payroll-*
+func inApplications(application string, applications []string) bool {
+	if application == "" {
+		return false
+	}
+	for _, pattern := range applications {
+		if util.KeyMatch(application, strings.TrimSpace(pattern)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package middleware
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplicationScope(t *testing.T) {
+	var body string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := NewApplicationScopeMW()(next)
+
+	serve := func(method, url, body, applications string) int {
+		r := httptest.NewRequest(method, url, strings.NewReader(body))
+		if applications != "" {
+			r.Header.Set(HeaderApplications, applications)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// Requests without restriction pass
+	assert.Equal(t, http.StatusOK, serve("DELETE", "/v1/function/hello", "", ""))
+
+	// Resources are created in the applications only, the handler still reads the body
+	created := `{"name":"hello","tags":[{"key":"Application","value":"billing"}]}`
+	assert.Equal(t, http.StatusOK, serve("POST", "/v1/function", created, "billing"))
+	assert.Equal(t, created, body)
+	assert.Equal(t, http.StatusOK, serve("POST", "/v1/function", created, "payroll,billing*"))
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/v1/function", created, "payroll"))
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/v1/function", `{"name":"hello"}`, "billing"))
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/v1/function", `not json`, "billing"))
+
+	// and listed filtered by an application
+	assert.Equal(t, http.StatusOK, serve("GET", "/v1/function?tags=Application=billing", "", "billing"))
+	assert.Equal(t, http.StatusOK, serve("GET", "/v1/function?tags=tier=web&tags=app==billing", "", "billing"))
+	assert.Equal(t, http.StatusForbidden, serve("GET", "/v1/function", "", "billing"))
+	assert.Equal(t, http.StatusForbidden, serve("GET", "/v1/function?tags=Application=payroll", "", "billing"))
+	assert.Equal(t, http.StatusForbidden, serve("GET", "/v1/function?tags=Application=billing|tier=web", "", "billing"))
+	assert.Equal(t, http.StatusForbidden, serve("GET", "/v1/function?tags=Application+in+(billing,payroll)", "", "billing"))
+
+	// Other requests name their resource, the identity manager resolves its application
+	assert.Equal(t, http.StatusForbidden, serve("DELETE", "/v1/function/hello", "", "billing"))
+}
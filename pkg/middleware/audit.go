@@ -0,0 +1,119 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/justinas/alice"
+	"github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/audit"
+	"github.com/vmware/dispatch/pkg/client"
+)
+
+const headerOrg = "X-Dispatch-Org"
+
+// Auditor is a middleware that records the calls creating, updating or deleting resources
+type Auditor struct {
+	service string
+	sink    audit.Sink
+	next    http.Handler
+}
+
+// NewAuditMW creates a new audit middleware, writing the records of the service to the sink
+func NewAuditMW(service string, sink audit.Sink) alice.Constructor {
+	if sink == nil {
+		// Disable audit middleware when the audit log is disabled
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+	return func(next http.Handler) http.Handler {
+		return NewAuditor(service, sink, next)
+	}
+}
+
+// NewAuditor creates a new audit middleware
+func NewAuditor(service string, sink audit.Sink, next http.Handler) *Auditor {
+	return &Auditor{
+		service: service,
+		sink:    sink,
+		next:    next,
+	}
+}
+
+// auditAction returns the action of a mutating request, or "" for other requests
+func auditAction(method string) string {
+	switch method {
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut, http.MethodPatch:
+		return "update"
+	case http.MethodDelete:
+		return "delete"
+	}
+	return ""
+}
+
+// ServeHTTP is the middleware interface implementation
+func (a *Auditor) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	action := auditAction(r.Method)
+	if action == "" {
+		a.next.ServeHTTP(rw, r)
+		return
+	}
+
+	requestID := r.Header.Get(audit.HeaderRequestID)
+	if requestID == "" {
+		requestID = uuid.NewV4().String()
+		r.Header.Set(audit.HeaderRequestID, requestID)
+	}
+	record := &audit.Record{
+		Service:      a.service,
+		Principal:    strings.Join(client.ParseRequesters(r.Header.Get(client.HeaderRequester)), ","),
+		Organization: r.Header.Get(headerOrg),
+		Action:       action,
+		RequestID:    requestID,
+	}
+	// Resource paths are /{version}/{resource}[/{name}], e.g. /v1/function/hello
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) > 1 {
+		record.Resource = parts[1]
+	}
+	if len(parts) > 2 {
+		record.Name = parts[2]
+	}
+	if record.Resource == "iam" && len(parts) > 2 {
+		// IAM paths are /v1/iam/{resource}[/{name}], e.g. /v1/iam/policy/admins
+		record.Resource = parts[2]
+		record.Name = ""
+		if len(parts) > 3 {
+			record.Name = parts[3]
+		}
+	}
+	if record.Principal == "" {
+		record.Principal = "anonymous"
+	}
+
+	tracker := &statusCodeTracker{rw, http.StatusOK}
+	ctx := audit.WithRecord(r.Context(), record)
+	a.next.ServeHTTP(tracker, r.WithContext(ctx))
+
+	record = audit.FromContext(ctx)
+	record.Time = time.Now()
+	record.Status = tracker.status
+	record.Outcome = audit.OutcomeSucceeded
+	if tracker.status >= http.StatusBadRequest {
+		record.Outcome = audit.OutcomeFailed
+	}
+	if err := a.sink.Write(ctx, record); err != nil {
+		log.Errorf("error writing the audit record of %s %s %s/%s: %+v", record.Principal, action, record.Resource, record.Name, err)
+	}
+}
@@ -0,0 +1,78 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/audit"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+type recordingSink struct {
+	records []*audit.Record
+}
+
+func (s *recordingSink) Write(ctx context.Context, record *audit.Record) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func TestAuditor(t *testing.T) {
+	es := audit.NewEntityStore(helpers.MakeEntityStore(t))
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			_, err := es.Add(r.Context(), &entitystore.BaseEntity{OrganizationID: "dispatch", Name: "hello"})
+			require.NoError(t, err)
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	sink := &recordingSink{}
+	handler := NewAuditMW("function-manager", sink)(next)
+
+	r := httptest.NewRequest("POST", "/v1/function", nil)
+	r.Header.Set("X-Dispatch-Org", "dispatch")
+	r.Header.Set("X-Dispatch-Requester", "user/jane@example.com")
+	r.Header.Set(audit.HeaderRequestID, "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	r = httptest.NewRequest("DELETE", "/v1/iam/policy/admins", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	// Reads are not recorded
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/function/hello", nil))
+
+	require.Len(t, sink.records, 2)
+	created := sink.records[0]
+	assert.Equal(t, "function-manager", created.Service)
+	assert.Equal(t, "user/jane@example.com", created.Principal)
+	assert.Equal(t, "dispatch", created.Organization)
+	assert.Equal(t, "function", created.Resource)
+	assert.Equal(t, "hello", created.Name)
+	assert.Equal(t, "create", created.Action)
+	assert.Equal(t, audit.OutcomeSucceeded, created.Outcome)
+	assert.Equal(t, http.StatusCreated, created.Status)
+	assert.Equal(t, "req-1", created.RequestID)
+	assert.NotZero(t, created.AfterRevision)
+
+	deleted := sink.records[1]
+	assert.Equal(t, "anonymous", deleted.Principal)
+	assert.Equal(t, "policy", deleted.Resource)
+	assert.Equal(t, "admins", deleted.Name)
+	assert.Equal(t, "delete", deleted.Action)
+	assert.Equal(t, audit.OutcomeFailed, deleted.Outcome)
+	assert.NotEmpty(t, deleted.RequestID)
+}
@@ -22,10 +22,14 @@ import (
 // HealthChecker is executed to verify health of the service.
 type HealthChecker func() error
 
+// HealthStatus reports the state of the service, served with the healthcheck information
+type HealthStatus func() interface{}
+
 // HealthCheck is a middleware that serves healthcheck information
 type HealthCheck struct {
 	basePath string
 	checker  HealthChecker
+	status   HealthStatus
 	next     http.Handler
 }
 
@@ -36,6 +40,15 @@ func NewHealthCheckMW(basePath string, checker HealthChecker) alice.Constructor
 	}
 }
 
+// NewHealthStatusMW creates a new health check middleware at the specified path, serving the state of the service
+func NewHealthStatusMW(basePath string, checker HealthChecker, status HealthStatus) alice.Constructor {
+	return func(next http.Handler) http.Handler {
+		h := NewHealthCheck(basePath, checker, next)
+		h.status = status
+		return h
+	}
+}
+
 // NewHealthCheck creates a new health check middleware at the specified path
 func NewHealthCheck(basePath string, checker HealthChecker, next http.Handler) *HealthCheck {
 	if basePath == "" {
@@ -51,6 +64,7 @@ func NewHealthCheck(basePath string, checker HealthChecker, next http.Handler) *
 
 type statusInfo struct {
 	Version *v1.Version `json:"version"`
+	Status  interface{} `json:"status,omitempty"`
 }
 
 // ServeHTTP is the middleware interface implementation
@@ -67,7 +81,11 @@ func (h *HealthCheck) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 
 	var bs []byte
 	if err == nil {
-		bs, err = json.Marshal(&statusInfo{Version: version.Get()})
+		info := &statusInfo{Version: version.Get()}
+		if h.status != nil {
+			info.Status = h.status()
+		}
+		bs, err = json.Marshal(info)
 	}
 
 	if err != nil {
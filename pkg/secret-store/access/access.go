@@ -0,0 +1,67 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package access
+
+import (
+	"path"
+	"strings"
+
+	"github.com/go-openapi/swag"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+)
+
+// Allowed returns whether one of the requesters may read the values of the secret. Requesters identify principals by
+// kind and name, e.g. function/hello (see client.Requester).
+//
+// Secrets without access rules may be read by any principal of the organization. The rotation function of a secret
+// may always read it, as it needs the current values to produce the new ones.
+func Allowed(secret *v1.Secret, requesters []string) bool {
+	if secret.Access == nil {
+		return true
+	}
+	for _, requester := range requesters {
+		parts := strings.SplitN(requester, "/", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			continue
+		}
+		kind, name := parts[0], parts[1]
+		if kind == client.RequesterFunction && secret.Rotation != nil && swag.StringValue(secret.Rotation.Function) == name {
+			return true
+		}
+		if matchAny(patterns(secret.Access, kind), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// patterns returns the names of the principals of a kind allowed by the access rules
+func patterns(access *v1.SecretAccess, kind string) []string {
+	switch kind {
+	case client.RequesterFunction:
+		return access.Functions
+	case client.RequesterSubscription:
+		return access.Subscriptions
+	case client.RequesterDriver:
+		return access.Drivers
+	case client.RequesterServiceAccount:
+		return access.ServiceAccounts
+	case client.RequesterUser:
+		return access.Users
+	}
+	return nil
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
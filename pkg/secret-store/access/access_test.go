@@ -0,0 +1,37 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package access
+
+import (
+	"testing"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+)
+
+func TestAllowed(t *testing.T) {
+	secret := &v1.Secret{Name: swag.String("psql-creds")}
+	assert.True(t, Allowed(secret, nil), "secrets without access rules can be read by anyone")
+
+	secret.Access = &v1.SecretAccess{
+		Functions:     []string{"billing-*"},
+		Subscriptions: []string{"on-order"},
+		Users:         []string{"admin@example.com"},
+	}
+	secret.Rotation = &v1.SecretRotation{Function: swag.String("rotate-psql")}
+
+	assert.False(t, Allowed(secret, nil))
+	assert.True(t, Allowed(secret, []string{"function/billing-report"}))
+	assert.False(t, Allowed(secret, []string{"function/shipping"}))
+	assert.True(t, Allowed(secret, []string{"subscription/on-order", "function/shipping"}))
+	assert.False(t, Allowed(secret, []string{"subscription/billing-report"}), "rules are per kind of principal")
+	assert.True(t, Allowed(secret, []string{"user/admin@example.com"}))
+	assert.False(t, Allowed(secret, []string{"serviceaccount/admin@example.com"}))
+	assert.True(t, Allowed(secret, []string{"function/rotate-psql"}), "the rotation function can read the secret")
+	assert.False(t, Allowed(secret, []string{"billing-report", "function/"}))
+}
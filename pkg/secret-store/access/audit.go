@@ -0,0 +1,146 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package access
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	secretstore "github.com/vmware/dispatch/pkg/secret-store"
+	"github.com/vmware/dispatch/pkg/trace"
+)
+
+// Auditor records the reads of secret values in the entity store, and prunes the records older than the retention
+type Auditor struct {
+	store     entitystore.EntityStore
+	retention time.Duration
+	period    time.Duration
+	done      chan bool
+}
+
+// NewAuditor creates a new auditor, pruning every period the records older than the retention (0 keeps all of them)
+func NewAuditor(store entitystore.EntityStore, retention time.Duration, period time.Duration) *Auditor {
+	return &Auditor{
+		store:     store,
+		retention: retention,
+		period:    period,
+		done:      make(chan bool),
+	}
+}
+
+// Start starts pruning the records
+func (a *Auditor) Start() {
+	go a.run()
+}
+
+// Shutdown stops pruning the records
+func (a *Auditor) Shutdown() {
+	a.done <- true
+}
+
+func (a *Auditor) run() {
+	ticker := time.NewTicker(a.period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if a.retention <= 0 {
+				continue
+			}
+			if err := a.Prune(context.Background(), time.Now().Add(-a.retention)); err != nil {
+				log.Errorf("error pruning the secret audit: %+v", err)
+			}
+		case <-a.done:
+			return
+		}
+	}
+}
+
+// Record records a read of the values of a secret, whether they were returned or not
+func (a *Auditor) Record(ctx context.Context, organizationID string, secret *v1.Secret, requesters []string, purpose string, allowed bool) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	entity := &secretstore.SecretAuditEntity{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: organizationID,
+			Name:           uuid.NewV4().String(),
+		},
+		Secret:    *secret.Name,
+		Version:   secret.Version,
+		Requester: strings.Join(requesters, ","),
+		Purpose:   purpose,
+		Allowed:   allowed,
+	}
+	log.Infof("secret %s version %d read by %q for %q, allowed: %t", entity.Secret, entity.Version, entity.Requester, entity.Purpose, allowed)
+	_, err := a.store.Add(ctx, entity)
+	return err
+}
+
+// List lists the reads of the values of a secret, oldest first
+func (a *Auditor) List(ctx context.Context, organizationID string, name string) ([]*v1.SecretAudit, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	var entities []*secretstore.SecretAuditEntity
+	opts := entitystore.Options{
+		Filter: entitystore.FilterEverything().Add(entitystore.FilterStat{
+			Scope:   entitystore.FilterScopeExtra,
+			Subject: "Secret",
+			Verb:    entitystore.FilterVerbEqual,
+			Object:  name,
+		}),
+	}
+	if err := a.store.List(ctx, organizationID, opts, &entities); err != nil {
+		return nil, err
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].CreatedTime.Before(entities[j].CreatedTime)
+	})
+	reads := []*v1.SecretAudit{}
+	for _, entity := range entities {
+		reads = append(reads, entity.ToModel())
+	}
+	return reads, nil
+}
+
+// Prune deletes the records created before the given time
+func (a *Auditor) Prune(ctx context.Context, before time.Time) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	orgIDs, err := a.store.ListOrgIDs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, orgID := range orgIDs {
+		var entities []*secretstore.SecretAuditEntity
+		opts := entitystore.Options{
+			Filter: entitystore.FilterEverything().Add(entitystore.FilterStat{
+				Scope:   entitystore.FilterScopeField,
+				Subject: "CreatedTime",
+				Verb:    entitystore.FilterVerbBefore,
+				Object:  before,
+			}),
+		}
+		if err := a.store.List(ctx, orgID, opts, &entities); err != nil {
+			return err
+		}
+		for _, entity := range entities {
+			if err := a.store.Delete(ctx, orgID, entity.Name, entity); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
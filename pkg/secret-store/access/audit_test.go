@@ -0,0 +1,51 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package access
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+func TestAudit(t *testing.T) {
+	a := NewAuditor(helpers.MakeEntityStore(t), time.Hour, time.Minute)
+	ctx := context.Background()
+
+	psql := &v1.Secret{Name: swag.String("psql-creds"), Version: 2}
+	require.NoError(t, a.Record(ctx, "dispatch", psql, []string{"subscription/on-order", "function/billing"}, "run 42", true))
+	require.NoError(t, a.Record(ctx, "dispatch", psql, []string{"user/joe@example.com"}, "", false))
+	require.NoError(t, a.Record(ctx, "dispatch", &v1.Secret{Name: swag.String("api-key")}, nil, "", true))
+
+	reads, err := a.List(ctx, "dispatch", "psql-creds")
+	require.NoError(t, err)
+	require.Len(t, reads, 2)
+	assert.Equal(t, "psql-creds", reads[0].Secret)
+	assert.Equal(t, int64(2), reads[0].Version)
+	assert.Equal(t, "subscription/on-order,function/billing", reads[0].Requester)
+	assert.Equal(t, "run 42", reads[0].Purpose)
+	assert.True(t, reads[0].Allowed)
+	assert.NotZero(t, reads[0].ReadTime)
+	assert.Equal(t, "user/joe@example.com", reads[1].Requester)
+	assert.False(t, reads[1].Allowed)
+
+	require.NoError(t, a.Prune(ctx, time.Now().Add(-time.Hour)))
+	reads, err = a.List(ctx, "dispatch", "psql-creds")
+	require.NoError(t, err)
+	assert.Len(t, reads, 2)
+
+	require.NoError(t, a.Prune(ctx, time.Now().Add(time.Second)))
+	reads, err = a.List(ctx, "dispatch", "psql-creds")
+	require.NoError(t, err)
+	assert.Empty(t, reads)
+}
@@ -9,6 +9,7 @@ package builder
 
 import (
 	"github.com/go-openapi/strfmt"
+	"github.com/pkg/errors"
 	k8sv1 "k8s.io/api/core/v1"
 
 	dispatchv1 "github.com/vmware/dispatch/pkg/api/v1"
@@ -20,20 +21,30 @@ import (
 type DispatchSecretBuilder struct {
 	k8sSecret k8sv1.Secret
 	entity    secretstore.SecretEntity
+	cipher    Cipher
 }
 
-// NewDispatchSecretBuilder creates a new DispatchSecretBuilder
-func NewDispatchSecretBuilder(entity secretstore.SecretEntity, k8sSecret k8sv1.Secret) *DispatchSecretBuilder {
+// NewDispatchSecretBuilder creates a new DispatchSecretBuilder, decrypting the values with cipher (nil if the values
+// are not encrypted)
+func NewDispatchSecretBuilder(entity secretstore.SecretEntity, k8sSecret k8sv1.Secret, cipher Cipher) *DispatchSecretBuilder {
 	return &DispatchSecretBuilder{
 		k8sSecret: k8sSecret,
 		entity:    entity,
+		cipher:    cipher,
 	}
 }
 
 // Build converts a DispatchSecretBuilder to a swagger model Secret
-func (builder *DispatchSecretBuilder) Build() dispatchv1.Secret {
+func (builder *DispatchSecretBuilder) Build() (dispatchv1.Secret, error) {
 	secretValue := dispatchv1.SecretValue{}
 	for k, v := range builder.k8sSecret.Data {
+		if builder.cipher != nil {
+			plaintext, err := builder.cipher.Decrypt(v)
+			if err != nil {
+				return dispatchv1.Secret{}, errors.Wrapf(err, "error decrypting value %s of secret %s", k, builder.entity.Name)
+			}
+			v = plaintext
+		}
 		secretValue[k] = string(v)
 	}
 	tags := []*dispatchv1.Tag{}
@@ -45,7 +56,10 @@ func (builder *DispatchSecretBuilder) Build() dispatchv1.Secret {
 		Name: &builder.entity.Name,
 		Kind: utils.SecretKind,
 		// Name:    &builder.k8sSecret.Name,
-		Secrets: secretValue,
-		Tags:    tags,
-	}
+		Secrets:  secretValue,
+		Tags:     tags,
+		Version:  builder.entity.CurrentVersion(),
+		Rotation: builder.entity.RotationToModel(),
+		Access:   builder.entity.AccessToModel(),
+	}, nil
 }
@@ -8,30 +8,46 @@
 package builder
 
 import (
+	"github.com/pkg/errors"
 	k8sv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	dispatchv1 "github.com/vmware/dispatch/pkg/api/v1"
 )
 
+// Cipher encrypts and decrypts the secret values stored in kubernetes
+type Cipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(value []byte) ([]byte, error)
+}
+
 // K8sSecretBuilder type
 type K8sSecretBuilder struct {
 	Secret dispatchv1.Secret
+	cipher Cipher
 }
 
-// NewK8sSecretBuilder creates a new K8sSecretBuilder
-func NewK8sSecretBuilder(secret dispatchv1.Secret) *K8sSecretBuilder {
+// NewK8sSecretBuilder creates a new K8sSecretBuilder, encrypting the values with cipher (nil to store them as is)
+func NewK8sSecretBuilder(secret dispatchv1.Secret, cipher Cipher) *K8sSecretBuilder {
 	return &K8sSecretBuilder{
 		Secret: secret,
+		cipher: cipher,
 	}
 }
 
 // Build converts a K8sSecretBuilder to a k8s secret
-func (builder *K8sSecretBuilder) Build() k8sv1.Secret {
+func (builder *K8sSecretBuilder) Build() (k8sv1.Secret, error) {
 
 	data := make(map[string][]byte)
 	for k, v := range builder.Secret.Secrets {
 		data[k] = []byte(v)
+		if builder.cipher != nil {
+			encrypted, err := builder.cipher.Encrypt(data[k])
+			if err != nil {
+				return k8sv1.Secret{}, errors.Wrapf(err, "error encrypting value %s of secret %s", k, *builder.Secret.Name)
+			}
+			data[k] = encrypted
+		}
 	}
 
 	return k8sv1.Secret{
@@ -39,5 +55,5 @@ func (builder *K8sSecretBuilder) Build() k8sv1.Secret {
 			Name: *builder.Secret.Name,
 		},
 		Data: data,
-	}
+	}, nil
 }
@@ -0,0 +1,109 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// data keys are AES-256 keys
+	dataKeySize = 32
+	// encrypted values are stored as dispatch:enc:<data key generation>:<base64 nonce and ciphertext>
+	valuePrefix = "dispatch:enc:"
+)
+
+// Cipher encrypts and decrypts the secret values of an organization with its data keys
+type Cipher struct {
+	current int
+	aeads   map[int]cipher.AEAD
+}
+
+func newCipher(current int, keys map[int][]byte) (*Cipher, error) {
+	c := &Cipher{
+		current: current,
+		aeads:   make(map[int]cipher.AEAD),
+	}
+	for generation, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid data key %d", generation)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		c.aeads[generation] = aead
+	}
+	if _, ok := c.aeads[current]; !ok {
+		return nil, errors.Errorf("missing current data key %d", current)
+	}
+	return c, nil
+}
+
+// generation returns the generation of the data key a value is encrypted with, 0 for values not encrypted
+func generation(value []byte) (int, []byte) {
+	if !bytes.HasPrefix(value, []byte(valuePrefix)) {
+		return 0, nil
+	}
+	rest := value[len(valuePrefix):]
+	i := bytes.IndexByte(rest, ':')
+	if i < 0 {
+		return 0, nil
+	}
+	generation, err := strconv.Atoi(string(rest[:i]))
+	if err != nil || generation < 1 {
+		return 0, nil
+	}
+	return generation, rest[i+1:]
+}
+
+// Encrypt encrypts a value with the current data key
+func (c *Cipher) Encrypt(plaintext []byte) ([]byte, error) {
+	aead := c.aeads[c.current]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "error generating nonce")
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+	return []byte(fmt.Sprintf("%s%d:%s", valuePrefix, c.current, base64.StdEncoding.EncodeToString(sealed))), nil
+}
+
+// Decrypt decrypts a value encrypted with any of the data keys. Values stored before encryption was enabled are
+// returned as is.
+func (c *Cipher) Decrypt(value []byte) ([]byte, error) {
+	generation, encoded := generation(value)
+	if generation == 0 {
+		return value, nil
+	}
+	aead, ok := c.aeads[generation]
+	if !ok {
+		return nil, errors.Errorf("data key %d not found", generation)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(encoded))
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, errors.New("invalid encrypted value")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error decrypting value with data key %d", generation)
+	}
+	return plaintext, nil
+}
+
+// Current returns whether a value is encrypted with the current data key
+func (c *Cipher) Current(value []byte) bool {
+	generation, _ := generation(value)
+	return generation == c.current
+}
@@ -0,0 +1,52 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package encryption
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCipher(t *testing.T) {
+	first := bytes.Repeat([]byte{1}, dataKeySize)
+	second := bytes.Repeat([]byte{2}, dataKeySize)
+
+	c1, err := newCipher(1, map[int][]byte{1: first})
+	require.NoError(t, err)
+	encrypted, err := c1.Encrypt([]byte("password"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(encrypted), "password")
+	assert.True(t, c1.Current(encrypted))
+
+	c2, err := newCipher(2, map[int][]byte{1: first, 2: second})
+	require.NoError(t, err)
+	assert.False(t, c2.Current(encrypted))
+	plaintext, err := c2.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "password", string(plaintext))
+
+	// values stored before encryption was enabled
+	plaintext, err = c2.Decrypt([]byte("legacy"))
+	require.NoError(t, err)
+	assert.Equal(t, "legacy", string(plaintext))
+	assert.False(t, c2.Current([]byte("legacy")))
+
+	// once the first key is retired
+	c3, err := newCipher(2, map[int][]byte{2: second})
+	require.NoError(t, err)
+	_, err = c3.Decrypt(encrypted)
+	assert.Error(t, err)
+
+	encrypted[len(encrypted)-2] ^= 1
+	_, err = c1.Decrypt(encrypted)
+	assert.Error(t, err)
+
+	_, err = newCipher(2, map[int][]byte{1: first})
+	assert.Error(t, err)
+}
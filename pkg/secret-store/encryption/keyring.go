@@ -0,0 +1,189 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	secretstore "github.com/vmware/dispatch/pkg/secret-store"
+	"github.com/vmware/dispatch/pkg/secret-store/kms"
+	"github.com/vmware/dispatch/pkg/trace"
+)
+
+// the data keys of an organization are stored in a single entity
+const dataKeyName = "data-key"
+
+type cachedCipher struct {
+	revision uint64
+	cipher   *Cipher
+}
+
+// Keyring manages the data keys of the organizations (envelope encryption): secret values are encrypted with a data
+// key per organization, and the data keys are encrypted by the KMS.
+type Keyring struct {
+	store entitystore.EntityStore
+	kms   kms.KMS
+
+	sync.Mutex
+	// decrypted data keys by organization, to avoid a KMS request per secret
+	ciphers map[string]cachedCipher
+}
+
+// NewKeyring creates a new keyring, storing the data keys in store encrypted by kms
+func NewKeyring(store entitystore.EntityStore, kms kms.KMS) *Keyring {
+	return &Keyring{
+		store:   store,
+		kms:     kms,
+		ciphers: make(map[string]cachedCipher),
+	}
+}
+
+func newDataKey() ([]byte, error) {
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, errors.Wrap(err, "error generating data key")
+	}
+	return key, nil
+}
+
+func (k *Keyring) find(ctx context.Context, organizationID string) (*secretstore.DataKeyEntity, bool, error) {
+	entity := &secretstore.DataKeyEntity{}
+	ok, err := k.store.Find(ctx, organizationID, dataKeyName, entitystore.Options{}, entity)
+	return entity, ok, err
+}
+
+func (k *Keyring) create(ctx context.Context, organizationID string) error {
+	key, err := newDataKey()
+	if err != nil {
+		return err
+	}
+	encrypted, err := k.kms.Encrypt(ctx, key)
+	if err != nil {
+		return err
+	}
+	entity := &secretstore.DataKeyEntity{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: organizationID,
+			Name:           dataKeyName,
+		},
+		Current: 1,
+		Keys:    map[int][]byte{1: encrypted},
+		Rotated: time.Now(),
+	}
+	_, err = k.store.Add(ctx, entity)
+	return err
+}
+
+// Cipher returns the cipher of an organization, creating its data key on first use
+func (k *Keyring) Cipher(ctx context.Context, organizationID string) (*Cipher, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	entity, ok, err := k.find(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// another replica may have created the data key concurrently, so the creation error is not final
+		if err := k.create(ctx, organizationID); err != nil {
+			log.Debugf("error creating the data key of organization %s: %s", organizationID, err)
+		}
+		if entity, ok, err = k.find(ctx, organizationID); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, errors.Errorf("error creating the data key of organization %s", organizationID)
+		}
+	}
+
+	k.Lock()
+	cached, ok := k.ciphers[organizationID]
+	k.Unlock()
+	if ok && cached.revision == entity.Revision {
+		return cached.cipher, nil
+	}
+
+	keys := make(map[int][]byte)
+	for generation, encrypted := range entity.Keys {
+		key, err := k.kms.Decrypt(ctx, encrypted)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error decrypting data key %d of organization %s", generation, organizationID)
+		}
+		keys[generation] = key
+	}
+	cipher, err := newCipher(entity.Current, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	k.Lock()
+	k.ciphers[organizationID] = cachedCipher{revision: entity.Revision, cipher: cipher}
+	k.Unlock()
+	return cipher, nil
+}
+
+// Rotate creates a new data key for an organization, encrypting the values from now on. The previous data keys
+// still decrypt the values until they are retired, and are encrypted again by the KMS, picking up its current
+// master key.
+func (k *Keyring) Rotate(ctx context.Context, organizationID string) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	entity, ok, err := k.find(ctx, organizationID)
+	if err != nil || !ok {
+		return err
+	}
+	keys := make(map[int][]byte)
+	for generation, encrypted := range entity.Keys {
+		key, err := k.kms.Decrypt(ctx, encrypted)
+		if err != nil {
+			return errors.Wrapf(err, "error decrypting data key %d of organization %s", generation, organizationID)
+		}
+		if keys[generation], err = k.kms.Encrypt(ctx, key); err != nil {
+			return err
+		}
+		if generation > entity.Current {
+			entity.Current = generation
+		}
+	}
+	key, err := newDataKey()
+	if err != nil {
+		return err
+	}
+	entity.Current++
+	if keys[entity.Current], err = k.kms.Encrypt(ctx, key); err != nil {
+		return err
+	}
+	entity.Keys = keys
+	entity.Rotated = time.Now()
+	if _, err := k.store.Update(ctx, entity.Revision, entity); err != nil {
+		return errors.Wrapf(err, "error storing the data keys of organization %s", organizationID)
+	}
+	log.Infof("data key of organization %s rotated to generation %d", organizationID, entity.Current)
+	return nil
+}
+
+// Retire deletes the data keys of an organization other than the current one, once no value is encrypted with them
+func (k *Keyring) Retire(ctx context.Context, organizationID string) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	entity, ok, err := k.find(ctx, organizationID)
+	if err != nil || !ok || len(entity.Keys) < 2 {
+		return err
+	}
+	entity.Keys = map[int][]byte{entity.Current: entity.Keys[entity.Current]}
+	if _, err := k.store.Update(ctx, entity.Revision, entity); err != nil {
+		return errors.Wrapf(err, "error storing the data keys of organization %s", organizationID)
+	}
+	return nil
+}
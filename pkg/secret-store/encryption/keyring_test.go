@@ -0,0 +1,157 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+// testKMS "encrypts" by prefixing the plaintext with its master key version
+type testKMS struct {
+	version byte
+	calls   int
+}
+
+func (k *testKMS) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	k.calls++
+	return append([]byte{k.version}, plaintext...), nil
+}
+
+func (k *testKMS) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	k.calls++
+	if len(ciphertext) == 0 || ciphertext[0] > k.version {
+		return nil, errors.New("unknown master key")
+	}
+	return ciphertext[1:], nil
+}
+
+// testReencrypter keeps encrypted values in memory, as a secrets service would
+type testReencrypter struct {
+	keyring *Keyring
+	values  map[string][]byte
+	fail    bool
+}
+
+func (r *testReencrypter) Reencrypt(ctx context.Context, organizationID string) error {
+	if r.fail {
+		return errors.New("reencryption failed")
+	}
+	cipher, err := r.keyring.Cipher(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	for name, value := range r.values {
+		if cipher.Current(value) {
+			continue
+		}
+		plaintext, err := cipher.Decrypt(value)
+		if err != nil {
+			return err
+		}
+		if r.values[name], err = cipher.Encrypt(plaintext); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestKeyring(t *testing.T) {
+	store := helpers.MakeEntityStore(t)
+	k := &testKMS{version: 1}
+	keyring := NewKeyring(store, k)
+	ctx := context.Background()
+
+	c1, err := keyring.Cipher(ctx, "dispatch")
+	require.NoError(t, err)
+	encrypted, err := c1.Encrypt([]byte("password"))
+	require.NoError(t, err)
+
+	// the decrypted data keys are cached
+	calls := k.calls
+	c, err := keyring.Cipher(ctx, "dispatch")
+	require.NoError(t, err)
+	assert.Equal(t, c1, c)
+	assert.Equal(t, calls, k.calls)
+
+	entity, ok, err := keyring.find(ctx, "dispatch")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.False(t, bytes.Contains(entity.Keys[1][1:], []byte("password")))
+	assert.Equal(t, byte(1), entity.Keys[1][0])
+
+	// rotating the master key, then the data key
+	k.version = 2
+	require.NoError(t, keyring.Rotate(ctx, "dispatch"))
+	entity, _, _ = keyring.find(ctx, "dispatch")
+	assert.Equal(t, 2, entity.Current)
+	assert.Len(t, entity.Keys, 2)
+	assert.Equal(t, byte(2), entity.Keys[1][0], "previous data keys are encrypted with the new master key")
+
+	c2, err := keyring.Cipher(ctx, "dispatch")
+	require.NoError(t, err)
+	assert.False(t, c2.Current(encrypted))
+	plaintext, err := c2.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "password", string(plaintext))
+
+	require.NoError(t, keyring.Retire(ctx, "dispatch"))
+	c3, err := keyring.Cipher(ctx, "dispatch")
+	require.NoError(t, err)
+	_, err = c3.Decrypt(encrypted)
+	assert.Error(t, err)
+}
+
+func TestKeyRotator(t *testing.T) {
+	store := helpers.MakeEntityStore(t)
+	keyring := NewKeyring(store, &testKMS{version: 1})
+	ctx := context.Background()
+	c, err := keyring.Cipher(ctx, "dispatch")
+	require.NoError(t, err)
+	encrypted, err := c.Encrypt([]byte("password"))
+	require.NoError(t, err)
+
+	reencrypter := &testReencrypter{
+		keyring: keyring,
+		values:  map[string][]byte{"encrypted": encrypted, "legacy": []byte("legacy")},
+		fail:    true,
+	}
+	rotator := NewKeyRotator(keyring, reencrypter, time.Hour, time.Minute)
+
+	require.NoError(t, rotator.RotateDue(ctx, time.Now()))
+	entity, _, _ := keyring.find(ctx, "dispatch")
+	assert.Equal(t, 1, entity.Current)
+
+	// an interrupted rotation keeps the previous data key
+	require.NoError(t, rotator.RotateDue(ctx, time.Now().Add(2*time.Hour)))
+	entity, _, _ = keyring.find(ctx, "dispatch")
+	assert.Equal(t, 2, entity.Current)
+	assert.Len(t, entity.Keys, 2)
+
+	// and is completed at the next check
+	reencrypter.fail = false
+	require.NoError(t, rotator.RotateDue(ctx, time.Now()))
+	entity, _, _ = keyring.find(ctx, "dispatch")
+	assert.Equal(t, 2, entity.Current)
+	assert.Len(t, entity.Keys, 1)
+
+	c, err = keyring.Cipher(ctx, "dispatch")
+	require.NoError(t, err)
+	for _, name := range []string{"encrypted", "legacy"} {
+		assert.True(t, c.Current(reencrypter.values[name]))
+	}
+	plaintext, err := c.Decrypt(reencrypter.values["legacy"])
+	require.NoError(t, err)
+	assert.Equal(t, "legacy", string(plaintext))
+}
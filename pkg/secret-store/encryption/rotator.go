@@ -0,0 +1,115 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package encryption
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/trace"
+)
+
+// Reencrypter re-encrypts all the stored values of an organization with its current data key
+type Reencrypter interface {
+	Reencrypt(ctx context.Context, organizationID string) error
+}
+
+// KeyRotator rotates the data keys once they reach their maximum age, re-encrypting the values with the new keys
+type KeyRotator struct {
+	keyring     *Keyring
+	reencrypter Reencrypter
+	maxAge      time.Duration
+	period      time.Duration
+	done        chan bool
+}
+
+// NewKeyRotator creates a new key rotator, checking every period for the data keys older than maxAge
+func NewKeyRotator(keyring *Keyring, reencrypter Reencrypter, maxAge time.Duration, period time.Duration) *KeyRotator {
+	return &KeyRotator{
+		keyring:     keyring,
+		reencrypter: reencrypter,
+		maxAge:      maxAge,
+		period:      period,
+		done:        make(chan bool),
+	}
+}
+
+// Start starts rotating the data keys when they are due
+func (r *KeyRotator) Start() {
+	go r.run()
+}
+
+// Shutdown stops the rotations
+func (r *KeyRotator) Shutdown() {
+	r.done <- true
+}
+
+func (r *KeyRotator) run() {
+	ticker := time.NewTicker(r.period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.RotateDue(context.Background(), time.Now()); err != nil {
+				log.Errorf("error rotating data keys: %+v", err)
+			}
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// RotateDue rotates the data keys due for rotation at the given time, and completes the interrupted rotations
+func (r *KeyRotator) RotateDue(ctx context.Context, now time.Time) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	orgIDs, err := r.keyring.store.ListOrgIDs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, orgID := range orgIDs {
+		entity, ok, err := r.keyring.find(ctx, orgID)
+		if err != nil {
+			return err
+		} else if !ok {
+			continue
+		}
+		due := r.maxAge > 0 && !now.Before(entity.Rotated.Add(r.maxAge))
+		interrupted := len(entity.Keys) > 1
+		if !due && !interrupted {
+			continue
+		}
+		if interrupted {
+			err = r.complete(ctx, orgID)
+		} else {
+			err = r.Rotate(ctx, orgID)
+		}
+		// a failed rotation is completed at the next check
+		if err != nil {
+			log.Errorf("error rotating the data key of organization %s: %+v", orgID, err)
+		}
+	}
+	return nil
+}
+
+// Rotate rotates the data key of an organization now
+func (r *KeyRotator) Rotate(ctx context.Context, organizationID string) error {
+	if err := r.keyring.Rotate(ctx, organizationID); err != nil {
+		return err
+	}
+	return r.complete(ctx, organizationID)
+}
+
+// complete re-encrypts the values of an organization and retires its previous data keys
+func (r *KeyRotator) complete(ctx context.Context, organizationID string) error {
+	if err := r.reencrypter.Reencrypt(ctx, organizationID); err != nil {
+		return err
+	}
+	return r.keyring.Retire(ctx, organizationID)
+}
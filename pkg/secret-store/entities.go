@@ -6,10 +6,160 @@
 package secretstore
 
 import (
+	"time"
+
+	"github.com/go-openapi/swag"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
 	entitystore "github.com/vmware/dispatch/pkg/entity-store"
 )
 
 // SecretEntity is the secret entity type
 type SecretEntity struct {
 	entitystore.BaseEntity
+	// Version is the current version of the secret values, secrets created before versioning are at version 1
+	Version  int64           `json:"version,omitempty"`
+	Rotation *SecretRotation `json:"rotation,omitempty"`
+	// Access restricts the principals allowed to read the secret values, nil allows any principal of the organization
+	Access *SecretAccess `json:"access,omitempty"`
+}
+
+// SecretAccess lists the principals allowed to read the secret values, by name. Names may contain wildcards, e.g. ci-*
+type SecretAccess struct {
+	Functions       []string `json:"functions,omitempty"`
+	Subscriptions   []string `json:"subscriptions,omitempty"`
+	Drivers         []string `json:"drivers,omitempty"`
+	ServiceAccounts []string `json:"serviceAccounts,omitempty"`
+	Users           []string `json:"users,omitempty"`
+}
+
+// SecretRotation describes how a secret is rotated
+type SecretRotation struct {
+	// Function is the name of the function producing the new secret values
+	Function string `json:"function"`
+	// Interval between two rotations, 0 only rotates on demand
+	Interval    time.Duration `json:"interval,omitempty"`
+	LastRotated time.Time     `json:"lastRotated,omitempty"`
+	Reason      []string      `json:"reason,omitempty"`
+}
+
+// CurrentVersion returns the current version of the secret values
+func (e *SecretEntity) CurrentVersion() int64 {
+	if e.Version < 1 {
+		return 1
+	}
+	return e.Version
+}
+
+// RotationDue returns whether the secret has to be rotated at the given time
+func (e *SecretEntity) RotationDue(now time.Time) bool {
+	if e.Rotation == nil || e.Rotation.Interval <= 0 {
+		return false
+	}
+	last := e.Rotation.LastRotated
+	if last.IsZero() {
+		last = e.CreatedTime
+	}
+	return !now.Before(last.Add(e.Rotation.Interval))
+}
+
+// RotationToModel converts the rotation of the secret to its swagger model
+func (e *SecretEntity) RotationToModel() *v1.SecretRotation {
+	if e.Rotation == nil {
+		return nil
+	}
+	m := &v1.SecretRotation{
+		Function: swag.String(e.Rotation.Function),
+		Interval: int64(e.Rotation.Interval / time.Second),
+		Reason:   e.Rotation.Reason,
+	}
+	if !e.Rotation.LastRotated.IsZero() {
+		m.LastRotated = e.Rotation.LastRotated.Unix()
+	}
+	return m
+}
+
+// RotationFromModel sets the rotation of the secret from its swagger model, keeping the rotation state
+func (e *SecretEntity) RotationFromModel(m *v1.SecretRotation) {
+	if m == nil {
+		return
+	}
+	rotation := &SecretRotation{
+		Function: swag.StringValue(m.Function),
+		Interval: time.Duration(m.Interval) * time.Second,
+	}
+	if e.Rotation != nil {
+		rotation.LastRotated = e.Rotation.LastRotated
+		rotation.Reason = e.Rotation.Reason
+	}
+	e.Rotation = rotation
+}
+
+// AccessToModel converts the access rules of the secret to their swagger model
+func (e *SecretEntity) AccessToModel() *v1.SecretAccess {
+	if e.Access == nil {
+		return nil
+	}
+	return &v1.SecretAccess{
+		Functions:       e.Access.Functions,
+		Subscriptions:   e.Access.Subscriptions,
+		Drivers:         e.Access.Drivers,
+		ServiceAccounts: e.Access.ServiceAccounts,
+		Users:           e.Access.Users,
+	}
+}
+
+// AccessFromModel sets the access rules of the secret from their swagger model. A nil model keeps the current rules,
+// a model without any principal removes them.
+func (e *SecretEntity) AccessFromModel(m *v1.SecretAccess) {
+	if m == nil {
+		return
+	}
+	access := &SecretAccess{
+		Functions:       m.Functions,
+		Subscriptions:   m.Subscriptions,
+		Drivers:         m.Drivers,
+		ServiceAccounts: m.ServiceAccounts,
+		Users:           m.Users,
+	}
+	if len(access.Functions)+len(access.Subscriptions)+len(access.Drivers)+len(access.ServiceAccounts)+len(access.Users) == 0 {
+		access = nil
+	}
+	e.Access = access
+}
+
+// SecretAuditEntity records a read of the values of a secret
+type SecretAuditEntity struct {
+	entitystore.BaseEntity
+	Secret  string `json:"secret"`
+	Version int64  `json:"version"`
+	// Requester are the principals the secret was read for, e.g. subscription/on-push,function/build
+	Requester string `json:"requester"`
+	Purpose   string `json:"purpose,omitempty"`
+	// Allowed is whether the secret values were returned
+	Allowed bool `json:"allowed"`
+}
+
+// ToModel converts the audit record to its swagger model
+func (e *SecretAuditEntity) ToModel() *v1.SecretAudit {
+	return &v1.SecretAudit{
+		Secret:    e.Secret,
+		Version:   e.Version,
+		Requester: e.Requester,
+		Purpose:   e.Purpose,
+		Allowed:   e.Allowed,
+		ReadTime:  e.CreatedTime.Unix(),
+	}
+}
+
+// DataKeyEntity holds the data keys of an organization, encrypting its secret values at rest.
+// The data keys are themselves stored encrypted by the KMS.
+type DataKeyEntity struct {
+	entitystore.BaseEntity
+	// Current is the generation of the data key encrypting new values
+	Current int `json:"current"`
+	// Keys are the encrypted data keys by generation, previous keys are kept until all values are re-encrypted
+	Keys map[int][]byte `json:"keys"`
+	// Rotated is the time the current data key was created
+	Rotated time.Time `json:"rotated"`
 }
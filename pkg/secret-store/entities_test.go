@@ -5,8 +5,64 @@
 
 package secretstore
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+)
 
 func EntityTest(t *testing.T) {
 
 }
+
+func TestRotationDue(t *testing.T) {
+	now := time.Now()
+	e := SecretEntity{BaseEntity: entitystore.BaseEntity{CreatedTime: now.Add(-2 * time.Hour)}}
+	assert.False(t, e.RotationDue(now))
+
+	e.Rotation = &SecretRotation{Function: "rotate-db"}
+	assert.False(t, e.RotationDue(now), "on demand rotations are never due")
+
+	e.Rotation.Interval = time.Hour
+	assert.True(t, e.RotationDue(now), "not rotated for longer than the interval since creation")
+
+	e.Rotation.LastRotated = now.Add(-30 * time.Minute)
+	assert.False(t, e.RotationDue(now))
+	assert.True(t, e.RotationDue(now.Add(30*time.Minute)))
+}
+
+func TestRotationModel(t *testing.T) {
+	e := SecretEntity{}
+	assert.Nil(t, e.RotationToModel())
+	assert.Equal(t, int64(1), e.CurrentVersion())
+
+	e.RotationFromModel(&v1.SecretRotation{Function: swag.String("rotate-db"), Interval: 3600})
+	assert.Equal(t, time.Hour, e.Rotation.Interval)
+
+	last := time.Unix(1500000000, 0)
+	e.Rotation.LastRotated = last
+	e.RotationFromModel(&v1.SecretRotation{Function: swag.String("rotate-db-v2"), Interval: 60})
+	m := e.RotationToModel()
+	assert.Equal(t, "rotate-db-v2", *m.Function)
+	assert.Equal(t, int64(60), m.Interval)
+	assert.Equal(t, last.Unix(), m.LastRotated, "the rotation state is kept")
+}
+
+func TestAccessModel(t *testing.T) {
+	e := SecretEntity{}
+	assert.Nil(t, e.AccessToModel())
+
+	e.AccessFromModel(&v1.SecretAccess{Functions: []string{"billing"}})
+	assert.Equal(t, []string{"billing"}, e.AccessToModel().Functions)
+
+	e.AccessFromModel(nil)
+	assert.NotNil(t, e.Access, "the access rules are kept")
+
+	e.AccessFromModel(&v1.SecretAccess{})
+	assert.Nil(t, e.Access, "the access rules are removed")
+}
@@ -0,0 +1,20 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package kms
+
+import (
+	"context"
+)
+
+// NO TESTS
+
+// KMS (key management service) encrypts the data keys of the secret store with a master key it holds.
+// A KMS must be able to decrypt the data keys encrypted with its previous master keys, so that they can be
+// re-encrypted with the current one.
+type KMS interface {
+	Encrypt(ctx context.Context, plaintext []byte) ([]byte, error)
+	Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
@@ -0,0 +1,124 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package kms
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// master keys are AES-256 keys
+	localKeySize = 32
+	// ciphertexts start with the ID of the master key used, so that previous keys can be found
+	localKeyIDSize = 8
+)
+
+type localKey struct {
+	id   []byte
+	aead cipher.AEAD
+}
+
+// LocalKMS encrypts with master keys read from a local keyfile.
+//
+// The keyfile contains one base64 encoded 32 bytes key per line, e.g. generated with
+// `head -c 32 /dev/urandom | base64`. The first key is used to encrypt, all of them to decrypt:
+// to rotate the master key, add a new key as the first line, and remove the previous key once
+// the data keys have been rotated.
+type LocalKMS struct {
+	keys []localKey
+}
+
+// NewLocalKMS creates a new local KMS reading the master keys from the keyfile at path
+func NewLocalKMS(path string) (*LocalKMS, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error opening keyfile")
+	}
+	defer f.Close()
+	return newLocalKMS(f)
+}
+
+func newLocalKMS(r io.Reader) (*LocalKMS, error) {
+	k := &LocalKMS{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(line)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key %d in keyfile", len(k.keys)+1)
+		}
+		if len(key) != localKeySize {
+			return nil, errors.Errorf("invalid key %d in keyfile: must be %d bytes", len(k.keys)+1, localKeySize)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(key)
+		k.keys = append(k.keys, localKey{id: sum[:localKeyIDSize], aead: aead})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "error reading keyfile")
+	}
+	if len(k.keys) == 0 {
+		return nil, errors.New("keyfile contains no key")
+	}
+	return k, nil
+}
+
+// Encrypt encrypts plaintext with the first master key
+func (k *LocalKMS) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	key := k.keys[0]
+	nonce := make([]byte, key.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "error generating nonce")
+	}
+	ciphertext := append(append([]byte{}, key.id...), nonce...)
+	return key.aead.Seal(ciphertext, nonce, plaintext, key.id), nil
+}
+
+// Decrypt decrypts a ciphertext encrypted with any of the master keys
+func (k *LocalKMS) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < localKeyIDSize {
+		return nil, errors.New("invalid ciphertext")
+	}
+	id := ciphertext[:localKeyIDSize]
+	for _, key := range k.keys {
+		if !bytes.Equal(key.id, id) {
+			continue
+		}
+		sealed := ciphertext[localKeyIDSize:]
+		if len(sealed) < key.aead.NonceSize() {
+			return nil, errors.New("invalid ciphertext")
+		}
+		nonce := sealed[:key.aead.NonceSize()]
+		plaintext, err := key.aead.Open(nil, nonce, sealed[key.aead.NonceSize():], key.id)
+		if err != nil {
+			return nil, errors.Wrap(err, "error decrypting with local master key")
+		}
+		return plaintext, nil
+	}
+	return nil, errors.New("master key not found in keyfile")
+}
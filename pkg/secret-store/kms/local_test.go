@@ -0,0 +1,66 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package kms
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func key(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), localKeySize)))
+}
+
+func TestLocalKMS(t *testing.T) {
+	ctx := context.Background()
+	old, err := newLocalKMS(strings.NewReader(key('a') + "\n"))
+	require.NoError(t, err)
+	ciphertext, err := old.Encrypt(ctx, []byte("data key"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(ciphertext), "data key")
+
+	// a new master key is added first, the previous one still decrypts
+	rotated, err := newLocalKMS(strings.NewReader("# rotated\n" + key('b') + "\n" + key('a') + "\n"))
+	require.NoError(t, err)
+	plaintext, err := rotated.Decrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "data key", string(plaintext))
+
+	reencrypted, err := rotated.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+	_, err = old.Decrypt(ctx, reencrypted)
+	assert.Error(t, err)
+
+	reencrypted[len(reencrypted)-1] ^= 1
+	_, err = rotated.Decrypt(ctx, reencrypted)
+	assert.Error(t, err)
+}
+
+func TestLocalKMSKeyfile(t *testing.T) {
+	_, err := newLocalKMS(strings.NewReader(""))
+	assert.Error(t, err)
+	_, err = newLocalKMS(strings.NewReader(base64.StdEncoding.EncodeToString([]byte("short"))))
+	assert.Error(t, err)
+	_, err = newLocalKMS(strings.NewReader("not base64"))
+	assert.Error(t, err)
+
+	f, err := ioutil.TempFile("", "keyfile")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	f.WriteString(key('a'))
+	f.Close()
+	_, err = NewLocalKMS(f.Name())
+	assert.NoError(t, err)
+	_, err = NewLocalKMS(f.Name() + "-missing")
+	assert.Error(t, err)
+}
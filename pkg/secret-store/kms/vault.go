@@ -0,0 +1,50 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package kms
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware/dispatch/pkg/secret-store/vault"
+)
+
+// VaultKMS encrypts with a key of the vault transit secrets engine.
+//
+// The master key never leaves vault, and is rotated in vault (vault write -f transit/keys/<key>/rotate).
+type VaultKMS struct {
+	client *vault.Client
+	mount  string
+	key    string
+}
+
+// NewVaultKMS creates a new vault KMS using the named key of the transit secrets engine mounted at mount
+func NewVaultKMS(client *vault.Client, mount, key string) *VaultKMS {
+	return &VaultKMS{
+		client: client,
+		mount:  mount,
+		key:    key,
+	}
+}
+
+// Encrypt encrypts plaintext with the latest version of the transit key
+func (k *VaultKMS) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	ciphertext, err := k.client.Encrypt(ctx, k.mount, k.key, plaintext)
+	if err != nil {
+		return nil, errors.Wrap(err, "error encrypting with vault transit key")
+	}
+	return []byte(ciphertext), nil
+}
+
+// Decrypt decrypts a ciphertext encrypted with any version of the transit key
+func (k *VaultKMS) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	plaintext, err := k.client.Decrypt(ctx, k.mount, k.key, string(ciphertext))
+	if err != nil {
+		return nil, errors.Wrap(err, "error decrypting with vault transit key")
+	}
+	return plaintext, nil
+}
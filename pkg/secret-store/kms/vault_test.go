@@ -0,0 +1,42 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package kms
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/secret-store/vault"
+	fakevault "github.com/vmware/dispatch/pkg/testing/vault"
+)
+
+func TestVaultKMS(t *testing.T) {
+	fake := fakevault.NewFakeVault("root")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client, err := vault.NewClient(&vault.Config{Address: server.URL, Token: "root"})
+	require.NoError(t, err)
+	k := NewVaultKMS(client, "transit", "dispatch")
+	ctx := context.Background()
+
+	ciphertext, err := k.Encrypt(ctx, []byte("data key"))
+	require.NoError(t, err)
+
+	fake.RotateTransitKey("dispatch")
+	rotated, err := k.Encrypt(ctx, []byte("data key"))
+	require.NoError(t, err)
+	assert.NotEqual(t, string(ciphertext), string(rotated))
+
+	for _, c := range [][]byte{ciphertext, rotated} {
+		plaintext, err := k.Decrypt(ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, "data key", string(plaintext))
+	}
+}
@@ -5,6 +5,7 @@ package mocks
 import context "context"
 import entitystore "github.com/vmware/dispatch/pkg/entity-store"
 import mock "github.com/stretchr/testify/mock"
+import reflect "reflect"
 
 // EntityStore is an autogenerated mock type for the EntityStore type
 type EntityStore struct {
@@ -132,6 +133,20 @@ func (_m *EntityStore) SoftDelete(ctx context.Context, entity entitystore.Entity
 	return r0
 }
 
+// Tx provides a mock function with given fields: ctx, f
+func (_m *EntityStore) Tx(ctx context.Context, f func(entitystore.EntityStore) error) error {
+	ret := _m.Called(ctx, f)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(entitystore.EntityStore) error) error); ok {
+		r0 = rf(ctx, f)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, lastRevision, entity
 func (_m *EntityStore) Update(ctx context.Context, lastRevision uint64, entity entitystore.Entity) (int64, error) {
 	ret := _m.Called(ctx, lastRevision, entity)
@@ -157,3 +172,26 @@ func (_m *EntityStore) Update(ctx context.Context, lastRevision uint64, entity e
 func (_m *EntityStore) UpdateWithError(ctx context.Context, e entitystore.Entity, err error) {
 	_m.Called(ctx, e, err)
 }
+
+// Watch provides a mock function with given fields: ctx, organizationID, entityType, filter
+func (_m *EntityStore) Watch(ctx context.Context, organizationID string, entityType reflect.Type, filter entitystore.Filter) (<-chan entitystore.Event, error) {
+	ret := _m.Called(ctx, organizationID, entityType, filter)
+
+	var r0 <-chan entitystore.Event
+	if rf, ok := ret.Get(0).(func(context.Context, string, reflect.Type, entitystore.Filter) <-chan entitystore.Event); ok {
+		r0 = rf(ctx, organizationID, entityType, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan entitystore.Event)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, reflect.Type, entitystore.Filter) error); ok {
+		r1 = rf(ctx, organizationID, entityType, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,177 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package rotation
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	secretstore "github.com/vmware/dispatch/pkg/secret-store"
+	"github.com/vmware/dispatch/pkg/secret-store/service"
+	"github.com/vmware/dispatch/pkg/trace"
+)
+
+// NotConfigured is the error type when rotating a secret without rotation function
+type NotConfigured struct {
+	error
+}
+
+// Rotator rotates secrets, by running their rotation function and storing its output as a new version of the secret.
+//
+// The rotation function runs with the secret injected (so it can use the current values), and gets the secret name
+// and current version as input, e.g. {"secret": "psql-creds", "version": 3}. Its output must be an object of strings,
+// e.g. {"username": "admin", "password": "rotated"}.
+type Rotator struct {
+	store     entitystore.EntityStore
+	secrets   service.SecretsService
+	functions client.FunctionsClient
+	period    time.Duration
+	done      chan bool
+}
+
+// NewRotator creates a new rotator, checking every period for the secrets due for rotation
+func NewRotator(store entitystore.EntityStore, secrets service.SecretsService, functions client.FunctionsClient, period time.Duration) *Rotator {
+	return &Rotator{
+		store:     store,
+		secrets:   secrets,
+		functions: functions,
+		period:    period,
+		done:      make(chan bool),
+	}
+}
+
+// Start starts rotating the secrets when they are due
+func (r *Rotator) Start() {
+	go r.run()
+}
+
+// Shutdown stops the rotations
+func (r *Rotator) Shutdown() {
+	r.done <- true
+}
+
+func (r *Rotator) run() {
+	ticker := time.NewTicker(r.period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.RotateDue(context.Background(), time.Now()); err != nil {
+				log.Errorf("error rotating secrets: %+v", err)
+			}
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// RotateDue rotates all the secrets due for rotation at the given time
+func (r *Rotator) RotateDue(ctx context.Context, now time.Time) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	orgIDs, err := r.store.ListOrgIDs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, orgID := range orgIDs {
+		var entities []*secretstore.SecretEntity
+		if err := r.store.List(ctx, orgID, entitystore.Options{}, &entities); err != nil {
+			return err
+		}
+		for _, entity := range entities {
+			if !entity.RotationDue(now) {
+				continue
+			}
+			// a failed rotation is recorded on the secret, and retried at the next check
+			if _, err := r.Rotate(ctx, orgID, entity.Name); err != nil {
+				log.Errorf("error rotating secret %s: %+v", entity.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Rotate rotates a secret now
+func (r *Rotator) Rotate(ctx context.Context, organizationID string, name string) (*v1.Secret, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	entity := secretstore.SecretEntity{}
+	ok, err := r.store.Find(ctx, organizationID, name, entitystore.Options{}, &entity)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, service.SecretNotFound{}
+	}
+	if entity.Rotation == nil || entity.Rotation.Function == "" {
+		return nil, NotConfigured{errors.Errorf("secret %s has no rotation function", name)}
+	}
+
+	values, err := r.runRotation(ctx, organizationID, &entity)
+	if err != nil {
+		entity.Rotation.Reason = []string{err.Error()}
+		if _, updateErr := r.store.Update(ctx, entity.Revision, &entity); updateErr != nil {
+			log.Errorf("error recording the rotation failure of secret %s: %+v", name, updateErr)
+		}
+		return nil, err
+	}
+
+	if _, err := r.secrets.UpdateSecret(ctx, organizationID, v1.Secret{Name: &name, Secrets: values}, entitystore.Options{}); err != nil {
+		return nil, errors.Wrapf(err, "error storing the rotated secret %s", name)
+	}
+
+	// the update created a new revision of the entity
+	if _, err := r.store.Find(ctx, organizationID, name, entitystore.Options{}, &entity); err != nil {
+		return nil, err
+	}
+	entity.Rotation.LastRotated = time.Now()
+	entity.Rotation.Reason = nil
+	if _, err := r.store.Update(ctx, entity.Revision, &entity); err != nil {
+		return nil, err
+	}
+	log.Infof("secret %s rotated to version %d", name, entity.CurrentVersion())
+	return r.secrets.GetSecret(ctx, organizationID, name, entitystore.Options{})
+}
+
+// runRotation runs the rotation function of the secret, and returns the new secret values
+func (r *Rotator) runRotation(ctx context.Context, organizationID string, entity *secretstore.SecretEntity) (v1.SecretValue, error) {
+	run, err := r.functions.RunFunction(ctx, organizationID, &v1.Run{
+		FunctionName: entity.Rotation.Function,
+		Blocking:     true,
+		Secrets:      []string{entity.Name},
+		Input: map[string]interface{}{
+			"secret":  entity.Name,
+			"version": entity.CurrentVersion(),
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error running rotation function %s", entity.Rotation.Function)
+	}
+	if run.Error != nil && run.Error.Message != nil {
+		return nil, errors.Errorf("rotation function %s failed: %s", entity.Rotation.Function, *run.Error.Message)
+	}
+
+	output, ok := run.Output.(map[string]interface{})
+	if !ok || len(output) == 0 {
+		return nil, errors.Errorf("rotation function %s must return an object of secret values", entity.Rotation.Function)
+	}
+	values := v1.SecretValue{}
+	for k, v := range output {
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.Errorf("rotation function %s returned a non string value for %s", entity.Rotation.Function, k)
+		}
+		values[k] = s
+	}
+	return values, nil
+}
@@ -0,0 +1,110 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package rotation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client/mocks"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	secretstore "github.com/vmware/dispatch/pkg/secret-store"
+	"github.com/vmware/dispatch/pkg/secret-store/service"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+func setup(t *testing.T, functions *mocks.FunctionsClient) (*Rotator, entitystore.EntityStore, service.SecretsService) {
+	store := helpers.MakeEntityStore(t)
+	secrets := &service.K8sSecretsService{
+		EntityStore: store,
+		SecretsAPI:  k8sfake.NewSimpleClientset().CoreV1().Secrets("default"),
+	}
+	_, err := secrets.AddSecret(context.Background(), "dispatch", v1.Secret{
+		Name:     swag.String("psql-creds"),
+		Secrets:  v1.SecretValue{"password": "initial"},
+		Rotation: &v1.SecretRotation{Function: swag.String("rotate-psql"), Interval: 3600},
+	})
+	require.NoError(t, err)
+	return NewRotator(store, secrets, functions, time.Minute), store, secrets
+}
+
+func TestRotate(t *testing.T) {
+	functions := &mocks.FunctionsClient{}
+	functions.On("RunFunction", mock.Anything, "dispatch", mock.Anything).Return(&v1.Run{
+		Output: map[string]interface{}{"password": "rotated"},
+	}, nil)
+	r, store, _ := setup(t, functions)
+
+	secret, err := r.Rotate(context.Background(), "dispatch", "psql-creds")
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", secret.Secrets["password"])
+	assert.Equal(t, int64(2), secret.Version)
+	assert.NotZero(t, secret.Rotation.LastRotated)
+
+	run := functions.Calls[0].Arguments.Get(2).(*v1.Run)
+	assert.Equal(t, "rotate-psql", run.FunctionName)
+	assert.True(t, run.Blocking)
+	assert.Equal(t, []string{"psql-creds"}, run.Secrets, "the rotation function gets the current values")
+	assert.Equal(t, int64(1), run.Input.(map[string]interface{})["version"])
+
+	entity := secretstore.SecretEntity{}
+	_, err = store.Find(context.Background(), "dispatch", "psql-creds", entitystore.Options{}, &entity)
+	require.NoError(t, err)
+	assert.False(t, entity.RotationDue(time.Now()))
+}
+
+func TestRotateFailure(t *testing.T) {
+	functions := &mocks.FunctionsClient{}
+	functions.On("RunFunction", mock.Anything, "dispatch", mock.Anything).Return(&v1.Run{
+		Output: map[string]interface{}{"password": 42},
+	}, nil)
+	r, _, secrets := setup(t, functions)
+
+	_, err := r.Rotate(context.Background(), "dispatch", "psql-creds")
+	assert.Error(t, err)
+
+	secret, err := secrets.GetSecret(context.Background(), "dispatch", "psql-creds", entitystore.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "initial", secret.Secrets["password"])
+	assert.Equal(t, int64(1), secret.Version)
+	assert.Len(t, secret.Rotation.Reason, 1)
+}
+
+func TestRotateNotConfigured(t *testing.T) {
+	r, _, secrets := setup(t, &mocks.FunctionsClient{})
+	_, err := secrets.AddSecret(context.Background(), "dispatch", v1.Secret{
+		Name:    swag.String("api-key"),
+		Secrets: v1.SecretValue{"key": "static"},
+	})
+	require.NoError(t, err)
+
+	_, err = r.Rotate(context.Background(), "dispatch", "api-key")
+	assert.IsType(t, NotConfigured{}, err)
+	_, err = r.Rotate(context.Background(), "dispatch", "missing")
+	assert.IsType(t, service.SecretNotFound{}, err)
+}
+
+func TestRotateDue(t *testing.T) {
+	functions := &mocks.FunctionsClient{}
+	functions.On("RunFunction", mock.Anything, "dispatch", mock.Anything).Return(&v1.Run{
+		Output: map[string]interface{}{"password": "rotated"},
+	}, nil)
+	r, _, _ := setup(t, functions)
+
+	require.NoError(t, r.RotateDue(context.Background(), time.Now()))
+	functions.AssertNotCalled(t, "RunFunction", mock.Anything, mock.Anything, mock.Anything)
+
+	require.NoError(t, r.RotateDue(context.Background(), time.Now().Add(2*time.Hour)))
+	functions.AssertNumberOfCalls(t, "RunFunction", 1)
+}
@@ -7,9 +7,16 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 
+	k8sv1api "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sv1 "k8s.io/client-go/kubernetes/typed/core/v1"
 
@@ -17,13 +24,65 @@ import (
 	entitystore "github.com/vmware/dispatch/pkg/entity-store"
 	secretstore "github.com/vmware/dispatch/pkg/secret-store"
 	"github.com/vmware/dispatch/pkg/secret-store/builder"
+	"github.com/vmware/dispatch/pkg/secret-store/encryption"
 	"github.com/vmware/dispatch/pkg/trace"
 )
 
+const (
+	// previous versions of a secret are kept as separate kubernetes secrets, labeled with the secret ID and version
+	secretIDLabel      = "dispatch-secret-id"
+	secretVersionLabel = "dispatch-secret-version"
+	// the time a version was created, the kubernetes creation timestamp is kept across updates
+	secretCreatedAnnotation = "dispatch-secret-created"
+)
+
 // K8sSecretsService type
 type K8sSecretsService struct {
 	EntityStore entitystore.EntityStore
 	SecretsAPI  k8sv1.SecretInterface
+	// MaxVersions is the number of previous versions kept for each secret, 0 keeps all of them
+	MaxVersions int
+	// Keyring encrypts the secret values stored in kubernetes, nil stores them as is
+	Keyring *encryption.Keyring
+}
+
+// cipher returns the cipher of the organization, or nil if the secret values are not encrypted
+func (secretsService *K8sSecretsService) cipher(ctx context.Context, organizationID string) (builder.Cipher, error) {
+	if secretsService.Keyring == nil {
+		return nil, nil
+	}
+	cipher, err := secretsService.Keyring.Cipher(ctx, organizationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting the data key")
+	}
+	return cipher, nil
+}
+
+func versionSecretName(id string, version int64) string {
+	return fmt.Sprintf("%s-v%d", id, version)
+}
+
+func versionSelector(id string) metav1.ListOptions {
+	return metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", secretIDLabel, id)}
+}
+
+func setCreated(k8sSecret *k8sv1api.Secret, created time.Time) {
+	if k8sSecret.Annotations == nil {
+		k8sSecret.Annotations = make(map[string]string)
+	}
+	k8sSecret.Annotations[secretCreatedAnnotation] = created.UTC().Format(time.RFC3339)
+}
+
+func createdTime(k8sSecret *k8sv1api.Secret) time.Time {
+	if created, err := time.Parse(time.RFC3339, k8sSecret.Annotations[secretCreatedAnnotation]); err == nil {
+		return created
+	}
+	return k8sSecret.CreationTimestamp.Time
+}
+
+func secretVersion(k8sSecret *k8sv1api.Secret) int64 {
+	version, _ := strconv.ParseInt(k8sSecret.Labels[secretVersionLabel], 10, 64)
+	return version
 }
 
 func (secretsService *K8sSecretsService) secretModelToEntity(m *dispatchv1.Secret) *secretstore.SecretEntity {
@@ -38,6 +97,8 @@ func (secretsService *K8sSecretsService) secretModelToEntity(m *dispatchv1.Secre
 			Tags: tags,
 		},
 	}
+	e.RotationFromModel(m.Rotation)
+	e.AccessFromModel(m.Access)
 	return &e
 }
 
@@ -78,6 +139,11 @@ func (secretsService *K8sSecretsService) GetSecrets(ctx context.Context, organiz
 		return []*dispatchv1.Secret{}, nil
 	}
 
+	cipher, err := secretsService.cipher(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
 	var secrets []*dispatchv1.Secret
 	for _, entity := range entities {
 
@@ -85,7 +151,10 @@ func (secretsService *K8sSecretsService) GetSecrets(ctx context.Context, organiz
 		if err != nil {
 			return nil, errors.Wrapf(err, "error retrieve secret from k8s secret apis")
 		}
-		model := builder.NewDispatchSecretBuilder(*entity, *k8sSecret).Build()
+		model, err := builder.NewDispatchSecretBuilder(*entity, *k8sSecret, cipher).Build()
+		if err != nil {
+			return nil, err
+		}
 		secrets = append(secrets, &model)
 	}
 	return secrets, nil
@@ -96,23 +165,37 @@ func (secretsService *K8sSecretsService) AddSecret(ctx context.Context, organiza
 	span, ctx := trace.Trace(ctx, "")
 	defer span.Finish()
 
+	cipher, err := secretsService.cipher(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	k8sSecret, err := builder.NewK8sSecretBuilder(secret, cipher).Build()
+	if err != nil {
+		return nil, err
+	}
+
 	secretEntity := secretsService.secretModelToEntity(&secret)
 	secretEntity.OrganizationID = organizationID
+	secretEntity.Version = 1
 	id, err := secretsService.EntityStore.Add(ctx, secretEntity)
 	if err != nil {
 		return nil, err
 	}
 
-	k8sSecret := builder.NewK8sSecretBuilder(secret).Build()
 	k8sSecret.Name = id
+	setCreated(&k8sSecret, time.Now())
 
 	createdSecret, err := secretsService.SecretsAPI.Create(&k8sSecret)
 	// TODO: Add goroutine to keep EntityStore and Kubernetes in sync.
 	if err != nil {
-		secretsService.EntityStore.Delete(ctx, organizationID, id, secretEntity)
+		secretsService.EntityStore.Delete(ctx, organizationID, secretEntity.Name, secretEntity)
+		return nil, errors.Wrap(err, "error creating secret with k8s secret apis")
 	}
 
-	retSecret := builder.NewDispatchSecretBuilder(*secretEntity, *createdSecret).Build()
+	retSecret, err := builder.NewDispatchSecretBuilder(*secretEntity, *createdSecret, cipher).Build()
+	if err != nil {
+		return nil, err
+	}
 
 	return &retSecret, nil
 }
@@ -135,6 +218,9 @@ func (secretsService *K8sSecretsService) DeleteSecret(ctx context.Context, organ
 	if err != nil {
 		return err
 	}
+	if err := secretsService.deleteVersions(entity.ID, entity.CurrentVersion()+1); err != nil {
+		return err
+	}
 
 	return secretsService.EntityStore.Delete(ctx, organizationID, name, &entity)
 }
@@ -155,16 +241,221 @@ func (secretsService *K8sSecretsService) UpdateSecret(ctx context.Context, organ
 		return nil, SecretNotFound{}
 	}
 
+	// keep the current values as a previous version
+	current, err := secretsService.SecretsAPI.Get(entity.ID, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieve secret from k8s secret apis")
+	}
+	version := entity.CurrentVersion()
+	previous := k8sv1api.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: versionSecretName(entity.ID, version),
+			Labels: map[string]string{
+				secretIDLabel:      entity.ID,
+				secretVersionLabel: strconv.FormatInt(version, 10),
+			},
+		},
+		Data: current.Data,
+	}
+	setCreated(&previous, createdTime(current))
+	if _, err := secretsService.SecretsAPI.Create(&previous); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return nil, errors.Wrapf(err, "error storing version %d of the secret with k8s secret apis", version)
+	}
+
+	cipher, err := secretsService.cipher(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
 	secret.Name = &entity.ID
-	k8sSecret := builder.NewK8sSecretBuilder(secret).Build()
+	k8sSecret, err := builder.NewK8sSecretBuilder(secret, cipher).Build()
+	if err != nil {
+		return nil, err
+	}
+	setCreated(&k8sSecret, time.Now())
 
 	updatedSecret, err := secretsService.SecretsAPI.Update(&k8sSecret)
 	if err != nil {
 		return nil, err
 	}
 
-	dispatchSecretBuilder := builder.NewDispatchSecretBuilder(entity, *updatedSecret)
-	dispatchSecret := dispatchSecretBuilder.Build()
+	entity.Version = version + 1
+	entity.RotationFromModel(secret.Rotation)
+	entity.AccessFromModel(secret.Access)
+	if _, err := secretsService.EntityStore.Update(ctx, entity.Revision, &entity); err != nil {
+		return nil, err
+	}
+	if secretsService.MaxVersions > 0 {
+		if err := secretsService.deleteVersions(entity.ID, entity.Version-int64(secretsService.MaxVersions)); err != nil {
+			log.Warnf("error deleting previous versions of secret %s: %+v", name, err)
+		}
+	}
+
+	dispatchSecretBuilder := builder.NewDispatchSecretBuilder(entity, *updatedSecret, cipher)
+	dispatchSecret, err := dispatchSecretBuilder.Build()
+	if err != nil {
+		return nil, err
+	}
 
 	return &dispatchSecret, nil
 }
+
+// deleteVersions deletes the previous versions of a secret older than the given version
+func (secretsService *K8sSecretsService) deleteVersions(id string, before int64) error {
+	list, err := secretsService.SecretsAPI.List(versionSelector(id))
+	if err != nil {
+		return errors.Wrapf(err, "error listing secret versions from k8s secret apis")
+	}
+	for _, k8sSecret := range list.Items {
+		if secretVersion(&k8sSecret) >= before {
+			continue
+		}
+		if err := secretsService.SecretsAPI.Delete(k8sSecret.Name, &metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "error deleting secret version from k8s secret apis")
+		}
+	}
+	return nil
+}
+
+// GetSecretVersions lists the versions of a secret, oldest first
+func (secretsService *K8sSecretsService) GetSecretVersions(ctx context.Context, organizationID string, name string, opts entitystore.Options) ([]*dispatchv1.SecretVersion, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	entity := secretstore.SecretEntity{}
+	ok, err := secretsService.EntityStore.Find(ctx, organizationID, name, opts, &entity)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, SecretNotFound{}
+	}
+
+	current, err := secretsService.SecretsAPI.Get(entity.ID, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieve secret from k8s secret apis")
+	}
+	list, err := secretsService.SecretsAPI.List(versionSelector(entity.ID))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing secret versions from k8s secret apis")
+	}
+
+	versions := []*dispatchv1.SecretVersion{}
+	for _, k8sSecret := range list.Items {
+		versions = append(versions, &dispatchv1.SecretVersion{
+			Version:     secretVersion(&k8sSecret),
+			CreatedTime: createdTime(&k8sSecret).Unix(),
+		})
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	versions = append(versions, &dispatchv1.SecretVersion{
+		Version:     entity.CurrentVersion(),
+		CreatedTime: createdTime(current).Unix(),
+		Current:     true,
+	})
+	return versions, nil
+}
+
+// GetSecretVersion gets a specific version of a secret
+func (secretsService *K8sSecretsService) GetSecretVersion(ctx context.Context, organizationID string, name string, version int64, opts entitystore.Options) (*dispatchv1.Secret, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	entity := secretstore.SecretEntity{}
+	ok, err := secretsService.EntityStore.Find(ctx, organizationID, name, opts, &entity)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, SecretNotFound{}
+	}
+	if version < 1 || version > entity.CurrentVersion() {
+		return nil, SecretVersionNotFound{}
+	}
+
+	k8sName := entity.ID
+	if version < entity.CurrentVersion() {
+		k8sName = versionSecretName(entity.ID, version)
+	}
+	k8sSecret, err := secretsService.SecretsAPI.Get(k8sName, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, SecretVersionNotFound{}
+		}
+		return nil, errors.Wrapf(err, "error retrieve secret from k8s secret apis")
+	}
+	cipher, err := secretsService.cipher(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	model, err := builder.NewDispatchSecretBuilder(entity, *k8sSecret, cipher).Build()
+	if err != nil {
+		return nil, err
+	}
+	model.Version = version
+	return &model, nil
+}
+
+// RollbackSecret makes the values of a previous version current, as a new version of the secret
+func (secretsService *K8sSecretsService) RollbackSecret(ctx context.Context, organizationID string, name string, version int64, opts entitystore.Options) (*dispatchv1.Secret, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	return rollbackSecret(ctx, secretsService, organizationID, name, version, opts)
+}
+
+// Reencrypt re-encrypts the values of all the secrets of an organization (including their previous versions) with
+// its current data key. Values stored before encryption was enabled are encrypted.
+func (secretsService *K8sSecretsService) Reencrypt(ctx context.Context, organizationID string) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	if secretsService.Keyring == nil {
+		return nil
+	}
+	cipher, err := secretsService.Keyring.Cipher(ctx, organizationID)
+	if err != nil {
+		return errors.Wrap(err, "error getting the data key")
+	}
+
+	var entities []*secretstore.SecretEntity
+	if err := secretsService.EntityStore.List(ctx, organizationID, entitystore.Options{}, &entities); err != nil {
+		return err
+	}
+	for _, entity := range entities {
+		names := []string{entity.ID}
+		list, err := secretsService.SecretsAPI.List(versionSelector(entity.ID))
+		if err != nil {
+			return errors.Wrapf(err, "error listing secret versions from k8s secret apis")
+		}
+		for _, k8sSecret := range list.Items {
+			names = append(names, k8sSecret.Name)
+		}
+
+		for _, k8sName := range names {
+			k8sSecret, err := secretsService.SecretsAPI.Get(k8sName, metav1.GetOptions{})
+			if err != nil {
+				return errors.Wrapf(err, "error retrieve secret from k8s secret apis")
+			}
+			changed := false
+			for k, v := range k8sSecret.Data {
+				if cipher.Current(v) {
+					continue
+				}
+				plaintext, err := cipher.Decrypt(v)
+				if err != nil {
+					return errors.Wrapf(err, "error decrypting value %s of secret %s", k, entity.Name)
+				}
+				if k8sSecret.Data[k], err = cipher.Encrypt(plaintext); err != nil {
+					return err
+				}
+				changed = true
+			}
+			if !changed {
+				continue
+			}
+			if _, err := secretsService.SecretsAPI.Update(k8sSecret); err != nil {
+				return errors.Wrapf(err, "error updating secret with k8s secret apis")
+			}
+		}
+	}
+	log.Infof("secrets of organization %s re-encrypted", organizationID)
+	return nil
+}
@@ -6,20 +6,31 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"errors"
+	"io/ioutil"
+	"os"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	k8sv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
 
 	dispatchv1 "github.com/vmware/dispatch/pkg/api/v1"
 	entitystore "github.com/vmware/dispatch/pkg/entity-store"
 	secretstore "github.com/vmware/dispatch/pkg/secret-store"
 	"github.com/vmware/dispatch/pkg/secret-store/builder"
+	"github.com/vmware/dispatch/pkg/secret-store/encryption"
+	"github.com/vmware/dispatch/pkg/secret-store/kms"
 	"github.com/vmware/dispatch/pkg/secret-store/mocks"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
 )
 
 func setup() K8sSecretsService {
@@ -174,7 +185,7 @@ func TestAddSecretSuccess(t *testing.T) {
 	secretUUID := "000-000-001"
 	entityStore.On("Add", mock.Anything, mock.Anything).Return(secretUUID, nil)
 
-	k8sSecret := builder.NewK8sSecretBuilder(principal).Build()
+	k8sSecret, _ := builder.NewK8sSecretBuilder(principal, nil).Build()
 
 	secretsAPI := &mocks.SecretInterface{}
 	secretsAPI.On("Create", mock.Anything).Return(&k8sSecret, nil)
@@ -233,6 +244,7 @@ func TestDeleteSecretSuccess(t *testing.T) {
 
 	secretsAPI := &mocks.SecretInterface{}
 	secretsAPI.On("Delete", "000-000-001", &metav1.DeleteOptions{}).Return(nil)
+	secretsAPI.On("List", mock.Anything).Return(&k8sv1.SecretList{}, nil)
 
 	entityStore.On("Delete", mock.Anything, organizationID, secretName, mock.Anything).Return(nil)
 
@@ -294,8 +306,11 @@ func TestUpdateSecretSuccess(t *testing.T) {
 		},
 	}
 
-	k8sSecret := builder.NewK8sSecretBuilder(principal).Build()
+	k8sSecret, _ := builder.NewK8sSecretBuilder(principal, nil).Build()
+	secretsAPI.On("Get", "000-000-001", metav1.GetOptions{}).Return(&k8sSecret, nil)
+	secretsAPI.On("Create", mock.Anything).Return(&k8sSecret, nil)
 	secretsAPI.On("Update", mock.Anything).Return(&k8sSecret, nil)
+	entityStore.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil)
 
 	secretsService := K8sSecretsService{
 		EntityStore: entityStore,
@@ -337,3 +352,169 @@ func TestUpdateSecretNotExist(t *testing.T) {
 	assert.Equal(t, SecretNotFound{}, err, "Should have returned SecretNotFound error")
 	secretsAPI.AssertNotCalled(t, "Update", "Kubernetes secrets Update was called and should not have been.")
 }
+
+func TestK8sSecretVersions(t *testing.T) {
+	ctx := context.Background()
+	secretsService := K8sSecretsService{
+		EntityStore: helpers.MakeEntityStore(t),
+		SecretsAPI:  k8sfake.NewSimpleClientset().CoreV1().Secrets("default"),
+		MaxVersions: 2,
+	}
+	name := "psql-creds"
+
+	added, err := secretsService.AddSecret(ctx, "vmware", dispatchv1.Secret{
+		Name:    &name,
+		Secrets: dispatchv1.SecretValue{"password": "v1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), added.Version)
+
+	for _, password := range []string{"v2", "v3", "v4"} {
+		_, err := secretsService.UpdateSecret(ctx, "vmware", dispatchv1.Secret{
+			Name:    &name,
+			Secrets: dispatchv1.SecretValue{"password": password},
+		}, entitystore.Options{})
+		require.NoError(t, err)
+	}
+
+	// version 1 is pruned, only two previous versions are kept
+	versions, err := secretsService.GetSecretVersions(ctx, "vmware", name, entitystore.Options{})
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	assert.Equal(t, int64(2), versions[0].Version)
+	assert.Equal(t, int64(3), versions[1].Version)
+	assert.Equal(t, int64(4), versions[2].Version)
+	assert.True(t, versions[2].Current)
+
+	_, err = secretsService.GetSecretVersion(ctx, "vmware", name, 1, entitystore.Options{})
+	assert.IsType(t, SecretVersionNotFound{}, err)
+	secret, err := secretsService.GetSecretVersion(ctx, "vmware", name, 2, entitystore.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "v2", secret.Secrets["password"])
+
+	rolledBack, err := secretsService.RollbackSecret(ctx, "vmware", name, 2, entitystore.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), rolledBack.Version)
+	secret, err = secretsService.GetSecret(ctx, "vmware", name, entitystore.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "v2", secret.Secrets["password"])
+	assert.Equal(t, int64(5), secret.Version)
+
+	require.NoError(t, secretsService.DeleteSecret(ctx, "vmware", name, entitystore.Options{}))
+	list, err := secretsService.SecretsAPI.List(metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Items)
+}
+
+func TestK8sSecretEncryption(t *testing.T) {
+	ctx := context.Background()
+	keyfile, err := ioutil.TempFile("", "keyfile")
+	require.NoError(t, err)
+	defer os.Remove(keyfile.Name())
+	keyfile.WriteString(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)))
+	keyfile.Close()
+	localKMS, err := kms.NewLocalKMS(keyfile.Name())
+	require.NoError(t, err)
+
+	store := helpers.MakeEntityStore(t)
+	secretsService := &K8sSecretsService{
+		EntityStore: store,
+		SecretsAPI:  k8sfake.NewSimpleClientset().CoreV1().Secrets("default"),
+	}
+	rawValues := func() []string {
+		list, err := secretsService.SecretsAPI.List(metav1.ListOptions{})
+		require.NoError(t, err)
+		var values []string
+		for _, k8sSecret := range list.Items {
+			values = append(values, string(k8sSecret.Data["password"]))
+		}
+		return values
+	}
+
+	// stored before encryption was enabled
+	legacy := "legacy"
+	_, err = secretsService.AddSecret(ctx, "dispatch", dispatchv1.Secret{
+		Name:    &legacy,
+		Secrets: dispatchv1.SecretValue{"password": "plain"},
+	})
+	require.NoError(t, err)
+
+	secretsService.Keyring = encryption.NewKeyring(store, localKMS)
+	name := "psql-creds"
+	_, err = secretsService.AddSecret(ctx, "dispatch", dispatchv1.Secret{
+		Name:    &name,
+		Secrets: dispatchv1.SecretValue{"password": "v1"},
+	})
+	require.NoError(t, err)
+	_, err = secretsService.UpdateSecret(ctx, "dispatch", dispatchv1.Secret{
+		Name:    &name,
+		Secrets: dispatchv1.SecretValue{"password": "v2"},
+	}, entitystore.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dispatch:enc:1:", "dispatch:enc:1:", "plain"}, prefixes(rawValues()))
+
+	secret, err := secretsService.GetSecret(ctx, "dispatch", name, entitystore.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "v2", secret.Secrets["password"])
+	secret, err = secretsService.GetSecretVersion(ctx, "dispatch", name, 1, entitystore.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "v1", secret.Secrets["password"])
+
+	// rotating the data key re-encrypts all the values, including the legacy ones
+	rotator := encryption.NewKeyRotator(secretsService.Keyring, secretsService, time.Hour, time.Minute)
+	require.NoError(t, rotator.Rotate(ctx, "dispatch"))
+	assert.Equal(t, []string{"dispatch:enc:2:", "dispatch:enc:2:", "dispatch:enc:2:"}, prefixes(rawValues()))
+
+	secrets, err := secretsService.GetSecrets(ctx, "dispatch", entitystore.Options{})
+	require.NoError(t, err)
+	values := make(map[string]string)
+	for _, s := range secrets {
+		values[*s.Name] = s.Secrets["password"]
+	}
+	assert.Equal(t, map[string]string{"legacy": "plain", "psql-creds": "v2"}, values)
+}
+
+// prefixes returns the sorted prefixes of the raw values, showing the data key they are encrypted with
+func prefixes(values []string) []string {
+	var p []string
+	for _, v := range values {
+		if len(v) > len("dispatch:enc:1:") {
+			v = v[:len("dispatch:enc:1:")]
+		}
+		p = append(p, v)
+	}
+	sort.Strings(p)
+	return p
+}
+
+func TestK8sSecretsOrganizationIsolation(t *testing.T) {
+	ctx := context.Background()
+	secretsService := K8sSecretsService{
+		EntityStore: helpers.MakeEntityStore(t),
+		SecretsAPI:  k8sfake.NewSimpleClientset().CoreV1().Secrets("default"),
+	}
+	name := "psql-creds"
+
+	_, err := secretsService.AddSecret(ctx, "org-a", dispatchv1.Secret{
+		Name:    &name,
+		Secrets: dispatchv1.SecretValue{"password": "a"},
+	})
+	require.NoError(t, err)
+
+	_, err = secretsService.GetSecret(ctx, "org-b", name, entitystore.Options{})
+	assert.Equal(t, SecretNotFound{}, err)
+	secrets, err := secretsService.GetSecrets(ctx, "org-b", entitystore.Options{})
+	require.NoError(t, err)
+	assert.Empty(t, secrets)
+	assert.Equal(t, SecretNotFound{}, secretsService.DeleteSecret(ctx, "org-b", name, entitystore.Options{}))
+
+	// org-b may have a secret of the same name
+	_, err = secretsService.AddSecret(ctx, "org-b", dispatchv1.Secret{
+		Name:    &name,
+		Secrets: dispatchv1.SecretValue{"password": "b"},
+	})
+	require.NoError(t, err)
+	secret, err := secretsService.GetSecret(ctx, "org-a", name, entitystore.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "a", secret.Secrets["password"])
+}
@@ -17,6 +17,11 @@ type SecretNotFound struct {
 	error
 }
 
+// SecretVersionNotFound is the error type when the version of a secret is not found
+type SecretVersionNotFound struct {
+	error
+}
+
 // SecretsService defines the secrets service interface
 type SecretsService interface {
 	AddSecret(ctx context.Context, organizationID string, secret v1.Secret) (*v1.Secret, error)
@@ -24,4 +29,19 @@ type SecretsService interface {
 	GetSecret(ctx context.Context, organizationID string, name string, opts entitystore.Options) (*v1.Secret, error)
 	UpdateSecret(ctx context.Context, organizationID string, secret v1.Secret, opts entitystore.Options) (*v1.Secret, error)
 	DeleteSecret(ctx context.Context, organizationID string, name string, opts entitystore.Options) error
+	GetSecretVersions(ctx context.Context, organizationID string, name string, opts entitystore.Options) ([]*v1.SecretVersion, error)
+	GetSecretVersion(ctx context.Context, organizationID string, name string, version int64, opts entitystore.Options) (*v1.Secret, error)
+	RollbackSecret(ctx context.Context, organizationID string, name string, version int64, opts entitystore.Options) (*v1.Secret, error)
+}
+
+// rollbackSecret stores the values of a previous version as a new version of the secret
+func rollbackSecret(ctx context.Context, secretsService SecretsService, organizationID string, name string, version int64, opts entitystore.Options) (*v1.Secret, error) {
+	previous, err := secretsService.GetSecretVersion(ctx, organizationID, name, version, opts)
+	if err != nil {
+		return nil, err
+	}
+	return secretsService.UpdateSecret(ctx, organizationID, v1.Secret{
+		Name:    &name,
+		Secrets: previous.Secrets,
+	}, opts)
 }
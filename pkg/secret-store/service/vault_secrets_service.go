@@ -0,0 +1,282 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package service
+
+import (
+	"context"
+	"path"
+	"sync"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	dispatchv1 "github.com/vmware/dispatch/pkg/api/v1"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	secretstore "github.com/vmware/dispatch/pkg/secret-store"
+	"github.com/vmware/dispatch/pkg/secret-store/vault"
+	"github.com/vmware/dispatch/pkg/trace"
+	"github.com/vmware/dispatch/pkg/utils"
+)
+
+// vaultReadConcurrency is the number of secrets read at once from vault when listing secrets
+const vaultReadConcurrency = 8
+
+// VaultSecretsService stores secret values in the vault KV v2 secrets engine, under
+// <PathPrefix>/<organization>/<secret name>. Secret metadata is kept in the entity store.
+type VaultSecretsService struct {
+	EntityStore entitystore.EntityStore
+	Vault       *vault.Client
+	PathPrefix  string
+}
+
+func (secretsService *VaultSecretsService) secretModelToEntity(m *dispatchv1.Secret) *secretstore.SecretEntity {
+	tags := make(map[string]string)
+	for _, t := range m.Tags {
+		tags[t.Key] = t.Value
+	}
+	tags["label"] = "secret"
+	e := secretstore.SecretEntity{
+		BaseEntity: entitystore.BaseEntity{
+			Name: *m.Name,
+			Tags: tags,
+		},
+	}
+	e.RotationFromModel(m.Rotation)
+	e.AccessFromModel(m.Access)
+	return &e
+}
+
+func (secretsService *VaultSecretsService) secretEntityToModel(entity *secretstore.SecretEntity, values map[string]string) *dispatchv1.Secret {
+	secretValue := dispatchv1.SecretValue{}
+	for k, v := range values {
+		secretValue[k] = v
+	}
+	tags := []*dispatchv1.Tag{}
+	for k, v := range entity.Tags {
+		tags = append(tags, &dispatchv1.Tag{Key: k, Value: v})
+	}
+	name := entity.Name
+	return &dispatchv1.Secret{
+		ID:       strfmt.UUID(entity.ID),
+		Name:     &name,
+		Kind:     utils.SecretKind,
+		Secrets:  secretValue,
+		Tags:     tags,
+		Version:  entity.CurrentVersion(),
+		Rotation: entity.RotationToModel(),
+		Access:   entity.AccessToModel(),
+	}
+}
+
+func (secretsService *VaultSecretsService) path(organizationID, name string) string {
+	return path.Join(secretsService.PathPrefix, organizationID, name)
+}
+
+// GetSecret gets a specific secret
+func (secretsService *VaultSecretsService) GetSecret(ctx context.Context, organizationID string, name string, opts entitystore.Options) (*dispatchv1.Secret, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	entity := secretstore.SecretEntity{}
+	ok, err := secretsService.EntityStore.Find(ctx, organizationID, name, opts, &entity)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, SecretNotFound{}
+	}
+
+	secret, err := secretsService.Vault.Read(ctx, secretsService.path(organizationID, name), 0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading secret %s from vault", name)
+	}
+	return secretsService.secretEntityToModel(&entity, secret.Data), nil
+}
+
+// GetSecrets gets all the secrets
+func (secretsService *VaultSecretsService) GetSecrets(ctx context.Context, organizationID string, opts entitystore.Options) ([]*dispatchv1.Secret, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	var entities []*secretstore.SecretEntity
+	if err := secretsService.EntityStore.List(ctx, organizationID, opts, &entities); err != nil {
+		return nil, err
+	}
+
+	// the values are read concurrently, a secret which can't be read is left out rather than failing the whole list
+	models := make([]*dispatchv1.Secret, len(entities))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < vaultReadConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				entity := entities[i]
+				secret, err := secretsService.Vault.Read(ctx, secretsService.path(organizationID, entity.Name), 0)
+				if err != nil {
+					log.Errorf("error reading secret %s from vault, leaving it out: %+v", entity.Name, err)
+					continue
+				}
+				models[i] = secretsService.secretEntityToModel(entity, secret.Data)
+			}
+		}()
+	}
+	for i := range entities {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	secrets := []*dispatchv1.Secret{}
+	for _, model := range models {
+		if model != nil {
+			secrets = append(secrets, model)
+		}
+	}
+	return secrets, nil
+}
+
+// AddSecret adds a secret
+func (secretsService *VaultSecretsService) AddSecret(ctx context.Context, organizationID string, secret dispatchv1.Secret) (*dispatchv1.Secret, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	secretEntity := secretsService.secretModelToEntity(&secret)
+	secretEntity.OrganizationID = organizationID
+	if _, err := secretsService.EntityStore.Add(ctx, secretEntity); err != nil {
+		return nil, err
+	}
+
+	version, err := secretsService.Vault.Write(ctx, secretsService.path(organizationID, *secret.Name), secret.Secrets)
+	if err != nil {
+		if delErr := secretsService.EntityStore.Delete(ctx, organizationID, secretEntity.Name, secretEntity); delErr != nil {
+			log.Errorf("error cleaning up secret %s after vault failure: %+v", secretEntity.Name, delErr)
+		}
+		return nil, errors.Wrapf(err, "error writing secret %s to vault", secretEntity.Name)
+	}
+	secretEntity.Version = int64(version)
+	if _, err := secretsService.EntityStore.Update(ctx, secretEntity.Revision, secretEntity); err != nil {
+		return nil, err
+	}
+	return secretsService.secretEntityToModel(secretEntity, secret.Secrets), nil
+}
+
+// DeleteSecret deletes a secret
+func (secretsService *VaultSecretsService) DeleteSecret(ctx context.Context, organizationID string, name string, opts entitystore.Options) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	entity := secretstore.SecretEntity{}
+	ok, err := secretsService.EntityStore.Find(ctx, organizationID, name, opts, &entity)
+	if err != nil {
+		return err
+	} else if !ok {
+		return SecretNotFound{}
+	}
+
+	if err := secretsService.Vault.Delete(ctx, secretsService.path(organizationID, name)); err != nil {
+		if _, notFound := err.(*vault.NotFoundError); !notFound {
+			return errors.Wrapf(err, "error deleting secret %s from vault", name)
+		}
+	}
+	return secretsService.EntityStore.Delete(ctx, organizationID, name, &entity)
+}
+
+// UpdateSecret updates a secret, vault keeps the previous values as older versions
+func (secretsService *VaultSecretsService) UpdateSecret(ctx context.Context, organizationID string, secret dispatchv1.Secret, opts entitystore.Options) (*dispatchv1.Secret, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	entity := secretstore.SecretEntity{}
+	name := *secret.Name
+
+	ok, err := secretsService.EntityStore.Find(ctx, organizationID, name, opts, &entity)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, SecretNotFound{}
+	}
+
+	version, err := secretsService.Vault.Write(ctx, secretsService.path(organizationID, name), secret.Secrets)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error writing secret %s to vault", name)
+	}
+	entity.Version = int64(version)
+	entity.RotationFromModel(secret.Rotation)
+	entity.AccessFromModel(secret.Access)
+	if _, err := secretsService.EntityStore.Update(ctx, entity.Revision, &entity); err != nil {
+		return nil, err
+	}
+	return secretsService.secretEntityToModel(&entity, secret.Secrets), nil
+}
+
+// GetSecretVersions lists the versions of a secret, oldest first
+func (secretsService *VaultSecretsService) GetSecretVersions(ctx context.Context, organizationID string, name string, opts entitystore.Options) ([]*dispatchv1.SecretVersion, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	entity := secretstore.SecretEntity{}
+	ok, err := secretsService.EntityStore.Find(ctx, organizationID, name, opts, &entity)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, SecretNotFound{}
+	}
+
+	versions, err := secretsService.Vault.Versions(ctx, secretsService.path(organizationID, name))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading versions of secret %s from vault", name)
+	}
+	models := []*dispatchv1.SecretVersion{}
+	for _, v := range versions {
+		if v.Deleted {
+			continue
+		}
+		models = append(models, &dispatchv1.SecretVersion{
+			Version:     int64(v.Version),
+			CreatedTime: v.CreatedTime.Unix(),
+			Current:     int64(v.Version) == entity.CurrentVersion(),
+		})
+	}
+	return models, nil
+}
+
+// GetSecretVersion gets a specific version of a secret
+func (secretsService *VaultSecretsService) GetSecretVersion(ctx context.Context, organizationID string, name string, version int64, opts entitystore.Options) (*dispatchv1.Secret, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	entity := secretstore.SecretEntity{}
+	ok, err := secretsService.EntityStore.Find(ctx, organizationID, name, opts, &entity)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, SecretNotFound{}
+	}
+	if version < 1 {
+		return nil, SecretVersionNotFound{}
+	}
+
+	secret, err := secretsService.Vault.Read(ctx, secretsService.path(organizationID, name), int(version))
+	if err != nil {
+		if _, notFound := err.(*vault.NotFoundError); notFound {
+			return nil, SecretVersionNotFound{}
+		}
+		return nil, errors.Wrapf(err, "error reading version %d of secret %s from vault", version, name)
+	}
+	model := secretsService.secretEntityToModel(&entity, secret.Data)
+	model.Version = version
+	return model, nil
+}
+
+// RollbackSecret makes the values of a previous version current, as a new version of the secret
+func (secretsService *VaultSecretsService) RollbackSecret(ctx context.Context, organizationID string, name string, version int64, opts entitystore.Options) (*dispatchv1.Secret, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	return rollbackSecret(ctx, secretsService, organizationID, name, version, opts)
+}
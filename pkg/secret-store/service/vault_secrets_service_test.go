@@ -0,0 +1,175 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package service
+
+import (
+	"context"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dispatchv1 "github.com/vmware/dispatch/pkg/api/v1"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/secret-store/vault"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+	fakevault "github.com/vmware/dispatch/pkg/testing/vault"
+)
+
+func setupVault(t *testing.T) (*VaultSecretsService, *fakevault.FakeVault, func()) {
+	fake := fakevault.NewFakeVault("root")
+	server := httptest.NewServer(fake)
+
+	client, err := vault.NewClient(&vault.Config{Address: server.URL, Token: "root"})
+	require.NoError(t, err)
+	return &VaultSecretsService{
+		EntityStore: helpers.MakeEntityStore(t),
+		Vault:       client,
+		PathPrefix:  "dispatch",
+	}, fake, server.Close
+}
+
+func TestVaultSecretLifecycle(t *testing.T) {
+	secretsService, fake, closer := setupVault(t)
+	defer closer()
+	ctx := context.Background()
+
+	added, err := secretsService.AddSecret(ctx, "vmware", dispatchv1.Secret{
+		Name:    swag.String("psql-creds"),
+		Secrets: dispatchv1.SecretValue{"username": "white-rabbit", "password": "iml8_iml8"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "psql-creds", *added.Name)
+	assert.NotEmpty(t, added.ID)
+	assert.Equal(t, []string{"dispatch/vmware/psql-creds"}, fake.Paths())
+
+	secret, err := secretsService.GetSecret(ctx, "vmware", "psql-creds", entitystore.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "iml8_iml8", secret.Secrets["password"])
+
+	_, err = secretsService.UpdateSecret(ctx, "vmware", dispatchv1.Secret{
+		Name:    swag.String("psql-creds"),
+		Secrets: dispatchv1.SecretValue{"username": "white-rabbit", "password": "rotated"},
+	}, entitystore.Options{})
+	require.NoError(t, err)
+
+	secrets, err := secretsService.GetSecrets(ctx, "vmware", entitystore.Options{})
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+	assert.Equal(t, "rotated", secrets[0].Secrets["password"])
+
+	// vault keeps the previous value as an older version
+	versions, err := secretsService.Vault.Versions(ctx, "dispatch/vmware/psql-creds")
+	require.NoError(t, err)
+	assert.Len(t, versions, 2)
+
+	require.NoError(t, secretsService.DeleteSecret(ctx, "vmware", "psql-creds", entitystore.Options{}))
+	_, err = secretsService.GetSecret(ctx, "vmware", "psql-creds", entitystore.Options{})
+	assert.IsType(t, SecretNotFound{}, err)
+	assert.Empty(t, fake.Paths())
+}
+
+func TestVaultSecretOrganizations(t *testing.T) {
+	secretsService, fake, closer := setupVault(t)
+	defer closer()
+	ctx := context.Background()
+
+	for _, org := range []string{"vmware", "acme"} {
+		_, err := secretsService.AddSecret(ctx, org, dispatchv1.Secret{
+			Name:    swag.String("api-key"),
+			Secrets: dispatchv1.SecretValue{"key": org},
+		})
+		require.NoError(t, err)
+	}
+	paths := fake.Paths()
+	sort.Strings(paths)
+	assert.Equal(t, []string{"dispatch/acme/api-key", "dispatch/vmware/api-key"}, paths)
+
+	secret, err := secretsService.GetSecret(ctx, "acme", "api-key", entitystore.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "acme", secret.Secrets["key"])
+
+	require.NoError(t, secretsService.DeleteSecret(ctx, "acme", "api-key", entitystore.Options{}))
+	secret, err = secretsService.GetSecret(ctx, "vmware", "api-key", entitystore.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "vmware", secret.Secrets["key"])
+}
+
+func TestVaultGetSecretsUnreadable(t *testing.T) {
+	secretsService, _, closer := setupVault(t)
+	defer closer()
+	ctx := context.Background()
+
+	for _, name := range []string{"api-key", "psql-creds"} {
+		_, err := secretsService.AddSecret(ctx, "vmware", dispatchv1.Secret{
+			Name:    swag.String(name),
+			Secrets: dispatchv1.SecretValue{"key": name},
+		})
+		require.NoError(t, err)
+	}
+	// the values of a secret are missing from vault, the other secrets are still listed
+	require.NoError(t, secretsService.Vault.Delete(ctx, "dispatch/vmware/api-key"))
+
+	secrets, err := secretsService.GetSecrets(ctx, "vmware", entitystore.Options{})
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+	assert.Equal(t, "psql-creds", *secrets[0].Name)
+	assert.Equal(t, "psql-creds", secrets[0].Secrets["key"])
+}
+
+func TestVaultAddSecretFailure(t *testing.T) {
+	secretsService, _, closer := setupVault(t)
+	ctx := context.Background()
+
+	// vault is unreachable, the entity must not be left behind
+	closer()
+	_, err := secretsService.AddSecret(ctx, "vmware", dispatchv1.Secret{
+		Name:    swag.String("psql-creds"),
+		Secrets: dispatchv1.SecretValue{"password": "iml8_iml8"},
+	})
+	assert.Error(t, err)
+
+	_, err = secretsService.GetSecret(ctx, "vmware", "psql-creds", entitystore.Options{})
+	assert.IsType(t, SecretNotFound{}, err)
+}
+
+func TestVaultSecretVersions(t *testing.T) {
+	secretsService, _, closer := setupVault(t)
+	defer closer()
+	ctx := context.Background()
+
+	_, err := secretsService.AddSecret(ctx, "vmware", dispatchv1.Secret{
+		Name:    swag.String("psql-creds"),
+		Secrets: dispatchv1.SecretValue{"password": "v1"},
+	})
+	require.NoError(t, err)
+	updated, err := secretsService.UpdateSecret(ctx, "vmware", dispatchv1.Secret{
+		Name:    swag.String("psql-creds"),
+		Secrets: dispatchv1.SecretValue{"password": "v2"},
+	}, entitystore.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), updated.Version)
+
+	versions, err := secretsService.GetSecretVersions(ctx, "vmware", "psql-creds", entitystore.Options{})
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.False(t, versions[0].Current)
+	assert.True(t, versions[1].Current)
+
+	secret, err := secretsService.GetSecretVersion(ctx, "vmware", "psql-creds", 1, entitystore.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "v1", secret.Secrets["password"])
+	_, err = secretsService.GetSecretVersion(ctx, "vmware", "psql-creds", 3, entitystore.Options{})
+	assert.IsType(t, SecretVersionNotFound{}, err)
+
+	rolledBack, err := secretsService.RollbackSecret(ctx, "vmware", "psql-creds", 1, entitystore.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), rolledBack.Version)
+	assert.Equal(t, "v1", rolledBack.Secrets["password"])
+}
@@ -0,0 +1,395 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/trace"
+)
+
+const (
+	tokenHeader = "X-Vault-Token"
+
+	defaultMount        = "secret"
+	defaultAppRoleMount = "approle"
+)
+
+// Config represents the configuration of a vault client
+type Config struct {
+	// Address of the vault server, e.g. https://vault:8200
+	Address string
+	// Token used to authenticate, if empty the AppRole credentials are used
+	Token string
+	// AppRole credentials
+	RoleID       string
+	SecretID     string
+	AppRoleMount string
+	// Mount is the path the KV v2 secrets engine is mounted at
+	Mount string
+}
+
+// NotFoundError is returned when a secret (or secret version) does not exist
+type NotFoundError struct {
+	Path string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("vault: %s not found", e.Path)
+}
+
+// ResponseError is returned when vault answers with an unexpected status code
+type ResponseError struct {
+	StatusCode int
+	Errors     []string
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("vault: unexpected status code %d: %s", e.StatusCode, strings.Join(e.Errors, ", "))
+}
+
+// Secret is a version of a KV secret
+type Secret struct {
+	Data        map[string]string
+	Version     int
+	CreatedTime time.Time
+}
+
+// Version describes a version of a KV secret
+type Version struct {
+	Version     int
+	CreatedTime time.Time
+	Deleted     bool
+}
+
+// Client is a minimal client for the vault KV v2 and transit secrets engines
+type Client struct {
+	address      string
+	mount        string
+	roleID       string
+	secretID     string
+	appRoleMount string
+	httpClient   *http.Client
+
+	sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewClient creates a new vault client
+func NewClient(config *Config) (*Client, error) {
+	if config.Address == "" {
+		return nil, errors.New("vault address is required")
+	}
+	if config.Token == "" && (config.RoleID == "" || config.SecretID == "") {
+		return nil, errors.New("vault token or approle credentials are required")
+	}
+	c := &Client{
+		address:      strings.TrimSuffix(config.Address, "/"),
+		mount:        strings.Trim(config.Mount, "/"),
+		roleID:       config.RoleID,
+		secretID:     config.SecretID,
+		appRoleMount: strings.Trim(config.AppRoleMount, "/"),
+		token:        config.Token,
+		httpClient:   http.DefaultClient,
+	}
+	if c.mount == "" {
+		c.mount = defaultMount
+	}
+	if c.appRoleMount == "" {
+		c.appRoleMount = defaultAppRoleMount
+	}
+	return c, nil
+}
+
+func (c *Client) usesAppRole() bool {
+	return c.roleID != ""
+}
+
+// login authenticates through AppRole, and stores the issued token
+func (c *Client) login(ctx context.Context) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	body := map[string]string{"role_id": c.roleID, "secret_id": c.secretID}
+	var result struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int    `json:"lease_duration"`
+		} `json:"auth"`
+	}
+	status, err := c.request(ctx, "POST", fmt.Sprintf("/v1/auth/%s/login", c.appRoleMount), "", body, &result)
+	if err != nil {
+		return errors.Wrap(err, "vault approle login failed")
+	}
+	if status != http.StatusOK || result.Auth.ClientToken == "" {
+		return errors.Errorf("vault approle login failed with status code %d", status)
+	}
+	c.token = result.Auth.ClientToken
+	c.tokenExpiry = time.Time{}
+	if result.Auth.LeaseDuration > 0 {
+		// renew a little before the lease runs out
+		c.tokenExpiry = time.Now().Add(time.Duration(result.Auth.LeaseDuration) * time.Second * 9 / 10)
+	}
+	log.Debugf("vault: logged in through approle, token lease %ds", result.Auth.LeaseDuration)
+	return nil
+}
+
+// currentToken returns a valid token, logging in first if needed
+func (c *Client) currentToken(ctx context.Context, forceLogin bool) (string, error) {
+	c.Lock()
+	defer c.Unlock()
+	if c.usesAppRole() && (forceLogin || c.token == "" || (!c.tokenExpiry.IsZero() && time.Now().After(c.tokenExpiry))) {
+		if err := c.login(ctx); err != nil {
+			return "", err
+		}
+	}
+	return c.token, nil
+}
+
+// do sends an authenticated request, logging in again once if the token got rejected
+func (c *Client) do(ctx context.Context, method, path string, body, result interface{}) (int, error) {
+	token, err := c.currentToken(ctx, false)
+	if err != nil {
+		return 0, err
+	}
+	status, err := c.request(ctx, method, path, token, body, result)
+	if status == http.StatusForbidden && c.usesAppRole() {
+		if token, err = c.currentToken(ctx, true); err != nil {
+			return 0, err
+		}
+		status, err = c.request(ctx, method, path, token, body, result)
+	}
+	return status, err
+}
+
+func (c *Client) request(ctx context.Context, method, path, token string, body, result interface{}) (int, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return 0, errors.Wrap(err, "error marshalling vault request")
+		}
+		reader = bytes.NewReader(b)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, c.address+path, reader)
+	if err != nil {
+		return 0, errors.Wrap(err, "error creating vault request")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set(tokenHeader, token)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, errors.Wrap(err, "error sending vault request")
+	}
+	defer resp.Body.Close()
+
+	log.Debugf("vault: %s %s: status code: %d", method, path, resp.StatusCode)
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, errors.Wrap(err, "error reading vault response")
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusNoContent:
+		return resp.StatusCode, nil
+	case resp.StatusCode >= 400:
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		json.Unmarshal(b, &e)
+		return resp.StatusCode, &ResponseError{StatusCode: resp.StatusCode, Errors: e.Errors}
+	}
+	if result != nil && len(b) > 0 {
+		if err := json.Unmarshal(b, result); err != nil {
+			return resp.StatusCode, errors.Wrap(err, "error decoding vault response")
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+func (c *Client) dataPath(path string) string {
+	return fmt.Sprintf("/v1/%s/data/%s", c.mount, strings.Trim(path, "/"))
+}
+
+func (c *Client) metadataPath(path string) string {
+	return fmt.Sprintf("/v1/%s/metadata/%s", c.mount, strings.Trim(path, "/"))
+}
+
+// Read returns a version of the secret at path, version 0 being the current version
+func (c *Client) Read(ctx context.Context, path string, version int) (*Secret, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	p := c.dataPath(path)
+	if version > 0 {
+		p += "?" + url.Values{"version": []string{strconv.Itoa(version)}}.Encode()
+	}
+	var result struct {
+		Data struct {
+			Data     map[string]string `json:"data"`
+			Metadata struct {
+				Version      int       `json:"version"`
+				CreatedTime  time.Time `json:"created_time"`
+				DeletionTime string    `json:"deletion_time"`
+				Destroyed    bool      `json:"destroyed"`
+			} `json:"metadata"`
+		} `json:"data"`
+	}
+	status, err := c.do(ctx, "GET", p, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	// deleted versions are reported as 404 (with their metadata) by vault
+	if status == http.StatusNotFound || result.Data.Data == nil {
+		return nil, &NotFoundError{Path: path}
+	}
+	return &Secret{
+		Data:        result.Data.Data,
+		Version:     result.Data.Metadata.Version,
+		CreatedTime: result.Data.Metadata.CreatedTime,
+	}, nil
+}
+
+// Write stores data as a new version of the secret at path, and returns the version number
+func (c *Client) Write(ctx context.Context, path string, data map[string]string) (int, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	if data == nil {
+		data = map[string]string{}
+	}
+	var result struct {
+		Data struct {
+			Version int `json:"version"`
+		} `json:"data"`
+	}
+	if _, err := c.do(ctx, "PUT", c.dataPath(path), map[string]interface{}{"data": data}, &result); err != nil {
+		return 0, err
+	}
+	return result.Data.Version, nil
+}
+
+// Versions lists the versions of the secret at path, oldest first
+func (c *Client) Versions(ctx context.Context, path string) ([]Version, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	var result struct {
+		Data struct {
+			Versions map[string]struct {
+				CreatedTime  time.Time `json:"created_time"`
+				DeletionTime string    `json:"deletion_time"`
+				Destroyed    bool      `json:"destroyed"`
+			} `json:"versions"`
+		} `json:"data"`
+	}
+	status, err := c.do(ctx, "GET", c.metadataPath(path), nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, &NotFoundError{Path: path}
+	}
+	var versions []Version
+	for k, v := range result.Data.Versions {
+		n, err := strconv.Atoi(k)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid version %s of %s", k, path)
+		}
+		versions = append(versions, Version{
+			Version:     n,
+			CreatedTime: v.CreatedTime,
+			Deleted:     v.DeletionTime != "" || v.Destroyed,
+		})
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	return versions, nil
+}
+
+// Delete permanently deletes the secret at path, with all its versions
+func (c *Client) Delete(ctx context.Context, path string) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	status, err := c.do(ctx, "DELETE", c.metadataPath(path), nil, nil)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound {
+		return &NotFoundError{Path: path}
+	}
+	return nil
+}
+
+func (c *Client) transitPath(mount, operation, key string) string {
+	return fmt.Sprintf("/v1/%s/%s/%s", strings.Trim(mount, "/"), operation, key)
+}
+
+// Encrypt encrypts plaintext with the named key of the transit secrets engine mounted at mount
+func (c *Client) Encrypt(ctx context.Context, mount, key string, plaintext []byte) (string, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	var result struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(plaintext)}
+	status, err := c.do(ctx, "POST", c.transitPath(mount, "encrypt", key), body, &result)
+	if err != nil {
+		return "", err
+	}
+	if status == http.StatusNotFound {
+		return "", &NotFoundError{Path: mount + "/" + key}
+	}
+	return result.Data.Ciphertext, nil
+}
+
+// Decrypt decrypts a ciphertext returned by Encrypt, with the named key of the transit secrets engine mounted at mount
+func (c *Client) Decrypt(ctx context.Context, mount, key string, ciphertext string) ([]byte, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	var result struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"ciphertext": ciphertext}
+	status, err := c.do(ctx, "POST", c.transitPath(mount, "decrypt", key), body, &result)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, &NotFoundError{Path: mount + "/" + key}
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(result.Data.Plaintext)
+	if err != nil {
+		return nil, errors.Wrap(err, "error decoding vault transit plaintext")
+	}
+	return plaintext, nil
+}
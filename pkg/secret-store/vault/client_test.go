@@ -0,0 +1,121 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package vault
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	fakevault "github.com/vmware/dispatch/pkg/testing/vault"
+)
+
+func TestNewClientValidation(t *testing.T) {
+	_, err := NewClient(&Config{Token: "root"})
+	assert.Error(t, err)
+	_, err = NewClient(&Config{Address: "http://vault:8200"})
+	assert.Error(t, err)
+	_, err = NewClient(&Config{Address: "http://vault:8200", RoleID: "role"})
+	assert.Error(t, err)
+}
+
+func TestKVVersions(t *testing.T) {
+	server := httptest.NewServer(fakevault.NewFakeVault("root"))
+	defer server.Close()
+
+	c, err := NewClient(&Config{Address: server.URL, Token: "root"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = c.Read(ctx, "dispatch/org/db", 0)
+	assert.IsType(t, &NotFoundError{}, err)
+
+	v, err := c.Write(ctx, "dispatch/org/db", map[string]string{"password": "one"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+	v, err = c.Write(ctx, "dispatch/org/db", map[string]string{"password": "two"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, v)
+
+	secret, err := c.Read(ctx, "dispatch/org/db", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "two", secret.Data["password"])
+	assert.Equal(t, 2, secret.Version)
+
+	secret, err = c.Read(ctx, "dispatch/org/db", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "one", secret.Data["password"])
+
+	versions, err := c.Versions(ctx, "dispatch/org/db")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, 1, versions[0].Version)
+	assert.Equal(t, 2, versions[1].Version)
+
+	require.NoError(t, c.Delete(ctx, "dispatch/org/db"))
+	_, err = c.Read(ctx, "dispatch/org/db", 0)
+	assert.IsType(t, &NotFoundError{}, err)
+}
+
+func TestAppRoleLogin(t *testing.T) {
+	fake := fakevault.NewFakeVault("root")
+	fake.RoleID = "role"
+	fake.SecretID = "secret"
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	c, err := NewClient(&Config{Address: server.URL, RoleID: "role", SecretID: "secret"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = c.Write(ctx, "dispatch/org/db", map[string]string{"password": "one"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, fake.Logins())
+
+	// a revoked token is replaced transparently
+	fake.RevokeTokens()
+	_, err = c.Read(ctx, "dispatch/org/db", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fake.Logins())
+
+	bad, err := NewClient(&Config{Address: server.URL, RoleID: "role", SecretID: "wrong"})
+	require.NoError(t, err)
+	_, err = bad.Read(ctx, "dispatch/org/db", 0)
+	assert.Error(t, err)
+}
+
+func TestTokenRejected(t *testing.T) {
+	server := httptest.NewServer(fakevault.NewFakeVault("root"))
+	defer server.Close()
+
+	c, err := NewClient(&Config{Address: server.URL, Token: "wrong"})
+	require.NoError(t, err)
+	_, err = c.Read(context.Background(), "dispatch/org/db", 0)
+	assert.IsType(t, &ResponseError{}, err)
+}
+
+func TestTransit(t *testing.T) {
+	server := httptest.NewServer(fakevault.NewFakeVault("root"))
+	defer server.Close()
+
+	c, err := NewClient(&Config{Address: server.URL, Token: "root"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	ciphertext, err := c.Encrypt(ctx, "transit", "dispatch", []byte("data key"))
+	require.NoError(t, err)
+	assert.Contains(t, ciphertext, "vault:v1:")
+
+	plaintext, err := c.Decrypt(ctx, "transit", "dispatch", ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "data key", string(plaintext))
+
+	_, err = c.Decrypt(ctx, "transit", "other", ciphertext)
+	assert.IsType(t, &ResponseError{}, err)
+}
@@ -8,8 +8,10 @@
 package web
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/go-openapi/swag"
@@ -23,10 +25,17 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
 	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	secretstore "github.com/vmware/dispatch/pkg/secret-store"
+	"github.com/vmware/dispatch/pkg/secret-store/access"
+	"github.com/vmware/dispatch/pkg/secret-store/encryption"
 	"github.com/vmware/dispatch/pkg/secret-store/gen/restapi/operations"
 	"github.com/vmware/dispatch/pkg/secret-store/gen/restapi/operations/secret"
+	"github.com/vmware/dispatch/pkg/secret-store/kms"
+	"github.com/vmware/dispatch/pkg/secret-store/rotation"
 	"github.com/vmware/dispatch/pkg/secret-store/service"
+	"github.com/vmware/dispatch/pkg/secret-store/vault"
 	"github.com/vmware/dispatch/pkg/trace"
 	"github.com/vmware/dispatch/pkg/utils"
 )
@@ -41,10 +50,38 @@ var SecretStoreFlags = struct {
 	DbPassword   string `long:"db-password" description:"Backend DB Password" default:"dispatch"`
 	DbDatabase   string `long:"db-database" description:"Backend DB Name" default:"dispatch"`
 	Tracer       string `long:"tracer" description:"Open Tracing Tracer endpoint" default:""`
+
+	SecretsBackend    string `long:"secrets-backend" description:"Backend storing secret values (kubernetes, vault)" default:"kubernetes"`
+	VaultAddress      string `long:"vault-address" description:"Vault server address" default:"http://localhost:8200"`
+	VaultToken        string `long:"vault-token" description:"Vault token, if empty AppRole is used" env:"VAULT_TOKEN"`
+	VaultRoleID       string `long:"vault-role-id" description:"Vault AppRole role ID"`
+	VaultSecretID     string `long:"vault-secret-id" description:"Vault AppRole secret ID" env:"VAULT_SECRET_ID"`
+	VaultAppRoleMount string `long:"vault-approle-mount" description:"Path the Vault AppRole auth method is mounted at" default:"approle"`
+	VaultMount        string `long:"vault-mount" description:"Path the Vault KV v2 secrets engine is mounted at" default:"secret"`
+	VaultPathPrefix   string `long:"vault-path-prefix" description:"Prefix of the Vault paths secrets are stored under, followed by the organization" default:"dispatch"`
+
+	MaxSecretVersions   int    `long:"max-secret-versions" description:"Number of previous versions kept for each secret with the kubernetes backend, 0 keeps all of them" default:"10"`
+	FunctionManager     string `long:"function-manager" description:"Function manager endpoint, running the secret rotation functions" default:"localhost:8001"`
+	RotationCheckPeriod int    `long:"rotation-check-period" description:"How often secrets are checked for due rotations, in seconds" default:"60"`
+
+	EncryptionKMS          string `long:"encryption-kms" description:"KMS encrypting the data keys of the secrets stored in kubernetes (local, vault), empty disables encryption"`
+	EncryptionKeyfile      string `long:"encryption-keyfile" description:"Path to the keyfile holding the master keys of the local KMS"`
+	EncryptionTransitMount string `long:"encryption-transit-mount" description:"Path the Vault transit secrets engine is mounted at, for the vault KMS" default:"transit"`
+	EncryptionTransitKey   string `long:"encryption-transit-key" description:"Name of the Vault transit key, for the vault KMS" default:"dispatch"`
+	DataKeyMaxAge          int    `long:"data-key-max-age" description:"Age after which the data keys are rotated, in hours, 0 disables rotation" default:"720"`
+
+	AuditRetention int `long:"audit-retention" description:"How long the reads of secret values are kept in the audit, in hours, 0 keeps them forever" default:"720"`
 }{}
 
 // Handlers encapsulates the secret store handlers
 type Handlers struct {
+	// Rotator rotates the secrets when they are due, it must be started separately
+	Rotator *rotation.Rotator
+	// KeyRotator rotates the data keys when they are due, nil if encryption is disabled. It must be started separately
+	KeyRotator *encryption.KeyRotator
+	// Auditor records the reads of secret values, its pruning of the old records must be started separately
+	Auditor *access.Auditor
+
 	secretsService service.SecretsService
 	entityStore    entitystore.EntityStore
 	k8snamespace   string
@@ -53,6 +90,90 @@ type Handlers struct {
 // NewHandlers create new handlers for secret store
 func NewHandlers(entityStore entitystore.EntityStore) (*Handlers, error) {
 	handlers := new(Handlers)
+	switch SecretStoreFlags.SecretsBackend {
+	case "kubernetes":
+		secretsService, err := newK8sSecretsService(entityStore)
+		if err != nil {
+			return nil, err
+		}
+		handlers.secretsService = secretsService
+		if SecretStoreFlags.EncryptionKMS != "" {
+			keyring, err := newKeyring(entityStore)
+			if err != nil {
+				return nil, err
+			}
+			secretsService.Keyring = keyring
+			maxAge := time.Duration(SecretStoreFlags.DataKeyMaxAge) * time.Hour
+			period := time.Duration(SecretStoreFlags.RotationCheckPeriod) * time.Second
+			handlers.KeyRotator = encryption.NewKeyRotator(keyring, secretsService, maxAge, period)
+		}
+	case "vault":
+		// vault encrypts the secrets at rest itself
+		secretsService, err := newVaultSecretsService(entityStore)
+		if err != nil {
+			return nil, err
+		}
+		handlers.secretsService = secretsService
+	default:
+		return nil, errors.Errorf("unknown secrets backend %s", SecretStoreFlags.SecretsBackend)
+	}
+	functions := client.NewFunctionsClient(SecretStoreFlags.FunctionManager, client.AuthWithToken("cookie"), "")
+	period := time.Duration(SecretStoreFlags.RotationCheckPeriod) * time.Second
+	handlers.Rotator = rotation.NewRotator(entityStore, handlers.secretsService, functions, period)
+	handlers.Auditor = access.NewAuditor(entityStore, time.Duration(SecretStoreFlags.AuditRetention)*time.Hour, time.Hour)
+	handlers.entityStore = entityStore
+	return handlers, nil
+}
+
+func newVaultClient() (*vault.Client, error) {
+	client, err := vault.NewClient(&vault.Config{
+		Address:      SecretStoreFlags.VaultAddress,
+		Token:        SecretStoreFlags.VaultToken,
+		RoleID:       SecretStoreFlags.VaultRoleID,
+		SecretID:     SecretStoreFlags.VaultSecretID,
+		AppRoleMount: SecretStoreFlags.VaultAppRoleMount,
+		Mount:        SecretStoreFlags.VaultMount,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating vault client")
+	}
+	return client, nil
+}
+
+func newKeyring(entityStore entitystore.EntityStore) (*encryption.Keyring, error) {
+	var masterKMS kms.KMS
+	switch SecretStoreFlags.EncryptionKMS {
+	case "local":
+		localKMS, err := kms.NewLocalKMS(SecretStoreFlags.EncryptionKeyfile)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error creating local KMS")
+		}
+		masterKMS = localKMS
+	case "vault":
+		client, err := newVaultClient()
+		if err != nil {
+			return nil, err
+		}
+		masterKMS = kms.NewVaultKMS(client, SecretStoreFlags.EncryptionTransitMount, SecretStoreFlags.EncryptionTransitKey)
+	default:
+		return nil, errors.Errorf("unknown encryption KMS %s", SecretStoreFlags.EncryptionKMS)
+	}
+	return encryption.NewKeyring(entityStore, masterKMS), nil
+}
+
+func newVaultSecretsService(entityStore entitystore.EntityStore) (service.SecretsService, error) {
+	client, err := newVaultClient()
+	if err != nil {
+		return nil, err
+	}
+	return &service.VaultSecretsService{
+		EntityStore: entityStore,
+		Vault:       client,
+		PathPrefix:  SecretStoreFlags.VaultPathPrefix,
+	}, nil
+}
+
+func newK8sSecretsService(entityStore entitystore.EntityStore) (*service.K8sSecretsService, error) {
 	var err error
 	var config *rest.Config
 	if SecretStoreFlags.K8sConfig == "" {
@@ -70,12 +191,11 @@ func NewHandlers(entityStore entitystore.EntityStore) (*Handlers, error) {
 		return nil, errors.Wrap(err, "Error creating kubernetes client")
 	}
 
-	handlers.secretsService = &service.K8sSecretsService{
+	return &service.K8sSecretsService{
 		EntityStore: entityStore,
 		SecretsAPI:  clientset.CoreV1().Secrets(SecretStoreFlags.K8sNamespace),
-	}
-
-	return handlers, nil
+		MaxVersions: SecretStoreFlags.MaxSecretVersions,
+	}, nil
 }
 
 // ConfigureHandlers registers secret store handlers to the API
@@ -103,6 +223,38 @@ func ConfigureHandlers(api middleware.RoutableAPI, h *Handlers) {
 	a.SecretGetSecretHandler = secret.GetSecretHandlerFunc(h.getSecret)
 	a.SecretDeleteSecretHandler = secret.DeleteSecretHandlerFunc(h.deleteSecret)
 	a.SecretUpdateSecretHandler = secret.UpdateSecretHandlerFunc(h.updateSecret)
+	a.SecretGetSecretVersionsHandler = secret.GetSecretVersionsHandlerFunc(h.getSecretVersions)
+	a.SecretGetSecretVersionHandler = secret.GetSecretVersionHandlerFunc(h.getSecretVersion)
+	a.SecretRollbackSecretHandler = secret.RollbackSecretHandlerFunc(h.rollbackSecret)
+	a.SecretRotateSecretHandler = secret.RotateSecretHandlerFunc(h.rotateSecret)
+	a.SecretGetSecretAuditHandler = secret.GetSecretAuditHandlerFunc(h.getSecretAudit)
+}
+
+// requester returns the principals a request is made for, and its purpose (see client.HeaderRequester)
+func requester(r *http.Request) ([]string, string) {
+	return client.ParseRequesters(r.Header.Get(client.HeaderRequester)), r.Header.Get(client.HeaderPurpose)
+}
+
+// read checks whether the requester of a request may read the values of the secret, and records the read in the audit
+func (h *Handlers) read(ctx context.Context, r *http.Request, organizationID string, vmwSecret *v1.Secret) (bool, error) {
+	requesters, purpose := requester(r)
+	allowed := access.Allowed(vmwSecret, requesters)
+	if err := h.Auditor.Record(ctx, organizationID, vmwSecret, requesters, purpose, allowed); err != nil {
+		return false, errors.Wrapf(err, "error recording the read of secret %s", *vmwSecret.Name)
+	}
+	return allowed, nil
+}
+
+// redact removes the values of the secret if the requester may not read them, they are returned otherwise and the
+// read is recorded in the audit
+func (h *Handlers) redact(ctx context.Context, r *http.Request, organizationID string, vmwSecret *v1.Secret) error {
+	requesters, _ := requester(r)
+	if !access.Allowed(vmwSecret, requesters) {
+		vmwSecret.Secrets = nil
+		return nil
+	}
+	_, err := h.read(ctx, r, organizationID, vmwSecret)
+	return err
 }
 
 func (h *Handlers) addSecret(params secret.AddSecretParams, principal interface{}) middleware.Responder {
@@ -131,7 +283,12 @@ func (h *Handlers) getSecrets(params secret.GetSecretsParams, principal interfac
 	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
 	defer span.Finish()
 
+	opts := entitystore.Options{}
 	filter, err := utils.ParseTags(nil, params.Tags)
+	if err == nil {
+		opts.Filter = filter
+		err = utils.ParsePaging(&opts, params.Limit, params.Continue, params.Sort)
+	}
 	if err != nil {
 		log.Errorf(err.Error())
 		return secret.NewGetSecretsBadRequest().WithPayload(
@@ -141,9 +298,7 @@ func (h *Handlers) getSecrets(params secret.GetSecretsParams, principal interfac
 			})
 	}
 
-	vmwSecrets, err := h.secretsService.GetSecrets(ctx, params.XDispatchOrg, entitystore.Options{
-		Filter: filter,
-	})
+	vmwSecrets, err := h.secretsService.GetSecrets(ctx, params.XDispatchOrg, opts)
 	if err != nil {
 		log.Errorf("error when listing secrets from k8s APIs: %+v", err)
 		return secret.NewGetSecretsDefault(http.StatusInternalServerError).WithPayload(&v1.Error{
@@ -151,8 +306,17 @@ func (h *Handlers) getSecrets(params secret.GetSecretsParams, principal interfac
 			Message: swag.String("internal server error when listing secrets from k8s APIs"),
 		})
 	}
+	for _, vmwSecret := range vmwSecrets {
+		if err := h.redact(ctx, params.HTTPRequest, params.XDispatchOrg, vmwSecret); err != nil {
+			log.Errorf("error when listing secrets: %+v", err)
+			return secret.NewGetSecretsDefault(http.StatusInternalServerError).WithPayload(&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String("internal server error when listing secrets"),
+			})
+		}
+	}
 
-	return secret.NewGetSecretsOK().WithPayload(vmwSecrets)
+	return secret.NewGetSecretsOK().WithPayload(vmwSecrets).WithXDispatchContinue(utils.NextPage(opts))
 }
 
 func (h *Handlers) getSecret(params secret.GetSecretParams, principal interface{}) middleware.Responder {
@@ -184,6 +348,21 @@ func (h *Handlers) getSecret(params secret.GetSecretParams, principal interface{
 		})
 	}
 
+	allowed, err := h.read(ctx, params.HTTPRequest, params.XDispatchOrg, vmwSecret)
+	if err != nil {
+		log.Errorf("error when reading the secret: %+v", err)
+		return secret.NewGetSecretDefault(http.StatusInternalServerError).WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when reading the secret"),
+		})
+	}
+	if !allowed {
+		return secret.NewGetSecretForbidden().WithPayload(&v1.Error{
+			Code:    http.StatusForbidden,
+			Message: swag.String(fmt.Sprintf("Not allowed to read secret: %s", params.SecretName)),
+		})
+	}
+
 	return secret.NewGetSecretOK().WithPayload(vmwSecret)
 }
 
@@ -254,3 +433,174 @@ func (h *Handlers) deleteSecret(params secret.DeleteSecretParams, principal inte
 	}
 	return secret.NewDeleteSecretNoContent()
 }
+
+func (h *Handlers) getSecretVersions(params secret.GetSecretVersionsParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	versions, err := h.secretsService.GetSecretVersions(ctx, params.XDispatchOrg, params.SecretName, entitystore.Options{})
+	if err != nil {
+		if _, ok := err.(service.SecretNotFound); ok {
+			return secret.NewGetSecretVersionsNotFound().WithPayload(&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String(fmt.Sprintf("Could not find secret: %s", params.SecretName)),
+			})
+		}
+
+		log.Errorf("error when listing the versions of the secret: %+v", err)
+		return secret.NewGetSecretVersionsDefault(http.StatusInternalServerError).WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when listing the versions of the secret"),
+		})
+	}
+
+	return secret.NewGetSecretVersionsOK().WithPayload(versions)
+}
+
+func (h *Handlers) getSecretVersion(params secret.GetSecretVersionParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	vmwSecret, err := h.secretsService.GetSecretVersion(ctx, params.XDispatchOrg, params.SecretName, params.Version, entitystore.Options{})
+	if err != nil {
+		switch err.(type) {
+		case service.SecretNotFound:
+			return secret.NewGetSecretVersionNotFound().WithPayload(&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String(fmt.Sprintf("Could not find secret: %s", params.SecretName)),
+			})
+		case service.SecretVersionNotFound:
+			return secret.NewGetSecretVersionNotFound().WithPayload(&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String(fmt.Sprintf("Could not find version %d of secret: %s", params.Version, params.SecretName)),
+			})
+		}
+
+		log.Errorf("error when reading the secret version: %+v", err)
+		return secret.NewGetSecretVersionDefault(http.StatusInternalServerError).WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when reading the secret version"),
+		})
+	}
+
+	allowed, err := h.read(ctx, params.HTTPRequest, params.XDispatchOrg, vmwSecret)
+	if err != nil {
+		log.Errorf("error when reading the secret version: %+v", err)
+		return secret.NewGetSecretVersionDefault(http.StatusInternalServerError).WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when reading the secret version"),
+		})
+	}
+	if !allowed {
+		return secret.NewGetSecretVersionForbidden().WithPayload(&v1.Error{
+			Code:    http.StatusForbidden,
+			Message: swag.String(fmt.Sprintf("Not allowed to read secret: %s", params.SecretName)),
+		})
+	}
+
+	return secret.NewGetSecretVersionOK().WithPayload(vmwSecret)
+}
+
+func (h *Handlers) rollbackSecret(params secret.RollbackSecretParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	vmwSecret, err := h.secretsService.RollbackSecret(ctx, params.XDispatchOrg, params.SecretName, params.Version, entitystore.Options{})
+	if err != nil {
+		switch err.(type) {
+		case service.SecretNotFound:
+			return secret.NewRollbackSecretNotFound().WithPayload(&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String(fmt.Sprintf("Could not find secret: %s", params.SecretName)),
+			})
+		case service.SecretVersionNotFound:
+			return secret.NewRollbackSecretNotFound().WithPayload(&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String(fmt.Sprintf("Could not find version %d of secret: %s", params.Version, params.SecretName)),
+			})
+		}
+
+		log.Errorf("error when rolling back the secret: %+v", err)
+		return secret.NewRollbackSecretDefault(http.StatusInternalServerError).WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when rolling back the secret"),
+		})
+	}
+	if err := h.redact(ctx, params.HTTPRequest, params.XDispatchOrg, vmwSecret); err != nil {
+		log.Errorf("error when rolling back the secret: %+v", err)
+		return secret.NewRollbackSecretDefault(http.StatusInternalServerError).WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when rolling back the secret"),
+		})
+	}
+
+	return secret.NewRollbackSecretOK().WithPayload(vmwSecret)
+}
+
+func (h *Handlers) rotateSecret(params secret.RotateSecretParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	vmwSecret, err := h.Rotator.Rotate(ctx, params.XDispatchOrg, params.SecretName)
+	if err != nil {
+		switch err.(type) {
+		case service.SecretNotFound:
+			return secret.NewRotateSecretNotFound().WithPayload(&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String(fmt.Sprintf("Could not find secret: %s", params.SecretName)),
+			})
+		case rotation.NotConfigured:
+			return secret.NewRotateSecretBadRequest().WithPayload(&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+		}
+
+		log.Errorf("error when rotating the secret: %+v", err)
+		return secret.NewRotateSecretDefault(http.StatusInternalServerError).WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String(fmt.Sprintf("error when rotating the secret: %s", err)),
+		})
+	}
+	if err := h.redact(ctx, params.HTTPRequest, params.XDispatchOrg, vmwSecret); err != nil {
+		log.Errorf("error when rotating the secret: %+v", err)
+		return secret.NewRotateSecretDefault(http.StatusInternalServerError).WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when rotating the secret"),
+		})
+	}
+
+	return secret.NewRotateSecretOK().WithPayload(vmwSecret)
+}
+
+func (h *Handlers) getSecretAudit(params secret.GetSecretAuditParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	entity := secretstore.SecretEntity{}
+	found, err := h.entityStore.Find(ctx, params.XDispatchOrg, params.SecretName, entitystore.Options{}, &entity)
+	if err != nil {
+		log.Errorf("error when finding the secret: %+v", err)
+		return secret.NewGetSecretAuditDefault(http.StatusInternalServerError).WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when listing the reads of the secret"),
+		})
+	}
+	if !found {
+		return secret.NewGetSecretAuditNotFound().WithPayload(&v1.Error{
+			Code:    http.StatusNotFound,
+			Message: swag.String(fmt.Sprintf("Could not find secret: %s", params.SecretName)),
+		})
+	}
+
+	reads, err := h.Auditor.List(ctx, params.XDispatchOrg, params.SecretName)
+	if err != nil {
+		log.Errorf("error when listing the reads of the secret: %+v", err)
+		return secret.NewGetSecretAuditDefault(http.StatusInternalServerError).WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when listing the reads of the secret"),
+		})
+	}
+
+	return secret.NewGetSecretAuditOK().WithPayload(reads)
+}
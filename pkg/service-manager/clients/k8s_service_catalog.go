@@ -8,7 +8,6 @@ package clients
 // NO TEST
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -18,6 +17,7 @@ import (
 	"github.com/vmware/dispatch/pkg/function-manager"
 
 	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 
 	"github.com/kubernetes-incubator/service-catalog/pkg/apis/servicecatalog/v1beta1"
 	"github.com/kubernetes-incubator/service-catalog/pkg/client/clientset_generated/clientset"
@@ -30,13 +30,13 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
-	dispatchv1 "github.com/vmware/dispatch/pkg/api/v1"
 	"github.com/vmware/dispatch/pkg/entity-store"
-	"github.com/vmware/dispatch/pkg/secret-store/gen/client/secret"
 	"github.com/vmware/dispatch/pkg/service-manager/entities"
-	"github.com/vmware/dispatch/pkg/service-manager/flags"
 )
 
+// organizationLabel labels the service instances and bindings with the organization they belong to
+const organizationLabel = "dispatch-organization"
+
 // K8sBrokerConfigOpts are k8s specific configuratation options
 type K8sBrokerConfigOpts struct {
 	K8sConfig        string
@@ -168,10 +168,11 @@ func (c *k8sServiceCatalogClient) ListServiceClasses() ([]entitystore.Entity, er
 				Name:   csc.Spec.ExternalName,
 				Status: entitystore.StatusREADY,
 			},
-			ServiceID: csc.Name,
-			Broker:    csc.Spec.ClusterServiceBrokerName,
-			Bindable:  csc.Spec.Bindable,
-			Plans:     serviceClassPlans,
+			ServiceID:     csc.Name,
+			Broker:        csc.Spec.ClusterServiceBrokerName,
+			Bindable:      csc.Spec.Bindable,
+			PlanUpdatable: csc.Spec.PlanUpdatable,
+			Plans:         serviceClassPlans,
 		})
 	}
 
@@ -197,13 +198,19 @@ func (c *k8sServiceCatalogClient) ListServiceInstances() ([]entitystore.Entity,
 		}
 		serviceInstance := &entities.ServiceInstance{
 			BaseEntity: entitystore.BaseEntity{
-				ID:     instance.ObjectMeta.Name,
-				Status: entitystore.StatusUNKNOWN,
+				OrganizationID: c.organization(instance.ObjectMeta),
+				ID:             instance.ObjectMeta.Name,
+				Status:         entitystore.StatusUNKNOWN,
 			},
 			ServiceClass: instance.Spec.ClusterServiceClassExternalName,
 			ServicePlan:  instance.Spec.ClusterServicePlanExternalName,
 			Parameters:   parameters,
 		}
+		if instance.Status.ReconciledGeneration != instance.Generation || instance.Status.AsyncOpInProgress {
+			// The spec changed and is not reconciled yet, the conditions are those of the previous spec
+			serviceInstances = append(serviceInstances, serviceInstance)
+			continue
+		}
 		for _, cond := range instance.Status.Conditions {
 			if cond.Type == v1beta1.ServiceInstanceConditionReady && cond.Status == v1beta1.ConditionTrue {
 				serviceInstance.Status = entitystore.StatusREADY
@@ -256,7 +263,8 @@ func (c *k8sServiceCatalogClient) ListServiceBindings() ([]entitystore.Entity, e
 		}
 		serviceBinding := &entities.ServiceBinding{
 			BaseEntity: entitystore.BaseEntity{
-				Status: entitystore.StatusUNKNOWN,
+				OrganizationID: c.organization(binding.ObjectMeta),
+				Status:         entitystore.StatusUNKNOWN,
 			},
 			Parameters: parameters,
 			BindingID:  binding.Name,
@@ -276,7 +284,7 @@ func (c *k8sServiceCatalogClient) ListServiceBindings() ([]entitystore.Entity, e
 					secrets[k] = string(v)
 				}
 				// This is a hack... we shouldn't be setting secrets on a list method
-				err = c.setSecret(flags.ServiceManagerFlags.OrgID, binding.Name, secrets)
+				err = c.setSecret(serviceBinding.OrganizationID, binding.Name, secrets)
 				if err != nil {
 					// Log the error and continue
 					log.Errorf("Error setting secret for binding %s: %v", binding.Name, err)
@@ -301,7 +309,7 @@ func (c *k8sServiceCatalogClient) ListServiceBindings() ([]entitystore.Entity, e
 
 // CreateService provisions a service, creating a service instance.
 func (c *k8sServiceCatalogClient) CreateService(class *entities.ServiceClass, service *entities.ServiceInstance) error {
-	secrets, err := c.getSecrets(flags.ServiceManagerFlags.OrgID, service.SecretParameters)
+	secrets, err := c.getSecrets(service.OrganizationID, service.SecretParameters)
 	if err != nil {
 		service.SetStatus(entitystore.StatusERROR)
 		return errors.Wrapf(err, "Error fetching secrets for provisioning service %s of class %s with plan %s", service.Name, service.ServiceClass, service.ServicePlan)
@@ -313,6 +321,7 @@ func (c *k8sServiceCatalogClient) CreateService(class *entities.ServiceClass, se
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      service.ID,
 			Namespace: c.config.CatalogNamespace,
+			Labels:    map[string]string{organizationLabel: service.OrganizationID},
 		},
 		Spec: v1beta1.ServiceInstanceSpec{
 			PlanReference: v1beta1.PlanReference{
@@ -333,10 +342,40 @@ func (c *k8sServiceCatalogClient) CreateService(class *entities.ServiceClass, se
 	return nil
 }
 
+// UpdateService updates the plan and parameters of a service instance, the service is being updated until the
+// Service Catalog reconciled the instance.
+func (c *k8sServiceCatalogClient) UpdateService(class *entities.ServiceClass, service *entities.ServiceInstance) error {
+	secrets, err := c.getSecrets(service.OrganizationID, service.SecretParameters)
+	if err != nil {
+		service.SetStatus(entitystore.StatusERROR)
+		return errors.Wrapf(err, "Error fetching secrets for updating service %s of class %s with plan %s", service.Name, service.ServiceClass, service.ServicePlan)
+	}
+
+	instances := c.sdk.ServiceCatalog().ServiceInstances(c.config.CatalogNamespace)
+	instance, err := instances.Get(service.InstanceID, metav1.GetOptions{})
+	if err != nil {
+		service.SetStatus(entitystore.StatusERROR)
+		return errors.Wrapf(err, "Error getting service instance %s", service.Name)
+	}
+
+	serviceParamsJSON, _ := json.Marshal(service.Parameters)
+
+	instance.Spec.ClusterServicePlanExternalName = service.ServicePlan
+	instance.Spec.ClusterServicePlanName = ""
+	instance.Spec.Parameters = &runtime.RawExtension{Raw: serviceParamsJSON}
+	instance.Spec.ParametersFrom = servicecatalog.BuildParametersFrom(secrets)
+	if _, err = instances.Update(instance); err != nil {
+		service.SetStatus(entitystore.StatusERROR)
+		return errors.Wrapf(err, "Error updating service %s of class %s to plan %s", service.Name, service.ServiceClass, service.ServicePlan)
+	}
+	service.SetStatus(entitystore.StatusUPDATING)
+	return nil
+}
+
 // CreateBinding creates a binding (credentials) for a service.
 func (c *k8sServiceCatalogClient) CreateBinding(service *entities.ServiceInstance, binding *entities.ServiceBinding) error {
 	log.Debugf("Creating service binding for service %+v and binding %+v", service, binding)
-	secrets, err := c.getSecrets(flags.ServiceManagerFlags.OrgID, binding.SecretParameters)
+	secrets, err := c.getSecrets(binding.OrganizationID, binding.SecretParameters)
 	if err != nil {
 		binding.SetStatus(entitystore.StatusERROR)
 		return errors.Wrapf(err, "Error fetching secrets for binding service %s of class %s with plan %s", service.Name, service.ServiceClass, service.ServicePlan)
@@ -344,10 +383,16 @@ func (c *k8sServiceCatalogClient) CreateBinding(service *entities.ServiceInstanc
 
 	bindingParamsJSON, _ := json.Marshal(binding.Parameters)
 
+	// every binding of a service is named uniquely, a binding bound again is given a new name
+	name := binding.ID
+	if binding.BindingID != "" {
+		name = uuid.NewV4().String()
+	}
 	b, err := c.sdk.ServiceCatalog().ServiceBindings(c.config.CatalogNamespace).Create(&v1beta1.ServiceBinding{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      service.ID,
+			Name:      name,
 			Namespace: c.config.CatalogNamespace,
+			Labels:    map[string]string{organizationLabel: binding.OrganizationID},
 		},
 		Spec: v1beta1.ServiceBindingSpec{
 			ServiceInstanceRef: v1beta1.LocalObjectReference{
@@ -368,6 +413,27 @@ func (c *k8sServiceCatalogClient) CreateBinding(service *entities.ServiceInstanc
 	return nil
 }
 
+// RefreshBinding binds a service again, once its plan or parameters changed or the binding is rotated. A new Service
+// Catalog binding is created as the pending binding, whose secret is copied when syncing the bindings. The binding
+// switches to it once ready, the previous Service Catalog binding is then deleted as an orphan.
+func (c *k8sServiceCatalogClient) RefreshBinding(service *entities.ServiceInstance, binding *entities.ServiceBinding) error {
+	if binding.BindingID == "" {
+		return c.CreateBinding(service, binding)
+	}
+	if binding.PendingBindingID != "" {
+		c.dropBinding(binding, binding.PendingBindingID)
+	}
+	previous := binding.BindingID
+	err := c.CreateBinding(service, binding)
+	binding.BindingID, binding.PendingBindingID = previous, binding.BindingID
+	if err != nil {
+		// the binding keeps the credentials it had before
+		binding.PendingBindingID = ""
+		return errors.Wrapf(err, "Error refreshing service binding %s", binding.Name)
+	}
+	return nil
+}
+
 // DeleteService deprovisions a service.
 func (c *k8sServiceCatalogClient) DeleteService(service *entities.ServiceInstance) error {
 	err := c.sdk.Deprovision(c.config.CatalogNamespace, service.ID)
@@ -379,12 +445,17 @@ func (c *k8sServiceCatalogClient) DeleteService(service *entities.ServiceInstanc
 }
 
 func (c *k8sServiceCatalogClient) DeleteBinding(binding *entities.ServiceBinding) error {
-	err := c.sdk.Unbind(c.config.CatalogNamespace, binding.BindingID)
+	if binding.PendingBindingID != "" {
+		// a rotation in progress
+		c.dropBinding(binding, binding.PendingBindingID)
+	}
+	// only this binding is deleted, the other bindings of the service are left untouched
+	err := c.sdk.DeleteBinding(c.config.CatalogNamespace, binding.BindingID)
 	if err != nil {
 		// Nothing we can do... try again later if there are orphaned resources
 		log.Errorf("Error deleting service binding %s", binding.BindingID)
 	}
-	err = c.deleteSecret(flags.ServiceManagerFlags.OrgID, binding.BindingID)
+	err = c.deleteSecret(binding.OrganizationID, binding.BindingID)
 	if err != nil {
 		return err
 	}
@@ -392,58 +463,36 @@ func (c *k8sServiceCatalogClient) DeleteBinding(binding *entities.ServiceBinding
 	return nil
 }
 
-func (c *k8sServiceCatalogClient) getSecrets(organizationID string, secretNames []string) (map[string]string, error) {
-	secrets := make(map[string]string)
-	for _, name := range secretNames {
-		resp, err := c.secretsClient.GetSecret(context.TODO(), organizationID, name)
-		if err != nil {
-			return secrets, errors.Wrapf(err, "failed to get secrets from secret store")
-		}
-		for key, value := range resp.Secrets {
-			secrets[key] = value
-		}
+// dropBinding deletes a Service Catalog binding no longer used by a binding and its secret, the errors are only logged
+// as nothing refers to them anymore
+func (c *k8sServiceCatalogClient) dropBinding(binding *entities.ServiceBinding, bindingID string) {
+	if err := c.sdk.DeleteBinding(c.config.CatalogNamespace, bindingID); err != nil {
+		log.Errorf("Error deleting %s of service binding %s: %v", bindingID, binding.Name, err)
+	}
+	if err := c.deleteSecret(binding.OrganizationID, bindingID); err != nil {
+		log.Errorf("Error deleting the secret %s of service binding %s: %v", bindingID, binding.Name, err)
 	}
-	return secrets, nil
 }
 
-func (c *k8sServiceCatalogClient) deleteSecret(organizationID string, secretName string) error {
-	err := c.secretsClient.DeleteSecret(context.TODO(), organizationID, secretName)
-	if err != nil {
-		_, ok := err.(*secret.GetSecretNotFound)
-		if !ok {
-			return errors.Wrapf(err, "failed to delete secret %s for binding", secretName)
-		}
+// organization returns the organization of a service instance or binding, those created before organizations were
+// labeled belong to the default organization
+func (c *k8sServiceCatalogClient) organization(meta metav1.ObjectMeta) string {
+	if orgID, ok := meta.Labels[organizationLabel]; ok {
+		return orgID
 	}
-	return nil
+	return c.config.OrgID
+}
+
+func (c *k8sServiceCatalogClient) getSecrets(organizationID string, secretNames []string) (map[string]string, error) {
+	return getSecrets(c.secretsClient, organizationID, secretNames)
+}
+
+func (c *k8sServiceCatalogClient) deleteSecret(organizationID string, secretName string) error {
+	return deleteSecret(c.secretsClient, organizationID, secretName)
 }
 
 func (c *k8sServiceCatalogClient) setSecret(organizationID string, secretName string, secrets map[string]string) error {
-	log.Debugf("Setting dispatch secret %s", secretName)
-	// We should probably update only on changes rather than just by default
-	_, err := c.secretsClient.UpdateSecret(
-		context.TODO(),
-		organizationID,
-		&dispatchv1.Secret{
-			Name:    &secretName,
-			Secrets: secrets,
-		},
-	)
-	if err != nil {
-		log.Debugf("failed to update secrets in secret store: %v", err)
-		// If update failed, probably missing so create
-		_, err := c.secretsClient.CreateSecret(
-			context.TODO(),
-			organizationID,
-			&dispatchv1.Secret{
-				Name:    &secretName,
-				Secrets: secrets,
-			},
-		)
-		if err != nil {
-			return errors.Wrapf(err, "failed to set secrets in secret store")
-		}
-	}
-	return nil
+	return setSecret(c.secretsClient, organizationID, secretName, secrets)
 }
 
 func buildEnv(input map[string]string) []corev1.EnvVar {
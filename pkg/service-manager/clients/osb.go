@@ -0,0 +1,905 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/spec"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/service-manager/entities"
+)
+
+// OSBAPIVersion is the version of the Open Service Broker API spoken to the brokers
+const OSBAPIVersion = "2.13"
+
+const (
+	osbVersionHeader             = "X-Broker-API-Version"
+	osbOriginatingIdentityHeader = "X-Broker-API-Originating-Identity"
+	osbPlatform                  = "dispatch"
+
+	defaultOSBTimeout = 60 * time.Second
+)
+
+// The states of the asynchronous operations of the brokers
+const (
+	osbStateInProgress = "in progress"
+	osbStateSucceeded  = "succeeded"
+	osbStateFailed     = "failed"
+)
+
+// OSBBrokerConfigOpts are the configuration options of the Open Service Broker API client
+type OSBBrokerConfigOpts struct {
+	// Store holds the brokers registered, and the service classes, instances and bindings
+	Store          entitystore.EntityStore
+	SecretStoreURL string
+	// OrgID is the organization of the brokers and the service classes, shared by all organizations
+	OrgID   string
+	Timeout time.Duration
+}
+
+// osbClient talks to the brokers registered with the Open Service Broker API. The brokers do not list the services
+// they provisioned: the service instances and bindings are the ones of the store, and their status is the status of
+// the last operation of their brokers.
+type osbClient struct {
+	store         entitystore.EntityStore
+	orgID         string
+	httpClient    *http.Client
+	secretsClient client.SecretsClient
+}
+
+// NewOSBBrokerClient creates a new Service Broker talking to the brokers registered with the Open Service Broker API
+func NewOSBBrokerClient(config OSBBrokerConfigOpts) (BrokerClient, error) {
+	if config.Store == nil {
+		return nil, errors.New("the Open Service Broker API client requires a store")
+	}
+	return newOSBClient(config, client.NewSecretsClient(config.SecretStoreURL, client.AuthWithToken("cookie"), "")), nil
+}
+
+func newOSBClient(config OSBBrokerConfigOpts, secretsClient client.SecretsClient) *osbClient {
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = defaultOSBTimeout
+	}
+	return &osbClient{
+		store:         config.Store,
+		orgID:         config.OrgID,
+		httpClient:    &http.Client{Timeout: timeout},
+		secretsClient: secretsClient,
+	}
+}
+
+// osbCatalog is the catalog of a broker
+type osbCatalog struct {
+	Services []osbService `json:"services"`
+}
+
+type osbService struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Bindable       bool      `json:"bindable"`
+	PlanUpdateable bool      `json:"plan_updateable"`
+	Plans          []osbPlan `json:"plans"`
+}
+
+type osbPlan struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Metadata    interface{} `json:"metadata,omitempty"`
+	Free        *bool       `json:"free,omitempty"`
+	Bindable    *bool       `json:"bindable,omitempty"`
+	Schemas     struct {
+		ServiceInstance struct {
+			Create osbSchema `json:"create"`
+			Update osbSchema `json:"update"`
+		} `json:"service_instance"`
+		ServiceBinding struct {
+			Create osbSchema `json:"create"`
+		} `json:"service_binding"`
+	} `json:"schemas"`
+}
+
+type osbSchema struct {
+	Parameters *spec.Schema `json:"parameters,omitempty"`
+}
+
+// osbRequest is the body of the provision, update and bind requests
+type osbRequest struct {
+	ServiceID        string                 `json:"service_id"`
+	PlanID           string                 `json:"plan_id"`
+	Context          map[string]interface{} `json:"context,omitempty"`
+	OrganizationGUID string                 `json:"organization_guid,omitempty"`
+	SpaceGUID        string                 `json:"space_guid,omitempty"`
+	BindResource     map[string]interface{} `json:"bind_resource,omitempty"`
+	Parameters       map[string]interface{} `json:"parameters,omitempty"`
+	PreviousValues   *osbPreviousValues     `json:"previous_values,omitempty"`
+}
+
+// osbPreviousValues are the values of a service instance before an update
+type osbPreviousValues struct {
+	ServiceID string `json:"service_id,omitempty"`
+	PlanID    string `json:"plan_id,omitempty"`
+}
+
+// osbResponse holds the fields of the responses of the brokers
+type osbResponse struct {
+	Operation   string                 `json:"operation,omitempty"`
+	Credentials map[string]interface{} `json:"credentials,omitempty"`
+	State       string                 `json:"state,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+}
+
+// osbCall is a call to a broker
+type osbCall struct {
+	method string
+	path   string
+	query  url.Values
+	// organizationID and requester are sent as the originating identity of the call
+	organizationID string
+	requester      string
+	body           interface{}
+}
+
+// osbError is an error responded by a broker
+func osbError(broker *entities.Broker, status int, resp *osbResponse) error {
+	message := resp.Description
+	if resp.Error != "" {
+		message = resp.Error + ": " + message
+	}
+	return errors.Errorf("broker %s responded with status %d: %s", broker.Name, status, message)
+}
+
+// originatingIdentity returns the originating identity header value of a call
+func originatingIdentity(organizationID, requester string) string {
+	identity, _ := json.Marshal(map[string]string{"user_id": requester, "organization": organizationID})
+	return osbPlatform + " " + base64.StdEncoding.EncodeToString(identity)
+}
+
+// do calls a broker and decodes its response into out, it returns the status of the response
+func (c *osbClient) do(broker *entities.Broker, call osbCall, out interface{}) (int, error) {
+	u := strings.TrimRight(broker.URL, "/") + call.path
+	if len(call.query) > 0 {
+		u += "?" + call.query.Encode()
+	}
+	var body []byte
+	if call.body != nil {
+		var err error
+		if body, err = json.Marshal(call.body); err != nil {
+			return 0, errors.Wrapf(err, "error encoding the request to broker %s", broker.Name)
+		}
+	}
+	req, err := http.NewRequest(call.method, u, bytes.NewReader(body))
+	if err != nil {
+		return 0, errors.Wrapf(err, "error creating the request to broker %s", broker.Name)
+	}
+	req.Header.Set(osbVersionHeader, OSBAPIVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if call.requester != "" {
+		req.Header.Set(osbOriginatingIdentityHeader, originatingIdentity(call.organizationID, call.requester))
+	}
+	if broker.AuthSecret != "" {
+		credentials, err := getSecrets(c.secretsClient, c.orgID, []string{broker.AuthSecret})
+		if err != nil {
+			return 0, errors.Wrapf(err, "error getting the credentials of broker %s", broker.Name)
+		}
+		if token, ok := credentials["token"]; ok {
+			req.Header.Set("Authorization", "Bearer "+token)
+		} else {
+			req.SetBasicAuth(credentials["username"], credentials["password"])
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error calling broker %s", broker.Name)
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, errors.Wrapf(err, "error reading the response of broker %s", broker.Name)
+	}
+	if len(bytes.TrimSpace(respBody)) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return resp.StatusCode, errors.Wrapf(err, "error decoding the response of broker %s with status %d", broker.Name, resp.StatusCode)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// broker returns a broker registered
+func (c *osbClient) broker(name string) (*entities.Broker, error) {
+	broker := &entities.Broker{}
+	if err := c.store.Get(context.TODO(), c.orgID, name, entitystore.Options{}, broker); err != nil {
+		return nil, errors.Wrapf(err, "error getting broker %s", name)
+	}
+	return broker, nil
+}
+
+// plan returns the broker, the service class and the plan of a service instance
+func (c *osbClient) plan(service *entities.ServiceInstance) (*entities.Broker, *entities.ServiceClass, *entities.ServicePlan, error) {
+	class := &entities.ServiceClass{}
+	if err := c.store.Get(context.TODO(), c.orgID, service.ServiceClass, entitystore.Options{}, class); err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "error getting service class %s", service.ServiceClass)
+	}
+	broker, err := c.broker(class.Broker)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	plan := findPlan(class, service.ServicePlan)
+	if plan == nil {
+		return nil, nil, nil, errors.Errorf("service class %s has no plan %s", class.Name, service.ServicePlan)
+	}
+	return broker, class, plan, nil
+}
+
+func findPlan(class *entities.ServiceClass, name string) *entities.ServicePlan {
+	for i := range class.Plans {
+		if class.Plans[i].Name == name {
+			return &class.Plans[i]
+		}
+	}
+	return nil
+}
+
+// osbParameters merges the parameters of a service instance or binding with the values of its secret parameters
+func osbParameters(parameters interface{}, secrets map[string]string) (map[string]interface{}, error) {
+	merged := make(map[string]interface{})
+	if parameters != nil {
+		b, err := json.Marshal(parameters)
+		if err != nil {
+			return nil, errors.Wrap(err, "error encoding the parameters")
+		}
+		if err := json.Unmarshal(b, &merged); err != nil {
+			return nil, errors.Wrap(err, "the parameters are not an object")
+		}
+	}
+	for key, value := range secrets {
+		merged[key] = value
+	}
+	return merged, nil
+}
+
+// osbContext is the context of the services provisioned and bound for an organization
+func osbContext(organizationID string) map[string]interface{} {
+	return map[string]interface{}{"platform": osbPlatform, "organization": organizationID}
+}
+
+// ListServiceClasses returns a list of ServiceClass entities which correspond to the services of the catalogs of
+// the brokers registered, as last fetched by refreshing the brokers
+func (c *osbClient) ListServiceClasses() ([]entitystore.Entity, error) {
+	var brokers []*entities.Broker
+	if err := c.store.List(context.TODO(), c.orgID, entitystore.Options{}, &brokers); err != nil {
+		return nil, errors.Wrap(err, "error listing the brokers")
+	}
+
+	var serviceClasses []entitystore.Entity
+	for _, broker := range brokers {
+		if broker.Delete {
+			continue
+		}
+		for i := range broker.Catalog {
+			class := broker.Catalog[i]
+			serviceClasses = append(serviceClasses, &class)
+		}
+	}
+	return serviceClasses, nil
+}
+
+// FetchCatalog fetches the catalog of a broker, and returns the service classes of its services
+func (c *osbClient) FetchCatalog(broker *entities.Broker) ([]entities.ServiceClass, error) {
+	var catalog osbCatalog
+	status, err := c.do(broker, osbCall{method: http.MethodGet, path: "/v2/catalog"}, &catalog)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, errors.Errorf("error fetching the catalog of broker %s: status %d", broker.Name, status)
+	}
+	log.Debugf("Fetched the catalog of broker %s [%d services]", broker.Name, len(catalog.Services))
+
+	var serviceClasses []entities.ServiceClass
+	for _, service := range catalog.Services {
+		var plans []entities.ServicePlan
+		for _, plan := range service.Plans {
+			free := true
+			if plan.Free != nil {
+				free = *plan.Free
+			}
+			bindable := service.Bindable
+			if plan.Bindable != nil {
+				bindable = *plan.Bindable
+			}
+			plans = append(plans, entities.ServicePlan{
+				BaseEntity: entitystore.BaseEntity{
+					Name:   plan.Name,
+					Status: entitystore.StatusREADY,
+				},
+				PlanID:      plan.ID,
+				Description: plan.Description,
+				Schema: entities.Schema{
+					Create: schemaOrEmpty(plan.Schemas.ServiceInstance.Create.Parameters),
+					Update: schemaOrEmpty(plan.Schemas.ServiceInstance.Update.Parameters),
+					Bind:   schemaOrEmpty(plan.Schemas.ServiceBinding.Create.Parameters),
+				},
+				Free:     free,
+				Bindable: bindable,
+				Metadata: plan.Metadata,
+			})
+		}
+		serviceClasses = append(serviceClasses, entities.ServiceClass{
+			BaseEntity: entitystore.BaseEntity{
+				Name:   service.Name,
+				Status: entitystore.StatusREADY,
+			},
+			Description:   service.Description,
+			ServiceID:     service.ID,
+			Broker:        broker.Name,
+			Bindable:      service.Bindable,
+			PlanUpdatable: service.PlanUpdateable,
+			Plans:         plans,
+		})
+	}
+	return serviceClasses, nil
+}
+
+// schemaOrEmpty returns an empty schema for the plans without schemas, as the Service Catalog client does
+func schemaOrEmpty(schema *spec.Schema) *spec.Schema {
+	if schema == nil {
+		return new(spec.Schema)
+	}
+	return schema
+}
+
+// ListServiceInstances returns the service instances of the store, with the status of the last operation of their
+// brokers. The instances being provisioned or updated are polled, the status of the others is unknown.
+func (c *osbClient) ListServiceInstances() ([]entitystore.Entity, error) {
+	ctx := context.TODO()
+	orgIDs, err := c.store.ListOrgIDs(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the organizations")
+	}
+	var serviceInstances []entitystore.Entity
+	for _, orgID := range orgIDs {
+		var instances []*entities.ServiceInstance
+		if err := c.store.List(ctx, orgID, entitystore.Options{}, &instances); err != nil {
+			return nil, errors.Wrap(err, "error listing the service instances")
+		}
+		for _, instance := range instances {
+			// the instances not provisioned yet are not known to the brokers
+			if instance.Status == entitystore.StatusINITIALIZED {
+				continue
+			}
+			serviceInstance := &entities.ServiceInstance{
+				BaseEntity: entitystore.BaseEntity{
+					OrganizationID: instance.OrganizationID,
+					ID:             instance.ID,
+					Name:           instance.Name,
+					Status:         entitystore.StatusUNKNOWN,
+				},
+				ServiceClass: instance.ServiceClass,
+				ServicePlan:  instance.ServicePlan,
+				Parameters:   instance.Parameters,
+				InstanceID:   instance.InstanceID,
+			}
+			pending := instance.Status == entitystore.StatusCREATING ||
+				instance.Status == entitystore.StatusUPDATING && instance.UpdateInProgress
+			if pending && instance.InstanceID != "" && !instance.Delete {
+				c.pollServiceInstance(instance, serviceInstance)
+			}
+			serviceInstances = append(serviceInstances, serviceInstance)
+		}
+	}
+	return serviceInstances, nil
+}
+
+// pollServiceInstance sets the status of a service instance being provisioned or updated to the state of the last
+// operation of its broker
+func (c *osbClient) pollServiceInstance(instance, actual *entities.ServiceInstance) {
+	broker, class, plan, err := c.plan(instance)
+	if err != nil {
+		log.Errorf("Error polling the last operation of service instance %s: %v", instance.Name, err)
+		return
+	}
+	query := url.Values{"service_id": {class.ServiceID}, "plan_id": {plan.PlanID}}
+	if instance.Operation != "" {
+		query.Set("operation", instance.Operation)
+	}
+	var resp osbResponse
+	status, err := c.do(broker, osbCall{
+		method:         http.MethodGet,
+		path:           fmt.Sprintf("/v2/service_instances/%s/last_operation", instance.InstanceID),
+		query:          query,
+		organizationID: instance.OrganizationID,
+		requester:      instance.Requester,
+	}, &resp)
+	if err != nil {
+		log.Errorf("Error polling the last operation of service instance %s: %v", instance.Name, err)
+		return
+	}
+	setOperationStatus(&actual.BaseEntity, broker, status, &resp)
+}
+
+// setOperationStatus sets the status of an entity to the state of the last operation of a broker
+func setOperationStatus(e *entitystore.BaseEntity, broker *entities.Broker, status int, resp *osbResponse) {
+	switch {
+	case status == http.StatusGone:
+		e.Status = entitystore.StatusERROR
+		e.Reason = entitystore.Reason{fmt.Sprintf("broker %s does not know the resource", broker.Name)}
+	case status != http.StatusOK:
+		log.Errorf("Error polling the last operation of broker %s: %v", broker.Name, osbError(broker, status, resp))
+	case resp.State == osbStateSucceeded:
+		e.Status = entitystore.StatusREADY
+	case resp.State == osbStateFailed:
+		e.Status = entitystore.StatusERROR
+		e.Reason = entitystore.Reason{resp.Description}
+	case resp.State == osbStateInProgress:
+		log.Debugf("Operation of broker %s in progress: %s", broker.Name, resp.Description)
+	}
+}
+
+// ListServiceBindings returns the service bindings of the store, with the status of the last operation of their
+// brokers. The bindings being created are polled, their credentials are set once they are bound.
+func (c *osbClient) ListServiceBindings() ([]entitystore.Entity, error) {
+	ctx := context.TODO()
+	orgIDs, err := c.store.ListOrgIDs(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the organizations")
+	}
+	var serviceBindings []entitystore.Entity
+	for _, orgID := range orgIDs {
+		var bindings []*entities.ServiceBinding
+		if err := c.store.List(ctx, orgID, entitystore.Options{}, &bindings); err != nil {
+			return nil, errors.Wrap(err, "error listing the service bindings")
+		}
+		for _, binding := range bindings {
+			// the bindings not created yet are not known to the brokers
+			if binding.Status == entitystore.StatusINITIALIZED {
+				continue
+			}
+			serviceBinding := &entities.ServiceBinding{
+				BaseEntity: entitystore.BaseEntity{
+					OrganizationID: binding.OrganizationID,
+					Name:           binding.Name,
+					Status:         entitystore.StatusUNKNOWN,
+				},
+				ServiceInstance: binding.ServiceInstance,
+				Parameters:      binding.Parameters,
+				BindingID:       binding.BindingID,
+			}
+			if binding.Status == entitystore.StatusCREATING && binding.BindingID != "" && !binding.Delete {
+				c.pollServiceBinding(binding, serviceBinding)
+			}
+			serviceBindings = append(serviceBindings, serviceBinding)
+		}
+	}
+	return serviceBindings, nil
+}
+
+// pollServiceBinding sets the status of a service binding being created to the state of the last operation of its
+// broker, and fetches its credentials once it succeeded. A binding being rotated is polled under its pending binding
+// ID, and switches to it once bound.
+func (c *osbClient) pollServiceBinding(binding, actual *entities.ServiceBinding) {
+	instance := &entities.ServiceInstance{}
+	if err := c.store.Get(context.TODO(), binding.OrganizationID, binding.ServiceInstance, entitystore.Options{}, instance); err != nil {
+		log.Errorf("Error getting service instance %s of binding %s: %v", binding.ServiceInstance, binding.Name, err)
+		return
+	}
+	broker, class, plan, err := c.plan(instance)
+	if err != nil {
+		log.Errorf("Error polling the last operation of service binding %s: %v", binding.Name, err)
+		return
+	}
+	bindingID := binding.BindingID
+	if binding.PendingBindingID != "" {
+		bindingID = binding.PendingBindingID
+	}
+	query := url.Values{"service_id": {class.ServiceID}, "plan_id": {plan.PlanID}}
+	if binding.Operation != "" {
+		query.Set("operation", binding.Operation)
+	}
+	call := osbCall{
+		method:         http.MethodGet,
+		path:           fmt.Sprintf("/v2/service_instances/%s/service_bindings/%s/last_operation", instance.InstanceID, bindingID),
+		query:          query,
+		organizationID: binding.OrganizationID,
+		requester:      binding.Requester,
+	}
+	var resp osbResponse
+	status, err := c.do(broker, call, &resp)
+	if err != nil {
+		log.Errorf("Error polling the last operation of service binding %s: %v", binding.Name, err)
+		return
+	}
+	setOperationStatus(&actual.BaseEntity, broker, status, &resp)
+	if actual.Status == entitystore.StatusERROR && binding.PendingBindingID != "" {
+		// the binding keeps the credentials it had before the rotation
+		c.dropBinding(binding, binding.PendingBindingID)
+		binding.PendingBindingID = ""
+		if _, err := c.store.Update(context.TODO(), binding.Revision, binding); err != nil {
+			log.Errorf("Error updating service binding %s: %v", binding.Name, err)
+		}
+		return
+	}
+	if actual.Status != entitystore.StatusREADY {
+		return
+	}
+
+	// the credentials of an asynchronous binding are fetched once it is bound
+	call.path = fmt.Sprintf("/v2/service_instances/%s/service_bindings/%s", instance.InstanceID, bindingID)
+	call.query = nil
+	resp = osbResponse{}
+	status, err = c.do(broker, call, &resp)
+	if err == nil && status != http.StatusOK {
+		err = osbError(broker, status, &resp)
+	}
+	if err == nil {
+		err = c.setCredentials(binding, bindingID, resp.Credentials)
+	}
+	if err == nil && binding.PendingBindingID != "" {
+		err = c.switchBinding(binding)
+		actual.BindingID = binding.BindingID
+	}
+	if err != nil {
+		log.Errorf("Error fetching the credentials of service binding %s: %v", binding.Name, err)
+		actual.Status = entitystore.StatusUNKNOWN
+	}
+}
+
+// setCredentials stores the credentials of a binding in the secret the functions using its service get
+func (c *osbClient) setCredentials(binding *entities.ServiceBinding, bindingID string, credentials map[string]interface{}) error {
+	secrets := make(map[string]string)
+	for key, value := range credentials {
+		if s, ok := value.(string); ok {
+			secrets[key] = s
+			continue
+		}
+		b, err := json.Marshal(value)
+		if err != nil {
+			return errors.Wrapf(err, "error encoding credential %s", key)
+		}
+		secrets[key] = string(b)
+	}
+	return setSecret(c.secretsClient, binding.OrganizationID, bindingID, secrets)
+}
+
+// CreateService provisions a service, creating a service instance. The service is ready once provisioned, or being
+// created while the broker provisions it asynchronously.
+func (c *osbClient) CreateService(class *entities.ServiceClass, service *entities.ServiceInstance) error {
+	broker, err := c.broker(class.Broker)
+	if err != nil {
+		service.SetStatus(entitystore.StatusERROR)
+		return err
+	}
+	plan := findPlan(class, service.ServicePlan)
+	if plan == nil {
+		service.SetStatus(entitystore.StatusERROR)
+		return errors.Errorf("Error provisioning service %s: service class %s has no plan %s", service.Name, class.Name, service.ServicePlan)
+	}
+	secrets, err := getSecrets(c.secretsClient, service.OrganizationID, service.SecretParameters)
+	if err != nil {
+		service.SetStatus(entitystore.StatusERROR)
+		return errors.Wrapf(err, "Error fetching secrets for provisioning service %s of class %s with plan %s", service.Name, service.ServiceClass, service.ServicePlan)
+	}
+	parameters, err := osbParameters(service.Parameters, secrets)
+	if err != nil {
+		service.SetStatus(entitystore.StatusERROR)
+		return errors.Wrapf(err, "Error provisioning service %s", service.Name)
+	}
+
+	// the instances are identified by the IDs of their entities, unique across organizations
+	service.InstanceID = service.ID
+	var resp osbResponse
+	status, err := c.do(broker, osbCall{
+		method:         http.MethodPut,
+		path:           fmt.Sprintf("/v2/service_instances/%s", service.InstanceID),
+		query:          url.Values{"accepts_incomplete": {"true"}},
+		organizationID: service.OrganizationID,
+		requester:      service.Requester,
+		body: &osbRequest{
+			ServiceID:        class.ServiceID,
+			PlanID:           plan.PlanID,
+			Context:          osbContext(service.OrganizationID),
+			OrganizationGUID: service.OrganizationID,
+			SpaceGUID:        service.OrganizationID,
+			Parameters:       parameters,
+		},
+	}, &resp)
+	if err == nil && status != http.StatusOK && status != http.StatusCreated && status != http.StatusAccepted {
+		err = osbError(broker, status, &resp)
+	}
+	if err != nil {
+		service.SetStatus(entitystore.StatusERROR)
+		return errors.Wrapf(err, "Error provisioning service %s of class %s with plan %s", service.Name, service.ServiceClass, service.ServicePlan)
+	}
+	service.Operation = resp.Operation
+	if status == http.StatusAccepted {
+		service.SetStatus(entitystore.StatusCREATING)
+	} else {
+		service.SetStatus(entitystore.StatusREADY)
+	}
+	return nil
+}
+
+// UpdateService updates the plan and parameters of a service instance. The service is ready once updated, or being
+// updated while the broker updates it asynchronously.
+func (c *osbClient) UpdateService(class *entities.ServiceClass, service *entities.ServiceInstance) error {
+	broker, err := c.broker(class.Broker)
+	if err != nil {
+		service.SetStatus(entitystore.StatusERROR)
+		return err
+	}
+	plan := findPlan(class, service.ServicePlan)
+	if plan == nil {
+		service.SetStatus(entitystore.StatusERROR)
+		return errors.Errorf("Error updating service %s: service class %s has no plan %s", service.Name, class.Name, service.ServicePlan)
+	}
+	secrets, err := getSecrets(c.secretsClient, service.OrganizationID, service.SecretParameters)
+	if err != nil {
+		service.SetStatus(entitystore.StatusERROR)
+		return errors.Wrapf(err, "Error fetching secrets for updating service %s of class %s with plan %s", service.Name, service.ServiceClass, service.ServicePlan)
+	}
+	parameters, err := osbParameters(service.Parameters, secrets)
+	if err != nil {
+		service.SetStatus(entitystore.StatusERROR)
+		return errors.Wrapf(err, "Error updating service %s", service.Name)
+	}
+	previous := &osbPreviousValues{ServiceID: class.ServiceID}
+	if previousPlan := findPlan(class, service.PreviousPlan); previousPlan != nil {
+		previous.PlanID = previousPlan.PlanID
+	}
+
+	var resp osbResponse
+	status, err := c.do(broker, osbCall{
+		method:         http.MethodPatch,
+		path:           fmt.Sprintf("/v2/service_instances/%s", service.InstanceID),
+		query:          url.Values{"accepts_incomplete": {"true"}},
+		organizationID: service.OrganizationID,
+		requester:      service.Requester,
+		body: &osbRequest{
+			ServiceID:      class.ServiceID,
+			PlanID:         plan.PlanID,
+			Context:        osbContext(service.OrganizationID),
+			Parameters:     parameters,
+			PreviousValues: previous,
+		},
+	}, &resp)
+	if err == nil && status != http.StatusOK && status != http.StatusAccepted {
+		err = osbError(broker, status, &resp)
+	}
+	if err != nil {
+		service.SetStatus(entitystore.StatusERROR)
+		return errors.Wrapf(err, "Error updating service %s of class %s to plan %s", service.Name, service.ServiceClass, service.ServicePlan)
+	}
+	service.Operation = resp.Operation
+	if status == http.StatusAccepted {
+		service.SetStatus(entitystore.StatusUPDATING)
+	} else {
+		service.SetStatus(entitystore.StatusREADY)
+	}
+	return nil
+}
+
+// CreateBinding creates a binding (credentials) for a service. The credentials of a binding created synchronously
+// are stored right away, those of a binding created asynchronously once the broker bound it.
+func (c *osbClient) CreateBinding(service *entities.ServiceInstance, binding *entities.ServiceBinding) error {
+	log.Debugf("Creating service binding for service %s and binding %s", service.Name, binding.Name)
+	broker, class, plan, err := c.plan(service)
+	if err != nil {
+		binding.SetStatus(entitystore.StatusERROR)
+		return errors.Wrapf(err, "Error binding service %s", service.Name)
+	}
+	secrets, err := getSecrets(c.secretsClient, binding.OrganizationID, binding.SecretParameters)
+	if err != nil {
+		binding.SetStatus(entitystore.StatusERROR)
+		return errors.Wrapf(err, "Error fetching secrets for binding service %s of class %s with plan %s", service.Name, service.ServiceClass, service.ServicePlan)
+	}
+	parameters, err := osbParameters(binding.Parameters, secrets)
+	if err != nil {
+		binding.SetStatus(entitystore.StatusERROR)
+		return errors.Wrapf(err, "Error binding service %s", service.Name)
+	}
+
+	// the credentials of a binding are stored in a secret named after its binding ID, the functions using the binding
+	// get them
+	if binding.BindingID == "" {
+		binding.BindingID = binding.ID
+	}
+	var resp osbResponse
+	status, err := c.do(broker, osbCall{
+		method:         http.MethodPut,
+		path:           fmt.Sprintf("/v2/service_instances/%s/service_bindings/%s", service.InstanceID, binding.BindingID),
+		query:          url.Values{"accepts_incomplete": {"true"}},
+		organizationID: binding.OrganizationID,
+		requester:      binding.Requester,
+		body: &osbRequest{
+			ServiceID:    class.ServiceID,
+			PlanID:       plan.PlanID,
+			Context:      osbContext(binding.OrganizationID),
+			BindResource: map[string]interface{}{"app_guid": binding.Name},
+			Parameters:   parameters,
+		},
+	}, &resp)
+	if err == nil && status != http.StatusOK && status != http.StatusCreated && status != http.StatusAccepted {
+		err = osbError(broker, status, &resp)
+	}
+	if err == nil && status != http.StatusAccepted {
+		err = c.setCredentials(binding, binding.BindingID, resp.Credentials)
+	}
+	if err != nil {
+		binding.SetStatus(entitystore.StatusERROR)
+		return errors.Wrapf(err, "Error binding service %s of class %s with plan %s", service.Name, service.ServiceClass, service.ServicePlan)
+	}
+	binding.Operation = resp.Operation
+	if status == http.StatusAccepted {
+		binding.SetStatus(entitystore.StatusCREATING)
+	} else {
+		binding.SetStatus(entitystore.StatusREADY)
+	}
+	return nil
+}
+
+// RefreshBinding binds a service again, once its plan or parameters changed or the binding is rotated, so the
+// credentials of the binding are those of the service updated. The service is bound under a new binding ID, which the
+// binding switches to once bound, before the previous binding is unbound: the functions using the binding always get
+// credentials.
+func (c *osbClient) RefreshBinding(service *entities.ServiceInstance, binding *entities.ServiceBinding) error {
+	if binding.BindingID == "" {
+		return c.CreateBinding(service, binding)
+	}
+	if binding.PendingBindingID != "" {
+		c.dropBinding(binding, binding.PendingBindingID)
+	}
+	previous := binding.BindingID
+	binding.BindingID = uuid.NewV4().String()
+	err := c.CreateBinding(service, binding)
+	binding.BindingID, binding.PendingBindingID = previous, binding.BindingID
+	if err == nil && binding.Status == entitystore.StatusCREATING {
+		// switched once the broker bound it
+		return nil
+	}
+	if err == nil {
+		err = c.switchBinding(binding)
+	}
+	if err != nil {
+		// the binding keeps the credentials it had before
+		c.dropBinding(binding, binding.PendingBindingID)
+		binding.PendingBindingID = ""
+		binding.SetStatus(entitystore.StatusERROR)
+		return errors.Wrapf(err, "Error refreshing service binding %s", binding.Name)
+	}
+	return nil
+}
+
+// switchBinding switches a binding to its pending binding ID, so the functions using it get the new credentials, then
+// unbinds the previous binding ID and deletes its credentials
+func (c *osbClient) switchBinding(binding *entities.ServiceBinding) error {
+	previous := binding.BindingID
+	binding.BindingID, binding.PendingBindingID = binding.PendingBindingID, ""
+	if _, err := c.store.Update(context.TODO(), binding.Revision, binding); err != nil {
+		binding.BindingID, binding.PendingBindingID = previous, binding.BindingID
+		return errors.Wrapf(err, "error switching service binding %s to %s", binding.Name, binding.PendingBindingID)
+	}
+	c.dropBinding(binding, previous)
+	return nil
+}
+
+// dropBinding unbinds a binding ID no longer used by a binding and deletes its credentials, the errors are only
+// logged as nothing refers to them anymore
+func (c *osbClient) dropBinding(binding *entities.ServiceBinding, bindingID string) {
+	if err := c.unbind(binding, bindingID); err != nil {
+		log.Errorf("Error unbinding %s of service binding %s: %v", bindingID, binding.Name, err)
+	}
+	if err := deleteSecret(c.secretsClient, binding.OrganizationID, bindingID); err != nil {
+		log.Errorf("Error deleting the credentials %s of service binding %s: %v", bindingID, binding.Name, err)
+	}
+}
+
+// DeleteService deprovisions a service.
+func (c *osbClient) DeleteService(service *entities.ServiceInstance) error {
+	if service.InstanceID == "" {
+		// never provisioned
+		service.Status = entitystore.StatusDELETED
+		return nil
+	}
+	broker, class, plan, err := c.plan(service)
+	if err != nil {
+		return errors.Wrapf(err, "Error deleting service instance %s", service.Name)
+	}
+	var resp osbResponse
+	status, err := c.do(broker, osbCall{
+		method: http.MethodDelete,
+		path:   fmt.Sprintf("/v2/service_instances/%s", service.InstanceID),
+		query: url.Values{
+			"service_id":         {class.ServiceID},
+			"plan_id":            {plan.PlanID},
+			"accepts_incomplete": {"true"},
+		},
+		organizationID: service.OrganizationID,
+		requester:      service.Requester,
+	}, &resp)
+	if err == nil && status != http.StatusOK && status != http.StatusAccepted && status != http.StatusGone {
+		err = osbError(broker, status, &resp)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "Error deleting service instance %s", service.Name)
+	}
+	service.Status = entitystore.StatusDELETED
+	return nil
+}
+
+// DeleteBinding deletes a binding and its credentials.
+func (c *osbClient) DeleteBinding(binding *entities.ServiceBinding) error {
+	if binding.BindingID == "" {
+		// never bound
+		binding.Status = entitystore.StatusDELETED
+		return nil
+	}
+	if binding.PendingBindingID != "" {
+		// a rotation in progress
+		c.dropBinding(binding, binding.PendingBindingID)
+	}
+	if err := c.unbind(binding, binding.BindingID); err != nil {
+		// Nothing we can do... try again later if there are orphaned resources
+		log.Errorf("Error deleting service binding %s: %v", binding.BindingID, err)
+	}
+	if err := deleteSecret(c.secretsClient, binding.OrganizationID, binding.BindingID); err != nil {
+		return err
+	}
+	binding.Status = entitystore.StatusDELETED
+	return nil
+}
+
+// unbind unbinds a binding ID of a binding
+func (c *osbClient) unbind(binding *entities.ServiceBinding, bindingID string) error {
+	instance := &entities.ServiceInstance{}
+	found, err := c.store.Find(context.TODO(), binding.OrganizationID, binding.ServiceInstance, entitystore.Options{}, instance)
+	if err != nil {
+		return err
+	}
+	if !found {
+		// the bindings of a service are deleted with it
+		return nil
+	}
+	broker, class, plan, err := c.plan(instance)
+	if err != nil {
+		return err
+	}
+	var resp osbResponse
+	status, err := c.do(broker, osbCall{
+		method: http.MethodDelete,
+		path:   fmt.Sprintf("/v2/service_instances/%s/service_bindings/%s", instance.InstanceID, bindingID),
+		query: url.Values{
+			"service_id":         {class.ServiceID},
+			"plan_id":            {plan.PlanID},
+			"accepts_incomplete": {"true"},
+		},
+		organizationID: binding.OrganizationID,
+		requester:      binding.Requester,
+	}, &resp)
+	if err == nil && status != http.StatusOK && status != http.StatusAccepted && status != http.StatusGone {
+		err = osbError(broker, status, &resp)
+	}
+	return err
+}
@@ -0,0 +1,518 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package clients
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client/mocks"
+	"github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/service-manager/entities"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+const (
+	testOrgID        = "dispatch"
+	testServiceOrgID = "org-a"
+)
+
+var testCatalog = `{
+  "services": [{
+    "id": "db-service-id",
+    "name": "db",
+    "description": "A database",
+    "bindable": true,
+    "plan_updateable": true,
+    "plans": [{
+      "id": "small-plan-id",
+      "name": "small",
+      "description": "A small database",
+      "free": false,
+      "schemas": {
+        "service_instance": {"create": {"parameters": {"type": "object", "properties": {"size": {"type": "integer"}}}}}
+      }
+    }, {
+      "id": "shared-plan-id",
+      "name": "shared",
+      "description": "A shared database",
+      "bindable": false
+    }]
+  }]
+}`
+
+// fakeBroker is an Open Service Broker API broker, provisioning, updating and binding asynchronously when async is
+// set
+type fakeBroker struct {
+	t     *testing.T
+	async bool
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []map[string]interface{}
+}
+
+func (b *fakeBroker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	assert.Equal(b.t, OSBAPIVersion, r.Header.Get(osbVersionHeader))
+	username, password, ok := r.BasicAuth()
+	if !ok || username != "broker" || password != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+	b.requests = append(b.requests, r)
+	b.bodies = append(b.bodies, body)
+
+	path := r.URL.Path
+	switch {
+	case path == "/v2/catalog":
+		w.Write([]byte(testCatalog))
+	case strings.HasSuffix(path, "/last_operation"):
+		assert.Equal(b.t, "op-1", r.URL.Query().Get("operation"))
+		w.Write([]byte(`{"state": "succeeded"}`))
+	case r.Method == http.MethodPut && body["plan_id"] == "unknown-plan-id":
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "InvalidPlan", "description": "unknown plan"}`))
+	case (r.Method == http.MethodPut || r.Method == http.MethodPatch) && b.async:
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"operation": "op-1"}`))
+	case r.Method == http.MethodPut && strings.Contains(path, "/service_bindings/"):
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"credentials": {"username": "db-user", "port": 5432}}`))
+	case r.Method == http.MethodGet && strings.Contains(path, "/service_bindings/"):
+		w.Write([]byte(`{"credentials": {"username": "db-user", "port": 5432}}`))
+	case r.Method == http.MethodPut:
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{}`))
+	case r.Method == http.MethodPatch || r.Method == http.MethodDelete:
+		w.Write([]byte(`{}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (b *fakeBroker) last() (*http.Request, map[string]interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.requests[len(b.requests)-1], b.bodies[len(b.bodies)-1]
+}
+
+// newTestOSBClient returns a client of a fake broker, the broker is closed with the function returned
+func newTestOSBClient(t *testing.T, broker *fakeBroker) (*osbClient, entitystore.EntityStore, *mocks.SecretsClient, func()) {
+	server := httptest.NewServer(broker)
+
+	store := helpers.MakeEntityStore(t)
+	_, err := store.Add(context.Background(), &entities.Broker{
+		BaseEntity: entitystore.BaseEntity{OrganizationID: testOrgID, Name: "test-broker"},
+		URL:        server.URL,
+		AuthSecret: "test-broker-auth",
+	})
+	require.NoError(t, err)
+
+	secretsClient := &mocks.SecretsClient{}
+	secretsClient.On("GetSecret", mock.Anything, testOrgID, "test-broker-auth").Return(
+		&v1.Secret{Secrets: map[string]string{"username": "broker", "password": "secret"}}, nil)
+	return newOSBClient(OSBBrokerConfigOpts{Store: store, OrgID: testOrgID}, secretsClient), store, secretsClient, server.Close
+}
+
+func addTestServiceClass(t *testing.T, c *osbClient, store entitystore.EntityStore) *entities.ServiceClass {
+	broker, err := c.broker("test-broker")
+	require.NoError(t, err)
+	classes, err := c.FetchCatalog(broker)
+	require.NoError(t, err)
+	require.Len(t, classes, 1)
+	class := &classes[0]
+	class.OrganizationID = testOrgID
+	_, err = store.Add(context.Background(), class)
+	require.NoError(t, err)
+	return class
+}
+
+func addTestServiceInstance(t *testing.T, store entitystore.EntityStore, plan string) *entities.ServiceInstance {
+	service := &entities.ServiceInstance{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: testServiceOrgID,
+			Name:           "test-db",
+			Status:         entitystore.StatusINITIALIZED,
+		},
+		ServiceClass: "db",
+		ServicePlan:  plan,
+		Parameters:   map[string]interface{}{"size": 10},
+		Requester:    "user/jane@example.com",
+	}
+	_, err := store.Add(context.Background(), service)
+	require.NoError(t, err)
+	return service
+}
+
+func TestOSBListServiceClasses(t *testing.T) {
+	c, store, _, closeBroker := newTestOSBClient(t, &fakeBroker{t: t})
+	defer closeBroker()
+	class := addTestServiceClass(t, c, store)
+
+	assert.Equal(t, "db", class.Name)
+	assert.Equal(t, "db-service-id", class.ServiceID)
+	assert.Equal(t, "test-broker", class.Broker)
+	assert.True(t, class.Bindable)
+	assert.True(t, class.PlanUpdatable)
+	require.Len(t, class.Plans, 2)
+
+	small := class.Plans[0]
+	assert.Equal(t, "small", small.Name)
+	assert.Equal(t, "small-plan-id", small.PlanID)
+	assert.False(t, small.Free)
+	assert.True(t, small.Bindable)
+	assert.Contains(t, small.Schema.Create.Properties, "size")
+	assert.NotNil(t, small.Schema.Bind)
+
+	shared := class.Plans[1]
+	assert.True(t, shared.Free)
+	assert.False(t, shared.Bindable)
+
+	// the service classes are the ones of the catalogs last fetched
+	classes, err := c.ListServiceClasses()
+	require.NoError(t, err)
+	assert.Empty(t, classes)
+
+	broker, err := c.broker("test-broker")
+	require.NoError(t, err)
+	broker.Catalog = []entities.ServiceClass{*class}
+	_, err = store.Update(context.Background(), broker.Revision, broker)
+	require.NoError(t, err)
+	classes, err = c.ListServiceClasses()
+	require.NoError(t, err)
+	require.Len(t, classes, 1)
+	assert.Equal(t, "db-service-id", classes[0].(*entities.ServiceClass).ServiceID)
+}
+
+func TestOSBCreateService(t *testing.T) {
+	broker := &fakeBroker{t: t}
+	c, store, _, closeBroker := newTestOSBClient(t, broker)
+	defer closeBroker()
+	class := addTestServiceClass(t, c, store)
+	service := addTestServiceInstance(t, store, "small")
+
+	require.NoError(t, c.CreateService(class, service))
+	assert.Equal(t, entitystore.StatusREADY, service.Status)
+	assert.Equal(t, service.ID, service.InstanceID)
+
+	req, body := broker.last()
+	assert.Equal(t, "/v2/service_instances/"+service.ID, req.URL.Path)
+	assert.Equal(t, "true", req.URL.Query().Get("accepts_incomplete"))
+	assert.Equal(t, "db-service-id", body["service_id"])
+	assert.Equal(t, "small-plan-id", body["plan_id"])
+	assert.Equal(t, map[string]interface{}{"size": float64(10)}, body["parameters"])
+	assert.Equal(t, map[string]interface{}{"platform": "dispatch", "organization": testServiceOrgID}, body["context"])
+
+	identity := strings.SplitN(req.Header.Get(osbOriginatingIdentityHeader), " ", 2)
+	require.Len(t, identity, 2)
+	assert.Equal(t, "dispatch", identity[0])
+	value, err := base64.StdEncoding.DecodeString(identity[1])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"user_id": "user/jane@example.com", "organization": "org-a"}`, string(value))
+
+	// the errors of the broker are reported
+	class.Plans[0].PlanID = "unknown-plan-id"
+	err = c.CreateService(class, service)
+	assert.Contains(t, err.Error(), "unknown plan")
+	assert.Equal(t, entitystore.StatusERROR, service.Status)
+}
+
+func TestOSBCreateServiceAsync(t *testing.T) {
+	broker := &fakeBroker{t: t, async: true}
+	c, store, _, closeBroker := newTestOSBClient(t, broker)
+	defer closeBroker()
+	class := addTestServiceClass(t, c, store)
+	service := addTestServiceInstance(t, store, "small")
+
+	require.NoError(t, c.CreateService(class, service))
+	assert.Equal(t, entitystore.StatusCREATING, service.Status)
+	assert.Equal(t, "op-1", service.Operation)
+	_, err := store.Update(context.Background(), service.Revision, service)
+	require.NoError(t, err)
+
+	// the instances being provisioned are polled
+	instances, err := c.ListServiceInstances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	actual := instances[0].(*entities.ServiceInstance)
+	assert.Equal(t, service.ID, actual.ID)
+	assert.Equal(t, entitystore.StatusREADY, actual.Status)
+
+	req, _ := broker.last()
+	assert.Equal(t, "/v2/service_instances/"+service.ID+"/last_operation", req.URL.Path)
+	assert.Equal(t, "db-service-id", req.URL.Query().Get("service_id"))
+	assert.Equal(t, "small-plan-id", req.URL.Query().Get("plan_id"))
+}
+
+func TestOSBUpdateService(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		broker := &fakeBroker{t: t}
+		c, store, _, closeBroker := newTestOSBClient(t, broker)
+		class := addTestServiceClass(t, c, store)
+		service := addTestServiceInstance(t, store, "small")
+		require.NoError(t, c.CreateService(class, service))
+
+		broker.async = async
+		service.PreviousPlan = "small"
+		service.PreviousParameters = service.Parameters
+		service.ServicePlan = "shared"
+		service.Parameters = map[string]interface{}{"size": 20}
+		require.NoError(t, c.UpdateService(class, service))
+
+		req, body := broker.last()
+		assert.Equal(t, http.MethodPatch, req.Method)
+		assert.Equal(t, "/v2/service_instances/"+service.ID, req.URL.Path)
+		assert.Equal(t, "true", req.URL.Query().Get("accepts_incomplete"))
+		assert.Equal(t, "shared-plan-id", body["plan_id"])
+		assert.Equal(t, map[string]interface{}{"size": float64(20)}, body["parameters"])
+		assert.Equal(t, map[string]interface{}{"service_id": "db-service-id", "plan_id": "small-plan-id"}, body["previous_values"])
+
+		if !async {
+			assert.Equal(t, entitystore.StatusREADY, service.Status)
+			closeBroker()
+			continue
+		}
+		assert.Equal(t, entitystore.StatusUPDATING, service.Status)
+		assert.Equal(t, "op-1", service.Operation)
+
+		// the instances being updated are polled
+		service.UpdateInProgress = true
+		_, err := store.Update(context.Background(), service.Revision, service)
+		require.NoError(t, err)
+		instances, err := c.ListServiceInstances()
+		require.NoError(t, err)
+		require.Len(t, instances, 1)
+		assert.Equal(t, entitystore.StatusREADY, instances[0].GetStatus())
+		req, _ = broker.last()
+		assert.Equal(t, "/v2/service_instances/"+service.ID+"/last_operation", req.URL.Path)
+		assert.Equal(t, "shared-plan-id", req.URL.Query().Get("plan_id"))
+		closeBroker()
+	}
+}
+
+func TestOSBBinding(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		broker := &fakeBroker{t: t}
+		c, store, secretsClient, closeBroker := newTestOSBClient(t, broker)
+		defer closeBroker()
+		class := addTestServiceClass(t, c, store)
+		service := addTestServiceInstance(t, store, "small")
+		require.NoError(t, c.CreateService(class, service))
+		_, err := store.Update(context.Background(), service.Revision, service)
+		require.NoError(t, err)
+
+		broker.async = async
+		binding := &entities.ServiceBinding{
+			BaseEntity: entitystore.BaseEntity{
+				OrganizationID: testServiceOrgID,
+				Name:           "test-db",
+				Status:         entitystore.StatusINITIALIZED,
+			},
+			ServiceInstance: "test-db",
+		}
+		_, err = store.Add(context.Background(), binding)
+		require.NoError(t, err)
+
+		credentials := v1.SecretValue{"username": "db-user", "port": "5432"}
+		secretsClient.On("UpdateSecret", mock.Anything, testServiceOrgID, mock.Anything).Return(nil, nil).Run(func(args mock.Arguments) {
+			secret := args.Get(2).(*v1.Secret)
+			assert.Equal(t, binding.ID, *secret.Name)
+			assert.Equal(t, credentials, secret.Secrets)
+		})
+
+		require.NoError(t, c.CreateBinding(service, binding))
+		assert.Equal(t, binding.ID, binding.BindingID)
+		req, _ := broker.last()
+		assert.Equal(t, "/v2/service_instances/"+service.ID+"/service_bindings/"+binding.ID, req.URL.Path)
+
+		if !async {
+			assert.Equal(t, entitystore.StatusREADY, binding.Status)
+			secretsClient.AssertNumberOfCalls(t, "UpdateSecret", 1)
+		} else {
+			// the credentials of an asynchronous binding are fetched once it is bound
+			assert.Equal(t, entitystore.StatusCREATING, binding.Status)
+			secretsClient.AssertNotCalled(t, "UpdateSecret", mock.Anything, mock.Anything, mock.Anything)
+			_, err = store.Update(context.Background(), binding.Revision, binding)
+			require.NoError(t, err)
+
+			bindings, err := c.ListServiceBindings()
+			require.NoError(t, err)
+			require.Len(t, bindings, 1)
+			assert.Equal(t, entitystore.StatusREADY, bindings[0].GetStatus())
+			secretsClient.AssertNumberOfCalls(t, "UpdateSecret", 1)
+		}
+
+		secretsClient.On("DeleteSecret", mock.Anything, testServiceOrgID, binding.ID).Return(nil)
+		require.NoError(t, c.DeleteBinding(binding))
+		assert.Equal(t, entitystore.StatusDELETED, binding.Status)
+		req, _ = broker.last()
+		assert.Equal(t, http.MethodDelete, req.Method)
+		assert.Equal(t, "/v2/service_instances/"+service.ID+"/service_bindings/"+binding.ID, req.URL.Path)
+		secretsClient.AssertCalled(t, "DeleteSecret", mock.Anything, testServiceOrgID, binding.ID)
+
+		require.NoError(t, c.DeleteService(service))
+		assert.Equal(t, entitystore.StatusDELETED, service.Status)
+		req, _ = broker.last()
+		assert.Equal(t, http.MethodDelete, req.Method)
+		assert.Equal(t, "/v2/service_instances/"+service.ID, req.URL.Path)
+		assert.Equal(t, "small-plan-id", req.URL.Query().Get("plan_id"))
+	}
+}
+
+func TestOSBBindingRotation(t *testing.T) {
+	broker := &fakeBroker{t: t}
+	c, store, secretsClient, closeBroker := newTestOSBClient(t, broker)
+	defer closeBroker()
+	class := addTestServiceClass(t, c, store)
+	service := addTestServiceInstance(t, store, "small")
+	require.NoError(t, c.CreateService(class, service))
+	_, err := store.Update(context.Background(), service.Revision, service)
+	require.NoError(t, err)
+	secretsClient.On("UpdateSecret", mock.Anything, testServiceOrgID, mock.Anything).Return(nil, nil)
+
+	// each binding of a service has its own ID, so its own credentials
+	var bindings []*entities.ServiceBinding
+	for _, name := range []string{"reader", "writer"} {
+		binding := &entities.ServiceBinding{
+			BaseEntity: entitystore.BaseEntity{
+				OrganizationID: testServiceOrgID,
+				Name:           name,
+				Status:         entitystore.StatusINITIALIZED,
+			},
+			ServiceInstance: "test-db",
+		}
+		_, err = store.Add(context.Background(), binding)
+		require.NoError(t, err)
+		require.NoError(t, c.CreateBinding(service, binding))
+		assert.Equal(t, binding.ID, binding.BindingID)
+		secretsClient.AssertCalled(t, "UpdateSecret", mock.Anything, testServiceOrgID, mock.MatchedBy(func(secret *v1.Secret) bool {
+			return *secret.Name == binding.ID
+		}))
+		bindings = append(bindings, binding)
+	}
+	assert.NotEqual(t, bindings[0].BindingID, bindings[1].BindingID)
+
+	// a binding rotated is bound under a new ID first, it switches to it before the previous ID is unbound
+	reader := bindings[0]
+	previous := reader.BindingID
+	secretsClient.On("DeleteSecret", mock.Anything, testServiceOrgID, previous).Return(nil).Run(func(args mock.Arguments) {
+		stored := &entities.ServiceBinding{}
+		require.NoError(t, store.Get(context.Background(), testServiceOrgID, "reader", entitystore.Options{}, stored))
+		assert.Equal(t, reader.BindingID, stored.BindingID)
+	})
+	reader.SetStatus(entitystore.StatusUPDATING)
+	require.NoError(t, c.RefreshBinding(service, reader))
+	assert.Equal(t, entitystore.StatusREADY, reader.Status)
+	assert.NotEqual(t, previous, reader.BindingID)
+	assert.Empty(t, reader.PendingBindingID)
+	n := len(broker.requests)
+	require.True(t, n >= 2)
+	assert.Equal(t, http.MethodPut, broker.requests[n-2].Method)
+	assert.Equal(t, "/v2/service_instances/"+service.ID+"/service_bindings/"+reader.BindingID, broker.requests[n-2].URL.Path)
+	assert.Equal(t, http.MethodDelete, broker.requests[n-1].Method)
+	assert.Equal(t, "/v2/service_instances/"+service.ID+"/service_bindings/"+previous, broker.requests[n-1].URL.Path)
+	secretsClient.AssertCalled(t, "UpdateSecret", mock.Anything, testServiceOrgID, mock.MatchedBy(func(secret *v1.Secret) bool {
+		return *secret.Name == reader.BindingID
+	}))
+	secretsClient.AssertCalled(t, "DeleteSecret", mock.Anything, testServiceOrgID, previous)
+
+	// revoking a binding leaves the others
+	secretsClient.On("DeleteSecret", mock.Anything, testServiceOrgID, reader.BindingID).Return(nil)
+	require.NoError(t, c.DeleteBinding(reader))
+	secretsClient.AssertNotCalled(t, "DeleteSecret", mock.Anything, testServiceOrgID, bindings[1].BindingID)
+	req, _ := broker.last()
+	assert.Equal(t, "/v2/service_instances/"+service.ID+"/service_bindings/"+reader.BindingID, req.URL.Path)
+}
+
+func TestOSBBindingRotationAsync(t *testing.T) {
+	broker := &fakeBroker{t: t}
+	c, store, secretsClient, closeBroker := newTestOSBClient(t, broker)
+	defer closeBroker()
+	class := addTestServiceClass(t, c, store)
+	service := addTestServiceInstance(t, store, "small")
+	require.NoError(t, c.CreateService(class, service))
+	_, err := store.Update(context.Background(), service.Revision, service)
+	require.NoError(t, err)
+	secretsClient.On("UpdateSecret", mock.Anything, testServiceOrgID, mock.Anything).Return(nil, nil)
+
+	binding := &entities.ServiceBinding{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: testServiceOrgID,
+			Name:           "test-db",
+			Status:         entitystore.StatusINITIALIZED,
+		},
+		ServiceInstance: "test-db",
+	}
+	_, err = store.Add(context.Background(), binding)
+	require.NoError(t, err)
+	require.NoError(t, c.CreateBinding(service, binding))
+	previous := binding.BindingID
+
+	// the binding keeps its credentials while the broker binds it again
+	broker.async = true
+	binding.SetStatus(entitystore.StatusUPDATING)
+	require.NoError(t, c.RefreshBinding(service, binding))
+	assert.Equal(t, entitystore.StatusCREATING, binding.Status)
+	assert.Equal(t, previous, binding.BindingID)
+	require.NotEmpty(t, binding.PendingBindingID)
+	assert.NotEqual(t, previous, binding.PendingBindingID)
+	req, _ := broker.last()
+	assert.Equal(t, http.MethodPut, req.Method)
+	assert.Equal(t, "/v2/service_instances/"+service.ID+"/service_bindings/"+binding.PendingBindingID, req.URL.Path)
+	_, err = store.Update(context.Background(), binding.Revision, binding)
+	require.NoError(t, err)
+
+	// once bound, the binding switches to its pending ID and the previous one is unbound
+	pending := binding.PendingBindingID
+	secretsClient.On("DeleteSecret", mock.Anything, testServiceOrgID, previous).Return(nil)
+	bindings, err := c.ListServiceBindings()
+	require.NoError(t, err)
+	require.Len(t, bindings, 1)
+	actual := bindings[0].(*entities.ServiceBinding)
+	assert.Equal(t, entitystore.StatusREADY, actual.Status)
+	assert.Equal(t, pending, actual.BindingID)
+	secretsClient.AssertCalled(t, "UpdateSecret", mock.Anything, testServiceOrgID, mock.MatchedBy(func(secret *v1.Secret) bool {
+		return *secret.Name == pending
+	}))
+	secretsClient.AssertCalled(t, "DeleteSecret", mock.Anything, testServiceOrgID, previous)
+	req, _ = broker.last()
+	assert.Equal(t, http.MethodDelete, req.Method)
+	assert.Equal(t, "/v2/service_instances/"+service.ID+"/service_bindings/"+previous, req.URL.Path)
+
+	stored := &entities.ServiceBinding{}
+	require.NoError(t, store.Get(context.Background(), testServiceOrgID, "test-db", entitystore.Options{}, stored))
+	assert.Equal(t, pending, stored.BindingID)
+	assert.Empty(t, stored.PendingBindingID)
+}
+
+func TestOSBBrokerUnauthorized(t *testing.T) {
+	c, _, secretsClient, closeBroker := newTestOSBClient(t, &fakeBroker{t: t})
+	defer closeBroker()
+	secretsClient.ExpectedCalls = nil
+	secretsClient.On("GetSecret", mock.Anything, testOrgID, "test-broker-auth").Return(
+		&v1.Secret{Secrets: map[string]string{"username": "broker", "password": "wrong"}}, nil)
+
+	broker, err := c.broker("test-broker")
+	require.NoError(t, err)
+	_, err = c.FetchCatalog(broker)
+	assert.Contains(t, err.Error(), "status 401")
+}
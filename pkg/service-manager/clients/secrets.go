@@ -0,0 +1,76 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package clients
+
+// NO TEST
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/secret-store/gen/client/secret"
+)
+
+// getSecrets merges the values of the secrets of an organization
+func getSecrets(secretsClient client.SecretsClient, organizationID string, secretNames []string) (map[string]string, error) {
+	secrets := make(map[string]string)
+	for _, name := range secretNames {
+		resp, err := secretsClient.GetSecret(context.TODO(), organizationID, name)
+		if err != nil {
+			return secrets, errors.Wrapf(err, "failed to get secrets from secret store")
+		}
+		for key, value := range resp.Secrets {
+			secrets[key] = value
+		}
+	}
+	return secrets, nil
+}
+
+// deleteSecret deletes a secret of an organization, if it exists
+func deleteSecret(secretsClient client.SecretsClient, organizationID string, secretName string) error {
+	err := secretsClient.DeleteSecret(context.TODO(), organizationID, secretName)
+	if err != nil {
+		_, ok := err.(*secret.GetSecretNotFound)
+		if !ok {
+			return errors.Wrapf(err, "failed to delete secret %s for binding", secretName)
+		}
+	}
+	return nil
+}
+
+// setSecret updates a secret of an organization, or creates it
+func setSecret(secretsClient client.SecretsClient, organizationID string, secretName string, secrets map[string]string) error {
+	log.Debugf("Setting dispatch secret %s", secretName)
+	// We should probably update only on changes rather than just by default
+	_, err := secretsClient.UpdateSecret(
+		context.TODO(),
+		organizationID,
+		&v1.Secret{
+			Name:    &secretName,
+			Secrets: secrets,
+		},
+	)
+	if err != nil {
+		log.Debugf("failed to update secrets in secret store: %v", err)
+		// If update failed, probably missing so create
+		_, err := secretsClient.CreateSecret(
+			context.TODO(),
+			organizationID,
+			&v1.Secret{
+				Name:    &secretName,
+				Secrets: secrets,
+			},
+		)
+		if err != nil {
+			return errors.Wrapf(err, "failed to set secrets in secret store")
+		}
+	}
+	return nil
+}
@@ -12,13 +12,29 @@ import (
 	"github.com/vmware/dispatch/pkg/service-manager/entities"
 )
 
+// The catalogs the service manager gets the services from, selected with the catalog of the configuration
+const (
+	// CatalogK8sServiceCatalog is the Kubernetes Service Catalog
+	CatalogK8sServiceCatalog = "k8sservicecatalog"
+	// CatalogOSB are the brokers registered, called with the Open Service Broker API
+	CatalogOSB = "osb"
+)
+
 // BrokerClient defines the Service Broker interface.  This interface very closely resembles Open Service Broker API
 type BrokerClient interface {
 	ListServiceClasses() ([]entitystore.Entity, error)
 	ListServiceInstances() ([]entitystore.Entity, error)
 	ListServiceBindings() ([]entitystore.Entity, error)
 	CreateService(*entities.ServiceClass, *entities.ServiceInstance) error
+	UpdateService(*entities.ServiceClass, *entities.ServiceInstance) error
 	CreateBinding(*entities.ServiceInstance, *entities.ServiceBinding) error
+	RefreshBinding(*entities.ServiceInstance, *entities.ServiceBinding) error
 	DeleteService(*entities.ServiceInstance) error
 	DeleteBinding(*entities.ServiceBinding) error
 }
+
+// CatalogClient is implemented by the broker clients calling the brokers registered, which fetch the catalogs of the
+// brokers when they are refreshed
+type CatalogClient interface {
+	FetchCatalog(*entities.Broker) ([]entities.ServiceClass, error)
+}
@@ -26,6 +26,111 @@ import (
 type ControllerConfig struct {
 	ResyncPeriod   time.Duration
 	OrganizationID string
+	// Ownership decides which replica reconciles the service classes, instances and bindings, nil with a single replica
+	Ownership controller.Ownership
+	// BrokerRefreshPeriod is how often the catalogs of the brokers registered are refreshed, never when zero
+	BrokerRefreshPeriod time.Duration
+}
+
+type serviceBrokerEntityHandler struct {
+	OrganizationID string
+	Store          entitystore.EntityStore
+	BrokerClient   clients.BrokerClient
+	RefreshPeriod  time.Duration
+}
+
+// Type returns the type of the entity associated to this handler
+func (h *serviceBrokerEntityHandler) Type() reflect.Type {
+	return reflect.TypeOf(&entities.Broker{})
+}
+
+// Add fetches the catalog of a broker registered
+func (h *serviceBrokerEntityHandler) Add(ctx context.Context, obj entitystore.Entity) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	return h.refresh(ctx, obj.(*entities.Broker))
+}
+
+// Update refreshes the catalog of a broker
+func (h *serviceBrokerEntityHandler) Update(ctx context.Context, obj entitystore.Entity) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	broker := obj.(*entities.Broker)
+	if broker.Status != entitystore.StatusUPDATING {
+		return nil
+	}
+	return h.refresh(ctx, broker)
+}
+
+// refresh fetches the catalog of a broker, the service classes are reconciled with the catalog when syncing. The
+// catalog last fetched is kept when the broker fails.
+func (h *serviceBrokerEntityHandler) refresh(ctx context.Context, broker *entities.Broker) (err error) {
+	defer func() { h.Store.UpdateWithError(ctx, broker, err) }()
+
+	catalogClient, ok := h.BrokerClient.(clients.CatalogClient)
+	if !ok {
+		return errors.New("the service catalog configured does not call the brokers registered")
+	}
+	catalog, err := catalogClient.FetchCatalog(broker)
+	if err != nil {
+		return err
+	}
+	broker.Catalog = catalog
+	broker.RefreshedTime = time.Now()
+	broker.Status = entitystore.StatusREADY
+	broker.Reason = nil
+	return nil
+}
+
+// Delete removes broker entities, the service classes of their catalogs are removed when syncing
+func (h *serviceBrokerEntityHandler) Delete(ctx context.Context, obj entitystore.Entity) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	var deleted entities.Broker
+	err := h.Store.Delete(ctx, obj.GetOrganizationID(), obj.GetName(), &deleted)
+	if err != nil {
+		err = errors.Wrapf(err, "error deleting broker entity %s/%s", obj.GetOrganizationID(), obj.GetName())
+		log.Error(err)
+		return err
+	}
+	return nil
+}
+
+// Sync refreshes the catalogs of the brokers last refreshed more than a refresh period ago
+func (h *serviceBrokerEntityHandler) Sync(ctx context.Context, resyncPeriod time.Duration) ([]entitystore.Entity, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	var brokers []*entities.Broker
+	if err := h.Store.List(ctx, h.OrganizationID, entitystore.Options{}, &brokers); err != nil {
+		return nil, errors.Wrap(err, "Sync error listing existing brokers")
+	}
+	var synced []entitystore.Entity
+	for _, broker := range brokers {
+		switch {
+		case broker.Delete:
+		case broker.Status == entitystore.StatusCREATING || broker.Status == entitystore.StatusUPDATING:
+		case h.RefreshPeriod > 0 && time.Since(broker.RefreshedTime) > h.RefreshPeriod:
+			log.Debugf("Refreshing the catalog of broker %s", broker.Name)
+			broker.SetStatus(entitystore.StatusUPDATING)
+		default:
+			continue
+		}
+		synced = append(synced, broker)
+	}
+	return synced, nil
+}
+
+// Error handles broker entities in the error state
+func (h *serviceBrokerEntityHandler) Error(ctx context.Context, obj entitystore.Entity) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	_, err := h.Store.Update(ctx, obj.GetRevision(), obj)
+	return err
 }
 
 type serviceClassEntityHandler struct {
@@ -82,9 +187,11 @@ func (h *serviceClassEntityHandler) needsUpdate(actual *entities.ServiceClass, e
 	existingJSON, _ := json.Marshal(existing.Plans)
 	if string(actualJSON) != string(existingJSON) ||
 		actual.Status != existing.Status ||
-		actual.Bindable != existing.Bindable {
+		actual.Bindable != existing.Bindable ||
+		actual.PlanUpdatable != existing.PlanUpdatable {
 		existing.Status = actual.Status
 		existing.Bindable = actual.Bindable
+		existing.PlanUpdatable = actual.PlanUpdatable
 		existing.Plans = actual.Plans
 		return existing, true
 	}
@@ -151,8 +258,9 @@ func (h *serviceClassEntityHandler) Error(ctx context.Context, obj entitystore.E
 }
 
 type serviceInstanceEntityHandler struct {
-	Store          entitystore.EntityStore
-	BrokerClient   clients.BrokerClient
+	Store        entitystore.EntityStore
+	BrokerClient clients.BrokerClient
+	// OrganizationID is the organization of the service classes
 	OrganizationID string
 }
 
@@ -171,7 +279,7 @@ func (h *serviceInstanceEntityHandler) Add(ctx context.Context, obj entitystore.
 	si := obj.(*entities.ServiceInstance)
 
 	var sc entities.ServiceClass
-	if err = h.Store.Get(ctx, si.OrganizationID, si.ServiceClass, entitystore.Options{}, &sc); err != nil {
+	if err = h.Store.Get(ctx, h.OrganizationID, si.ServiceClass, entitystore.Options{}, &sc); err != nil {
 		return
 	}
 
@@ -183,14 +291,74 @@ func (h *serviceInstanceEntityHandler) Add(ctx context.Context, obj entitystore.
 	return
 }
 
-// Update updates service instance entities
-func (h *serviceInstanceEntityHandler) Update(ctx context.Context, obj entitystore.Entity) error {
+// Update updates service instance entities.  The plan and parameters of the service instances being updated are sent
+// to the broker, the bindings of the service instances updated are refreshed once the update completed.
+func (h *serviceInstanceEntityHandler) Update(ctx context.Context, obj entitystore.Entity) (err error) {
 	span, ctx := trace.Trace(ctx, "")
 	defer span.Finish()
 
 	si := obj.(*entities.ServiceInstance)
-	_, err := h.Store.Update(ctx, si.GetRevision(), si)
-	return err
+	if si.Status == entitystore.StatusUPDATING && !si.UpdateInProgress {
+		err = h.updateService(ctx, si)
+	}
+	completed := err == nil && si.Status == entitystore.StatusREADY && si.PreviousPlan != ""
+	if completed {
+		si.PreviousPlan = ""
+		si.PreviousParameters = nil
+		si.UpdateInProgress = false
+	}
+	h.Store.UpdateWithError(ctx, si, err)
+	if completed {
+		h.refreshBindings(ctx, si)
+	}
+	return
+}
+
+// updateService sends the plan and parameters of a service instance to the broker, the previous ones are restored
+// if the broker fails to update the service
+func (h *serviceInstanceEntityHandler) updateService(ctx context.Context, si *entities.ServiceInstance) error {
+	var sc entities.ServiceClass
+	err := h.Store.Get(ctx, h.OrganizationID, si.ServiceClass, entitystore.Options{}, &sc)
+	if err == nil {
+		err = h.BrokerClient.UpdateService(&sc, si)
+	}
+	if err != nil {
+		restorePrevious(si)
+		return err
+	}
+	si.UpdateInProgress = si.Status == entitystore.StatusUPDATING
+	return nil
+}
+
+// refreshBindings marks the bindings of a service instance updated for refreshing, so their credentials are those
+// of the service updated
+func (h *serviceInstanceEntityHandler) refreshBindings(ctx context.Context, si *entities.ServiceInstance) {
+	var bindings []*entities.ServiceBinding
+	if err := h.Store.List(ctx, si.OrganizationID, entitystore.Options{}, &bindings); err != nil {
+		log.Errorf("Error listing the bindings of service instance %s: %v", si.Name, err)
+		return
+	}
+	for _, binding := range bindings {
+		if binding.ServiceInstance != si.Name || binding.Status != entitystore.StatusREADY || binding.Delete {
+			continue
+		}
+		binding.SetStatus(entitystore.StatusUPDATING)
+		if _, err := h.Store.Update(ctx, binding.Revision, binding); err != nil {
+			log.Errorf("Error refreshing binding %s of service instance %s: %v", binding.Name, si.Name, err)
+		}
+	}
+}
+
+// restorePrevious restores the plan and parameters of a service instance before an update which failed
+func restorePrevious(si *entities.ServiceInstance) {
+	if si.PreviousPlan == "" {
+		return
+	}
+	si.ServicePlan = si.PreviousPlan
+	si.Parameters = si.PreviousParameters
+	si.PreviousPlan = ""
+	si.PreviousParameters = nil
+	si.UpdateInProgress = false
 }
 
 // Delete deletes service instance entities
@@ -200,10 +368,21 @@ func (h *serviceInstanceEntityHandler) Delete(ctx context.Context, obj entitysto
 
 	si := obj.(*entities.ServiceInstance)
 
-	var b entities.ServiceBinding
-	found, err := h.Store.Find(ctx, si.GetOrganizationID(), si.GetID(), entitystore.Options{}, &b)
-	if found {
-		log.Debugf("waiting to delete service instance %s, binding still exists")
+	// the bindings of the instance are deleted first
+	filter := entitystore.FilterEverything().Add(
+		entitystore.FilterStat{
+			Scope:   entitystore.FilterScopeExtra,
+			Subject: "ServiceInstance",
+			Verb:    entitystore.FilterVerbEqual,
+			Object:  si.Name,
+		})
+	var bindings []*entities.ServiceBinding
+	err := h.Store.List(ctx, si.GetOrganizationID(), entitystore.Options{Filter: filter}, &bindings)
+	if err != nil {
+		return errors.Wrapf(err, "error listing the bindings of service instance %s", si.Name)
+	}
+	if len(bindings) > 0 {
+		log.Debugf("waiting to delete service instance %s, %d bindings still exist", si.Name, len(bindings))
 		return nil
 	}
 
@@ -240,8 +419,7 @@ func (h *serviceInstanceEntityHandler) Sync(ctx context.Context, resyncPeriod ti
 		actualMap[si.ID] = si
 	}
 
-	var existing []*entities.ServiceInstance
-	err = h.Store.List(ctx, h.OrganizationID, entitystore.Options{}, &existing)
+	existing, err := listServiceInstances(ctx, h.Store)
 	if err != nil {
 		return nil, errors.Wrap(err, "Sync error listing exising service instances")
 	}
@@ -254,6 +432,12 @@ func (h *serviceInstanceEntityHandler) Sync(ctx context.Context, resyncPeriod ti
 			synced = append(synced, instance)
 			continue
 		}
+		if instance.Status == entitystore.StatusUPDATING && !instance.UpdateInProgress && !instance.Delete {
+			// Hasn't been sent to the broker yet, so let's do that.
+			delete(actualMap, instance.ID)
+			synced = append(synced, instance)
+			continue
+		}
 		if instance.Delete {
 			// Marked for deletion... ignore actual status - though we need to start tracking
 			// actual state separately from desired stated (i.e. marked for delete, but is currently
@@ -294,19 +478,20 @@ func (h *serviceInstanceEntityHandler) Sync(ctx context.Context, resyncPeriod ti
 	return synced, err
 }
 
-// Error handles service class entities in the error state
+// Error handles service instance entities in the error state, the plan and parameters before a failed update are
+// restored
 func (h *serviceInstanceEntityHandler) Error(ctx context.Context, obj entitystore.Entity) error {
 	span, ctx := trace.Trace(ctx, "")
 	defer span.Finish()
 
+	restorePrevious(obj.(*entities.ServiceInstance))
 	_, err := h.Store.Update(ctx, obj.GetRevision(), obj)
 	return err
 }
 
 type serviceBindingEntityHandler struct {
-	OrganizationID string
-	Store          entitystore.EntityStore
-	BrokerClient   clients.BrokerClient
+	Store        entitystore.EntityStore
+	BrokerClient clients.BrokerClient
 }
 
 // Type returns the type of the entity associated to this handler
@@ -336,13 +521,25 @@ func (h *serviceBindingEntityHandler) Add(ctx context.Context, obj entitystore.E
 	return
 }
 
-// Update updates service class entities
-func (h *serviceBindingEntityHandler) Update(ctx context.Context, obj entitystore.Entity) error {
+// Update updates service binding entities, the bindings being updated are bound again to refresh their credentials
+func (h *serviceBindingEntityHandler) Update(ctx context.Context, obj entitystore.Entity) (err error) {
 	span, ctx := trace.Trace(ctx, "")
 	defer span.Finish()
 
-	_, err := h.Store.Update(ctx, obj.GetRevision(), obj)
-	return err
+	b := obj.(*entities.ServiceBinding)
+	if b.Status != entitystore.StatusUPDATING {
+		_, err = h.Store.Update(ctx, b.GetRevision(), b)
+		return
+	}
+
+	defer func() { h.Store.UpdateWithError(ctx, b, err) }()
+
+	var si entities.ServiceInstance
+	if err = h.Store.Get(ctx, b.OrganizationID, b.ServiceInstance, entitystore.Options{}, &si); err != nil {
+		return
+	}
+	err = h.BrokerClient.RefreshBinding(&si, b)
+	return
 }
 
 // Delete removes service binding entities
@@ -385,26 +582,33 @@ func (h *serviceBindingEntityHandler) Sync(ctx context.Context, resyncPeriod tim
 	}
 
 	var existing []*entities.ServiceBinding
-	err = h.Store.List(ctx, h.OrganizationID, entitystore.Options{}, &existing)
+	orgIDs, err := h.Store.ListOrgIDs(ctx)
 	if err != nil {
-		return nil, errors.Wrap(err, "Sync error listing exising service bindings")
+		return nil, errors.Wrap(err, "Sync error listing organizations")
+	}
+	for _, orgID := range orgIDs {
+		var orgBindings []*entities.ServiceBinding
+		if err = h.Store.List(ctx, orgID, entitystore.Options{}, &orgBindings); err != nil {
+			return nil, errors.Wrap(err, "Sync error listing exising service bindings")
+		}
+		existing = append(existing, orgBindings...)
 	}
 
-	var existingServices []*entities.ServiceInstance
-	err = h.Store.List(ctx, h.OrganizationID, entitystore.Options{}, &existingServices)
+	existingServices, err := listServiceInstances(ctx, h.Store)
 	if err != nil {
 		return nil, errors.Wrap(err, "Sync error listing exising services")
 	}
+	// Services are named uniquely within their organization
 	serviceMap := make(map[string]*entities.ServiceInstance)
 	for _, service := range existingServices {
-		serviceMap[service.Name] = service
+		serviceMap[service.OrganizationID+"/"+service.Name] = service
 	}
 
 	var synced []entitystore.Entity
 
 	for _, binding := range existing {
 		log.Debugf("Processing service binding %s [%s]", binding.Name, binding.Status)
-		if _, ok := serviceMap[binding.ServiceInstance]; !ok {
+		if _, ok := serviceMap[binding.OrganizationID+"/"+binding.ServiceInstance]; !ok {
 			log.Debugf("Service for binding %s missing, delete", binding.Name)
 			// No matching service exists... delete
 			binding.SetDelete(true)
@@ -417,11 +621,39 @@ func (h *serviceBindingEntityHandler) Sync(ctx context.Context, resyncPeriod tim
 			synced = append(synced, binding)
 			continue
 		}
+		if binding.Status == entitystore.StatusUPDATING && !binding.Delete {
+			// Hasn't been refreshed yet, so let's do that.
+			delete(actualMap, binding.BindingID)
+			synced = append(synced, binding)
+			continue
+		}
 		if binding.Delete {
 			// Marked for deletion... ignore actual status - though we need to start tracking
 			// actual state separately from desired stated (i.e. marked for delete, but is currently
 			// in ready state)
 			delete(actualMap, binding.BindingID)
+			delete(actualMap, binding.PendingBindingID)
+			synced = append(synced, binding)
+			continue
+		}
+		if pending, ok := actualMap[binding.PendingBindingID]; ok && binding.PendingBindingID != "" {
+			// Being rotated, the binding switches to its pending binding once ready. The previous binding is left
+			// until the switch is stored, it is then deleted as an orphan.
+			delete(actualMap, binding.BindingID)
+			delete(actualMap, binding.PendingBindingID)
+			switch pending.Status {
+			case entitystore.StatusREADY:
+				binding.BindingID, binding.PendingBindingID = binding.PendingBindingID, ""
+				binding.SetStatus(entitystore.StatusREADY)
+				binding.Reason = nil
+			case entitystore.StatusERROR:
+				// the binding keeps the credentials it had before, the pending binding is deleted as an orphan
+				binding.PendingBindingID = ""
+				binding.SetStatus(entitystore.StatusERROR)
+				binding.SetReason(pending.Reason)
+			default:
+				continue
+			}
 			synced = append(synced, binding)
 			continue
 		}
@@ -463,15 +695,35 @@ func (h *serviceBindingEntityHandler) Error(ctx context.Context, obj entitystore
 	return err
 }
 
+// listServiceInstances lists the service instances of all organizations
+func listServiceInstances(ctx context.Context, store entitystore.EntityStore) ([]*entities.ServiceInstance, error) {
+	orgIDs, err := store.ListOrgIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var instances []*entities.ServiceInstance
+	for _, orgID := range orgIDs {
+		var orgInstances []*entities.ServiceInstance
+		if err := store.List(ctx, orgID, entitystore.Options{}, &orgInstances); err != nil {
+			return nil, err
+		}
+		instances = append(instances, orgInstances...)
+	}
+	return instances, nil
+}
+
 // NewController creates a new service manager controller
 func NewController(config *ControllerConfig, store entitystore.EntityStore, brokerClient clients.BrokerClient) controller.Controller {
 	c := controller.NewController(controller.Options{
 		ResyncPeriod: config.ResyncPeriod,
 		Workers:      10, // want more functions concurrently? add more workers // TODO configure workers
+		Store:        store,
+		Ownership:    config.Ownership,
 	})
 
+	c.AddEntityHandler(&serviceBrokerEntityHandler{Store: store, BrokerClient: brokerClient, OrganizationID: flags.ServiceManagerFlags.OrgID, RefreshPeriod: config.BrokerRefreshPeriod})
 	c.AddEntityHandler(&serviceClassEntityHandler{Store: store, BrokerClient: brokerClient, OrganizationID: flags.ServiceManagerFlags.OrgID})
 	c.AddEntityHandler(&serviceInstanceEntityHandler{Store: store, BrokerClient: brokerClient, OrganizationID: flags.ServiceManagerFlags.OrgID})
-	c.AddEntityHandler(&serviceBindingEntityHandler{Store: store, BrokerClient: brokerClient, OrganizationID: flags.ServiceManagerFlags.OrgID})
+	c.AddEntityHandler(&serviceBindingEntityHandler{Store: store, BrokerClient: brokerClient})
 	return c
 }
@@ -7,11 +7,13 @@ package servicemanager
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/vmware/dispatch/pkg/entity-store"
 
@@ -135,6 +137,88 @@ func TestServiceInstanceAdd(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestServiceInstanceUpdate(t *testing.T) {
+	es := helpers.MakeEntityStore(t)
+	client := &mocks.BrokerClient{}
+
+	handler := serviceInstanceEntityHandler{
+		OrganizationID: "test",
+		Store:          es,
+		BrokerClient:   client,
+	}
+
+	class := entities.ServiceClass{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: "test",
+			Name:           "class",
+			Status:         entitystore.StatusREADY,
+		},
+		ServiceID:     "deadbeef",
+		PlanUpdatable: true,
+	}
+	_, err := es.Add(context.Background(), &class)
+	assert.NoError(t, err)
+
+	instance := entities.ServiceInstance{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: "test",
+			Name:           "instance",
+			Status:         entitystore.StatusUPDATING,
+		},
+		ServiceClass:       "class",
+		ServicePlan:        "large",
+		Parameters:         map[string]interface{}{"size": 3},
+		PreviousPlan:       "small",
+		PreviousParameters: map[string]interface{}{"size": 1},
+	}
+	_, err = es.Add(context.Background(), &instance)
+	assert.NoError(t, err)
+	binding := entities.ServiceBinding{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: "test",
+			Name:           "instance",
+			Status:         entitystore.StatusREADY,
+		},
+		ServiceInstance: "instance",
+	}
+	_, err = es.Add(context.Background(), &binding)
+	assert.NoError(t, err)
+
+	// the previous plan is restored when the broker fails
+	client.On("UpdateService", mock.Anything, &instance).Return(errors.New("plan change refused")).Once()
+	err = handler.Update(context.Background(), &instance)
+	assert.Error(t, err)
+	assert.Equal(t, entitystore.StatusERROR, instance.Status)
+	assert.Equal(t, "small", instance.ServicePlan)
+	assert.Empty(t, instance.PreviousPlan)
+
+	instance.SetStatus(entitystore.StatusUPDATING)
+	instance.ServicePlan = "large"
+	instance.PreviousPlan = "small"
+	_, err = es.Update(context.Background(), instance.Revision, &instance)
+	assert.NoError(t, err)
+
+	// the broker updates the service asynchronously
+	client.On("UpdateService", mock.Anything, &instance).Return(nil).Once()
+	err = handler.Update(context.Background(), &instance)
+	assert.NoError(t, err)
+	assert.True(t, instance.UpdateInProgress)
+	assert.Equal(t, "small", instance.PreviousPlan)
+
+	// the update completed, the binding is refreshed
+	instance.SetStatus(entitystore.StatusREADY)
+	err = handler.Update(context.Background(), &instance)
+	assert.NoError(t, err)
+	assert.Equal(t, "large", instance.ServicePlan)
+	assert.Empty(t, instance.PreviousPlan)
+	assert.False(t, instance.UpdateInProgress)
+	client.AssertNumberOfCalls(t, "UpdateService", 2)
+
+	err = es.Get(context.Background(), "test", "instance", entitystore.Options{}, &binding)
+	assert.NoError(t, err)
+	assert.Equal(t, entitystore.StatusUPDATING, binding.Status)
+}
+
 func TestServiceInstanceDelete(t *testing.T) {
 	es := helpers.MakeEntityStore(t)
 	client := &mocks.BrokerClient{}
@@ -161,6 +245,24 @@ func TestServiceInstanceDelete(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, found)
 
+	// the instance is deleted once all its bindings are
+	binding := entities.ServiceBinding{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: "test",
+			Name:           "readonly",
+			Status:         entitystore.StatusDELETING,
+			Delete:         true,
+		},
+		ServiceInstance: "instance",
+	}
+	_, err = es.Add(context.Background(), &binding)
+	assert.NoError(t, err)
+	err = handler.Delete(context.Background(), &instance)
+	assert.NoError(t, err)
+	client.AssertNotCalled(t, "DeleteService", &instance)
+
+	var deleted entities.ServiceBinding
+	assert.NoError(t, es.Delete(context.Background(), binding.OrganizationID, binding.Name, &deleted))
 	client.On("DeleteService", &instance).Return(nil).Once()
 	err = handler.Delete(context.Background(), &instance)
 	assert.NoError(t, err)
@@ -298,9 +400,8 @@ func TestServiceBindingAdd(t *testing.T) {
 	client := &mocks.BrokerClient{}
 
 	handler := serviceBindingEntityHandler{
-		OrganizationID: "test",
-		Store:          es,
-		BrokerClient:   client,
+		Store:        es,
+		BrokerClient: client,
 	}
 
 	initializedService := entities.ServiceInstance{
@@ -337,14 +438,57 @@ func TestServiceBindingAdd(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestServiceBindingUpdate(t *testing.T) {
+	es := helpers.MakeEntityStore(t)
+	client := &mocks.BrokerClient{}
+
+	handler := serviceBindingEntityHandler{
+		Store:        es,
+		BrokerClient: client,
+	}
+
+	service := entities.ServiceInstance{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: "test",
+			Name:           "instance",
+			Status:         entitystore.StatusREADY,
+		},
+		ServiceClass: "class",
+	}
+	_, err := es.Add(context.Background(), &service)
+	assert.NoError(t, err)
+	binding := entities.ServiceBinding{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: "test",
+			Name:           "binding",
+			Status:         entitystore.StatusUPDATING,
+		},
+		ServiceInstance: "instance",
+	}
+	_, err = es.Add(context.Background(), &binding)
+	assert.NoError(t, err)
+
+	// the binding is refreshed once its service was updated
+	client.On("RefreshBinding", mock.AnythingOfType("*entities.ServiceInstance"), &binding).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*entities.ServiceBinding).SetStatus(entitystore.StatusREADY)
+	}).Once()
+	err = handler.Update(context.Background(), &binding)
+	assert.NoError(t, err)
+	assert.Equal(t, entitystore.StatusREADY, binding.Status)
+
+	// not refreshed again
+	err = handler.Update(context.Background(), &binding)
+	assert.NoError(t, err)
+	client.AssertNumberOfCalls(t, "RefreshBinding", 1)
+}
+
 func TestServiceBindingDelete(t *testing.T) {
 	es := helpers.MakeEntityStore(t)
 	client := &mocks.BrokerClient{}
 
 	handler := serviceBindingEntityHandler{
-		OrganizationID: "test",
-		Store:          es,
-		BrokerClient:   client,
+		Store:        es,
+		BrokerClient: client,
 	}
 
 	readyBinding := entities.ServiceBinding{
@@ -373,9 +517,8 @@ func TestServiceBindingSyncInitialized(t *testing.T) {
 	client := &mocks.BrokerClient{}
 
 	handler := serviceBindingEntityHandler{
-		OrganizationID: "test",
-		Store:          es,
-		BrokerClient:   client,
+		Store:        es,
+		BrokerClient: client,
 	}
 
 	initializedService := entities.ServiceInstance{
@@ -411,9 +554,8 @@ func TestServiceBindingSyncMissingService(t *testing.T) {
 	client := &mocks.BrokerClient{}
 
 	handler := serviceBindingEntityHandler{
-		OrganizationID: "test",
-		Store:          es,
-		BrokerClient:   client,
+		Store:        es,
+		BrokerClient: client,
 	}
 
 	initializedBinding := entities.ServiceBinding{
@@ -441,9 +583,8 @@ func TestServiceBindingSyncReady(t *testing.T) {
 	client := &mocks.BrokerClient{}
 
 	handler := serviceBindingEntityHandler{
-		OrganizationID: "test",
-		Store:          es,
-		BrokerClient:   client,
+		Store:        es,
+		BrokerClient: client,
 	}
 
 	readyService := entities.ServiceInstance{
@@ -499,3 +640,147 @@ func TestServiceBindingSyncReady(t *testing.T) {
 	assert.Equal(t, entitystore.StatusDELETING, bindings[0].GetStatus())
 	assert.Equal(t, orphan.Name, bindings[0].GetName())
 }
+
+func TestServiceBindingSyncRotated(t *testing.T) {
+	es := helpers.MakeEntityStore(t)
+	client := &mocks.BrokerClient{}
+
+	handler := serviceBindingEntityHandler{
+		Store:        es,
+		BrokerClient: client,
+	}
+
+	readyService := entities.ServiceInstance{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: "test",
+			Name:           "instance",
+			Status:         entitystore.StatusREADY,
+		},
+		ServiceClass: "class",
+	}
+	_, err := es.Add(context.Background(), &readyService)
+	assert.NoError(t, err)
+	rotatedBinding := entities.ServiceBinding{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: "test",
+			Name:           "binding",
+			Status:         entitystore.StatusCREATING,
+		},
+		ServiceInstance:  "instance",
+		BindingID:        "previous",
+		PendingBindingID: "pending",
+	}
+	_, err = es.Add(context.Background(), &rotatedBinding)
+	assert.NoError(t, err)
+
+	previous := &entities.ServiceBinding{
+		BaseEntity: entitystore.BaseEntity{OrganizationID: "test", Status: entitystore.StatusREADY},
+		BindingID:  "previous",
+	}
+	pending := &entities.ServiceBinding{
+		BaseEntity: entitystore.BaseEntity{OrganizationID: "test", Status: entitystore.StatusUNKNOWN},
+		BindingID:  "pending",
+	}
+	client.On("ListServiceBindings").Return([]entitystore.Entity{previous, pending}, nil).Once()
+	// The pending binding is not bound yet... the binding keeps the previous one
+	bindings, err := handler.Sync(context.Background(), time.Duration(1))
+	assert.NoError(t, err)
+	assert.Len(t, bindings, 0)
+
+	pending.Status = entitystore.StatusREADY
+	client.On("ListServiceBindings").Return([]entitystore.Entity{previous, pending}, nil).Once()
+	// The pending binding is bound... the binding switches to it, the previous one is left until the switch is stored
+	bindings, err = handler.Sync(context.Background(), time.Duration(1))
+	assert.NoError(t, err)
+	require.Len(t, bindings, 1)
+	switched := bindings[0].(*entities.ServiceBinding)
+	assert.Equal(t, entitystore.StatusREADY, switched.Status)
+	assert.Equal(t, "pending", switched.BindingID)
+	assert.Empty(t, switched.PendingBindingID)
+	_, err = es.Update(context.Background(), switched.Revision, switched)
+	assert.NoError(t, err)
+
+	client.On("ListServiceBindings").Return([]entitystore.Entity{previous, pending}, nil).Once()
+	// The previous binding is an orphan
+	bindings, err = handler.Sync(context.Background(), time.Duration(1))
+	assert.NoError(t, err)
+	require.Len(t, bindings, 1)
+	assert.True(t, bindings[0].GetDelete())
+	assert.Equal(t, "previous", bindings[0].(*entities.ServiceBinding).BindingID)
+}
+
+// catalogBrokerClient is a broker client fetching the catalogs of the brokers registered
+type catalogBrokerClient struct {
+	mocks.BrokerClient
+	catalog []entities.ServiceClass
+	err     error
+}
+
+func (c *catalogBrokerClient) FetchCatalog(*entities.Broker) ([]entities.ServiceClass, error) {
+	return c.catalog, c.err
+}
+
+func TestServiceBrokerAdd(t *testing.T) {
+	es := helpers.MakeEntityStore(t)
+	client := &catalogBrokerClient{
+		catalog: []entities.ServiceClass{
+			{
+				BaseEntity: entitystore.BaseEntity{Name: "classA", Status: entitystore.StatusREADY},
+				ServiceID:  "deadbeef",
+				Broker:     "brokerA",
+			},
+		},
+	}
+
+	handler := serviceBrokerEntityHandler{
+		OrganizationID: "test",
+		Store:          es,
+		BrokerClient:   client,
+		RefreshPeriod:  time.Hour,
+	}
+
+	broker := entities.Broker{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: "test",
+			Name:           "brokerA",
+			Status:         entitystore.StatusCREATING,
+		},
+		URL: "http://broker.example.com",
+	}
+	_, err := es.Add(context.Background(), &broker)
+	assert.NoError(t, err)
+
+	err = handler.Add(context.Background(), &broker)
+	assert.NoError(t, err)
+
+	var refreshed entities.Broker
+	err = es.Get(context.Background(), "test", "brokerA", entitystore.Options{}, &refreshed)
+	assert.NoError(t, err)
+	assert.Equal(t, entitystore.StatusREADY, refreshed.Status)
+	assert.Len(t, refreshed.Catalog, 1)
+	assert.False(t, refreshed.RefreshedTime.IsZero())
+
+	// Refreshed within the refresh period
+	brokers, err := handler.Sync(context.Background(), time.Duration(1))
+	assert.NoError(t, err)
+	assert.Len(t, brokers, 0)
+
+	// A failing broker keeps the catalog last fetched
+	client.err = errors.New("broker unavailable")
+	refreshed.Status = entitystore.StatusUPDATING
+	err = handler.Update(context.Background(), &refreshed)
+	assert.Error(t, err)
+
+	var failed entities.Broker
+	err = es.Get(context.Background(), "test", "brokerA", entitystore.Options{}, &failed)
+	assert.NoError(t, err)
+	assert.Equal(t, entitystore.StatusERROR, failed.Status)
+	assert.Len(t, failed.Catalog, 1)
+
+	// Refreshed once the refresh period elapsed
+	handler.RefreshPeriod = time.Nanosecond
+	brokers, err = handler.Sync(context.Background(), time.Duration(1))
+	assert.NoError(t, err)
+	assert.Len(t, brokers, 1)
+	assert.Equal(t, entitystore.StatusUPDATING, brokers[0].GetStatus())
+}
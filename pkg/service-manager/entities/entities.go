@@ -6,6 +6,8 @@
 package entities
 
 import (
+	"time"
+
 	"github.com/go-openapi/spec"
 	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/swag"
@@ -20,6 +22,13 @@ import (
 type Broker struct {
 	entitystore.BaseEntity
 	URL string `json:"url"`
+	// AuthSecret is the secret holding the credentials of the broker, either a username and a password for basic
+	// authentication, or a bearer token
+	AuthSecret string `json:"authSecret,omitempty"`
+	// RefreshedTime is when the catalog of the broker was last fetched
+	RefreshedTime time.Time `json:"refreshedTime,omitempty"`
+	// Catalog are the service classes of the catalog last fetched, reconciled with the service classes when syncing
+	Catalog []ServiceClass `json:"catalog,omitempty"`
 }
 
 // Schema represents contract for the three service operations (Create, Update, and Bind).
@@ -48,6 +57,8 @@ type ServiceClass struct {
 	Broker      string        `json:"broker"`
 	Bindable    bool          `json:"bindable"`
 	Plans       []ServicePlan `json:"plans"`
+	// PlanUpdatable is whether the plan of the instances of the service can be changed
+	PlanUpdatable bool `json:"planUpdatable"`
 }
 
 // ServiceBinding represents a binding or connection to the service.  Generally this is in the form of credentials
@@ -59,6 +70,13 @@ type ServiceBinding struct {
 	SecretParameters []string    `json:"secretParameters"`
 	BindingID        string      `json:"bindingID"`
 	BindingSecret    string      `json:"bindingSecret"`
+	// PendingBindingID is the binding replacing BindingID while the binding is rotated, the functions get the
+	// credentials of BindingID until it is bound
+	PendingBindingID string `json:"pendingBindingID,omitempty"`
+	// Operation is the asynchronous operation of the broker binding the service, polled until it completes
+	Operation string `json:"operation,omitempty"`
+	// Requester is who requested the binding, sent to the broker as the originating identity
+	Requester string `json:"requester,omitempty"`
 }
 
 // ServiceInstance represents a provisioned service.
@@ -71,6 +89,16 @@ type ServiceInstance struct {
 	SecretParameters []string    `json:"secretParameters"`
 	InstanceID       string      `json:"instanceID"`
 	Bind             bool        `json:"bind"`
+	// Operation is the asynchronous operation of the broker provisioning the service, polled until it completes
+	Operation string `json:"operation,omitempty"`
+	// Requester is who requested the service, sent to the broker as the originating identity
+	Requester string `json:"requester,omitempty"`
+	// PreviousPlan and PreviousParameters are the plan and parameters before an update, sent to the broker as the
+	// previous values and restored if the update fails.  They are cleared once the update completes.
+	PreviousPlan       string      `json:"previousPlan,omitempty"`
+	PreviousParameters interface{} `json:"previousParameters,omitempty"`
+	// UpdateInProgress is set while the broker is updating the service
+	UpdateInProgress bool `json:"updateInProgress,omitempty"`
 }
 
 var statusMap = map[v1.Status]entitystore.Status{
@@ -79,6 +107,8 @@ var statusMap = map[v1.Status]entitystore.Status{
 	v1.StatusERROR:       entitystore.StatusERROR,
 	v1.StatusINITIALIZED: entitystore.StatusINITIALIZED,
 	v1.StatusREADY:       entitystore.StatusREADY,
+	v1.StatusUPDATING:    entitystore.StatusUPDATING,
+	v1.StatusDELETING:    entitystore.StatusDELETING,
 }
 var reverseStatusMap = make(map[entitystore.Status]v1.Status)
 
@@ -89,6 +119,49 @@ func InitializeStatusMap() {
 	}
 }
 
+// BrokerEntityToModel translates the Broker entity representation (DB) to the model representation (API).  The
+// credentials of the broker are never returned.
+func BrokerEntityToModel(e *Broker) *v1.ServiceBroker {
+	var tags []*v1.Tag
+	for k, v := range e.Tags {
+		tags = append(tags, &v1.Tag{Key: k, Value: v})
+	}
+
+	m := v1.ServiceBroker{
+		CreatedTime:  e.CreatedTime.Unix(),
+		ModifiedTime: e.ModifiedTime.Unix(),
+		ID:           strfmt.UUID(e.ID),
+		Name:         swag.String(e.Name),
+		Kind:         utils.ServiceBrokerKind,
+		Status:       reverseStatusMap[e.Status],
+		Tags:         tags,
+		Reason:       e.Reason,
+		URL:          swag.String(e.URL),
+	}
+	if !e.RefreshedTime.IsZero() {
+		m.RefreshedTime = e.RefreshedTime.Unix()
+	}
+	return &m
+}
+
+// BrokerModelToEntity translates the Broker model representation (API) to the entity representation (DB).  Brokers
+// are shared by all organizations, the credentials are stored separately.
+func BrokerModelToEntity(m *v1.ServiceBroker) *Broker {
+	tags := make(map[string]string)
+	for _, t := range m.Tags {
+		tags[t.Key] = t.Value
+	}
+	e := Broker{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: flags.ServiceManagerFlags.OrgID,
+			Name:           *m.Name,
+			Tags:           tags,
+		},
+		URL: *m.URL,
+	}
+	return &e
+}
+
 // ServiceClassEntityToModel translates the ServiceClass entity representation (DB) to the model representation (API).
 func ServiceClassEntityToModel(e *ServiceClass) *v1.ServiceClass {
 	var tags []*v1.Tag
@@ -148,8 +221,7 @@ func ServiceClassModelToEntity(m *v1.ServiceClass) *ServiceClass {
 }
 
 // ServiceInstanceEntityToModel translates the ServiceInstance entity representation (DB) to the model representation
-// (API).  Notice that the ServiceBinding is includeded as the API does not have a separate binding endpoint.
-// Services are always created with a single binding.
+// (API).  Notice that the default ServiceBinding, named after the instance, is included.
 func ServiceInstanceEntityToModel(e *ServiceInstance, b *ServiceBinding) *v1.ServiceInstance {
 	var tags []*v1.Tag
 	for k, v := range e.Tags {
@@ -170,27 +242,21 @@ func ServiceInstanceEntityToModel(e *ServiceInstance, b *ServiceBinding) *v1.Ser
 		SecretParameters: e.SecretParameters,
 	}
 	if b != nil {
-		m.Binding = &v1.ServiceBinding{
-			Status:           reverseStatusMap[b.Status],
-			Parameters:       b.Parameters,
-			SecretParameters: b.SecretParameters,
-			BindingSecret:    b.BindingSecret,
-		}
+		m.Binding = ServiceBindingEntityToModel(b)
 	}
 	return &m
 }
 
 // ServiceInstanceModelToEntity translates the ServiceInstance model representation (API) to the entity representation
-// (DB).  Notice that the ServiceBinding is includeded as the API does not have a separate binding endpoint.
-// Services are always created with a single binding.
-func ServiceInstanceModelToEntity(m *v1.ServiceInstance) (*ServiceInstance, *ServiceBinding) {
+// (DB) in the given organization.  Notice that the default ServiceBinding, named after the instance, is included.
+func ServiceInstanceModelToEntity(organizationID string, m *v1.ServiceInstance) (*ServiceInstance, *ServiceBinding) {
 	tags := make(map[string]string)
 	for _, t := range m.Tags {
 		tags[t.Key] = t.Value
 	}
 	e := ServiceInstance{
 		BaseEntity: entitystore.BaseEntity{
-			OrganizationID: flags.ServiceManagerFlags.OrgID,
+			OrganizationID: organizationID,
 			Name:           *m.Name,
 			Tags:           tags,
 		},
@@ -201,7 +267,7 @@ func ServiceInstanceModelToEntity(m *v1.ServiceInstance) (*ServiceInstance, *Ser
 	}
 	b := ServiceBinding{
 		BaseEntity: entitystore.BaseEntity{
-			OrganizationID: flags.ServiceManagerFlags.OrgID,
+			OrganizationID: organizationID,
 			Name:           *m.Name,
 		},
 	}
@@ -212,3 +278,47 @@ func ServiceInstanceModelToEntity(m *v1.ServiceInstance) (*ServiceInstance, *Ser
 	}
 	return &e, &b
 }
+
+// ServiceBindingEntityToModel translates the ServiceBinding entity representation (DB) to the model representation
+// (API).
+func ServiceBindingEntityToModel(e *ServiceBinding) *v1.ServiceBinding {
+	var tags []*v1.Tag
+	for k, v := range e.Tags {
+		tags = append(tags, &v1.Tag{Key: k, Value: v})
+	}
+
+	return &v1.ServiceBinding{
+		CreatedTime:      e.CreatedTime.Unix(),
+		ID:               strfmt.UUID(e.ID),
+		Name:             e.Name,
+		Kind:             utils.ServiceBindingKind,
+		Status:           reverseStatusMap[e.Status],
+		Tags:             tags,
+		Reason:           e.Reason,
+		ServiceInstance:  e.ServiceInstance,
+		Parameters:       e.Parameters,
+		SecretParameters: e.SecretParameters,
+		BindingID:        e.BindingID,
+		BindingSecret:    e.BindingSecret,
+	}
+}
+
+// ServiceBindingModelToEntity translates the ServiceBinding model representation (API) to the entity representation
+// (DB) in the given organization.
+func ServiceBindingModelToEntity(organizationID string, m *v1.ServiceBinding) *ServiceBinding {
+	tags := make(map[string]string)
+	for _, t := range m.Tags {
+		tags[t.Key] = t.Value
+	}
+	return &ServiceBinding{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: organizationID,
+			Name:           m.Name,
+			Tags:           tags,
+		},
+		ServiceInstance:  m.ServiceInstance,
+		Parameters:       m.Parameters,
+		SecretParameters: m.SecretParameters,
+		BindingSecret:    m.BindingSecret,
+	}
+}
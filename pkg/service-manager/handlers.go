@@ -6,21 +6,28 @@
 package servicemanager
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/vmware/dispatch/pkg/controller"
 	"github.com/vmware/dispatch/pkg/utils"
 
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
 	entitystore "github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/service-manager/entities"
 	"github.com/vmware/dispatch/pkg/service-manager/flags"
 	"github.com/vmware/dispatch/pkg/service-manager/gen/restapi/operations"
+	servicebinding "github.com/vmware/dispatch/pkg/service-manager/gen/restapi/operations/service_binding"
+	servicebroker "github.com/vmware/dispatch/pkg/service-manager/gen/restapi/operations/service_broker"
 	serviceclass "github.com/vmware/dispatch/pkg/service-manager/gen/restapi/operations/service_class"
 	serviceinstance "github.com/vmware/dispatch/pkg/service-manager/gen/restapi/operations/service_instance"
 	"github.com/vmware/dispatch/pkg/trace"
@@ -30,6 +37,8 @@ import (
 type Handlers struct {
 	Store   entitystore.EntityStore
 	Watcher controller.Watcher
+	// SecretsClient stores the credentials of the brokers registered
+	SecretsClient client.SecretsClient
 }
 
 // NewHandlers is the constructor for the Handlers type
@@ -55,6 +64,12 @@ func (h *Handlers) ConfigureHandlers(api middleware.RoutableAPI) {
 		return token, nil
 	}
 
+	a.ServiceBrokerAddServiceBrokerHandler = servicebroker.AddServiceBrokerHandlerFunc(h.addServiceBroker)
+	a.ServiceBrokerGetServiceBrokerByNameHandler = servicebroker.GetServiceBrokerByNameHandlerFunc(h.getServiceBrokerByName)
+	a.ServiceBrokerGetServiceBrokersHandler = servicebroker.GetServiceBrokersHandlerFunc(h.getServiceBrokers)
+	a.ServiceBrokerDeleteServiceBrokerByNameHandler = servicebroker.DeleteServiceBrokerByNameHandlerFunc(h.deleteServiceBrokerByName)
+	a.ServiceBrokerRefreshServiceBrokerHandler = servicebroker.RefreshServiceBrokerHandlerFunc(h.refreshServiceBroker)
+
 	a.ServiceClassGetServiceClassByNameHandler = serviceclass.GetServiceClassByNameHandlerFunc(h.getServiceClassByName)
 	a.ServiceClassGetServiceClassesHandler = serviceclass.GetServiceClassesHandlerFunc(h.getServiceClasses)
 
@@ -62,6 +77,256 @@ func (h *Handlers) ConfigureHandlers(api middleware.RoutableAPI) {
 	a.ServiceInstanceGetServiceInstanceByNameHandler = serviceinstance.GetServiceInstanceByNameHandlerFunc(h.getServiceInstanceByName)
 	a.ServiceInstanceGetServiceInstancesHandler = serviceinstance.GetServiceInstancesHandlerFunc(h.getServiceInstances)
 	a.ServiceInstanceDeleteServiceInstanceByNameHandler = serviceinstance.DeleteServiceInstanceByNameHandlerFunc(h.deleteServiceInstanceByName)
+	a.ServiceInstanceUpdateServiceInstanceByNameHandler = serviceinstance.UpdateServiceInstanceByNameHandlerFunc(h.updateServiceInstanceByName)
+
+	a.ServiceBindingAddServiceBindingHandler = servicebinding.AddServiceBindingHandlerFunc(h.addServiceBinding)
+	a.ServiceBindingGetServiceBindingByNameHandler = servicebinding.GetServiceBindingByNameHandlerFunc(h.getServiceBindingByName)
+	a.ServiceBindingGetServiceBindingsHandler = servicebinding.GetServiceBindingsHandlerFunc(h.getServiceBindings)
+	a.ServiceBindingDeleteServiceBindingByNameHandler = servicebinding.DeleteServiceBindingByNameHandlerFunc(h.deleteServiceBindingByName)
+	a.ServiceBindingRotateServiceBindingHandler = servicebinding.RotateServiceBindingHandlerFunc(h.rotateServiceBinding)
+}
+
+// brokerSecretName is the name of the secret holding the credentials of a broker
+func brokerSecretName(brokerName string) string {
+	return "broker-" + brokerName
+}
+
+func (h *Handlers) addServiceBroker(params servicebroker.AddServiceBrokerParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	m := params.Body
+	badRequest := func(message string) middleware.Responder {
+		return servicebroker.NewAddServiceBrokerBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(message),
+			})
+	}
+	if m.Token != "" && (m.Username != "" || m.Password != "") {
+		return badRequest("a broker authenticates either with a token or with a username and a password")
+	}
+	if (m.Username == "") != (m.Password == "") {
+		return badRequest("a broker authenticating with a username requires a password, and conversely")
+	}
+
+	e := entities.BrokerModelToEntity(m)
+	exists, err := h.Store.Find(ctx, e.OrganizationID, e.Name, entitystore.Options{}, &entities.Broker{})
+	if err != nil {
+		log.Errorf("store error when fetching broker %s: %+v", e.Name, err)
+		return servicebroker.NewAddServiceBrokerDefault(http.StatusInternalServerError).WithPayload(
+			&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String(fmt.Sprintf("Error fetching broker %s", e.Name)),
+			})
+	}
+	conflict := servicebroker.NewAddServiceBrokerConflict().WithPayload(&v1.Error{
+		Code:    http.StatusConflict,
+		Message: swag.String("error creating broker: non-unique name"),
+	})
+	if exists {
+		return conflict
+	}
+
+	// the credentials are kept in the secret store, never in the broker entity
+	credentials := v1.SecretValue{}
+	if m.Token != "" {
+		credentials["token"] = m.Token
+	}
+	if m.Username != "" {
+		credentials["username"] = m.Username
+		credentials["password"] = m.Password
+	}
+	if len(credentials) > 0 {
+		secret := &v1.Secret{Name: swag.String(brokerSecretName(e.Name)), Secrets: credentials}
+		if _, err := h.SecretsClient.CreateSecret(ctx, e.OrganizationID, secret); err != nil {
+			log.Errorf("error storing the credentials of broker %s: %+v", e.Name, err)
+			return servicebroker.NewAddServiceBrokerDefault(http.StatusInternalServerError).WithPayload(
+				&v1.Error{
+					Code:    http.StatusInternalServerError,
+					Message: swag.String(fmt.Sprintf("Error storing the credentials of broker %s", e.Name)),
+				})
+		}
+		e.AuthSecret = *secret.Name
+	}
+
+	e.Status = entitystore.StatusCREATING
+	if _, err := h.Store.Add(ctx, e); err != nil {
+		if e.AuthSecret != "" {
+			if err := h.SecretsClient.DeleteSecret(ctx, e.OrganizationID, e.AuthSecret); err != nil {
+				log.Warnf("error deleting the credentials of broker %s: %+v", e.Name, err)
+			}
+		}
+		if entitystore.IsUniqueViolation(err) {
+			return conflict
+		}
+		log.Debugf("store error when adding broker: %+v", err)
+		return badRequest("store error when adding broker")
+	}
+	h.Watcher.OnAction(ctx, e)
+
+	return servicebroker.NewAddServiceBrokerCreated().WithPayload(entities.BrokerEntityToModel(e))
+}
+
+func (h *Handlers) getServiceBrokerByName(params servicebroker.GetServiceBrokerByNameParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	e := entities.Broker{}
+	err := h.Store.Get(ctx, flags.ServiceManagerFlags.OrgID, params.ServiceBrokerName, entitystore.Options{}, &e)
+	if err != nil {
+		log.Warnf("Received GET for non-existent broker %s", params.ServiceBrokerName)
+		log.Debugf("store error when getting broker: %+v", err)
+		return servicebroker.NewGetServiceBrokerByNameNotFound().WithPayload(
+			&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String(fmt.Sprintf("broker %s not found", params.ServiceBrokerName)),
+			})
+	}
+	return servicebroker.NewGetServiceBrokerByNameOK().WithPayload(entities.BrokerEntityToModel(&e))
+}
+
+func (h *Handlers) getServiceBrokers(params servicebroker.GetServiceBrokersParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	var brokers []*entities.Broker
+
+	var err error
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	opts.Filter, err = utils.ParseTags(opts.Filter, params.Tags)
+	if err == nil {
+		err = utils.ParsePaging(&opts, params.Limit, params.Continue, params.Sort)
+	}
+	if err != nil {
+		return servicebroker.NewGetServiceBrokersDefault(http.StatusBadRequest).WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
+	err = h.Store.List(ctx, flags.ServiceManagerFlags.OrgID, opts, &brokers)
+	if err != nil {
+		log.Errorf("store error when listing brokers: %+v", err)
+		return servicebroker.NewGetServiceBrokersDefault(http.StatusInternalServerError).WithPayload(
+			&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String("internal server error when getting brokers"),
+			})
+	}
+	var brokerModels []*v1.ServiceBroker
+	for _, broker := range brokers {
+		brokerModels = append(brokerModels, entities.BrokerEntityToModel(broker))
+	}
+	return servicebroker.NewGetServiceBrokersOK().WithPayload(brokerModels).WithXDispatchContinue(utils.NextPage(opts))
+}
+
+// brokerInstances returns the names of the service instances of the service classes of a broker
+func (h *Handlers) brokerInstances(ctx context.Context, brokerName string) ([]string, error) {
+	var classes []*entities.ServiceClass
+	if err := h.Store.List(ctx, flags.ServiceManagerFlags.OrgID, entitystore.Options{}, &classes); err != nil {
+		return nil, err
+	}
+	brokerClasses := make(map[string]bool)
+	for _, class := range classes {
+		if class.Broker == brokerName {
+			brokerClasses[class.Name] = true
+		}
+	}
+	instances, err := listServiceInstances(ctx, h.Store)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, instance := range instances {
+		if !instance.Delete && brokerClasses[instance.ServiceClass] {
+			names = append(names, instance.Name)
+		}
+	}
+	return names, nil
+}
+
+func (h *Handlers) deleteServiceBrokerByName(params servicebroker.DeleteServiceBrokerByNameParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	e := entities.Broker{}
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	err := h.Store.Get(ctx, flags.ServiceManagerFlags.OrgID, params.ServiceBrokerName, opts, &e)
+	if err != nil {
+		return servicebroker.NewDeleteServiceBrokerByNameNotFound().WithPayload(
+			&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String(fmt.Sprintf("broker %s not found", params.ServiceBrokerName)),
+			})
+	}
+	// the service classes of a broker go away with it, which would orphan their service instances
+	instances, err := h.brokerInstances(ctx, e.Name)
+	if err != nil {
+		log.Errorf("store error when listing the service instances of broker %s: %+v", e.Name, err)
+		return servicebroker.NewDeleteServiceBrokerByNameDefault(http.StatusInternalServerError).WithPayload(
+			&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String(fmt.Sprintf("Error listing the service instances of broker %s", e.Name)),
+			})
+	}
+	if len(instances) > 0 {
+		return servicebroker.NewDeleteServiceBrokerByNameBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(fmt.Sprintf("broker %s is in use by service instances [%s]", e.Name, strings.Join(instances, ", "))),
+			})
+	}
+	if err := h.Store.SoftDelete(ctx, &e); err != nil {
+		log.Debugf("store error when deleting broker: %+v", err)
+		return servicebroker.NewDeleteServiceBrokerByNameNotFound().WithPayload(
+			&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String("broker not found while deleting"),
+			})
+	}
+	if e.AuthSecret != "" {
+		if err := h.SecretsClient.DeleteSecret(ctx, e.OrganizationID, e.AuthSecret); err != nil {
+			log.Warnf("error deleting the credentials of broker %s: %+v", e.Name, err)
+		}
+	}
+	h.Watcher.OnAction(ctx, &e)
+
+	return servicebroker.NewDeleteServiceBrokerByNameOK().WithPayload(entities.BrokerEntityToModel(&e))
+}
+
+func (h *Handlers) refreshServiceBroker(params servicebroker.RefreshServiceBrokerParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	e := entities.Broker{}
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	err := h.Store.Get(ctx, flags.ServiceManagerFlags.OrgID, params.ServiceBrokerName, opts, &e)
+	if err != nil {
+		return servicebroker.NewRefreshServiceBrokerNotFound().WithPayload(
+			&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String(fmt.Sprintf("broker %s not found", params.ServiceBrokerName)),
+			})
+	}
+	e.Status = entitystore.StatusUPDATING
+	if _, err := h.Store.Update(ctx, e.Revision, &e); err != nil {
+		log.Debugf("store error when refreshing broker: %+v", err)
+		return servicebroker.NewRefreshServiceBrokerBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String("store error when refreshing broker"),
+			})
+	}
+	h.Watcher.OnAction(ctx, &e)
+
+	return servicebroker.NewRefreshServiceBrokerOK().WithPayload(entities.BrokerEntityToModel(&e))
 }
 
 func (h *Handlers) getServiceClassByName(params serviceclass.GetServiceClassByNameParams, principal interface{}) middleware.Responder {
@@ -93,6 +358,17 @@ func (h *Handlers) getServiceClasses(params serviceclass.GetServiceClassesParams
 	opts := entitystore.Options{
 		Filter: entitystore.FilterExists(),
 	}
+	opts.Filter, err = utils.ParseTags(opts.Filter, params.Tags)
+	if err == nil {
+		err = utils.ParsePaging(&opts, params.Limit, params.Continue, params.Sort)
+	}
+	if err != nil {
+		return serviceclass.NewGetServiceClassesDefault(http.StatusBadRequest).WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
 	err = h.Store.List(ctx, flags.ServiceManagerFlags.OrgID, opts, &classes)
 	if err != nil {
 		log.Errorf("store error when listing service classes: %+v", err)
@@ -106,7 +382,7 @@ func (h *Handlers) getServiceClasses(params serviceclass.GetServiceClassesParams
 	for _, class := range classes {
 		classModels = append(classModels, entities.ServiceClassEntityToModel(class))
 	}
-	return serviceclass.NewGetServiceClassesOK().WithPayload(classModels)
+	return serviceclass.NewGetServiceClassesOK().WithPayload(classModels).WithXDispatchContinue(utils.NextPage(opts))
 }
 
 func (h *Handlers) addServiceInstance(params serviceinstance.AddServiceInstanceParams, principal interface{}) middleware.Responder {
@@ -114,11 +390,15 @@ func (h *Handlers) addServiceInstance(params serviceinstance.AddServiceInstanceP
 	defer span.Finish()
 
 	serviceRequest := params.Body
-	e, b := entities.ServiceInstanceModelToEntity(serviceRequest)
+	e, b := entities.ServiceInstanceModelToEntity(params.XDispatchOrg, serviceRequest)
 	e.Status = entitystore.StatusINITIALIZED
+	// the brokers are told who requested the service
+	e.Requester = strings.Join(client.ParseRequesters(params.HTTPRequest.Header.Get(client.HeaderRequester)), ",")
+	b.Requester = e.Requester
 
+	// Service classes are shared by all organizations
 	var sc entities.ServiceClass
-	exists, err := h.Store.Find(ctx, e.OrganizationID, e.ServiceClass, entitystore.Options{}, &sc)
+	exists, err := h.Store.Find(ctx, flags.ServiceManagerFlags.OrgID, e.ServiceClass, entitystore.Options{}, &sc)
 	if !exists {
 		log.Debugf("service class %s does not exist", e.ServiceClass)
 		return serviceinstance.NewAddServiceInstanceBadRequest().WithPayload(
@@ -138,7 +418,26 @@ func (h *Handlers) addServiceInstance(params serviceinstance.AddServiceInstanceP
 		)
 	}
 	// TODO (bjung): actually validate the binding/update/add schema against the parameters
-	_, err = h.Store.Add(ctx, e)
+	// the instance and its binding are added together
+	bound := false
+	err = h.Store.Tx(ctx, func(tx entitystore.EntityStore) error {
+		if _, err := tx.Add(ctx, e); err != nil {
+			return err
+		}
+		// Get Plan and determine if bindable.  Plan "Bindable" is optional and trumps class setting.
+		for _, p := range sc.Plans {
+			if p.Name == e.ServicePlan && p.Bindable {
+				e.Bind = true
+				b.Status = entitystore.StatusINITIALIZED
+				b.ServiceInstance = e.Name
+				if _, err := tx.Add(ctx, b); err != nil {
+					return err
+				}
+				bound = true
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		if entitystore.IsUniqueViolation(err) {
 			return serviceinstance.NewAddServiceInstanceConflict().WithPayload(&v1.Error{
@@ -154,29 +453,8 @@ func (h *Handlers) addServiceInstance(params serviceinstance.AddServiceInstanceP
 			})
 	}
 	h.Watcher.OnAction(ctx, e)
-	// Get Plan and determine if bindable.  Plan "Bindable" is optional and trumps class setting.
-	for _, p := range sc.Plans {
-		if p.Name == e.ServicePlan && p.Bindable {
-			e.Bind = true
-			b.Status = entitystore.StatusINITIALIZED
-			b.ServiceInstance = e.Name
-			_, err = h.Store.Add(ctx, b)
-			if err != nil {
-				if entitystore.IsUniqueViolation(err) {
-					return serviceinstance.NewAddServiceInstanceConflict().WithPayload(&v1.Error{
-						Code:    http.StatusConflict,
-						Message: swag.String("error creating service instance: non-unique name"),
-					})
-				}
-				log.Debugf("store error when adding service instance: %+v", err)
-				return serviceinstance.NewAddServiceInstanceBadRequest().WithPayload(
-					&v1.Error{
-						Code:    http.StatusBadRequest,
-						Message: swag.String("store error when adding service instance"),
-					})
-			}
-			h.Watcher.OnAction(ctx, b)
-		}
+	if bound {
+		h.Watcher.OnAction(ctx, b)
 	}
 
 	m := entities.ServiceInstanceEntityToModel(e, b)
@@ -193,7 +471,7 @@ func (h *Handlers) getServiceInstanceByName(params serviceinstance.GetServiceIns
 	opts := entitystore.Options{
 		Filter: entitystore.FilterExists(),
 	}
-	err = h.Store.Get(ctx, flags.ServiceManagerFlags.OrgID, params.ServiceInstanceName, opts, &si)
+	err = h.Store.Get(ctx, params.XDispatchOrg, params.ServiceInstanceName, opts, &si)
 	if err != nil {
 		log.Warnf("Received GET for non-existent service instance %s", params.ServiceInstanceName)
 		log.Debugf("store error when getting service instance: %+v", err)
@@ -206,7 +484,7 @@ func (h *Handlers) getServiceInstanceByName(params serviceinstance.GetServiceIns
 	b := entities.ServiceBinding{}
 	// There is currently a 1:1 relationship between instance and binding.  We are using the service name as the
 	// key for bindings (hence they have the same name)
-	err = h.Store.Get(ctx, flags.ServiceManagerFlags.OrgID, params.ServiceInstanceName, opts, &b)
+	err = h.Store.Get(ctx, params.XDispatchOrg, params.ServiceInstanceName, opts, &b)
 	if err != nil {
 		log.Warnf("Received GET for non-existent service binding %s", params.ServiceInstanceName)
 		log.Debugf("store error when getting service binding: %+v", err)
@@ -231,6 +509,9 @@ func (h *Handlers) getServiceInstances(params serviceinstance.GetServiceInstance
 		Filter: entitystore.FilterExists(),
 	}
 	opts.Filter, err = utils.ParseTags(opts.Filter, params.Tags)
+	if err == nil {
+		err = utils.ParsePaging(&opts, params.Limit, params.Continue, params.Sort)
+	}
 	if err != nil {
 		log.Errorf(err.Error())
 		return serviceinstance.NewGetServiceInstancesBadRequest().WithPayload(
@@ -240,7 +521,7 @@ func (h *Handlers) getServiceInstances(params serviceinstance.GetServiceInstance
 			})
 	}
 
-	err = h.Store.List(ctx, flags.ServiceManagerFlags.OrgID, opts, &services)
+	err = h.Store.List(ctx, params.XDispatchOrg, opts, &services)
 	if err != nil {
 		log.Errorf("store error when listing service instances: %+v", err)
 		return serviceinstance.NewGetServiceInstancesDefault(http.StatusInternalServerError).WithPayload(
@@ -249,8 +530,9 @@ func (h *Handlers) getServiceInstances(params serviceinstance.GetServiceInstance
 				Message: swag.String("internal server error while listing service instances"),
 			})
 	}
+	// the bindings are not paged, the page of instances is matched against all of them
 	var bindings []*entities.ServiceBinding
-	err = h.Store.List(ctx, flags.ServiceManagerFlags.OrgID, opts, &bindings)
+	err = h.Store.List(ctx, params.XDispatchOrg, entitystore.Options{Filter: opts.Filter}, &bindings)
 	if err != nil {
 		log.Errorf("store error when listing service bindings: %+v", err)
 		return serviceinstance.NewGetServiceInstancesDefault(http.StatusInternalServerError).WithPayload(
@@ -259,66 +541,381 @@ func (h *Handlers) getServiceInstances(params serviceinstance.GetServiceInstance
 				Message: swag.String("internal server error while listing service bindings"),
 			})
 	}
+	// the default binding of an instance is named after it
 	bindingsMap := make(map[string]*entities.ServiceBinding)
 	for _, binding := range bindings {
-		bindingsMap[binding.Name] = binding
+		if binding.Name == binding.ServiceInstance {
+			bindingsMap[binding.Name] = binding
+		}
 	}
 	var serviceModels []*v1.ServiceInstance
 	for _, service := range services {
 		binding := bindingsMap[service.Name]
 		serviceModels = append(serviceModels, entities.ServiceInstanceEntityToModel(service, binding))
 	}
-	return serviceinstance.NewGetServiceInstancesOK().WithPayload(serviceModels)
+	return serviceinstance.NewGetServiceInstancesOK().WithPayload(serviceModels).WithXDispatchContinue(utils.NextPage(opts))
 }
 
 func (h *Handlers) deleteServiceInstanceByName(params serviceinstance.DeleteServiceInstanceByNameParams, principal interface{}) middleware.Responder {
 	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
 	defer span.Finish()
 
-	b := entities.ServiceBinding{}
 	i := entities.ServiceInstance{}
 
 	var err error
 	opts := entitystore.Options{
 		Filter: entitystore.FilterExists(),
 	}
-	// TODO (bjung): We always assume there is an assocated binding... this may not always be true
-	err = h.Store.Get(ctx, flags.ServiceManagerFlags.OrgID, params.ServiceInstanceName, opts, &b)
+	err = h.Store.Get(ctx, params.XDispatchOrg, params.ServiceInstanceName, opts, &i)
 	if err != nil {
 		return serviceinstance.NewDeleteServiceInstanceByNameNotFound().WithPayload(
 			&v1.Error{
 				Code:    http.StatusNotFound,
-				Message: swag.String("binding for service instance not found"),
+				Message: swag.String("service instance not found"),
 			})
 	}
-	err = h.Store.Get(ctx, flags.ServiceManagerFlags.OrgID, params.ServiceInstanceName, opts, &i)
+	bindings, err := h.instanceBindings(ctx, params.XDispatchOrg, i.Name)
 	if err != nil {
+		log.Errorf("store error when listing the bindings of service instance %s: %+v", i.Name, err)
+		return serviceinstance.NewDeleteServiceInstanceByNameDefault(http.StatusInternalServerError).WithPayload(
+			&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String("store error when listing the bindings of service instance"),
+			})
+	}
+	// the instance and all its bindings are deleted together
+	message := "service instance not found while deleting"
+	err = h.Store.Tx(ctx, func(tx entitystore.EntityStore) error {
+		for _, b := range bindings {
+			if err := tx.SoftDelete(ctx, b); err != nil {
+				message = fmt.Sprintf("binding %s for service instance not found while deleting", b.Name)
+				return err
+			}
+		}
+		return tx.SoftDelete(ctx, &i)
+	})
+	if err != nil {
+		log.Debugf("store error when deleting service instance: %+v", err)
 		return serviceinstance.NewDeleteServiceInstanceByNameNotFound().WithPayload(
 			&v1.Error{
 				Code:    http.StatusNotFound,
-				Message: swag.String("service instance not found"),
+				Message: swag.String(message),
+			})
+	}
+
+	for _, b := range bindings {
+		h.Watcher.OnAction(ctx, b)
+	}
+	h.Watcher.OnAction(ctx, &i)
+
+	m := entities.ServiceInstanceEntityToModel(&i, nil)
+	return serviceinstance.NewDeleteServiceInstanceByNameOK().WithPayload(m)
+}
+
+func (h *Handlers) updateServiceInstanceByName(params serviceinstance.UpdateServiceInstanceByNameParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	badRequest := func(message string) middleware.Responder {
+		return serviceinstance.NewUpdateServiceInstanceByNameBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(message),
+			})
+	}
+
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	var si entities.ServiceInstance
+	if err := h.Store.Get(ctx, params.XDispatchOrg, params.ServiceInstanceName, opts, &si); err != nil {
+		log.Debugf("store error when getting service instance: %+v", err)
+		return serviceinstance.NewUpdateServiceInstanceByNameNotFound().WithPayload(
+			&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String(fmt.Sprintf("service instance %s not found", params.ServiceInstanceName)),
 			})
 	}
-	err = h.Store.SoftDelete(ctx, &b)
+	serviceRequest := params.Body
+	if *serviceRequest.ServiceClass != si.ServiceClass {
+		return badRequest(fmt.Sprintf("the service class of service instance %s cannot be changed", si.Name))
+	}
+	if si.Status != entitystore.StatusREADY && si.Status != entitystore.StatusERROR {
+		return badRequest(fmt.Sprintf("service instance %s cannot be updated while %s", si.Name, si.Status))
+	}
+
+	// Service classes are shared by all organizations
+	var sc entities.ServiceClass
+	if err := h.Store.Get(ctx, flags.ServiceManagerFlags.OrgID, si.ServiceClass, entitystore.Options{}, &sc); err != nil {
+		log.Debugf("store error when fetching service class: %+v", err)
+		return badRequest(fmt.Sprintf("Service class %s does not exist", si.ServiceClass))
+	}
+	plan := *serviceRequest.ServicePlan
+	var servicePlan *entities.ServicePlan
+	for i := range sc.Plans {
+		if sc.Plans[i].Name == plan {
+			servicePlan = &sc.Plans[i]
+		}
+	}
+	if servicePlan == nil {
+		return badRequest(fmt.Sprintf("service class %s has no plan %s", sc.Name, plan))
+	}
+	if plan != si.ServicePlan && !sc.PlanUpdatable {
+		return badRequest(fmt.Sprintf("the plan of the instances of service class %s cannot be changed", sc.Name))
+	}
+	parameters := si.Parameters
+	if serviceRequest.Parameters != nil {
+		parameters = serviceRequest.Parameters
+		if schema := servicePlan.Schema.Update; schema != nil {
+			if err := validate.AgainstSchema(schema, parameters, strfmt.Default); err != nil {
+				return badRequest(fmt.Sprintf("invalid parameters for service plan %s: %v", plan, err))
+			}
+		}
+	}
+
+	// the previous plan and parameters are restored if the broker fails to update the service
+	si.PreviousPlan = si.ServicePlan
+	si.PreviousParameters = si.Parameters
+	si.ServicePlan = plan
+	si.Parameters = parameters
+	si.UpdateInProgress = false
+	si.Status = entitystore.StatusUPDATING
+	si.Reason = nil
+	if _, err := h.Store.Update(ctx, si.Revision, &si); err != nil {
+		log.Debugf("store error when updating service instance: %+v", err)
+		return serviceinstance.NewUpdateServiceInstanceByNameDefault(http.StatusInternalServerError).WithPayload(
+			&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String("store error when updating service instance"),
+			})
+	}
+	h.Watcher.OnAction(ctx, &si)
+
+	m := entities.ServiceInstanceEntityToModel(&si, nil)
+	// the binding of the instance is named after it
+	var b entities.ServiceBinding
+	if found, _ := h.Store.Find(ctx, params.XDispatchOrg, si.Name, opts, &b); found {
+		m = entities.ServiceInstanceEntityToModel(&si, &b)
+	}
+	return serviceinstance.NewUpdateServiceInstanceByNameOK().WithPayload(m)
+}
+
+// instanceBindings returns the bindings of a service instance
+func (h *Handlers) instanceBindings(ctx context.Context, organizationID string, instanceName string) ([]*entities.ServiceBinding, error) {
+	filter := entitystore.FilterExists().Add(
+		entitystore.FilterStat{
+			Scope:   entitystore.FilterScopeExtra,
+			Subject: "ServiceInstance",
+			Verb:    entitystore.FilterVerbEqual,
+			Object:  instanceName,
+		})
+	var bindings []*entities.ServiceBinding
+	err := h.Store.List(ctx, organizationID, entitystore.Options{Filter: filter}, &bindings)
+	return bindings, err
+}
+
+func (h *Handlers) addServiceBinding(params servicebinding.AddServiceBindingParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	badRequest := func(message string) middleware.Responder {
+		return servicebinding.NewAddServiceBindingBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(message),
+			})
+	}
+
+	b := entities.ServiceBindingModelToEntity(params.XDispatchOrg, params.Body)
+	if b.Name == "" || b.ServiceInstance == "" {
+		return badRequest("a service binding needs a name and a service instance")
+	}
+	b.Status = entitystore.StatusINITIALIZED
+	// the brokers are told who requested the binding
+	b.Requester = strings.Join(client.ParseRequesters(params.HTTPRequest.Header.Get(client.HeaderRequester)), ",")
+
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	// the default binding of a bindable service instance is named after it
+	found, err := h.Store.Find(ctx, params.XDispatchOrg, b.Name, opts, &entities.ServiceInstance{})
 	if err != nil {
-		return serviceinstance.NewDeleteServiceInstanceByNameNotFound().WithPayload(
+		log.Debugf("store error when finding service instance: %+v", err)
+		return badRequest("store error when adding service binding")
+	}
+	if found {
+		return servicebinding.NewAddServiceBindingConflict().WithPayload(&v1.Error{
+			Code:    http.StatusConflict,
+			Message: swag.String(fmt.Sprintf("error creating service binding: %s is the name of a service instance", b.Name)),
+		})
+	}
+	var si entities.ServiceInstance
+	if err := h.Store.Get(ctx, params.XDispatchOrg, b.ServiceInstance, opts, &si); err != nil {
+		log.Debugf("store error when getting service instance: %+v", err)
+		return badRequest(fmt.Sprintf("Service instance %s does not exist", b.ServiceInstance))
+	}
+	// Service classes are shared by all organizations
+	var sc entities.ServiceClass
+	if err := h.Store.Get(ctx, flags.ServiceManagerFlags.OrgID, si.ServiceClass, entitystore.Options{}, &sc); err != nil {
+		log.Debugf("store error when fetching service class: %+v", err)
+		return badRequest(fmt.Sprintf("Service class %s does not exist", si.ServiceClass))
+	}
+	bindable := false
+	for _, p := range sc.Plans {
+		if p.Name == si.ServicePlan && p.Bindable {
+			bindable = true
+		}
+	}
+	if !bindable {
+		return badRequest(fmt.Sprintf("service instance %s with plan %s is not bindable", si.Name, si.ServicePlan))
+	}
+
+	if _, err := h.Store.Add(ctx, b); err != nil {
+		if entitystore.IsUniqueViolation(err) {
+			return servicebinding.NewAddServiceBindingConflict().WithPayload(&v1.Error{
+				Code:    http.StatusConflict,
+				Message: swag.String("error creating service binding: non-unique name"),
+			})
+		}
+		log.Debugf("store error when adding service binding: %+v", err)
+		return badRequest("store error when adding service binding")
+	}
+	h.Watcher.OnAction(ctx, b)
+
+	return servicebinding.NewAddServiceBindingCreated().WithPayload(entities.ServiceBindingEntityToModel(b))
+}
+
+func (h *Handlers) getServiceBindingByName(params servicebinding.GetServiceBindingByNameParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	b := entities.ServiceBinding{}
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	if err := h.Store.Get(ctx, params.XDispatchOrg, params.ServiceBindingName, opts, &b); err != nil {
+		log.Warnf("Received GET for non-existent service binding %s", params.ServiceBindingName)
+		log.Debugf("store error when getting service binding: %+v", err)
+		return servicebinding.NewGetServiceBindingByNameNotFound().WithPayload(
 			&v1.Error{
 				Code:    http.StatusNotFound,
-				Message: swag.String("binding for service instance not found while deleting"),
+				Message: swag.String(fmt.Sprintf("service binding %s not found", params.ServiceBindingName)),
 			})
 	}
-	err = h.Store.SoftDelete(ctx, &i)
+	return servicebinding.NewGetServiceBindingByNameOK().WithPayload(entities.ServiceBindingEntityToModel(&b))
+}
+
+func (h *Handlers) getServiceBindings(params servicebinding.GetServiceBindingsParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	var bindings []*entities.ServiceBinding
+
+	var err error
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	if params.Serviceinstance != nil {
+		opts.Filter.Add(
+			entitystore.FilterStat{
+				Scope:   entitystore.FilterScopeExtra,
+				Subject: "ServiceInstance",
+				Verb:    entitystore.FilterVerbEqual,
+				Object:  *params.Serviceinstance,
+			})
+	}
+	opts.Filter, err = utils.ParseTags(opts.Filter, params.Tags)
+	if err == nil {
+		err = utils.ParsePaging(&opts, params.Limit, params.Continue, params.Sort)
+	}
 	if err != nil {
-		return serviceinstance.NewDeleteServiceInstanceByNameNotFound().WithPayload(
+		return servicebinding.NewGetServiceBindingsBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
+
+	if err = h.Store.List(ctx, params.XDispatchOrg, opts, &bindings); err != nil {
+		log.Errorf("store error when listing service bindings: %+v", err)
+		return servicebinding.NewGetServiceBindingsDefault(http.StatusInternalServerError).WithPayload(
+			&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String("internal server error while listing service bindings"),
+			})
+	}
+	var bindingModels []*v1.ServiceBinding
+	for _, binding := range bindings {
+		bindingModels = append(bindingModels, entities.ServiceBindingEntityToModel(binding))
+	}
+	return servicebinding.NewGetServiceBindingsOK().WithPayload(bindingModels).WithXDispatchContinue(utils.NextPage(opts))
+}
+
+// deleteServiceBindingByName revokes a binding, the other bindings of its service instance are left untouched
+func (h *Handlers) deleteServiceBindingByName(params servicebinding.DeleteServiceBindingByNameParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	b := entities.ServiceBinding{}
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	if err := h.Store.Get(ctx, params.XDispatchOrg, params.ServiceBindingName, opts, &b); err != nil {
+		return servicebinding.NewDeleteServiceBindingByNameNotFound().WithPayload(
 			&v1.Error{
 				Code:    http.StatusNotFound,
-				Message: swag.String("service instance not found while deleting"),
+				Message: swag.String(fmt.Sprintf("service binding %s not found", params.ServiceBindingName)),
 			})
 	}
+	if err := h.Store.SoftDelete(ctx, &b); err != nil {
+		log.Debugf("store error when deleting service binding: %+v", err)
+		return servicebinding.NewDeleteServiceBindingByNameNotFound().WithPayload(
+			&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String("service binding not found while deleting"),
+			})
+	}
+	h.Watcher.OnAction(ctx, &b)
+
+	return servicebinding.NewDeleteServiceBindingByNameOK().WithPayload(entities.ServiceBindingEntityToModel(&b))
+}
+
+// rotateServiceBinding binds the service instance of a binding again, the new credentials replace those of the binding
+func (h *Handlers) rotateServiceBinding(params servicebinding.RotateServiceBindingParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
 
+	b := entities.ServiceBinding{}
+	opts := entitystore.Options{
+		Filter: entitystore.FilterExists(),
+	}
+	if err := h.Store.Get(ctx, params.XDispatchOrg, params.ServiceBindingName, opts, &b); err != nil {
+		return servicebinding.NewRotateServiceBindingNotFound().WithPayload(
+			&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: swag.String(fmt.Sprintf("service binding %s not found", params.ServiceBindingName)),
+			})
+	}
+	if b.Status != entitystore.StatusREADY && b.Status != entitystore.StatusERROR {
+		return servicebinding.NewRotateServiceBindingBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(fmt.Sprintf("service binding %s cannot be rotated while %s", b.Name, b.Status)),
+			})
+	}
+	b.Status = entitystore.StatusUPDATING
+	b.Reason = nil
+	// the requester of the rotation is the one told to the broker
+	b.Requester = strings.Join(client.ParseRequesters(params.HTTPRequest.Header.Get(client.HeaderRequester)), ",")
+	if _, err := h.Store.Update(ctx, b.Revision, &b); err != nil {
+		log.Debugf("store error when rotating service binding: %+v", err)
+		return servicebinding.NewRotateServiceBindingDefault(http.StatusInternalServerError).WithPayload(
+			&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String("store error when rotating service binding"),
+			})
+	}
 	h.Watcher.OnAction(ctx, &b)
-	h.Watcher.OnAction(ctx, &i)
 
-	m := entities.ServiceInstanceEntityToModel(&i, nil)
-	return serviceinstance.NewDeleteServiceInstanceByNameOK().WithPayload(m)
+	return servicebinding.NewRotateServiceBindingOK().WithPayload(entities.ServiceBindingEntityToModel(&b))
 }
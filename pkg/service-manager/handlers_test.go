@@ -11,15 +11,19 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
 	"github.com/go-openapi/spec"
 	"github.com/go-openapi/swag"
 
 	"github.com/vmware/dispatch/pkg/api/v1"
+	clientmocks "github.com/vmware/dispatch/pkg/client/mocks"
 	"github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/service-manager/entities"
 	"github.com/vmware/dispatch/pkg/service-manager/flags"
 	"github.com/vmware/dispatch/pkg/service-manager/gen/restapi/operations"
+	servicebinding "github.com/vmware/dispatch/pkg/service-manager/gen/restapi/operations/service_binding"
+	servicebroker "github.com/vmware/dispatch/pkg/service-manager/gen/restapi/operations/service_broker"
 	serviceclass "github.com/vmware/dispatch/pkg/service-manager/gen/restapi/operations/service_class"
 	serviceinstance "github.com/vmware/dispatch/pkg/service-manager/gen/restapi/operations/service_instance"
 	helpers "github.com/vmware/dispatch/pkg/testing/api"
@@ -163,8 +167,9 @@ func TestAddServiceInstance(t *testing.T) {
 	}
 	r := httptest.NewRequest("POST", "/v1/serviceinstance", nil)
 	post := serviceinstance.AddServiceInstanceParams{
-		HTTPRequest: r,
-		Body:        &addRequest,
+		HTTPRequest:  r,
+		Body:         &addRequest,
+		XDispatchOrg: "dispatch",
 	}
 	responder := api.ServiceInstanceAddServiceInstanceHandler.Handle(post, "testCookie")
 	var respBody v1.ServiceInstance
@@ -198,6 +203,7 @@ func TestGetServiceInstanceByName(t *testing.T) {
 	get := serviceinstance.GetServiceInstanceByNameParams{
 		HTTPRequest:         r,
 		ServiceInstanceName: "instanceA",
+		XDispatchOrg:        "dispatch",
 	}
 
 	var respBody v1.ServiceInstance
@@ -215,6 +221,7 @@ func TestGetServiceInstanceByName(t *testing.T) {
 	get = serviceinstance.GetServiceInstanceByNameParams{
 		HTTPRequest:         r,
 		ServiceInstanceName: "instanceB",
+		XDispatchOrg:        "dispatch",
 	}
 	responder = api.ServiceInstanceGetServiceInstanceByNameHandler.Handle(get, "testCookie")
 	helpers.HandlerRequest(t, responder, &respBody, 404)
@@ -231,7 +238,8 @@ func TestGetServiceInstances(t *testing.T) {
 
 	r := httptest.NewRequest("GET", "/v1/serviceinstance", nil)
 	get := serviceinstance.GetServiceInstancesParams{
-		HTTPRequest: r,
+		HTTPRequest:  r,
+		XDispatchOrg: "dispatch",
 	}
 
 	var respBody []*v1.ServiceInstance
@@ -263,6 +271,7 @@ func TestDeleteServiceInstanceByName(t *testing.T) {
 	del := serviceinstance.DeleteServiceInstanceByNameParams{
 		HTTPRequest:         r,
 		ServiceInstanceName: "instanceA",
+		XDispatchOrg:        "dispatch",
 	}
 
 	var respBody v1.ServiceInstance
@@ -279,7 +288,8 @@ func TestDeleteServiceInstanceByName(t *testing.T) {
 
 	r = httptest.NewRequest("GET", "/v1/serviceinstance", nil)
 	get := serviceinstance.GetServiceInstancesParams{
-		HTTPRequest: r,
+		HTTPRequest:  r,
+		XDispatchOrg: "dispatch",
 	}
 	var instances []*v1.ServiceInstance
 	responder = api.ServiceInstanceGetServiceInstancesHandler.Handle(get, "testCookie")
@@ -287,3 +297,516 @@ func TestDeleteServiceInstanceByName(t *testing.T) {
 
 	assert.Len(t, instances, 0)
 }
+
+func TestUpdateServiceInstanceByName(t *testing.T) {
+	flags.ServiceManagerFlags.OrgID = "dispatch"
+	handlers := &Handlers{
+		Store: helpers.MakeEntityStore(t),
+	}
+
+	api := operations.NewServiceManagerAPI(nil)
+	handlers.ConfigureHandlers(api)
+
+	updateRequest := v1.ServiceInstance{
+		Name:         swag.String("instanceA"),
+		ServiceClass: swag.String("classA"),
+		ServicePlan:  swag.String("planB"),
+		Parameters: map[string]interface{}{
+			"size": "large",
+		},
+	}
+	r := httptest.NewRequest("PUT", "/v1/serviceinstance/instanceA", nil)
+	put := serviceinstance.UpdateServiceInstanceByNameParams{
+		HTTPRequest:         r,
+		Body:                &updateRequest,
+		ServiceInstanceName: "instanceA",
+		XDispatchOrg:        "dispatch",
+	}
+	var respBody v1.ServiceInstance
+	responder := api.ServiceInstanceUpdateServiceInstanceByNameHandler.Handle(put, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 404)
+
+	serviceEntities := createServiceEntities(t, handlers)
+	// Service instance not provisioned yet
+	responder = api.ServiceInstanceUpdateServiceInstanceByNameHandler.Handle(put, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 400)
+
+	instance := serviceEntities["instanceA"].(*entities.ServiceInstance)
+	instance.ServicePlan = "planA"
+	instance.Status = entitystore.StatusREADY
+	_, err := handlers.Store.Update(context.Background(), instance.Revision, instance)
+	assert.NoError(t, err)
+	class := serviceEntities["classA"].(*entities.ServiceClass)
+	class.Plans = append(class.Plans, entities.ServicePlan{
+		BaseEntity: entitystore.BaseEntity{
+			Name: "planB",
+		},
+		Schema: entities.Schema{
+			Update: &spec.Schema{
+				SchemaProps: spec.SchemaProps{
+					Type: spec.StringOrArray{"object"},
+					Properties: map[string]spec.Schema{
+						"size": *spec.Int64Property(),
+					},
+				},
+			},
+		},
+	})
+	_, err = handlers.Store.Update(context.Background(), class.Revision, class)
+	assert.NoError(t, err)
+
+	// The plans of the service class cannot be changed
+	responder = api.ServiceInstanceUpdateServiceInstanceByNameHandler.Handle(put, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 400)
+
+	class.PlanUpdatable = true
+	_, err = handlers.Store.Update(context.Background(), class.Revision, class)
+	assert.NoError(t, err)
+
+	// The parameters do not match the update schema of the plan
+	responder = api.ServiceInstanceUpdateServiceInstanceByNameHandler.Handle(put, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 400)
+
+	updateRequest.Parameters = map[string]interface{}{
+		"size": 3,
+	}
+	responder = api.ServiceInstanceUpdateServiceInstanceByNameHandler.Handle(put, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 200)
+
+	assert.Equal(t, "planB", *respBody.ServicePlan)
+	assert.Equal(t, v1.StatusUPDATING, respBody.Status)
+	assert.NotNil(t, respBody.Binding)
+
+	var updated entities.ServiceInstance
+	err = handlers.Store.Get(context.Background(), "dispatch", "instanceA", entitystore.Options{}, &updated)
+	assert.NoError(t, err)
+	assert.Equal(t, "planB", updated.ServicePlan)
+	assert.Equal(t, "planA", updated.PreviousPlan)
+}
+
+func TestServiceInstancesOrganizationIsolation(t *testing.T) {
+	flags.ServiceManagerFlags.OrgID = "dispatch"
+	handlers := &Handlers{
+		Store: helpers.MakeEntityStore(t),
+	}
+
+	api := operations.NewServiceManagerAPI(nil)
+	handlers.ConfigureHandlers(api)
+
+	// instanceA is in the dispatch organization
+	_ = createServiceEntities(t, handlers)
+
+	get := serviceinstance.GetServiceInstanceByNameParams{
+		HTTPRequest:         httptest.NewRequest("GET", "/v1/serviceinstance/instanceA", nil),
+		ServiceInstanceName: "instanceA",
+		XDispatchOrg:        "org-b",
+	}
+	responder := api.ServiceInstanceGetServiceInstanceByNameHandler.Handle(get, "testCookie")
+	helpers.HandlerRequest(t, responder, &v1.Error{}, 404)
+
+	list := serviceinstance.GetServiceInstancesParams{
+		HTTPRequest:  httptest.NewRequest("GET", "/v1/serviceinstance", nil),
+		XDispatchOrg: "org-b",
+	}
+	var instances []*v1.ServiceInstance
+	responder = api.ServiceInstanceGetServiceInstancesHandler.Handle(list, "testCookie")
+	helpers.HandlerRequest(t, responder, &instances, 200)
+	assert.Len(t, instances, 0)
+
+	// Service classes are shared, org-b may use the same name for its own instance
+	post := serviceinstance.AddServiceInstanceParams{
+		HTTPRequest: httptest.NewRequest("POST", "/v1/serviceinstance", nil),
+		Body: &v1.ServiceInstance{
+			Name:         swag.String("instanceA"),
+			ServiceClass: swag.String("classA"),
+			ServicePlan:  swag.String("planA"),
+		},
+		XDispatchOrg: "org-b",
+	}
+	var created v1.ServiceInstance
+	responder = api.ServiceInstanceAddServiceInstanceHandler.Handle(post, "testCookie")
+	helpers.HandlerRequest(t, responder, &created, 201)
+
+	var instance entities.ServiceInstance
+	assert.NoError(t, handlers.Store.Get(context.Background(), "org-b", "instanceA", entitystore.Options{}, &instance))
+	assert.Equal(t, "org-b", instance.OrganizationID)
+}
+
+func TestAddServiceBroker(t *testing.T) {
+	flags.ServiceManagerFlags.OrgID = "dispatch"
+	secretsClient := &clientmocks.SecretsClient{}
+	handlers := &Handlers{
+		Store:         helpers.MakeEntityStore(t),
+		SecretsClient: secretsClient,
+	}
+
+	api := operations.NewServiceManagerAPI(nil)
+	handlers.ConfigureHandlers(api)
+
+	addRequest := v1.ServiceBroker{
+		Name:     swag.String("brokerA"),
+		URL:      swag.String("http://broker.example.com"),
+		Username: "user",
+		Token:    "token",
+	}
+	r := httptest.NewRequest("POST", "/v1/servicebroker", nil)
+	post := servicebroker.AddServiceBrokerParams{
+		HTTPRequest: r,
+		Body:        &addRequest,
+	}
+	var respBody v1.ServiceBroker
+	// A token and a username are exclusive
+	responder := api.ServiceBrokerAddServiceBrokerHandler.Handle(post, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 400)
+	// A username requires a password
+	addRequest.Token = ""
+	responder = api.ServiceBrokerAddServiceBrokerHandler.Handle(post, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 400)
+
+	addRequest.Password = "password"
+	secretsClient.On("CreateSecret", mock.Anything, "dispatch", mock.Anything).Return(&v1.Secret{}, nil)
+	responder = api.ServiceBrokerAddServiceBrokerHandler.Handle(post, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 201)
+
+	assert.Equal(t, addRequest.Name, respBody.Name)
+	assert.Equal(t, addRequest.URL, respBody.URL)
+	assert.Equal(t, v1.StatusCREATING, respBody.Status)
+	// The credentials are stored in the secret store, and never returned
+	assert.Empty(t, respBody.Username)
+	assert.Empty(t, respBody.Password)
+	secretsClient.AssertCalled(t, "CreateSecret", mock.Anything, "dispatch", &v1.Secret{
+		Name:    swag.String("broker-brokerA"),
+		Secrets: v1.SecretValue{"username": "user", "password": "password"},
+	})
+	broker := entities.Broker{}
+	err := handlers.Store.Get(context.Background(), "dispatch", "brokerA", entitystore.Options{}, &broker)
+	assert.NoError(t, err)
+	assert.Equal(t, "broker-brokerA", broker.AuthSecret)
+
+	responder = api.ServiceBrokerAddServiceBrokerHandler.Handle(post, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 409)
+}
+
+func TestGetServiceBrokers(t *testing.T) {
+	flags.ServiceManagerFlags.OrgID = "dispatch"
+	handlers := &Handlers{
+		Store: helpers.MakeEntityStore(t),
+	}
+
+	api := operations.NewServiceManagerAPI(nil)
+	handlers.ConfigureHandlers(api)
+
+	broker := entities.Broker{
+		BaseEntity: entitystore.BaseEntity{
+			Name:           "brokerA",
+			OrganizationID: "dispatch",
+			Status:         entitystore.StatusREADY,
+		},
+		URL:        "http://broker.example.com",
+		AuthSecret: "broker-brokerA",
+	}
+	_, err := handlers.Store.Add(context.Background(), &broker)
+	assert.NoError(t, err)
+
+	r := httptest.NewRequest("GET", "/v1/servicebroker", nil)
+	get := servicebroker.GetServiceBrokersParams{
+		HTTPRequest: r,
+	}
+	var brokers []*v1.ServiceBroker
+	responder := api.ServiceBrokerGetServiceBrokersHandler.Handle(get, "testCookie")
+	helpers.HandlerRequest(t, responder, &brokers, 200)
+
+	assert.Len(t, brokers, 1)
+	assert.Equal(t, "brokerA", *brokers[0].Name)
+	assert.Equal(t, "http://broker.example.com", *brokers[0].URL)
+	assert.Equal(t, v1.StatusREADY, brokers[0].Status)
+
+	r = httptest.NewRequest("GET", "/v1/servicebroker/brokerB", nil)
+	getByName := servicebroker.GetServiceBrokerByNameParams{
+		HTTPRequest:       r,
+		ServiceBrokerName: "brokerB",
+	}
+	var respBody v1.ServiceBroker
+	responder = api.ServiceBrokerGetServiceBrokerByNameHandler.Handle(getByName, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 404)
+}
+
+func TestDeleteServiceBrokerByName(t *testing.T) {
+	flags.ServiceManagerFlags.OrgID = "dispatch"
+	secretsClient := &clientmocks.SecretsClient{}
+	handlers := &Handlers{
+		Store:         helpers.MakeEntityStore(t),
+		SecretsClient: secretsClient,
+	}
+
+	api := operations.NewServiceManagerAPI(nil)
+	handlers.ConfigureHandlers(api)
+
+	r := httptest.NewRequest("DELETE", "/v1/servicebroker/brokerA", nil)
+	del := servicebroker.DeleteServiceBrokerByNameParams{
+		HTTPRequest:       r,
+		ServiceBrokerName: "brokerA",
+	}
+	var respBody v1.ServiceBroker
+	responder := api.ServiceBrokerDeleteServiceBrokerByNameHandler.Handle(del, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 404)
+
+	broker := entities.Broker{
+		BaseEntity: entitystore.BaseEntity{
+			Name:           "brokerA",
+			OrganizationID: "dispatch",
+			Status:         entitystore.StatusREADY,
+		},
+		URL:        "http://broker.example.com",
+		AuthSecret: "broker-brokerA",
+	}
+	_, err := handlers.Store.Add(context.Background(), &broker)
+	assert.NoError(t, err)
+	serviceEntities := createServiceEntities(t, handlers)
+
+	// instanceA of classA of brokerA is in use
+	responder = api.ServiceBrokerDeleteServiceBrokerByNameHandler.Handle(del, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 400)
+
+	instance := serviceEntities["instanceA"].(*entities.ServiceInstance)
+	assert.NoError(t, handlers.Store.SoftDelete(context.Background(), instance))
+
+	secretsClient.On("DeleteSecret", mock.Anything, "dispatch", "broker-brokerA").Return(nil)
+	responder = api.ServiceBrokerDeleteServiceBrokerByNameHandler.Handle(del, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 200)
+
+	assert.Equal(t, "brokerA", *respBody.Name)
+	assert.Equal(t, v1.StatusDELETING, respBody.Status)
+	secretsClient.AssertCalled(t, "DeleteSecret", mock.Anything, "dispatch", "broker-brokerA")
+}
+
+func TestRefreshServiceBroker(t *testing.T) {
+	flags.ServiceManagerFlags.OrgID = "dispatch"
+	handlers := &Handlers{
+		Store: helpers.MakeEntityStore(t),
+	}
+
+	api := operations.NewServiceManagerAPI(nil)
+	handlers.ConfigureHandlers(api)
+
+	r := httptest.NewRequest("POST", "/v1/servicebroker/brokerA/refresh", nil)
+	refresh := servicebroker.RefreshServiceBrokerParams{
+		HTTPRequest:       r,
+		ServiceBrokerName: "brokerA",
+	}
+	var respBody v1.ServiceBroker
+	responder := api.ServiceBrokerRefreshServiceBrokerHandler.Handle(refresh, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 404)
+
+	broker := entities.Broker{
+		BaseEntity: entitystore.BaseEntity{
+			Name:           "brokerA",
+			OrganizationID: "dispatch",
+			Status:         entitystore.StatusREADY,
+		},
+		URL: "http://broker.example.com",
+	}
+	_, err := handlers.Store.Add(context.Background(), &broker)
+	assert.NoError(t, err)
+
+	responder = api.ServiceBrokerRefreshServiceBrokerHandler.Handle(refresh, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 200)
+
+	assert.Equal(t, v1.StatusUPDATING, respBody.Status)
+}
+
+// makeBindable makes the plan of the test service instance bindable
+func makeBindable(t *testing.T, handlers *Handlers, serviceEntities map[string]interface{}) {
+	class := serviceEntities["classA"].(*entities.ServiceClass)
+	class.Plans[0].Bindable = true
+	_, err := handlers.Store.Update(context.Background(), class.Revision, class)
+	assert.NoError(t, err)
+	instance := serviceEntities["instanceA"].(*entities.ServiceInstance)
+	instance.ServicePlan = "planA"
+	_, err = handlers.Store.Update(context.Background(), instance.Revision, instance)
+	assert.NoError(t, err)
+}
+
+func TestAddServiceBinding(t *testing.T) {
+	flags.ServiceManagerFlags.OrgID = "dispatch"
+	handlers := &Handlers{
+		Store: helpers.MakeEntityStore(t),
+	}
+
+	api := operations.NewServiceManagerAPI(nil)
+	handlers.ConfigureHandlers(api)
+
+	bindingRequest := v1.ServiceBinding{
+		Name:            "readonly",
+		ServiceInstance: "instanceA",
+		Parameters: map[string]interface{}{
+			"role": "reader",
+		},
+	}
+	r := httptest.NewRequest("POST", "/v1/servicebinding", nil)
+	add := servicebinding.AddServiceBindingParams{
+		HTTPRequest:  r,
+		Body:         &bindingRequest,
+		XDispatchOrg: "dispatch",
+	}
+	var respBody v1.ServiceBinding
+	responder := api.ServiceBindingAddServiceBindingHandler.Handle(add, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 400)
+
+	serviceEntities := createServiceEntities(t, handlers)
+	// the plan of the instance is not bindable
+	responder = api.ServiceBindingAddServiceBindingHandler.Handle(add, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 400)
+
+	makeBindable(t, handlers, serviceEntities)
+	responder = api.ServiceBindingAddServiceBindingHandler.Handle(add, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 201)
+	assert.Equal(t, "readonly", respBody.Name)
+	assert.Equal(t, "instanceA", respBody.ServiceInstance)
+	assert.Equal(t, v1.StatusINITIALIZED, respBody.Status)
+	assert.NotEmpty(t, respBody.ID)
+
+	responder = api.ServiceBindingAddServiceBindingHandler.Handle(add, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 409)
+
+	// the default binding of an instance is named after it
+	bindingRequest.Name = "instanceA"
+	responder = api.ServiceBindingAddServiceBindingHandler.Handle(add, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 409)
+	bindingRequest.Name = "readonly"
+
+	r = httptest.NewRequest("GET", "/v1/servicebinding/readonly", nil)
+	get := servicebinding.GetServiceBindingByNameParams{
+		HTTPRequest:        r,
+		ServiceBindingName: "readonly",
+		XDispatchOrg:       "dispatch",
+	}
+	responder = api.ServiceBindingGetServiceBindingByNameHandler.Handle(get, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 200)
+	assert.Equal(t, map[string]interface{}{"role": "reader"}, respBody.Parameters)
+
+	// the default binding of the instance and the one added
+	r = httptest.NewRequest("GET", "/v1/servicebinding?serviceinstance=instanceA", nil)
+	list := servicebinding.GetServiceBindingsParams{
+		HTTPRequest:     r,
+		Serviceinstance: swag.String("instanceA"),
+		XDispatchOrg:    "dispatch",
+	}
+	var bindings []*v1.ServiceBinding
+	responder = api.ServiceBindingGetServiceBindingsHandler.Handle(list, "testCookie")
+	helpers.HandlerRequest(t, responder, &bindings, 200)
+	assert.Len(t, bindings, 2)
+
+	list.Serviceinstance = swag.String("instanceB")
+	responder = api.ServiceBindingGetServiceBindingsHandler.Handle(list, "testCookie")
+	helpers.HandlerRequest(t, responder, &bindings, 200)
+	assert.Len(t, bindings, 0)
+}
+
+func TestRotateServiceBinding(t *testing.T) {
+	flags.ServiceManagerFlags.OrgID = "dispatch"
+	handlers := &Handlers{
+		Store: helpers.MakeEntityStore(t),
+	}
+
+	api := operations.NewServiceManagerAPI(nil)
+	handlers.ConfigureHandlers(api)
+
+	r := httptest.NewRequest("POST", "/v1/servicebinding/instanceA/rotate", nil)
+	rotate := servicebinding.RotateServiceBindingParams{
+		HTTPRequest:        r,
+		ServiceBindingName: "instanceA",
+		XDispatchOrg:       "dispatch",
+	}
+	var respBody v1.ServiceBinding
+	responder := api.ServiceBindingRotateServiceBindingHandler.Handle(rotate, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 404)
+
+	createServiceEntities(t, handlers)
+	// Service binding not bound yet
+	responder = api.ServiceBindingRotateServiceBindingHandler.Handle(rotate, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 400)
+
+	var binding entities.ServiceBinding
+	assert.NoError(t, handlers.Store.Get(context.Background(), "dispatch", "instanceA", entitystore.Options{}, &binding))
+	binding.Status = entitystore.StatusREADY
+	_, err := handlers.Store.Update(context.Background(), binding.Revision, &binding)
+	assert.NoError(t, err)
+
+	responder = api.ServiceBindingRotateServiceBindingHandler.Handle(rotate, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 200)
+	assert.Equal(t, v1.StatusUPDATING, respBody.Status)
+}
+
+func TestDeleteServiceBindingByName(t *testing.T) {
+	flags.ServiceManagerFlags.OrgID = "dispatch"
+	handlers := &Handlers{
+		Store: helpers.MakeEntityStore(t),
+	}
+
+	api := operations.NewServiceManagerAPI(nil)
+	handlers.ConfigureHandlers(api)
+
+	r := httptest.NewRequest("DELETE", "/v1/servicebinding/readonly", nil)
+	del := servicebinding.DeleteServiceBindingByNameParams{
+		HTTPRequest:        r,
+		ServiceBindingName: "readonly",
+		XDispatchOrg:       "dispatch",
+	}
+	var respBody v1.ServiceBinding
+	responder := api.ServiceBindingDeleteServiceBindingByNameHandler.Handle(del, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 404)
+
+	createServiceEntities(t, handlers)
+	readonly := entities.ServiceBinding{
+		BaseEntity: entitystore.BaseEntity{
+			Name:           "readonly",
+			OrganizationID: "dispatch",
+			Status:         entitystore.StatusREADY,
+		},
+		ServiceInstance: "instanceA",
+	}
+	_, err := handlers.Store.Add(context.Background(), &readonly)
+	assert.NoError(t, err)
+
+	// revoking a binding leaves the other bindings of the instance
+	responder = api.ServiceBindingDeleteServiceBindingByNameHandler.Handle(del, "testCookie")
+	helpers.HandlerRequest(t, responder, &respBody, 200)
+	assert.Equal(t, "readonly", respBody.Name)
+
+	r = httptest.NewRequest("GET", "/v1/servicebinding", nil)
+	list := servicebinding.GetServiceBindingsParams{
+		HTTPRequest:  r,
+		XDispatchOrg: "dispatch",
+	}
+	var bindings []*v1.ServiceBinding
+	responder = api.ServiceBindingGetServiceBindingsHandler.Handle(list, "testCookie")
+	helpers.HandlerRequest(t, responder, &bindings, 200)
+	assert.Len(t, bindings, 1)
+	assert.Equal(t, "instanceA", bindings[0].Name)
+
+	// the bindings left are deleted with the instance
+	readonly = entities.ServiceBinding{
+		BaseEntity: entitystore.BaseEntity{
+			Name:           "readwrite",
+			OrganizationID: "dispatch",
+			Status:         entitystore.StatusREADY,
+		},
+		ServiceInstance: "instanceA",
+	}
+	_, err = handlers.Store.Add(context.Background(), &readonly)
+	assert.NoError(t, err)
+	r = httptest.NewRequest("DELETE", "/v1/serviceinstance/instanceA", nil)
+	delInstance := serviceinstance.DeleteServiceInstanceByNameParams{
+		HTTPRequest:         r,
+		ServiceInstanceName: "instanceA",
+		XDispatchOrg:        "dispatch",
+	}
+	var instance v1.ServiceInstance
+	responder = api.ServiceInstanceDeleteServiceInstanceByNameHandler.Handle(delInstance, "testCookie")
+	helpers.HandlerRequest(t, responder, &instance, 200)
+
+	responder = api.ServiceBindingGetServiceBindingsHandler.Handle(list, "testCookie")
+	helpers.HandlerRequest(t, responder, &bindings, 200)
+	assert.Len(t, bindings, 0)
+}
@@ -134,3 +134,31 @@ func (_m *BrokerClient) ListServiceInstances() ([]entitystore.Entity, error) {
 
 	return r0, r1
 }
+
+// RefreshBinding provides a mock function with given fields: _a0, _a1
+func (_m *BrokerClient) RefreshBinding(_a0 *entities.ServiceInstance, _a1 *entities.ServiceBinding) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*entities.ServiceInstance, *entities.ServiceBinding) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateService provides a mock function with given fields: _a0, _a1
+func (_m *BrokerClient) UpdateService(_a0 *entities.ServiceClass, _a1 *entities.ServiceInstance) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*entities.ServiceClass, *entities.ServiceInstance) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
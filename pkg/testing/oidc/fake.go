@@ -0,0 +1,217 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+
+	"github.com/vmware/dispatch/pkg/identity-manager/oidc"
+)
+
+// NO TESTS
+
+type authorization struct {
+	clientID    string
+	redirectURI string
+	challenge   string
+	nonce       string
+}
+
+// FakeProvider is a local OpenID Connect provider, logging in a single user without prompting. It supports discovery,
+// the authorization code flow with PKCE, and serves its signing keys.
+type FakeProvider struct {
+	sync.Mutex
+	*httptest.Server
+
+	// ClientID is the only client of the provider
+	ClientID string
+	// Email and Groups are the claims of the logged in user
+	Email  string
+	Groups []string
+
+	key   *rsa.PrivateKey
+	kid   int
+	codes map[string]authorization
+}
+
+// NewFakeProvider starts a fake provider, to be closed after use
+func NewFakeProvider(clientID, email string, groups ...string) *FakeProvider {
+	f := &FakeProvider{
+		ClientID: clientID,
+		Email:    email,
+		Groups:   groups,
+		codes:    make(map[string]authorization),
+	}
+	f.RotateKey()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", f.discovery)
+	mux.HandleFunc("/keys", f.keys)
+	mux.HandleFunc("/authorize", f.authorize)
+	mux.HandleFunc("/token", f.token)
+	f.Server = httptest.NewServer(mux)
+	return f
+}
+
+// Issuer returns the issuer of the provider
+func (f *FakeProvider) Issuer() string {
+	return f.URL
+}
+
+// RotateKey replaces the signing key of the provider
+func (f *FakeProvider) RotateKey() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	f.Lock()
+	defer f.Unlock()
+	f.key = key
+	f.kid++
+}
+
+// IDToken returns an ID token of the logged in user, signed by the provider. Claims override the default ones.
+func (f *FakeProvider) IDToken(claims jwt.MapClaims) string {
+	f.Lock()
+	defer f.Unlock()
+	return f.idToken(claims)
+}
+
+func (f *FakeProvider) idToken(claims jwt.MapClaims) string {
+	all := jwt.MapClaims{
+		"iss":            f.URL,
+		"sub":            f.Email,
+		"aud":            f.ClientID,
+		"email":          f.Email,
+		"email_verified": true,
+		"groups":         f.Groups,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range claims {
+		all[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, all)
+	token.Header["kid"] = fmt.Sprint(f.kid)
+	signed, err := token.SignedString(f.key)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+func (f *FakeProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 f.URL,
+		"authorization_endpoint": f.URL + "/authorize",
+		"token_endpoint":         f.URL + "/token",
+		"jwks_uri":               f.URL + "/keys",
+	})
+}
+
+func (f *FakeProvider) keys(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+	key, err := oidc.NewJSONWebKey(fmt.Sprint(f.kid), &f.key.PublicKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(oidc.JSONWebKeySet{Keys: []oidc.JSONWebKey{key}})
+}
+
+func (f *FakeProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("client_id") != f.ClientID || q.Get("redirect_uri") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "PKCE required", http.StatusBadRequest)
+		return
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	code := base64.RawURLEncoding.EncodeToString(b)
+
+	f.Lock()
+	f.codes[code] = authorization{
+		clientID:    q.Get("client_id"),
+		redirectURI: q.Get("redirect_uri"),
+		challenge:   q.Get("code_challenge"),
+		nonce:       q.Get("nonce"),
+	}
+	f.Unlock()
+
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	values := redirect.Query()
+	values.Set("code", code)
+	values.Set("state", q.Get("state"))
+	redirect.RawQuery = values.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (f *FakeProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "invalid_request")
+		return
+	}
+	f.Lock()
+	defer f.Unlock()
+	code := r.PostForm.Get("code")
+	auth, ok := f.codes[code]
+	// codes are single use
+	delete(f.codes, code)
+	if !ok || auth.redirectURI != r.PostForm.Get("redirect_uri") {
+		tokenError(w, "invalid_grant")
+		return
+	}
+	clientID := r.PostForm.Get("client_id")
+	if user, _, ok := r.BasicAuth(); ok {
+		clientID = user
+	}
+	if clientID != auth.clientID {
+		tokenError(w, "invalid_client")
+		return
+	}
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.challenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+	claims := jwt.MapClaims{}
+	if auth.nonce != "" {
+		claims["nonce"] = auth.nonce
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "access",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     f.idToken(claims),
+	})
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
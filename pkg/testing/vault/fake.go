@@ -0,0 +1,268 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package vault
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NO TESTS
+
+type kvVersion struct {
+	data        map[string]string
+	createdTime time.Time
+}
+
+// FakeVault is an in-memory stand-in for the parts of the vault HTTP API used by dispatch:
+// the KV v2 secrets engine mounted at "secret", the transit secrets engine mounted at "transit"
+// and AppRole login mounted at "approle"
+type FakeVault struct {
+	sync.Mutex
+
+	// Token is the root token accepted by the fake
+	Token string
+	// RoleID and SecretID are the AppRole credentials accepted by the fake
+	RoleID   string
+	SecretID string
+	// LeaseDuration of tokens issued through AppRole, in seconds
+	LeaseDuration int
+
+	tokens  map[string]bool
+	secrets map[string][]kvVersion
+	issued  int
+	// transit key versions by key name
+	transitKeys map[string]int
+}
+
+// NewFakeVault creates a new fake vault accepting the given root token
+func NewFakeVault(token string) *FakeVault {
+	return &FakeVault{
+		Token:       token,
+		tokens:      map[string]bool{token: true},
+		secrets:     make(map[string][]kvVersion),
+		transitKeys: make(map[string]int),
+	}
+}
+
+// RevokeTokens invalidates all tokens issued through AppRole
+func (f *FakeVault) RevokeTokens() {
+	f.Lock()
+	defer f.Unlock()
+	f.tokens = map[string]bool{f.Token: true}
+}
+
+// Logins returns the number of successful AppRole logins
+func (f *FakeVault) Logins() int {
+	f.Lock()
+	defer f.Unlock()
+	return f.issued
+}
+
+// RotateTransitKey creates a new version of a transit key, used to encrypt from now on
+func (f *FakeVault) RotateTransitKey(name string) {
+	f.Lock()
+	defer f.Unlock()
+	f.transitKeys[name]++
+}
+
+// Paths returns the paths of all stored secrets
+func (f *FakeVault) Paths() []string {
+	f.Lock()
+	defer f.Unlock()
+	var paths []string
+	for p := range f.secrets {
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeErrors(w http.ResponseWriter, status int, errs ...string) {
+	writeJSON(w, status, map[string][]string{"errors": errs})
+}
+
+func (f *FakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	if r.URL.Path == "/v1/auth/approle/login" && r.Method == "POST" {
+		f.login(w, r)
+		return
+	}
+	if !f.tokens[r.Header.Get("X-Vault-Token")] {
+		writeErrors(w, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/v1/secret/data/"):
+		f.data(w, r, strings.TrimPrefix(r.URL.Path, "/v1/secret/data/"))
+	case strings.HasPrefix(r.URL.Path, "/v1/secret/metadata/"):
+		f.metadata(w, r, strings.TrimPrefix(r.URL.Path, "/v1/secret/metadata/"))
+	case strings.HasPrefix(r.URL.Path, "/v1/transit/encrypt/") && r.Method == "POST":
+		f.encrypt(w, r, strings.TrimPrefix(r.URL.Path, "/v1/transit/encrypt/"))
+	case strings.HasPrefix(r.URL.Path, "/v1/transit/decrypt/") && r.Method == "POST":
+		f.decrypt(w, r, strings.TrimPrefix(r.URL.Path, "/v1/transit/decrypt/"))
+	default:
+		writeErrors(w, http.StatusNotFound, "no handler for route")
+	}
+}
+
+func (f *FakeVault) login(w http.ResponseWriter, r *http.Request) {
+	var creds struct {
+		RoleID   string `json:"role_id"`
+		SecretID string `json:"secret_id"`
+	}
+	json.NewDecoder(r.Body).Decode(&creds)
+	if f.RoleID == "" || creds.RoleID != f.RoleID || creds.SecretID != f.SecretID {
+		writeErrors(w, http.StatusBadRequest, "invalid role or secret ID")
+		return
+	}
+	f.issued++
+	token := fmt.Sprintf("approle-token-%d", f.issued)
+	f.tokens[token] = true
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"auth": map[string]interface{}{
+			"client_token":   token,
+			"lease_duration": f.LeaseDuration,
+			"renewable":      true,
+		},
+	})
+}
+
+func (f *FakeVault) data(w http.ResponseWriter, r *http.Request, path string) {
+	switch r.Method {
+	case "GET":
+		versions, ok := f.secrets[path]
+		if !ok {
+			writeErrors(w, http.StatusNotFound)
+			return
+		}
+		n := len(versions)
+		if v := r.URL.Query().Get("version"); v != "" {
+			n, _ = strconv.Atoi(v)
+		}
+		if n < 1 || n > len(versions) {
+			writeErrors(w, http.StatusNotFound)
+			return
+		}
+		version := versions[n-1]
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{
+				"data": version.data,
+				"metadata": map[string]interface{}{
+					"version":       n,
+					"created_time":  version.createdTime,
+					"deletion_time": "",
+					"destroyed":     false,
+				},
+			},
+		})
+	case "PUT", "POST":
+		var body struct {
+			Data map[string]string `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeErrors(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		f.secrets[path] = append(f.secrets[path], kvVersion{data: body.Data, createdTime: time.Now().UTC()})
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{
+				"version": len(f.secrets[path]),
+			},
+		})
+	default:
+		writeErrors(w, http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *FakeVault) metadata(w http.ResponseWriter, r *http.Request, path string) {
+	versions, ok := f.secrets[path]
+	switch r.Method {
+	case "GET":
+		if !ok {
+			writeErrors(w, http.StatusNotFound)
+			return
+		}
+		m := make(map[string]interface{})
+		for i, v := range versions {
+			m[strconv.Itoa(i+1)] = map[string]interface{}{
+				"created_time":  v.createdTime,
+				"deletion_time": "",
+				"destroyed":     false,
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{
+				"current_version": len(versions),
+				"versions":        m,
+			},
+		})
+	case "DELETE":
+		delete(f.secrets, path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeErrors(w, http.StatusMethodNotAllowed)
+	}
+}
+
+// encrypt "encrypts" by encoding the key name along with the plaintext, so that decrypt can check the key
+func (f *FakeVault) encrypt(w http.ResponseWriter, r *http.Request, key string) {
+	var body struct {
+		Plaintext string `json:"plaintext"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeErrors(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if f.transitKeys[key] == 0 {
+		f.transitKeys[key] = 1
+	}
+	sealed := base64.StdEncoding.EncodeToString([]byte(key + ":" + body.Plaintext))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{
+			"ciphertext": fmt.Sprintf("vault:v%d:%s", f.transitKeys[key], sealed),
+		},
+	})
+}
+
+func (f *FakeVault) decrypt(w http.ResponseWriter, r *http.Request, key string) {
+	var body struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeErrors(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	parts := strings.SplitN(body.Ciphertext, ":", 3)
+	if len(parts) != 3 || parts[0] != "vault" {
+		writeErrors(w, http.StatusBadRequest, "invalid ciphertext")
+		return
+	}
+	sealed, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil || !strings.HasPrefix(string(sealed), key+":") {
+		writeErrors(w, http.StatusBadRequest, "cipher: message authentication failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{
+			"plaintext": strings.TrimPrefix(string(sealed), key+":"),
+		},
+	})
+}
@@ -8,6 +8,9 @@ package utils
 // APIKind a constant representing the kind of the API model
 const APIKind = "API"
 
+// CertificateKind a constant representing the kind of the Certificate model
+const CertificateKind = "Certificate"
+
 // ApplicationKind a constant to represent the kind of the Application model
 const ApplicationKind = "Application"
 
@@ -35,6 +38,15 @@ const SecretKind = "Secret"
 // PolicyKind a constant representing the kind of the Policy model
 const PolicyKind = "Policy"
 
+// RoleKind a constant representing the kind of the Role model
+const RoleKind = "Role"
+
+// RoleBindingKind a constant representing the kind of the RoleBinding model
+const RoleBindingKind = "RoleBinding"
+
+// ServiceBrokerKind a constant representing the kind of the Service Broker model
+const ServiceBrokerKind = "ServiceBroker"
+
 // ServiceClassKind a constant representing the kind of the Service Class model
 const ServiceClassKind = "ServiceClass"
 
@@ -52,3 +64,6 @@ const ServiceAccountKind = "ServiceAccount"
 
 // OrganizationKind a constant representing the kind of the Organization Model
 const OrganizationKind = "Organization"
+
+// APITokenKind a constant representing the kind of the APIToken Model
+const APITokenKind = "APIToken"
@@ -0,0 +1,55 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package utils
+
+// NO TESTS
+
+import (
+	"fmt"
+	"strings"
+
+	es "github.com/vmware/dispatch/pkg/entity-store"
+)
+
+// sortFields maps the fields the list operations sort by to the entity fields
+var sortFields = map[string]string{
+	"name":         "Name",
+	"createdTime":  "CreatedTime",
+	"modifiedTime": "ModifiedTime",
+	"status":       "Status",
+}
+
+// ParsePaging parses the limit, continue and sort parameters of the list operations into the options of a listing
+func ParsePaging(opts *es.Options, limit *int64, continueToken *string, sort *string) error {
+	if sort != nil && *sort != "" {
+		field, ok := sortFields[strings.TrimPrefix(*sort, "-")]
+		if !ok {
+			return fmt.Errorf("invalid sort field '%s'", *sort)
+		}
+		if strings.HasPrefix(*sort, "-") {
+			field = "-" + field
+		}
+		opts.Sort = field
+	}
+	if limit != nil || continueToken != nil {
+		opts.Page = &es.Page{}
+		if limit != nil {
+			opts.Page.Limit = int(*limit)
+		}
+		if continueToken != nil {
+			opts.Page.Continue = *continueToken
+		}
+	}
+	return opts.Validate()
+}
+
+// NextPage returns the token of the page following a listing, empty if the listing was not paged or is complete
+func NextPage(opts es.Options) string {
+	if opts.Page == nil {
+		return ""
+	}
+	return opts.Page.Continue
+}
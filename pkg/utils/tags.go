@@ -5,34 +5,122 @@
 
 package utils
 
-// NO TESTS
-
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
 	es "github.com/vmware/dispatch/pkg/entity-store"
 )
 
-// ParseTags parses tags pass from dispatch client,
-// a tag is key-value pair string, separate by an equal symbol
-// the only valid format of a tag is "<key>=<value>"
+// extraKeyPrefix prefixes the keys naming fields of the entities, e.g. "extra.timeout" for the Timeout field
+const extraKeyPrefix = "extra."
+
+// selectorTermRegexp matches a selector term: an optional "!", a key, then an optional operator and value, or set
+var selectorTermRegexp = regexp.MustCompile(
+	`^\s*(!?)\s*([A-Za-z0-9][-A-Za-z0-9_./]*)\s*(?:(==|!=|=|\^=|\*=|>|<)\s*(.*?)|\s(in|notin)\s*\((.*)\))?\s*$`)
+
+// ParseTags parses tags pass from dispatch client, a tag is a label selector term:
+//
+//	key=value, key==value   the tag is the value
+//	key!=value              the tag is not the value
+//	key in (v1,v2)          the tag is one of the values
+//	key notin (v1,v2)       the tag is none of the values
+//	key, !key               the tag is set, or not set
+//	key^=value, key*=value  the tag starts with, or contains the value
+//	key>number, key<number  the tag is a number greater, or less than the number
+//
+// Alternatives separated by "|" match if any of them matches, e.g. "tier=web|tier=api". The "application" (or "app")
+// key is the application of the entities, the "name" key is their name. Keys prefixed with "extra." are the fields of
+// the entities beyond the common ones, e.g. "extra.timeout>30" for the functions with a timeout over 30.
 func ParseTags(filter es.Filter, tags []string) (es.Filter, error) {
 	if filter == nil {
 		filter = es.FilterEverything()
 	}
 	for _, tag := range tags {
-		values := strings.Split(tag, "=")
-		if len(values) != 2 {
-			return nil, fmt.Errorf("error parsing tag '%s': invalid format", tag)
+		alternatives := strings.Split(tag, "|")
+		if len(alternatives) == 1 {
+			stat, err := parseSelectorTerm(tag)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing tag '%s': %s", tag, err)
+			}
+			filter.Add(stat)
+			continue
 		}
-		key, value := values[0], values[1]
-		switch strings.ToLower(key) {
-		case "application", "app":
-			filter.Add(es.FilterStatByApplication(value))
-		default:
-			return nil, fmt.Errorf("error parsing tag '%s': key '%s' not exist", tag, key)
+		var filters []es.Filter
+		for _, alternative := range alternatives {
+			stat, err := parseSelectorTerm(alternative)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing tag '%s': %s", tag, err)
+			}
+			filters = append(filters, es.FilterEverything().Add(stat))
 		}
+		filter.Add(es.FilterStatOr(filters...))
 	}
 	return filter, nil
 }
+
+// parseSelectorTerm parses a selector term into a filter statement
+func parseSelectorTerm(term string) (es.FilterStat, error) {
+	m := selectorTermRegexp.FindStringSubmatch(term)
+	if m == nil {
+		return es.FilterStat{}, fmt.Errorf("invalid format")
+	}
+	not, key, operator, value, setOperator, set := m[1] == "!", m[2], m[3], strings.TrimSpace(m[4]), m[5], m[6]
+
+	stat := es.FilterStat{Scope: es.FilterScopeTag, Subject: key}
+	switch strings.ToLower(key) {
+	case "application", "app":
+		stat.Subject = "Application"
+	case "name":
+		stat.Scope, stat.Subject = es.FilterScopeField, "Name"
+	default:
+		if strings.HasPrefix(key, extraKeyPrefix) {
+			field := strings.TrimPrefix(key, extraKeyPrefix)
+			if field == "" {
+				return stat, fmt.Errorf("missing field name")
+			}
+			stat.Scope, stat.Subject = es.FilterScopeExtra, strings.ToUpper(field[:1])+field[1:]
+		}
+	}
+
+	if not && (operator != "" || setOperator != "") {
+		return stat, fmt.Errorf("'!' only applies to a key")
+	}
+	switch {
+	case setOperator != "":
+		values := []string{}
+		for _, v := range strings.Split(set, ",") {
+			values = append(values, strings.TrimSpace(v))
+		}
+		stat.Verb, stat.Object = es.FilterVerbIn, values
+		if setOperator == "notin" {
+			stat.Verb = es.FilterVerbNotIn
+		}
+	case operator == "":
+		if stat.Scope != es.FilterScopeTag {
+			return stat, fmt.Errorf("key '%s' is always set", key)
+		}
+		stat.Verb, stat.Object = es.FilterVerbExists, !not
+	case operator == ">" || operator == "<":
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return stat, fmt.Errorf("'%s' is not a number", value)
+		}
+		stat.Verb, stat.Object = es.FilterVerbGreater, number
+		if operator == "<" {
+			stat.Verb = es.FilterVerbLess
+		}
+	default:
+		verbs := map[string]es.Verb{
+			"=":  es.FilterVerbEqual,
+			"==": es.FilterVerbEqual,
+			"!=": es.FilterVerbNotEqual,
+			"^=": es.FilterVerbPrefix,
+			"*=": es.FilterVerbContains,
+		}
+		stat.Verb, stat.Object = verbs[operator], value
+	}
+	return stat, nil
+}
@@ -0,0 +1,80 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	es "github.com/vmware/dispatch/pkg/entity-store"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+type testSelectorEntity struct {
+	es.BaseEntity
+	Timeout int64  `json:"timeout"`
+	Image   string `json:"image"`
+}
+
+func TestParseSelectorTerm(t *testing.T) {
+	stat, err := parseSelectorTerm("tier=web")
+	assert.NoError(t, err)
+	assert.Equal(t, es.FilterStat{Scope: es.FilterScopeTag, Subject: "tier", Verb: es.FilterVerbEqual, Object: "web"}, stat)
+
+	stat, err = parseSelectorTerm("app in (billing, payroll)")
+	assert.NoError(t, err)
+	assert.Equal(t, es.FilterStat{Scope: es.FilterScopeTag, Subject: "Application", Verb: es.FilterVerbIn, Object: []string{"billing", "payroll"}}, stat)
+
+	stat, err = parseSelectorTerm("name^=billing-")
+	assert.NoError(t, err)
+	assert.Equal(t, es.FilterStat{Scope: es.FilterScopeField, Subject: "Name", Verb: es.FilterVerbPrefix, Object: "billing-"}, stat)
+
+	// Extra fields are compared as numbers
+	stat, err = parseSelectorTerm("extra.timeout > 30")
+	assert.NoError(t, err)
+	assert.Equal(t, es.FilterStat{Scope: es.FilterScopeExtra, Subject: "Timeout", Verb: es.FilterVerbGreater, Object: float64(30)}, stat)
+
+	stat, err = parseSelectorTerm("extra.Timeout<1.5")
+	assert.NoError(t, err)
+	assert.Equal(t, es.FilterStat{Scope: es.FilterScopeExtra, Subject: "Timeout", Verb: es.FilterVerbLess, Object: 1.5}, stat)
+
+	for _, term := range []string{"", "!tier=web", "tier>high", "extra.", "extra.timeout", "!name"} {
+		_, err = parseSelectorTerm(term)
+		assert.Error(t, err, term)
+	}
+}
+
+func TestParseTagsExtra(t *testing.T) {
+	store := helpers.MakeEntityStore(t)
+	for name, timeout := range map[string]int64{"fast": 10, "medium": 30, "slow": 60} {
+		_, err := store.Add(context.Background(), &testSelectorEntity{
+			BaseEntity: es.BaseEntity{OrganizationID: "dispatch", Name: name, Tags: es.Tags{"tier": "web"}},
+			Timeout:    timeout,
+			Image:      "nodejs",
+		})
+		assert.NoError(t, err)
+	}
+
+	list := func(tags ...string) []string {
+		filter, err := ParseTags(nil, tags)
+		assert.NoError(t, err)
+		var entities []*testSelectorEntity
+		assert.NoError(t, store.List(context.Background(), "dispatch", es.Options{Filter: filter}, &entities))
+		names := []string{}
+		for _, e := range entities {
+			names = append(names, e.Name)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"slow"}, list("extra.timeout>30"))
+	assert.Equal(t, []string{"fast", "medium"}, list("extra.timeout<60", "tier=web"))
+	assert.Equal(t, []string{"fast", "slow"}, list("extra.timeout<20|extra.timeout>40"))
+	assert.Equal(t, []string{"fast", "medium", "slow"}, list("extra.image=nodejs"))
+	assert.Equal(t, []string{}, list("extra.image>1"))
+}